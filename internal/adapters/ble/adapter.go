@@ -0,0 +1,762 @@
+package ble
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+// BLEAdapter implements the PMAAdapter interface for passive BLE sensors and beacons
+type BLEAdapter struct {
+	scanner              Scanner
+	devices              map[string]*BLEDevice
+	logger               *logrus.Logger
+	config               BLEAdapterConfig
+	allowed              map[string]bool
+	mutex                sync.RWMutex
+	connected            bool
+	cancel               context.CancelFunc
+	eventHandler         func(entityID, oldState, newState string)
+	lastSyncTime         time.Time
+	startTime            time.Time
+	advertisementsSeen   int64
+	advertisementsParsed int64
+	syncErrors           int
+}
+
+// BLEAdapterConfig holds configuration for the BLE adapter
+type BLEAdapterConfig struct {
+	ScanCommand      string        `json:"scan_command"`
+	PresenceTimeout  time.Duration `json:"presence_timeout"` // Devices not heard from within this window are away
+	RSSIThreshold    int           `json:"rssi_threshold"`   // Weaker signals do not count as present (0 disables)
+	AllowedAddresses []string      `json:"allowed_addresses"`
+	PathLossExponent float64       `json:"path_loss_exponent"`
+}
+
+// BLEDevice is the latest state of an advertising sensor or beacon
+type BLEDevice struct {
+	Key       string             `json:"key"`
+	Address   string             `json:"address"`
+	Name      string             `json:"name,omitempty"`
+	Format    string             `json:"format"`
+	Model     string             `json:"model,omitempty"`
+	Values    map[string]float64 `json:"values"`
+	Binary    map[string]bool    `json:"binary"`
+	RSSI      *int               `json:"rssi,omitempty"`
+	Beacon    *IBeacon           `json:"beacon,omitempty"`
+	FirstSeen time.Time          `json:"first_seen"`
+	LastSeen  time.Time          `json:"last_seen"`
+}
+
+// measurementInfo describes how a decoded value is presented as a sensor entity
+type measurementInfo struct {
+	Label       string
+	Unit        string
+	DeviceClass string
+	Icon        string
+	Capability  types.PMACapability
+}
+
+var measurements = map[string]measurementInfo{
+	"temperature": {Label: "Temperature", Unit: "°C", DeviceClass: "temperature", Icon: "mdi:thermometer", Capability: types.CapabilityTemperature},
+	"humidity":    {Label: "Humidity", Unit: "%", DeviceClass: "humidity", Icon: "mdi:water-percent", Capability: types.CapabilityHumidity},
+	"battery":     {Label: "Battery", Unit: "%", DeviceClass: "battery", Icon: "mdi:battery", Capability: types.CapabilityBattery},
+	"voltage":     {Label: "Voltage", Unit: "V", DeviceClass: "voltage", Icon: "mdi:flash-triangle"},
+	"pressure":    {Label: "Pressure", Unit: "hPa", DeviceClass: "pressure", Icon: "mdi:gauge"},
+	"illuminance": {Label: "Illuminance", Unit: "lx", DeviceClass: "illuminance", Icon: "mdi:brightness-5"},
+	"dewpoint":    {Label: "Dew Point", Unit: "°C", DeviceClass: "temperature", Icon: "mdi:thermometer-water"},
+	"co2":         {Label: "CO2", Unit: "ppm", DeviceClass: "carbon_dioxide", Icon: "mdi:molecule-co2"},
+	"pm25":        {Label: "PM2.5", Unit: "µg/m³", DeviceClass: "pm25", Icon: "mdi:air-filter"},
+	"pm10":        {Label: "PM10", Unit: "µg/m³", DeviceClass: "pm10", Icon: "mdi:air-filter"},
+	"moisture":    {Label: "Moisture", Unit: "%", DeviceClass: "moisture", Icon: "mdi:water"},
+	"power":       {Label: "Power", Unit: "W", DeviceClass: "power", Icon: "mdi:flash"},
+	"energy":      {Label: "Energy", Unit: "kWh", DeviceClass: "energy", Icon: "mdi:lightning-bolt"},
+	"current":     {Label: "Current", Unit: "A", DeviceClass: "current", Icon: "mdi:current-ac"},
+	"distance":    {Label: "Distance", Unit: "m", DeviceClass: "distance", Icon: "mdi:signal-distance-variant"},
+	"rssi":        {Label: "Signal Strength", Unit: "dBm", DeviceClass: "signal_strength", Icon: "mdi:bluetooth", Capability: types.CapabilityConnectivity},
+}
+
+// NewBLEAdapter creates a new BLE adapter. A nil scanner uses bluetoothctl.
+func NewBLEAdapter(config BLEAdapterConfig, scanner Scanner, logger *logrus.Logger) *BLEAdapter {
+	if config.PresenceTimeout == 0 {
+		config.PresenceTimeout = 2 * time.Minute
+	}
+	if config.PathLossExponent == 0 {
+		config.PathLossExponent = 2.0
+	}
+	if scanner == nil {
+		scanner = NewBluetoothctlScanner(config.ScanCommand, logger)
+	}
+
+	allowed := make(map[string]bool)
+	for _, address := range config.AllowedAddresses {
+		allowed[strings.ToUpper(strings.TrimSpace(address))] = true
+	}
+
+	return &BLEAdapter{
+		scanner:   scanner,
+		devices:   make(map[string]*BLEDevice),
+		logger:    logger,
+		config:    config,
+		allowed:   allowed,
+		startTime: time.Now(),
+	}
+}
+
+// SetEventHandler sets the callback invoked when an advertisement changes an entity state
+func (a *BLEAdapter) SetEventHandler(handler func(entityID, oldState, newState string)) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.eventHandler = handler
+}
+
+// GetDevices returns a snapshot of all devices heard so far
+func (a *BLEAdapter) GetDevices() []*BLEDevice {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	devices := make([]*BLEDevice, 0, len(a.devices))
+	for _, device := range a.devices {
+		devices = append(devices, copyDevice(device))
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Key < devices[j].Key })
+	return devices
+}
+
+// ========================================
+// PMAAdapter Interface Implementation
+// ========================================
+
+// GetID returns the unique identifier for this adapter instance
+func (a *BLEAdapter) GetID() string {
+	return "ble_passive"
+}
+
+// GetSourceType returns the source type for BLE
+func (a *BLEAdapter) GetSourceType() types.PMASourceType {
+	return types.SourceBLE
+}
+
+// GetName returns the adapter name
+func (a *BLEAdapter) GetName() string {
+	return "BLE Passive Sensor Adapter"
+}
+
+// GetVersion returns the adapter version
+func (a *BLEAdapter) GetVersion() string {
+	return "1.0.0"
+}
+
+// Connect starts passive scanning
+func (a *BLEAdapter) Connect(ctx context.Context) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.connected {
+		return nil
+	}
+
+	a.logger.Info("Starting BLE passive scanning...")
+
+	// Scanning outlives the connect request, so it gets its own context
+	scanCtx, cancel := context.WithCancel(context.Background())
+	if err := a.scanner.Start(scanCtx, a.handleAdvertisement); err != nil {
+		cancel()
+		return fmt.Errorf("failed to start BLE scanner: %w", err)
+	}
+
+	a.cancel = cancel
+	a.connected = true
+	go a.presenceLoop(scanCtx)
+
+	a.logger.Info("BLE adapter connected")
+	return nil
+}
+
+// Disconnect stops scanning
+func (a *BLEAdapter) Disconnect(ctx context.Context) error {
+	a.mutex.Lock()
+	cancel := a.cancel
+	a.cancel = nil
+	a.connected = false
+	a.mutex.Unlock()
+
+	if err := a.scanner.Stop(); err != nil {
+		a.logger.WithError(err).Warn("Failed to stop BLE scanner")
+	}
+	if cancel != nil {
+		cancel()
+	}
+
+	a.logger.Info("Disconnected BLE adapter")
+	return nil
+}
+
+// IsConnected returns connection status
+func (a *BLEAdapter) IsConnected() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.connected
+}
+
+// GetStatus returns the adapter status
+func (a *BLEAdapter) GetStatus() string {
+	if a.IsConnected() {
+		return "connected"
+	}
+	return "disconnected"
+}
+
+// ConvertEntity converts a BLE device to its presence entity
+func (a *BLEAdapter) ConvertEntity(sourceEntity interface{}) (types.PMAEntity, error) {
+	device, ok := sourceEntity.(*BLEDevice)
+	if !ok {
+		return nil, fmt.Errorf("unsupported BLE entity type: %T", sourceEntity)
+	}
+
+	return a.presenceEntity(device, time.Now()), nil
+}
+
+// ConvertEntities converts BLE devices to PMA entities, one per measurement plus presence
+func (a *BLEAdapter) ConvertEntities(sourceEntities []interface{}) ([]types.PMAEntity, error) {
+	pmaEntities := make([]types.PMAEntity, 0)
+	now := time.Now()
+
+	for _, sourceEntity := range sourceEntities {
+		device, ok := sourceEntity.(*BLEDevice)
+		if !ok {
+			a.logger.Warnf("Skipping non-BLE entity: %T", sourceEntity)
+			continue
+		}
+
+		pmaEntities = append(pmaEntities, a.convertDevice(device, now)...)
+	}
+
+	return pmaEntities, nil
+}
+
+// ConvertRoom converts a BLE room to PMA room (not supported)
+func (a *BLEAdapter) ConvertRoom(sourceRoom interface{}) (*types.PMARoom, error) {
+	return nil, fmt.Errorf("room conversion not supported for BLE devices")
+}
+
+// ConvertArea converts a BLE area to PMA area (not supported)
+func (a *BLEAdapter) ConvertArea(sourceArea interface{}) (*types.PMAArea, error) {
+	return nil, fmt.Errorf("area conversion not supported for BLE devices")
+}
+
+// ExecuteAction executes control actions on BLE devices (passive sensors cannot be controlled)
+func (a *BLEAdapter) ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error) {
+	return &types.PMAControlResult{
+		Success:     false,
+		EntityID:    action.EntityID,
+		Action:      action.Action,
+		ProcessedAt: time.Now(),
+		Error: &types.PMAError{
+			Code:     "BLE_CONTROL_NOT_SUPPORTED",
+			Message:  "BLE passive sensors do not support control actions",
+			Source:   "ble",
+			EntityID: action.EntityID,
+		},
+	}, nil
+}
+
+// SyncEntities returns entities for every device heard so far
+func (a *BLEAdapter) SyncEntities(ctx context.Context) ([]types.PMAEntity, error) {
+	if !a.IsConnected() {
+		return nil, fmt.Errorf("adapter not connected")
+	}
+
+	devices := a.GetDevices()
+	sourceEntities := make([]interface{}, len(devices))
+	for i, device := range devices {
+		sourceEntities[i] = device
+	}
+
+	pmaEntities, err := a.ConvertEntities(sourceEntities)
+	if err != nil {
+		a.mutex.Lock()
+		a.syncErrors++
+		a.mutex.Unlock()
+		return nil, fmt.Errorf("failed to convert BLE devices: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.lastSyncTime = time.Now()
+	a.mutex.Unlock()
+
+	return pmaEntities, nil
+}
+
+// SyncRooms synchronizes rooms from BLE (not supported)
+func (a *BLEAdapter) SyncRooms(ctx context.Context) ([]*types.PMARoom, error) {
+	return []*types.PMARoom{}, nil
+}
+
+// GetLastSyncTime returns the last synchronization time
+func (a *BLEAdapter) GetLastSyncTime() *time.Time {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.lastSyncTime.IsZero() {
+		return nil
+	}
+	return &a.lastSyncTime
+}
+
+// GetSupportedEntityTypes returns entity types supported by the BLE adapter
+func (a *BLEAdapter) GetSupportedEntityTypes() []types.PMAEntityType {
+	return []types.PMAEntityType{
+		types.EntityTypeSensor,
+		types.EntityTypeBinarySensor,
+	}
+}
+
+// GetSupportedCapabilities returns capabilities supported by BLE sensors
+func (a *BLEAdapter) GetSupportedCapabilities() []types.PMACapability {
+	return []types.PMACapability{
+		types.CapabilityTemperature,
+		types.CapabilityHumidity,
+		types.CapabilityBattery,
+		types.CapabilityConnectivity,
+	}
+}
+
+// SupportsRealtime returns whether BLE supports real-time updates
+func (a *BLEAdapter) SupportsRealtime() bool {
+	return true // Advertisements are pushed as they are received
+}
+
+// GetHealth returns adapter health information
+func (a *BLEAdapter) GetHealth() *types.AdapterHealth {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	issues := []string{}
+	if !a.connected {
+		issues = append(issues, "BLE scanner not running")
+	}
+
+	present := 0
+	now := time.Now()
+	for _, device := range a.devices {
+		if a.isPresent(device, now) {
+			present++
+		}
+	}
+
+	if a.connected && len(a.devices) > 0 && present == 0 {
+		issues = append(issues, "No BLE devices heard recently")
+	}
+
+	return &types.AdapterHealth{
+		IsHealthy:       len(issues) == 0,
+		LastHealthCheck: now,
+		Issues:          issues,
+		ResponseTime:    0,
+		ErrorRate:       0,
+		Details: map[string]interface{}{
+			"connected":             a.connected,
+			"device_count":          len(a.devices),
+			"present_devices":       present,
+			"advertisements_seen":   a.advertisementsSeen,
+			"advertisements_parsed": a.advertisementsParsed,
+		},
+	}
+}
+
+// GetMetrics returns adapter performance metrics
+func (a *BLEAdapter) GetMetrics() *types.AdapterMetrics {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	var lastSync *time.Time
+	if !a.lastSyncTime.IsZero() {
+		lastSync = &a.lastSyncTime
+	}
+
+	entityCount := 0
+	for _, device := range a.devices {
+		entityCount += len(device.Values) + len(device.Binary) + 1 // + presence
+		if device.RSSI != nil {
+			entityCount++
+		}
+	}
+
+	return &types.AdapterMetrics{
+		EntitiesManaged:     entityCount,
+		RoomsManaged:        0,
+		ActionsExecuted:     0,
+		SuccessfulActions:   0,
+		FailedActions:       0,
+		AverageResponseTime: 0,
+		LastSync:            lastSync,
+		SyncErrors:          a.syncErrors,
+		Uptime:              time.Since(a.startTime),
+	}
+}
+
+// ========================================
+// Advertisement handling
+// ========================================
+
+// handleAdvertisement records a decoded advertisement and reports changed entity states
+func (a *BLEAdapter) handleAdvertisement(adv *Advertisement) {
+	address := strings.ToUpper(adv.Address)
+
+	a.mutex.Lock()
+	a.advertisementsSeen++
+	if len(a.allowed) > 0 && !a.allowed[address] {
+		a.mutex.Unlock()
+		return
+	}
+	a.mutex.Unlock()
+
+	reading, err := ParseAdvertisement(adv)
+	if err != nil {
+		return
+	}
+
+	// iBeacons rotate addresses on some phones, so they are keyed by their identifiers
+	key := strings.ToLower(strings.ReplaceAll(address, ":", ""))
+	if reading.Beacon != nil {
+		key = strings.ToLower(reading.Beacon.ID())
+	}
+
+	a.mutex.Lock()
+	a.advertisementsParsed++
+
+	now := adv.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	device, exists := a.devices[key]
+	var before map[string]string
+	if exists {
+		before = a.entityStates(device, now)
+	} else {
+		device = &BLEDevice{
+			Key:       key,
+			FirstSeen: now,
+			Values:    make(map[string]float64),
+			Binary:    make(map[string]bool),
+		}
+		a.devices[key] = device
+		a.logger.WithFields(logrus.Fields{
+			"address": address,
+			"format":  reading.Format,
+			"model":   reading.Model,
+		}).Info("Discovered BLE sensor")
+	}
+
+	device.Address = address
+	if adv.Name != "" {
+		device.Name = adv.Name
+	}
+	device.Format = reading.Format
+	if reading.Model != "" {
+		device.Model = reading.Model
+	}
+	for name, value := range reading.Values {
+		device.Values[name] = value
+	}
+	for name, value := range reading.Binary {
+		device.Binary[name] = value
+	}
+	if adv.RSSI != nil {
+		rssi := *adv.RSSI
+		device.RSSI = &rssi
+	}
+	if reading.Beacon != nil {
+		device.Beacon = reading.Beacon
+		if device.RSSI != nil {
+			device.Values["distance"] = EstimateDistance(*device.RSSI, int(reading.Beacon.TxPower), a.config.PathLossExponent)
+		}
+	}
+	device.LastSeen = now
+
+	after := a.entityStates(device, now)
+	handler := a.eventHandler
+	a.mutex.Unlock()
+
+	// New devices become entities on the next sync
+	if exists && handler != nil {
+		notifyChanges(handler, before, after)
+	}
+}
+
+// presenceLoop marks devices away once they have not been heard within the presence timeout
+func (a *BLEAdapter) presenceLoop(ctx context.Context) {
+	interval := a.config.PresenceTimeout / 4
+	if interval < 5*time.Second {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastPresence := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.mutex.RLock()
+			handler := a.eventHandler
+			changes := make(map[string]bool)
+			for key, device := range a.devices {
+				present := a.isPresent(device, now)
+				if previous, ok := lastPresence[key]; !ok || previous != present {
+					changes[a.entityID(device, "presence")] = present
+				}
+				lastPresence[key] = present
+			}
+			a.mutex.RUnlock()
+
+			if handler == nil {
+				continue
+			}
+			for entityID, present := range changes {
+				if !present {
+					handler(entityID, string(types.StateOn), string(types.StateOff))
+				}
+			}
+		}
+	}
+}
+
+// ========================================
+// Entity conversion
+// ========================================
+
+func (a *BLEAdapter) convertDevice(device *BLEDevice, now time.Time) []types.PMAEntity {
+	entities := make([]types.PMAEntity, 0, len(device.Values)+len(device.Binary)+2)
+
+	for _, name := range sortedKeys(device.Values) {
+		entities = append(entities, a.sensorEntity(device, name, device.Values[name], now))
+	}
+	if device.RSSI != nil {
+		entities = append(entities, a.sensorEntity(device, "rssi", float64(*device.RSSI), now))
+	}
+	for _, name := range sortedKeys(device.Binary) {
+		entities = append(entities, a.binaryEntity(device, name, device.Binary[name], now))
+	}
+	entities = append(entities, a.presenceEntity(device, now))
+
+	return entities
+}
+
+func (a *BLEAdapter) sensorEntity(device *BLEDevice, name string, value float64, now time.Time) types.PMAEntity {
+	info, ok := measurements[name]
+	if !ok {
+		info = measurementInfo{Label: titleCase(name), Icon: "mdi:bluetooth"}
+	}
+
+	capabilities := []types.PMACapability{}
+	if info.Capability != "" {
+		capabilities = append(capabilities, info.Capability)
+	}
+
+	numeric := value
+	return &types.PMASensorEntity{
+		PMABaseEntity: &types.PMABaseEntity{
+			ID:           a.entityID(device, name),
+			Type:         types.EntityTypeSensor,
+			FriendlyName: fmt.Sprintf("%s %s", a.deviceName(device), info.Label),
+			Icon:         info.Icon,
+			State:        types.PMAEntityState(formatValue(value)),
+			Attributes: map[string]interface{}{
+				"value":        value,
+				"unit":         info.Unit,
+				"device_class": info.DeviceClass,
+				"address":      device.Address,
+				"format":       device.Format,
+				"model":        device.Model,
+				"last_seen":    device.LastSeen,
+			},
+			LastUpdated:  device.LastSeen,
+			Capabilities: capabilities,
+			Metadata:     a.metadata(device, name),
+			Available:    now.Sub(device.LastSeen) < a.config.PresenceTimeout,
+		},
+		Unit:            info.Unit,
+		DeviceClass:     info.DeviceClass,
+		NumericValue:    &numeric,
+		LastMeasurement: device.LastSeen,
+	}
+}
+
+func (a *BLEAdapter) binaryEntity(device *BLEDevice, name string, value bool, now time.Time) types.PMAEntity {
+	state := types.StateOff
+	if value {
+		state = types.StateOn
+	}
+
+	capabilities := []types.PMACapability{}
+	if name == "motion" || name == "occupancy" {
+		capabilities = append(capabilities, types.CapabilityMotion)
+	}
+
+	return &types.PMABaseEntity{
+		ID:           a.entityID(device, name),
+		Type:         types.EntityTypeBinarySensor,
+		FriendlyName: fmt.Sprintf("%s %s", a.deviceName(device), titleCase(name)),
+		Icon:         "mdi:checkbox-marked-circle-outline",
+		State:        state,
+		Attributes: map[string]interface{}{
+			"device_class": name,
+			"address":      device.Address,
+			"format":       device.Format,
+			"model":        device.Model,
+			"last_seen":    device.LastSeen,
+		},
+		LastUpdated:  device.LastSeen,
+		Capabilities: capabilities,
+		Metadata:     a.metadata(device, name),
+		Available:    now.Sub(device.LastSeen) < a.config.PresenceTimeout,
+	}
+}
+
+func (a *BLEAdapter) presenceEntity(device *BLEDevice, now time.Time) types.PMAEntity {
+	state := types.StateOff
+	if a.isPresent(device, now) {
+		state = types.StateOn
+	}
+
+	attributes := map[string]interface{}{
+		"device_class":     "presence",
+		"address":          device.Address,
+		"format":           device.Format,
+		"last_seen":        device.LastSeen,
+		"first_seen":       device.FirstSeen,
+		"presence_timeout": a.config.PresenceTimeout.String(),
+		"rssi_threshold":   a.config.RSSIThreshold,
+	}
+	if device.RSSI != nil {
+		attributes["rssi"] = *device.RSSI
+	}
+	if device.Beacon != nil {
+		attributes["beacon_uuid"] = device.Beacon.UUID
+		attributes["beacon_major"] = device.Beacon.Major
+		attributes["beacon_minor"] = device.Beacon.Minor
+	}
+
+	return &types.PMABaseEntity{
+		ID:           a.entityID(device, "presence"),
+		Type:         types.EntityTypeBinarySensor,
+		FriendlyName: fmt.Sprintf("%s Presence", a.deviceName(device)),
+		Icon:         "mdi:bluetooth-connect",
+		State:        state,
+		Attributes:   attributes,
+		LastUpdated:  device.LastSeen,
+		Capabilities: []types.PMACapability{types.CapabilityConnectivity},
+		Metadata:     a.metadata(device, "presence"),
+		Available:    true, // Absence is a valid presence state
+	}
+}
+
+func (a *BLEAdapter) metadata(device *BLEDevice, sensor string) *types.PMAMetadata {
+	return &types.PMAMetadata{
+		Source:         types.SourceBLE,
+		SourceEntityID: fmt.Sprintf("%s_%s", device.Key, sensor),
+		SourceData: map[string]interface{}{
+			"address": device.Address,
+			"format":  device.Format,
+			"sensor":  sensor,
+		},
+		LastSynced:   time.Now(),
+		QualityScore: 0.8,
+	}
+}
+
+// entityStates returns the current state of every entity of a device, keyed by entity ID
+func (a *BLEAdapter) entityStates(device *BLEDevice, now time.Time) map[string]string {
+	states := make(map[string]string)
+	for _, entity := range a.convertDevice(device, now) {
+		states[entity.GetID()] = string(entity.GetState())
+	}
+	return states
+}
+
+func (a *BLEAdapter) isPresent(device *BLEDevice, now time.Time) bool {
+	if now.Sub(device.LastSeen) > a.config.PresenceTimeout {
+		return false
+	}
+	if a.config.RSSIThreshold != 0 && device.RSSI != nil && *device.RSSI < a.config.RSSIThreshold {
+		return false
+	}
+	return true
+}
+
+func (a *BLEAdapter) entityID(device *BLEDevice, sensor string) string {
+	return fmt.Sprintf("ble_%s_%s", device.Key, sensor)
+}
+
+func (a *BLEAdapter) deviceName(device *BLEDevice) string {
+	if device.Name != "" {
+		return device.Name
+	}
+	if device.Beacon != nil {
+		return fmt.Sprintf("iBeacon %d/%d", device.Beacon.Major, device.Beacon.Minor)
+	}
+	if device.Model != "" {
+		return fmt.Sprintf("%s %s", device.Model, device.Address)
+	}
+	return fmt.Sprintf("BLE %s", device.Address)
+}
+
+// Helper functions
+
+func notifyChanges(handler func(entityID, oldState, newState string), before, after map[string]string) {
+	for entityID, newState := range after {
+		if oldState, ok := before[entityID]; ok && oldState != newState {
+			handler(entityID, oldState, newState)
+		}
+	}
+}
+
+func copyDevice(device *BLEDevice) *BLEDevice {
+	clone := *device
+	clone.Values = make(map[string]float64, len(device.Values))
+	for k, v := range device.Values {
+		clone.Values[k] = v
+	}
+	clone.Binary = make(map[string]bool, len(device.Binary))
+	for k, v := range device.Binary {
+		clone.Binary[k] = v
+	}
+	if device.RSSI != nil {
+		rssi := *device.RSSI
+		clone.RSSI = &rssi
+	}
+	return &clone
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// titleCase turns a measurement name like battery_low into "Battery Low"
+func titleCase(name string) string {
+	words := strings.Fields(strings.ReplaceAll(name, "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
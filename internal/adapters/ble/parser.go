@@ -0,0 +1,431 @@
+package ble
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Advertisement formats recognized by the parser
+const (
+	FormatBTHome  = "bthome"
+	FormatATC     = "atc"
+	FormatPVVX    = "pvvx"
+	FormatGovee   = "govee"
+	FormatIBeacon = "ibeacon"
+)
+
+// Well-known 16-bit service UUIDs and company identifiers
+const (
+	uuidBTHome           uint16 = 0xFCD2
+	uuidEnvironmentSense uint16 = 0x181A // Used by ATC and pvvx custom firmware
+	companyApple         uint16 = 0x004C
+	companyGoveeH5075    uint16 = 0xEC88
+	companyGoveeH5101    uint16 = 0x0001
+	companyGoveeH5179    uint16 = 0x0188
+)
+
+// ErrUnsupportedAdvertisement is returned when no parser recognizes an advertisement
+var ErrUnsupportedAdvertisement = errors.New("unsupported advertisement format")
+
+// Advertisement is a single BLE advertisement observed by a scanner
+type Advertisement struct {
+	Address          string            `json:"address"`
+	Name             string            `json:"name,omitempty"`
+	RSSI             *int              `json:"rssi,omitempty"`
+	ServiceData      map[uint16][]byte `json:"service_data,omitempty"`      // Keyed by 16-bit service UUID
+	ManufacturerData map[uint16][]byte `json:"manufacturer_data,omitempty"` // Keyed by company identifier
+	Timestamp        time.Time         `json:"timestamp"`
+}
+
+// IBeacon contains the identifiers broadcast by an iBeacon
+type IBeacon struct {
+	UUID    string `json:"uuid"`
+	Major   uint16 `json:"major"`
+	Minor   uint16 `json:"minor"`
+	TxPower int8   `json:"tx_power"` // Calibrated RSSI at 1m
+}
+
+// ID returns a stable identifier for the beacon independent of its (possibly random) address
+func (b *IBeacon) ID() string {
+	return fmt.Sprintf("%s_%d_%d", strings.ReplaceAll(b.UUID, "-", ""), b.Major, b.Minor)
+}
+
+// Reading is the decoded content of a sensor advertisement
+type Reading struct {
+	Format   string             `json:"format"`
+	Model    string             `json:"model,omitempty"`
+	Values   map[string]float64 `json:"values,omitempty"` // e.g. temperature, humidity, battery, voltage
+	Binary   map[string]bool    `json:"binary,omitempty"` // e.g. motion, door, window
+	PacketID *int               `json:"packet_id,omitempty"`
+	Beacon   *IBeacon           `json:"beacon,omitempty"`
+}
+
+func newReading(format, model string) *Reading {
+	return &Reading{
+		Format: format,
+		Model:  model,
+		Values: make(map[string]float64),
+		Binary: make(map[string]bool),
+	}
+}
+
+// ParseAdvertisement decodes the first recognized sensor payload in an advertisement
+func ParseAdvertisement(adv *Advertisement) (*Reading, error) {
+	if data, ok := adv.ServiceData[uuidBTHome]; ok {
+		return ParseBTHome(data)
+	}
+
+	if data, ok := adv.ServiceData[uuidEnvironmentSense]; ok {
+		switch len(data) {
+		case 13:
+			return ParseATC(data)
+		case 15:
+			return ParsePVVX(data)
+		}
+	}
+
+	for companyID, data := range adv.ManufacturerData {
+		switch companyID {
+		case companyApple:
+			if reading, err := ParseIBeacon(data); err == nil {
+				return reading, nil
+			}
+		case companyGoveeH5075, companyGoveeH5101, companyGoveeH5179:
+			if reading, err := ParseGovee(companyID, data); err == nil {
+				return reading, nil
+			}
+		}
+	}
+
+	return nil, ErrUnsupportedAdvertisement
+}
+
+// ======== BTHome v2 ========
+
+// bthomeObject describes how to decode a BTHome v2 object ID
+type bthomeObject struct {
+	Name   string
+	Size   int // Payload size in bytes; 0 for variable length objects
+	Signed bool
+	Factor float64
+	Binary bool
+}
+
+// bthomeObjects is the BTHome v2 object table (https://bthome.io/format/)
+var bthomeObjects = map[byte]bthomeObject{
+	0x00: {Name: "packet_id", Size: 1, Factor: 1},
+	0x01: {Name: "battery", Size: 1, Factor: 1},
+	0x02: {Name: "temperature", Size: 2, Signed: true, Factor: 0.01},
+	0x03: {Name: "humidity", Size: 2, Factor: 0.01},
+	0x04: {Name: "pressure", Size: 3, Factor: 0.01},
+	0x05: {Name: "illuminance", Size: 3, Factor: 0.01},
+	0x06: {Name: "mass_kg", Size: 2, Factor: 0.01},
+	0x07: {Name: "mass_lb", Size: 2, Factor: 0.01},
+	0x08: {Name: "dewpoint", Size: 2, Signed: true, Factor: 0.01},
+	0x09: {Name: "count", Size: 1, Factor: 1},
+	0x0A: {Name: "energy", Size: 3, Factor: 0.001},
+	0x0B: {Name: "power", Size: 3, Factor: 0.01},
+	0x0C: {Name: "voltage", Size: 2, Factor: 0.001},
+	0x0D: {Name: "pm25", Size: 2, Factor: 1},
+	0x0E: {Name: "pm10", Size: 2, Factor: 1},
+	0x0F: {Name: "generic", Size: 1, Binary: true},
+	0x10: {Name: "power_on", Size: 1, Binary: true},
+	0x11: {Name: "opening", Size: 1, Binary: true},
+	0x12: {Name: "co2", Size: 2, Factor: 1},
+	0x13: {Name: "tvoc", Size: 2, Factor: 1},
+	0x14: {Name: "moisture", Size: 2, Factor: 0.01},
+	0x15: {Name: "battery_low", Size: 1, Binary: true},
+	0x16: {Name: "battery_charging", Size: 1, Binary: true},
+	0x17: {Name: "carbon_monoxide", Size: 1, Binary: true},
+	0x18: {Name: "cold", Size: 1, Binary: true},
+	0x19: {Name: "connectivity", Size: 1, Binary: true},
+	0x1A: {Name: "door", Size: 1, Binary: true},
+	0x1B: {Name: "garage_door", Size: 1, Binary: true},
+	0x1C: {Name: "gas", Size: 1, Binary: true},
+	0x1D: {Name: "heat", Size: 1, Binary: true},
+	0x1E: {Name: "light", Size: 1, Binary: true},
+	0x1F: {Name: "lock", Size: 1, Binary: true},
+	0x20: {Name: "moisture_detected", Size: 1, Binary: true},
+	0x21: {Name: "motion", Size: 1, Binary: true},
+	0x22: {Name: "moving", Size: 1, Binary: true},
+	0x23: {Name: "occupancy", Size: 1, Binary: true},
+	0x24: {Name: "plug", Size: 1, Binary: true},
+	0x25: {Name: "presence", Size: 1, Binary: true},
+	0x26: {Name: "problem", Size: 1, Binary: true},
+	0x27: {Name: "running", Size: 1, Binary: true},
+	0x28: {Name: "safety", Size: 1, Binary: true},
+	0x29: {Name: "smoke", Size: 1, Binary: true},
+	0x2A: {Name: "sound", Size: 1, Binary: true},
+	0x2B: {Name: "tamper", Size: 1, Binary: true},
+	0x2C: {Name: "vibration", Size: 1, Binary: true},
+	0x2D: {Name: "window", Size: 1, Binary: true},
+	0x2E: {Name: "humidity", Size: 1, Factor: 1},
+	0x2F: {Name: "moisture", Size: 1, Factor: 1},
+	0x3A: {Name: "button", Size: 1, Factor: 1},
+	0x3C: {Name: "dimmer", Size: 2, Factor: 1},
+	0x3D: {Name: "count", Size: 2, Factor: 1},
+	0x3E: {Name: "count", Size: 4, Factor: 1},
+	0x3F: {Name: "rotation", Size: 2, Signed: true, Factor: 0.1},
+	0x40: {Name: "distance_mm", Size: 2, Factor: 1},
+	0x41: {Name: "distance_m", Size: 2, Factor: 0.1},
+	0x42: {Name: "duration", Size: 3, Factor: 0.001},
+	0x43: {Name: "current", Size: 2, Factor: 0.001},
+	0x44: {Name: "speed", Size: 2, Factor: 0.01},
+	0x45: {Name: "temperature", Size: 2, Signed: true, Factor: 0.1},
+	0x46: {Name: "uv_index", Size: 1, Factor: 0.1},
+	0x47: {Name: "volume_l", Size: 2, Factor: 0.1},
+	0x48: {Name: "volume_ml", Size: 2, Factor: 1},
+	0x49: {Name: "volume_flow_rate", Size: 2, Factor: 0.001},
+	0x4A: {Name: "voltage", Size: 2, Factor: 0.1},
+	0x4B: {Name: "gas_volume", Size: 3, Factor: 0.001},
+	0x4C: {Name: "gas_volume", Size: 4, Factor: 0.001},
+	0x4D: {Name: "energy", Size: 4, Factor: 0.001},
+	0x4E: {Name: "volume", Size: 4, Factor: 0.001},
+	0x4F: {Name: "water", Size: 4, Factor: 0.001},
+	0x50: {Name: "timestamp", Size: 4, Factor: 1},
+	0x51: {Name: "acceleration", Size: 2, Factor: 0.001},
+	0x52: {Name: "gyroscope", Size: 2, Factor: 0.001},
+	0x53: {Name: "text", Size: 0},
+	0x54: {Name: "raw", Size: 0},
+	0x55: {Name: "volume_storage", Size: 4, Factor: 0.001},
+	0x57: {Name: "temperature", Size: 1, Signed: true, Factor: 1},
+	0x58: {Name: "temperature", Size: 1, Signed: true, Factor: 0.35},
+	0x59: {Name: "count", Size: 1, Signed: true, Factor: 1},
+	0x5A: {Name: "count", Size: 2, Signed: true, Factor: 1},
+	0x5B: {Name: "count", Size: 4, Signed: true, Factor: 1},
+	0x5C: {Name: "power", Size: 4, Signed: true, Factor: 0.01},
+	0x5D: {Name: "current", Size: 2, Signed: true, Factor: 0.001},
+	0xF0: {Name: "device_type_id", Size: 2, Factor: 1},
+	0xF1: {Name: "firmware_version", Size: 4, Factor: 1},
+	0xF2: {Name: "firmware_version", Size: 3, Factor: 1},
+}
+
+// ParseBTHome decodes an unencrypted BTHome v2 service data payload (UUID 0xFCD2)
+func ParseBTHome(data []byte) (*Reading, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("bthome: empty payload")
+	}
+
+	info := data[0]
+	if version := info >> 5; version != 2 {
+		return nil, fmt.Errorf("bthome: unsupported version %d", version)
+	}
+	if info&0x01 != 0 {
+		return nil, fmt.Errorf("bthome: encrypted payloads are not supported")
+	}
+
+	reading := newReading(FormatBTHome, "")
+	if info&0x04 != 0 {
+		reading.Model = "trigger_based"
+	}
+
+	for offset := 1; offset < len(data); {
+		objectID := data[offset]
+		offset++
+
+		object, ok := bthomeObjects[objectID]
+		if !ok {
+			return nil, fmt.Errorf("bthome: unknown object id 0x%02x", objectID)
+		}
+
+		size := object.Size
+		if size == 0 {
+			if offset >= len(data) {
+				return nil, fmt.Errorf("bthome: truncated %s object", object.Name)
+			}
+			size = int(data[offset])
+			offset++
+		}
+		if offset+size > len(data) {
+			return nil, fmt.Errorf("bthome: truncated %s object", object.Name)
+		}
+		payload := data[offset : offset+size]
+		offset += size
+
+		if object.Size == 0 {
+			// Text and raw objects carry no measurement
+			continue
+		}
+
+		raw := readLittleEndian(payload, object.Signed)
+		switch {
+		case objectID == 0x00:
+			packetID := int(raw)
+			reading.PacketID = &packetID
+		case object.Binary:
+			setUnique(reading.Binary, object.Name, raw != 0)
+		default:
+			setUnique(reading.Values, object.Name, round(float64(raw)*object.Factor, 3))
+		}
+	}
+
+	return reading, nil
+}
+
+// ======== ATC / pvvx custom Xiaomi firmware ========
+
+// ParseATC decodes the atc1441 custom firmware format (13 bytes, big-endian, UUID 0x181A)
+func ParseATC(data []byte) (*Reading, error) {
+	if len(data) != 13 {
+		return nil, fmt.Errorf("atc: expected 13 bytes, got %d", len(data))
+	}
+
+	reading := newReading(FormatATC, "LYWSD03MMC")
+	reading.Values["temperature"] = round(float64(int16(binary.BigEndian.Uint16(data[6:8])))*0.1, 2)
+	reading.Values["humidity"] = float64(data[8])
+	reading.Values["battery"] = float64(data[9])
+	reading.Values["voltage"] = round(float64(binary.BigEndian.Uint16(data[10:12]))*0.001, 3)
+	packetID := int(data[12])
+	reading.PacketID = &packetID
+
+	return reading, nil
+}
+
+// ParsePVVX decodes the pvvx custom firmware format (15 bytes, little-endian, UUID 0x181A)
+func ParsePVVX(data []byte) (*Reading, error) {
+	if len(data) != 15 {
+		return nil, fmt.Errorf("pvvx: expected 15 bytes, got %d", len(data))
+	}
+
+	reading := newReading(FormatPVVX, "LYWSD03MMC")
+	reading.Values["temperature"] = round(float64(int16(binary.LittleEndian.Uint16(data[6:8])))*0.01, 2)
+	reading.Values["humidity"] = round(float64(binary.LittleEndian.Uint16(data[8:10]))*0.01, 2)
+	reading.Values["voltage"] = round(float64(binary.LittleEndian.Uint16(data[10:12]))*0.001, 3)
+	reading.Values["battery"] = float64(data[12])
+	packetID := int(data[13])
+	reading.PacketID = &packetID
+
+	return reading, nil
+}
+
+// ======== Govee ========
+
+// ParseGovee decodes Govee thermometer/hygrometer manufacturer data (payload after the company ID)
+func ParseGovee(companyID uint16, data []byte) (*Reading, error) {
+	switch {
+	case companyID == companyGoveeH5075 && len(data) == 6:
+		// H5072/H5075: packed 24-bit temperature/humidity, battery
+		reading := newReading(FormatGovee, "H5075")
+		temperature, humidity := decodeGoveePacked(data[1:4])
+		reading.Values["temperature"] = temperature
+		reading.Values["humidity"] = humidity
+		reading.Values["battery"] = float64(data[4])
+		return reading, nil
+
+	case companyID == companyGoveeH5075 && len(data) == 7:
+		// H5074: little-endian int16 temperature and uint16 humidity in hundredths
+		reading := newReading(FormatGovee, "H5074")
+		reading.Values["temperature"] = round(float64(int16(binary.LittleEndian.Uint16(data[1:3])))*0.01, 2)
+		reading.Values["humidity"] = round(float64(binary.LittleEndian.Uint16(data[3:5]))*0.01, 2)
+		reading.Values["battery"] = float64(data[5])
+		return reading, nil
+
+	case companyID == companyGoveeH5101 && len(data) == 6:
+		// H5100/H5101/H5102/H5177: packed 24-bit temperature/humidity, battery
+		reading := newReading(FormatGovee, "H5101")
+		temperature, humidity := decodeGoveePacked(data[2:5])
+		reading.Values["temperature"] = temperature
+		reading.Values["humidity"] = humidity
+		reading.Values["battery"] = float64(data[5])
+		return reading, nil
+
+	case companyID == companyGoveeH5179 && len(data) == 9:
+		// H5179: little-endian int16 temperature and uint16 humidity in hundredths
+		reading := newReading(FormatGovee, "H5179")
+		reading.Values["temperature"] = round(float64(int16(binary.LittleEndian.Uint16(data[4:6])))*0.01, 2)
+		reading.Values["humidity"] = round(float64(binary.LittleEndian.Uint16(data[6:8]))*0.01, 2)
+		reading.Values["battery"] = float64(data[8])
+		return reading, nil
+	}
+
+	return nil, fmt.Errorf("govee: unrecognized payload (company 0x%04x, %d bytes)", companyID, len(data))
+}
+
+// decodeGoveePacked decodes the 24-bit big-endian value used by several Govee models:
+// temperature*10000 + humidity*10, with the top bit marking negative temperatures
+func decodeGoveePacked(data []byte) (float64, float64) {
+	packed := uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+
+	negative := packed&0x800000 != 0
+	packed &^= 0x800000
+
+	temperature := float64(packed/1000) / 10
+	humidity := float64(packed%1000) / 10
+	if negative {
+		temperature = -temperature
+	}
+
+	return round(temperature, 2), round(humidity, 2)
+}
+
+// ======== iBeacon ========
+
+// ParseIBeacon decodes Apple iBeacon manufacturer data (payload after company ID 0x004C)
+func ParseIBeacon(data []byte) (*Reading, error) {
+	if len(data) != 23 || data[0] != 0x02 || data[1] != 0x15 {
+		return nil, fmt.Errorf("ibeacon: not an iBeacon payload")
+	}
+
+	uuid := data[2:18]
+	beacon := &IBeacon{
+		UUID: fmt.Sprintf("%x-%x-%x-%x-%x",
+			uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]),
+		Major:   binary.BigEndian.Uint16(data[18:20]),
+		Minor:   binary.BigEndian.Uint16(data[20:22]),
+		TxPower: int8(data[22]),
+	}
+
+	reading := newReading(FormatIBeacon, "iBeacon")
+	reading.Beacon = beacon
+	return reading, nil
+}
+
+// EstimateDistance estimates the distance in meters to a transmitter from its RSSI and
+// calibrated 1m transmit power using the log-distance path loss model
+func EstimateDistance(rssi int, txPower int, pathLossExponent float64) float64 {
+	if pathLossExponent <= 0 {
+		pathLossExponent = 2.0
+	}
+	return round(math.Pow(10, float64(txPower-rssi)/(10*pathLossExponent)), 2)
+}
+
+// ======== Helpers ========
+
+func readLittleEndian(data []byte, signed bool) int64 {
+	var value uint64
+	for i := len(data) - 1; i >= 0; i-- {
+		value = value<<8 | uint64(data[i])
+	}
+
+	if signed && len(data) > 0 && len(data) < 8 {
+		bits := uint(len(data) * 8)
+		if value&(1<<(bits-1)) != 0 {
+			return int64(value) - int64(1)<<bits
+		}
+	}
+	return int64(value)
+}
+
+// setUnique stores a value, suffixing the key when a measurement type repeats
+func setUnique[T any](m map[string]T, key string, value T) {
+	if _, exists := m[key]; !exists {
+		m[key] = value
+		return
+	}
+	for i := 2; ; i++ {
+		suffixed := fmt.Sprintf("%s_%d", key, i)
+		if _, exists := m[suffixed]; !exists {
+			m[suffixed] = value
+			return
+		}
+	}
+}
+
+func round(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
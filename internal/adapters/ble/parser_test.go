@@ -0,0 +1,205 @@
+package ble
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseBTHome(t *testing.T) {
+	// Packet 5, battery 100%, 25.00°C, 50.55% humidity, motion detected
+	reading, err := ParseBTHome(mustHex(t, "40 00 05 01 64 02 C4 09 03 BF 13 21 01"))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatBTHome, reading.Format)
+	require.NotNil(t, reading.PacketID)
+	assert.Equal(t, 5, *reading.PacketID)
+	assert.Equal(t, 100.0, reading.Values["battery"])
+	assert.Equal(t, 25.0, reading.Values["temperature"])
+	assert.Equal(t, 50.55, reading.Values["humidity"])
+	assert.True(t, reading.Binary["motion"])
+}
+
+func TestParseBTHome_NegativeAndRepeatedObjects(t *testing.T) {
+	// Two temperatures: -5.25°C (sint16 x0.01) and 21.3°C (sint16 x0.1)
+	reading, err := ParseBTHome(mustHex(t, "40 02 F3 FD 45 D5 00"))
+	require.NoError(t, err)
+
+	assert.Equal(t, -5.25, reading.Values["temperature"])
+	assert.Equal(t, 21.3, reading.Values["temperature_2"])
+}
+
+func TestParseBTHome_Rejects(t *testing.T) {
+	_, err := ParseBTHome(mustHex(t, "41 02 C4 09"))
+	assert.ErrorContains(t, err, "encrypted")
+
+	_, err = ParseBTHome(mustHex(t, "20 02 C4 09"))
+	assert.ErrorContains(t, err, "version")
+
+	_, err = ParseBTHome(mustHex(t, "40 02 C4"))
+	assert.ErrorContains(t, err, "truncated")
+
+	_, err = ParseBTHome(mustHex(t, "40 FE 01"))
+	assert.ErrorContains(t, err, "unknown object")
+}
+
+func TestParseATC(t *testing.T) {
+	reading, err := ParseATC(mustHex(t, "A4C138123456 00E1 2D 55 0B8C 10"))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatATC, reading.Format)
+	assert.Equal(t, 22.5, reading.Values["temperature"])
+	assert.Equal(t, 45.0, reading.Values["humidity"])
+	assert.Equal(t, 85.0, reading.Values["battery"])
+	assert.Equal(t, 2.956, reading.Values["voltage"])
+	assert.Equal(t, 16, *reading.PacketID)
+}
+
+func TestParsePVVX(t *testing.T) {
+	reading, err := ParsePVVX(mustHex(t, "563412 38C1A4 CA08 D711 8C0B 55 10 04"))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatPVVX, reading.Format)
+	assert.Equal(t, 22.5, reading.Values["temperature"])
+	assert.Equal(t, 45.67, reading.Values["humidity"])
+	assert.Equal(t, 2.956, reading.Values["voltage"])
+	assert.Equal(t, 85.0, reading.Values["battery"])
+}
+
+func TestParseGovee(t *testing.T) {
+	tests := []struct {
+		name        string
+		companyID   uint16
+		data        string
+		model       string
+		temperature float64
+		humidity    float64
+		battery     float64
+	}{
+		{"H5075", 0xEC88, "00 035E8B 64 00", "H5075", 22.0, 81.1, 100},
+		{"H5075 negative", 0xEC88, "00 80CCE5 4B 00", "H5075", -5.2, 45.3, 75},
+		{"H5074", 0xEC88, "00 85FF D711 64 02", "H5074", -1.23, 45.67, 100},
+		{"H5101", 0x0001, "01 01 035E8B 5A", "H5101", 22.0, 81.1, 90},
+		{"H5179", 0x0188, "01 01 0A 00 CA08 D711 55", "H5179", 22.5, 45.67, 85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reading, err := ParseGovee(tt.companyID, mustHex(t, tt.data))
+			require.NoError(t, err)
+
+			assert.Equal(t, FormatGovee, reading.Format)
+			assert.Equal(t, tt.model, reading.Model)
+			assert.Equal(t, tt.temperature, reading.Values["temperature"])
+			assert.Equal(t, tt.humidity, reading.Values["humidity"])
+			assert.Equal(t, tt.battery, reading.Values["battery"])
+		})
+	}
+
+	_, err := ParseGovee(0xEC88, mustHex(t, "0001"))
+	assert.Error(t, err)
+}
+
+func TestParseIBeacon(t *testing.T) {
+	reading, err := ParseIBeacon(mustHex(t, "0215 E2C56DB5DFFB48D2B060D0F5A71096E0 0001 0002 C5"))
+	require.NoError(t, err)
+
+	require.NotNil(t, reading.Beacon)
+	assert.Equal(t, "e2c56db5-dffb-48d2-b060-d0f5a71096e0", reading.Beacon.UUID)
+	assert.Equal(t, uint16(1), reading.Beacon.Major)
+	assert.Equal(t, uint16(2), reading.Beacon.Minor)
+	assert.Equal(t, int8(-59), reading.Beacon.TxPower)
+	assert.Equal(t, "e2c56db5dffb48d2b060d0f5a71096e0_1_2", reading.Beacon.ID())
+
+	_, err = ParseIBeacon(mustHex(t, "1005"))
+	assert.Error(t, err)
+}
+
+func TestEstimateDistance(t *testing.T) {
+	assert.Equal(t, 1.0, EstimateDistance(-59, -59, 2))
+	assert.Equal(t, 10.0, EstimateDistance(-79, -59, 2))
+}
+
+func TestParseAdvertisement_Dispatch(t *testing.T) {
+	reading, err := ParseAdvertisement(&Advertisement{
+		ServiceData: map[uint16][]byte{0x181A: mustHex(t, "A4C138123456 00E1 2D 55 0B8C 10")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, FormatATC, reading.Format)
+
+	reading, err = ParseAdvertisement(&Advertisement{
+		ManufacturerData: map[uint16][]byte{0xEC88: mustHex(t, "00 035E8B 64 00")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, FormatGovee, reading.Format)
+
+	_, err = ParseAdvertisement(&Advertisement{
+		ManufacturerData: map[uint16][]byte{0x0006: mustHex(t, "0102")},
+	})
+	assert.ErrorIs(t, err, ErrUnsupportedAdvertisement)
+}
+
+func TestBluetoothctlParser(t *testing.T) {
+	// Captured from bluetoothctl 5.66 with "scan on"
+	output := []string{
+		"[NEW] Device A4:C1:38:12:34:56 ATC_123456",
+		"[CHG] Device A4:C1:38:12:34:56 RSSI: 0xffffffbd (-67)",
+		"[CHG] Device A4:C1:38:12:34:56 ServiceData Key: 0000181a-0000-1000-8000-00805f9b34fb",
+		"[CHG] Device A4:C1:38:12:34:56 ServiceData Value:",
+		"  a4 c1 38 12 34 56 00 e1 2d 55 0b 8c 10           ..8.4V..-U...   ",
+		"\x1b[0;94m[bluetooth]\x1b[0m# [CHG] Device E3:37:3C:11:22:33 ManufacturerData Key: 0xec88 (60552)",
+		"[CHG] Device E3:37:3C:11:22:33 ManufacturerData Value:",
+		"  00 03 5e 8b 64 00                                ..^.d.          ",
+		"[CHG] Device E3:37:3C:11:22:33 RSSI: -80",
+	}
+
+	parser := NewBluetoothctlParser()
+	var advertisements []*Advertisement
+	for _, line := range output {
+		if adv := parser.ParseLine(line); adv != nil {
+			advertisements = append(advertisements, adv)
+		}
+	}
+	if adv := parser.Flush(); adv != nil {
+		advertisements = append(advertisements, adv)
+	}
+
+	require.Len(t, advertisements, 2)
+
+	atc := advertisements[0]
+	assert.Equal(t, "A4:C1:38:12:34:56", atc.Address)
+	assert.Equal(t, "ATC_123456", atc.Name)
+	require.NotNil(t, atc.RSSI)
+	assert.Equal(t, -67, *atc.RSSI)
+	assert.Equal(t, mustHex(t, "a4c138123456 00e1 2d 55 0b8c 10"), atc.ServiceData[0x181A])
+
+	govee := advertisements[1]
+	assert.Equal(t, "E3:37:3C:11:22:33", govee.Address)
+	assert.Nil(t, govee.RSSI) // RSSI arrived after the data block
+	assert.Equal(t, mustHex(t, "00035e8b6400"), govee.ManufacturerData[0xEC88])
+}
+
+func TestParseDataKey(t *testing.T) {
+	for input, expected := range map[string]uint16{
+		"0000fcd2-0000-1000-8000-00805f9b34fb": 0xFCD2,
+		"0xec88":                               0xEC88,
+		"76":                                   76,
+	} {
+		key, ok := parseDataKey(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, key, input)
+	}
+
+	_, ok := parseDataKey("6e400001-b5a3-f393-e0a9-e50e24dcca9e")
+	assert.False(t, ok)
+}
@@ -0,0 +1,322 @@
+package ble
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scanner delivers BLE advertisements observed by the host radio
+type Scanner interface {
+	// Start begins passive scanning and calls handler for every advertisement until ctx is cancelled
+	Start(ctx context.Context, handler func(*Advertisement)) error
+	Stop() error
+}
+
+// BluetoothctlScanner scans using the BlueZ bluetoothctl client, the same tool used by the
+// bluetooth service, and decodes service/manufacturer data from its interactive output
+type BluetoothctlScanner struct {
+	command string
+	logger  *logrus.Logger
+	mutex   sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	done    chan struct{}
+}
+
+// NewBluetoothctlScanner creates a scanner that runs the given bluetoothctl binary
+func NewBluetoothctlScanner(command string, logger *logrus.Logger) *BluetoothctlScanner {
+	if command == "" {
+		command = "bluetoothctl"
+	}
+	return &BluetoothctlScanner{
+		command: command,
+		logger:  logger,
+	}
+}
+
+// Start launches bluetoothctl, enables scanning and parses its output in the background
+func (s *BluetoothctlScanner) Start(ctx context.Context, handler func(*Advertisement)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cmd != nil {
+		return fmt.Errorf("scanner already running")
+	}
+
+	if _, err := exec.LookPath(s.command); err != nil {
+		return fmt.Errorf("%s not found: %w", s.command, err)
+	}
+
+	cmd := exec.CommandContext(ctx, s.command)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open %s stdin: %w", s.command, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open %s stdout: %w", s.command, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", s.command, err)
+	}
+
+	// Duplicate reports are required to receive every advertisement, not only the first
+	for _, line := range []string{"menu scan", "duplicate-data on", "back", "scan on"} {
+		if _, err := fmt.Fprintln(stdin, line); err != nil {
+			_ = cmd.Process.Kill()
+			return fmt.Errorf("failed to enable scanning: %w", err)
+		}
+	}
+
+	done := make(chan struct{})
+	s.cmd = cmd
+	s.stdin = stdin
+	s.done = done
+
+	go func() {
+		defer close(done)
+
+		parser := NewBluetoothctlParser()
+		reader := bufio.NewScanner(stdout)
+		reader.Buffer(make([]byte, 64*1024), 1024*1024)
+		for reader.Scan() {
+			if adv := parser.ParseLine(reader.Text()); adv != nil {
+				handler(adv)
+			}
+		}
+		if adv := parser.Flush(); adv != nil {
+			handler(adv)
+		}
+
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			s.logger.WithError(err).Warn("bluetoothctl scanner exited unexpectedly")
+		}
+
+		s.mutex.Lock()
+		if s.cmd == cmd {
+			s.cmd = nil
+			s.stdin = nil
+			s.done = nil
+		}
+		s.mutex.Unlock()
+	}()
+
+	s.logger.Info("BLE advertisement scanning started")
+	return nil
+}
+
+// Stop disables scanning and terminates bluetoothctl
+func (s *BluetoothctlScanner) Stop() error {
+	s.mutex.Lock()
+	cmd, stdin, done := s.cmd, s.stdin, s.done
+	s.cmd = nil
+	s.stdin = nil
+	s.done = nil
+	s.mutex.Unlock()
+
+	if cmd == nil {
+		return nil
+	}
+
+	if stdin != nil {
+		fmt.Fprintln(stdin, "scan off")
+		fmt.Fprintln(stdin, "quit")
+		stdin.Close()
+	}
+
+	// Give bluetoothctl a moment to exit cleanly before killing it
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+		<-done
+	}
+
+	s.logger.Info("BLE advertisement scanning stopped")
+	return nil
+}
+
+// ======== bluetoothctl output parsing ========
+
+var (
+	ansiPattern     = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]|\x01|\x02`)
+	devicePattern   = regexp.MustCompile(`^\[(NEW|CHG|DEL)\] Device ([0-9A-Fa-f:]{17}) (.*)$`)
+	rssiPattern     = regexp.MustCompile(`^RSSI: (?:0x[0-9a-fA-F]+ \()?(-?\d+)\)?`)
+	dataKeyPattern  = regexp.MustCompile(`^(ServiceData|ManufacturerData)[ .]Key: (\S+)`)
+	dataValPattern  = regexp.MustCompile(`^(ServiceData|ManufacturerData)[ .]Value:\s*$`)
+	dataInlinePatt  = regexp.MustCompile(`^(ServiceData|ManufacturerData)\.(\S+):\s*$`)
+	hexDumpPattern  = regexp.MustCompile(`^\s+((?:[0-9a-fA-F]{2} ){1,16})`)
+	bluetoothSuffix = "-0000-1000-8000-00805f9b34fb"
+)
+
+// BluetoothctlParser turns bluetoothctl's line oriented output into advertisements.
+// Service and manufacturer data are printed as a key line followed by an indented hex dump,
+// so the parser keeps the pending key until the dump is complete.
+type BluetoothctlParser struct {
+	names      map[string]string
+	rssi       map[string]int
+	address    string
+	kind       string
+	key        uint16
+	hasKey     bool
+	collecting bool
+	buffer     []byte
+}
+
+// NewBluetoothctlParser creates a parser with empty device state
+func NewBluetoothctlParser() *BluetoothctlParser {
+	return &BluetoothctlParser{
+		names: make(map[string]string),
+		rssi:  make(map[string]int),
+	}
+}
+
+// ParseLine consumes one line of output and returns an advertisement when a data block completes
+func (p *BluetoothctlParser) ParseLine(line string) *Advertisement {
+	line = ansiPattern.ReplaceAllString(line, "")
+	line = strings.TrimRight(line, "\r")
+
+	if p.collecting {
+		if match := hexDumpPattern.FindStringSubmatch(line); match != nil {
+			for _, field := range strings.Fields(match[1]) {
+				if b, err := hex.DecodeString(field); err == nil {
+					p.buffer = append(p.buffer, b...)
+				}
+			}
+			return nil
+		}
+	}
+
+	// Any other line ends a pending hex dump
+	adv := p.Flush()
+
+	// The interactive prompt may precede the event on the same line
+	for _, marker := range []string{"[NEW]", "[CHG]", "[DEL]"} {
+		if idx := strings.Index(line, marker); idx > 0 {
+			line = line[idx:]
+			break
+		}
+	}
+
+	match := devicePattern.FindStringSubmatch(line)
+	if match == nil {
+		return adv
+	}
+
+	event, address, rest := match[1], strings.ToUpper(match[2]), strings.TrimSpace(match[3])
+	switch event {
+	case "DEL":
+		delete(p.names, address)
+		delete(p.rssi, address)
+		return adv
+	case "NEW":
+		if rest != "" && strings.ReplaceAll(rest, "-", ":") != address {
+			p.names[address] = rest
+		}
+		return adv
+	}
+
+	if strings.HasPrefix(rest, "Name: ") || strings.HasPrefix(rest, "Alias: ") {
+		p.names[address] = strings.TrimSpace(rest[strings.Index(rest, ":")+1:])
+		return adv
+	}
+
+	if m := rssiPattern.FindStringSubmatch(rest); m != nil {
+		if value, err := strconv.Atoi(m[1]); err == nil {
+			p.rssi[address] = value
+		}
+		return adv
+	}
+
+	if m := dataKeyPattern.FindStringSubmatch(rest); m != nil {
+		if key, ok := parseDataKey(m[2]); ok {
+			p.address, p.kind, p.key, p.hasKey = address, m[1], key, true
+		} else {
+			p.hasKey = false
+		}
+		return adv
+	}
+
+	if m := dataValPattern.FindStringSubmatch(rest); m != nil {
+		if p.hasKey && p.address == address && p.kind == m[1] {
+			p.collecting = true
+			p.buffer = p.buffer[:0]
+		}
+		return adv
+	}
+
+	if m := dataInlinePatt.FindStringSubmatch(rest); m != nil {
+		if key, ok := parseDataKey(m[2]); ok {
+			p.address, p.kind, p.key, p.hasKey = address, m[1], key, true
+			p.collecting = true
+			p.buffer = p.buffer[:0]
+		}
+		return adv
+	}
+
+	return adv
+}
+
+// Flush completes any pending data block and returns its advertisement
+func (p *BluetoothctlParser) Flush() *Advertisement {
+	if !p.collecting {
+		return nil
+	}
+	p.collecting = false
+	p.hasKey = false
+
+	if len(p.buffer) == 0 {
+		return nil
+	}
+
+	data := make([]byte, len(p.buffer))
+	copy(data, p.buffer)
+
+	adv := &Advertisement{
+		Address:   p.address,
+		Name:      p.names[p.address],
+		Timestamp: time.Now(),
+	}
+	if rssi, ok := p.rssi[p.address]; ok {
+		adv.RSSI = &rssi
+	}
+	if p.kind == "ServiceData" {
+		adv.ServiceData = map[uint16][]byte{p.key: data}
+	} else {
+		adv.ManufacturerData = map[uint16][]byte{p.key: data}
+	}
+	return adv
+}
+
+// parseDataKey converts a bluetoothctl key (0xec88, 0xec88 (60552), 60552 or a full
+// 128-bit Bluetooth base UUID) into its 16-bit identifier
+func parseDataKey(key string) (uint16, bool) {
+	key = strings.ToLower(strings.TrimSpace(key))
+
+	if strings.HasSuffix(key, bluetoothSuffix) && len(key) == 36 {
+		value, err := strconv.ParseUint(key[4:8], 16, 16)
+		return uint16(value), err == nil && key[:4] == "0000"
+	}
+
+	if strings.HasPrefix(key, "0x") {
+		value, err := strconv.ParseUint(key[2:], 16, 16)
+		return uint16(value), err == nil
+	}
+
+	value, err := strconv.ParseUint(key, 10, 16)
+	return uint16(value), err == nil
+}
@@ -0,0 +1,506 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// Helper errors
+var (
+	ErrHelperNotFound = errors.New("helper not found")
+	ErrHelperExists   = errors.New("helper already exists")
+	ErrInvalidHelper  = errors.New("invalid helper")
+)
+
+// EntityHandler is called with the new entity after a helper changed
+type EntityHandler func(entity types.PMAEntity)
+
+// RemoveHandler is called after a helper was deleted
+type RemoveHandler func(entityID string)
+
+// TimerEventHandler is called when a timer is started, paused, cancelled or finishes. The event
+// is one of the automation timer event types.
+type TimerEventHandler func(entityID, event string, data map[string]interface{})
+
+// HelpersAdapter implements the PMAAdapter interface for helper entities stored in SQLite
+type HelpersAdapter struct {
+	repo              repositories.HelperRepository
+	logger            *logrus.Logger
+	mutex             sync.RWMutex
+	helpers           map[string]*models.Helper // keyed by entity ID
+	timers            map[string]*time.Timer
+	connected         bool
+	lastSyncTime      time.Time
+	startTime         time.Time
+	actionsExecuted   int64
+	successfulActions int64
+	failedActions     int64
+
+	onChange     EntityHandler
+	onRemove     RemoveHandler
+	onTimerEvent TimerEventHandler
+}
+
+// NewHelpersAdapter creates a new helpers adapter
+func NewHelpersAdapter(repo repositories.HelperRepository, logger *logrus.Logger) *HelpersAdapter {
+	return &HelpersAdapter{
+		repo:      repo,
+		logger:    logger,
+		helpers:   make(map[string]*models.Helper),
+		timers:    make(map[string]*time.Timer),
+		startTime: time.Now(),
+	}
+}
+
+// SetEventHandler sets the handler called with the new entity after a helper changed
+func (a *HelpersAdapter) SetEventHandler(handler EntityHandler) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.onChange = handler
+}
+
+// SetRemoveHandler sets the handler called after a helper was deleted
+func (a *HelpersAdapter) SetRemoveHandler(handler RemoveHandler) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.onRemove = handler
+}
+
+// SetTimerEventHandler sets the handler called for timer events
+func (a *HelpersAdapter) SetTimerEventHandler(handler TimerEventHandler) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.onTimerEvent = handler
+}
+
+// ========================================
+// PMAAdapter Interface Implementation
+// ========================================
+
+// GetID returns the unique identifier for this adapter instance
+func (a *HelpersAdapter) GetID() string {
+	return "helpers"
+}
+
+// GetSourceType returns the source type for helpers
+func (a *HelpersAdapter) GetSourceType() types.PMASourceType {
+	return types.SourceHelper
+}
+
+// GetName returns the adapter name
+func (a *HelpersAdapter) GetName() string {
+	return "Helper Entities Adapter"
+}
+
+// GetVersion returns the adapter version
+func (a *HelpersAdapter) GetVersion() string {
+	return "1.0.0"
+}
+
+// Connect loads the stored helpers and resumes running timers. Timers that finished while PMA
+// was down finish right away.
+func (a *HelpersAdapter) Connect(ctx context.Context) error {
+	stored, err := a.repo.ListHelpers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load helpers: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.helpers = make(map[string]*models.Helper, len(stored))
+	for _, helper := range stored {
+		a.helpers[EntityID(helper)] = helper
+		if helper.Type == string(types.EntityTypeTimer) && helper.State == string(types.StateActive) && helper.TimerFinishesAt != nil {
+			a.scheduleTimer(helper)
+		}
+	}
+	a.connected = true
+	a.mutex.Unlock()
+
+	a.logger.WithField("helpers", len(stored)).Info("Helper entities loaded")
+	return nil
+}
+
+// Disconnect stops all running timers. Their state stays stored and resumes on Connect.
+func (a *HelpersAdapter) Disconnect(ctx context.Context) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for entityID, timer := range a.timers {
+		timer.Stop()
+		delete(a.timers, entityID)
+	}
+	a.connected = false
+	return nil
+}
+
+// IsConnected returns connection status
+func (a *HelpersAdapter) IsConnected() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.connected
+}
+
+// GetStatus returns the adapter status
+func (a *HelpersAdapter) GetStatus() string {
+	if a.IsConnected() {
+		return "connected"
+	}
+	return "disconnected"
+}
+
+// ConvertEntity converts a helper to a PMA entity
+func (a *HelpersAdapter) ConvertEntity(sourceEntity interface{}) (types.PMAEntity, error) {
+	helper, ok := sourceEntity.(*models.Helper)
+	if !ok {
+		return nil, fmt.Errorf("unsupported helper entity type: %T", sourceEntity)
+	}
+	return buildEntity(helper), nil
+}
+
+// ConvertEntities converts multiple helpers to PMA entities
+func (a *HelpersAdapter) ConvertEntities(sourceEntities []interface{}) ([]types.PMAEntity, error) {
+	entities := make([]types.PMAEntity, 0, len(sourceEntities))
+	for _, sourceEntity := range sourceEntities {
+		entity, err := a.ConvertEntity(sourceEntity)
+		if err != nil {
+			a.logger.WithError(err).Warn("Skipping helper entity")
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// ConvertRoom converts a helper room to PMA room (not supported)
+func (a *HelpersAdapter) ConvertRoom(sourceRoom interface{}) (*types.PMARoom, error) {
+	return nil, fmt.Errorf("room conversion not supported for helpers")
+}
+
+// ConvertArea converts a helper area to PMA area (not supported)
+func (a *HelpersAdapter) ConvertArea(sourceArea interface{}) (*types.PMAArea, error) {
+	return nil, fmt.Errorf("area conversion not supported for helpers")
+}
+
+// ExecuteAction changes a helper, stores its new state and publishes the new entity
+func (a *HelpersAdapter) ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error) {
+	start := time.Now()
+
+	a.mutex.Lock()
+	a.actionsExecuted++
+	helper, ok := a.helpers[action.EntityID]
+	if !ok {
+		a.failedActions++
+		a.mutex.Unlock()
+		return actionError(action, "ENTITY_NOT_FOUND", fmt.Sprintf("helper %s not found", action.EntityID)), nil
+	}
+
+	previous := *helper
+	event, err := a.apply(helper, action)
+	if err != nil {
+		*helper = previous
+		a.failedActions++
+		a.mutex.Unlock()
+		return actionError(action, "INVALID_ACTION", err.Error()), nil
+	}
+	if helper.Type == string(types.EntityTypeTimer) {
+		a.rescheduleTimer(helper)
+	}
+	a.successfulActions++
+	snapshot := *helper
+	a.mutex.Unlock()
+
+	if err := a.repo.SaveHelperState(ctx, &snapshot); err != nil {
+		a.logger.WithError(err).WithField("entity_id", action.EntityID).Warn("Failed to store helper state")
+	}
+
+	entity := buildEntity(&snapshot)
+	a.publish(entity)
+	if event != "" {
+		a.fireTimerEvent(&snapshot, event)
+	}
+
+	return &types.PMAControlResult{
+		Success:     true,
+		EntityID:    action.EntityID,
+		Action:      action.Action,
+		NewState:    entity.State,
+		Attributes:  entity.Attributes,
+		ProcessedAt: time.Now(),
+		Duration:    time.Since(start),
+	}, nil
+}
+
+// SyncEntities returns the entities of all helpers
+func (a *HelpersAdapter) SyncEntities(ctx context.Context) ([]types.PMAEntity, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	entities := make([]types.PMAEntity, 0, len(a.helpers))
+	for _, helper := range a.helpers {
+		entities = append(entities, buildEntity(helper))
+	}
+	a.lastSyncTime = time.Now()
+	return entities, nil
+}
+
+// SyncRooms synchronizes rooms from helpers (not supported)
+func (a *HelpersAdapter) SyncRooms(ctx context.Context) ([]*types.PMARoom, error) {
+	return []*types.PMARoom{}, nil
+}
+
+// GetLastSyncTime returns the last synchronization time
+func (a *HelpersAdapter) GetLastSyncTime() *time.Time {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.lastSyncTime.IsZero() {
+		return nil
+	}
+	lastSync := a.lastSyncTime
+	return &lastSync
+}
+
+// GetSupportedEntityTypes returns the helper entity types
+func (a *HelpersAdapter) GetSupportedEntityTypes() []types.PMAEntityType {
+	return []types.PMAEntityType{
+		types.EntityTypeInputBoolean,
+		types.EntityTypeInputNumber,
+		types.EntityTypeInputSelect,
+		types.EntityTypeInputText,
+		types.EntityTypeCounter,
+		types.EntityTypeTimer,
+	}
+}
+
+// GetSupportedCapabilities returns capabilities supported by helpers
+func (a *HelpersAdapter) GetSupportedCapabilities() []types.PMACapability {
+	return []types.PMACapability{}
+}
+
+// SupportsRealtime returns whether helpers push their changes
+func (a *HelpersAdapter) SupportsRealtime() bool {
+	return true
+}
+
+// GetHealth returns adapter health information
+func (a *HelpersAdapter) GetHealth() *types.AdapterHealth {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	issues := []string{}
+	if !a.connected {
+		issues = append(issues, "Helpers not loaded")
+	}
+
+	return &types.AdapterHealth{
+		IsHealthy:       len(issues) == 0,
+		LastHealthCheck: time.Now(),
+		Issues:          issues,
+		ErrorRate:       a.calculateErrorRate(),
+		Details: map[string]interface{}{
+			"helper_count":  len(a.helpers),
+			"running_timer": len(a.timers),
+		},
+	}
+}
+
+// GetMetrics returns adapter performance metrics
+func (a *HelpersAdapter) GetMetrics() *types.AdapterMetrics {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	var lastSync *time.Time
+	if !a.lastSyncTime.IsZero() {
+		t := a.lastSyncTime
+		lastSync = &t
+	}
+
+	return &types.AdapterMetrics{
+		EntitiesManaged:   len(a.helpers),
+		ActionsExecuted:   a.actionsExecuted,
+		SuccessfulActions: a.successfulActions,
+		FailedActions:     a.failedActions,
+		LastSync:          lastSync,
+		Uptime:            time.Since(a.startTime),
+	}
+}
+
+// ========================================
+// Helper Management
+// ========================================
+
+// ListHelpers returns all helpers
+func (a *HelpersAdapter) ListHelpers() []*models.Helper {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	helpers := make([]*models.Helper, 0, len(a.helpers))
+	for _, helper := range a.helpers {
+		copied := *helper
+		helpers = append(helpers, &copied)
+	}
+	sort.Slice(helpers, func(i, j int) bool {
+		if helpers[i].Type != helpers[j].Type {
+			return helpers[i].Type < helpers[j].Type
+		}
+		return helpers[i].Name < helpers[j].Name
+	})
+	return helpers
+}
+
+// GetHelper returns a helper by its entity ID
+func (a *HelpersAdapter) GetHelper(entityID string) (*models.Helper, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	helper, ok := a.helpers[entityID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrHelperNotFound, entityID)
+	}
+	copied := *helper
+	return &copied, nil
+}
+
+// CreateHelper validates and stores a new helper and publishes its entity
+func (a *HelpersAdapter) CreateHelper(ctx context.Context, helper *models.Helper) error {
+	if err := normalize(helper); err != nil {
+		return err
+	}
+	helper.State = initialState(helper)
+	helper.TimerDuration, helper.TimerRemaining, helper.TimerFinishesAt = 0, 0, nil
+
+	a.mutex.RLock()
+	_, exists := a.helpers[EntityID(helper)]
+	for _, existing := range a.helpers {
+		exists = exists || existing.ID == helper.ID
+	}
+	a.mutex.RUnlock()
+	if exists {
+		return fmt.Errorf("%w: %s", ErrHelperExists, helper.ID)
+	}
+
+	if err := a.repo.CreateHelper(ctx, helper); err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	a.helpers[EntityID(helper)] = helper
+	snapshot := *helper
+	a.mutex.Unlock()
+
+	a.publish(buildEntity(&snapshot))
+	return nil
+}
+
+// UpdateHelper changes the name, icon and settings of a helper. The type cannot change; a
+// state that no longer fits the new settings is reset.
+func (a *HelpersAdapter) UpdateHelper(ctx context.Context, entityID string, update *models.Helper) (*models.Helper, error) {
+	a.mutex.RLock()
+	existing, ok := a.helpers[entityID]
+	var helper models.Helper
+	if ok {
+		helper = *existing
+	}
+	a.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrHelperNotFound, entityID)
+	}
+
+	helper.Name = update.Name
+	helper.Icon = update.Icon
+	helper.Config = update.Config
+	if err := normalize(&helper); err != nil {
+		return nil, err
+	}
+	if !validState(&helper) {
+		helper.State = initialState(&helper)
+	}
+
+	if err := a.repo.UpdateHelper(ctx, &helper); err != nil {
+		return nil, err
+	}
+	if err := a.repo.SaveHelperState(ctx, &helper); err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	stored, ok := a.helpers[entityID]
+	if ok {
+		stored.Name, stored.Icon, stored.Config, stored.State = helper.Name, helper.Icon, helper.Config, helper.State
+		stored.UpdatedAt = helper.UpdatedAt
+		helper = *stored
+	}
+	a.mutex.Unlock()
+
+	a.publish(buildEntity(&helper))
+	return &helper, nil
+}
+
+// DeleteHelper deletes a helper and removes its entity
+func (a *HelpersAdapter) DeleteHelper(ctx context.Context, entityID string) error {
+	a.mutex.RLock()
+	helper, ok := a.helpers[entityID]
+	a.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrHelperNotFound, entityID)
+	}
+
+	if err := a.repo.DeleteHelper(ctx, helper.ID); err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	if timer, ok := a.timers[entityID]; ok {
+		timer.Stop()
+		delete(a.timers, entityID)
+	}
+	delete(a.helpers, entityID)
+	onRemove := a.onRemove
+	a.mutex.Unlock()
+
+	if onRemove != nil {
+		onRemove(entityID)
+	}
+	return nil
+}
+
+// publish hands a changed entity to the event handler
+func (a *HelpersAdapter) publish(entity types.PMAEntity) {
+	a.mutex.RLock()
+	onChange := a.onChange
+	a.mutex.RUnlock()
+
+	if onChange != nil {
+		onChange(entity)
+	}
+}
+
+func (a *HelpersAdapter) calculateErrorRate() float64 {
+	if a.actionsExecuted == 0 {
+		return 0.0
+	}
+	return float64(a.failedActions) / float64(a.actionsExecuted)
+}
+
+func actionError(action types.PMAControlAction, code, message string) *types.PMAControlResult {
+	return &types.PMAControlResult{
+		Success:     false,
+		EntityID:    action.EntityID,
+		Action:      action.Action,
+		ProcessedAt: time.Now(),
+		Error: &types.PMAError{
+			Code:     code,
+			Message:  message,
+			Source:   string(types.SourceHelper),
+			EntityID: action.EntityID,
+		},
+	}
+}
@@ -0,0 +1,429 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// defaultMaxLength is the maximum length of input_text values without a configured limit
+const defaultMaxLength = 100
+
+var helperIDPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// helperActions lists the actions each helper type supports
+var helperActions = map[types.PMAEntityType][]string{
+	types.EntityTypeInputBoolean: {"turn_on", "turn_off", "toggle"},
+	types.EntityTypeInputNumber:  {"set_value", "increment", "decrement"},
+	types.EntityTypeInputSelect:  {"select_option", "select_next", "select_previous", "select_first", "select_last"},
+	types.EntityTypeInputText:    {"set_value"},
+	types.EntityTypeCounter:      {"increment", "decrement", "reset", "set_value"},
+	types.EntityTypeTimer:        {"start", "pause", "cancel", "finish"},
+}
+
+// HelperEntity is the entity of a helper
+type HelperEntity struct {
+	*types.PMABaseEntity
+}
+
+// GetAvailableActions returns the actions of the helper type
+func (h *HelperEntity) GetAvailableActions() []string {
+	return helperActions[h.Type]
+}
+
+// EntityID returns the entity ID of a helper, e.g. input_boolean.guest_mode
+func EntityID(helper *models.Helper) string {
+	return helper.Type + "." + helper.ID
+}
+
+// buildEntity converts a helper to its entity
+func buildEntity(helper *models.Helper) *HelperEntity {
+	entityType := types.PMAEntityType(helper.Type)
+	cfg := helper.Config
+
+	attributes := map[string]interface{}{
+		"helper_id": helper.ID,
+		"editable":  true,
+	}
+	switch entityType {
+	case types.EntityTypeInputNumber:
+		attributes["min"] = *cfg.Min
+		attributes["max"] = *cfg.Max
+		attributes["step"] = cfg.Step
+		attributes["mode"] = cfg.Mode
+		if cfg.Unit != "" {
+			attributes["unit_of_measurement"] = cfg.Unit
+		}
+	case types.EntityTypeInputSelect:
+		attributes["options"] = cfg.Options
+	case types.EntityTypeInputText:
+		attributes["max"] = cfg.MaxLength
+		attributes["pattern"] = cfg.Pattern
+		attributes["mode"] = cfg.Mode
+	case types.EntityTypeCounter:
+		attributes["step"] = cfg.Step
+		attributes["initial"] = cfg.Initial
+		if cfg.Min != nil {
+			attributes["minimum"] = *cfg.Min
+		}
+		if cfg.Max != nil {
+			attributes["maximum"] = *cfg.Max
+		}
+	case types.EntityTypeTimer:
+		attributes["duration"] = formatDuration(timerDefault(helper))
+		if helper.TimerDuration > 0 {
+			attributes["duration"] = formatDuration(time.Duration(helper.TimerDuration) * time.Second)
+		}
+		switch types.PMAEntityState(helper.State) {
+		case types.StateActive:
+			if helper.TimerFinishesAt != nil {
+				attributes["finishes_at"] = helper.TimerFinishesAt.UTC().Format(time.RFC3339)
+				attributes["remaining"] = formatDuration(time.Until(*helper.TimerFinishesAt))
+			}
+		case types.StatePaused:
+			attributes["remaining"] = formatDuration(time.Duration(helper.TimerRemaining) * time.Second)
+		}
+	}
+
+	now := time.Now()
+	return &HelperEntity{
+		PMABaseEntity: &types.PMABaseEntity{
+			ID:           EntityID(helper),
+			Type:         entityType,
+			FriendlyName: helper.Name,
+			Icon:         helper.Icon,
+			State:        types.PMAEntityState(helper.State),
+			Attributes:   attributes,
+			LastUpdated:  helper.UpdatedAt,
+			Capabilities: []types.PMACapability{},
+			Metadata: &types.PMAMetadata{
+				Source:         types.SourceHelper,
+				SourceEntityID: helper.ID,
+				LastSynced:     now,
+				QualityScore:   1.0,
+			},
+			Available: true,
+		},
+	}
+}
+
+// normalize validates a helper and fills in the defaults of its type
+func normalize(helper *models.Helper) error {
+	helper.ID = strings.TrimSpace(helper.ID)
+	helper.Name = strings.TrimSpace(helper.Name)
+	cfg := &helper.Config
+
+	if !helperIDPattern.MatchString(helper.ID) {
+		return fmt.Errorf("%w: id must contain only lowercase letters, digits and underscores", ErrInvalidHelper)
+	}
+	if helper.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidHelper)
+	}
+
+	switch types.PMAEntityType(helper.Type) {
+	case types.EntityTypeInputBoolean:
+		if cfg.Initial != "" && cfg.Initial != string(types.StateOn) && cfg.Initial != string(types.StateOff) {
+			return fmt.Errorf("%w: initial must be on or off", ErrInvalidHelper)
+		}
+	case types.EntityTypeInputNumber:
+		if cfg.Min == nil || cfg.Max == nil || *cfg.Min >= *cfg.Max {
+			return fmt.Errorf("%w: min and max are required and min must be below max", ErrInvalidHelper)
+		}
+		if cfg.Step <= 0 {
+			cfg.Step = 1
+		}
+		if cfg.Mode == "" {
+			cfg.Mode = "slider"
+		}
+		if cfg.Mode != "slider" && cfg.Mode != "box" {
+			return fmt.Errorf("%w: mode must be slider or box", ErrInvalidHelper)
+		}
+		if cfg.Initial != "" {
+			if value, err := strconv.ParseFloat(cfg.Initial, 64); err != nil || value < *cfg.Min || value > *cfg.Max {
+				return fmt.Errorf("%w: initial must be a number between min and max", ErrInvalidHelper)
+			}
+		}
+	case types.EntityTypeInputSelect:
+		if len(cfg.Options) == 0 {
+			return fmt.Errorf("%w: at least one option is required", ErrInvalidHelper)
+		}
+		seen := make(map[string]bool, len(cfg.Options))
+		for _, option := range cfg.Options {
+			if option == "" || seen[option] {
+				return fmt.Errorf("%w: options must be unique and not empty", ErrInvalidHelper)
+			}
+			seen[option] = true
+		}
+		if cfg.Initial != "" && !seen[cfg.Initial] {
+			return fmt.Errorf("%w: initial must be one of the options", ErrInvalidHelper)
+		}
+	case types.EntityTypeInputText:
+		if cfg.MaxLength <= 0 {
+			cfg.MaxLength = defaultMaxLength
+		}
+		if cfg.Mode == "" {
+			cfg.Mode = "text"
+		}
+		if cfg.Mode != "text" && cfg.Mode != "password" {
+			return fmt.Errorf("%w: mode must be text or password", ErrInvalidHelper)
+		}
+		if cfg.Pattern != "" {
+			if _, err := regexp.Compile(cfg.Pattern); err != nil {
+				return fmt.Errorf("%w: invalid pattern: %v", ErrInvalidHelper, err)
+			}
+		}
+		if err := checkText(cfg, cfg.Initial); cfg.Initial != "" && err != nil {
+			return fmt.Errorf("%w: initial: %v", ErrInvalidHelper, err)
+		}
+	case types.EntityTypeCounter:
+		if cfg.Step <= 0 {
+			cfg.Step = 1
+		}
+		if cfg.Min != nil && cfg.Max != nil && *cfg.Min >= *cfg.Max {
+			return fmt.Errorf("%w: minimum must be below maximum", ErrInvalidHelper)
+		}
+		if cfg.Initial == "" {
+			cfg.Initial = "0"
+		}
+		if _, err := strconv.ParseFloat(cfg.Initial, 64); err != nil {
+			return fmt.Errorf("%w: initial must be a number", ErrInvalidHelper)
+		}
+	case types.EntityTypeTimer:
+		if cfg.Duration != "" {
+			if d, err := parseTimerDuration(cfg.Duration); err != nil || d < 0 {
+				return fmt.Errorf("%w: invalid duration %q", ErrInvalidHelper, cfg.Duration)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: type must be input_boolean, input_number, input_select, input_text, counter or timer", ErrInvalidHelper)
+	}
+
+	return nil
+}
+
+// initialState returns the state a new or reset helper starts in
+func initialState(helper *models.Helper) string {
+	cfg := helper.Config
+	switch types.PMAEntityType(helper.Type) {
+	case types.EntityTypeInputBoolean:
+		if cfg.Initial != "" {
+			return cfg.Initial
+		}
+		return string(types.StateOff)
+	case types.EntityTypeInputNumber:
+		if cfg.Initial != "" {
+			return cfg.Initial
+		}
+		return formatNumber(*cfg.Min)
+	case types.EntityTypeInputSelect:
+		if cfg.Initial != "" {
+			return cfg.Initial
+		}
+		return cfg.Options[0]
+	case types.EntityTypeInputText:
+		return cfg.Initial
+	case types.EntityTypeCounter:
+		return cfg.Initial
+	case types.EntityTypeTimer:
+		return string(types.StateIdle)
+	}
+	return ""
+}
+
+// validState reports whether the state of a helper fits its settings
+func validState(helper *models.Helper) bool {
+	cfg := helper.Config
+	switch types.PMAEntityType(helper.Type) {
+	case types.EntityTypeInputNumber:
+		value, err := strconv.ParseFloat(helper.State, 64)
+		return err == nil && value >= *cfg.Min && value <= *cfg.Max
+	case types.EntityTypeInputSelect:
+		return indexOf(cfg.Options, helper.State) >= 0
+	case types.EntityTypeInputText:
+		return checkText(&cfg, helper.State) == nil
+	case types.EntityTypeCounter:
+		value, err := strconv.ParseFloat(helper.State, 64)
+		return err == nil && (cfg.Min == nil || value >= *cfg.Min) && (cfg.Max == nil || value <= *cfg.Max)
+	}
+	return true
+}
+
+// apply changes a helper according to an action. Timer actions return the timer event to fire.
+func (a *HelpersAdapter) apply(helper *models.Helper, action types.PMAControlAction) (string, error) {
+	cfg := helper.Config
+
+	switch types.PMAEntityType(helper.Type) {
+	case types.EntityTypeInputBoolean:
+		switch action.Action {
+		case "turn_on":
+			helper.State = string(types.StateOn)
+		case "turn_off":
+			helper.State = string(types.StateOff)
+		case "toggle":
+			if helper.State == string(types.StateOn) {
+				helper.State = string(types.StateOff)
+			} else {
+				helper.State = string(types.StateOn)
+			}
+		default:
+			return "", unsupported(helper, action)
+		}
+
+	case types.EntityTypeInputNumber:
+		current, _ := strconv.ParseFloat(helper.State, 64)
+		switch action.Action {
+		case "set_value":
+			value, err := numberParameter(action, "value")
+			if err != nil {
+				return "", err
+			}
+			if value < *cfg.Min || value > *cfg.Max {
+				return "", fmt.Errorf("value %s is outside %s..%s", formatNumber(value), formatNumber(*cfg.Min), formatNumber(*cfg.Max))
+			}
+			helper.State = formatNumber(value)
+		case "increment":
+			helper.State = formatNumber(clamp(current+cfg.Step, cfg.Min, cfg.Max))
+		case "decrement":
+			helper.State = formatNumber(clamp(current-cfg.Step, cfg.Min, cfg.Max))
+		default:
+			return "", unsupported(helper, action)
+		}
+
+	case types.EntityTypeInputSelect:
+		index := indexOf(cfg.Options, helper.State)
+		cycle := true
+		if value, ok := action.Parameters["cycle"].(bool); ok {
+			cycle = value
+		}
+		switch action.Action {
+		case "select_option":
+			option, _ := action.Parameters["option"].(string)
+			if indexOf(cfg.Options, option) < 0 {
+				return "", fmt.Errorf("%q is not an option", option)
+			}
+			helper.State = option
+		case "select_next":
+			if index+1 < len(cfg.Options) {
+				helper.State = cfg.Options[index+1]
+			} else if cycle {
+				helper.State = cfg.Options[0]
+			}
+		case "select_previous":
+			if index > 0 {
+				helper.State = cfg.Options[index-1]
+			} else if cycle || index < 0 {
+				helper.State = cfg.Options[len(cfg.Options)-1]
+			}
+		case "select_first":
+			helper.State = cfg.Options[0]
+		case "select_last":
+			helper.State = cfg.Options[len(cfg.Options)-1]
+		default:
+			return "", unsupported(helper, action)
+		}
+
+	case types.EntityTypeInputText:
+		if action.Action != "set_value" {
+			return "", unsupported(helper, action)
+		}
+		value, ok := action.Parameters["value"].(string)
+		if !ok {
+			return "", fmt.Errorf("parameter value must be a string")
+		}
+		if err := checkText(&cfg, value); err != nil {
+			return "", err
+		}
+		helper.State = value
+
+	case types.EntityTypeCounter:
+		current, _ := strconv.ParseFloat(helper.State, 64)
+		switch action.Action {
+		case "increment":
+			helper.State = formatNumber(clamp(current+cfg.Step, cfg.Min, cfg.Max))
+		case "decrement":
+			helper.State = formatNumber(clamp(current-cfg.Step, cfg.Min, cfg.Max))
+		case "reset":
+			helper.State = cfg.Initial
+		case "set_value":
+			value, err := numberParameter(action, "value")
+			if err != nil {
+				return "", err
+			}
+			if (cfg.Min != nil && value < *cfg.Min) || (cfg.Max != nil && value > *cfg.Max) {
+				return "", fmt.Errorf("value %s is outside the counter bounds", formatNumber(value))
+			}
+			helper.State = formatNumber(value)
+		default:
+			return "", unsupported(helper, action)
+		}
+
+	case types.EntityTypeTimer:
+		return applyTimer(helper, action)
+	}
+
+	return "", nil
+}
+
+func unsupported(helper *models.Helper, action types.PMAControlAction) error {
+	return fmt.Errorf("action %s not supported by %s", action.Action, helper.Type)
+}
+
+// numberParameter reads a numeric action parameter, accepting numbers and numeric strings
+func numberParameter(action types.PMAControlAction, name string) (float64, error) {
+	switch v := action.Parameters[name].(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		if value, err := strconv.ParseFloat(v, 64); err == nil {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("parameter %s must be a number", name)
+}
+
+// checkText validates an input_text value against the length limit and pattern
+func checkText(cfg *models.HelperConfig, value string) error {
+	if cfg.MaxLength > 0 && len([]rune(value)) > cfg.MaxLength {
+		return fmt.Errorf("value is longer than %d characters", cfg.MaxLength)
+	}
+	if cfg.Pattern != "" {
+		pattern, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("value does not match pattern %s", cfg.Pattern)
+		}
+	}
+	return nil
+}
+
+func clamp(value float64, min, max *float64) float64 {
+	if min != nil && value < *min {
+		return *min
+	}
+	if max != nil && value > *max {
+		return *max
+	}
+	return value
+}
+
+func indexOf(options []string, value string) int {
+	for i, option := range options {
+		if option == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
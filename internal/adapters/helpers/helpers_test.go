@@ -0,0 +1,264 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+// applyAll applies the actions in order and returns the resulting states
+func applyAll(t *testing.T, helper *models.Helper, actions ...types.PMAControlAction) []string {
+	t.Helper()
+	adapter := &HelpersAdapter{}
+
+	var states []string
+	for _, action := range actions {
+		_, err := adapter.apply(helper, action)
+		require.NoError(t, err, action.Action)
+		states = append(states, helper.State)
+	}
+	return states
+}
+
+func action(name string, parameters map[string]interface{}) types.PMAControlAction {
+	return types.PMAControlAction{Action: name, Parameters: parameters}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		helper  models.Helper
+		wantErr bool
+	}{
+		{"valid boolean", models.Helper{ID: "guest_mode", Name: "Guest", Type: "input_boolean"}, false},
+		{"invalid id", models.Helper{ID: "Guest Mode", Name: "Guest", Type: "input_boolean"}, true},
+		{"missing name", models.Helper{ID: "guest_mode", Name: "  ", Type: "input_boolean"}, true},
+		{"unknown type", models.Helper{ID: "x", Name: "X", Type: "input_datetime"}, true},
+		{"boolean initial", models.Helper{ID: "x", Name: "X", Type: "input_boolean", Config: models.HelperConfig{Initial: "yes"}}, true},
+		{"number without range", models.Helper{ID: "x", Name: "X", Type: "input_number"}, true},
+		{"number with inverted range", models.Helper{ID: "x", Name: "X", Type: "input_number", Config: models.HelperConfig{Min: float(10), Max: float(0)}}, true},
+		{"number initial out of range", models.Helper{ID: "x", Name: "X", Type: "input_number", Config: models.HelperConfig{Min: float(0), Max: float(10), Initial: "11"}}, true},
+		{"number mode", models.Helper{ID: "x", Name: "X", Type: "input_number", Config: models.HelperConfig{Min: float(0), Max: float(10), Mode: "dial"}}, true},
+		{"select without options", models.Helper{ID: "x", Name: "X", Type: "input_select"}, true},
+		{"select duplicate options", models.Helper{ID: "x", Name: "X", Type: "input_select", Config: models.HelperConfig{Options: []string{"a", "a"}}}, true},
+		{"select initial not an option", models.Helper{ID: "x", Name: "X", Type: "input_select", Config: models.HelperConfig{Options: []string{"a"}, Initial: "b"}}, true},
+		{"text invalid pattern", models.Helper{ID: "x", Name: "X", Type: "input_text", Config: models.HelperConfig{Pattern: "("}}, true},
+		{"text initial too long", models.Helper{ID: "x", Name: "X", Type: "input_text", Config: models.HelperConfig{MaxLength: 2, Initial: "abc"}}, true},
+		{"counter inverted bounds", models.Helper{ID: "x", Name: "X", Type: "counter", Config: models.HelperConfig{Min: float(5), Max: float(5)}}, true},
+		{"timer clock duration", models.Helper{ID: "x", Name: "X", Type: "timer", Config: models.HelperConfig{Duration: "00:05:00"}}, false},
+		{"timer invalid duration", models.Helper{ID: "x", Name: "X", Type: "timer", Config: models.HelperConfig{Duration: "soon"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalize(&tt.helper)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidHelper), "got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeDefaults(t *testing.T) {
+	number := &models.Helper{ID: "volume", Name: "Volume", Type: "input_number", Config: models.HelperConfig{Min: float(0), Max: float(100)}}
+	require.NoError(t, normalize(number))
+	assert.Equal(t, 1.0, number.Config.Step)
+	assert.Equal(t, "slider", number.Config.Mode)
+	assert.Equal(t, "0", initialState(number))
+
+	text := &models.Helper{ID: "note", Name: "Note", Type: "input_text"}
+	require.NoError(t, normalize(text))
+	assert.Equal(t, defaultMaxLength, text.Config.MaxLength)
+	assert.Equal(t, "text", text.Config.Mode)
+
+	counter := &models.Helper{ID: "visits", Name: "Visits", Type: "counter"}
+	require.NoError(t, normalize(counter))
+	assert.Equal(t, "0", initialState(counter))
+
+	selectHelper := &models.Helper{ID: "mode", Name: "Mode", Type: "input_select", Config: models.HelperConfig{Options: []string{"home", "away"}}}
+	require.NoError(t, normalize(selectHelper))
+	assert.Equal(t, "home", initialState(selectHelper))
+}
+
+func TestApplyInputNumber(t *testing.T) {
+	helper := &models.Helper{Type: "input_number", State: "8", Config: models.HelperConfig{Min: float(0), Max: float(10), Step: 1.5}}
+
+	states := applyAll(t, helper,
+		action("increment", nil),
+		action("increment", nil),
+		action("decrement", nil),
+		action("set_value", map[string]interface{}{"value": "0.5"}),
+		action("decrement", nil),
+	)
+	assert.Equal(t, []string{"9.5", "10", "8.5", "0.5", "0"}, states, "steps are clamped to the range")
+
+	_, err := (&HelpersAdapter{}).apply(helper, action("set_value", map[string]interface{}{"value": 11.0}))
+	assert.Error(t, err, "set_value is rejected outside the range, not clamped")
+	assert.Equal(t, "0", helper.State)
+
+	_, err = (&HelpersAdapter{}).apply(helper, action("set_value", map[string]interface{}{"value": "high"}))
+	assert.Error(t, err)
+}
+
+func TestApplyCounter(t *testing.T) {
+	t.Run("bounded", func(t *testing.T) {
+		helper := &models.Helper{Type: "counter", State: "1", Config: models.HelperConfig{Min: float(0), Max: float(3), Step: 2, Initial: "1"}}
+
+		states := applyAll(t, helper,
+			action("increment", nil),
+			action("increment", nil),
+			action("decrement", nil),
+			action("decrement", nil),
+			action("decrement", nil),
+			action("reset", nil),
+		)
+		assert.Equal(t, []string{"3", "3", "1", "0", "0", "1"}, states)
+
+		_, err := (&HelpersAdapter{}).apply(helper, action("set_value", map[string]interface{}{"value": 4}))
+		assert.Error(t, err)
+	})
+
+	t.Run("unbounded", func(t *testing.T) {
+		helper := &models.Helper{Type: "counter", State: "0", Config: models.HelperConfig{Step: 1}}
+		states := applyAll(t, helper, action("decrement", nil), action("set_value", map[string]interface{}{"value": 1000}))
+		assert.Equal(t, []string{"-1", "1000"}, states)
+	})
+}
+
+func TestApplyInputSelect(t *testing.T) {
+	options := []string{"low", "medium", "high"}
+
+	helper := &models.Helper{Type: "input_select", State: "high", Config: models.HelperConfig{Options: options}}
+	states := applyAll(t, helper,
+		action("select_next", nil),
+		action("select_previous", nil),
+		action("select_last", nil),
+		action("select_next", map[string]interface{}{"cycle": false}),
+		action("select_first", nil),
+		action("select_previous", map[string]interface{}{"cycle": false}),
+		action("select_option", map[string]interface{}{"option": "medium"}),
+	)
+	assert.Equal(t, []string{"low", "high", "high", "high", "low", "low", "medium"}, states)
+
+	_, err := (&HelpersAdapter{}).apply(helper, action("select_option", map[string]interface{}{"option": "max"}))
+	assert.Error(t, err)
+}
+
+func TestApplyInputText(t *testing.T) {
+	helper := &models.Helper{Type: "input_text", Config: models.HelperConfig{MaxLength: 5, Pattern: "^[a-z]*$"}}
+
+	assert.Equal(t, []string{"hello"}, applyAll(t, helper, action("set_value", map[string]interface{}{"value": "hello"})))
+
+	adapter := &HelpersAdapter{}
+	_, err := adapter.apply(helper, action("set_value", map[string]interface{}{"value": "toolong"}))
+	assert.Error(t, err)
+	_, err = adapter.apply(helper, action("set_value", map[string]interface{}{"value": "ABC"}))
+	assert.Error(t, err)
+	_, err = adapter.apply(helper, action("set_value", map[string]interface{}{"value": 5}))
+	assert.Error(t, err)
+	assert.Equal(t, "hello", helper.State)
+}
+
+func TestApplyUnsupported(t *testing.T) {
+	_, err := (&HelpersAdapter{}).apply(&models.Helper{Type: "input_boolean", State: "off"}, action("increment", nil))
+	assert.Error(t, err)
+}
+
+func TestValidState(t *testing.T) {
+	number := models.Helper{Type: "input_number", Config: models.HelperConfig{Min: float(0), Max: float(10)}}
+	number.State = "5"
+	assert.True(t, validState(&number))
+	number.State = "11"
+	assert.False(t, validState(&number))
+
+	counter := models.Helper{Type: "counter", State: "-5", Config: models.HelperConfig{Max: float(10)}}
+	assert.True(t, validState(&counter))
+	counter.State = "abc"
+	assert.False(t, validState(&counter))
+
+	selectHelper := models.Helper{Type: "input_select", State: "gone", Config: models.HelperConfig{Options: []string{"a"}}}
+	assert.False(t, validState(&selectHelper))
+}
+
+func TestApplyTimer(t *testing.T) {
+	helper := &models.Helper{Type: "timer", State: string(types.StateIdle), Config: models.HelperConfig{Duration: "5m"}}
+
+	event, err := applyTimer(helper, action("start", nil))
+	require.NoError(t, err)
+	assert.Equal(t, automation.EventTypeTimerStarted, event)
+	assert.Equal(t, string(types.StateActive), helper.State)
+	assert.Equal(t, 300, helper.TimerDuration)
+	require.NotNil(t, helper.TimerFinishesAt)
+
+	event, err = applyTimer(helper, action("pause", nil))
+	require.NoError(t, err)
+	assert.Equal(t, automation.EventTypeTimerPaused, event)
+	assert.Equal(t, string(types.StatePaused), helper.State)
+	assert.InDelta(t, 300, helper.TimerRemaining, 1)
+
+	// Resuming keeps the remaining time and the original duration
+	helper.TimerRemaining = 120
+	_, err = applyTimer(helper, action("start", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 300, helper.TimerDuration)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), *helper.TimerFinishesAt, 2*time.Second)
+
+	// An explicit duration restarts the timer
+	_, err = applyTimer(helper, action("start", map[string]interface{}{"duration": "00:00:30"}))
+	require.NoError(t, err)
+	assert.Equal(t, 30, helper.TimerDuration)
+
+	event, err = applyTimer(helper, action("finish", nil))
+	require.NoError(t, err)
+	assert.Equal(t, automation.EventTypeTimerFinished, event)
+	assert.Equal(t, string(types.StateIdle), helper.State)
+	assert.Nil(t, helper.TimerFinishesAt)
+
+	_, err = applyTimer(helper, action("pause", nil))
+	assert.Error(t, err, "an idle timer cannot be paused")
+	event, err = applyTimer(helper, action("cancel", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, event, "cancelling an idle timer fires nothing")
+
+	_, err = applyTimer(&models.Helper{Type: "timer", State: string(types.StateIdle)}, action("start", nil))
+	assert.Error(t, err, "a timer needs a duration")
+}
+
+func TestParseTimerDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"1h30m", 90 * time.Minute, false},
+		{"01:30:00", 90 * time.Minute, false},
+		{"05:30", 5*time.Minute + 30*time.Second, false},
+		{"1:2:3:4", 0, true},
+		{"later", 0, true},
+	}
+
+	for _, tt := range tests {
+		d, err := parseTimerDuration(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, d, tt.value)
+	}
+
+	assert.Equal(t, "1:30:05", formatDuration(90*time.Minute+5*time.Second))
+	assert.Equal(t, "0:00:00", formatDuration(-time.Second))
+}
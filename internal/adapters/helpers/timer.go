@@ -0,0 +1,214 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// applyTimer changes a timer according to an action and returns the timer event to fire
+func applyTimer(helper *models.Helper, action types.PMAControlAction) (string, error) {
+	now := time.Now().UTC()
+	state := types.PMAEntityState(helper.State)
+
+	switch action.Action {
+	case "start":
+		duration, given, err := durationParameter(action)
+		if err != nil {
+			return "", err
+		}
+		if !given && state == types.StatePaused {
+			// Resume with the remaining time
+			duration = time.Duration(helper.TimerRemaining) * time.Second
+		} else {
+			if !given {
+				duration = timerDefault(helper)
+			}
+			helper.TimerDuration = int(duration / time.Second)
+		}
+		if duration <= 0 {
+			return "", fmt.Errorf("timer has no duration")
+		}
+		finishesAt := now.Add(duration)
+		helper.State = string(types.StateActive)
+		helper.TimerRemaining = int(duration / time.Second)
+		helper.TimerFinishesAt = &finishesAt
+		return automation.EventTypeTimerStarted, nil
+
+	case "pause":
+		if state != types.StateActive || helper.TimerFinishesAt == nil {
+			return "", fmt.Errorf("timer is not running")
+		}
+		remaining := helper.TimerFinishesAt.Sub(now)
+		if remaining < 0 {
+			remaining = 0
+		}
+		helper.State = string(types.StatePaused)
+		helper.TimerRemaining = int(remaining.Round(time.Second) / time.Second)
+		helper.TimerFinishesAt = nil
+		return automation.EventTypeTimerPaused, nil
+
+	case "cancel":
+		if state == types.StateIdle {
+			return "", nil
+		}
+		resetTimer(helper)
+		return automation.EventTypeTimerCancelled, nil
+
+	case "finish":
+		if state == types.StateIdle {
+			return "", fmt.Errorf("timer is not running")
+		}
+		resetTimer(helper)
+		return automation.EventTypeTimerFinished, nil
+	}
+
+	return "", unsupported(helper, action)
+}
+
+// resetTimer puts a timer back to idle
+func resetTimer(helper *models.Helper) {
+	helper.State = string(types.StateIdle)
+	helper.TimerRemaining = 0
+	helper.TimerFinishesAt = nil
+}
+
+// durationParameter reads the optional duration of a start action, given as a duration string
+// such as "5m" or "00:05:00", or as a number of seconds
+func durationParameter(action types.PMAControlAction) (time.Duration, bool, error) {
+	switch v := action.Parameters["duration"].(type) {
+	case nil:
+		return 0, false, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), true, nil
+	case int:
+		return time.Duration(v) * time.Second, true, nil
+	case string:
+		d, err := parseTimerDuration(v)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid duration %q", v)
+		}
+		return d, true, nil
+	}
+	return 0, false, fmt.Errorf("parameter duration must be a duration or a number of seconds")
+}
+
+// parseTimerDuration parses a Go duration such as "1h30m" or a clock duration such as "01:30:00"
+func parseTimerDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, ":") {
+		return time.ParseDuration(value)
+	}
+
+	var hours, minutes, seconds int
+	parts := strings.Split(value, ":")
+	var err error
+	switch len(parts) {
+	case 2:
+		_, err = fmt.Sscanf(value, "%d:%d", &minutes, &seconds)
+	case 3:
+		_, err = fmt.Sscanf(value, "%d:%d:%d", &hours, &minutes, &seconds)
+	default:
+		err = fmt.Errorf("invalid duration %q", value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second, nil
+}
+
+// formatDuration formats a duration as H:MM:SS
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// timerDefault returns the configured duration of a timer
+func timerDefault(helper *models.Helper) time.Duration {
+	if helper.Config.Duration == "" {
+		return 0
+	}
+	d, err := parseTimerDuration(helper.Config.Duration)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// scheduleTimer finishes an active timer when it runs out. Must be called with the mutex held.
+func (a *HelpersAdapter) scheduleTimer(helper *models.Helper) {
+	entityID := EntityID(helper)
+	finishesAt := *helper.TimerFinishesAt
+
+	delay := time.Until(finishesAt)
+	if delay < 0 {
+		delay = 0
+	}
+	a.timers[entityID] = time.AfterFunc(delay, func() {
+		a.finishTimer(entityID, finishesAt)
+	})
+}
+
+// rescheduleTimer replaces the scheduled finish of a timer after it changed. Must be called
+// with the mutex held.
+func (a *HelpersAdapter) rescheduleTimer(helper *models.Helper) {
+	entityID := EntityID(helper)
+	if timer, ok := a.timers[entityID]; ok {
+		timer.Stop()
+		delete(a.timers, entityID)
+	}
+	if helper.State == string(types.StateActive) && helper.TimerFinishesAt != nil {
+		a.scheduleTimer(helper)
+	}
+}
+
+// finishTimer puts a timer that ran out back to idle, unless it was changed in the meantime
+func (a *HelpersAdapter) finishTimer(entityID string, finishesAt time.Time) {
+	a.mutex.Lock()
+	helper, ok := a.helpers[entityID]
+	if !ok || helper.State != string(types.StateActive) || helper.TimerFinishesAt == nil ||
+		!helper.TimerFinishesAt.Equal(finishesAt) {
+		a.mutex.Unlock()
+		return
+	}
+	delete(a.timers, entityID)
+	resetTimer(helper)
+	snapshot := *helper
+	a.mutex.Unlock()
+
+	if err := a.repo.SaveHelperState(context.Background(), &snapshot); err != nil {
+		a.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to store finished timer")
+	}
+
+	a.logger.WithField("entity_id", entityID).Debug("Timer finished")
+	a.publish(buildEntity(&snapshot))
+	a.fireTimerEvent(&snapshot, automation.EventTypeTimerFinished)
+}
+
+// fireTimerEvent hands a timer event to the timer event handler
+func (a *HelpersAdapter) fireTimerEvent(helper *models.Helper, event string) {
+	a.mutex.RLock()
+	onTimerEvent := a.onTimerEvent
+	a.mutex.RUnlock()
+	if onTimerEvent == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"entity_id": EntityID(helper),
+		"duration":  formatDuration(time.Duration(helper.TimerDuration) * time.Second),
+		"remaining": formatDuration(time.Duration(helper.TimerRemaining) * time.Second),
+	}
+	if helper.TimerFinishesAt != nil {
+		data["finishes_at"] = helper.TimerFinishesAt.UTC().Format(time.RFC3339)
+	}
+	onTimerEvent(EntityID(helper), event, data)
+}
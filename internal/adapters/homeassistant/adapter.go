@@ -3,7 +3,9 @@ package homeassistant
 import (
 	"context"
 	"fmt"
+	"io"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -807,6 +809,57 @@ func (a *HomeAssistantAdapter) updateAverageResponseTime(duration time.Duration)
 	}
 }
 
+// GetCameraSnapshot fetches the current image of a Home Assistant camera entity
+func (a *HomeAssistantAdapter) GetCameraSnapshot(ctx context.Context, entityID string) (io.ReadCloser, string, error) {
+	if !a.IsConnected() {
+		return nil, "", fmt.Errorf("adapter not connected")
+	}
+
+	return a.client.GetCameraImage(ctx, a.convertPMAEntityIDToHA(entityID))
+}
+
+// GetConfigs fetches the configuration of every automation, script or scene (domain) that Home
+// Assistant stores in its config files. Items defined elsewhere, e.g. in packages, are not
+// editable through the config API and are skipped.
+func (a *HomeAssistantAdapter) GetConfigs(ctx context.Context, domain string) ([]map[string]interface{}, error) {
+	if !a.IsConnected() {
+		return nil, fmt.Errorf("adapter not connected")
+	}
+
+	entities, err := a.client.GetAllEntitiesHTTPOnly(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s entities: %w", domain, err)
+	}
+
+	var configs []map[string]interface{}
+	for _, entity := range entities {
+		if !strings.HasPrefix(entity.EntityID, domain+".") {
+			continue
+		}
+
+		// Automations and scenes are stored by their id attribute, scripts by object ID
+		id, _ := entity.Attributes["id"].(string)
+		if domain == "script" {
+			id = strings.TrimPrefix(entity.EntityID, "script.")
+		}
+		if id == "" {
+			continue
+		}
+
+		config, err := a.client.GetConfig(ctx, domain, id)
+		if err != nil {
+			a.logger.WithError(err).WithField("entity_id", entity.EntityID).Debug("Skipping configuration not available through the config API")
+			continue
+		}
+		if _, ok := config["id"]; !ok {
+			config["id"] = id
+		}
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
+
 func (a *HomeAssistantAdapter) convertPMAEntityIDToHA(pmaEntityID string) string {
 	// Remove "ha_" prefix if present
 	if len(pmaEntityID) > 3 && pmaEntityID[:3] == "ha_" {
@@ -344,6 +344,61 @@ func (c *HAClientWrapper) CallService(ctx context.Context, domain, service, enti
 	return nil
 }
 
+// GetCameraImage fetches the current still image of a camera entity through the camera proxy
+func (c *HAClientWrapper) GetCameraImage(ctx context.Context, entityID string) (io.ReadCloser, string, error) {
+	url := fmt.Sprintf("%s/api/camera_proxy/%s", c.baseURL, entityID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("camera proxy request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+// GetConfig fetches the stored configuration of an automation, script or scene from the config API
+func (c *HAClientWrapper) GetConfig(ctx context.Context, domain, id string) (map[string]interface{}, error) {
+	url := fmt.Sprintf("%s/api/config/%s/config/%s", c.baseURL, domain, id)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("config request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var config map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
 // GetAllEntitiesHTTPOnly fetches entities using only HTTP API, no WebSocket required
 func (c *HAClientWrapper) GetAllEntitiesHTTPOnly(ctx context.Context) ([]*HAEntity, error) {
 	url := fmt.Sprintf("%s/api/states", c.baseURL)
@@ -3,6 +3,8 @@ package ring
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -366,22 +368,16 @@ func (a *RingAdapter) checkForEvents() {
 
 // processRingEvent converts a Ring event to a device event and emits it
 func (a *RingAdapter) processRingEvent(ringEvent RingEvent) {
-	deviceID := fmt.Sprintf("ring-doorbell-%d", ringEvent.DoorbotID)
+	// Doorbells and cameras share the same ID scheme (see NewRingDoorbell/NewRingCamera)
+	deviceID := fmt.Sprintf("ring_%d", ringEvent.DoorbotID)
 
-	// Check if it's a camera instead
 	a.mutex.RLock()
-	if device, exists := a.devices[deviceID]; !exists {
-		// Try camera ID format
-		deviceID = fmt.Sprintf("ring-camera-%d", ringEvent.DoorbotID)
-		if _, exists := a.devices[deviceID]; !exists {
-			a.mutex.RUnlock()
-			a.logger.WithField("doorbot_id", ringEvent.DoorbotID).Debug("Event for unknown device")
-			return
-		}
-	} else {
-		_ = device // Use the device variable to avoid unused variable error
-	}
+	_, exists := a.devices[deviceID]
 	a.mutex.RUnlock()
+	if !exists {
+		a.logger.WithField("doorbot_id", ringEvent.DoorbotID).Debug("Event for unknown device")
+		return
+	}
 
 	// Determine event type
 	var eventType devices.EventType
@@ -410,6 +406,10 @@ func (a *RingAdapter) processRingEvent(ringEvent RingEvent) {
 	if ringEvent.RecordingURL != "" {
 		eventData["recording_url"] = ringEvent.RecordingURL
 	}
+	if ringEvent.IDStr != "" {
+		eventData["ring_event_id_str"] = ringEvent.IDStr
+	}
+	eventData["has_recording"] = ringEvent.HasRecording
 	if ringEvent.StreamingURL != "" {
 		eventData["streaming_url"] = ringEvent.StreamingURL
 	}
@@ -448,6 +448,38 @@ func (a *RingAdapter) emitEvent(event devices.DeviceEvent) {
 	}
 }
 
+// GetCameraSnapshot fetches the current snapshot image of a Ring camera or doorbell
+func (a *RingAdapter) GetCameraSnapshot(ctx context.Context, entityID string) (io.ReadCloser, string, error) {
+	if !a.IsConnected() {
+		return nil, "", fmt.Errorf("adapter not connected")
+	}
+
+	snapshotURL, err := a.client.GetSnapshot(ctx, strings.TrimPrefix(entityID, "ring_"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to request Ring snapshot: %w", err)
+	}
+	if snapshotURL == "" {
+		return nil, "", fmt.Errorf("no snapshot available for %s", entityID)
+	}
+
+	return a.client.DownloadMedia(ctx, snapshotURL)
+}
+
+// DownloadEventRecording fetches the recording of a Ring event. Ring only makes a recording
+// available some time after the event, so callers should retry on failure.
+func (a *RingAdapter) DownloadEventRecording(ctx context.Context, eventID string) (io.ReadCloser, string, error) {
+	if !a.IsConnected() {
+		return nil, "", fmt.Errorf("adapter not connected")
+	}
+
+	recordingURL, err := a.client.GetRecordingURL(ctx, eventID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return a.client.DownloadMedia(ctx, recordingURL)
+}
+
 // UpdateConfig updates the adapter configuration
 func (a *RingAdapter) UpdateConfig(config RingAdapterConfig) error {
 	a.mutex.Lock()
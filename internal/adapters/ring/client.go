@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -373,6 +374,61 @@ func (c *RingClient) GetSnapshot(ctx context.Context, deviceID string) (string,
 	return result.URL, nil
 }
 
+// GetRecordingURL retrieves a temporary download URL for the recording of an event
+func (c *RingClient) GetRecordingURL(ctx context.Context, eventID string) (string, error) {
+	path := fmt.Sprintf("/clients_api/dings/%s/share/download?disable_redirect=true", eventID)
+
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		URL string `json:"url"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode recording response: %w", err)
+	}
+
+	if result.URL == "" {
+		return "", fmt.Errorf("recording for event %s is not available yet", eventID)
+	}
+
+	return result.URL, nil
+}
+
+// DownloadMedia opens a snapshot or recording. Paths relative to the Ring API are fetched
+// with the account token, absolute URLs are pre-signed and fetched as is.
+func (c *RingClient) DownloadMedia(ctx context.Context, mediaURL string) (io.ReadCloser, string, error) {
+	if strings.HasPrefix(mediaURL, "/") {
+		resp, err := c.makeAuthenticatedRequest(ctx, "GET", mediaURL, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", ringUserAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("media download failed with status %d", resp.StatusCode)
+	}
+
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
 // GetLiveStreamURL retrieves a live stream URL for a device
 func (c *RingClient) GetLiveStreamURL(ctx context.Context, deviceID string) (string, error) {
 	path := fmt.Sprintf("/clients_api/dings/active/%s", deviceID)
@@ -29,6 +29,16 @@ type ShellyAdapter struct {
 	discoveredDevices map[string]*EnhancedShellyDevice
 	lastDiscoverySync time.Time
 	ctx               context.Context
+
+	// Realtime push
+	eventHandler       StateChangeHandler
+	deviceEventHandler DeviceEventHandler
+	pushMutex          sync.RWMutex
+	pushConnections    map[string]int       // device ID -> open outbound WebSocket connections
+	pushConfigured     map[string]bool      // devices pointed at PMA
+	pushAttempts       map[string]time.Time // last failed configuration attempt per device
+	pushMessages       int
+	lastPush           time.Time
 }
 
 // ShellyAdapterConfig holds configuration for the Shelly adapter
@@ -56,6 +66,12 @@ type ShellyAdapterConfig struct {
 	ExcludeLoopback           bool     `json:"exclude_loopback"`
 	ExcludeDockerInterfaces   bool     `json:"exclude_docker_interfaces"`
 	MinSubnetSize             int      `json:"min_subnet_size"`
+
+	// Realtime push configuration
+	PushEnabled       bool   `json:"push_enabled"`
+	PushBaseURL       string `json:"push_base_url"`
+	PushToken         string `json:"push_token"`
+	AutoConfigurePush bool   `json:"auto_configure_push"`
 }
 
 // NewShellyAdapter creates a new Shelly adapter with enhanced discovery
@@ -108,6 +124,9 @@ func NewShellyAdapter(config ShellyAdapterConfig, logger *logrus.Logger, debugLo
 		startTime:         time.Now(),
 		discoveredDevices: make(map[string]*EnhancedShellyDevice),
 		ctx:               context.Background(),
+		pushConnections:   make(map[string]int),
+		pushConfigured:    make(map[string]bool),
+		pushAttempts:      make(map[string]time.Time),
 	}
 }
 
@@ -445,6 +464,7 @@ func (a *ShellyAdapter) GetAdapterStats() map[string]interface{} {
 		"gen2_devices":       stats["gen2_devices"].(int),
 		"discovery_running":  stats["discovery_running"].(bool),
 		"last_discovery":     stats["last_discovery"].(time.Time),
+		"push":               a.GetPushStats(),
 	}
 }
 
@@ -518,7 +538,7 @@ func (a *ShellyAdapter) GetSupportedEntityTypes() []types.PMAEntityType {
 
 // SupportsRealtime returns whether Shelly supports real-time updates
 func (a *ShellyAdapter) SupportsRealtime() bool {
-	return false // Shelly uses HTTP polling
+	return a.config.PushEnabled // devices push over WebSocket/action URLs, otherwise HTTP polling
 }
 
 // SyncRooms synchronizes rooms from Shelly (not supported)
@@ -612,6 +632,7 @@ func (s *ShellyAdapter) runDeviceSync() {
 			if err := s.RefreshDevices(s.ctx); err != nil {
 				s.logger.WithError(err).Error("Failed to refresh devices during periodic sync")
 			}
+			s.configurePendingDevices(s.ctx)
 		}
 	}
 }
@@ -0,0 +1,162 @@
+package shelly
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Firmware release channels
+const (
+	FirmwareStageStable = "stable"
+	FirmwareStageBeta   = "beta"
+)
+
+// FirmwareInfo describes the installed and available firmware of a device
+type FirmwareInfo struct {
+	DeviceID        string           `json:"device_id"`
+	Name            string           `json:"name"`
+	Model           string           `json:"model"`
+	IP              string           `json:"ip"`
+	Generation      DeviceGeneration `json:"generation"`
+	CurrentVersion  string           `json:"current_version"`
+	StableVersion   string           `json:"stable_version,omitempty"`
+	BetaVersion     string           `json:"beta_version,omitempty"`
+	UpdateAvailable bool             `json:"update_available"`
+	Updating        bool             `json:"updating"`
+	Error           string           `json:"error,omitempty"`
+	CheckedAt       time.Time        `json:"checked_at"`
+}
+
+// AvailableVersion returns the version an update on the given stage would install, or ""
+func (f *FirmwareInfo) AvailableVersion(stage string) string {
+	if stage == FirmwareStageBeta && f.BetaVersion != "" {
+		return f.BetaVersion
+	}
+	return f.StableVersion
+}
+
+// CheckFirmwareUpdate asks a device for available firmware updates. Gen1 devices use
+// /ota/check and /ota, Gen2+ devices Shelly.CheckForUpdate.
+func (c *ShellyClient) CheckFirmwareUpdate(ctx context.Context, device *EnhancedShellyDevice) (*FirmwareInfo, error) {
+	info := &FirmwareInfo{
+		DeviceID:   device.ID,
+		Name:       device.Name,
+		Model:      device.Model,
+		IP:         device.IP,
+		Generation: device.Generation,
+		CheckedAt:  time.Now(),
+	}
+
+	version, err := c.GetFirmwareVersion(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	info.CurrentVersion = version
+
+	switch device.Generation {
+	case Gen1:
+		// /ota/check makes the device contact the update server; /ota reports the result
+		if _, err := c.makeJSONRequest(ctx, "GET", fmt.Sprintf("http://%s/ota/check", device.IP), nil); err != nil {
+			return nil, fmt.Errorf("failed to check for updates: %w", err)
+		}
+		ota, err := c.makeJSONRequest(ctx, "GET", fmt.Sprintf("http://%s/ota", device.IP), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get update status: %w", err)
+		}
+
+		hasUpdate, _ := ota["has_update"].(bool)
+		if newVersion, ok := ota["new_version"].(string); ok && hasUpdate {
+			info.StableVersion = newVersion
+		}
+		if betaVersion, ok := ota["beta_version"].(string); ok && betaVersion != "" && betaVersion != info.CurrentVersion {
+			info.BetaVersion = betaVersion
+		}
+		status, _ := ota["status"].(string)
+		info.Updating = status == "updating"
+	default:
+		result, err := c.makeGen2RPCCall(ctx, device.IP, "Shelly.CheckForUpdate", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for updates: %w", err)
+		}
+
+		if stable, ok := result["stable"].(map[string]interface{}); ok {
+			info.StableVersion, _ = stable["version"].(string)
+		}
+		if beta, ok := result["beta"].(map[string]interface{}); ok {
+			info.BetaVersion, _ = beta["version"].(string)
+		}
+	}
+
+	info.UpdateAvailable = info.StableVersion != ""
+	return info, nil
+}
+
+// StartFirmwareUpdate makes a device download and install firmware from the given stage. The
+// device reboots when the update is installed.
+func (c *ShellyClient) StartFirmwareUpdate(ctx context.Context, device *EnhancedShellyDevice, stage string) error {
+	if stage == "" {
+		stage = FirmwareStageStable
+	}
+
+	switch device.Generation {
+	case Gen1:
+		url := fmt.Sprintf("http://%s/ota?update=true", device.IP)
+		if stage == FirmwareStageBeta {
+			url = fmt.Sprintf("http://%s/ota?beta=true", device.IP)
+		}
+		if _, err := c.makeJSONRequest(ctx, "GET", url, nil); err != nil {
+			return fmt.Errorf("failed to start update: %w", err)
+		}
+	default:
+		if _, err := c.makeGen2RPCCall(ctx, device.IP, "Shelly.Update", map[string]interface{}{"stage": stage}); err != nil {
+			return fmt.Errorf("failed to start update: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetFirmwareVersion reads the installed firmware version from a device and records it
+func (c *ShellyClient) GetFirmwareVersion(ctx context.Context, device *EnhancedShellyDevice) (string, error) {
+	var version string
+
+	switch device.Generation {
+	case Gen1:
+		info, err := c.makeJSONRequest(ctx, "GET", fmt.Sprintf("http://%s/shelly", device.IP), nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get firmware version: %w", err)
+		}
+		version, _ = info["fw"].(string)
+	default:
+		info, err := c.makeGen2RPCCall(ctx, device.IP, "Shelly.GetDeviceInfo", nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get firmware version: %w", err)
+		}
+		version, _ = info["ver"].(string)
+	}
+
+	if version != "" {
+		c.devicesMutex.Lock()
+		device.FirmwareVersion = version
+		c.devicesMutex.Unlock()
+	}
+	return version, nil
+}
+
+// CheckDeviceHealth runs a health check on one device immediately and reports whether it
+// answered
+func (c *ShellyClient) CheckDeviceHealth(ctx context.Context, device *EnhancedShellyDevice) bool {
+	c.checkDeviceHealth(ctx, device)
+
+	c.devicesMutex.RLock()
+	defer c.devicesMutex.RUnlock()
+	return device.ErrorCount == 0
+}
+
+// GetDevice returns a discovered device by ID
+func (c *ShellyClient) GetDevice(deviceID string) *EnhancedShellyDevice {
+	c.devicesMutex.RLock()
+	defer c.devicesMutex.RUnlock()
+	return c.discoveredDevices[deviceID]
+}
@@ -0,0 +1,557 @@
+package shelly
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// API paths devices are configured to push to
+const (
+	PushWebSocketPath = "/api/v1/shelly/ws"
+	PushWebhookPath   = "/api/v1/shelly/webhook"
+)
+
+// Gen2+ notification methods sent over the outbound WebSocket
+const (
+	methodNotifyStatus     = "NotifyStatus"
+	methodNotifyFullStatus = "NotifyFullStatus"
+	methodNotifyEvent      = "NotifyEvent"
+)
+
+const (
+	pushReadLimit     = 64 * 1024
+	pushRetryInterval = 10 * time.Minute
+)
+
+// gen1Actions maps the Gen1 action URL settings PMA configures to the event they report.
+// Devices ignore the actions they do not support.
+var gen1Actions = map[string]string{
+	"out_on_url":           "output_on",
+	"out_off_url":          "output_off",
+	"btn_on_url":           "btn_down",
+	"btn_off_url":          "btn_up",
+	"shortpush_url":        "single_push",
+	"double_shortpush_url": "double_push",
+	"triple_shortpush_url": "triple_push",
+	"longpush_url":         "long_push",
+}
+
+// StateChangeHandler is called when a pushed update changes the state of an entity
+type StateChangeHandler func(entityID, oldState, newState string)
+
+// DeviceEventHandler is called for momentary events such as button presses
+type DeviceEventHandler func(event DeviceEvent)
+
+// DeviceEvent is an event reported by a device that does not change an entity state
+type DeviceEvent struct {
+	DeviceID  string                 `json:"device_id"`
+	EntityID  string                 `json:"entity_id"`
+	Component string                 `json:"component"` // e.g. "input:0"
+	Event     string                 `json:"event"`     // e.g. "single_push", "double_push", "long_push"
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// rpcNotification is a JSON-RPC notification frame from a Gen2+ device
+type rpcNotification struct {
+	Src    string                 `json:"src"`
+	Dst    string                 `json:"dst"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// SetEventHandler sets the handler for pushed state changes
+func (a *ShellyAdapter) SetEventHandler(handler StateChangeHandler) {
+	a.pushMutex.Lock()
+	defer a.pushMutex.Unlock()
+	a.eventHandler = handler
+}
+
+// SetDeviceEventHandler sets the handler for button and input events
+func (a *ShellyAdapter) SetDeviceEventHandler(handler DeviceEventHandler) {
+	a.pushMutex.Lock()
+	defer a.pushMutex.Unlock()
+	a.deviceEventHandler = handler
+}
+
+// AuthorizePush reports whether a push request may be accepted, and the address it came from.
+// With a token configured the request must carry it; without one only addresses of discovered
+// devices are accepted. The address is the connection's peer: forwarding headers are ignored,
+// since any client can send them and devices connect directly.
+func (a *ShellyAdapter) AuthorizePush(r *http.Request) (string, bool) {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	if !a.config.PushEnabled {
+		return remoteIP, false
+	}
+	if a.config.PushToken != "" {
+		token := r.URL.Query().Get("token")
+		return remoteIP, subtle.ConstantTimeCompare([]byte(token), []byte(a.config.PushToken)) == 1
+	}
+	return remoteIP, a.client.GetDeviceByIP(remoteIP) != nil
+}
+
+// ServePushConnection reads notification frames from a device's outbound WebSocket until the
+// connection or the context is closed
+func (a *ShellyAdapter) ServePushConnection(ctx context.Context, conn *websocket.Conn, remoteIP string) {
+	defer conn.Close()
+	conn.SetReadLimit(pushReadLimit)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	var deviceID string
+	defer func() {
+		if deviceID == "" {
+			return
+		}
+		a.pushMutex.Lock()
+		if a.pushConnections[deviceID]--; a.pushConnections[deviceID] <= 0 {
+			delete(a.pushConnections, deviceID)
+		}
+		a.pushMutex.Unlock()
+		a.logger.WithField("device_id", deviceID).Info("Shelly push connection closed")
+	}()
+
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				a.logger.WithError(err).WithField("remote_ip", remoteIP).Debug("Shelly push connection ended")
+			}
+			return
+		}
+
+		id, err := a.HandleNotification(frame, remoteIP)
+		if err != nil {
+			a.logger.WithError(err).WithField("remote_ip", remoteIP).Debug("Ignoring Shelly push frame")
+			continue
+		}
+
+		if deviceID == "" && id != "" {
+			deviceID = id
+			a.pushMutex.Lock()
+			a.pushConnections[deviceID]++
+			a.pushMutex.Unlock()
+			a.logger.WithFields(logrus.Fields{"device_id": deviceID, "remote_ip": remoteIP}).Info("Shelly device connected for realtime updates")
+		}
+	}
+}
+
+// HandleNotification processes one Gen2+ JSON-RPC notification frame and returns the ID of
+// the device that sent it
+func (a *ShellyAdapter) HandleNotification(frame []byte, remoteIP string) (string, error) {
+	var notification rpcNotification
+	if err := json.Unmarshal(frame, &notification); err != nil {
+		return "", fmt.Errorf("invalid frame: %w", err)
+	}
+	if notification.Method == "" {
+		// Responses to requests PMA never sent on this connection
+		return "", nil
+	}
+
+	device := a.client.FindDevice(notification.Src, remoteIP)
+	if device == nil {
+		return "", fmt.Errorf("unknown device %q", notification.Src)
+	}
+	a.recordPush()
+
+	switch notification.Method {
+	case methodNotifyStatus:
+		a.applyStatus(device, notification.Params, false)
+	case methodNotifyFullStatus:
+		a.applyStatus(device, notification.Params, true)
+	case methodNotifyEvent:
+		events, _ := notification.Params["events"].([]interface{})
+		for _, raw := range events {
+			event, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			component, _ := event["component"].(string)
+			name, _ := event["event"].(string)
+			if name == "" {
+				continue
+			}
+			a.emitDeviceEvent(device, component, name, event)
+		}
+	default:
+		return device.ID, fmt.Errorf("unsupported method %q", notification.Method)
+	}
+
+	return device.ID, nil
+}
+
+// HandleAction processes a Gen1 action URL call. Gen1 actions carry no state, so the device
+// status is fetched again to pick up output changes.
+func (a *ShellyAdapter) HandleAction(ctx context.Context, deviceID, action, remoteIP string) error {
+	device := a.client.FindDevice(deviceID, remoteIP)
+	if device == nil {
+		return fmt.Errorf("unknown device %q", deviceID)
+	}
+	a.recordPush()
+
+	event, known := gen1Actions[action]
+	if !known {
+		event = action
+	}
+
+	if status, err := a.client.getGen1DeviceStatus(ctx, device.IP); err == nil {
+		a.applyStatus(device, status, true)
+	} else {
+		a.logger.WithError(err).WithField("device_id", device.ID).Debug("Failed to refresh Gen1 status after action")
+	}
+
+	if event != "output_on" && event != "output_off" {
+		a.emitDeviceEvent(device, "input:0", event, map[string]interface{}{"action": action})
+	}
+	return nil
+}
+
+// applyStatus merges pushed status into the device and reports a changed entity state
+func (a *ShellyAdapter) applyStatus(device *EnhancedShellyDevice, update map[string]interface{}, full bool) {
+	before, err := a.convertDeviceToPMAEntity(device)
+	if err != nil {
+		return
+	}
+
+	a.client.ApplyStatusUpdate(device.ID, update, full)
+
+	after, err := a.convertDeviceToPMAEntity(device)
+	if err != nil {
+		return
+	}
+
+	oldState, newState := string(before.GetState()), string(after.GetState())
+	if oldState == newState {
+		return
+	}
+
+	a.pushMutex.RLock()
+	handler := a.eventHandler
+	a.pushMutex.RUnlock()
+
+	a.logger.WithFields(logrus.Fields{
+		"device_id": device.ID,
+		"old_state": oldState,
+		"new_state": newState,
+	}).Debug("Shelly device pushed a state change")
+
+	if handler != nil {
+		handler(after.GetID(), oldState, newState)
+	}
+}
+
+func (a *ShellyAdapter) emitDeviceEvent(device *EnhancedShellyDevice, component, event string, data map[string]interface{}) {
+	a.pushMutex.RLock()
+	handler := a.deviceEventHandler
+	a.pushMutex.RUnlock()
+
+	a.logger.WithFields(logrus.Fields{
+		"device_id": device.ID,
+		"component": component,
+		"event":     event,
+	}).Debug("Shelly device event")
+
+	if handler != nil {
+		handler(DeviceEvent{
+			DeviceID:  device.ID,
+			EntityID:  device.ID,
+			Component: component,
+			Event:     event,
+			Data:      data,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+func (a *ShellyAdapter) recordPush() {
+	a.pushMutex.Lock()
+	defer a.pushMutex.Unlock()
+	a.pushMessages++
+	a.lastPush = time.Now()
+}
+
+// GetPushStats returns realtime push statistics
+func (a *ShellyAdapter) GetPushStats() map[string]interface{} {
+	a.pushMutex.RLock()
+	defer a.pushMutex.RUnlock()
+
+	connected := make([]string, 0, len(a.pushConnections))
+	for id := range a.pushConnections {
+		connected = append(connected, id)
+	}
+	configured := make([]string, 0, len(a.pushConfigured))
+	for id := range a.pushConfigured {
+		configured = append(configured, id)
+	}
+
+	return map[string]interface{}{
+		"enabled":            a.config.PushEnabled,
+		"auto_configure":     a.config.AutoConfigurePush,
+		"connected_devices":  connected,
+		"configured_devices": configured,
+		"messages":           a.pushMessages,
+		"last_message":       a.lastPush,
+	}
+}
+
+// ========================================
+// Device configuration
+// ========================================
+
+// configurePendingDevices points online devices that have not been configured yet at PMA
+func (a *ShellyAdapter) configurePendingDevices(ctx context.Context) {
+	if !a.config.PushEnabled || !a.config.AutoConfigurePush || a.config.PushBaseURL == "" {
+		return
+	}
+
+	for _, device := range a.client.GetOnlineDevices() {
+		a.pushMutex.RLock()
+		configured := a.pushConfigured[device.ID] || a.pushConnections[device.ID] > 0
+		lastAttempt := a.pushAttempts[device.ID]
+		a.pushMutex.RUnlock()
+
+		if configured || time.Since(lastAttempt) < pushRetryInterval {
+			continue
+		}
+
+		if err := a.ConfigurePush(ctx, device.ID); err != nil {
+			a.logger.WithError(err).WithField("device_id", device.ID).Warn("Failed to configure Shelly realtime push")
+		}
+	}
+}
+
+// ConfigurePush points a device at PMA: Gen2+ devices get an outbound WebSocket server, Gen1
+// devices get action URLs
+func (a *ShellyAdapter) ConfigurePush(ctx context.Context, deviceID string) error {
+	if a.config.PushBaseURL == "" {
+		return fmt.Errorf("push base URL is not configured")
+	}
+
+	device := a.client.FindDevice(deviceID, "")
+	if device == nil {
+		return fmt.Errorf("device not found: %s", deviceID)
+	}
+
+	var err error
+	switch device.Generation {
+	case Gen1:
+		err = a.client.ConfigureGen1Actions(ctx, device.IP, a.gen1ActionURLs(device.ID))
+	default:
+		var restart bool
+		restart, err = a.client.ConfigureOutboundWebSocket(ctx, device.IP, a.pushWebSocketURL())
+		if err == nil && restart {
+			err = a.client.RebootDevice(ctx, device.IP)
+		}
+	}
+
+	a.pushMutex.Lock()
+	if err != nil {
+		a.pushAttempts[device.ID] = time.Now()
+	} else {
+		a.pushConfigured[device.ID] = true
+		delete(a.pushAttempts, device.ID)
+	}
+	a.pushMutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"device_id":  device.ID,
+		"generation": int(device.Generation),
+	}).Info("Configured Shelly device for realtime push")
+	return nil
+}
+
+// pushWebSocketURL returns the ws:// or wss:// URL Gen2+ devices connect to
+func (a *ShellyAdapter) pushWebSocketURL() string {
+	base := strings.TrimRight(a.config.PushBaseURL, "/")
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + PushWebSocketPath + a.pushQuery()
+}
+
+// gen1ActionURLs returns the action URL to configure for every Gen1 action
+func (a *ShellyAdapter) gen1ActionURLs(deviceID string) map[string]string {
+	base := strings.TrimRight(a.config.PushBaseURL, "/")
+	urls := make(map[string]string, len(gen1Actions))
+	for action := range gen1Actions {
+		urls[action] = fmt.Sprintf("%s%s/%s/%s%s", base, PushWebhookPath, url.PathEscape(deviceID), action, a.pushQuery())
+	}
+	return urls
+}
+
+func (a *ShellyAdapter) pushQuery() string {
+	if a.config.PushToken == "" {
+		return ""
+	}
+	return "?token=" + url.QueryEscape(a.config.PushToken)
+}
+
+// ========================================
+// Client support
+// ========================================
+
+// FindDevice returns a device by ID, falling back to its IP address
+func (c *ShellyClient) FindDevice(deviceID, ip string) *EnhancedShellyDevice {
+	c.devicesMutex.RLock()
+	defer c.devicesMutex.RUnlock()
+
+	if device, ok := c.discoveredDevices[deviceID]; ok && deviceID != "" {
+		return device
+	}
+	for _, device := range c.discoveredDevices {
+		if deviceID != "" && strings.EqualFold(device.ID, deviceID) {
+			return device
+		}
+	}
+	if ip != "" {
+		for _, device := range c.discoveredDevices {
+			if device.IP == ip {
+				return device
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyStatusUpdate merges a status update into a device. Gen2+ NotifyStatus frames only carry
+// the changed fields of a component, so component maps are merged; full replaces the status.
+func (c *ShellyClient) ApplyStatusUpdate(deviceID string, update map[string]interface{}, full bool) {
+	c.devicesMutex.Lock()
+	defer c.devicesMutex.Unlock()
+
+	device, ok := c.discoveredDevices[deviceID]
+	if !ok {
+		return
+	}
+
+	// Copy on write, readers use the status map without holding the lock
+	status := make(map[string]interface{})
+	if current, ok := device.Status.(map[string]interface{}); ok && !full {
+		for key, value := range current {
+			status[key] = value
+		}
+	}
+
+	for key, value := range update {
+		if key == "ts" {
+			continue
+		}
+		changes, isMap := value.(map[string]interface{})
+		existing, hasMap := status[key].(map[string]interface{})
+		if !full && isMap && hasMap {
+			merged := make(map[string]interface{}, len(existing)+len(changes))
+			for k, v := range existing {
+				merged[k] = v
+			}
+			for k, v := range changes {
+				merged[k] = v
+			}
+			status[key] = merged
+			continue
+		}
+		status[key] = value
+	}
+
+	device.Status = status
+	device.LastSeen = time.Now()
+	device.IsOnline = true
+}
+
+// ConfigureOutboundWebSocket enables a Gen2+ device's outbound WebSocket to the given server
+// and reports whether the device needs a restart to apply it
+func (c *ShellyClient) ConfigureOutboundWebSocket(ctx context.Context, deviceIP, server string) (bool, error) {
+	result, err := c.makeGen2RPCCall(ctx, deviceIP, "Ws.SetConfig", map[string]interface{}{
+		"config": map[string]interface{}{
+			"enable": true,
+			"server": server,
+			"ssl_ca": "*",
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to configure outbound WebSocket: %w", err)
+	}
+
+	restart, _ := result["restart_required"].(bool)
+	return restart, nil
+}
+
+// RebootDevice restarts a Gen2+ device
+func (c *ShellyClient) RebootDevice(ctx context.Context, deviceIP string) error {
+	if _, err := c.makeGen2RPCCall(ctx, deviceIP, "Shelly.Reboot", nil); err != nil {
+		return fmt.Errorf("failed to reboot device: %w", err)
+	}
+	return nil
+}
+
+// ConfigureGen1Actions sets the action URLs of a Gen1 device. Actions the device does not
+// support are skipped; it fails only when no action could be set.
+func (c *ShellyClient) ConfigureGen1Actions(ctx context.Context, deviceIP string, actions map[string]string) error {
+	configured := 0
+	var lastErr error
+
+	for name, actionURL := range actions {
+		params := url.Values{}
+		params.Set("index", "0")
+		params.Set("name", name)
+		params.Set("enabled", "true")
+		params.Add("urls[]", actionURL)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://%s/settings/actions?%s", deviceIP, params.Encode()), nil)
+		if err != nil {
+			return err
+		}
+		if c.defaultPassword != "" {
+			req.SetBasicAuth(c.defaultUsername, c.defaultPassword)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			configured++
+		} else {
+			lastErr = fmt.Errorf("action %s: HTTP %d", name, resp.StatusCode)
+		}
+	}
+
+	if configured == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no actions to configure")
+		}
+		return fmt.Errorf("failed to configure action URLs: %w", lastErr)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package shelly
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizePushIgnoresForwardedAddress(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	adapter := NewShellyAdapter(ShellyAdapterConfig{PushEnabled: true}, logger, nil)
+	adapter.client.discoveredDevices = map[string]*EnhancedShellyDevice{
+		"shellyplus1-a8032ab12345": {ID: "shellyplus1-a8032ab12345", IP: "192.168.1.50"},
+	}
+
+	// A device connecting directly is accepted
+	request := httptest.NewRequest("GET", PushWebSocketPath, nil)
+	request.RemoteAddr = "192.168.1.50:51234"
+	remoteIP, authorized := adapter.AuthorizePush(request)
+	assert.True(t, authorized)
+	assert.Equal(t, "192.168.1.50", remoteIP)
+
+	// Another client naming the device in forwarding headers is not
+	request = httptest.NewRequest("GET", PushWebhookPath+"/shellyplus1-a8032ab12345/out_on", nil)
+	request.RemoteAddr = "203.0.113.7:40000"
+	request.Header.Set("X-Forwarded-For", "192.168.1.50")
+	request.Header.Set("X-Real-IP", "192.168.1.50")
+	remoteIP, authorized = adapter.AuthorizePush(request)
+	assert.False(t, authorized)
+	assert.Equal(t, "203.0.113.7", remoteIP)
+
+	// With a token, the token decides
+	adapter.config.PushToken = "s3cret"
+	request = httptest.NewRequest("GET", PushWebSocketPath+"?token=s3cret", nil)
+	request.RemoteAddr = "203.0.113.7:40000"
+	_, authorized = adapter.AuthorizePush(request)
+	assert.True(t, authorized)
+
+	request = httptest.NewRequest("GET", PushWebSocketPath, nil)
+	request.RemoteAddr = "192.168.1.50:51234"
+	_, authorized = adapter.AuthorizePush(request)
+	assert.False(t, authorized)
+}
@@ -284,6 +284,32 @@ func (c *NUTClient) SendInstantCommand(ctx context.Context, upsName, command str
 	return c.sendCommand(cmd)
 }
 
+// ForcedShutdown logs in as the primary of a UPS and sets its forced shutdown flag, which
+// makes every NUT client monitoring it shut down its host
+func (c *NUTClient) ForcedShutdown(ctx context.Context, upsName, username, password string) error {
+	if c.conn == nil {
+		return fmt.Errorf("not connected to NUT server")
+	}
+
+	if username != "" {
+		if err := c.sendCommand(fmt.Sprintf("USERNAME %s", username)); err != nil {
+			return err
+		}
+		if err := c.sendCommand(fmt.Sprintf("PASSWORD %s", password)); err != nil {
+			return err
+		}
+	}
+
+	// NUT before 2.8 only knows MASTER
+	if err := c.sendCommand(fmt.Sprintf("PRIMARY %s", upsName)); err != nil {
+		if err := c.sendCommand(fmt.Sprintf("MASTER %s", upsName)); err != nil {
+			return err
+		}
+	}
+
+	return c.sendCommand(fmt.Sprintf("FSD %s", upsName))
+}
+
 // ListInstantCommands lists available instant commands for a UPS
 func (c *NUTClient) ListInstantCommands(ctx context.Context, upsName string) ([]string, error) {
 	if c.conn == nil {
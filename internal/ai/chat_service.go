@@ -3,6 +3,7 @@ package ai
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +15,10 @@ type ChatService struct {
 	manager          *LLMManager
 	logger           *logrus.Logger
 	contextExtractor ContextExtractor
+	intentHandler    IntentHandler
+	homeContext      HomeContextProvider
+	homeContextShare float64
+	homeContextMax   int
 	defaultModel     string
 	systemPrompt     string
 }
@@ -57,6 +62,20 @@ func (cs *ChatService) SetContextExtractor(extractor ContextExtractor) {
 	cs.contextExtractor = extractor
 }
 
+// SetIntentHandler sets the handler that answers common commands without the LLM
+func (cs *ChatService) SetIntentHandler(handler IntentHandler) {
+	cs.intentHandler = handler
+}
+
+// SetHomeContextProvider sets the provider of the home state added to chat requests, which
+// may use share of the provider's context window up to maxTokens. It replaces the entity and
+// room lists of the request context.
+func (cs *ChatService) SetHomeContextProvider(provider HomeContextProvider, share float64, maxTokens int) {
+	cs.homeContext = provider
+	cs.homeContextShare = share
+	cs.homeContextMax = maxTokens
+}
+
 // SetDefaultModel sets the default model to use for chat requests
 func (cs *ChatService) SetDefaultModel(model string) {
 	cs.defaultModel = model
@@ -64,6 +83,14 @@ func (cs *ChatService) SetDefaultModel(model string) {
 
 // Chat performs a context-aware chat interaction
 func (cs *ChatService) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// Commands the intent handler recognizes never reach the LLM
+	if cs.intentHandler != nil {
+		started := time.Now()
+		if result, ok := cs.intentHandler.HandleIntent(ctx, LastUserMessage(req.Messages), req.Metadata["locale"]); ok {
+			return NewIntentChatResponse(result, started), nil
+		}
+	}
+
 	// Enrich the request with context if available
 	if cs.contextExtractor != nil && req.Context != nil && req.Context.UserID != "" {
 		enrichedContext, err := cs.contextExtractor.ExtractContext(ctx, req.Context.UserID)
@@ -74,8 +101,15 @@ func (cs *ChatService) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 		}
 	}
 
+	// Add the home state relevant to the message, sized for the provider answering it
+	budget := cs.manager.ContextBudget(req.Provider, cs.homeContextShare, cs.homeContextMax)
+	home, homePrompt, err := homeContextPrompt(ctx, cs.homeContext, LastUserMessage(req.Messages), budget)
+	if err != nil {
+		cs.logger.WithError(err).Warn("Failed to build home context, continuing without it")
+	}
+
 	// Build the message chain with system prompt and context
-	messages := cs.buildMessageChain(req)
+	messages := cs.buildMessageChain(req, homePrompt)
 
 	// Set up chat options
 	opts := ChatOptions{
@@ -105,7 +139,12 @@ func (cs *ChatService) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 		response.Metadata = make(map[string]string)
 	}
 
-	if req.Context != nil {
+	if home != nil && homePrompt != "" {
+		response.Metadata["context_provided"] = "true"
+		response.Metadata["home_context_tokens"] = strconv.Itoa(home.Tokens)
+		response.Metadata["home_context_entities"] = strconv.Itoa(home.Relevant)
+		response.Metadata["home_context_cached"] = strconv.FormatBool(home.Cached)
+	} else if req.Context != nil {
 		response.Metadata["context_provided"] = "true"
 		response.Metadata["entity_count"] = fmt.Sprintf("%d", len(req.Context.Entities))
 		response.Metadata["room_count"] = fmt.Sprintf("%d", len(req.Context.Rooms))
@@ -257,11 +296,26 @@ func (cs *ChatService) SummarizeSystem(ctx context.Context, req SystemSummaryReq
 
 // Private helper methods
 
-func (cs *ChatService) buildMessageChain(req ChatRequest) []ChatMessage {
+func (cs *ChatService) buildMessageChain(req ChatRequest, homePrompt string) []ChatMessage {
 	messages := make([]ChatMessage, 0)
 
-	// Add context information as a system message if available
-	if req.Context != nil {
+	// Add context information as a system message if available. The home context takes the
+	// place of the request's entity and room lists.
+	if homePrompt != "" {
+		contextMsg := strings.TrimSpace(homePrompt)
+		if req.Context != nil {
+			withoutEntities := *req.Context
+			withoutEntities.Entities, withoutEntities.Rooms = nil, nil
+			if extra := cs.buildContextMessage(&withoutEntities); extra != "" {
+				contextMsg = extra + "\n\n" + contextMsg
+			}
+		}
+		messages = append(messages, ChatMessage{
+			Role:      "system",
+			Content:   contextMsg,
+			Timestamp: time.Now(),
+		})
+	} else if req.Context != nil {
 		contextMsg := cs.buildContextMessage(req.Context)
 		if contextMsg != "" {
 			messages = append(messages, ChatMessage{
@@ -92,6 +92,33 @@ type MCPToolExecution struct {
 	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
 }
 
+// MCP server transports
+const (
+	MCPTransportStdio = "stdio" // subprocess speaking newline-delimited JSON-RPC
+	MCPTransportHTTP  = "http"  // streamable HTTP
+	MCPTransportSSE   = "sse"   // legacy HTTP with server-sent events
+)
+
+// MCPServer represents an external MCP server whose tools are offered to the LLM
+type MCPServer struct {
+	ID          string            `json:"id" db:"id"`
+	Name        string            `json:"name" db:"name"`
+	Description string            `json:"description" db:"description"`
+	Transport   string            `json:"transport" db:"transport"`
+	Command     string            `json:"command,omitempty" db:"command"`
+	Args        []string          `json:"args,omitempty" db:"args"`
+	Env         map[string]string `json:"env,omitempty" db:"env"`
+	CWD         string            `json:"cwd,omitempty" db:"cwd"`
+	URL         string            `json:"url,omitempty" db:"url"`
+	Headers     map[string]string `json:"headers,omitempty" db:"headers"`
+	Timeout     int               `json:"timeout" db:"timeout"` // seconds
+	Enabled     bool              `json:"enabled" db:"enabled"`
+	AutoRestart bool              `json:"autoRestart" db:"auto_restart"`
+	MaxRestarts int               `json:"maxRestarts" db:"max_restarts"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
 // ConversationAnalytics represents analytics data for a conversation
 type ConversationAnalytics struct {
 	ID                 string    `json:"id" db:"id"`
@@ -272,6 +299,7 @@ type EnhancedChatResponse struct {
 	Message        ConversationMessage `json:"message"`
 	Response       ChatResponse        `json:"response"`
 	ToolExecutions []MCPToolExecution  `json:"tool_executions,omitempty"`
+	Memories       []RecalledMemory    `json:"memories,omitempty"` // Memories recalled into the system prompt
 	TokensUsed     int                 `json:"tokens_used"`
 	Cost           float64             `json:"cost"`
 	ResponseTime   time.Duration       `json:"response_time"`
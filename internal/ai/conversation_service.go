@@ -2,10 +2,12 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/core/audit"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -38,12 +40,28 @@ type MCPRepositoryInterface interface {
 	CleanupOldExecutions(ctx context.Context, days int) error
 }
 
+// ExternalToolSource provides tools served outside PMA, such as those of external MCP servers
+type ExternalToolSource interface {
+	LLMTools() []LLMTool
+	HandlesTool(name string) bool
+	ExecuteTool(ctx context.Context, name string, arguments map[string]interface{}) (string, error)
+}
+
+// maxToolRounds bounds the tool calls the LLM may chain before answering
+const maxToolRounds = 5
+
 // ConversationService provides enhanced conversation management with persistence and MCP support
 type ConversationService struct {
 	llmManager       *LLMManager
 	conversationRepo ConversationRepositoryInterface
 	mcpRepo          MCPRepositoryInterface
 	toolExecutor     *MCPToolExecutor
+	externalTools    ExternalToolSource
+	intentHandler    IntentHandler
+	memory           MemoryProvider
+	homeContext      HomeContextProvider
+	homeContextShare float64
+	homeContextMax   int
 	logger           *logrus.Logger
 	contextExtractor ContextExtractor
 	defaultProvider  string
@@ -90,6 +108,29 @@ func (cs *ConversationService) SetContextExtractor(extractor ContextExtractor) {
 	cs.contextExtractor = extractor
 }
 
+// SetExternalToolSource sets the source of tools offered alongside PMA's own tools
+func (cs *ConversationService) SetExternalToolSource(source ExternalToolSource) {
+	cs.externalTools = source
+}
+
+// SetIntentHandler sets the handler that answers common commands without the LLM
+func (cs *ConversationService) SetIntentHandler(handler IntentHandler) {
+	cs.intentHandler = handler
+}
+
+// SetMemoryProvider sets the long-term memory recalled into and learned from conversations
+func (cs *ConversationService) SetMemoryProvider(memory MemoryProvider) {
+	cs.memory = memory
+}
+
+// SetHomeContextProvider sets the provider of the home state added to the system prompt, which
+// may use share of the provider's context window up to maxTokens
+func (cs *ConversationService) SetHomeContextProvider(provider HomeContextProvider, share float64, maxTokens int) {
+	cs.homeContext = provider
+	cs.homeContextShare = share
+	cs.homeContextMax = maxTokens
+}
+
 // SetDefaults sets default provider and model
 func (cs *ConversationService) SetDefaults(provider, model string) {
 	cs.defaultProvider = provider
@@ -246,47 +287,20 @@ func (cs *ConversationService) SendMessage(ctx context.Context, userID, conversa
 		return nil, fmt.Errorf("failed to save user message: %w", err)
 	}
 
-	// Build conversation history for AI context
-	messages, err := cs.buildConversationHistory(ctx, conversation, userMessage)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build conversation history: %w", err)
-	}
-
-	// Set up chat options
-	chatOpts := ChatOptions{
-		MaxTokens:    conversation.MaxTokens,
-		Temperature:  conversation.Temperature,
-		Provider:     conversation.Provider,
-		SystemPrompt: cs.getSystemPrompt(conversation),
-	}
-
-	// Set model (handle pointer vs string)
-	if conversation.Model != nil {
-		chatOpts.Model = *conversation.Model
-	}
-
-	// Apply request overrides
-	if req.Temperature != nil {
-		chatOpts.Temperature = *req.Temperature
-	}
-	if req.MaxTokens != nil {
-		chatOpts.MaxTokens = *req.MaxTokens
-	}
-
-	// Use defaults if not set
-	if chatOpts.Provider == "" || chatOpts.Provider == "auto" {
-		chatOpts.Provider = cs.defaultProvider
-	}
-	if chatOpts.Model == "" && cs.defaultModel != "" {
-		chatOpts.Model = cs.defaultModel
-	}
-
-	// Note: Tool support would be added here when the ChatOptions structure supports it
-
-	// Get AI response
-	response, err := cs.llmManager.Chat(ctx, messages, chatOpts)
-	if err != nil {
-		return nil, fmt.Errorf("AI chat failed: %w", err)
+	// Commands the intent handler recognizes never reach the LLM
+	var response *ChatResponse
+	var toolExecutions []MCPToolExecution
+	var toolCalls []ToolCall
+	var memories []RecalledMemory
+	if result, ok := cs.handleIntent(ctx, req); ok {
+		response = NewIntentChatResponse(result, startTime)
+	} else {
+		memories = cs.recallMemories(ctx, userID, userMessage)
+		response, toolExecutions, toolCalls, err = cs.chatWithTools(ctx, conversation, userMessage, req, memories)
+		if err != nil {
+			return nil, err
+		}
+		MarkCitedMemories(memories, response.Message.Content)
 	}
 
 	responseTime := time.Since(startTime)
@@ -297,22 +311,27 @@ func (cs *ConversationService) SendMessage(ctx context.Context, userID, conversa
 		ConversationID: conversationID,
 		Role:           "assistant",
 		Content:        response.Message.Content,
+		ToolCalls:      toolCalls,
 		TokensUsed:     response.TokensUsed.TotalTokens,
 		ModelUsed:      &response.Model,
 		ProviderUsed:   &response.Provider,
 		ResponseTimeMs: int(responseTime.Milliseconds()),
 		Metadata:       make(map[string]interface{}),
 	}
-
-	// Handle tool calls if present (placeholder for future enhancement)
-	var toolExecutions []MCPToolExecution
-	// Note: Tool call support would be added here when the ChatResponse structure supports it
+	if len(memories) > 0 {
+		memoryIDs := make([]int64, len(memories))
+		for i, memory := range memories {
+			memoryIDs[i] = memory.ID
+		}
+		assistantMessage.Metadata["memory_ids"] = memoryIDs
+	}
 
 	// Save assistant message
 	err = cs.conversationRepo.CreateMessage(ctx, assistantMessage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save assistant message: %w", err)
 	}
+	cs.recordToolExecutions(ctx, assistantMessage.ID, toolExecutions)
 
 	// Calculate cost (placeholder - would integrate with actual pricing)
 	cost := float64(response.TokensUsed.TotalTokens) * 0.0001 // $0.0001 per token
@@ -323,6 +342,7 @@ func (cs *ConversationService) SendMessage(ctx context.Context, userID, conversa
 		Message:        *assistantMessage,
 		Response:       *response,
 		ToolExecutions: toolExecutions,
+		Memories:       memories,
 		TokensUsed:     response.TokensUsed.TotalTokens,
 		Cost:           cost,
 		ResponseTime:   responseTime,
@@ -333,6 +353,15 @@ func (cs *ConversationService) SendMessage(ctx context.Context, userID, conversa
 	// Update analytics
 	go cs.updateConversationAnalytics(conversationID, response.TokensUsed.TotalTokens, cost, responseTime)
 
+	// Remember lasting facts from LLM exchanges
+	if cs.memory != nil && userMessage.Role == "user" && response.Provider != IntentProvider {
+		exchange := []ChatMessage{
+			{Role: userMessage.Role, Content: userMessage.Content},
+			{Role: assistantMessage.Role, Content: assistantMessage.Content},
+		}
+		go cs.memory.Learn(context.Background(), userID, conversationID, exchange)
+	}
+
 	cs.logger.WithFields(logrus.Fields{
 		"conversation_id": conversationID,
 		"tokens_used":     response.TokensUsed,
@@ -343,8 +372,126 @@ func (cs *ConversationService) SendMessage(ctx context.Context, userID, conversa
 	return enhancedResponse, nil
 }
 
+// handleIntent lets the intent handler answer the message without the LLM
+func (cs *ConversationService) handleIntent(ctx context.Context, req *SendMessageRequest) (*IntentResult, bool) {
+	if cs.intentHandler == nil || (req.Role != nil && *req.Role != "user") {
+		return nil, false
+	}
+	locale, _ := req.Metadata["locale"].(string)
+	return cs.intentHandler.HandleIntent(ctx, req.Content, locale)
+}
+
+// recallMemories returns the memories relevant to a user message. Recall failures only cost
+// the assistant its memory.
+func (cs *ConversationService) recallMemories(ctx context.Context, userID string, message *ConversationMessage) []RecalledMemory {
+	if cs.memory == nil || message.Role != "user" {
+		return nil
+	}
+	memories, err := cs.memory.Recall(ctx, userID, message.Content)
+	if err != nil {
+		cs.logger.WithError(err).Warn("Failed to recall memories")
+		return nil
+	}
+	return memories
+}
+
+// chatWithTools gets the LLM response to the message, running the tools it asks for until it answers
+func (cs *ConversationService) chatWithTools(ctx context.Context, conversation *Conversation, userMessage *ConversationMessage, req *SendMessageRequest, memories []RecalledMemory) (*ChatResponse, []MCPToolExecution, []ToolCall, error) {
+	// Set up chat options
+	chatOpts := ChatOptions{
+		MaxTokens:   conversation.MaxTokens,
+		Temperature: conversation.Temperature,
+		Provider:    conversation.Provider,
+	}
+
+	// Set model (handle pointer vs string)
+	if conversation.Model != nil {
+		chatOpts.Model = *conversation.Model
+	}
+
+	// Apply request overrides
+	if req.Temperature != nil {
+		chatOpts.Temperature = *req.Temperature
+	}
+	if req.MaxTokens != nil {
+		chatOpts.MaxTokens = *req.MaxTokens
+	}
+
+	// Use defaults if not set
+	if chatOpts.Provider == "" || chatOpts.Provider == "auto" {
+		chatOpts.Provider = cs.defaultProvider
+	}
+	if chatOpts.Model == "" && cs.defaultModel != "" {
+		chatOpts.Model = cs.defaultModel
+	}
+
+	chatOpts.Tools = cs.availableTools(ctx)
+
+	// The home state relevant to the message is sized for the provider answering it
+	budget := cs.llmManager.ContextBudget(chatOpts.Provider, cs.homeContextShare, cs.homeContextMax)
+	_, homePrompt, err := homeContextPrompt(ctx, cs.homeContext, userMessage.Content, budget)
+	if err != nil {
+		cs.logger.WithError(err).Warn("Failed to build home context, continuing without it")
+	}
+	chatOpts.SystemPrompt = cs.getSystemPrompt(conversation) + homePrompt + MemoryPrompt(memories)
+
+	// Build conversation history for AI context
+	messages, err := cs.buildConversationHistory(ctx, conversation, userMessage, chatOpts.SystemPrompt)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to build conversation history: %w", err)
+	}
+
+	// Get AI response, running the tools it asks for until it answers
+	response, err := cs.llmManager.Chat(ctx, messages, chatOpts)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("AI chat failed: %w", err)
+	}
+
+	var toolExecutions []MCPToolExecution
+	var toolCalls []ToolCall
+	tokensUsed := response.TokensUsed.TotalTokens
+	for round := 0; len(response.Message.ToolCalls) > 0 && round < maxToolRounds; round++ {
+		messages = append(messages, response.Message)
+		for _, toolCall := range response.Message.ToolCalls {
+			execution := cs.runToolCall(ctx, conversation.ID, toolCall)
+			toolExecutions = append(toolExecutions, *execution)
+
+			toolCall.Result = &ToolCallResult{
+				Success:       execution.Success,
+				Error:         execution.Error,
+				ExecutionTime: execution.ExecutionTimeMs,
+			}
+			content := ""
+			if execution.Result != nil {
+				content = *execution.Result
+				toolCall.Result.Result = content
+			} else if execution.Error != nil {
+				content = "Error: " + *execution.Error
+			}
+			toolCalls = append(toolCalls, toolCall)
+
+			messages = append(messages, ChatMessage{
+				Role:       "tool",
+				Name:       toolCallName(toolCall),
+				Content:    content,
+				ToolCallID: toolCall.ID,
+				Timestamp:  time.Now(),
+			})
+		}
+
+		response, err = cs.llmManager.Chat(ctx, messages, chatOpts)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("AI chat failed: %w", err)
+		}
+		tokensUsed += response.TokensUsed.TotalTokens
+	}
+	response.TokensUsed.TotalTokens = tokensUsed
+
+	return response, toolExecutions, toolCalls, nil
+}
+
 // buildConversationHistory builds the message history for AI context
-func (cs *ConversationService) buildConversationHistory(ctx context.Context, conversation *Conversation, newMessage *ConversationMessage) ([]ChatMessage, error) {
+func (cs *ConversationService) buildConversationHistory(ctx context.Context, conversation *Conversation, newMessage *ConversationMessage, systemPrompt string) ([]ChatMessage, error) {
 	// Get recent messages for context (limit to last 20 messages to manage token usage)
 	recentMessages, err := cs.conversationRepo.GetConversationMessages(ctx, conversation.ID, 20, 0)
 	if err != nil {
@@ -354,7 +501,6 @@ func (cs *ConversationService) buildConversationHistory(ctx context.Context, con
 	var messages []ChatMessage
 
 	// Add system message if present
-	systemPrompt := cs.getSystemPrompt(conversation)
 	if systemPrompt != "" {
 		messages = append(messages, ChatMessage{
 			Role:    "system",
@@ -392,14 +538,74 @@ func (cs *ConversationService) getSystemPrompt(conversation *Conversation) strin
 	return cs.systemPrompt
 }
 
-// executeToolCall executes an MCP tool call
-func (cs *ConversationService) executeToolCall(ctx context.Context, conversationID, messageID string, toolCall ToolCall) (*MCPToolExecution, error) {
+// availableTools returns PMA's enabled tools together with the external tools
+func (cs *ConversationService) availableTools(ctx context.Context) []LLMTool {
+	var tools []LLMTool
+	if cs.toolExecutor != nil {
+		localTools, err := cs.mcpRepo.GetEnabledTools(ctx, "")
+		if err != nil {
+			cs.logger.WithError(err).Warn("Failed to load MCP tools")
+		} else {
+			tools = ConvertMCPToolsToLLMTools(localTools)
+		}
+	}
+	if cs.externalTools != nil {
+		tools = append(tools, cs.externalTools.LLMTools()...)
+	}
+	return tools
+}
+
+// runToolCall executes a tool call on an external server or PMA's tool executor. Failures
+// are recorded on the execution so the LLM can see them.
+func (cs *ConversationService) runToolCall(ctx context.Context, conversationID string, toolCall ToolCall) *MCPToolExecution {
+	name := toolCallName(toolCall)
+	// Entity actions run by tools are audited against the conversation
+	ctx = audit.WithActor(ctx, audit.Actor{Type: audit.ActorAI, ID: conversationID})
+	if cs.externalTools != nil && cs.externalTools.HandlesTool(name) {
+		startTime := time.Now()
+		result, err := cs.externalTools.ExecuteTool(ctx, name, toolCall.Function.Arguments)
+		execution := &MCPToolExecution{
+			ID:              uuid.New().String(),
+			ConversationID:  conversationID,
+			ToolName:        name,
+			Parameters:      toolCall.Function.Arguments,
+			ExecutionTimeMs: int(time.Since(startTime).Milliseconds()),
+			Success:         err == nil,
+			CreatedAt:       time.Now(),
+		}
+		if err != nil {
+			cs.logger.WithError(err).WithField("tool", name).Error("External tool execution failed")
+			errMsg := err.Error()
+			execution.Error = &errMsg
+		} else {
+			execution.Result = &result
+		}
+		return execution
+	}
+
+	execution, err := cs.executeToolCall(ctx, conversationID, toolCall)
+	if err != nil && execution == nil {
+		errMsg := err.Error()
+		execution = &MCPToolExecution{
+			ID:             uuid.New().String(),
+			ConversationID: conversationID,
+			ToolName:       name,
+			Parameters:     toolCall.Function.Arguments,
+			Error:          &errMsg,
+			CreatedAt:      time.Now(),
+		}
+	}
+	return execution
+}
+
+// executeToolCall executes a call to one of PMA's own MCP tools
+func (cs *ConversationService) executeToolCall(ctx context.Context, conversationID string, toolCall ToolCall) (*MCPToolExecution, error) {
 	if cs.toolExecutor == nil {
 		return nil, fmt.Errorf("tool executor not available")
 	}
 
 	// Get tool definition
-	tool, err := cs.mcpRepo.GetToolByName(ctx, toolCall.Name)
+	tool, err := cs.mcpRepo.GetToolByName(ctx, toolCallName(toolCall))
 	if err != nil {
 		return nil, fmt.Errorf("tool not found: %w", err)
 	}
@@ -407,14 +613,13 @@ func (cs *ConversationService) executeToolCall(ctx context.Context, conversation
 	// Execute tool
 	execution, err := cs.toolExecutor.ExecuteTool(ctx, tool, toolCall.Function.Arguments)
 	if err != nil {
-		cs.logger.WithError(err).WithField("tool", toolCall.Name).Error("Tool execution failed")
+		cs.logger.WithError(err).WithField("tool", tool.Name).Error("Tool execution failed")
 	}
 
 	// Create execution record
 	mcpExecution := &MCPToolExecution{
 		ID:             uuid.New().String(),
 		ConversationID: conversationID,
-		MessageID:      messageID,
 		ToolID:         tool.ID,
 		ToolName:       tool.Name,
 		Parameters:     toolCall.Function.Arguments,
@@ -425,28 +630,49 @@ func (cs *ConversationService) executeToolCall(ctx context.Context, conversation
 	if execution != nil {
 		mcpExecution.ExecutionTimeMs = execution.ExecutionTime
 		if execution.Success {
-			if resultStr, ok := execution.Result.(string); ok {
-				mcpExecution.Result = &resultStr
+			resultStr, ok := execution.Result.(string)
+			if !ok {
+				data, _ := json.Marshal(execution.Result)
+				resultStr = string(data)
 			}
+			mcpExecution.Result = &resultStr
 		} else if execution.Error != nil {
 			mcpExecution.Error = execution.Error
 		}
+	} else if err != nil {
+		errMsg := err.Error()
+		mcpExecution.Error = &errMsg
 	}
 
-	// Save execution record
-	if saveErr := cs.mcpRepo.CreateToolExecution(ctx, mcpExecution); saveErr != nil {
-		cs.logger.WithError(saveErr).Error("Failed to save tool execution")
-	}
+	return mcpExecution, nil
+}
 
-	// Increment tool usage
-	if updateErr := cs.mcpRepo.IncrementToolUsage(ctx, tool.ID); updateErr != nil {
-		cs.logger.WithError(updateErr).Error("Failed to increment tool usage")
-	}
+// recordToolExecutions saves the executions of PMA's own tools once the message they belong to exists
+func (cs *ConversationService) recordToolExecutions(ctx context.Context, messageID string, executions []MCPToolExecution) {
+	for i := range executions {
+		execution := &executions[i]
+		execution.MessageID = messageID
+		if execution.ToolID == "" {
+			// External tools have no row in mcp_tools
+			continue
+		}
 
-	return mcpExecution, err
+		if err := cs.mcpRepo.CreateToolExecution(ctx, execution); err != nil {
+			cs.logger.WithError(err).Error("Failed to save tool execution")
+		}
+		if err := cs.mcpRepo.IncrementToolUsage(ctx, execution.ToolID); err != nil {
+			cs.logger.WithError(err).Error("Failed to increment tool usage")
+		}
+	}
 }
 
-// Note: convertMCPToolsToLLMTools would be implemented when LLMTool types are available
+// toolCallName returns the name of the tool a call targets
+func toolCallName(toolCall ToolCall) string {
+	if toolCall.Function.Name != "" {
+		return toolCall.Function.Name
+	}
+	return toolCall.Name
+}
 
 // updateConversationAnalytics updates conversation analytics asynchronously
 func (cs *ConversationService) updateConversationAnalytics(conversationID string, tokensUsed int, cost float64, responseTime time.Duration) {
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// HomeContextProvider renders the home state relevant to a message as prompt text that fits
+// a token budget
+type HomeContextProvider interface {
+	HomeContext(ctx context.Context, message string, budget TokenBudget) (*HomeContext, error)
+}
+
+// TokenBudget bounds text added to a prompt, measured the way the provider reading it counts
+type TokenBudget struct {
+	MaxTokens int                   `json:"max_tokens"`
+	Provider  string                `json:"provider,omitempty"`
+	Estimate  func(text string) int `json:"-"`
+}
+
+// Tokens estimates the tokens of text
+func (b TokenBudget) Tokens(text string) int {
+	if b.Estimate == nil {
+		return len(text) / 4
+	}
+	return b.Estimate(text)
+}
+
+// HomeContext is a rendered home state snapshot
+type HomeContext struct {
+	Text        string    `json:"text"`
+	Tokens      int       `json:"tokens"`
+	Relevant    int       `json:"relevant"`   // Entities listed individually
+	Summarized  int       `json:"summarized"` // Entities only counted in the area and room summary
+	Cached      bool      `json:"cached"`
+	GeneratedAt time.Time `json:"generated_at"` // When the entity states were read
+}
+
+// defaultContextWindows are the context windows assumed for providers that do not configure one
+var defaultContextWindows = map[string]int{
+	"ollama": 4096,
+	"openai": 16385,
+	"claude": 200000,
+	"gemini": 1000000,
+}
+
+const (
+	fallbackContextWindow = 4096 // Assumed when no provider is known
+	defaultContextShare   = 0.25 // Share of the context window used when none is configured
+)
+
+// homeContextPrompt renders home context for a system prompt; failures leave the prompt without it
+func homeContextPrompt(ctx context.Context, provider HomeContextProvider, message string, budget TokenBudget) (*HomeContext, string, error) {
+	if provider == nil || message == "" || budget.MaxTokens <= 0 {
+		return nil, "", nil
+	}
+	home, err := provider.HomeContext(ctx, message, budget)
+	if err != nil || home == nil || home.Text == "" {
+		return home, "", err
+	}
+	return home, "\n\n" + home.Text, nil
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IntentProvider is the provider reported for responses produced without an LLM
+const IntentProvider = "local"
+
+// IntentHandler recognizes and executes common commands locally. Requests it does not
+// handle fall back to the LLM.
+type IntentHandler interface {
+	HandleIntent(ctx context.Context, text, locale string) (*IntentResult, bool)
+}
+
+// IntentResult is the outcome of a command handled by an IntentHandler
+type IntentResult struct {
+	Intent    string   `json:"intent"`
+	Locale    string   `json:"locale"`
+	Response  string   `json:"response"`
+	Success   bool     `json:"success"`
+	EntityIDs []string `json:"entity_ids"`
+}
+
+// NewIntentChatResponse wraps an intent result as an assistant chat response
+func NewIntentChatResponse(result *IntentResult, started time.Time) *ChatResponse {
+	now := time.Now()
+	return &ChatResponse{
+		ID: uuid.New().String(),
+		Message: ChatMessage{
+			Role:      "assistant",
+			Content:   result.Response,
+			Timestamp: now,
+		},
+		FinishReason:     "stop",
+		Model:            "intent",
+		Provider:         IntentProvider,
+		ProcessingTimeMs: time.Since(started).Milliseconds(),
+		Metadata: map[string]string{
+			"intent":         result.Intent,
+			"intent_locale":  result.Locale,
+			"intent_success": strconv.FormatBool(result.Success),
+			"entity_ids":     strings.Join(result.EntityIDs, ","),
+		},
+		CreatedAt: now,
+	}
+}
+
+// LastUserMessage returns the content of the latest user message
+func LastUserMessage(messages []ChatMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
@@ -15,6 +15,7 @@ import (
 type LLMManager struct {
 	providers       []LLMProvider
 	providersByName map[string]LLMProvider
+	contextWindows  map[string]int
 	primaryProvider string
 	fallbackEnabled bool
 	fallbackDelay   time.Duration
@@ -66,6 +67,7 @@ func NewLLMManager(cfg *config.Config, logger *logrus.Logger) (*LLMManager, erro
 	manager := &LLMManager{
 		providers:         make([]LLMProvider, 0),
 		providersByName:   make(map[string]LLMProvider),
+		contextWindows:    make(map[string]int),
 		primaryProvider:   cfg.AI.DefaultProvider,
 		fallbackEnabled:   cfg.AI.FallbackEnabled,
 		maxRetries:        cfg.AI.MaxRetries,
@@ -221,6 +223,89 @@ func (m *LLMManager) Chat(ctx context.Context, messages []ChatMessage, opts Chat
 	return m.chatWithFallback(ctx, messages, opts)
 }
 
+// Embed turns texts into embedding vectors with the requested provider, or else the first
+// available provider supporting embeddings
+func (m *LLMManager) Embed(ctx context.Context, texts []string, opts EmbeddingOptions) (*EmbeddingResponse, error) {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+
+	m.mu.RLock()
+	providers := make([]LLMProvider, 0, len(m.providers))
+	for _, provider := range m.providers {
+		if opts.Provider == "" || provider.GetName() == opts.Provider {
+			providers = append(providers, provider)
+		}
+	}
+	m.mu.RUnlock()
+
+	var lastError error
+	for _, provider := range providers {
+		embedder, ok := provider.(EmbeddingProvider)
+		if !ok || !m.isProviderAvailable(ctx, provider) {
+			continue
+		}
+
+		model := opts.Model
+		if model == "" {
+			model = embedder.EmbeddingModel()
+		}
+		vectors, err := embedder.Embed(ctx, texts, model)
+		if err != nil {
+			lastError = err
+			m.logger.WithError(err).WithField("provider", provider.GetName()).Debug("Embedding failed, trying next provider")
+			continue
+		}
+		return &EmbeddingResponse{Vectors: vectors, Provider: provider.GetName(), Model: model}, nil
+	}
+
+	if lastError != nil {
+		return nil, lastError
+	}
+	return nil, &ProviderError{
+		Provider: "manager",
+		Type:     "unavailable",
+		Message:  "No available provider supports embeddings",
+	}
+}
+
+// ContextBudget returns the token budget for context added to a request to a provider: a share
+// of its context window, capped at maxTokens, measured with the provider's token estimate. An
+// empty or "auto" provider name means the provider chat requests try first.
+func (m *LLMManager) ContextBudget(providerName string, share float64, maxTokens int) TokenBudget {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if providerName == "" || providerName == "auto" {
+		providerName = m.primaryProvider
+	}
+	provider, exists := m.providersByName[providerName]
+	if !exists && len(m.providers) > 0 {
+		provider = m.providers[0]
+	}
+
+	budget := TokenBudget{}
+	window := fallbackContextWindow
+	if provider != nil {
+		budget.Provider = provider.GetName()
+		budget.Estimate = provider.EstimateTokens
+		if configured := m.contextWindows[provider.GetName()]; configured > 0 {
+			window = configured
+		}
+	}
+
+	if share <= 0 || share > 1 {
+		share = defaultContextShare
+	}
+	budget.MaxTokens = int(float64(window) * share)
+	if maxTokens > 0 && budget.MaxTokens > maxTokens {
+		budget.MaxTokens = maxTokens
+	}
+	return budget
+}
+
 // GetProviders returns all available providers
 func (m *LLMManager) GetProviders(ctx context.Context) []ProviderStatus {
 	m.mu.RLock()
@@ -326,6 +411,7 @@ func (m *LLMManager) ReinitializeProviders(cfg *config.Config) error {
 	// Clear existing providers
 	m.providers = make([]LLMProvider, 0)
 	m.providersByName = make(map[string]LLMProvider)
+	m.contextWindows = make(map[string]int)
 	m.circuitBreaker = make(map[string]*CircuitBreaker)
 
 	// Re-run provider initialization with current factories
@@ -379,6 +465,10 @@ func (m *LLMManager) initializeProviders(providerConfigs []config.AIProviderConf
 
 		m.providers = append(m.providers, provider)
 		m.providersByName[provider.GetName()] = provider
+		m.contextWindows[provider.GetName()] = cfg.ContextWindow
+		if cfg.ContextWindow <= 0 {
+			m.contextWindows[provider.GetName()] = defaultContextWindows[cfg.Type]
+		}
 		m.circuitBreaker[provider.GetName()] = &CircuitBreaker{
 			state: CircuitClosed,
 		}
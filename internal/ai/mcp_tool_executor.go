@@ -22,6 +22,12 @@ type RoomService interface {
 	GetRoomByID(ctx context.Context, roomID string) (interface{}, error)
 }
 
+// RoomManager is implemented by room services that persist rooms and entity assignments
+type RoomManager interface {
+	CreateRoom(ctx context.Context, name, description string, attributes map[string]interface{}) (interface{}, error)
+	AssignEntityToRoom(ctx context.Context, entityID, roomID, roomName string) (interface{}, error)
+}
+
 type SystemService interface {
 	GetSystemStatus(ctx context.Context) (interface{}, error)
 	AnalyzePatterns(ctx context.Context, entityIDs []interface{}, timeRange, analysisType string) (interface{}, error)
@@ -159,6 +165,24 @@ func (w *RoomServiceWrapper) GetRoomByID(ctx context.Context, roomID string) (in
 	}, nil
 }
 
+// CreateRoom creates a room when the wrapped service supports it
+func (w *RoomServiceWrapper) CreateRoom(ctx context.Context, name, description string, attributes map[string]interface{}) (interface{}, error) {
+	manager, ok := w.service.(RoomManager)
+	if !ok {
+		return nil, fmt.Errorf("room service does not support creating rooms")
+	}
+	return manager.CreateRoom(ctx, name, description, attributes)
+}
+
+// AssignEntityToRoom assigns an entity to a room when the wrapped service supports it
+func (w *RoomServiceWrapper) AssignEntityToRoom(ctx context.Context, entityID, roomID, roomName string) (interface{}, error) {
+	manager, ok := w.service.(RoomManager)
+	if !ok {
+		return nil, fmt.Errorf("room service does not support assigning entities")
+	}
+	return manager.AssignEntityToRoom(ctx, entityID, roomID, roomName)
+}
+
 // SystemServiceWrapper wraps the concrete system service
 type SystemServiceWrapper struct {
 	service interface{} // Will be *system.Service at runtime
@@ -741,12 +765,21 @@ func (e *MCPToolExecutor) executeAssignEntityToRoom(ctx context.Context, params
 		return nil, fmt.Errorf("either room_id or room_name is required")
 	}
 
+	manager, ok := e.roomService.(RoomManager)
+	if !ok {
+		return nil, fmt.Errorf("room service does not support assigning entities")
+	}
+
+	room, err := manager.AssignEntityToRoom(ctx, entityID, roomID, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign entity to room: %w", err)
+	}
+
 	return map[string]interface{}{
 		"success":   true,
-		"message":   "Entity assignment ready - functionality will be wired to unified system",
+		"message":   "Entity assigned to room",
 		"entity_id": entityID,
-		"room_id":   roomID,
-		"room_name": roomName,
+		"room":      room,
 		"force":     force,
 	}, nil
 }
@@ -762,13 +795,28 @@ func (e *MCPToolExecutor) executeCreateRoom(ctx context.Context, params map[stri
 	floor, _ := params["floor"].(string)
 	roomType, _ := params["room_type"].(string)
 
+	manager, ok := e.roomService.(RoomManager)
+	if !ok {
+		return nil, fmt.Errorf("room service does not support creating rooms")
+	}
+
+	attributes := make(map[string]interface{})
+	if floor != "" {
+		attributes["floor"] = floor
+	}
+	if roomType != "" {
+		attributes["room_type"] = roomType
+	}
+
+	room, err := manager.CreateRoom(ctx, name, description, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create room: %w", err)
+	}
+
 	return map[string]interface{}{
-		"success":     true,
-		"message":     "Room creation ready - functionality will be wired to unified system",
-		"name":        name,
-		"description": description,
-		"floor":       floor,
-		"room_type":   roomType,
+		"success": true,
+		"message": "Room created",
+		"room":    room,
 	}, nil
 }
 
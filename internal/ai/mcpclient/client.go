@@ -0,0 +1,290 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrConnectionClosed is returned for requests pending when the connection ends
+var ErrConnectionClosed = errors.New("mcp connection closed")
+
+// clientInfo identifies PMA to servers
+var clientInfo = Implementation{Name: "pma-backend", Version: "1.0.0"}
+
+// Client is a JSON-RPC client for one MCP server
+type Client struct {
+	transport Transport
+	timeout   time.Duration
+	logger    *logrus.Logger
+
+	nextID  atomic.Int64
+	mu      sync.Mutex
+	pending map[int64]chan *incoming
+	closed  chan struct{}
+
+	server         InitializeResult
+	onToolsChanged func()
+}
+
+// NewClient creates a client on top of a transport; timeout bounds every request
+func NewClient(transport Transport, timeout time.Duration, logger *logrus.Logger) *Client {
+	return &Client{
+		transport: transport,
+		timeout:   timeout,
+		logger:    logger,
+		pending:   make(map[int64]chan *incoming),
+		closed:    make(chan struct{}),
+	}
+}
+
+// OnToolsChanged registers a callback for the server's tools/list_changed notification
+func (c *Client) OnToolsChanged(fn func()) {
+	c.onToolsChanged = fn
+}
+
+// Connect starts the transport and performs the initialize handshake
+func (c *Client) Connect(ctx context.Context) (*InitializeResult, error) {
+	if err := c.transport.Start(ctx); err != nil {
+		return nil, err
+	}
+	go c.readLoop()
+
+	params := initializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      clientInfo,
+	}
+	if err := c.call(ctx, "initialize", params, &c.server); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("initialized notification failed: %w", err)
+	}
+
+	return &c.server, nil
+}
+
+// Server returns the server's answer to the initialize handshake
+func (c *Client) Server() *InitializeResult {
+	return &c.server
+}
+
+// Done is closed when the connection ends
+func (c *Client) Done() <-chan struct{} {
+	return c.closed
+}
+
+// Close ends the connection
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+// Ping checks that the server is responsive
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, "ping", nil, nil)
+}
+
+// ListTools returns all tools offered by the server
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	cursor := ""
+	for {
+		var result listToolsResult
+		if err := c.call(ctx, "tools/list", paginatedParams{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// CallTool calls a tool; a tool that ran but failed returns a result with IsError set
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	var result CallToolResult
+	if err := c.call(ctx, "tools/call", callToolParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListResources returns all resources offered by the server
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	cursor := ""
+	for {
+		var result listResourcesResult
+		if err := c.call(ctx, "resources/list", paginatedParams{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		resources = append(resources, result.Resources...)
+		if result.NextCursor == "" {
+			return resources, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// ReadResource reads one resource
+func (c *Client) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	var result ReadResourceResult
+	if err := c.call(ctx, "resources/read", readResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// call sends a request and waits for its response
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	id := c.nextID.Add(1)
+	responses := make(chan *incoming, 1)
+
+	c.mu.Lock()
+	c.pending[id] = responses
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	if err := c.send(ctx, &message{ID: json.RawMessage(strconv.FormatInt(id, 10)), Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-responses:
+		if response.Error != nil {
+			return response.Error
+		}
+		if result != nil && len(response.Result) > 0 {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.notify(context.Background(), "notifications/cancelled", cancelledParams{RequestID: id, Reason: ctx.Err().Error()})
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	case <-c.closed:
+		return ErrConnectionClosed
+	}
+}
+
+// notify sends a notification
+func (c *Client) notify(ctx context.Context, method string, params interface{}) error {
+	return c.send(ctx, &message{Method: method, Params: params})
+}
+
+func (c *Client) send(ctx context.Context, msg *message) error {
+	msg.JSONRPC = jsonRPCVersion
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", msg.Method, err)
+	}
+	return c.transport.Send(ctx, data)
+}
+
+func (c *Client) readLoop() {
+	defer close(c.closed)
+	for {
+		select {
+		case data := <-c.transport.Messages():
+			c.handle(data)
+		case <-c.transport.Done():
+			// Handle whatever arrived before the connection ended
+			for {
+				select {
+				case data := <-c.transport.Messages():
+					c.handle(data)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) handle(data []byte) {
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			c.logger.WithError(err).Warn("Invalid MCP message batch")
+			return
+		}
+		for _, item := range batch {
+			c.handle(item)
+		}
+		return
+	}
+
+	var msg incoming
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.logger.WithError(err).Warn("Invalid MCP message")
+		return
+	}
+
+	switch {
+	case msg.Method != "" && len(msg.ID) > 0:
+		go c.handleRequest(&msg)
+	case msg.Method != "":
+		c.handleNotification(&msg)
+	default:
+		id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		responses := c.pending[id]
+		c.mu.Unlock()
+		if responses != nil {
+			responses <- &msg
+		}
+	}
+}
+
+// handleRequest answers requests the server sends to the client
+func (c *Client) handleRequest(msg *incoming) {
+	response := &message{ID: msg.ID}
+	switch msg.Method {
+	case "ping":
+		response.Result = json.RawMessage("{}")
+	default:
+		response.Error = &RPCError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.send(ctx, response); err != nil {
+		c.logger.WithError(err).WithField("method", msg.Method).Debug("Failed to answer MCP server request")
+	}
+}
+
+func (c *Client) handleNotification(msg *incoming) {
+	switch msg.Method {
+	case "notifications/tools/list_changed":
+		if c.onToolsChanged != nil {
+			go c.onToolsChanged()
+		}
+	case "notifications/message":
+		c.logger.WithField("params", string(msg.Params)).Debug("MCP server log message")
+	}
+}
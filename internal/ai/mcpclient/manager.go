@@ -0,0 +1,781 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/sirupsen/logrus"
+)
+
+// Errors returned by the manager
+var (
+	ErrServerNotFound     = errors.New("MCP server not found")
+	ErrServerExists       = errors.New("MCP server already exists")
+	ErrInvalidServer      = errors.New("invalid MCP server")
+	ErrServerNotConnected = errors.New("MCP server not connected")
+	ErrToolNotFound       = errors.New("MCP tool not found")
+)
+
+// ToolSeparator joins a server ID and a tool name into the name offered to the LLM
+const ToolSeparator = "__"
+
+// Server statuses
+const (
+	StatusConnecting   = "connecting"
+	StatusConnected    = "connected"
+	StatusReconnecting = "reconnecting"
+	StatusDisconnected = "disconnected"
+	StatusError        = "error"
+	StatusDisabled     = "disabled"
+)
+
+const (
+	defaultTimeout     = 30
+	defaultMaxRestarts = 3
+)
+
+var (
+	serverIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+	toolNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+)
+
+// ServerStore persists server configurations
+type ServerStore interface {
+	SaveServer(ctx context.Context, server *ai.MCPServer) error
+	GetServers(ctx context.Context) ([]*ai.MCPServer, error)
+	DeleteServer(ctx context.Context, id string) error
+}
+
+// ToolInfo is a tool offered by a connected server
+type ToolInfo struct {
+	Name          string                 `json:"name"`
+	QualifiedName string                 `json:"qualifiedName"`
+	Description   string                 `json:"description"`
+	InputSchema   map[string]interface{} `json:"inputSchema"`
+	ServerID      string                 `json:"serverId"`
+}
+
+// ResourceInfo is a resource offered by a connected server
+type ResourceInfo struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+	ServerID    string `json:"serverId"`
+}
+
+// ServerStatistics counts the traffic to one server
+type ServerStatistics struct {
+	ToolCalls        int64     `json:"toolCalls"`
+	ResourceReads    int64     `json:"resourceReads"`
+	Errors           int64     `json:"errors"`
+	Uptime           int64     `json:"uptime"`
+	LastToolCall     time.Time `json:"lastToolCall"`
+	LastResourceRead time.Time `json:"lastResourceRead"`
+}
+
+// ServerStatus is a snapshot of one server
+type ServerStatus struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Transport    string           `json:"transport"`
+	Status       string           `json:"status"`
+	PID          int              `json:"pid,omitempty"`
+	Uptime       int64            `json:"uptime,omitempty"`
+	LastError    string           `json:"lastError,omitempty"`
+	RestartCount int              `json:"restartCount"`
+	ServerInfo   *Implementation  `json:"serverInfo,omitempty"`
+	Tools        []ToolInfo       `json:"tools"`
+	Resources    []ResourceInfo   `json:"resources"`
+	Statistics   ServerStatistics `json:"statistics"`
+	LastActivity time.Time        `json:"lastActivity"`
+	Config       *ai.MCPServer    `json:"config"`
+}
+
+// server is the live state of one configured server
+type server struct {
+	config       *ai.MCPServer
+	client       *Client
+	transport    Transport
+	status       string
+	lastError    string
+	restarts     int
+	connectedAt  time.Time
+	lastActivity time.Time
+	info         *Implementation
+	tools        []Tool
+	toolNames    map[string]string // qualified name -> tool name on the server
+	resources    []Resource
+	stats        ServerStatistics
+
+	// generation invalidates the supervision of earlier connections
+	generation int
+	cancel     context.CancelFunc
+}
+
+// Manager connects to the configured servers, keeps them connected and routes tool calls
+type Manager struct {
+	store  ServerStore
+	logger *logrus.Logger
+
+	mu      sync.RWMutex
+	servers map[string]*server
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewManager creates a manager; store may be nil to keep configurations in memory only
+func NewManager(store ServerStore, logger *logrus.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		store:      store,
+		logger:     logger,
+		servers:    make(map[string]*server),
+		ctx:        ctx,
+		cancel:     cancel,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+}
+
+// Load reads the stored configurations and connects to the enabled servers in the background
+func (m *Manager) Load(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	configs, err := m.store.GetServers(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	for _, config := range configs {
+		m.servers[config.ID] = newServer(config)
+	}
+	m.mu.Unlock()
+
+	for _, config := range configs {
+		if config.Enabled {
+			go m.Connect(m.ctx, config.ID)
+		}
+	}
+
+	m.logger.WithField("servers", len(configs)).Info("Loaded MCP servers")
+	return nil
+}
+
+// AddServer validates, stores and, when enabled, connects a new server in the background
+func (m *Manager) AddServer(ctx context.Context, config *ai.MCPServer) error {
+	if err := normalizeConfig(config); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if _, exists := m.servers[config.ID]; exists {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrServerExists, config.ID)
+	}
+	if m.store != nil {
+		if err := m.store.SaveServer(ctx, config); err != nil {
+			m.mu.Unlock()
+			return err
+		}
+	}
+	m.servers[config.ID] = newServer(config)
+	m.mu.Unlock()
+
+	m.logger.WithFields(logrus.Fields{"server": config.ID, "transport": config.Transport}).Info("Added MCP server")
+
+	if config.Enabled {
+		go m.Connect(m.ctx, config.ID)
+	}
+	return nil
+}
+
+// RemoveServer disconnects and deletes a server
+func (m *Manager) RemoveServer(ctx context.Context, id string) error {
+	m.mu.Lock()
+	s, exists := m.servers[id]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrServerNotFound, id)
+	}
+	if m.store != nil {
+		if err := m.store.DeleteServer(ctx, id); err != nil {
+			m.mu.Unlock()
+			return err
+		}
+	}
+	delete(m.servers, id)
+	client := m.stopLocked(s)
+	m.mu.Unlock()
+
+	if client != nil {
+		client.Close()
+	}
+	m.logger.WithField("server", id).Info("Removed MCP server")
+	return nil
+}
+
+// Connect connects to a server and waits for the handshake. If the attempt fails and the
+// server restarts automatically, reconnection continues in the background.
+func (m *Manager) Connect(ctx context.Context, id string) error {
+	m.mu.Lock()
+	s, exists := m.servers[id]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrServerNotFound, id)
+	}
+	previous := m.stopLocked(s)
+	supervision, cancel := context.WithCancel(m.ctx)
+	s.generation++
+	s.cancel = cancel
+	s.status = StatusConnecting
+	s.lastError = ""
+	generation := s.generation
+	m.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+
+	err := m.dial(ctx, supervision, s, generation)
+	if err != nil {
+		m.setError(s, generation, err)
+		if s.config.AutoRestart {
+			go m.reconnect(supervision, s, generation)
+		}
+	}
+	return err
+}
+
+// Disconnect closes the connection to a server and stops reconnecting
+func (m *Manager) Disconnect(id string) error {
+	m.mu.Lock()
+	s, exists := m.servers[id]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrServerNotFound, id)
+	}
+	client := m.stopLocked(s)
+	s.status = StatusDisconnected
+	m.mu.Unlock()
+
+	if client != nil {
+		client.Close()
+	}
+	m.logger.WithField("server", id).Info("Disconnected MCP server")
+	return nil
+}
+
+// Restart reconnects to a server and resets its restart count
+func (m *Manager) Restart(ctx context.Context, id string) error {
+	m.mu.Lock()
+	s, exists := m.servers[id]
+	if exists {
+		s.restarts = 0
+	}
+	m.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServerNotFound, id)
+	}
+	return m.Connect(ctx, id)
+}
+
+// Shutdown disconnects from every server
+func (m *Manager) Shutdown() {
+	m.cancel()
+
+	m.mu.Lock()
+	var clients []*Client
+	for _, s := range m.servers {
+		if client := m.stopLocked(s); client != nil {
+			clients = append(clients, client)
+		}
+		s.status = StatusDisconnected
+	}
+	m.mu.Unlock()
+
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
+// Servers returns a snapshot of every server, sorted by name
+func (m *Manager) Servers() []*ServerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]*ServerStatus, 0, len(m.servers))
+	for _, s := range m.servers {
+		statuses = append(statuses, s.snapshot())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Server returns a snapshot of one server
+func (m *Manager) Server(id string) (*ServerStatus, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, exists := m.servers[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServerNotFound, id)
+	}
+	return s.snapshot(), nil
+}
+
+// Tools returns the tools of one server, or of every connected server if serverID is empty
+func (m *Manager) Tools(serverID string) []ToolInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tools := []ToolInfo{}
+	for id, s := range m.servers {
+		if (serverID != "" && id != serverID) || s.status != StatusConnected {
+			continue
+		}
+		tools = append(tools, s.toolInfos()...)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].QualifiedName < tools[j].QualifiedName })
+	return tools
+}
+
+// Resources returns the resources of one server, or of every connected server if serverID is empty
+func (m *Manager) Resources(serverID string) []ResourceInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	resources := []ResourceInfo{}
+	for id, s := range m.servers {
+		if (serverID != "" && id != serverID) || s.status != StatusConnected {
+			continue
+		}
+		for _, resource := range s.resources {
+			resources = append(resources, ResourceInfo{
+				URI:         resource.URI,
+				Name:        resource.Name,
+				Description: resource.Description,
+				MimeType:    resource.MimeType,
+				ServerID:    id,
+			})
+		}
+	}
+	return resources
+}
+
+// CallTool calls a tool on a server by its name on that server
+func (m *Manager) CallTool(ctx context.Context, serverID, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	s, client, err := m.connected(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.CallTool(ctx, name, arguments)
+
+	m.mu.Lock()
+	s.stats.ToolCalls++
+	s.stats.LastToolCall = time.Now()
+	s.lastActivity = s.stats.LastToolCall
+	if err != nil || result.IsError {
+		s.stats.Errors++
+	}
+	m.mu.Unlock()
+
+	return result, err
+}
+
+// ReadResource reads a resource from a server
+func (m *Manager) ReadResource(ctx context.Context, serverID, uri string) (*ReadResourceResult, error) {
+	s, client, err := m.connected(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.ReadResource(ctx, uri)
+
+	m.mu.Lock()
+	s.stats.ResourceReads++
+	s.stats.LastResourceRead = time.Now()
+	s.lastActivity = s.stats.LastResourceRead
+	if err != nil {
+		s.stats.Errors++
+	}
+	m.mu.Unlock()
+
+	return result, err
+}
+
+// LLMTools returns the tools of every connected server, named <server>__<tool>
+func (m *Manager) LLMTools() []ai.LLMTool {
+	tools := m.Tools("")
+	llmTools := make([]ai.LLMTool, 0, len(tools))
+	for _, tool := range tools {
+		llmTools = append(llmTools, ai.LLMTool{
+			Name:        tool.QualifiedName,
+			Description: tool.Description,
+			Parameters:  tool.InputSchema,
+		})
+	}
+	return llmTools
+}
+
+// HandlesTool reports whether a tool name offered to the LLM belongs to a connected server
+func (m *Manager) HandlesTool(name string) bool {
+	_, _, err := m.resolve(name)
+	return err == nil
+}
+
+// ExecuteTool calls a tool by the name offered to the LLM and returns its result as text
+func (m *Manager) ExecuteTool(ctx context.Context, name string, arguments map[string]interface{}) (string, error) {
+	serverID, toolName, err := m.resolve(name)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := m.CallTool(ctx, serverID, toolName, arguments)
+	if err != nil {
+		return "", err
+	}
+	if result.IsError {
+		return result.Text(), fmt.Errorf("tool %s failed: %s", name, result.Text())
+	}
+	return result.Text(), nil
+}
+
+// resolve maps a qualified tool name to its server and the tool name on that server
+func (m *Manager) resolve(name string) (string, string, error) {
+	serverID, _, found := strings.Cut(name, ToolSeparator)
+	if !found {
+		return "", "", fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, exists := m.servers[serverID]
+	if !exists || s.status != StatusConnected {
+		return "", "", fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	toolName, exists := s.toolNames[name]
+	if !exists {
+		return "", "", fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	return serverID, toolName, nil
+}
+
+func (m *Manager) connected(id string) (*server, *Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, exists := m.servers[id]
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: %s", ErrServerNotFound, id)
+	}
+	if s.status != StatusConnected || s.client == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrServerNotConnected, id)
+	}
+	return s, s.client, nil
+}
+
+// dial opens a connection, performs the handshake and discovers tools and resources
+func (m *Manager) dial(ctx, supervision context.Context, s *server, generation int) error {
+	transport, err := newTransport(s.config, m.logger)
+	if err != nil {
+		return err
+	}
+
+	client := NewClient(transport, time.Duration(s.config.Timeout)*time.Second, m.logger)
+	client.OnToolsChanged(func() { m.refreshTools(s, generation, client) })
+
+	info, err := client.Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	var resources []Resource
+	if info.Capabilities.Resources != nil {
+		if resources, err = client.ListResources(ctx); err != nil {
+			m.logger.WithError(err).WithField("server", s.config.ID).Warn("Failed to list MCP resources")
+		}
+	}
+
+	m.mu.Lock()
+	if s.generation != generation || supervision.Err() != nil {
+		// Disconnected or reconnected while the handshake was running
+		m.mu.Unlock()
+		client.Close()
+		return nil
+	}
+	s.client = client
+	s.transport = transport
+	s.status = StatusConnected
+	s.lastError = ""
+	s.connectedAt = time.Now()
+	s.lastActivity = s.connectedAt
+	s.info = &info.ServerInfo
+	s.setTools(tools)
+	s.resources = resources
+	m.mu.Unlock()
+
+	m.logger.WithFields(logrus.Fields{
+		"server":    s.config.ID,
+		"tools":     len(tools),
+		"resources": len(resources),
+	}).Info("Connected to MCP server")
+
+	go m.supervise(supervision, s, generation, client)
+	return nil
+}
+
+// supervise waits for a connection to end and reconnects if the server restarts automatically
+func (m *Manager) supervise(supervision context.Context, s *server, generation int, client *Client) {
+	select {
+	case <-client.Done():
+	case <-supervision.Done():
+		return
+	}
+
+	m.mu.Lock()
+	if s.generation != generation || supervision.Err() != nil {
+		m.mu.Unlock()
+		return
+	}
+	s.client = nil
+	s.transport = nil
+	s.status = StatusDisconnected
+	s.lastError = "connection lost"
+	s.stats.Errors++
+	autoRestart := s.config.AutoRestart
+	m.mu.Unlock()
+
+	m.logger.WithField("server", s.config.ID).Warn("Lost connection to MCP server")
+
+	if autoRestart {
+		m.reconnect(supervision, s, generation)
+	}
+}
+
+// reconnect retries with exponential backoff until connected, stopped or out of restarts
+func (m *Manager) reconnect(supervision context.Context, s *server, generation int) {
+	backoff := m.minBackoff
+	for {
+		m.mu.Lock()
+		if s.generation != generation || supervision.Err() != nil {
+			m.mu.Unlock()
+			return
+		}
+		// A negative limit retries forever
+		if s.config.MaxRestarts >= 0 && s.restarts >= s.config.MaxRestarts {
+			s.status = StatusError
+			s.lastError = fmt.Sprintf("gave up after %d restarts: %s", s.restarts, s.lastError)
+			m.mu.Unlock()
+			m.logger.WithField("server", s.config.ID).Error("Giving up reconnecting to MCP server")
+			return
+		}
+		s.restarts++
+		s.status = StatusReconnecting
+		m.mu.Unlock()
+
+		select {
+		case <-time.After(backoff):
+		case <-supervision.Done():
+			return
+		}
+
+		err := m.dial(supervision, supervision, s, generation)
+		if err == nil {
+			return
+		}
+		m.setError(s, generation, err)
+		m.logger.WithError(err).WithFields(logrus.Fields{
+			"server":  s.config.ID,
+			"backoff": backoff,
+		}).Warn("Failed to reconnect to MCP server")
+
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+	}
+}
+
+// refreshTools reloads the tools of a server after it announced a change
+func (m *Manager) refreshTools(s *server, generation int, client *Client) {
+	ctx, cancel := context.WithTimeout(m.ctx, time.Duration(s.config.Timeout)*time.Second)
+	defer cancel()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		m.logger.WithError(err).WithField("server", s.config.ID).Warn("Failed to refresh MCP tools")
+		return
+	}
+
+	m.mu.Lock()
+	if s.generation == generation {
+		s.setTools(tools)
+	}
+	m.mu.Unlock()
+}
+
+func (m *Manager) setError(s *server, generation int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s.generation != generation {
+		return
+	}
+	s.status = StatusError
+	s.lastError = err.Error()
+	s.stats.Errors++
+}
+
+// stopLocked cancels the supervision of a server and returns its client for closing
+func (m *Manager) stopLocked(s *server) *Client {
+	s.generation++
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	client := s.client
+	s.client = nil
+	s.transport = nil
+	s.tools = nil
+	s.toolNames = nil
+	s.resources = nil
+	return client
+}
+
+func newServer(config *ai.MCPServer) *server {
+	status := StatusDisconnected
+	if !config.Enabled {
+		status = StatusDisabled
+	}
+	return &server{config: config, status: status}
+}
+
+func (s *server) setTools(tools []Tool) {
+	s.tools = tools
+	s.toolNames = make(map[string]string, len(tools))
+	for _, tool := range tools {
+		s.toolNames[QualifiedToolName(s.config.ID, tool.Name)] = tool.Name
+	}
+}
+
+func (s *server) toolInfos() []ToolInfo {
+	infos := make([]ToolInfo, 0, len(s.tools))
+	for _, tool := range s.tools {
+		infos = append(infos, ToolInfo{
+			Name:          tool.Name,
+			QualifiedName: QualifiedToolName(s.config.ID, tool.Name),
+			Description:   tool.Description,
+			InputSchema:   tool.InputSchema,
+			ServerID:      s.config.ID,
+		})
+	}
+	return infos
+}
+
+func (s *server) snapshot() *ServerStatus {
+	status := &ServerStatus{
+		ID:           s.config.ID,
+		Name:         s.config.Name,
+		Description:  s.config.Description,
+		Transport:    s.config.Transport,
+		Status:       s.status,
+		LastError:    s.lastError,
+		RestartCount: s.restarts,
+		ServerInfo:   s.info,
+		Tools:        s.toolInfos(),
+		Resources:    []ResourceInfo{},
+		Statistics:   s.stats,
+		LastActivity: s.lastActivity,
+		Config:       s.config,
+	}
+	for _, resource := range s.resources {
+		status.Resources = append(status.Resources, ResourceInfo{
+			URI:         resource.URI,
+			Name:        resource.Name,
+			Description: resource.Description,
+			MimeType:    resource.MimeType,
+			ServerID:    s.config.ID,
+		})
+	}
+	if s.status == StatusConnected {
+		status.Uptime = int64(time.Since(s.connectedAt).Seconds())
+		status.Statistics.Uptime = status.Uptime
+	}
+	if stdio, ok := s.transport.(*StdioTransport); ok {
+		status.PID = stdio.PID()
+	}
+	return status
+}
+
+// QualifiedToolName returns the name a server's tool is offered to the LLM under
+func QualifiedToolName(serverID, toolName string) string {
+	return serverID + ToolSeparator + toolNameInvalid.ReplaceAllString(toolName, "_")
+}
+
+// normalizeConfig validates a configuration and fills in defaults
+func normalizeConfig(config *ai.MCPServer) error {
+	if !serverIDPattern.MatchString(config.ID) || strings.Contains(config.ID, ToolSeparator) {
+		return fmt.Errorf("%w: id must contain only letters, digits, '-' and single '_'", ErrInvalidServer)
+	}
+	if config.Name == "" {
+		config.Name = config.ID
+	}
+	if config.Transport == "" {
+		config.Transport = ai.MCPTransportStdio
+	}
+
+	switch config.Transport {
+	case ai.MCPTransportStdio:
+		if config.Command == "" {
+			return fmt.Errorf("%w: command is required for stdio servers", ErrInvalidServer)
+		}
+	case ai.MCPTransportHTTP, ai.MCPTransportSSE:
+		if !strings.HasPrefix(config.URL, "http://") && !strings.HasPrefix(config.URL, "https://") {
+			return fmt.Errorf("%w: an http(s) url is required for %s servers", ErrInvalidServer, config.Transport)
+		}
+	default:
+		return fmt.Errorf("%w: unknown transport %q", ErrInvalidServer, config.Transport)
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.MaxRestarts == 0 {
+		config.MaxRestarts = defaultMaxRestarts
+	}
+	return nil
+}
+
+func newTransport(config *ai.MCPServer, logger *logrus.Logger) (Transport, error) {
+	switch config.Transport {
+	case ai.MCPTransportStdio:
+		return NewStdioTransport(config.Command, config.Args, config.Env, config.CWD, logger), nil
+	case ai.MCPTransportHTTP:
+		return NewHTTPTransport(config.URL, config.Headers), nil
+	case ai.MCPTransportSSE:
+		return NewSSETransport(config.URL, config.Headers), nil
+	}
+	return nil, fmt.Errorf("%w: unknown transport %q", ErrInvalidServer, config.Transport)
+}
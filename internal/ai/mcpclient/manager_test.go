@@ -0,0 +1,321 @@
+package mcpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary doubles as a stdio stub server when this variable is set
+const stubServerEnv = "PMA_MCP_STUB_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubServerEnv) == "1" {
+		runStdioStub()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// stubHandle answers one request the way a minimal MCP server would; nil means no response
+func stubHandle(msg *incoming) *message {
+	if len(msg.ID) == 0 {
+		return nil
+	}
+
+	response := &message{JSONRPC: jsonRPCVersion, ID: msg.ID}
+	result := func(v interface{}) {
+		data, _ := json.Marshal(v)
+		response.Result = data
+	}
+
+	switch msg.Method {
+	case "initialize":
+		result(InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities: ServerCapabilities{
+				Tools:     map[string]interface{}{"listChanged": false},
+				Resources: map[string]interface{}{"subscribe": false},
+			},
+			ServerInfo: Implementation{Name: "stub", Version: "0.1.0"},
+		})
+	case "tools/list":
+		result(listToolsResult{Tools: []Tool{
+			{Name: "echo", Description: "Echoes its text", InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "fail", Description: "Always fails", InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "crash", Description: "Exits the server", InputSchema: map[string]interface{}{"type": "object"}},
+		}})
+	case "tools/call":
+		var params callToolParams
+		json.Unmarshal(msg.Params, &params)
+		switch params.Name {
+		case "echo":
+			result(CallToolResult{Content: []Content{{Type: "text", Text: fmt.Sprintf("echo: %v", params.Arguments["text"])}}})
+		case "fail":
+			result(CallToolResult{Content: []Content{{Type: "text", Text: "it broke"}}, IsError: true})
+		case "crash":
+			os.Exit(1)
+		default:
+			response.Error = &RPCError{Code: -32602, Message: "unknown tool"}
+		}
+	case "resources/list":
+		result(listResourcesResult{Resources: []Resource{{URI: "stub://readme", Name: "readme", MimeType: "text/plain"}}})
+	case "resources/read":
+		var params readResourceParams
+		json.Unmarshal(msg.Params, &params)
+		result(ReadResourceResult{Contents: []ResourceContents{{URI: params.URI, MimeType: "text/plain", Text: "hello from stub"}}})
+	default:
+		response.Error = &RPCError{Code: codeMethodNotFound, Message: "method not found"}
+	}
+	return response
+}
+
+func runStdioStub() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg incoming
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if response := stubHandle(&msg); response != nil {
+			data, _ := json.Marshal(response)
+			os.Stdout.Write(append(data, '\n'))
+		}
+	}
+}
+
+// newHTTPStub serves the stub over streamable HTTP, answering tools/call as an event stream
+func newHTTPStub(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var msg incoming
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &msg))
+
+		if msg.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else if r.Header.Get("Mcp-Session-Id") != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+
+		response := stubHandle(&msg)
+		if response == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		data, _ := json.Marshal(response)
+
+		if msg.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newSSEStub serves the stub over the legacy HTTP+SSE transport
+func newSSEStub(t *testing.T) *httptest.Server {
+	events := make(chan []byte, 16)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case data := <-events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		var msg incoming
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &msg))
+		if response := stubHandle(&msg); response != nil {
+			data, _ := json.Marshal(response)
+			events <- data
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestManager(t *testing.T) *Manager {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	manager := NewManager(nil, logger)
+	manager.minBackoff = 10 * time.Millisecond
+	manager.maxBackoff = 50 * time.Millisecond
+	t.Cleanup(manager.Shutdown)
+	return manager
+}
+
+func stdioStubConfig(id string) *ai.MCPServer {
+	return &ai.MCPServer{
+		ID:          id,
+		Transport:   ai.MCPTransportStdio,
+		Command:     os.Args[0],
+		Args:        []string{"-test.run=^$"},
+		Env:         map[string]string{stubServerEnv: "1"},
+		Timeout:     5,
+		AutoRestart: true,
+	}
+}
+
+func waitForStatus(t *testing.T, manager *Manager, id, status string) *ServerStatus {
+	var server *ServerStatus
+	require.Eventually(t, func() bool {
+		server, _ = manager.Server(id)
+		return server != nil && server.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return server
+}
+
+func assertStubServer(t *testing.T, manager *Manager, id string) {
+	ctx := context.Background()
+
+	names := []string{}
+	for _, tool := range manager.LLMTools() {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{id + "__crash", id + "__echo", id + "__fail"}, names)
+
+	require.True(t, manager.HandlesTool(id+"__echo"))
+	assert.False(t, manager.HandlesTool("other__echo"))
+
+	text, err := manager.ExecuteTool(ctx, id+"__echo", map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "echo: hi", text)
+
+	text, err = manager.ExecuteTool(ctx, id+"__fail", nil)
+	assert.Error(t, err)
+	assert.Equal(t, "it broke", text)
+
+	resources := manager.Resources(id)
+	require.Len(t, resources, 1)
+	contents, err := manager.ReadResource(ctx, id, resources[0].URI)
+	require.NoError(t, err)
+	assert.Equal(t, "hello from stub", contents.Contents[0].Text)
+
+	server, err := manager.Server(id)
+	require.NoError(t, err)
+	assert.Equal(t, "stub", server.ServerInfo.Name)
+	assert.Equal(t, int64(2), server.Statistics.ToolCalls)
+	assert.Equal(t, int64(1), server.Statistics.Errors)
+	assert.Equal(t, int64(1), server.Statistics.ResourceReads)
+}
+
+func TestManager_StdioServer(t *testing.T) {
+	manager := newTestManager(t)
+	config := stdioStubConfig("stub")
+	require.NoError(t, manager.AddServer(context.Background(), config))
+	require.NoError(t, manager.Connect(context.Background(), "stub"))
+
+	server := waitForStatus(t, manager, "stub", StatusConnected)
+	assert.NotZero(t, server.PID)
+	assertStubServer(t, manager, "stub")
+}
+
+func TestManager_HTTPServer(t *testing.T) {
+	stub := newHTTPStub(t)
+	manager := newTestManager(t)
+	require.NoError(t, manager.AddServer(context.Background(), &ai.MCPServer{
+		ID:        "web",
+		Transport: ai.MCPTransportHTTP,
+		URL:       stub.URL,
+		Timeout:   5,
+	}))
+	require.NoError(t, manager.Connect(context.Background(), "web"))
+
+	assertStubServer(t, manager, "web")
+}
+
+func TestManager_SSEServer(t *testing.T) {
+	stub := newSSEStub(t)
+	manager := newTestManager(t)
+	require.NoError(t, manager.AddServer(context.Background(), &ai.MCPServer{
+		ID:        "legacy",
+		Transport: ai.MCPTransportSSE,
+		URL:       stub.URL + "/sse",
+		Timeout:   5,
+	}))
+	require.NoError(t, manager.Connect(context.Background(), "legacy"))
+
+	assertStubServer(t, manager, "legacy")
+}
+
+func TestManager_ReconnectsAfterCrash(t *testing.T) {
+	manager := newTestManager(t)
+	require.NoError(t, manager.AddServer(context.Background(), stdioStubConfig("stub")))
+	require.NoError(t, manager.Connect(context.Background(), "stub"))
+
+	_, err := manager.ExecuteTool(context.Background(), "stub__crash", nil)
+	assert.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		server, _ := manager.Server("stub")
+		return server.Status == StatusConnected && server.RestartCount == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	text, err := manager.ExecuteTool(context.Background(), "stub__echo", map[string]interface{}{"text": "again"})
+	require.NoError(t, err)
+	assert.Equal(t, "echo: again", text)
+}
+
+func TestManager_GivesUpAfterMaxRestarts(t *testing.T) {
+	manager := newTestManager(t)
+	config := stdioStubConfig("broken")
+	config.Command = "/nonexistent/mcp-server"
+	config.MaxRestarts = 2
+	require.NoError(t, manager.AddServer(context.Background(), config))
+
+	assert.Error(t, manager.Connect(context.Background(), "broken"))
+
+	require.Eventually(t, func() bool {
+		server, _ := manager.Server("broken")
+		return server.Status == StatusError && strings.Contains(server.LastError, "gave up after 2 restarts")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, manager.LLMTools())
+}
+
+func TestManager_RejectsInvalidServers(t *testing.T) {
+	manager := newTestManager(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, manager.AddServer(ctx, &ai.MCPServer{ID: "a__b", Command: "x"}), ErrInvalidServer)
+	assert.ErrorIs(t, manager.AddServer(ctx, &ai.MCPServer{ID: "nocmd"}), ErrInvalidServer)
+	assert.ErrorIs(t, manager.AddServer(ctx, &ai.MCPServer{ID: "web", Transport: ai.MCPTransportHTTP}), ErrInvalidServer)
+
+	require.NoError(t, manager.AddServer(ctx, &ai.MCPServer{ID: "ok", Command: "x"}))
+	assert.ErrorIs(t, manager.AddServer(ctx, &ai.MCPServer{ID: "ok", Command: "x"}), ErrServerExists)
+	assert.ErrorIs(t, manager.RemoveServer(ctx, "missing"), ErrServerNotFound)
+}
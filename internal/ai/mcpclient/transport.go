@@ -0,0 +1,498 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrTransportClosed is returned when sending on a closed transport
+var ErrTransportClosed = errors.New("mcp transport closed")
+
+// Transport carries JSON-RPC messages between the client and one server
+type Transport interface {
+	// Start opens the connection
+	Start(ctx context.Context) error
+	// Send delivers one encoded message to the server
+	Send(ctx context.Context, data []byte) error
+	// Messages delivers encoded messages received from the server
+	Messages() <-chan []byte
+	// Done is closed when the connection ends
+	Done() <-chan struct{}
+	// Close ends the connection
+	Close() error
+}
+
+// messageBuffer is the number of received messages buffered by a transport
+const messageBuffer = 32
+
+// baseTransport holds the receive side shared by all transports
+type baseTransport struct {
+	messages  chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newBaseTransport() baseTransport {
+	return baseTransport{
+		messages: make(chan []byte, messageBuffer),
+		done:     make(chan struct{}),
+	}
+}
+
+func (t *baseTransport) Messages() <-chan []byte { return t.messages }
+func (t *baseTransport) Done() <-chan struct{}   { return t.done }
+
+// deliver queues a received message unless the transport is closed
+func (t *baseTransport) deliver(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return true
+	}
+	select {
+	case t.messages <- data:
+		return true
+	case <-t.done:
+		return false
+	}
+}
+
+// finish marks the transport closed
+func (t *baseTransport) finish() {
+	t.closeOnce.Do(func() { close(t.done) })
+}
+
+// StdioTransport runs a server as a subprocess speaking newline-delimited JSON-RPC
+type StdioTransport struct {
+	baseTransport
+	command string
+	args    []string
+	env     map[string]string
+	dir     string
+	logger  *logrus.Logger
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+	exited  chan struct{}
+}
+
+// NewStdioTransport creates a transport for a server started with the given command
+func NewStdioTransport(command string, args []string, env map[string]string, dir string, logger *logrus.Logger) *StdioTransport {
+	return &StdioTransport{
+		baseTransport: newBaseTransport(),
+		command:       command,
+		args:          args,
+		env:           env,
+		dir:           dir,
+		logger:        logger,
+		exited:        make(chan struct{}),
+	}
+}
+
+// Start launches the server process
+func (t *StdioTransport) Start(ctx context.Context) error {
+	cmd := exec.Command(t.command, t.args...)
+	cmd.Dir = t.dir
+	cmd.Env = os.Environ()
+	for key, value := range t.env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stderr: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", t.command, err)
+	}
+	t.cmd = cmd
+	t.stdin = stdin
+
+	go t.logStderr(stderr)
+	go t.readStdout(stdout)
+	return nil
+}
+
+// PID returns the process ID of the server
+func (t *StdioTransport) PID() int {
+	if t.cmd == nil || t.cmd.Process == nil {
+		return 0
+	}
+	return t.cmd.Process.Pid
+}
+
+func (t *StdioTransport) readStdout(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && !t.deliver(line) {
+			break
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	err := t.cmd.Wait()
+	close(t.exited)
+	if err != nil {
+		t.logger.WithError(err).WithField("command", t.command).Debug("MCP server process exited")
+	}
+	t.finish()
+}
+
+func (t *StdioTransport) logStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		t.logger.WithField("command", t.command).Debug("MCP server: " + scanner.Text())
+	}
+}
+
+// Send writes one message followed by a newline to the server's stdin
+func (t *StdioTransport) Send(ctx context.Context, data []byte) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to server: %w", err)
+	}
+	return nil
+}
+
+// Close closes stdin and kills the process if it does not exit on its own
+func (t *StdioTransport) Close() error {
+	if t.cmd == nil {
+		t.finish()
+		return nil
+	}
+
+	t.stdin.Close()
+	t.finish()
+	select {
+	case <-t.exited:
+	case <-time.After(2 * time.Second):
+		t.cmd.Process.Kill()
+		<-t.exited
+	}
+	return nil
+}
+
+// HTTPTransport speaks the streamable HTTP transport: every message is POSTed and the
+// response is either a JSON body or an event stream
+type HTTPTransport struct {
+	baseTransport
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+	wg        sync.WaitGroup
+}
+
+// NewHTTPTransport creates a streamable HTTP transport for the given endpoint
+func NewHTTPTransport(endpoint string, headers map[string]string) *HTTPTransport {
+	return &HTTPTransport{
+		baseTransport: newBaseTransport(),
+		url:           endpoint,
+		headers:       headers,
+		client:        &http.Client{},
+	}
+}
+
+// Start is a no-op; the session is established by the initialize request
+func (t *HTTPTransport) Start(ctx context.Context) error {
+	return nil
+}
+
+// Send POSTs one message and queues any messages returned with the response
+func (t *HTTPTransport) Send(ctx context.Context, data []byte) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && t.session() != "":
+		// The server dropped our session; the client has to initialize again
+		resp.Body.Close()
+		t.finish()
+		return fmt.Errorf("session expired")
+	case resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	case resp.StatusCode == http.StatusAccepted:
+		resp.Body.Close()
+		return nil
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// Responses may follow server requests on the stream; read it in the background
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer resp.Body.Close()
+			readEvents(resp.Body, func(event, data string) bool {
+				if event != "" && event != "message" {
+					return true
+				}
+				return t.deliver([]byte(data))
+			})
+		}()
+		return nil
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	t.deliver(body)
+	return nil
+}
+
+func (t *HTTPTransport) session() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+func (t *HTTPTransport) setHeaders(req *http.Request) {
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	if sessionID := t.session(); sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+}
+
+// Close ends the session on the server and stops reading responses
+func (t *HTTPTransport) Close() error {
+	if t.session() != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil); err == nil {
+			t.setHeaders(req)
+			if resp, err := t.client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	t.finish()
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// SSETransport speaks the legacy HTTP+SSE transport: server messages arrive on a GET
+// event stream that first announces the endpoint to POST client messages to
+type SSETransport struct {
+	baseTransport
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	endpoint      string
+	endpointReady chan struct{}
+	cancel        context.CancelFunc
+}
+
+// NewSSETransport creates a legacy HTTP+SSE transport for the given stream URL
+func NewSSETransport(streamURL string, headers map[string]string) *SSETransport {
+	return &SSETransport{
+		baseTransport: newBaseTransport(),
+		url:           streamURL,
+		headers:       headers,
+		client:        &http.Client{},
+		endpointReady: make(chan struct{}),
+	}
+}
+
+// Start opens the event stream and waits for the server to announce its endpoint
+func (t *SSETransport) Start(ctx context.Context) error {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, t.url, nil)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	go func() {
+		defer resp.Body.Close()
+		defer t.finish()
+		readEvents(resp.Body, func(event, data string) bool {
+			switch event {
+			case "endpoint":
+				t.setEndpoint(data)
+				return true
+			case "", "message":
+				return t.deliver([]byte(data))
+			}
+			return true
+		})
+	}()
+
+	select {
+	case <-t.endpointReady:
+		return nil
+	case <-t.done:
+		return fmt.Errorf("event stream closed before the endpoint was announced")
+	case <-ctx.Done():
+		t.Close()
+		return ctx.Err()
+	}
+}
+
+func (t *SSETransport) setEndpoint(data string) {
+	select {
+	case <-t.endpointReady:
+		return
+	default:
+	}
+
+	endpoint := strings.TrimSpace(data)
+	if base, err := url.Parse(t.url); err == nil {
+		if ref, err := url.Parse(endpoint); err == nil {
+			endpoint = base.ResolveReference(ref).String()
+		}
+	}
+	t.endpoint = endpoint
+	close(t.endpointReady)
+}
+
+// Send POSTs one message to the announced endpoint; the answer arrives on the stream
+func (t *SSETransport) Send(ctx context.Context, data []byte) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Close closes the event stream
+func (t *SSETransport) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.finish()
+	return nil
+}
+
+// readEvents parses a server-sent event stream, calling handle for every event until it
+// returns false or the stream ends
+func readEvents(r io.Reader, handle func(event, data string) bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 && !handle(event, strings.Join(data, "\n")) {
+				return
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if len(data) > 0 {
+		handle(event, strings.Join(data, "\n"))
+	}
+}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MemoryProvider keeps long-term memory across conversations: it recalls facts relevant to a
+// message and learns new facts from exchanges
+type MemoryProvider interface {
+	// Recall returns the memories most relevant to the text, best first
+	Recall(ctx context.Context, userID, text string) ([]RecalledMemory, error)
+	// Learn extracts lasting facts from an exchange of a conversation
+	Learn(ctx context.Context, userID, conversationID string, exchange []ChatMessage)
+}
+
+// RecalledMemory is a memory retrieved for a message
+type RecalledMemory struct {
+	ID             int64     `json:"id"`
+	Content        string    `json:"content"`
+	Source         string    `json:"source"` // "conversation" or "note"
+	ConversationID string    `json:"conversation_id,omitempty"`
+	Score          float64   `json:"score"`
+	Cited          bool      `json:"cited"` // Whether the response cites the memory
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// memoryCitationPattern matches memory citations such as [memory:12]
+var memoryCitationPattern = regexp.MustCompile(`\[memory:(\d+)\]`)
+
+// MemoryCitation returns the marker the assistant cites a memory with
+func MemoryCitation(id int64) string {
+	return fmt.Sprintf("[memory:%d]", id)
+}
+
+// MemoryPrompt formats recalled memories as a section of the system prompt
+func MemoryPrompt(memories []RecalledMemory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\nThings you remember about this household from earlier conversations and notes. ")
+	b.WriteString("Use them when relevant and cite each one you rely on with its marker, for example ")
+	b.WriteString(MemoryCitation(memories[0].ID))
+	b.WriteString(". Current device states and tool results take precedence.\n")
+	for _, memory := range memories {
+		origin := "noted"
+		if memory.Source == "conversation" {
+			origin = "learned"
+		}
+		fmt.Fprintf(&b, "- %s %s (%s %s)\n", MemoryCitation(memory.ID), memory.Content, origin, memory.CreatedAt.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+// MarkCitedMemories sets Cited on the memories the response content cites
+func MarkCitedMemories(memories []RecalledMemory, content string) {
+	cited := make(map[int64]bool)
+	for _, match := range memoryCitationPattern.FindAllStringSubmatch(content, -1) {
+		if id, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			cited[id] = true
+		}
+	}
+	for i := range memories {
+		memories[i].Cited = cited[memories[i].ID]
+	}
+}
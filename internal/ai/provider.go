@@ -26,6 +26,28 @@ type LLMProvider interface {
 	Shutdown(ctx context.Context) error
 }
 
+// EmbeddingProvider is implemented by providers that can turn text into embedding vectors
+type EmbeddingProvider interface {
+	// Embed returns one vector per text, using the default embedding model when model is empty
+	Embed(ctx context.Context, texts []string, model string) ([][]float32, error)
+	// EmbeddingModel returns the default embedding model
+	EmbeddingModel() string
+}
+
+// EmbeddingOptions holds options for embedding requests
+type EmbeddingOptions struct {
+	Provider string `json:"provider,omitempty"` // Any provider supporting embeddings when empty
+	Model    string `json:"model,omitempty"`
+}
+
+// EmbeddingResponse holds the vectors of an embedding request and where they came from. Vectors
+// of different providers or models cannot be compared.
+type EmbeddingResponse struct {
+	Vectors  [][]float32 `json:"vectors"`
+	Provider string      `json:"provider"`
+	Model    string      `json:"model"`
+}
+
 // CompletionOptions holds options for text completion requests
 type CompletionOptions struct {
 	Model       string            `json:"model,omitempty"`
@@ -20,6 +20,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ollamaEmbeddingModel is the embedding model used when none is requested
+const ollamaEmbeddingModel = "nomic-embed-text"
+
 // OllamaProvider implements the LLMProvider interface for Ollama
 type OllamaProvider struct {
 	name              string
@@ -411,6 +414,50 @@ func (o *OllamaProvider) GetModels(ctx context.Context) ([]ai.ModelInfo, error)
 	return models, nil
 }
 
+// EmbeddingModel returns the model used for embeddings when none is requested
+func (o *OllamaProvider) EmbeddingModel() string {
+	return ollamaEmbeddingModel
+}
+
+// Embed turns texts into embedding vectors, pulling the embedding model if needed
+func (o *OllamaProvider) Embed(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	if model == "" {
+		model = ollamaEmbeddingModel
+	}
+	if err := o.ensureModelAvailable(ctx, model); err != nil {
+		return nil, err
+	}
+
+	resp, err := o.makeRequest(ctx, "POST", "/api/embed", map[string]interface{}{
+		"model": model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var embedResp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(resp, &embedResp); err != nil {
+		return nil, &ai.ProviderError{
+			Provider:   o.name,
+			Type:       "internal",
+			Message:    "Failed to parse embedding response",
+			Underlying: err,
+		}
+	}
+	if len(embedResp.Embeddings) != len(texts) {
+		return nil, &ai.ProviderError{
+			Provider: o.name,
+			Type:     "model_error",
+			Message:  fmt.Sprintf("Expected %d embeddings, got %d", len(texts), len(embedResp.Embeddings)),
+		}
+	}
+
+	return embedResp.Embeddings, nil
+}
+
 // EstimateTokens provides a rough estimate of token count
 func (o *OllamaProvider) EstimateTokens(text string) int {
 	// Rough estimation: 1 token ≈ 4 characters for English text
@@ -17,6 +17,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// openAIEmbeddingModel is the embedding model used when none is requested
+const openAIEmbeddingModel = "text-embedding-3-small"
+
 // OpenAIProvider implements the LLMProvider interface for OpenAI
 type OpenAIProvider struct {
 	name              string
@@ -515,6 +518,71 @@ func (o *OpenAIProvider) GetModels(ctx context.Context) ([]ai.ModelInfo, error)
 	return models, nil
 }
 
+// EmbeddingModel returns the model used for embeddings when none is requested
+func (o *OpenAIProvider) EmbeddingModel() string {
+	return openAIEmbeddingModel
+}
+
+// Embed turns texts into embedding vectors
+func (o *OpenAIProvider) Embed(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	if !o.IsAvailable(ctx) {
+		return nil, &ai.ProviderError{
+			Provider:  o.name,
+			Type:      "unavailable",
+			Message:   "OpenAI provider is not available",
+			Retryable: true,
+		}
+	}
+	if model == "" {
+		model = openAIEmbeddingModel
+	}
+
+	if err := o.rateLimiter.checkRequest(ctx, o.EstimateTokens(strings.Join(texts, "\n"))); err != nil {
+		return nil, err
+	}
+
+	resp, err := o.makeRequest(ctx, "POST", "/embeddings", map[string]interface{}{
+		"model": model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var embedResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &embedResp); err != nil {
+		return nil, &ai.ProviderError{
+			Provider:   o.name,
+			Type:       "internal",
+			Message:    "Failed to parse embedding response",
+			Underlying: err,
+		}
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range embedResp.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, &ai.ProviderError{
+				Provider: o.name,
+				Type:     "model_error",
+				Message:  fmt.Sprintf("Missing embedding for input %d", i),
+			}
+		}
+	}
+
+	return vectors, nil
+}
+
 // EstimateTokens provides an estimate of token count using OpenAI's rules
 func (o *OpenAIProvider) EstimateTokens(text string) int {
 	// More accurate estimation for OpenAI models
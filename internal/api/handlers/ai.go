@@ -29,6 +29,14 @@ func (h *Handlers) ChatWithAI(c *gin.Context) {
 	// Get chat service from context or handlers
 	chatService := h.getChatService()
 	if chatService == nil {
+		// Common commands are still handled locally without an LLM
+		if h.intentEngine != nil {
+			started := time.Now()
+			if result, ok := h.intentEngine.HandleIntent(c.Request.Context(), ai.LastUserMessage(req.Messages), req.Metadata["locale"]); ok {
+				c.JSON(http.StatusOK, ai.NewIntentChatResponse(result, started))
+				return
+			}
+		}
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
 		return
 	}
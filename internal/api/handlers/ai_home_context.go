@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// homeContextResponse is the home state added to a prompt and the budget it was rendered for
+type homeContextResponse struct {
+	Context *ai.HomeContext `json:"context"`
+	Budget  ai.TokenBudget  `json:"budget"`
+}
+
+// GetHomeContext previews the home state the assistant sees for a message sent to a provider
+func (h *Handlers) GetHomeContext(c *gin.Context) {
+	if h.homeContext == nil || h.llmManager == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Home context not enabled")
+		return
+	}
+
+	message := c.Query("q")
+	if message == "" {
+		utils.SendError(c, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+
+	budget := h.llmManager.ContextBudget(c.Query("provider"), h.cfg.AI.HomeContext.WindowShare, h.cfg.AI.HomeContext.MaxTokens)
+	home, err := h.homeContext.HomeContext(c.Request.Context(), message, budget)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SendSuccess(c, homeContextResponse{Context: home, Budget: budget})
+}
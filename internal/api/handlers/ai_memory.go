@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/memory"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// memoryNoteRequest is a note for the assistant to remember
+type memoryNoteRequest struct {
+	Content string `json:"content" binding:"required"`
+	Shared  bool   `json:"shared,omitempty"` // Remembered for every user of the household, not only the author
+}
+
+// memoriesResponse is a page of remembered facts
+type memoriesResponse struct {
+	Memories []*models.AIMemory `json:"memories"`
+	Total    int                `json:"total"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+}
+
+// requireMemoryService reports whether long-term memory is available
+func (h *Handlers) requireMemoryService(c *gin.Context) bool {
+	if h.memoryService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "AI memory not enabled")
+		return false
+	}
+	return true
+}
+
+// GetMemories lists the facts the assistant remembers for the current user, newest first
+func (h *Handlers) GetMemories(c *gin.Context) {
+	if !h.requireMemoryService(c) {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	memories, total, err := h.memoryService.List(c.Request.Context(), models.AIMemoryFilter{
+		UserID: getUserIDFromContext(c),
+		Source: c.Query("source"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SendSuccess(c, &memoriesResponse{
+		Memories: memories,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// SearchMemories returns the memories the assistant would recall for a message
+func (h *Handlers) SearchMemories(c *gin.Context) {
+	if !h.requireMemoryService(c) {
+		return
+	}
+
+	query := c.Query("q")
+	if query == "" {
+		utils.SendError(c, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	results, err := h.memoryService.Search(c.Request.Context(), getUserIDFromContext(c), query, limit)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccess(c, results)
+}
+
+// CreateMemoryNote stores a note for the assistant to remember
+func (h *Handlers) CreateMemoryNote(c *gin.Context) {
+	if !h.requireMemoryService(c) {
+		return
+	}
+
+	var req memoryNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	note, err := h.memoryService.AddNote(c.Request.Context(), getUserIDFromContext(c), req.Content, req.Shared)
+	if err != nil {
+		if errors.Is(err, memory.ErrEmptyMemory) {
+			utils.SendError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccess(c, note)
+}
+
+// DeleteMemory makes the assistant forget a memory
+func (h *Handlers) DeleteMemory(c *gin.Context) {
+	if !h.requireMemoryService(c) {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid memory ID")
+		return
+	}
+
+	if err := h.memoryService.Delete(c.Request.Context(), getUserIDFromContext(c), id); err != nil {
+		if errors.Is(err, memory.ErrMemoryNotFound) {
+			utils.SendError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccess(c, messageResponse{Message: "Memory deleted"})
+}
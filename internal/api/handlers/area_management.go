@@ -141,7 +141,7 @@ func (h *Handlers) DeleteArea(c *gin.Context) {
 		return
 	}
 
-	utils.SendSuccess(c, gin.H{"message": "Area deleted successfully"})
+	utils.SendSuccess(c, messageResponse{Message: "Area deleted successfully"})
 }
 
 // Area Mapping Endpoints
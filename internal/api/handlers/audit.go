@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/api/middleware"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/audit"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware attributes requests to their actor and records audited mutations
+func (h *Handlers) AuditMiddleware() gin.HandlerFunc {
+	return middleware.AuditMiddleware(h.auditService, h.log)
+}
+
+// requireAuditService reports whether the audit log is available
+func (h *Handlers) requireAuditService(c *gin.Context) bool {
+	if h.auditService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Audit log not available")
+		return false
+	}
+	return true
+}
+
+// auditFilter reads the audit filter from the query string; it sends the error response itself
+func auditFilter(c *gin.Context) (models.AuditFilter, bool) {
+	filter := models.AuditFilter{
+		ActorType: c.Query("actor_type"),
+		ActorID:   c.Query("actor_id"),
+		Category:  c.Query("category"),
+		Action:    c.Query("action"),
+		Target:    c.Query("target"),
+		Outcome:   c.Query("outcome"),
+		Limit:     100,
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 1000 {
+			filter.Limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filter.Offset = parsed
+		}
+	}
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Invalid %s timestamp, expected RFC3339", param))
+				return filter, false
+			}
+			*target = parsed
+		}
+	}
+	return filter, true
+}
+
+// auditLogResponse is one page of audit entries
+type auditLogResponse struct {
+	Entries []*models.AuditEntry `json:"entries"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// GetAuditLog returns audit entries matching the query filters, newest first
+func (h *Handlers) GetAuditLog(c *gin.Context) {
+	if !h.requireAuditService(c) {
+		return
+	}
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.auditService.Query(c.Request.Context(), filter)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to query audit log")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to query audit log")
+		return
+	}
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+
+	utils.SendSuccess(c, &auditLogResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	})
+}
+
+// VerifyAuditLog checks the hash chain of the whole audit log
+func (h *Handlers) VerifyAuditLog(c *gin.Context) {
+	if !h.requireAuditService(c) {
+		return
+	}
+
+	result, err := h.auditService.Verify(c.Request.Context())
+	if err != nil {
+		h.log.WithError(err).Error("Failed to verify audit log")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to verify audit log")
+		return
+	}
+	utils.SendSuccess(c, result)
+}
+
+// ExportAuditLog streams the audit entries matching the query filters, oldest first, as CSV or
+// JSON lines. Paging parameters are ignored.
+func (h *Handlers) ExportAuditLog(c *gin.Context) {
+	if !h.requireAuditService(c) {
+		return
+	}
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", audit.FormatCSV)
+	var contentType string
+	switch format {
+	case audit.FormatCSV:
+		contentType = "text/csv"
+	case audit.FormatJSONL:
+		contentType = "application/x-ndjson"
+	default:
+		utils.SendError(c, http.StatusBadRequest, "Invalid format, expected csv or jsonl")
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	if err := h.auditService.Export(c.Request.Context(), filter, format, c.Writer); err != nil {
+		// Headers are sent already, the truncated download is all the client gets
+		h.log.WithError(err).Error("Failed to export audit log")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -29,6 +30,17 @@ func NewAutomationHandler(engine *automation.AutomationEngine, logger *logrus.Lo
 	}
 }
 
+// automationRulesResponse is the data of the automation rule list
+type automationRulesResponse struct {
+	Rules []*automation.AutomationRule `json:"rules"`
+	Count int                          `json:"count"`
+}
+
+// automationRuleResponse is the data of a single automation rule
+type automationRuleResponse struct {
+	Rule *automation.AutomationRule `json:"rule"`
+}
+
 // GetAutomations returns all automation rules
 func (ah *AutomationHandler) GetAutomations(c *gin.Context) {
 	ah.logger.Debug("Getting all automation rules")
@@ -70,12 +82,9 @@ func (ah *AutomationHandler) GetAutomations(c *gin.Context) {
 		rules = filteredRules
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"rules": rules,
-			"count": len(rules),
-		},
+	utils.SendSuccess(c, automationRulesResponse{
+		Rules: rules,
+		Count: len(rules),
 	})
 }
 
@@ -86,10 +95,7 @@ func (ah *AutomationHandler) CreateAutomation(c *gin.Context) {
 	var requestBody map[string]interface{}
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
 		ah.logger.WithError(err).Error("Failed to parse request body")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-		})
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
@@ -109,20 +115,14 @@ func (ah *AutomationHandler) CreateAutomation(c *gin.Context) {
 		// Convert back to YAML for parsing
 		yamlData, err := json.Marshal(requestBody)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error":   "Failed to process YAML data",
-			})
+			utils.SendError(c, http.StatusBadRequest, "Failed to process YAML data")
 			return
 		}
 		rule, err = ah.parser.ParseFromJSON(yamlData) // Parse as JSON for now
 	} else {
 		jsonData, err := json.Marshal(requestBody)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error":   "Failed to process JSON data",
-			})
+			utils.SendError(c, http.StatusBadRequest, "Failed to process JSON data")
 			return
 		}
 		rule, err = ah.parser.ParseFromJSON(jsonData)
@@ -130,30 +130,21 @@ func (ah *AutomationHandler) CreateAutomation(c *gin.Context) {
 
 	if err != nil {
 		ah.logger.WithError(err).Error("Failed to parse automation rule")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("Failed to parse rule: %v", err),
-		})
+		utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Failed to parse rule: %v", err))
 		return
 	}
 
 	// Additional safety check to prevent nil rule
 	if rule == nil {
 		ah.logger.Error("Parsed rule is nil")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Failed to parse rule: parsed rule is nil",
-		})
+		utils.SendError(c, http.StatusBadRequest, "Failed to parse rule: parsed rule is nil")
 		return
 	}
 
 	// Add rule to engine
 	if err := ah.engine.AddRule(rule); err != nil {
 		ah.logger.WithError(err).Error("Failed to add automation rule")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("Failed to add rule: %v", err),
-		})
+		utils.SendError(c, http.StatusInternalServerError, fmt.Sprintf("Failed to add rule: %v", err))
 		return
 	}
 
@@ -162,11 +153,10 @@ func (ah *AutomationHandler) CreateAutomation(c *gin.Context) {
 		"rule_name": rule.Name,
 	}).Info("Automation rule created successfully")
 
-	c.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"data": gin.H{
-			"rule": rule,
-		},
+	c.JSON(http.StatusCreated, utils.Response{
+		Success:   true,
+		Data:      automationRuleResponse{Rule: rule},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
@@ -178,19 +168,11 @@ func (ah *AutomationHandler) GetAutomation(c *gin.Context) {
 	rule, err := ah.engine.GetRule(ruleID)
 	if err != nil {
 		ah.logger.WithError(err).WithField("rule_id", ruleID).Error("Failed to get automation rule")
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Rule not found",
-		})
+		utils.SendError(c, http.StatusNotFound, "Rule not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"rule": rule,
-		},
-	})
+	utils.SendSuccess(c, automationRuleResponse{Rule: rule})
 }
 
 // UpdateAutomation updates an existing automation rule
@@ -201,10 +183,7 @@ func (ah *AutomationHandler) UpdateAutomation(c *gin.Context) {
 	var requestBody map[string]interface{}
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
 		ah.logger.WithError(err).Error("Failed to parse request body")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-		})
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
@@ -214,41 +193,27 @@ func (ah *AutomationHandler) UpdateAutomation(c *gin.Context) {
 	// Parse updated rule
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Failed to process request data",
-		})
+		utils.SendError(c, http.StatusBadRequest, "Failed to process request data")
 		return
 	}
 
 	rule, err := ah.parser.ParseFromJSON(jsonData)
 	if err != nil {
 		ah.logger.WithError(err).Error("Failed to parse automation rule")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("Failed to parse rule: %v", err),
-		})
+		utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Failed to parse rule: %v", err))
 		return
 	}
 
 	// Update rule in engine
 	if err := ah.engine.UpdateRule(rule); err != nil {
 		ah.logger.WithError(err).Error("Failed to update automation rule")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("Failed to update rule: %v", err),
-		})
+		utils.SendError(c, http.StatusInternalServerError, fmt.Sprintf("Failed to update rule: %v", err))
 		return
 	}
 
 	ah.logger.WithField("rule_id", ruleID).Info("Automation rule updated successfully")
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"rule": rule,
-		},
-	})
+	utils.SendSuccess(c, automationRuleResponse{Rule: rule})
 }
 
 // DeleteAutomation deletes an automation rule
@@ -258,19 +223,13 @@ func (ah *AutomationHandler) DeleteAutomation(c *gin.Context) {
 
 	if err := ah.engine.RemoveRule(ruleID); err != nil {
 		ah.logger.WithError(err).WithField("rule_id", ruleID).Error("Failed to delete automation rule")
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Rule not found",
-		})
+		utils.SendError(c, http.StatusNotFound, "Rule not found")
 		return
 	}
 
 	ah.logger.WithField("rule_id", ruleID).Info("Automation rule deleted successfully")
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Rule deleted successfully",
-	})
+	utils.SendSuccess(c, messageResponse{Message: "Rule deleted successfully"})
 }
 
 // EnableAutomation enables an automation rule
@@ -280,19 +239,13 @@ func (ah *AutomationHandler) EnableAutomation(c *gin.Context) {
 
 	if err := ah.engine.EnableRule(ruleID); err != nil {
 		ah.logger.WithError(err).WithField("rule_id", ruleID).Error("Failed to enable automation rule")
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
+		utils.SendError(c, http.StatusNotFound, err.Error())
 		return
 	}
 
 	ah.logger.WithField("rule_id", ruleID).Info("Automation rule enabled successfully")
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Rule enabled successfully",
-	})
+	utils.SendSuccess(c, messageResponse{Message: "Rule enabled successfully"})
 }
 
 // DisableAutomation disables an automation rule
@@ -302,19 +255,13 @@ func (ah *AutomationHandler) DisableAutomation(c *gin.Context) {
 
 	if err := ah.engine.DisableRule(ruleID); err != nil {
 		ah.logger.WithError(err).WithField("rule_id", ruleID).Error("Failed to disable automation rule")
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
+		utils.SendError(c, http.StatusNotFound, err.Error())
 		return
 	}
 
 	ah.logger.WithField("rule_id", ruleID).Info("Automation rule disabled successfully")
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Rule disabled successfully",
-	})
+	utils.SendSuccess(c, messageResponse{Message: "Rule disabled successfully"})
 }
 
 // TestAutomation tests an automation rule
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/homeassistant"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Import sources
+const (
+	haImportSourceYAML = "yaml"
+	haImportSourceAPI  = "api"
+)
+
+// haImportRequest is the JSON body of Home Assistant imports. YAML files can also be posted
+// as the raw body with a YAML content type and the kind and dry_run query parameters.
+type haImportRequest struct {
+	Source    string                    `json:"source,omitempty"` // yaml (default) or api
+	Kind      automation.HAImportKind   `json:"kind,omitempty"`   // kind of the YAML document
+	YAML      string                    `json:"yaml,omitempty"`
+	Kinds     []automation.HAImportKind `json:"kinds,omitempty"`      // kinds fetched from the API, all by default
+	EntityMap map[string]string         `json:"entity_map,omitempty"` // Home Assistant entity ID -> PMA entity ID overrides
+	DryRun    bool                      `json:"dry_run,omitempty"`
+}
+
+// ImportHomeAssistantAutomations converts Home Assistant automations, scripts and scenes into
+// automation rules and reports the outcome of every item
+func (h *Handlers) ImportHomeAssistantAutomations(c *gin.Context) {
+	if h.automationEngine == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Automation engine not available")
+		return
+	}
+
+	var req haImportRequest
+	if contentType := c.GetHeader("Content-Type"); strings.Contains(contentType, "yaml") || strings.Contains(contentType, "yml") {
+		body, err := c.GetRawData()
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		req.Source = haImportSourceYAML
+		req.Kind = automation.HAImportKind(c.DefaultQuery("kind", string(automation.HAImportAutomation)))
+		req.YAML = string(body)
+		req.DryRun, _ = strconv.ParseBool(c.Query("dry_run"))
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	importer := automation.NewHAImporter(h.haEntityMapper(c.Request.Context(), req.EntityMap))
+	report := &automation.HAImportReport{Items: []*automation.HAImportItem{}}
+
+	switch req.Source {
+	case "", haImportSourceYAML:
+		if req.Kind == "" {
+			req.Kind = automation.HAImportAutomation
+		}
+		if !validHAImportKind(req.Kind) {
+			utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Unsupported kind: %s", req.Kind))
+			return
+		}
+		items, err := automation.ParseHAConfig([]byte(req.YAML))
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		report = importer.Import(req.Kind, items)
+
+	case haImportSourceAPI:
+		adapter, err := h.adapterRegistry.GetAdapterBySource(types.SourceHomeAssistant)
+		haAdapter, ok := adapter.(*homeassistant.HomeAssistantAdapter)
+		if err != nil || !ok {
+			utils.SendError(c, http.StatusServiceUnavailable, "Home Assistant not available")
+			return
+		}
+
+		kinds := req.Kinds
+		if len(kinds) == 0 {
+			kinds = []automation.HAImportKind{automation.HAImportAutomation, automation.HAImportScript, automation.HAImportScene}
+		}
+		for _, kind := range kinds {
+			if !validHAImportKind(kind) {
+				utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Unsupported kind: %s", kind))
+				return
+			}
+			items, err := haAdapter.GetConfigs(c.Request.Context(), string(kind))
+			if err != nil {
+				utils.SendError(c, http.StatusBadGateway, fmt.Sprintf("Failed to fetch %s configurations: %v", kind, err))
+				return
+			}
+			report.Items = append(report.Items, importer.Import(kind, items).Items...)
+		}
+
+	default:
+		utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Unsupported source: %s", req.Source))
+		return
+	}
+
+	if !req.DryRun {
+		h.addImportedRules(report)
+	}
+	report.Summarize()
+
+	h.log.WithFields(logrus.Fields{
+		"source":   req.Source,
+		"dry_run":  req.DryRun,
+		"imported": report.Imported,
+		"partial":  report.Partial,
+		"failed":   report.Failed,
+	}).Info("Home Assistant configuration imported")
+
+	utils.SendSuccess(c, report)
+}
+
+// addImportedRules adds the converted rules to the engine, replacing rules from earlier imports
+func (h *Handlers) addImportedRules(report *automation.HAImportReport) {
+	for _, item := range report.Items {
+		if item.Rule == nil {
+			continue
+		}
+
+		var err error
+		if _, lookupErr := h.automationEngine.GetRule(item.Rule.ID); lookupErr == nil {
+			err = h.automationEngine.UpdateRule(item.Rule)
+		} else {
+			err = h.automationEngine.AddRule(item.Rule)
+		}
+		if err != nil {
+			item.Fail(err)
+		}
+	}
+}
+
+// haEntityMapper maps Home Assistant entity IDs to the entities the Home Assistant adapter
+// publishes, after the explicit overrides
+func (h *Handlers) haEntityMapper(ctx context.Context, overrides map[string]string) automation.EntityMapper {
+	return func(haEntityID string) (string, bool) {
+		if entityID, ok := overrides[haEntityID]; ok {
+			return entityID, true
+		}
+
+		entityID := "ha_" + haEntityID
+		if h.unifiedService == nil {
+			return entityID, false
+		}
+		_, err := h.unifiedService.GetByID(ctx, entityID, unified.GetEntityOptions{})
+		return entityID, err == nil
+	}
+}
+
+func validHAImportKind(kind automation.HAImportKind) bool {
+	switch kind {
+	case automation.HAImportAutomation, automation.HAImportScript, automation.HAImportScene:
+		return true
+	}
+	return false
+}
@@ -711,44 +711,218 @@ func (h *Handlers) getRingCameraSnapshot(c *gin.Context, camera *models.Camera)
 	utils.SendSuccess(c, response)
 }
 
-// GetAllCameraEvents returns all camera events from various sources
+// CameraEventResponse is an archived camera event with links to its files
+type CameraEventResponse struct {
+	*models.CameraEvent
+	SnapshotURL  string `json:"snapshot_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	ClipURL      string `json:"clip_url,omitempty"`
+}
+
+// cameraEventFileURL returns the API path serving one file of an archived event
+func cameraEventFileURL(eventID int, kind string) string {
+	return fmt.Sprintf("/api/v1/cameras/events/%d/%s", eventID, kind)
+}
+
+func newCameraEventResponse(event *models.CameraEvent) CameraEventResponse {
+	response := CameraEventResponse{CameraEvent: event}
+	if event.SnapshotFileID != "" {
+		response.SnapshotURL = cameraEventFileURL(event.ID, "snapshot")
+	}
+	if event.ThumbnailFileID != "" {
+		response.ThumbnailURL = cameraEventFileURL(event.ID, "thumbnail")
+	}
+	if event.ClipFileID != "" {
+		response.ClipURL = cameraEventFileURL(event.ID, "clip")
+	}
+	return response
+}
+
+// GetAllCameraEvents returns the event timeline of all cameras
 func (h *Handlers) GetAllCameraEvents(c *gin.Context) {
-	limit := 50
+	h.sendCameraTimeline(c, c.Query("camera"))
+}
+
+// GetCameraEvents returns the event timeline of a specific camera
+func (h *Handlers) GetCameraEvents(c *gin.Context) {
+	h.sendCameraTimeline(c, c.Param("id"))
+}
+
+// sendCameraTimeline lists archived events filtered by the type, since, until, limit and offset query parameters
+func (h *Handlers) sendCameraTimeline(c *gin.Context, cameraEntityID string) {
+	if h.cameraRecorder == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Camera event archive not available")
+		return
+	}
+
+	filter := models.CameraEventFilter{
+		CameraEntityID: cameraEntityID,
+		EventType:      c.Query("type"),
+		Limit:          50,
+	}
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
-			limit = parsed
+			filter.Limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filter.Offset = parsed
+		}
+	}
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Invalid %s timestamp, expected RFC3339", param))
+				return
+			}
+			*target = parsed
 		}
 	}
 
-	// For now, return empty events since this would require integration with
-	// camera sources (Ring, etc.) to fetch actual events
-	events := []map[string]interface{}{}
+	events, total, err := h.cameraRecorder.Timeline(c.Request.Context(), filter)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to get camera events")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to get camera events")
+		return
+	}
 
-	utils.SendSuccessWithMeta(c, events, gin.H{
-		"total":   0,
-		"limit":   limit,
-		"message": "Camera events endpoint is available but no sources are configured",
+	response := make([]CameraEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, newCameraEventResponse(event))
+	}
+
+	utils.SendSuccessWithMeta(c, response, gin.H{
+		"camera_id": cameraEntityID,
+		"total":     total,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
 	})
 }
 
-// GetCameraEvents returns events for a specific camera
-func (h *Handlers) GetCameraEvents(c *gin.Context) {
-	cameraID := c.Param("id")
-	limit := 50
-	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
-			limit = parsed
-		}
+// GetCameraEvent returns a single archived camera event
+func (h *Handlers) GetCameraEvent(c *gin.Context) {
+	event, ok := h.lookupCameraEvent(c)
+	if !ok {
+		return
 	}
 
-	// For now, return empty events since this would require integration with
-	// camera sources to fetch actual events for the specific camera
-	events := []map[string]interface{}{}
+	utils.SendSuccess(c, newCameraEventResponse(event))
+}
+
+// GetCameraEventFile serves the snapshot, thumbnail or clip of an archived event
+func (h *Handlers) GetCameraEventFile(c *gin.Context) {
+	event, ok := h.lookupCameraEvent(c)
+	if !ok {
+		return
+	}
 
-	utils.SendSuccessWithMeta(c, events, gin.H{
-		"camera_id": cameraID,
-		"total":     0,
-		"limit":     limit,
-		"message":   "Camera events endpoint is available but no events found",
+	var fileID string
+	switch c.Param("kind") {
+	case "snapshot":
+		fileID = event.SnapshotFileID
+	case "thumbnail":
+		fileID = event.ThumbnailFileID
+	case "clip":
+		fileID = event.ClipFileID
+	default:
+		utils.SendError(c, http.StatusBadRequest, "File kind must be snapshot, thumbnail or clip")
+		return
+	}
+
+	reader, file, err := h.cameraRecorder.OpenFile(fileID)
+	if err != nil {
+		utils.SendError(c, http.StatusNotFound, fmt.Sprintf("Event has no %s", c.Param("kind")))
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.DataFromReader(http.StatusOK, file.Size, file.MimeType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", file.Name),
 	})
 }
+
+// DeleteCameraEvent removes an archived event and its files
+func (h *Handlers) DeleteCameraEvent(c *gin.Context) {
+	event, ok := h.lookupCameraEvent(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cameraRecorder.DeleteEvent(c.Request.Context(), event.ID); err != nil {
+		h.log.WithError(err).WithField("event_id", event.ID).Error("Failed to delete camera event")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to delete camera event")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"deleted": event.ID})
+}
+
+// CaptureCameraSnapshot records a manual snapshot of a camera into the archive
+func (h *Handlers) CaptureCameraSnapshot(c *gin.Context) {
+	if h.cameraRecorder == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Camera event archive not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	event, err := h.cameraRecorder.CaptureSnapshot(ctx, c.Param("entityId"))
+	if err != nil {
+		h.log.WithError(err).WithField("entity_id", c.Param("entityId")).Error("Failed to capture camera snapshot")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to capture camera snapshot")
+		return
+	}
+	if event.SnapshotFileID == "" {
+		utils.SendError(c, http.StatusBadGateway, "Camera did not return a snapshot")
+		return
+	}
+
+	utils.SendSuccess(c, newCameraEventResponse(event))
+}
+
+// GetLatestCameraSnapshot returns the newest archived event with a snapshot for a camera
+func (h *Handlers) GetLatestCameraSnapshot(c *gin.Context) {
+	if h.cameraRecorder == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Camera event archive not available")
+		return
+	}
+
+	event, err := h.cameraRecorder.LatestSnapshot(c.Request.Context(), c.Param("entityId"))
+	if err != nil {
+		h.log.WithError(err).WithField("entity_id", c.Param("entityId")).Error("Failed to get latest camera snapshot")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to get latest camera snapshot")
+		return
+	}
+	if event == nil {
+		utils.SendError(c, http.StatusNotFound, "No snapshot archived for this camera")
+		return
+	}
+
+	utils.SendSuccess(c, newCameraEventResponse(event))
+}
+
+// lookupCameraEvent resolves the :eventId parameter, writing the error response on failure
+func (h *Handlers) lookupCameraEvent(c *gin.Context) (*models.CameraEvent, bool) {
+	if h.cameraRecorder == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Camera event archive not available")
+		return nil, false
+	}
+
+	eventID, err := strconv.Atoi(c.Param("eventId"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid event ID")
+		return nil, false
+	}
+
+	event, err := h.cameraRecorder.GetEvent(c.Request.Context(), eventID)
+	if err != nil {
+		utils.SendError(c, http.StatusNotFound, "Camera event not found")
+		return nil, false
+	}
+
+	return event, true
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ConfigManager returns the manager that propagates configuration changes to running services
+func (h *Handlers) ConfigManager() *config.Manager {
+	return h.configManager
+}
+
+// shellyAdapterKeys are the Shelly settings the adapter is built from; firmware rollout settings
+// belong to the firmware service and need a restart
+var shellyAdapterKeys = []string{
+	"devices.shelly.enabled",
+	"devices.shelly.discovery_interval",
+	"devices.shelly.discovery_timeout",
+	"devices.shelly.network_scan_enabled",
+	"devices.shelly.network_scan_ranges",
+	"devices.shelly.auto_wifi_setup",
+	"devices.shelly.default_username",
+	"devices.shelly.default_password",
+	"devices.shelly.poll_interval",
+	"devices.shelly.max_devices",
+	"devices.shelly.health_check_interval",
+	"devices.shelly.retry_attempts",
+	"devices.shelly.retry_backoff",
+	"devices.shelly.enable_gen1_support",
+	"devices.shelly.enable_gen2_support",
+	"devices.shelly.discovery_broadcast_addr",
+	"devices.shelly.auto_detect_subnets",
+	"devices.shelly.auto_detect_interface_filter",
+	"devices.shelly.exclude_loopback",
+	"devices.shelly.exclude_docker_interfaces",
+	"devices.shelly.min_subnet_size",
+	"devices.shelly.push",
+}
+
+// subscribeConfigChanges registers the services that apply configuration changes without a
+// restart. Adapters are rebuilt when their connection settings change; the logger and AI
+// providers are updated in place.
+func (h *Handlers) subscribeConfigChanges() {
+	h.configManager.Subscribe("logger", []string{"logging.level"}, func(ctx context.Context, old, new *config.Config, changes config.Changes) error {
+		level, err := logrus.ParseLevel(new.Logging.Level)
+		if err != nil {
+			return err
+		}
+		h.log.SetLevel(level)
+		return nil
+	})
+
+	adapterKeys := []struct {
+		source types.PMASourceType
+		keys   []string
+	}{
+		{types.SourceHomeAssistant, []string{"home_assistant.url", "home_assistant.token"}},
+		{types.SourceRing, []string{"devices.ring"}},
+		{types.SourceShelly, shellyAdapterKeys},
+		{types.SourceUPS, []string{"devices.ups"}},
+		{types.SourceBLE, []string{"devices.ble"}},
+		{types.SourceNetwork, []string{"devices.network", "router.base_url", "router.auth_token"}},
+	}
+	for _, adapter := range adapterKeys {
+		source := adapter.source
+		h.configManager.Subscribe(fmt.Sprintf("%s_adapter", source), adapter.keys, func(ctx context.Context, old, new *config.Config, changes config.Changes) error {
+			return h.unifiedService.ReconfigureAdapter(ctx, source, new)
+		})
+	}
+
+	if h.llmManager != nil {
+		h.configManager.Subscribe("ai_providers", []string{"ai.providers"}, func(ctx context.Context, old, new *config.Config, changes config.Changes) error {
+			if err := h.llmManager.ReinitializeProviders(new); err != nil {
+				return err
+			}
+			return h.llmManager.Initialize(ctx)
+		})
+	}
+}
+
+// ReloadConfig reads the configuration file again and applies the changes to running services.
+// Nothing changes if the new configuration is invalid or a service rejects it.
+func (h *Handlers) ReloadConfig(c *gin.Context) {
+	result, err := h.configManager.Reload(c.Request.Context())
+	if err != nil {
+		var rejected *config.RejectedError
+		switch {
+		case errors.Is(err, config.ErrInvalidConfig):
+			utils.SendError(c, http.StatusBadRequest, err.Error())
+		case errors.As(err, &rejected):
+			utils.SendError(c, http.StatusConflict, err.Error())
+		default:
+			utils.SendError(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, result)
+}
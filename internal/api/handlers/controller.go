@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -134,6 +135,9 @@ func (h *Handlers) CreateControllerDashboard(c *gin.Context) {
 
 	createdDashboard, err := h.controllerService.CreateDashboard(ctx, &dashboard, userID)
 	if err != nil {
+		if sendControllerValidationError(c, err) {
+			return
+		}
 		h.log.WithError(err).WithField("user_id", userID).Error("Failed to create controller dashboard")
 		utils.SendError(c, http.StatusInternalServerError, "Failed to create dashboard")
 		return
@@ -175,6 +179,9 @@ func (h *Handlers) UpdateControllerDashboard(c *gin.Context) {
 			utils.SendError(c, http.StatusForbidden, "Insufficient permissions to update dashboard")
 			return
 		}
+		if sendControllerValidationError(c, err) {
+			return
+		}
 		h.log.WithError(err).WithFields(logrus.Fields{
 			"dashboard_id": dashboardID,
 			"user_id":      userID,
@@ -190,6 +197,29 @@ func (h *Handlers) UpdateControllerDashboard(c *gin.Context) {
 	})
 }
 
+// sendControllerValidationError responds with 400 and any schema violations when err is a
+// dashboard or template validation failure. It reports whether a response was sent.
+func sendControllerValidationError(c *gin.Context, err error) bool {
+	var schemaErr *controller.SchemaValidationError
+	if errors.As(err, &schemaErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":        false,
+			"error":          "Dashboard does not match the controller schema",
+			"schema_version": schemaErr.SchemaVersion,
+			"details":        schemaErr.Errors,
+			"timestamp":      time.Now().UTC(),
+		})
+		return true
+	}
+
+	if strings.Contains(err.Error(), "validation failed") {
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+		return true
+	}
+
+	return false
+}
+
 func isLocal(r *http.Request) bool {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -360,6 +390,9 @@ func (h *Handlers) CreateControllerTemplate(c *gin.Context) {
 
 	createdTemplate, err := h.controllerService.CreateTemplate(ctx, &template, userID)
 	if err != nil {
+		if sendControllerValidationError(c, err) {
+			return
+		}
 		h.log.WithError(err).WithField("user_id", userID).Error("Failed to create controller template")
 		utils.SendError(c, http.StatusInternalServerError, "Failed to create template")
 		return
@@ -397,6 +430,9 @@ func (h *Handlers) ApplyControllerTemplate(c *gin.Context) {
 
 	dashboard, err := h.controllerService.ApplyTemplate(ctx, templateID, request.Name, request.Variables, userID)
 	if err != nil {
+		if sendControllerValidationError(c, err) {
+			return
+		}
 		h.log.WithError(err).WithFields(logrus.Fields{
 			"template_id": templateID,
 			"user_id":     userID,
@@ -575,6 +611,9 @@ func (h *Handlers) ImportControllerDashboard(c *gin.Context) {
 
 	dashboard, err := h.controllerService.ImportDashboard(ctx, importData, userID)
 	if err != nil {
+		if sendControllerValidationError(c, err) {
+			return
+		}
 		h.log.WithError(err).WithField("user_id", userID).Error("Failed to import controller dashboard")
 		utils.SendError(c, http.StatusInternalServerError, "Failed to import dashboard")
 		return
@@ -689,3 +728,184 @@ func (h *Handlers) SearchControllerDashboards(c *gin.Context) {
 		"timestamp": time.Now().UTC(),
 	})
 }
+
+// Schema and Revision Operations
+
+// GetControllerDashboardSchema returns the JSON schema for controller dashboard elements
+func (h *Handlers) GetControllerDashboardSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      controller.DashboardJSONSchema(),
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// MigrateControllerDashboards upgrades all stored dashboards to the current schema version
+func (h *Handlers) MigrateControllerDashboards(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.controllerService.MigrateAllDashboards(ctx)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to migrate controller dashboards")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to migrate dashboards")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      result,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// GetControllerDashboardRevisions returns the revision history of a dashboard
+func (h *Handlers) GetControllerDashboardRevisions(c *gin.Context) {
+	dashboardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid dashboard ID")
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	userID := h.getControllerUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	revisions, err := h.controllerService.GetDashboardRevisions(ctx, dashboardID, limit, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") {
+			utils.SendError(c, http.StatusForbidden, "Access denied")
+			return
+		}
+		h.log.WithError(err).WithField("dashboard_id", dashboardID).Error("Failed to get controller dashboard revisions")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      revisions,
+		"count":     len(revisions),
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// GetControllerDashboardRevision returns a single dashboard revision
+func (h *Handlers) GetControllerDashboardRevision(c *gin.Context) {
+	dashboardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid dashboard ID")
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	userID := h.getControllerUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rev, err := h.controllerService.GetDashboardRevision(ctx, dashboardID, revision, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "not found") {
+			utils.SendError(c, http.StatusNotFound, "Revision not found or access denied")
+			return
+		}
+		h.log.WithError(err).WithField("dashboard_id", dashboardID).Error("Failed to get controller dashboard revision")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      rev,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// RollbackControllerDashboard restores a dashboard to an earlier revision
+func (h *Handlers) RollbackControllerDashboard(c *gin.Context) {
+	dashboardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid dashboard ID")
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	userID := h.getControllerUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dashboard, err := h.controllerService.RollbackDashboard(ctx, dashboardID, revision, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "insufficient permissions") {
+			utils.SendError(c, http.StatusForbidden, "Insufficient permissions to update dashboard")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.SendError(c, http.StatusNotFound, "Dashboard or revision not found")
+			return
+		}
+		if sendControllerValidationError(c, err) {
+			return
+		}
+		h.log.WithError(err).WithFields(logrus.Fields{
+			"dashboard_id": dashboardID,
+			"revision":     revision,
+			"user_id":      userID,
+		}).Error("Failed to roll back controller dashboard")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to roll back dashboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      dashboard,
+		"message":   fmt.Sprintf("Dashboard rolled back to revision %d", revision),
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// GetControllerTemplateVariables returns a template's typed variables with picker choices
+func (h *Handlers) GetControllerTemplateVariables(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	variables, err := h.controllerService.GetTemplateVariables(ctx, templateID)
+	if err != nil {
+		h.log.WithError(err).WithField("template_id", templateID).Error("Failed to get controller template variables")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve template variables")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      variables,
+		"count":     len(variables),
+		"timestamp": time.Now().UTC(),
+	})
+}
@@ -14,12 +14,96 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// entityActionResponse is the data of a successful entity action
+type entityActionResponse struct {
+	Success     bool                   `json:"success"`
+	EntityID    string                 `json:"entity_id"`
+	Action      string                 `json:"action"`
+	NewState    types.PMAEntityState   `json:"new_state"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	ProcessedAt time.Time              `json:"processed_at"`
+	Duration    string                 `json:"duration"`
+}
+
+// entityStateRequest is the body of the legacy entity state update
+type entityStateRequest struct {
+	State      string                 `json:"state" binding:"required"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// entityStateResponse is the data of a legacy entity state update
+type entityStateResponse struct {
+	Message     string               `json:"message"`
+	EntityID    string               `json:"entity_id"`
+	NewState    types.PMAEntityState `json:"new_state"`
+	ProcessedAt time.Time            `json:"processed_at"`
+}
+
+// entityRequest is the body of an entity create or update
+type entityRequest struct {
+	ID           string                 `json:"id" binding:"required"`
+	Type         types.PMAEntityType    `json:"type" binding:"required"`
+	FriendlyName string                 `json:"friendly_name" binding:"required"`
+	Icon         string                 `json:"icon,omitempty"`
+	State        types.PMAEntityState   `json:"state,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Capabilities []types.PMACapability  `json:"capabilities,omitempty"`
+	RoomID       *string                `json:"room_id,omitempty"`
+	AreaID       *string                `json:"area_id,omitempty"`
+	DeviceID     *string                `json:"device_id,omitempty"`
+	Metadata     *types.PMAMetadata     `json:"metadata,omitempty"`
+}
+
+// entityChangeResponse is the data of an entity create, update or delete; only the
+// timestamp of the operation is set
+type entityChangeResponse struct {
+	Message   string               `json:"message"`
+	EntityID  string               `json:"entity_id"`
+	Operation string               `json:"operation"`
+	Entity    *types.PMABaseEntity `json:"entity,omitempty"`
+	CreatedAt *time.Time           `json:"created_at,omitempty"`
+	UpdatedAt *time.Time           `json:"updated_at,omitempty"`
+	DeletedAt *time.Time           `json:"deleted_at,omitempty"`
+}
+
+// entityTypeResponse describes a supported entity type; only the type and name are set when
+// the type is not registered
+type entityTypeResponse struct {
+	Type         types.PMAEntityType   `json:"type"`
+	Name         string                `json:"name"`
+	Description  string                `json:"description,omitempty"`
+	Capabilities []types.PMACapability `json:"capabilities,omitempty"`
+	Actions      []string              `json:"actions,omitempty"`
+}
+
+// capabilityResponse describes a supported entity capability
+type capabilityResponse struct {
+	Capability  types.PMACapability `json:"capability"`
+	Description string              `json:"description"`
+}
+
+// adapterSyncStatus is the connection and sync state of one adapter
+type adapterSyncStatus struct {
+	ID              string              `json:"id"`
+	Name            string              `json:"name"`
+	Source          types.PMASourceType `json:"source"`
+	Connected       bool                `json:"connected"`
+	IsHealthy       bool                `json:"is_healthy"`
+	LastHealthCheck time.Time           `json:"last_health_check"`
+	ResponseTime    time.Duration       `json:"response_time"`
+	EntitiesManaged int                 `json:"entities_managed"`
+	RoomsManaged    int                 `json:"rooms_managed"`
+	LastSync        *time.Time          `json:"last_sync"`
+	SyncErrors      int                 `json:"sync_errors"`
+}
+
 // GetEntities retrieves all entities using the unified PMA service
 func (h *Handlers) GetEntities(c *gin.Context) {
 	includeRoom := c.Query("include_room") == "true"
 	includeArea := c.Query("include_area") == "true"
 	domain := c.Query("domain")
 	availableOnly := c.Query("available_only") == "true"
+	excludeHidden := c.Query("include_hidden") != "true"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -30,6 +114,7 @@ func (h *Handlers) GetEntities(c *gin.Context) {
 		IncludeRoom:   includeRoom,
 		IncludeArea:   includeArea,
 		AvailableOnly: availableOnly,
+		ExcludeHidden: excludeHidden,
 	}
 
 	// Parse capabilities filter if provided
@@ -135,6 +220,7 @@ func (h *Handlers) GetEntitiesByType(c *gin.Context) {
 	includeRoom := c.Query("include_room") == "true"
 	includeArea := c.Query("include_area") == "true"
 	availableOnly := c.Query("available_only") == "true"
+	excludeHidden := c.Query("include_hidden") != "true"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -146,6 +232,7 @@ func (h *Handlers) GetEntitiesByType(c *gin.Context) {
 		IncludeRoom:   includeRoom,
 		IncludeArea:   includeArea,
 		AvailableOnly: availableOnly,
+		ExcludeHidden: excludeHidden,
 	}
 
 	entitiesWithRooms, err := h.unifiedService.GetByType(ctx, entityType, options)
@@ -172,6 +259,7 @@ func (h *Handlers) GetEntitiesBySource(c *gin.Context) {
 	includeRoom := c.Query("include_room") == "true"
 	includeArea := c.Query("include_area") == "true"
 	availableOnly := c.Query("available_only") == "true"
+	excludeHidden := c.Query("include_hidden") != "true"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -183,6 +271,7 @@ func (h *Handlers) GetEntitiesBySource(c *gin.Context) {
 		IncludeRoom:   includeRoom,
 		IncludeArea:   includeArea,
 		AvailableOnly: availableOnly,
+		ExcludeHidden: excludeHidden,
 	}
 
 	entitiesWithRooms, err := h.unifiedService.GetBySource(ctx, source, options)
@@ -205,10 +294,11 @@ func (h *Handlers) GetEntitiesBySource(c *gin.Context) {
 
 // GetEntitiesByRoom retrieves entities in a specific room
 func (h *Handlers) GetEntitiesByRoom(c *gin.Context) {
-	roomID := c.Param("room_id")
+	roomID := c.Param("roomId")
 	includeRoom := c.Query("include_room") == "true"
 	includeArea := c.Query("include_area") == "true"
 	availableOnly := c.Query("available_only") == "true"
+	excludeHidden := c.Query("include_hidden") != "true"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -217,6 +307,7 @@ func (h *Handlers) GetEntitiesByRoom(c *gin.Context) {
 		IncludeRoom:   includeRoom,
 		IncludeArea:   includeArea,
 		AvailableOnly: availableOnly,
+		ExcludeHidden: excludeHidden,
 	}
 
 	entitiesWithRooms, err := h.unifiedService.GetByRoom(ctx, roomID, options)
@@ -248,6 +339,7 @@ func (h *Handlers) SearchEntities(c *gin.Context) {
 	includeRoom := c.Query("include_room") == "true"
 	includeArea := c.Query("include_area") == "true"
 	availableOnly := c.Query("available_only") == "true"
+	excludeHidden := c.Query("include_hidden") != "true"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -256,6 +348,7 @@ func (h *Handlers) SearchEntities(c *gin.Context) {
 		IncludeRoom:   includeRoom,
 		IncludeArea:   includeArea,
 		AvailableOnly: availableOnly,
+		ExcludeHidden: excludeHidden,
 	}
 
 	entitiesWithRooms, err := h.unifiedService.Search(ctx, query, options)
@@ -299,7 +392,8 @@ func (h *Handlers) ExecuteEntityAction(c *gin.Context) {
 		"parameters": actionRequest.Parameters,
 	}).Info("🔍 ExecuteEntityAction: Parsed action request")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// The request context carries the actor the action is audited against
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
 	h.log.WithField("entity_id", entityID).Info("⏰ ExecuteEntityAction: About to get entity from unified service")
@@ -339,6 +433,10 @@ func (h *Handlers) ExecuteEntityAction(c *gin.Context) {
 
 	// Execute the action through the adapter
 	result, err := adapter.ExecuteAction(ctx, actionRequest)
+	if h.auditService != nil {
+		// The adapter is called directly, bypassing the auditing in the unified service
+		h.auditService.RecordAction(ctx, actionRequest, result, err)
+	}
 	if err != nil {
 		h.log.WithError(err).WithFields(logrus.Fields{
 			"entity_id": entityID,
@@ -493,14 +591,14 @@ func (h *Handlers) ExecuteEntityAction(c *gin.Context) {
 
 	// Return enhanced result with immediate state information
 	if result.Success {
-		utils.SendSuccess(c, gin.H{
-			"success":      result.Success,
-			"entity_id":    result.EntityID,
-			"action":       result.Action,
-			"new_state":    result.NewState,
-			"attributes":   result.Attributes,
-			"processed_at": result.ProcessedAt,
-			"duration":     result.Duration.String(),
+		utils.SendSuccess(c, entityActionResponse{
+			Success:     result.Success,
+			EntityID:    result.EntityID,
+			Action:      result.Action,
+			NewState:    result.NewState,
+			Attributes:  result.Attributes,
+			ProcessedAt: result.ProcessedAt,
+			Duration:    result.Duration.String(),
 		})
 	} else {
 		// Action failed but adapter returned a result with error details
@@ -512,11 +610,7 @@ func (h *Handlers) ExecuteEntityAction(c *gin.Context) {
 func (h *Handlers) UpdateEntityState(c *gin.Context) {
 	entityID := c.Param("id")
 
-	var request struct {
-		State      string                 `json:"state" binding:"required"`
-		Attributes map[string]interface{} `json:"attributes"`
-	}
-
+	var request entityStateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
 		return
@@ -533,7 +627,7 @@ func (h *Handlers) UpdateEntityState(c *gin.Context) {
 		action = "set_state"
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
 	// Create PMA control action
@@ -561,11 +655,11 @@ func (h *Handlers) UpdateEntityState(c *gin.Context) {
 		return
 	}
 
-	utils.SendSuccess(c, gin.H{
-		"message":      "Entity state updated successfully",
-		"entity_id":    entityID,
-		"new_state":    result.NewState,
-		"processed_at": result.ProcessedAt,
+	utils.SendSuccess(c, entityStateResponse{
+		Message:     "Entity state updated successfully",
+		EntityID:    entityID,
+		NewState:    result.NewState,
+		ProcessedAt: result.ProcessedAt,
 	})
 }
 
@@ -573,24 +667,24 @@ func (h *Handlers) UpdateEntityState(c *gin.Context) {
 func (h *Handlers) GetEntityTypes(c *gin.Context) {
 	supportedTypes := h.typeRegistry.GetSupportedEntityTypes()
 
-	typesInfo := make([]gin.H, len(supportedTypes))
+	typesInfo := make([]entityTypeResponse, len(supportedTypes))
 	for i, entityType := range supportedTypes {
 		typeInfo, err := h.typeRegistry.GetEntityTypeInfo(entityType)
 		if err != nil {
 			h.log.WithError(err).Warnf("Failed to get type info for %s", entityType)
-			typesInfo[i] = gin.H{
-				"type": entityType,
-				"name": string(entityType),
+			typesInfo[i] = entityTypeResponse{
+				Type: entityType,
+				Name: string(entityType),
 			}
 			continue
 		}
 
-		typesInfo[i] = gin.H{
-			"type":         typeInfo.Type,
-			"name":         typeInfo.Name,
-			"description":  typeInfo.Description,
-			"capabilities": typeInfo.Capabilities,
-			"actions":      typeInfo.Actions,
+		typesInfo[i] = entityTypeResponse{
+			Type:         typeInfo.Type,
+			Name:         typeInfo.Name,
+			Description:  typeInfo.Description,
+			Capabilities: typeInfo.Capabilities,
+			Actions:      typeInfo.Actions,
 		}
 	}
 
@@ -601,20 +695,20 @@ func (h *Handlers) GetEntityTypes(c *gin.Context) {
 
 // GetEntityCapabilities returns all supported capabilities from the unified system
 func (h *Handlers) GetEntityCapabilities(c *gin.Context) {
-	capabilities := []gin.H{
-		{"capability": types.CapabilityDimmable, "description": "Entity supports dimming/brightness control"},
-		{"capability": types.CapabilityColorable, "description": "Entity supports color changes"},
-		{"capability": types.CapabilityTemperature, "description": "Entity provides temperature readings or control"},
-		{"capability": types.CapabilityHumidity, "description": "Entity provides humidity readings"},
-		{"capability": types.CapabilityPosition, "description": "Entity supports position control (covers, etc.)"},
-		{"capability": types.CapabilityVolume, "description": "Entity supports volume control"},
-		{"capability": types.CapabilityBrightness, "description": "Entity supports brightness control"},
-		{"capability": types.CapabilityMotion, "description": "Entity detects motion"},
-		{"capability": types.CapabilityRecording, "description": "Entity supports recording functionality"},
-		{"capability": types.CapabilityStreaming, "description": "Entity supports streaming functionality"},
-		{"capability": types.CapabilityNotification, "description": "Entity supports notifications"},
-		{"capability": types.CapabilityBattery, "description": "Entity reports battery status"},
-		{"capability": types.CapabilityConnectivity, "description": "Entity reports connectivity status"},
+	capabilities := []capabilityResponse{
+		{Capability: types.CapabilityDimmable, Description: "Entity supports dimming/brightness control"},
+		{Capability: types.CapabilityColorable, Description: "Entity supports color changes"},
+		{Capability: types.CapabilityTemperature, Description: "Entity provides temperature readings or control"},
+		{Capability: types.CapabilityHumidity, Description: "Entity provides humidity readings"},
+		{Capability: types.CapabilityPosition, Description: "Entity supports position control (covers, etc.)"},
+		{Capability: types.CapabilityVolume, Description: "Entity supports volume control"},
+		{Capability: types.CapabilityBrightness, Description: "Entity supports brightness control"},
+		{Capability: types.CapabilityMotion, Description: "Entity detects motion"},
+		{Capability: types.CapabilityRecording, Description: "Entity supports recording functionality"},
+		{Capability: types.CapabilityStreaming, Description: "Entity supports streaming functionality"},
+		{Capability: types.CapabilityNotification, Description: "Entity supports notifications"},
+		{Capability: types.CapabilityBattery, Description: "Entity reports battery status"},
+		{Capability: types.CapabilityConnectivity, Description: "Entity reports connectivity status"},
 	}
 
 	utils.SendSuccessWithMeta(c, capabilities, gin.H{
@@ -678,23 +772,23 @@ func (h *Handlers) SyncEntities(c *gin.Context) {
 func (h *Handlers) GetSyncStatus(c *gin.Context) {
 	adapters := h.adapterRegistry.GetAllAdapters()
 
-	adapterStatus := make([]gin.H, len(adapters))
+	adapterStatus := make([]adapterSyncStatus, len(adapters))
 	for i, adapter := range adapters {
 		health := adapter.GetHealth()
 		metrics := adapter.GetMetrics()
 
-		adapterStatus[i] = gin.H{
-			"id":                adapter.GetID(),
-			"name":              adapter.GetName(),
-			"source":            adapter.GetSourceType(),
-			"connected":         adapter.IsConnected(),
-			"is_healthy":        health.IsHealthy,
-			"last_health_check": health.LastHealthCheck,
-			"response_time":     health.ResponseTime,
-			"entities_managed":  metrics.EntitiesManaged,
-			"rooms_managed":     metrics.RoomsManaged,
-			"last_sync":         metrics.LastSync,
-			"sync_errors":       metrics.SyncErrors,
+		adapterStatus[i] = adapterSyncStatus{
+			ID:              adapter.GetID(),
+			Name:            adapter.GetName(),
+			Source:          adapter.GetSourceType(),
+			Connected:       adapter.IsConnected(),
+			IsHealthy:       health.IsHealthy,
+			LastHealthCheck: health.LastHealthCheck,
+			ResponseTime:    health.ResponseTime,
+			EntitiesManaged: metrics.EntitiesManaged,
+			RoomsManaged:    metrics.RoomsManaged,
+			LastSync:        metrics.LastSync,
+			SyncErrors:      metrics.SyncErrors,
 		}
 	}
 
@@ -710,19 +804,7 @@ func (h *Handlers) GetSyncStatus(c *gin.Context) {
 
 // CreateOrUpdateEntity creates or updates an entity
 func (h *Handlers) CreateOrUpdateEntity(c *gin.Context) {
-	var entityData struct {
-		ID           string                 `json:"id" binding:"required"`
-		Type         types.PMAEntityType    `json:"type" binding:"required"`
-		FriendlyName string                 `json:"friendly_name" binding:"required"`
-		Icon         string                 `json:"icon,omitempty"`
-		State        types.PMAEntityState   `json:"state,omitempty"`
-		Attributes   map[string]interface{} `json:"attributes,omitempty"`
-		Capabilities []types.PMACapability  `json:"capabilities,omitempty"`
-		RoomID       *string                `json:"room_id,omitempty"`
-		AreaID       *string                `json:"area_id,omitempty"`
-		DeviceID     *string                `json:"device_id,omitempty"`
-		Metadata     *types.PMAMetadata     `json:"metadata,omitempty"`
-	}
+	var entityData entityRequest
 
 	if err := c.ShouldBindJSON(&entityData); err != nil {
 		utils.SendError(c, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
@@ -831,12 +913,13 @@ func (h *Handlers) CreateOrUpdateEntity(c *gin.Context) {
 			"entity_type": entity.Type,
 		}).Info("Entity updated successfully")
 
-		utils.SendSuccess(c, gin.H{
-			"message":    "Entity updated successfully",
-			"entity_id":  entity.ID,
-			"operation":  "update",
-			"entity":     entity,
-			"updated_at": time.Now(),
+		updatedAt := time.Now()
+		utils.SendSuccess(c, entityChangeResponse{
+			Message:   "Entity updated successfully",
+			EntityID:  entity.ID,
+			Operation: "update",
+			Entity:    entity,
+			UpdatedAt: &updatedAt,
 		})
 	} else {
 		err = h.unifiedService.GetRegistryManager().GetEntityRegistry().RegisterEntity(entity)
@@ -851,12 +934,13 @@ func (h *Handlers) CreateOrUpdateEntity(c *gin.Context) {
 			"entity_type": entity.Type,
 		}).Info("Entity created successfully")
 
-		utils.SendSuccess(c, gin.H{
-			"message":    "Entity created successfully",
-			"entity_id":  entity.ID,
-			"operation":  "create",
-			"entity":     entity,
-			"created_at": time.Now(),
+		createdAt := time.Now()
+		utils.SendSuccess(c, entityChangeResponse{
+			Message:   "Entity created successfully",
+			EntityID:  entity.ID,
+			Operation: "create",
+			Entity:    entity,
+			CreatedAt: &createdAt,
 		})
 	}
 }
@@ -876,6 +960,7 @@ func (h *Handlers) getDefaultIconForEntityType(entityType types.PMAEntityType) s
 		types.EntityTypeBinarySensor: "mdi:checkbox-marked-circle",
 		types.EntityTypeDevice:       "mdi:chip",
 		types.EntityTypeGeneric:      "mdi:circle",
+		types.EntityTypePerson:       "mdi:account",
 	}
 
 	if icon, exists := iconMap[entityType]; exists {
@@ -886,7 +971,7 @@ func (h *Handlers) getDefaultIconForEntityType(entityType types.PMAEntityType) s
 
 // DeleteEntity deletes an entity
 func (h *Handlers) DeleteEntity(c *gin.Context) {
-	entityID := c.Param("entity_id")
+	entityID := c.Param("id")
 	if entityID == "" {
 		utils.SendError(c, http.StatusBadRequest, "Entity ID is required")
 		return
@@ -918,11 +1003,12 @@ func (h *Handlers) DeleteEntity(c *gin.Context) {
 		"entity_type": entity.GetType(),
 	}).Info("Entity deleted successfully")
 
-	utils.SendSuccess(c, gin.H{
-		"message":    "Entity deleted successfully",
-		"entity_id":  entityID,
-		"operation":  "delete",
-		"deleted_at": time.Now(),
+	deletedAt := time.Now()
+	utils.SendSuccess(c, entityChangeResponse{
+		Message:   "Entity deleted successfully",
+		EntityID:  entityID,
+		Operation: "delete",
+		DeletedAt: &deletedAt,
 	})
 }
 
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// entityCustomizationRequest is the body of a customization update
+type entityCustomizationRequest struct {
+	EntityID     string `json:"entity_id"`
+	FriendlyName string `json:"friendly_name"`
+	Icon         string `json:"icon"`
+	EntityType   string `json:"entity_type"`
+	Unit         string `json:"unit"`
+	DeviceClass  string `json:"device_class"`
+	Hidden       bool   `json:"hidden"`
+	Disabled     bool   `json:"disabled"`
+}
+
+// bulkEntityCustomizationRequest is the body of a bulk customization update
+type bulkEntityCustomizationRequest struct {
+	Customizations []entityCustomizationRequest `json:"customizations" binding:"required"`
+}
+
+// sendEntityCustomizationError maps customization errors to HTTP responses
+func sendEntityCustomizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, unified.ErrCustomizationNotFound):
+		utils.SendError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, unified.ErrInvalidCustomization):
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetEntityCustomizations lists all entity customizations
+func (h *Handlers) GetEntityCustomizations(c *gin.Context) {
+	utils.SendSuccess(c, h.unifiedService.ListCustomizations())
+}
+
+// GetEntityCustomization returns the customization of one entity
+func (h *Handlers) GetEntityCustomization(c *gin.Context) {
+	customization, err := h.unifiedService.GetCustomization(c.Param("id"))
+	if err != nil {
+		sendEntityCustomizationError(c, err)
+		return
+	}
+	utils.SendSuccess(c, customization)
+}
+
+// UpdateEntityCustomization replaces the customization of one entity. An empty customization
+// resets the entity to its source values.
+func (h *Handlers) UpdateEntityCustomization(c *gin.Context) {
+	var req entityCustomizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	customization := req.toModel()
+	customization.EntityID = c.Param("id")
+	if err := h.unifiedService.SaveCustomizations(c.Request.Context(), []*models.EntityCustomization{customization}); err != nil {
+		sendEntityCustomizationError(c, err)
+		return
+	}
+	utils.SendSuccess(c, customization)
+}
+
+// BulkUpdateEntityCustomizations replaces the customizations of several entities at once
+func (h *Handlers) BulkUpdateEntityCustomizations(c *gin.Context) {
+	var req bulkEntityCustomizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	customizations := make([]*models.EntityCustomization, 0, len(req.Customizations))
+	for i := range req.Customizations {
+		customizations = append(customizations, req.Customizations[i].toModel())
+	}
+	if err := h.unifiedService.SaveCustomizations(c.Request.Context(), customizations); err != nil {
+		sendEntityCustomizationError(c, err)
+		return
+	}
+	utils.SendSuccess(c, customizations)
+}
+
+// DeleteEntityCustomization removes the customization of one entity
+func (h *Handlers) DeleteEntityCustomization(c *gin.Context) {
+	if err := h.unifiedService.DeleteCustomization(c.Request.Context(), c.Param("id")); err != nil {
+		sendEntityCustomizationError(c, err)
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Customization deleted"})
+}
+
+func (r *entityCustomizationRequest) toModel() *models.EntityCustomization {
+	return &models.EntityCustomization{
+		EntityID:     r.EntityID,
+		FriendlyName: r.FriendlyName,
+		Icon:         r.Icon,
+		EntityType:   r.EntityType,
+		Unit:         r.Unit,
+		DeviceClass:  r.DeviceClass,
+		Hidden:       r.Hidden,
+		Disabled:     r.Disabled,
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// entityGroupRequest is the body of group create and update requests
+type entityGroupRequest struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	EntityType string   `json:"entity_type"`
+	Mode       string   `json:"mode"`
+	Aggregate  string   `json:"aggregate"`
+	Unit       string   `json:"unit"`
+	Icon       string   `json:"icon"`
+	RoomID     string   `json:"room_id"`
+	Members    []string `json:"members"`
+}
+
+// entityGroupResponse is a group together with its current entity
+type entityGroupResponse struct {
+	*models.EntityGroup
+	EntityID string          `json:"entity_id"`
+	Entity   types.PMAEntity `json:"entity,omitempty"`
+}
+
+// sendEntityGroupError maps group errors to HTTP responses
+func sendEntityGroupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, unified.ErrGroupNotFound):
+		utils.SendError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, unified.ErrGroupExists):
+		utils.SendError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, unified.ErrInvalidGroup):
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// entityGroupResponse pairs a group with its published entity
+func (h *Handlers) entityGroupResponse(c *gin.Context, group *models.EntityGroup) *entityGroupResponse {
+	response := &entityGroupResponse{EntityGroup: group, EntityID: unified.GroupEntityID(group)}
+	if entity, err := h.unifiedService.GetByID(c.Request.Context(), response.EntityID, unified.GetEntityOptions{}); err == nil {
+		response.Entity = entity.Entity
+	}
+	return response
+}
+
+// GetEntityGroups lists all group entities
+func (h *Handlers) GetEntityGroups(c *gin.Context) {
+	groups := h.unifiedService.ListGroups()
+
+	response := make([]*entityGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		response = append(response, h.entityGroupResponse(c, group))
+	}
+	utils.SendSuccess(c, response)
+}
+
+// GetEntityGroup returns one group entity
+func (h *Handlers) GetEntityGroup(c *gin.Context) {
+	group, err := h.unifiedService.GetGroup(c.Param("id"))
+	if err != nil {
+		sendEntityGroupError(c, err)
+		return
+	}
+	utils.SendSuccess(c, h.entityGroupResponse(c, group))
+}
+
+// CreateEntityGroup creates a group entity
+func (h *Handlers) CreateEntityGroup(c *gin.Context) {
+	var req entityGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	group := req.toModel()
+	group.ID = req.ID
+	if err := h.unifiedService.CreateGroup(c.Request.Context(), group); err != nil {
+		sendEntityGroupError(c, err)
+		return
+	}
+	utils.SendSuccess(c, h.entityGroupResponse(c, group))
+}
+
+// UpdateEntityGroup replaces the settings and members of a group entity
+func (h *Handlers) UpdateEntityGroup(c *gin.Context) {
+	var req entityGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	group := req.toModel()
+	group.ID = c.Param("id")
+	if err := h.unifiedService.UpdateGroup(c.Request.Context(), group); err != nil {
+		sendEntityGroupError(c, err)
+		return
+	}
+	utils.SendSuccess(c, h.entityGroupResponse(c, group))
+}
+
+// DeleteEntityGroup deletes a group entity
+func (h *Handlers) DeleteEntityGroup(c *gin.Context) {
+	if err := h.unifiedService.DeleteGroup(c.Request.Context(), c.Param("id")); err != nil {
+		sendEntityGroupError(c, err)
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Group deleted"})
+}
+
+func (r *entityGroupRequest) toModel() *models.EntityGroup {
+	return &models.EntityGroup{
+		Name:       r.Name,
+		EntityType: r.EntityType,
+		Mode:       r.Mode,
+		Aggregate:  r.Aggregate,
+		Unit:       r.Unit,
+		Icon:       r.Icon,
+		RoomID:     r.RoomID,
+		Members:    r.Members,
+	}
+}
@@ -3,46 +3,66 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"net/http"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/helpers"
 	"github.com/frostdev-ops/pma-backend-go/internal/adapters/homeassistant"
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/shelly"
 	"github.com/frostdev-ops/pma-backend-go/internal/adapters/ups"
 	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/ai/mcpclient"
 	"github.com/frostdev-ops/pma-backend-go/internal/ai/providers"
 	"github.com/frostdev-ops/pma-backend-go/internal/api/middleware"
 	"github.com/frostdev-ops/pma-backend-go/internal/config"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/analytics"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/audit"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/backup"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/bluetooth"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/cache"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/camera"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/controller"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/dashboard"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/display"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/energymgr"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/filemanager"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/homecontext"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/i18n"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/intents"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/interfaces"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/kiosk"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/media"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/memory"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/monitoring"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/network"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/notifications"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/preferences"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/presence"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/queue"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/rooms"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/screensaver"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/shelly_firmware"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/system"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/test"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	upsmonitor "github.com/frostdev-ops/pma-backend-go/internal/core/ups"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/voice"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/watchdog"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/webhooks"
 	"github.com/frostdev-ops/pma-backend-go/internal/database"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
 	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
 	"github.com/frostdev-ops/pma-backend-go/internal/database/sqlite"
 	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
@@ -97,6 +117,31 @@ func parseDuration(durationStr string) time.Duration {
 	return duration
 }
 
+// forwardServiceAlerts raises the alerts services report to the alert manager (kiosk offline,
+// watchdog, webhook auto-disable) on the alerting engine behind the monitoring alerts API
+func forwardServiceAlerts(alertManager *monitor.AlertManager, alertingEngine *monitoring.AlertingEngine) {
+	severities := map[monitor.AlertSeverity]monitoring.AlertSeverity{
+		monitor.AlertSeverityInfo:     monitoring.SeverityInfo,
+		monitor.AlertSeverityWarning:  monitoring.SeverityHigh,
+		monitor.AlertSeverityCritical: monitoring.SeverityCritical,
+	}
+
+	alertManager.OnAlertCreated(func(alert *monitor.Alert) {
+		severity, ok := severities[alert.Severity]
+		if !ok {
+			severity = monitoring.SeverityMedium
+		}
+		annotations := map[string]string{"summary": alert.Message}
+		for key, value := range alert.Details {
+			annotations[key] = fmt.Sprint(value)
+		}
+		alertingEngine.RaiseAlert(alert.ID, alert.Source, severity, map[string]string{"source": alert.Source}, annotations)
+	})
+	alertManager.OnAlertResolved(func(alert *monitor.Alert) {
+		alertingEngine.ClearAlert(alert.ID, alert.ResolvedBy)
+	})
+}
+
 // Repository adapters to bridge interface mismatches
 
 // MCP Service Adapters to bridge real services to MCP interfaces
@@ -159,10 +204,11 @@ func (a *MCPEntityServiceAdapter) ExecuteAction(ctx context.Context, action type
 	return a.unifiedService.ExecuteAction(ctx, action)
 }
 
-// MCPRoomServiceAdapter adapts rooms.RoomService to interfaces.RoomServiceInterface
+// MCPRoomServiceAdapter adapts rooms.RoomService to interfaces.RoomServiceInterface and ai.RoomManager
 type MCPRoomServiceAdapter struct {
-	roomService *rooms.RoomService
-	logger      *logrus.Logger
+	roomService  *rooms.RoomService
+	typeRegistry *types.PMATypeRegistry
+	logger       *logrus.Logger
 }
 
 func (a *MCPRoomServiceAdapter) GetRoomByID(ctx context.Context, roomID string) (*types.PMARoom, error) {
@@ -173,6 +219,42 @@ func (a *MCPRoomServiceAdapter) GetAllRooms(ctx context.Context) ([]*types.PMARo
 	return a.roomService.GetAllRooms(ctx)
 }
 
+func (a *MCPRoomServiceAdapter) CreateRoom(ctx context.Context, name, description string, attributes map[string]interface{}) (interface{}, error) {
+	room := a.typeRegistry.CreateRoom(generateRoomID(), name)
+	room.Description = description
+	for key, value := range attributes {
+		room.Attributes[key] = value
+	}
+
+	if err := a.roomService.CreateRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (a *MCPRoomServiceAdapter) AssignEntityToRoom(ctx context.Context, entityID, roomID, roomName string) (interface{}, error) {
+	if roomID == "" {
+		allRooms, err := a.roomService.GetAllRooms(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, room := range allRooms {
+			if strings.EqualFold(room.Name, roomName) {
+				roomID = room.ID
+				break
+			}
+		}
+		if roomID == "" {
+			return nil, fmt.Errorf("room not found: %s", roomName)
+		}
+	}
+
+	if err := a.roomService.AssignEntityToRoom(ctx, entityID, roomID); err != nil {
+		return nil, err
+	}
+	return a.roomService.GetRoomByID(ctx, roomID)
+}
+
 // MCPSystemServiceAdapter adapts system.Service to interfaces.SystemServiceInterface
 type MCPSystemServiceAdapter struct {
 	systemService *system.Service
@@ -483,6 +565,32 @@ func (a *MCPRepositoryAdapter) CleanupOldExecutions(ctx context.Context, days in
 	return a.repo.CleanupOldExecutions(ctx, days)
 }
 
+// CameraSnapshotProviderAdapter exposes archived camera snapshots to automation notifications
+type CameraSnapshotProviderAdapter struct {
+	recorder *camera.Recorder
+}
+
+func (a *CameraSnapshotProviderAdapter) LatestSnapshot(ctx context.Context, cameraEntityID string) (*automation.SnapshotAttachment, error) {
+	event, err := a.recorder.LatestSnapshot(ctx, cameraEntityID)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	attachment := &automation.SnapshotAttachment{
+		CameraEntityID: event.CameraEntityID,
+		EventID:        event.ID,
+		EventType:      event.EventType,
+		FileID:         event.SnapshotFileID,
+		URL:            cameraEventFileURL(event.ID, "snapshot"),
+		CapturedAt:     event.OccurredAt,
+	}
+	if event.ThumbnailFileID != "" {
+		attachment.ThumbnailURL = cameraEventFileURL(event.ID, "thumbnail")
+	}
+
+	return attachment, nil
+}
+
 // Handlers holds all HTTP handlers and their dependencies
 type Handlers struct {
 	cfg                 *config.Config
@@ -495,6 +603,9 @@ type Handlers struct {
 	llmManager          *ai.LLMManager
 	chatService         *ai.ChatService
 	conversationService *ai.ConversationService
+	intentEngine        *intents.Engine
+	memoryService       *memory.Service
+	homeContext         *homecontext.Builder
 	mcpToolExecutor     *ai.MCPToolExecutor
 	networkService      *network.Service
 	upsService          *ups.UPSAdapter
@@ -503,9 +614,11 @@ type Handlers struct {
 	bluetoothService    *bluetooth.Service
 	energyService       *energymgr.Service
 	roomService         *rooms.RoomService
+	roomReconciler      *rooms.Reconciler
 	queueService        *queue.QueueService
 	kioskService        kiosk.Service
 	KioskHandler        *KioskHandler
+	alertManager        *monitor.AlertManager
 	eventsHandler       *EventsHandler
 	mcpHandler          *MCPHandler
 	fileHandler         *FileHandler
@@ -555,6 +668,39 @@ type Handlers struct {
 	thumbnailGenerator *media.ThumbnailGenerator
 	MediaHandler       *MediaHandler
 
+	// Camera Event Archive
+	cameraRecorder *camera.Recorder
+
+	// Notification Delivery
+	notificationService *notifications.Service
+
+	// Presence Detection
+	presenceService *presence.Service
+
+	// Shelly Firmware Management
+	shellyFirmwareService *shelly_firmware.Service
+
+	// UPS Power Failure Handling
+	upsPowerManager *upsmonitor.PowerManager
+
+	// Device Health Watchdog
+	watchdogService *watchdog.Service
+
+	// Audit Log
+	auditService *audit.Service
+
+	// Outbound Webhooks
+	webhookService *webhooks.Service
+
+	// Voice Pipeline
+	voiceService *voice.Service
+
+	// Configuration Hot Reload
+	configManager *config.Manager
+
+	// Helper Entities
+	helpersAdapter *helpers.HelpersAdapter
+
 	// Controller Dashboard System
 	controllerService *controller.Service
 
@@ -661,8 +807,19 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	// Initialize Energy service
 	energyService := energymgr.NewService(repos.Energy, repos.Entity, repos.UPS, logger)
 
-	// Initialize Room service
-	roomService := rooms.NewRoomService(logger)
+	// Initialize Room service backed by the room repository
+	roomService := rooms.NewRoomService(repos.Room, logger)
+	if err := roomService.Load(context.Background()); err != nil {
+		logger.WithError(err).Error("Failed to load rooms")
+	}
+
+	// Reconcile rooms with the area hierarchy; scheduled runs only report
+	var haAreaSource rooms.ExternalAreaSource
+	if cfg.HomeAssistant.URL != "" && cfg.HomeAssistant.Token != "" {
+		haAreaSource = &homeAssistantAreaSource{client: homeassistant.NewHAClientWrapper(cfg, logger)}
+	}
+	roomReconciler := rooms.NewReconciler(roomService, repos.Area, haAreaSource, logger)
+	roomReconciler.Start(context.Background(), time.Hour)
 
 	// Initialize Queue service (create queue repository separately)
 	sqlxDB := sqlx.NewDb(db, "sqlite3")
@@ -670,9 +827,18 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	queueService := queue.NewQueueService(queueRepo, wsHub, logger)
 
 	// Initialize Kiosk service
-	kioskService := kiosk.NewService(repos.Kiosk, repos.Entity, repos.Room, logger)
+	kioskService := kiosk.NewService(repos.Kiosk, repos.Entity, repos.Room, wsHub, logger)
 	kioskHandler := NewKioskHandler(kioskService)
 
+	// Shared alert manager for service-level alerts (offline kiosks, etc.)
+	alertManager := monitor.NewAlertManager(nil, logger)
+	if kioskImpl, ok := kioskService.(*kiosk.ServiceImpl); ok {
+		kioskImpl.SetAlertManager(alertManager)
+	}
+	if err := kioskService.Start(context.Background()); err != nil {
+		logger.WithError(err).Warn("Failed to start kiosk monitor")
+	}
+
 	// Legacy entity and room services are replaced by unified service
 
 	// Legacy PMA service removed - now using unified service
@@ -690,6 +856,19 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	logger.Info("Connecting WebSocket hub to unified entity service for real-time updates")
 	wsEventEmitter := websocket.NewWebSocketEventEmitter(wsHub)
 	unifiedService.SetEventEmitter(wsEventEmitter)
+	unifiedService.SetRoomService(roomService)
+
+	// Record every control action in the tamper-evident audit log
+	var auditService *audit.Service
+	if repos.Audit != nil {
+		auditService = audit.NewService(repos.Audit, logger)
+		if err := auditService.Load(context.Background()); err != nil {
+			logger.WithError(err).Error("Failed to load audit log, actions are not audited")
+			auditService = nil
+		} else {
+			unifiedService.SetActionAuditor(auditService)
+		}
+	}
 
 	// CRITICAL FIX: Initialize adapters during startup to ensure entity synchronization
 	logger.Info("Initializing adapters during startup")
@@ -704,6 +883,12 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
+	// Load customizations first so synced entities are customized as they are registered
+	unifiedService.SetCustomizationRepository(repos.Customization)
+	if err := unifiedService.LoadCustomizations(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to load entity customizations")
+	}
+
 	if results, err := unifiedService.SyncFromAllSources(ctx); err != nil {
 		logger.WithError(err).Error("Failed to perform initial entity synchronization")
 	} else {
@@ -721,6 +906,12 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 		logger.WithField("total_entities", totalEntities).Info("Initial entity synchronization completed successfully")
 	}
 
+	// Publish group entities once their members are registered
+	unifiedService.SetGroupRepository(repos.EntityGroup)
+	if err := unifiedService.LoadGroups(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to load group entities")
+	}
+
 	// Get registry manager and components
 	registryManager := unifiedService.GetRegistryManager()
 	adapterRegistry := registryManager.GetAdapterRegistry()
@@ -773,6 +964,7 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 		repos.Controller,
 		repos.User,
 		repos.Entity,
+		repos.Room,
 		unifiedService,
 		wsHub,
 		logger,
@@ -804,7 +996,11 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	// Initialize new handlers with standard logger
 	stdLogger := log.New(os.Stdout, "[PMA] ", log.LstdFlags)
 	eventsHandler := NewEventsHandler(stdLogger)
-	mcpHandler := NewMCPHandler(stdLogger)
+	mcpManager := mcpclient.NewManager(repos.MCP, logger)
+	if err := mcpManager.Load(context.Background()); err != nil {
+		logger.WithError(err).Warn("Failed to load MCP servers")
+	}
+	mcpHandler := NewMCPHandler(mcpManager, stdLogger)
 
 	// Initialize file security components
 	basicScanner := filemanager.NewBasicVirusScanner(logger)
@@ -853,6 +1049,7 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	dashboardEngine := monitoring.NewDashboardEngine(nil, logger)                                    // Use default config
 	predictiveEngine := monitoring.NewPredictiveEngine(nil, alertingEngine, metricCollector, logger) // Use default config
 	monitoringHandler := NewMonitoringHandler(alertingEngine, dashboardEngine, predictiveEngine, logger)
+	forwardServiceAlerts(alertManager, alertingEngine)
 
 	// Initialize security system
 	advancedSecurity := middleware.NewAdvancedSecurityMiddleware(middleware.DefaultSecurityConfig(), logger)
@@ -899,12 +1096,15 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 		bluetoothService:  bluetoothService,
 		energyService:     energyService,
 		roomService:       roomService,
+		roomReconciler:    roomReconciler,
 		queueService:      queueService,
 		kioskService:      kioskService,
 		KioskHandler:      kioskHandler,
+		alertManager:      alertManager,
 		eventsHandler:     eventsHandler,
 		mcpHandler:        mcpHandler,
 		fileHandler:       fileHandler,
+		auditService:      auditService,
 
 		testService:        test.NewService(cfg, repos, logger, db),
 		cacheManager:       cacheManager,
@@ -945,7 +1145,7 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	if unifiedService != nil && roomService != nil && systemService != nil && energyService != nil && automationEngine != nil {
 		// Create simple service adapters directly in handlers to avoid import cycles
 		entityServiceAdapter := &MCPEntityServiceAdapter{unifiedService: unifiedService, logger: logger}
-		roomServiceAdapter := &MCPRoomServiceAdapter{roomService: roomService, logger: logger}
+		roomServiceAdapter := &MCPRoomServiceAdapter{roomService: roomService, typeRegistry: typeRegistry, logger: logger}
 		systemServiceAdapter := &MCPSystemServiceAdapter{systemService: systemService, logger: logger}
 		energyServiceAdapter := &MCPEnergyServiceAdapter{energyService: energyService, logger: logger}
 		automationServiceAdapter := &MCPAutomationServiceAdapter{automationEngine: automationEngine, logger: logger}
@@ -974,12 +1174,69 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 			mcpToolExecutor,
 			logger,
 		)
+		conversationService.SetExternalToolSource(mcpManager)
 		handlers.conversationService = conversationService
 		logger.Info("Conversation service initialized with MCP integration")
 	} else {
 		logger.Info("Conversation service initialization skipped - using MCP tool executor directly")
 	}
 
+	// Initialize long-term memory, recalled into and learned from conversations
+	if cfg.AI.Memory.Enabled && llmManager != nil && repos.AIMemory != nil {
+		memoryService := memory.NewService(repos.AIMemory, llmManager, cfg.AI.Memory, logger)
+		if handlers.conversationService != nil {
+			handlers.conversationService.SetMemoryProvider(memoryService)
+		}
+		handlers.memoryService = memoryService
+		logger.Info("AI memory initialized successfully")
+	}
+
+	// Initialize the home context builder, which adds the home state to chat prompts
+	if cfg.AI.HomeContext.Enabled && unifiedService != nil {
+		homeContext := homecontext.NewBuilder(cfg.AI.HomeContext, unifiedService, logger)
+		homeContext.ObserveEntities(unifiedService)
+		if chatService != nil {
+			chatService.SetHomeContextProvider(homeContext, cfg.AI.HomeContext.WindowShare, cfg.AI.HomeContext.MaxTokens)
+		}
+		if handlers.conversationService != nil {
+			handlers.conversationService.SetHomeContextProvider(homeContext, cfg.AI.HomeContext.WindowShare, cfg.AI.HomeContext.MaxTokens)
+		}
+		handlers.homeContext = homeContext
+		logger.Info("Home context builder initialized successfully")
+	}
+
+	// Initialize local intent recognition, which handles common commands without the LLM
+	if cfg.AI.Intents.Enabled {
+		intentEngine, err := intents.NewEngine(cfg.AI.Intents, unifiedService, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to initialize intent engine, commands will go to the LLM")
+		} else {
+			if chatService != nil {
+				chatService.SetIntentHandler(intentEngine)
+			}
+			if handlers.conversationService != nil {
+				handlers.conversationService.SetIntentHandler(intentEngine)
+			}
+			handlers.intentEngine = intentEngine
+			logger.WithField("locales", intentEngine.Locales()).Info("Intent engine initialized successfully")
+		}
+	}
+
+	// Initialize Voice Pipeline, answering transcripts through the conversation service or,
+	// without it, the intent engine
+	if cfg.Voice.Enabled {
+		voiceService := voice.NewService(cfg.Voice, logger)
+		if handlers.intentEngine != nil {
+			voiceService.SetIntentHandler(handlers.intentEngine)
+		}
+		if handlers.conversationService != nil {
+			voiceService.SetConversationService(handlers.conversationService)
+		}
+		voiceService.RegisterHandlers(wsHub)
+		handlers.voiceService = voiceService
+		logger.Info("Voice pipeline initialized successfully")
+	}
+
 	// Initialize WebSocket optimization
 	optimizationConfig := websocket.DefaultOptimizationConfig()
 	optimizedHub := websocket.NewOptimizedHub(wsHub, optimizationConfig, logger)
@@ -1005,6 +1262,241 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 
 	logger.Info("Preferences system initialized successfully")
 
+	// Initialize Notification Service
+	if cfg.Notifications.Enabled {
+		var notificationHub notifications.Broadcaster
+		if wsHub != nil {
+			notificationHub = wsHub
+		}
+		notificationService := notifications.NewService(cfg.Notifications, repos.Notification, repos.User, preferencesManager, notificationHub, logger)
+		notificationService.Start(context.Background())
+		if automationEngine != nil {
+			automationEngine.SetNotifier(notificationService)
+			notificationService.OnResponse(func(ctx context.Context, notification *models.Notification, response *types.NotificationResponse) {
+				automationEngine.FireEvent(automation.Event{
+					Type:   automation.EventTypeNotificationAction,
+					Source: "notifications",
+					Data: map[string]interface{}{
+						"notification_id":   notification.ID,
+						"notification_type": notification.Type,
+						"action_id":         response.ActionID,
+						"user_id":           response.UserID,
+						"response":          response.Response,
+						"data":              json.RawMessage(notification.Data),
+					},
+				})
+			})
+		}
+		handlers.notificationService = notificationService
+		logger.WithField("channels", notificationService.Channels()).Info("Notification service initialized successfully")
+	}
+
+	// Forward Shelly push updates and button events to automations
+	if automationEngine != nil && unifiedService != nil {
+		forwardDeviceEvents := func(adapter types.PMAAdapter) {
+			shellyAdapter, ok := adapter.(*shelly.ShellyAdapter)
+			if !ok {
+				return
+			}
+			shellyAdapter.SetDeviceEventHandler(func(event shelly.DeviceEvent) {
+				data := map[string]interface{}{
+					"device_id": event.DeviceID,
+					"component": event.Component,
+					"event":     event.Event,
+				}
+				for key, value := range event.Data {
+					if _, exists := data[key]; !exists {
+						data[key] = value
+					}
+				}
+				automationEngine.FireEvent(automation.Event{
+					Type:      automation.EventTypeDeviceEvent,
+					Source:    "shelly",
+					EntityID:  event.EntityID,
+					Data:      data,
+					Timestamp: event.Timestamp,
+				})
+			})
+		}
+		if adapter, err := adapterRegistry.GetAdapterBySource(types.SourceShelly); err == nil {
+			forwardDeviceEvents(adapter)
+		}
+		unifiedService.AddAdapterReplacedListener(func(source types.PMASourceType, adapter types.PMAAdapter) {
+			if source == types.SourceShelly {
+				forwardDeviceEvents(adapter)
+			}
+		})
+
+		unifiedService.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+			if source != types.SourceShelly {
+				return
+			}
+			automationEngine.FireEvent(automation.Event{
+				Type:     "state_changed",
+				Source:   "shelly",
+				EntityID: entityID,
+				Data: map[string]interface{}{
+					"old_state": string(oldState),
+					"new_state": string(newState),
+				},
+			})
+		})
+	}
+
+	// Initialize Shelly Firmware Management
+	if adapter, err := adapterRegistry.GetAdapterBySource(types.SourceShelly); err == nil {
+		if shellyAdapter, ok := adapter.(*shelly.ShellyAdapter); ok {
+			firmwareService := shelly_firmware.NewService(cfg.Devices.Shelly.Firmware, shellyAdapter.GetClient(), wsHub, logger)
+			if unifiedService != nil {
+				unifiedService.AddAdapterReplacedListener(func(source types.PMASourceType, adapter types.PMAAdapter) {
+					if shellyAdapter, ok := adapter.(*shelly.ShellyAdapter); ok {
+						firmwareService.SetClient(shellyAdapter.GetClient())
+					}
+				})
+			}
+			handlers.shellyFirmwareService = firmwareService
+			logger.Info("Shelly firmware management initialized successfully")
+		}
+	}
+
+	// Initialize Presence Detection
+	if cfg.Presence.Enabled {
+		presenceService := presence.NewService(cfg.Presence, repos.Presence, unifiedService, logger)
+		if automationEngine != nil {
+			presenceService.OnChange(func(ctx context.Context, change *presence.Change) {
+				automationEngine.FireEvent(automation.Event{
+					Type:     "state_changed",
+					Source:   "presence",
+					EntityID: change.EntityID,
+					Data: map[string]interface{}{
+						"old_state":  change.OldState,
+						"new_state":  change.NewState,
+						"zone":       change.Zone,
+						"confidence": change.Confidence,
+					},
+				})
+			})
+		}
+		if err := presenceService.Start(context.Background()); err != nil {
+			logger.WithError(err).Warn("Failed to start presence detection")
+		} else {
+			handlers.presenceService = presenceService
+			logger.Info("Presence detection initialized successfully")
+		}
+	}
+
+	// Initialize Helper Entities
+	helpersAdapter := helpers.NewHelpersAdapter(repos.Helper, logger)
+	helpersAdapter.SetEventHandler(func(entity types.PMAEntity) {
+		if err := unifiedService.PublishEntity(context.Background(), entity); err != nil {
+			logger.WithError(err).WithField("entity_id", entity.GetID()).Warn("Failed to publish helper entity")
+		}
+	})
+	helpersAdapter.SetRemoveHandler(unifiedService.UnpublishEntity)
+	if automationEngine != nil {
+		helpersAdapter.SetTimerEventHandler(func(entityID, event string, data map[string]interface{}) {
+			automationEngine.FireEvent(automation.Event{
+				Type:     event,
+				Source:   "helper",
+				EntityID: entityID,
+				Data:     data,
+			})
+		})
+	}
+	if err := unifiedService.RegisterAdapter(helpersAdapter); err != nil {
+		logger.WithError(err).Warn("Failed to register helpers adapter")
+	} else if err := helpersAdapter.Connect(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to load helper entities")
+	} else {
+		if _, err := unifiedService.SyncFromSource(ctx, types.SourceHelper); err != nil {
+			logger.WithError(err).Warn("Failed to sync helper entities")
+		}
+		handlers.helpersAdapter = helpersAdapter
+		logger.Info("Helper entities initialized successfully")
+	}
+
+	// Initialize Device Health Watchdog
+	if cfg.Watchdog.Enabled {
+		watchdogService := watchdog.NewService(cfg.Watchdog, unifiedService, logger)
+		watchdogService.SetAlertManager(alertManager)
+		watchdogService.Start(context.Background())
+		handlers.watchdogService = watchdogService
+		logger.Info("Device health watchdog initialized successfully")
+	}
+
+	// Initialize Outbound Webhooks, delivered through the action queue. The queue runs only for
+	// webhook deliveries; other queued action types are not processed.
+	if cfg.Webhooks.Enabled && repos.Webhook != nil && db != nil {
+		webhookService := webhooks.NewService(cfg.Webhooks, repos.Webhook, queueService, logger)
+		if err := webhookService.Load(context.Background()); err != nil {
+			logger.WithError(err).Error("Failed to load webhooks, outbound webhooks disabled")
+		} else {
+			webhookService.SetAlertManager(alertManager)
+			webhookService.ObserveEntities(unifiedService)
+			webhookService.ObserveAlerts(alertManager)
+			webhookService.ObserveAdapters(adapterRegistry)
+			if automationEngine != nil {
+				webhookService.ObserveAutomations(automationEngine)
+			}
+			webhookService.Start(context.Background())
+			handlers.webhookService = webhookService
+			if err := queueService.Start(context.Background()); err != nil {
+				logger.WithError(err).Error("Failed to start action queue, webhooks will not be delivered")
+			}
+			logger.Info("Outbound webhooks initialized successfully")
+		}
+	}
+
+	// Initialize UPS power failure handling
+	if cfg.Devices.UPS.Enabled && cfg.Devices.UPS.Power.Enabled {
+		powerManager, err := upsmonitor.NewPowerManager(cfg.Devices.UPS.Power, cfg.Devices.UPS.NUTHost, cfg.Devices.UPS.NUTPort, cfg.Devices.UPS.UPSName, wsHub, logger)
+		if err != nil {
+			logger.WithError(err).Error("Invalid UPS power configuration, power failure handling disabled")
+		} else {
+			powerManager.SetEntityController(unifiedService)
+			powerManager.SetSystemShutdowner(systemService)
+			if automationEngine != nil {
+				powerManager.OnEvent(func(event upsmonitor.PowerEvent, status *models.UPSStatus) {
+					automationEngine.FireEvent(automation.Event{
+						Type:   automation.EventTypePowerEvent,
+						Source: "ups",
+						Data: map[string]interface{}{
+							"event":           string(event),
+							"ups":             cfg.Devices.UPS.UPSName,
+							"ups_status":      status.Status,
+							"battery_charge":  status.BatteryCharge,
+							"battery_runtime": status.BatteryRuntime,
+						},
+					})
+				})
+			}
+
+			monitorInterval, err := time.ParseDuration(cfg.Devices.UPS.MonitoringInterval)
+			if err != nil {
+				monitorInterval = 30 * time.Second
+			}
+			upsMonitor := upsmonitor.NewService(upsmonitor.Config{
+				NUTHost:            cfg.Devices.UPS.NUTHost,
+				NUTPort:            cfg.Devices.UPS.NUTPort,
+				UPSName:            cfg.Devices.UPS.UPSName,
+				MonitoringInterval: monitorInterval,
+				HistoryRetention:   cfg.Devices.UPS.HistoryRetentionDays,
+				AlertThresholds: upsmonitor.AlertThresholds{
+					LowBattery:      cfg.Devices.UPS.Power.LowBattery,
+					CriticalBattery: cfg.Devices.UPS.Power.CriticalBattery,
+				},
+			}, repos.UPS, wsHub, logger)
+			upsMonitor.SetPowerManager(powerManager)
+
+			if err := upsMonitor.StartMonitoring(context.Background()); err != nil {
+				logger.WithError(err).Warn("Failed to start UPS power monitoring")
+			} else {
+				handlers.upsPowerManager = powerManager
+				logger.Info("UPS power failure handling initialized successfully")
+			}
+		}
+	}
+
 	// Initialize Backup System
 	// Create file manager config for backup system
 	fileManagerConfig := &config.FileManagerConfig{
@@ -1037,6 +1529,7 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 		},
 		Media: config.FileMediaConfig{
 			CachePath: "./data/media/cache",
+			HLS:       cfg.FileManager.Media.HLS,
 		},
 	}
 
@@ -1048,7 +1541,10 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 
 	var mediaStreamer media.MediaStreamer
 	if mediaFileManager != nil {
-		mediaStreamer = media.NewLocalMediaStreamer(basicFileManagerConfig, mediaFileManager, logger)
+		localStreamer := media.NewLocalMediaStreamer(basicFileManagerConfig, mediaFileManager, logger)
+		// Sign stream URLs with a key derived from the server secret so they survive restarts
+		localStreamer.SetStreamSigningKey(media.DeriveStreamSigningKey(cfg.Auth.JWTSecret))
+		mediaStreamer = localStreamer
 	}
 
 	mediaHandler := NewMediaHandler(mediaProcessor, mediaStreamer, thumbnailGenerator, logger)
@@ -1060,6 +1556,33 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 
 	logger.Info("Media processing system initialized successfully")
 
+	// Initialize Camera Event Recorder
+	recordingConfig := cfg.Devices.Cameras.Recording
+	if recordingConfig.StoragePath == "" {
+		recordingConfig.StoragePath = "./data/cameras"
+	}
+	cameraFileManager, err := filemanager.NewLocalStorage(&config.FileManagerConfig{
+		Storage: config.FileStorageConfig{
+			BasePath:    recordingConfig.StoragePath,
+			TempPath:    filepath.Join(recordingConfig.StoragePath, "tmp"),
+			MaxFileSize: 200 * 1024 * 1024, // 200MB, event clips
+		},
+	}, db, logger, securityManager)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to initialize camera storage, camera event archive disabled")
+	} else {
+		cameraRecorder := camera.NewRecorder(recordingConfig, repos.Camera, cameraFileManager, thumbnailGenerator, unifiedService, logger)
+		if err := cameraRecorder.Start(); err != nil {
+			logger.WithError(err).Warn("Failed to start camera event recorder")
+		} else {
+			handlers.cameraRecorder = cameraRecorder
+			if automationEngine != nil {
+				automationEngine.SetSnapshotProvider(&CameraSnapshotProviderAdapter{recorder: cameraRecorder})
+			}
+			logger.Info("Camera event recorder initialized successfully")
+		}
+	}
+
 	logger.Info("Unified PMA type system initialized successfully")
 
 	// Initialize adapters based on config (basic implementation)
@@ -1089,6 +1612,13 @@ func NewHandlers(cfg *config.Config, repos *database.Repositories, logger *logru
 	*/
 	logger.Info("Periodic sync scheduler DISABLED to fix GetAll deadlock")
 
+	// Apply configuration file changes to running services without a restart
+	handlers.configManager = config.NewManager(cfg, logger)
+	handlers.subscribeConfigChanges()
+	if err := handlers.configManager.Watch(context.Background()); err != nil {
+		logger.WithError(err).Info("Configuration file is not watched, use the reload endpoint to apply changes")
+	}
+
 	return handlers
 }
 
@@ -1348,6 +1878,10 @@ func (h *Handlers) GetMCPServers(c *gin.Context) {
 	h.mcpHandler.GetMCPServers(c)
 }
 
+func (h *Handlers) GetMCPServer(c *gin.Context) {
+	h.mcpHandler.GetMCPServer(c)
+}
+
 func (h *Handlers) AddMCPServer(c *gin.Context) {
 	h.mcpHandler.AddMCPServer(c)
 }
@@ -1372,6 +1906,14 @@ func (h *Handlers) ExecuteMCPTools(c *gin.Context) {
 	h.mcpHandler.ExecuteMCPTools(c)
 }
 
+func (h *Handlers) GetMCPResources(c *gin.Context) {
+	h.mcpHandler.GetMCPResources(c)
+}
+
+func (h *Handlers) ReadMCPResource(c *gin.Context) {
+	h.mcpHandler.ReadMCPResource(c)
+}
+
 // Screensaver Handler Methods
 func (h *Handlers) GetScreensaverImages(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/helpers"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// helperRequest is the body of helper create and update requests
+type helperRequest struct {
+	ID     string              `json:"id"`
+	Type   string              `json:"type"`
+	Name   string              `json:"name"`
+	Icon   string              `json:"icon"`
+	Config models.HelperConfig `json:"config"`
+}
+
+// helperResponse is a helper together with its entity ID
+type helperResponse struct {
+	*models.Helper
+	EntityID string `json:"entity_id"`
+}
+
+// requireHelpersAdapter reports whether helper entities are available
+func (h *Handlers) requireHelpersAdapter(c *gin.Context) bool {
+	if h.helpersAdapter == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Helper entities not available")
+		return false
+	}
+	return true
+}
+
+// sendHelperError maps helper errors to HTTP responses
+func sendHelperError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, helpers.ErrHelperNotFound):
+		utils.SendError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, helpers.ErrHelperExists):
+		utils.SendError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, helpers.ErrInvalidHelper):
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetHelpers lists all helper entities
+func (h *Handlers) GetHelpers(c *gin.Context) {
+	if !h.requireHelpersAdapter(c) {
+		return
+	}
+
+	list := h.helpersAdapter.ListHelpers()
+	response := make([]*helperResponse, 0, len(list))
+	for _, helper := range list {
+		response = append(response, &helperResponse{Helper: helper, EntityID: helpers.EntityID(helper)})
+	}
+	utils.SendSuccess(c, response)
+}
+
+// GetHelper returns one helper by its entity ID
+func (h *Handlers) GetHelper(c *gin.Context) {
+	if !h.requireHelpersAdapter(c) {
+		return
+	}
+
+	helper, err := h.helpersAdapter.GetHelper(c.Param("id"))
+	if err != nil {
+		sendHelperError(c, err)
+		return
+	}
+	utils.SendSuccess(c, &helperResponse{Helper: helper, EntityID: helpers.EntityID(helper)})
+}
+
+// CreateHelper creates a helper entity
+func (h *Handlers) CreateHelper(c *gin.Context) {
+	if !h.requireHelpersAdapter(c) {
+		return
+	}
+
+	var req helperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	helper := &models.Helper{ID: req.ID, Type: req.Type, Name: req.Name, Icon: req.Icon, Config: req.Config}
+	if err := h.helpersAdapter.CreateHelper(c.Request.Context(), helper); err != nil {
+		sendHelperError(c, err)
+		return
+	}
+	utils.SendSuccess(c, &helperResponse{Helper: helper, EntityID: helpers.EntityID(helper)})
+}
+
+// UpdateHelper changes the name, icon and settings of a helper entity
+func (h *Handlers) UpdateHelper(c *gin.Context) {
+	if !h.requireHelpersAdapter(c) {
+		return
+	}
+
+	var req helperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	helper, err := h.helpersAdapter.UpdateHelper(c.Request.Context(), c.Param("id"), &models.Helper{
+		Name:   req.Name,
+		Icon:   req.Icon,
+		Config: req.Config,
+	})
+	if err != nil {
+		sendHelperError(c, err)
+		return
+	}
+	utils.SendSuccess(c, &helperResponse{Helper: helper, EntityID: helpers.EntityID(helper)})
+}
+
+// DeleteHelper deletes a helper entity
+func (h *Handlers) DeleteHelper(c *gin.Context) {
+	if !h.requireHelpersAdapter(c) {
+		return
+	}
+
+	if err := h.helpersAdapter.DeleteHelper(c.Request.Context(), c.Param("id")); err != nil {
+		sendHelperError(c, err)
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Helper deleted"})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/intents"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// intentRequest is a command to recognize and execute locally
+type intentRequest struct {
+	Text   string `json:"text" binding:"required"`
+	Locale string `json:"locale,omitempty"` // Locale of the text, any loaded locale when empty
+}
+
+// intentResponse tells whether a command was recognized, and its result
+type intentResponse struct {
+	Matched bool            `json:"matched"`
+	Result  *intents.Result `json:"result,omitempty"`
+}
+
+// intentLocalesResponse lists the locales with intents
+type intentLocalesResponse struct {
+	Locales []string `json:"locales"`
+}
+
+// requireIntentEngine reports whether local intent recognition is available
+func (h *Handlers) requireIntentEngine(c *gin.Context) bool {
+	if h.intentEngine == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Intent recognition not enabled")
+		return false
+	}
+	return true
+}
+
+// ProcessIntent recognizes and executes a command without the LLM. Unrecognized commands
+// are reported as not matched and nothing is executed.
+func (h *Handlers) ProcessIntent(c *gin.Context) {
+	if !h.requireIntentEngine(c) {
+		return
+	}
+
+	var req intentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.intentEngine.Process(c.Request.Context(), req.Text, req.Locale)
+	if err != nil {
+		h.log.WithError(err).Error("Intent processing failed")
+		utils.SendError(c, http.StatusInternalServerError, "Intent processing failed")
+		return
+	}
+	utils.SendSuccess(c, intentResponse{Matched: result != nil, Result: result})
+}
+
+// GetIntentLocales returns the locales intents are recognized in
+func (h *Handlers) GetIntentLocales(c *gin.Context) {
+	if !h.requireIntentEngine(c) {
+		return
+	}
+	utils.SendSuccess(c, intentLocalesResponse{Locales: h.intentEngine.Locales()})
+}
@@ -613,6 +613,9 @@ func (h *KioskHandler) GetPendingCommands(c *gin.Context) {
 // POST /api/kiosk/commands/:commandId/ack
 func (h *KioskHandler) AcknowledgeCommand(c *gin.Context) {
 	commandID := c.Param("commandId")
+	if !h.commandBelongsToKiosk(c, commandID) {
+		return
+	}
 
 	err := h.service.AcknowledgeCommand(c.Request.Context(), commandID)
 	if err != nil {
@@ -632,6 +635,9 @@ func (h *KioskHandler) AcknowledgeCommand(c *gin.Context) {
 // POST /api/kiosk/commands/:commandId/complete
 func (h *KioskHandler) CompleteCommand(c *gin.Context) {
 	commandID := c.Param("commandId")
+	if !h.commandBelongsToKiosk(c, commandID) {
+		return
+	}
 
 	var request struct {
 		Success    bool                   `json:"success"`
@@ -665,6 +671,224 @@ func (h *KioskHandler) CompleteCommand(c *gin.Context) {
 	})
 }
 
+// commandBelongsToKiosk verifies that a command targets the authenticated kiosk.
+// It writes an error response and returns false otherwise.
+func (h *KioskHandler) commandBelongsToKiosk(c *gin.Context, commandID string) bool {
+	kioskToken, exists := c.Get("kiosk_token")
+	if !exists {
+		return true
+	}
+
+	command, err := h.service.GetCommand(c.Request.Context(), commandID)
+	if err != nil || command.KioskTokenID != kioskToken.(*models.KioskToken).ID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Command not found",
+		})
+		return false
+	}
+
+	return true
+}
+
+// UploadCommandScreenshot receives a screenshot captured for a screenshot command
+// POST /api/v1/kiosk-client/commands/:commandId/screenshot
+func (h *KioskHandler) UploadCommandScreenshot(c *gin.Context) {
+	kioskToken, exists := c.Get("kiosk_token")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "No kiosk token found",
+		})
+		return
+	}
+
+	token := kioskToken.(*models.KioskToken)
+	commandID := c.Param("commandId")
+
+	file, header, err := c.Request.FormFile("screenshot")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing screenshot file",
+		})
+		return
+	}
+	defer file.Close()
+
+	format := c.DefaultPostForm("format", "png")
+	if strings.HasSuffix(strings.ToLower(header.Filename), ".jpg") || strings.HasSuffix(strings.ToLower(header.Filename), ".jpeg") {
+		format = "jpeg"
+	}
+
+	url, err := h.service.SaveScreenshot(c.Request.Context(), token.ID, commandID, format, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":        true,
+		"screenshot_url": url,
+	})
+}
+
+// DownloadBundle serves a published frontend bundle to a kiosk
+// GET /api/v1/kiosk-client/bundles/:version
+func (h *KioskHandler) DownloadBundle(c *gin.Context) {
+	info, path, err := h.service.GetBundle(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Header("X-Bundle-SHA256", info.SHA256)
+	c.FileAttachment(path, fmt.Sprintf("kiosk-bundle-%s.zip", info.Version))
+}
+
+// ======== REMOTE MANAGEMENT ENDPOINTS ========
+
+// GetFleetStatus lists every paired kiosk with its connection state
+// GET /api/v1/kiosk/fleet
+func (h *KioskHandler) GetFleetStatus(c *gin.Context) {
+	fleet, err := h.service.GetFleetStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve kiosk fleet status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kiosks": fleet,
+		"count":  len(fleet),
+	})
+}
+
+// SendKioskCommand sends a typed remote command to a kiosk
+// POST /api/v1/kiosk/devices/:kioskId/commands
+func (h *KioskHandler) SendKioskCommand(c *gin.Context) {
+	var request kiosk.CommandRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	command, err := h.service.SendTypedCommand(c.Request.Context(), c.Param("kioskId"), &request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"command": command,
+	})
+}
+
+// GetKioskCommandHistory lists recent commands sent to a kiosk
+// GET /api/v1/kiosk/devices/:kioskId/commands
+func (h *KioskHandler) GetKioskCommandHistory(c *gin.Context) {
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	commands, err := h.service.GetCommandHistory(c.Request.Context(), c.Param("kioskId"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve commands",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"commands": commands,
+		"count":    len(commands),
+	})
+}
+
+// GetKioskCommand returns the status and result of a command
+// GET /api/v1/kiosk/commands/:commandId
+func (h *KioskHandler) GetKioskCommand(c *gin.Context) {
+	command, err := h.service.GetCommand(c.Request.Context(), c.Param("commandId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Command not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
+
+// GetCommandScreenshot serves the screenshot captured for a screenshot command
+// GET /api/v1/kiosk/commands/:commandId/screenshot
+func (h *KioskHandler) GetCommandScreenshot(c *gin.Context) {
+	path, err := h.service.GetScreenshotPath(c.Request.Context(), c.Param("commandId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Screenshot not found",
+		})
+		return
+	}
+
+	c.File(path)
+}
+
+// PublishBundle uploads a frontend bundle for distribution to kiosks
+// POST /api/v1/kiosk/bundles
+func (h *KioskHandler) PublishBundle(c *gin.Context) {
+	version := c.PostForm("version")
+	file, _, err := c.Request.FormFile("bundle")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing bundle file",
+		})
+		return
+	}
+	defer file.Close()
+
+	info, err := h.service.PublishBundle(c.Request.Context(), version, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, info)
+}
+
+// DeployBundle sends an update_bundle command for a published bundle
+// POST /api/v1/kiosk/bundles/:version/deploy
+func (h *KioskHandler) DeployBundle(c *gin.Context) {
+	var request struct {
+		KioskIDs []string `json:"kiosk_ids,omitempty"`
+	}
+	_ = c.ShouldBindJSON(&request)
+
+	commands, err := h.service.DeployBundle(c.Request.Context(), c.Param("version"), request.KioskIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    err.Error(),
+			"commands": commands,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":  true,
+		"commands": commands,
+		"count":    len(commands),
+	})
+}
+
 // ======== STATISTICS AND ADMIN ENDPOINTS ========
 
 // GetStats retrieves kiosk system statistics
@@ -1,73 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os/exec"
-	"sync"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/ai/mcpclient"
 	"github.com/gin-gonic/gin"
 )
 
-// MCPServerConfig represents MCP server configuration
-type MCPServerConfig struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	Command     string            `json:"command"`
-	Args        []string          `json:"args"`
-	Env         map[string]string `json:"env"`
-	CWD         string            `json:"cwd"`
-	Timeout     int               `json:"timeout"`
-	Enabled     bool              `json:"enabled"`
-	AutoRestart bool              `json:"autoRestart"`
-	MaxRestarts int               `json:"maxRestarts"`
-}
-
-// MCPServerStatus represents the status of an MCP server
-type MCPServerStatus struct {
-	ID           string              `json:"id"`
-	Name         string              `json:"name"`
-	Status       string              `json:"status"` // connecting, connected, disconnected, error, disabled
-	PID          int                 `json:"pid,omitempty"`
-	Uptime       int64               `json:"uptime,omitempty"`
-	LastError    string              `json:"lastError,omitempty"`
-	RestartCount int                 `json:"restartCount"`
-	Tools        []MCPTool           `json:"tools"`
-	Resources    []MCPResource       `json:"resources"`
-	Statistics   MCPServerStatistics `json:"statistics"`
-	LastActivity time.Time           `json:"lastActivity"`
-}
-
-// MCPTool represents an MCP tool
-type MCPTool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
-	ServerID    string                 `json:"serverId"`
-}
-
-// MCPResource represents an MCP resource
-type MCPResource struct {
-	URI         string `json:"uri"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	MimeType    string `json:"mimeType"`
-	ServerID    string `json:"serverId"`
-}
-
-// MCPServerStatistics represents server statistics
-type MCPServerStatistics struct {
-	ToolCalls        int64     `json:"toolCalls"`
-	ResourceReads    int64     `json:"resourceReads"`
-	Errors           int64     `json:"errors"`
-	Uptime           int64     `json:"uptime"`
-	LastToolCall     time.Time `json:"lastToolCall"`
-	LastResourceRead time.Time `json:"lastResourceRead"`
-}
-
 // MCPToolExecutionRequest represents a tool execution request
 type MCPToolExecutionRequest struct {
 	ServerID  string                 `json:"serverId"`
@@ -85,43 +30,46 @@ type MCPToolExecutionResponse struct {
 	ToolName      string      `json:"toolName"`
 }
 
+// MCPResourceReadRequest represents a resource read request
+type MCPResourceReadRequest struct {
+	ServerID string `json:"serverId"`
+	URI      string `json:"uri"`
+}
+
 // MCPHandler handles MCP operations
 type MCPHandler struct {
-	servers    map[string]*MCPServerStatus
-	configs    map[string]*MCPServerConfig
-	processes  map[string]*exec.Cmd
-	serversMux sync.RWMutex
-	log        *log.Logger
-	enabled    bool
+	manager *mcpclient.Manager
+	log     *log.Logger
+	enabled bool
 }
 
 // NewMCPHandler creates a new MCP handler
-func NewMCPHandler(logger *log.Logger) *MCPHandler {
+func NewMCPHandler(manager *mcpclient.Manager, logger *log.Logger) *MCPHandler {
 	return &MCPHandler{
-		servers:   make(map[string]*MCPServerStatus),
-		configs:   make(map[string]*MCPServerConfig),
-		processes: make(map[string]*exec.Cmd),
-		log:       logger,
-		enabled:   true,
+		manager: manager,
+		log:     logger,
+		enabled: true,
 	}
 }
 
 // GetMCPStatus returns the overall MCP status
 func (h *MCPHandler) GetMCPStatus(c *gin.Context) {
-	h.serversMux.RLock()
-	defer h.serversMux.RUnlock()
+	servers := h.manager.Servers()
 
 	connected := 0
-	total := len(h.servers)
 	errors := []string{}
+	var lastActivity time.Time
 
-	for _, server := range h.servers {
-		if server.Status == "connected" {
+	for _, server := range servers {
+		if server.Status == mcpclient.StatusConnected {
 			connected++
 		}
 		if server.LastError != "" {
 			errors = append(errors, fmt.Sprintf("%s: %s", server.Name, server.LastError))
 		}
+		if server.LastActivity.After(lastActivity) {
+			lastActivity = server.LastActivity
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -129,8 +77,8 @@ func (h *MCPHandler) GetMCPStatus(c *gin.Context) {
 		"data": gin.H{
 			"enabled":          h.enabled,
 			"connectedServers": connected,
-			"totalServers":     total,
-			"lastActivity":     time.Now().Format(time.RFC3339),
+			"totalServers":     len(servers),
+			"lastActivity":     lastActivity.Format(time.RFC3339),
 			"errors":           errors,
 		},
 		"timestamp": time.Now().Format(time.RFC3339),
@@ -139,14 +87,14 @@ func (h *MCPHandler) GetMCPStatus(c *gin.Context) {
 
 // GetMCPServers returns all MCP servers
 func (h *MCPHandler) GetMCPServers(c *gin.Context) {
-	h.serversMux.RLock()
-	defer h.serversMux.RUnlock()
+	statuses := h.manager.Servers()
 
-	servers := make([]gin.H, 0, len(h.servers))
-	for _, server := range h.servers {
+	servers := make([]gin.H, 0, len(statuses))
+	for _, server := range statuses {
 		servers = append(servers, gin.H{
 			"id":            server.ID,
 			"name":          server.Name,
+			"transport":     server.Transport,
 			"status":        server.Status,
 			"toolCount":     len(server.Tools),
 			"resourceCount": len(server.Resources),
@@ -163,9 +111,24 @@ func (h *MCPHandler) GetMCPServers(c *gin.Context) {
 	})
 }
 
+// GetMCPServer returns the full status of one MCP server
+func (h *MCPHandler) GetMCPServer(c *gin.Context) {
+	server, err := h.manager.Server(mcpServerID(c))
+	if err != nil {
+		sendMCPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      server,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
 // AddMCPServer adds a new MCP server
 func (h *MCPHandler) AddMCPServer(c *gin.Context) {
-	var config MCPServerConfig
+	var config ai.MCPServer
 	if err := c.ShouldBindJSON(&config); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success":   false,
@@ -175,58 +138,13 @@ func (h *MCPHandler) AddMCPServer(c *gin.Context) {
 		return
 	}
 
-	// Validate required fields
-	if config.ID == "" || config.Name == "" || config.Command == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":   false,
-			"error":     "ID, name, and command are required",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
-		return
-	}
-
-	h.serversMux.Lock()
-	defer h.serversMux.Unlock()
-
-	// Check if server already exists
-	if _, exists := h.servers[config.ID]; exists {
-		c.JSON(http.StatusConflict, gin.H{
-			"success":   false,
-			"error":     "Server with this ID already exists",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
+	if err := h.manager.AddServer(c.Request.Context(), &config); err != nil {
+		sendMCPError(c, err)
 		return
 	}
 
-	// Set defaults
-	if config.Timeout == 0 {
-		config.Timeout = 30
-	}
-	if config.MaxRestarts == 0 {
-		config.MaxRestarts = 3
-	}
-
-	// Create server status
-	status := &MCPServerStatus{
-		ID:           config.ID,
-		Name:         config.Name,
-		Status:       "disconnected",
-		Tools:        []MCPTool{},
-		Resources:    []MCPResource{},
-		Statistics:   MCPServerStatistics{},
-		LastActivity: time.Now(),
-	}
-
-	h.configs[config.ID] = &config
-	h.servers[config.ID] = status
-
 	h.log.Printf("Added MCP server: %s (%s)", config.Name, config.ID)
 
-	// Auto-connect if enabled
-	if config.Enabled {
-		go h.connectServer(config.ID)
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -239,43 +157,11 @@ func (h *MCPHandler) AddMCPServer(c *gin.Context) {
 
 // RemoveMCPServer removes an MCP server
 func (h *MCPHandler) RemoveMCPServer(c *gin.Context) {
-	serverID := c.Param("serverId")
-	if serverID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":   false,
-			"error":     "Server ID is required",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
+	if err := h.manager.RemoveServer(c.Request.Context(), mcpServerID(c)); err != nil {
+		sendMCPError(c, err)
 		return
 	}
 
-	h.serversMux.Lock()
-	defer h.serversMux.Unlock()
-
-	// Check if server exists
-	if _, exists := h.servers[serverID]; !exists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success":   false,
-			"error":     "Server not found",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
-		return
-	}
-
-	// Stop the server if running
-	if process, exists := h.processes[serverID]; exists {
-		if process.Process != nil {
-			process.Process.Kill()
-		}
-		delete(h.processes, serverID)
-	}
-
-	// Remove from maps
-	delete(h.servers, serverID)
-	delete(h.configs, serverID)
-
-	h.log.Printf("Removed MCP server: %s", serverID)
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -287,31 +173,14 @@ func (h *MCPHandler) RemoveMCPServer(c *gin.Context) {
 
 // ConnectMCPServer connects to an MCP server
 func (h *MCPHandler) ConnectMCPServer(c *gin.Context) {
-	serverID := c.Param("serverId")
-	if serverID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":   false,
-			"error":     "Server ID is required",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
+	serverID := mcpServerID(c)
+	server, err := h.manager.Server(serverID)
+	if err != nil {
+		sendMCPError(c, err)
 		return
 	}
 
-	h.serversMux.RLock()
-	_, configExists := h.configs[serverID]
-	server, serverExists := h.servers[serverID]
-	h.serversMux.RUnlock()
-
-	if !configExists || !serverExists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success":   false,
-			"error":     "Server not found",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
-		return
-	}
-
-	if server.Status == "connected" {
+	if server.Status == mcpclient.StatusConnected {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"data": gin.H{
@@ -322,7 +191,11 @@ func (h *MCPHandler) ConnectMCPServer(c *gin.Context) {
 		return
 	}
 
-	go h.connectServer(serverID)
+	go func() {
+		if err := h.manager.Connect(context.Background(), serverID); err != nil {
+			h.log.Printf("Failed to connect MCP server %s: %v", serverID, err)
+		}
+	}()
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -335,45 +208,11 @@ func (h *MCPHandler) ConnectMCPServer(c *gin.Context) {
 
 // DisconnectMCPServer disconnects from an MCP server
 func (h *MCPHandler) DisconnectMCPServer(c *gin.Context) {
-	serverID := c.Param("serverId")
-	if serverID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":   false,
-			"error":     "Server ID is required",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
+	if err := h.manager.Disconnect(mcpServerID(c)); err != nil {
+		sendMCPError(c, err)
 		return
 	}
 
-	h.serversMux.Lock()
-	defer h.serversMux.Unlock()
-
-	// Check if server exists
-	server, exists := h.servers[serverID]
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success":   false,
-			"error":     "Server not found",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
-		return
-	}
-
-	// Stop the process if running
-	if process, exists := h.processes[serverID]; exists {
-		if process.Process != nil {
-			process.Process.Kill()
-		}
-		delete(h.processes, serverID)
-	}
-
-	// Update status
-	server.Status = "disconnected"
-	server.PID = 0
-	server.Uptime = 0
-
-	h.log.Printf("Disconnected MCP server: %s", serverID)
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -383,32 +222,33 @@ func (h *MCPHandler) DisconnectMCPServer(c *gin.Context) {
 	})
 }
 
-// GetMCPTools returns all available tools
-func (h *MCPHandler) GetMCPTools(c *gin.Context) {
-	serverID := c.Query("serverId")
-
-	h.serversMux.RLock()
-	defer h.serversMux.RUnlock()
-
-	var tools []MCPTool
+// RestartMCPServer reconnects to an MCP server and resets its restart count
+func (h *MCPHandler) RestartMCPServer(c *gin.Context) {
+	serverID := mcpServerID(c)
+	if _, err := h.manager.Server(serverID); err != nil {
+		sendMCPError(c, err)
+		return
+	}
 
-	if serverID != "" {
-		// Get tools for specific server
-		if server, exists := h.servers[serverID]; exists {
-			tools = server.Tools
-		}
-	} else {
-		// Get tools from all servers
-		for _, server := range h.servers {
-			if server.Status == "connected" {
-				tools = append(tools, server.Tools...)
-			}
+	go func() {
+		if err := h.manager.Restart(context.Background(), serverID); err != nil {
+			h.log.Printf("Failed to restart MCP server %s: %v", serverID, err)
 		}
-	}
+	}()
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":   true,
-		"data":      tools,
+		"message":   "MCP server restart initiated",
+		"server_id": serverID,
+		"timestamp": time.Now(),
+	})
+}
+
+// GetMCPTools returns all available tools
+func (h *MCPHandler) GetMCPTools(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      h.manager.Tools(c.Query("serverId")),
 		"timestamp": time.Now().Format(time.RFC3339),
 	})
 }
@@ -435,50 +275,27 @@ func (h *MCPHandler) ExecuteMCPTools(c *gin.Context) {
 	}
 
 	start := time.Now()
-
-	h.serversMux.RLock()
-	server, exists := h.servers[request.ServerID]
-	h.serversMux.RUnlock()
-
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success":   false,
-			"error":     "Server not found",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
+	result, err := h.manager.CallTool(c.Request.Context(), request.ServerID, request.ToolName, request.Arguments)
+	if errors.Is(err, mcpclient.ErrServerNotFound) || errors.Is(err, mcpclient.ErrServerNotConnected) {
+		sendMCPError(c, err)
 		return
 	}
 
-	if server.Status != "connected" {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"success":   false,
-			"error":     "Server not connected",
-			"timestamp": time.Now().Format(time.RFC3339),
-		})
-		return
-	}
-
-	// Mock execution for now - in a real implementation, this would
-	// communicate with the actual MCP server process
-	executionTime := time.Since(start).Milliseconds()
-
 	response := MCPToolExecutionResponse{
-		Success: true,
-		Result: map[string]interface{}{
-			"message":   fmt.Sprintf("Tool %s executed successfully", request.ToolName),
-			"arguments": request.Arguments,
-		},
-		ExecutionTime: executionTime,
+		ExecutionTime: time.Since(start).Milliseconds(),
 		ServerID:      request.ServerID,
 		ToolName:      request.ToolName,
 	}
-
-	// Update statistics
-	h.serversMux.Lock()
-	server.Statistics.ToolCalls++
-	server.Statistics.LastToolCall = time.Now()
-	server.LastActivity = time.Now()
-	h.serversMux.Unlock()
+	switch {
+	case err != nil:
+		response.Error = err.Error()
+	case result.IsError:
+		response.Result = result
+		response.Error = result.Text()
+	default:
+		response.Success = true
+		response.Result = result
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":   true,
@@ -487,143 +304,76 @@ func (h *MCPHandler) ExecuteMCPTools(c *gin.Context) {
 	})
 }
 
-// connectServer connects to an MCP server (internal method)
-func (h *MCPHandler) connectServer(serverID string) {
-	h.serversMux.RLock()
-	config := h.configs[serverID]
-	server := h.servers[serverID]
-	h.serversMux.RUnlock()
+// GetMCPResources returns the resources of connected MCP servers
+func (h *MCPHandler) GetMCPResources(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      h.manager.Resources(c.Query("serverId")),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
 
-	if config == nil || server == nil {
+// ReadMCPResource reads a resource from an MCP server
+func (h *MCPHandler) ReadMCPResource(c *gin.Context) {
+	var request MCPResourceReadRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.ServerID == "" || request.URI == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":   false,
+			"error":     "ServerID and URI are required",
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
 		return
 	}
 
-	h.serversMux.Lock()
-	server.Status = "connecting"
-	h.serversMux.Unlock()
-
-	h.log.Printf("Connecting to MCP server: %s", config.Name)
-
-	// Mock connection for now - in a real implementation, this would
-	// start the actual MCP server process and establish communication
-	time.Sleep(2 * time.Second)
-
-	h.serversMux.Lock()
-	server.Status = "connected"
-	server.PID = 12345 // Mock PID
-	server.Uptime = time.Now().Unix()
-	server.LastActivity = time.Now()
-
-	// Mock some tools and resources
-	server.Tools = []MCPTool{
-		{
-			Name:        "file_reader",
-			Description: "Read files from the filesystem",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"path": map[string]interface{}{
-						"type":        "string",
-						"description": "Path to the file to read",
-					},
-				},
-				"required": []string{"path"},
-			},
-			ServerID: serverID,
-		},
-		{
-			Name:        "web_search",
-			Description: "Search the web for information",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"query": map[string]interface{}{
-						"type":        "string",
-						"description": "Search query",
-					},
-				},
-				"required": []string{"query"},
-			},
-			ServerID: serverID,
-		},
-	}
-
-	server.Resources = []MCPResource{
-		{
-			URI:         "file:///tmp/example.txt",
-			Name:        "example.txt",
-			Description: "Example text file",
-			MimeType:    "text/plain",
-			ServerID:    serverID,
-		},
+	result, err := h.manager.ReadResource(c.Request.Context(), request.ServerID, request.URI)
+	if err != nil {
+		sendMCPError(c, err)
+		return
 	}
 
-	h.serversMux.Unlock()
-
-	h.log.Printf("Connected to MCP server: %s", config.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      result,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
 }
 
 // Shutdown gracefully shuts down the MCP handler
 func (h *MCPHandler) Shutdown() {
-	h.serversMux.Lock()
-	defer h.serversMux.Unlock()
-
-	// Stop all processes
-	for serverID, process := range h.processes {
-		if process.Process != nil {
-			h.log.Printf("Stopping MCP server process: %s", serverID)
-			process.Process.Kill()
-		}
-	}
-
+	h.manager.Shutdown()
 	h.log.Println("MCP handler shutdown complete")
 }
 
-// RestartMCPServer restarts an MCP server
-func (h *Handlers) RestartMCPServer(c *gin.Context) {
-	serverID := c.Param("id")
-	if serverID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Server ID is required"})
-		return
-	}
-
-	// First stop the server
-	h.mcpHandler.serversMux.Lock()
-	server, exists := h.mcpHandler.servers[serverID]
-	if !exists {
-		h.mcpHandler.serversMux.Unlock()
-		c.JSON(http.StatusNotFound, gin.H{"error": "MCP server not found"})
-		return
-	}
-
-	// Stop existing process if running
-	if process, exists := h.mcpHandler.processes[serverID]; exists && process.Process != nil {
-		process.Process.Kill()
-		delete(h.mcpHandler.processes, serverID)
+// mcpServerID returns the server ID path parameter, which the AI and MCP route groups name differently
+func mcpServerID(c *gin.Context) string {
+	if serverID := c.Param("serverId"); serverID != "" {
+		return serverID
 	}
+	return c.Param("id")
+}
 
-	// Update status
-	server.Status = "restarting"
-	h.mcpHandler.servers[serverID] = server
-	h.mcpHandler.serversMux.Unlock()
-
-	// Start the server again
-	go func() {
-		time.Sleep(2 * time.Second) // Brief pause before restart
-		// Note: In a full implementation, you'd restart the server process
-		// For now, just update status back to running
-		h.mcpHandler.serversMux.Lock()
-		if s, exists := h.mcpHandler.servers[serverID]; exists {
-			s.Status = "running"
-			h.mcpHandler.servers[serverID] = s
-		}
-		h.mcpHandler.serversMux.Unlock()
-	}()
-
-	c.JSON(http.StatusOK, gin.H{
-		"success":   true,
-		"message":   "MCP server restart initiated",
-		"server_id": serverID,
-		"timestamp": time.Now(),
+// sendMCPError maps MCP manager errors to HTTP responses
+func sendMCPError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, mcpclient.ErrServerNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, mcpclient.ErrServerExists):
+		status = http.StatusConflict
+	case errors.Is(err, mcpclient.ErrInvalidServer):
+		status = http.StatusBadRequest
+	case errors.Is(err, mcpclient.ErrServerNotConnected):
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"success":   false,
+		"error":     err.Error(),
+		"timestamp": time.Now().Format(time.RFC3339),
 	})
 }
+
+// RestartMCPServer restarts an MCP server
+func (h *Handlers) RestartMCPServer(c *gin.Context) {
+	h.mcpHandler.RestartMCPServer(c)
+}
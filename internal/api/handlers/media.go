@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -301,6 +302,90 @@ func (mh *MediaHandler) GetStreamingURL(c *gin.Context) {
 	})
 }
 
+// GetHLSMasterPlaylist serves the adaptive streaming playlist listing all renditions of a video.
+// Players cannot send auth headers for playlist and segment requests, so access is
+// granted by the signed token returned from GetStreamingURL.
+func (mh *MediaHandler) GetHLSMasterPlaylist(c *gin.Context) {
+	fileID := c.Param("id")
+	token := c.Query("token")
+	if !mh.verifyStreamToken(c, fileID, token) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	playlist, err := mh.mediaStreamer.GetHLSMasterPlaylist(ctx, fileID, token)
+	if err != nil {
+		mh.logger.WithError(err).WithField("file_id", fileID).Error("Failed to generate HLS playlist")
+		utils.SendError(c, http.StatusNotFound, fmt.Sprintf("Failed to generate playlist: %v", err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "application/vnd.apple.mpegurl", []byte(playlist))
+}
+
+// GetHLSRenditionResource serves a rendition playlist (index.m3u8) or one of its segments
+func (mh *MediaHandler) GetHLSRenditionResource(c *gin.Context) {
+	fileID := c.Param("id")
+	rendition := c.Param("rendition")
+	resource := c.Param("resource")
+	token := c.Query("token")
+	if !mh.verifyStreamToken(c, fileID, token) {
+		return
+	}
+
+	if resource == "index.m3u8" {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		playlist, err := mh.mediaStreamer.GetHLSMediaPlaylist(ctx, fileID, rendition, token)
+		if err != nil {
+			mh.logger.WithError(err).WithField("file_id", fileID).Error("Failed to generate HLS rendition playlist")
+			utils.SendError(c, http.StatusNotFound, fmt.Sprintf("Failed to generate playlist: %v", err))
+			return
+		}
+
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "application/vnd.apple.mpegurl", []byte(playlist))
+		return
+	}
+
+	// Segments may need transcoding, which takes longer than a playlist
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	path, err := mh.mediaStreamer.GetHLSSegment(ctx, fileID, rendition, resource)
+	if err != nil {
+		mh.logger.WithError(err).WithFields(logrus.Fields{
+			"file_id":   fileID,
+			"rendition": rendition,
+			"segment":   resource,
+		}).Error("Failed to produce HLS segment")
+		utils.SendError(c, http.StatusNotFound, fmt.Sprintf("Segment not available: %v", err))
+		return
+	}
+
+	c.Header("Content-Type", "video/mp2t")
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.File(path)
+}
+
+// verifyStreamToken rejects requests without a valid signed stream token
+func (mh *MediaHandler) verifyStreamToken(c *gin.Context, fileID, token string) bool {
+	if mh.mediaStreamer == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Media streaming is not available")
+		return false
+	}
+
+	if err := mh.mediaStreamer.VerifyStreamToken(fileID, token); err != nil {
+		utils.SendError(c, http.StatusUnauthorized, err.Error())
+		return false
+	}
+	return true
+}
+
 // ValidateMedia validates an uploaded media file
 func (mh *MediaHandler) ValidateMedia(c *gin.Context) {
 	file, header, err := c.Request.FormFile("media")
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/notifications"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// notificationUserID returns the authenticated user, defaulting to "1" like the preferences API
+func notificationUserID(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return userID
+	}
+	return "1"
+}
+
+// requireNotificationService reports whether the notification service is available
+func (h *Handlers) requireNotificationService(c *gin.Context) bool {
+	if h.notificationService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Notification service not available")
+		return false
+	}
+	return true
+}
+
+// lookupNotification loads the notification in the :id parameter if it belongs to the current user
+func (h *Handlers) lookupNotification(c *gin.Context) (*models.Notification, bool) {
+	if !h.requireNotificationService(c) {
+		return nil, false
+	}
+
+	notification, err := h.notificationService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil || notification.UserID != notificationUserID(c) {
+		utils.SendError(c, http.StatusNotFound, "Notification not found")
+		return nil, false
+	}
+
+	return notification, true
+}
+
+// GetNotifications lists the notifications of the current user
+func (h *Handlers) GetNotifications(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	userID := notificationUserID(c)
+	filter := models.NotificationFilter{
+		UserID:             userID,
+		Type:               c.Query("type"),
+		UnreadOnly:         c.Query("unread") == "true",
+		UnacknowledgedOnly: c.Query("unacknowledged") == "true",
+		Limit:              50,
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			filter.Limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			filter.Offset = parsed
+		}
+	}
+	if since := c.Query("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid since timestamp, expected RFC3339")
+			return
+		}
+		filter.Since = parsed
+	}
+
+	list, total, err := h.notificationService.List(c.Request.Context(), filter)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to list notifications")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to list notifications")
+		return
+	}
+	if list == nil {
+		list = []*models.Notification{}
+	}
+
+	unread, _ := h.notificationService.UnreadCount(c.Request.Context(), userID)
+
+	utils.SendSuccessWithMeta(c, list, gin.H{
+		"total":  total,
+		"unread": unread,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// GetNotificationUnreadCount returns the number of unread notifications of the current user
+func (h *Handlers) GetNotificationUnreadCount(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(c.Request.Context(), notificationUserID(c))
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to count notifications")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"unread": count})
+}
+
+// GetNotification returns one notification
+func (h *Handlers) GetNotification(c *gin.Context) {
+	notification, ok := h.lookupNotification(c)
+	if !ok {
+		return
+	}
+
+	utils.SendSuccess(c, notification)
+}
+
+// MarkNotificationRead marks a notification as read
+func (h *Handlers) MarkNotificationRead(c *gin.Context) {
+	notification, ok := h.lookupNotification(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), notification.ID); err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to mark notification read")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"id": notification.ID, "read": true})
+}
+
+// MarkAllNotificationsRead marks all notifications of the current user as read
+func (h *Handlers) MarkAllNotificationsRead(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(c.Request.Context(), notificationUserID(c))
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to mark notifications read")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"updated": updated})
+}
+
+// AcknowledgeNotification acknowledges a notification
+func (h *Handlers) AcknowledgeNotification(c *gin.Context) {
+	notification, ok := h.lookupNotification(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.Acknowledge(c.Request.Context(), notification.ID, notificationUserID(c)); err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to acknowledge notification")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"id": notification.ID, "acknowledged": true})
+}
+
+// RespondToNotification records the response to an action of a notification, which fires a
+// notification_action automation event
+func (h *Handlers) RespondToNotification(c *gin.Context) {
+	notification, ok := h.lookupNotification(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Response map[string]interface{} `json:"response"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	updated, err := h.notificationService.Respond(c.Request.Context(), notification.ID, c.Param("actionId"), notificationUserID(c), request.Response)
+	if err != nil {
+		h.sendNotificationResponseError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, updated)
+}
+
+// RespondToNotificationLink records a response from a signed action link, as used by ntfy buttons
+func (h *Handlers) RespondToNotificationLink(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	updated, err := h.notificationService.RespondWithToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.sendNotificationResponseError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"id": updated.ID, "responded": true})
+}
+
+func (h *Handlers) sendNotificationResponseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, notifications.ErrUnknownAction):
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, notifications.ErrAlreadyResponded):
+		utils.SendError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, notifications.ErrInvalidActionToken):
+		utils.SendError(c, http.StatusForbidden, err.Error())
+	default:
+		h.log.WithError(err).Error("Failed to record notification response")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to record notification response")
+	}
+}
+
+// DeleteNotification deletes a notification
+func (h *Handlers) DeleteNotification(c *gin.Context) {
+	notification, ok := h.lookupNotification(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.Delete(c.Request.Context(), notification.ID); err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to delete notification")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"id": notification.ID, "deleted": true})
+}
+
+// SendTestNotification sends a notification to the current user through their channels
+func (h *Handlers) SendTestNotification(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	var request struct {
+		Title    string   `json:"title"`
+		Message  string   `json:"message"`
+		Priority string   `json:"priority"`
+		Channels []string `json:"channels"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if request.Title == "" {
+		request.Title = "Test notification"
+	}
+	if request.Message == "" {
+		request.Message = "Notifications are working."
+	}
+	if request.Priority == "" {
+		request.Priority = string(types.NotificationPriorityMedium)
+	}
+
+	sent, err := h.notificationService.Send(c.Request.Context(), &types.AINotification{
+		Type:     types.NotificationTypeSystemInfo,
+		Title:    request.Title,
+		Message:  request.Message,
+		Priority: types.NotificationPriority(request.Priority),
+		Source:   "test",
+		UserID:   notificationUserID(c),
+	}, request.Channels)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SendSuccess(c, sent)
+}
+
+// GetNotificationChannels returns the configured channels and the Web Push public key
+func (h *Handlers) GetNotificationChannels(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	response := gin.H{"channels": h.notificationService.Channels()}
+	if h.cfg.Notifications.WebPush.Enabled {
+		response["vapid_public_key"] = h.cfg.Notifications.WebPush.VAPIDPublicKey
+	}
+
+	utils.SendSuccess(c, response)
+}
+
+// SubscribeWebPush registers a browser push subscription for the current user
+func (h *Handlers) SubscribeWebPush(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	var request struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+		Keys     struct {
+			P256dh string `json:"p256dh" binding:"required"`
+			Auth   string `json:"auth" binding:"required"`
+		} `json:"keys"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Subscription needs endpoint, keys.p256dh and keys.auth")
+		return
+	}
+
+	subscription := &models.PushSubscription{
+		UserID:    notificationUserID(c),
+		Endpoint:  request.Endpoint,
+		P256dh:    request.Keys.P256dh,
+		Auth:      request.Keys.Auth,
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err := h.repos.Notification.SavePushSubscription(c.Request.Context(), subscription); err != nil {
+		h.log.WithError(err).Error("Failed to save push subscription")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to save push subscription")
+		return
+	}
+
+	utils.SendSuccess(c, subscription)
+}
+
+// UnsubscribeWebPush removes a browser push subscription
+func (h *Handlers) UnsubscribeWebPush(c *gin.Context) {
+	if !h.requireNotificationService(c) {
+		return
+	}
+
+	var request struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "endpoint is required")
+		return
+	}
+
+	if err := h.repos.Notification.DeletePushSubscription(c.Request.Context(), request.Endpoint); err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to remove push subscription")
+		return
+	}
+
+	utils.SendSuccess(c, gin.H{"endpoint": request.Endpoint, "deleted": true})
+}
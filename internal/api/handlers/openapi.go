@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/helpers"
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/api/openapi"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/audit"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/presence"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/voice"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/watchdog"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// messageResponse is the data of responses that only confirm an action
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// auditQuery lists the filters accepted by the audit log and export endpoints
+var auditQuery = []openapi.Parameter{
+	{Name: "actor_type", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "actor_id", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "category", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "action", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "target", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "outcome", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{audit.OutcomeSuccess, audit.OutcomeFailure}}},
+	{Name: "since", In: "query", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+	{Name: "until", In: "query", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+	{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+	{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+}
+
+// roomQuery lists the options accepted by the room list endpoints
+var roomQuery = []openapi.Parameter{
+	{Name: "include_entities", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	{Name: "source", In: "query", Schema: &openapi.Schema{Type: "string"}},
+}
+
+// entityQuery lists the options accepted by the entity list endpoints
+var entityQuery = []openapi.Parameter{
+	{Name: "include_room", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	{Name: "include_area", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	{Name: "available_only", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	{Name: "include_hidden", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+}
+
+// APIImplementations lists the concrete types behind the interfaces in described responses
+func APIImplementations() []openapi.Implementations {
+	return []openapi.Implementations{
+		{Interface: (*types.PMAEntity)(nil), Types: []interface{}{
+			&types.PMABaseEntity{}, &types.PMALightEntity{}, &types.PMASwitchEntity{}, &types.PMASensorEntity{},
+			&unified.GroupEntity{}, &helpers.HelperEntity{},
+		}},
+	}
+}
+
+// APIRoutes describes the request and response types of the typed /api/v1 routes. The OpenAPI
+// document and request validation are generated from these descriptions; routes that are not
+// listed are documented without schemas.
+func APIRoutes() []openapi.Route {
+	return []openapi.Route{
+		// Entities
+		{Method: http.MethodGet, Path: "/api/v1/entities", Summary: "List entities", Query: append(entityQuery[:len(entityQuery):len(entityQuery)],
+			openapi.Parameter{Name: "domain", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			openapi.Parameter{Name: "capabilities", In: "query", Description: "JSON array of capabilities", Schema: &openapi.Schema{Type: "string"}}),
+			Response: []types.PMAEntity{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/", Summary: "List entities", Query: append(entityQuery[:len(entityQuery):len(entityQuery)],
+			openapi.Parameter{Name: "domain", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			openapi.Parameter{Name: "capabilities", In: "query", Description: "JSON array of capabilities", Schema: &openapi.Schema{Type: "string"}}),
+			Response: []types.PMAEntity{}},
+		{Method: http.MethodPost, Path: "/api/v1/entities/", Summary: "Create or update a PMA entity", Request: entityRequest{}, Response: &entityChangeResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/:id", Summary: "Get an entity with its room and area", Query: entityQuery[:2], Response: &unified.EntityWithRoom{}},
+		{Method: http.MethodDelete, Path: "/api/v1/entities/:id", Summary: "Delete a PMA entity", Response: &entityChangeResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/entities/:id/action", Summary: "Execute an action on an entity", Request: types.PMAControlAction{}, Response: &entityActionResponse{}},
+		{Method: http.MethodPut, Path: "/api/v1/entities/:id/state", Summary: "Set the state of an entity (deprecated, use the action endpoint)", Request: entityStateRequest{}, Response: &entityStateResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/search", Summary: "Search entities", Query: append([]openapi.Parameter{
+			{Name: "q", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		}, entityQuery...), Response: []*unified.EntityWithRoom{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/type/:type", Summary: "List entities of a type", Query: entityQuery, Response: []*unified.EntityWithRoom{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/source/:source", Summary: "List entities of a source", Query: entityQuery, Response: []*unified.EntityWithRoom{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/room/:roomId", Summary: "List entities in a room", Query: entityQuery, Response: []*unified.EntityWithRoom{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/types", Summary: "List the supported entity types", Response: []entityTypeResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/capabilities", Summary: "List the supported entity capabilities", Response: []capabilityResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/sync/status", Summary: "Get the connection and sync state of each adapter", Response: []adapterSyncStatus{}},
+
+		// Rooms
+		{Method: http.MethodGet, Path: "/api/v1/rooms", Summary: "List rooms", Query: roomQuery, Response: []*RoomWithEntities{}},
+		{Method: http.MethodPost, Path: "/api/v1/rooms", Summary: "Create a room", Request: roomRequest{}, Response: &roomChangeResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/rooms/", Summary: "List rooms", Query: roomQuery, Response: []*RoomWithEntities{}},
+		{Method: http.MethodPost, Path: "/api/v1/rooms/", Summary: "Create a room", Request: roomRequest{}, Response: &roomChangeResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/rooms/:id", Summary: "Get a room", Query: roomQuery[:1], Response: &RoomWithEntities{}},
+		{Method: http.MethodPut, Path: "/api/v1/rooms/:id", Summary: "Update a room", Request: roomRequest{}, Response: &roomChangeResponse{}},
+		{Method: http.MethodDelete, Path: "/api/v1/rooms/:id", Summary: "Delete a room, optionally moving its entities to another", Request: roomDeleteRequest{}, Response: &roomChangeResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/rooms/stats", Summary: "Summarize the rooms", Response: &roomStatsResponse{}},
+
+		// Areas
+		{Method: http.MethodPost, Path: "/api/v1/areas/", Summary: "Create an area", Request: models.CreateAreaRequest{}, Response: &models.Area{}},
+		{Method: http.MethodGet, Path: "/api/v1/areas/:id", Summary: "Get an area", Query: []openapi.Parameter{
+			{Name: "include_children", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		}, Response: &models.AreaWithChildren{}},
+		{Method: http.MethodPut, Path: "/api/v1/areas/:id", Summary: "Update an area", Request: models.UpdateAreaRequest{}, Response: &models.Area{}},
+		{Method: http.MethodDelete, Path: "/api/v1/areas/:id", Summary: "Delete an area", Response: messageResponse{}},
+
+		// Scenes
+		{Method: http.MethodGet, Path: "/api/v1/scenes", Summary: "List scenes", Response: &scenesResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/scenes/", Summary: "List scenes", Response: &scenesResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/scenes/:id", Summary: "Get a scene", Response: new(types.PMAEntity)},
+		{Method: http.MethodPost, Path: "/api/v1/scenes/:id/activate", Summary: "Activate a scene", Response: &sceneActivationResponse{}},
+
+		// Automation rules
+		{Method: http.MethodGet, Path: "/api/v1/automation/rules", Summary: "List automation rules", Query: []openapi.Parameter{
+			{Name: "enabled", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+			{Name: "category", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "tag", In: "query", Schema: &openapi.Schema{Type: "string"}},
+		}, Response: &automationRulesResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/automation/rules", Summary: "Create an automation rule", Request: map[string]interface{}{}, Response: &automationRuleResponse{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/automation/rules/:id", Summary: "Get an automation rule", Response: &automationRuleResponse{}},
+		{Method: http.MethodPut, Path: "/api/v1/automation/rules/:id", Summary: "Replace an automation rule", Request: map[string]interface{}{}, Response: &automationRuleResponse{}},
+		{Method: http.MethodDelete, Path: "/api/v1/automation/rules/:id", Summary: "Delete an automation rule", Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/automation/rules/:id/enable", Summary: "Enable an automation rule", Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/automation/rules/:id/disable", Summary: "Disable an automation rule", Response: messageResponse{}},
+
+		// Entity groups
+		{Method: http.MethodGet, Path: "/api/v1/entities/groups", Summary: "List group entities", Response: []*entityGroupResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/entities/groups", Summary: "Create a group entity", Request: entityGroupRequest{}, Response: &entityGroupResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/groups/:id", Summary: "Get a group entity", Response: &entityGroupResponse{}},
+		{Method: http.MethodPut, Path: "/api/v1/entities/groups/:id", Summary: "Update a group entity", Request: entityGroupRequest{}, Response: &entityGroupResponse{}},
+		{Method: http.MethodDelete, Path: "/api/v1/entities/groups/:id", Summary: "Delete a group entity", Response: messageResponse{}},
+
+		// Entity customizations
+		{Method: http.MethodGet, Path: "/api/v1/entities/customizations", Summary: "List entity customizations", Response: []*models.EntityCustomization{}},
+		{Method: http.MethodPut, Path: "/api/v1/entities/customizations", Summary: "Update several entity customizations", Request: bulkEntityCustomizationRequest{}, Response: []*models.EntityCustomization{}},
+		{Method: http.MethodGet, Path: "/api/v1/entities/:id/customization", Summary: "Get an entity customization", Response: &models.EntityCustomization{}},
+		{Method: http.MethodPut, Path: "/api/v1/entities/:id/customization", Summary: "Update an entity customization", Request: entityCustomizationRequest{}, Response: &models.EntityCustomization{}},
+		{Method: http.MethodDelete, Path: "/api/v1/entities/:id/customization", Summary: "Delete an entity customization", Response: messageResponse{}},
+
+		// Presence detection
+		{Method: http.MethodGet, Path: "/api/v1/presence/persons", Summary: "List persons with their presence", Response: []*presence.PersonStatus{}},
+		{Method: http.MethodPost, Path: "/api/v1/presence/persons", Summary: "Create a person", Request: presencePersonRequest{}, Response: &presence.PersonStatus{}},
+		{Method: http.MethodGet, Path: "/api/v1/presence/persons/:id", Summary: "Get a person with their presence", Response: &presence.PersonStatus{}},
+		{Method: http.MethodPut, Path: "/api/v1/presence/persons/:id", Summary: "Update a person", Request: presencePersonRequest{}, Response: &presence.PersonStatus{}},
+		{Method: http.MethodDelete, Path: "/api/v1/presence/persons/:id", Summary: "Delete a person", Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/presence/persons/:id/trackers", Summary: "Attach a tracker to a person", Request: models.PresenceTracker{}, Response: &presence.PersonStatus{}},
+		{Method: http.MethodDelete, Path: "/api/v1/presence/persons/:id/trackers/:tracker_id", Summary: "Detach a tracker from a person", Response: &presence.PersonStatus{}},
+		{Method: http.MethodPost, Path: "/api/v1/presence/persons/:id/checkin", Summary: "Record a check-in for a person", Request: presence.CheckIn{}, Response: &presence.PersonStatus{}},
+		{Method: http.MethodPost, Path: "/api/v1/presence/checkin", Summary: "Record a check-in for the current user", Request: presence.CheckIn{}, Response: &presence.PersonStatus{}},
+		{Method: http.MethodGet, Path: "/api/v1/presence/zones", Summary: "List zones", Response: []*models.PresenceZone{}},
+		{Method: http.MethodPost, Path: "/api/v1/presence/zones", Summary: "Create or replace a zone", Request: models.PresenceZone{}, Response: models.PresenceZone{}},
+		{Method: http.MethodDelete, Path: "/api/v1/presence/zones/:id", Summary: "Delete a zone", Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/presence/refresh", Summary: "Evaluate every person now", Response: []*presence.PersonStatus{}},
+
+		// Device health watchdog
+		{Method: http.MethodGet, Path: "/api/v1/watchdog/attention", Summary: "List entities needing attention", Response: &watchdog.Attention{}},
+		{Method: http.MethodGet, Path: "/api/v1/watchdog/entities/:id", Summary: "Get the health of an entity", Response: &watchdog.EntityHealth{}},
+		{Method: http.MethodPost, Path: "/api/v1/watchdog/check", Summary: "Check every entity now", Response: &watchdog.Attention{}},
+
+		// Helper entities
+		{Method: http.MethodGet, Path: "/api/v1/helpers/", Summary: "List helper entities", Response: []*helperResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/helpers/", Summary: "Create a helper entity", Request: helperRequest{}, Response: &helperResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/helpers/:id", Summary: "Get a helper entity", Response: &helperResponse{}},
+		{Method: http.MethodPut, Path: "/api/v1/helpers/:id", Summary: "Update a helper entity", Request: helperRequest{}, Response: &helperResponse{}},
+		{Method: http.MethodDelete, Path: "/api/v1/helpers/:id", Summary: "Delete a helper entity", Response: messageResponse{}},
+
+		// Home Assistant import
+		{Method: http.MethodPost, Path: "/api/v1/automation/import/homeassistant", Summary: "Import Home Assistant automations, scripts and scenes as automation rules", Request: haImportRequest{}, Response: &automation.HAImportReport{}},
+
+		// Local intent recognition
+		{Method: http.MethodPost, Path: "/api/v1/ai/intents", Summary: "Recognize and execute a command without the LLM", Request: intentRequest{}, Response: &intentResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/ai/intents/locales", Summary: "List the locales intents are recognized in", Response: &intentLocalesResponse{}},
+
+		// Long-term memory
+		{Method: http.MethodGet, Path: "/api/v1/ai/memories", Summary: "List the facts the assistant remembers", Query: []openapi.Parameter{
+			{Name: "source", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{models.AIMemorySourceConversation, models.AIMemorySourceNote}}},
+			{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+			{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+		}, Response: &memoriesResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/ai/memories/search", Summary: "Find the memories recalled for a message", Query: []openapi.Parameter{
+			{Name: "q", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+		}, Response: []ai.RecalledMemory{}},
+		{Method: http.MethodPost, Path: "/api/v1/ai/memories", Summary: "Add a note for the assistant to remember", Request: memoryNoteRequest{}, Response: &models.AIMemory{}},
+		{Method: http.MethodDelete, Path: "/api/v1/ai/memories/:id", Summary: "Forget a memory", Response: messageResponse{}},
+
+		// Home context
+		{Method: http.MethodGet, Path: "/api/v1/ai/context/home", Summary: "Preview the home state added to the prompt for a message", Query: []openapi.Parameter{
+			{Name: "q", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			{Name: "provider", In: "query", Schema: &openapi.Schema{Type: "string"}},
+		}, Response: &homeContextResponse{}},
+
+		// Voice pipeline
+		{Method: http.MethodGet, Path: "/api/v1/voice/status", Summary: "Get the voice services and whether they answer", Response: &voice.Status{}},
+		{Method: http.MethodPost, Path: "/api/v1/voice/synthesize", Summary: "Speak text as a WAV file", Request: voiceSynthesizeRequest{}, ContentType: "audio/wav"},
+
+		// Configuration
+		{Method: http.MethodPost, Path: "/api/v1/config/reload", Summary: "Reload the configuration file and apply changes to running services", Response: &config.ReloadResult{}},
+
+		// Audit log
+		{Method: http.MethodGet, Path: "/api/v1/audit/", Summary: "Query the audit log", Query: auditQuery, Response: &auditLogResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/audit/verify", Summary: "Verify the audit log hash chain", Response: &audit.VerifyResult{}},
+		{Method: http.MethodGet, Path: "/api/v1/audit/export", Summary: "Export the audit log as CSV or JSON lines", Query: append(auditQuery[:len(auditQuery):len(auditQuery)],
+			openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{audit.FormatCSV, audit.FormatJSONL}}}),
+			ContentType: "text/csv"},
+
+		// Outbound webhooks
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/", Summary: "List webhooks", Response: []*webhookResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/webhooks/", Summary: "Create a webhook", Request: webhookRequest{}, Response: &webhookResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/events", Summary: "List the event types webhooks can subscribe to", Response: []string{}},
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/:id", Summary: "Get a webhook", Response: &webhookResponse{}},
+		{Method: http.MethodPut, Path: "/api/v1/webhooks/:id", Summary: "Update a webhook", Request: webhookRequest{}, Response: &webhookResponse{}},
+		{Method: http.MethodDelete, Path: "/api/v1/webhooks/:id", Summary: "Delete a webhook and its delivery log", Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", Summary: "List the deliveries of a webhook", Query: []openapi.Parameter{
+			{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+			{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer"}},
+		}, Response: &webhookDeliveriesResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/webhooks/:id/test", Summary: "Deliver a test event to a webhook", Response: &models.WebhookDelivery{}},
+	}
+}
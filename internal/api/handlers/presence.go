@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/presence"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// presencePersonRequest is the body of person create and update requests
+type presencePersonRequest struct {
+	ID                  string                    `json:"id"`
+	Name                string                    `json:"name"`
+	UserID              string                    `json:"user_id"`
+	Picture             string                    `json:"picture"`
+	ConsiderHomeSeconds int                       `json:"consider_home_seconds"`
+	Trackers            []*models.PresenceTracker `json:"trackers"`
+}
+
+// requirePresenceService reports whether presence detection is available
+func (h *Handlers) requirePresenceService(c *gin.Context) bool {
+	if h.presenceService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Presence detection not enabled")
+		return false
+	}
+	return true
+}
+
+// sendPresenceError maps presence errors to HTTP responses
+func sendPresenceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, presence.ErrPersonNotFound), errors.Is(err, presence.ErrTrackerNotFound):
+		utils.SendError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, presence.ErrNoAppTracker):
+		utils.SendError(c, http.StatusConflict, err.Error())
+	default:
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+	}
+}
+
+// GetPresencePersons lists every person with their presence
+func (h *Handlers) GetPresencePersons(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.presenceService.ListPersons())
+}
+
+// GetPresencePerson returns the presence of one person
+func (h *Handlers) GetPresencePerson(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	status, err := h.presenceService.GetPerson(c.Param("id"))
+	if err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+	utils.SendSuccess(c, status)
+}
+
+// CreatePresencePerson adds a person, optionally with trackers
+func (h *Handlers) CreatePresencePerson(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	var req presencePersonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	status, err := h.presenceService.CreatePerson(c.Request.Context(), &models.PresencePerson{
+		ID:                  req.ID,
+		Name:                req.Name,
+		UserID:              req.UserID,
+		Picture:             req.Picture,
+		ConsiderHomeSeconds: req.ConsiderHomeSeconds,
+	})
+	if err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+
+	for _, tracker := range req.Trackers {
+		if status, err = h.presenceService.AddTracker(c.Request.Context(), status.ID, tracker); err != nil {
+			sendPresenceError(c, err)
+			return
+		}
+	}
+
+	utils.SendSuccess(c, status)
+}
+
+// UpdatePresencePerson updates the settings of a person
+func (h *Handlers) UpdatePresencePerson(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	var req presencePersonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	status, err := h.presenceService.UpdatePerson(c.Request.Context(), c.Param("id"), &models.PresencePerson{
+		Name:                req.Name,
+		UserID:              req.UserID,
+		Picture:             req.Picture,
+		ConsiderHomeSeconds: req.ConsiderHomeSeconds,
+	})
+	if err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+	utils.SendSuccess(c, status)
+}
+
+// DeletePresencePerson removes a person and their entity
+func (h *Handlers) DeletePresencePerson(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	if err := h.presenceService.DeletePerson(c.Request.Context(), c.Param("id")); err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Person deleted"})
+}
+
+// AddPresenceTracker attaches a tracker to a person
+func (h *Handlers) AddPresenceTracker(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	var tracker models.PresenceTracker
+	if err := c.ShouldBindJSON(&tracker); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	status, err := h.presenceService.AddTracker(c.Request.Context(), c.Param("id"), &tracker)
+	if err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, status)
+}
+
+// DeletePresenceTracker detaches a tracker from a person
+func (h *Handlers) DeletePresenceTracker(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	trackerID, err := strconv.Atoi(c.Param("tracker_id"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid tracker ID")
+		return
+	}
+
+	status, err := h.presenceService.RemoveTracker(c.Request.Context(), c.Param("id"), trackerID)
+	if err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+	utils.SendSuccess(c, status)
+}
+
+// PresenceCheckIn records a mobile app check-in for a person
+func (h *Handlers) PresenceCheckIn(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+	h.presenceCheckIn(c, c.Param("id"))
+}
+
+// PresenceCheckInSelf records a mobile app check-in for the person linked to the current user
+func (h *Handlers) PresenceCheckInSelf(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	person, err := h.presenceService.GetPersonForUser(notificationUserID(c))
+	if err != nil {
+		utils.SendError(c, http.StatusNotFound, "No person is linked to the current user")
+		return
+	}
+	h.presenceCheckIn(c, person.ID)
+}
+
+func (h *Handlers) presenceCheckIn(c *gin.Context, personID string) {
+	var checkIn presence.CheckIn
+	if err := c.ShouldBindJSON(&checkIn); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	status, err := h.presenceService.CheckIn(c.Request.Context(), personID, &checkIn)
+	if err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+	utils.SendSuccess(c, status)
+}
+
+// GetPresenceZones lists the configured zones
+func (h *Handlers) GetPresenceZones(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.presenceService.ListZones())
+}
+
+// SavePresenceZone creates or replaces a zone
+func (h *Handlers) SavePresenceZone(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	var zone models.PresenceZone
+	if err := c.ShouldBindJSON(&zone); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.presenceService.SaveZone(c.Request.Context(), &zone); err != nil {
+		sendPresenceError(c, err)
+		return
+	}
+	utils.SendSuccess(c, zone)
+}
+
+// DeletePresenceZone deletes a zone
+func (h *Handlers) DeletePresenceZone(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+
+	if err := h.presenceService.DeleteZone(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SendError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Zone deleted"})
+}
+
+// RefreshPresence evaluates every person immediately
+func (h *Handlers) RefreshPresence(c *gin.Context) {
+	if !h.requirePresenceService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.presenceService.Refresh(c.Request.Context()))
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/homeassistant"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
 	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
@@ -23,6 +24,37 @@ type RoomWithEntities struct {
 	SourceCounts map[string]int    `json:"source_counts,omitempty"`
 }
 
+// roomRequest is the body of a room create or update
+type roomRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Icon        string `json:"icon"`
+	Description string `json:"description"`
+	Source      string `json:"source"` // Which source to create the room in
+}
+
+// roomDeleteRequest is the optional body of a room delete
+type roomDeleteRequest struct {
+	ReassignToRoomID string `json:"reassign_to_room_id"`
+}
+
+// roomChangeResponse is the data of a room create, update or delete
+type roomChangeResponse struct {
+	Message string `json:"message"`
+	RoomID  string `json:"room_id"`
+	Name    string `json:"name,omitempty"`
+}
+
+// roomStatsResponse summarizes the rooms of all sources
+type roomStatsResponse struct {
+	TotalRooms             int            `json:"total_rooms"`
+	TotalEntities          int            `json:"total_entities"`
+	AverageEntitiesPerRoom float64        `json:"average_entities_per_room"`
+	LargestRoom            string         `json:"largest_room"`
+	MaxEntitiesInRoom      int            `json:"max_entities_in_room"`
+	RoomsBySource          map[string]int `json:"rooms_by_source"`
+	EntitiesBySource       map[string]int `json:"entities_by_source"`
+}
+
 // GetRooms retrieves all rooms using the unified PMA service
 func (h *Handlers) GetRooms(c *gin.Context) {
 	includeEntities := c.Query("include_entities") == "true"
@@ -139,12 +171,7 @@ func (h *Handlers) GetRoom(c *gin.Context) {
 
 // CreateRoom creates a new PMA room (note: may need to be routed through an adapter)
 func (h *Handlers) CreateRoom(c *gin.Context) {
-	var request struct {
-		Name        string `json:"name" binding:"required"`
-		Icon        string `json:"icon"`
-		Description string `json:"description"`
-		Source      string `json:"source"` // Which source to create the room in
-	}
+	var request roomRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
@@ -175,10 +202,10 @@ func (h *Handlers) CreateRoom(c *gin.Context) {
 		h.wsHub.BroadcastToAll(message.Type, message.Data)
 	}
 
-	utils.SendSuccess(c, gin.H{
-		"message": "Room created successfully",
-		"room_id": room.ID,
-		"name":    room.Name,
+	utils.SendSuccess(c, roomChangeResponse{
+		Message: "Room created successfully",
+		RoomID:  room.ID,
+		Name:    room.Name,
 	})
 }
 
@@ -186,11 +213,7 @@ func (h *Handlers) CreateRoom(c *gin.Context) {
 func (h *Handlers) UpdateRoom(c *gin.Context) {
 	roomID := c.Param("id")
 
-	var request struct {
-		Name        string `json:"name" binding:"required"`
-		Icon        string `json:"icon"`
-		Description string `json:"description"`
-	}
+	var request roomRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
@@ -229,9 +252,9 @@ func (h *Handlers) UpdateRoom(c *gin.Context) {
 		h.wsHub.BroadcastToAll(message.Type, message.Data)
 	}
 
-	utils.SendSuccess(c, gin.H{
-		"message": "Room updated successfully",
-		"room_id": roomID,
+	utils.SendSuccess(c, roomChangeResponse{
+		Message: "Room updated successfully",
+		RoomID:  roomID,
 	})
 }
 
@@ -239,9 +262,7 @@ func (h *Handlers) UpdateRoom(c *gin.Context) {
 func (h *Handlers) DeleteRoom(c *gin.Context) {
 	roomID := c.Param("id")
 
-	var request struct {
-		ReassignToRoomID string `json:"reassign_to_room_id"`
-	}
+	var request roomDeleteRequest
 
 	// This is optional, so we don't use binding:"required"
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -284,9 +305,9 @@ func (h *Handlers) DeleteRoom(c *gin.Context) {
 		h.wsHub.BroadcastToAll(message.Type, message.Data)
 	}
 
-	utils.SendSuccess(c, gin.H{
-		"message": "Room deleted successfully",
-		"room_id": roomID,
+	utils.SendSuccess(c, roomChangeResponse{
+		Message: "Room deleted successfully",
+		RoomID:  roomID,
 	})
 }
 
@@ -330,14 +351,14 @@ func (h *Handlers) GetRoomStats(c *gin.Context) {
 		averageEntitiesPerRoom = float64(totalEntities) / float64(totalRooms)
 	}
 
-	stats := gin.H{
-		"total_rooms":               totalRooms,
-		"total_entities":            totalEntities,
-		"average_entities_per_room": averageEntitiesPerRoom,
-		"largest_room":              largestRoom,
-		"max_entities_in_room":      maxEntities,
-		"rooms_by_source":           roomsBySource,
-		"entities_by_source":        entitiesBySource,
+	stats := roomStatsResponse{
+		TotalRooms:             totalRooms,
+		TotalEntities:          totalEntities,
+		AverageEntitiesPerRoom: averageEntitiesPerRoom,
+		LargestRoom:            largestRoom,
+		MaxEntitiesInRoom:      maxEntities,
+		RoomsBySource:          roomsBySource,
+		EntitiesBySource:       entitiesBySource,
 	}
 
 	utils.SendSuccess(c, stats)
@@ -414,6 +435,62 @@ func (h *Handlers) AssignEntityToRoom(c *gin.Context) {
 	})
 }
 
+// GetRoomReconciliation returns the most recent room reconciliation report, running one if none exists
+func (h *Handlers) GetRoomReconciliation(c *gin.Context) {
+	if h.roomReconciler == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Room reconciliation not available")
+		return
+	}
+
+	report := h.roomReconciler.LastReport()
+	if report == nil {
+		report = h.roomReconciler.Reconcile(c.Request.Context(), false)
+	}
+
+	utils.SendSuccess(c, report)
+}
+
+// ReconcileRooms checks rooms against entities and areas, and repairs the drift when fix is set
+func (h *Handlers) ReconcileRooms(c *gin.Context) {
+	if h.roomReconciler == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Room reconciliation not available")
+		return
+	}
+
+	var request struct {
+		Fix bool `json:"fix"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	utils.SendSuccess(c, h.roomReconciler.Reconcile(ctx, request.Fix))
+}
+
+// homeAssistantAreaSource lists the current Home Assistant areas for room reconciliation
+type homeAssistantAreaSource struct {
+	client *homeassistant.HAClientWrapper
+}
+
+func (s *homeAssistantAreaSource) GetAreaIDs(ctx context.Context) ([]string, error) {
+	areas, err := s.client.GetAllAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(areas))
+	for _, area := range areas {
+		ids = append(ids, area.ID)
+	}
+	return ids, nil
+}
+
 // Helper methods (these would need full implementation)
 
 func (h *Handlers) getAllRoomsFromSources(ctx context.Context, source string) ([]*types.PMARoom, error) {
@@ -13,6 +13,19 @@ import (
 	"github.com/google/uuid"
 )
 
+// scenesResponse is the data of the scene list
+type scenesResponse struct {
+	Scenes []types.PMAEntity `json:"scenes"`
+	Count  int               `json:"count"`
+}
+
+// sceneActivationResponse is the data of a scene activation
+type sceneActivationResponse struct {
+	Message string                  `json:"message"`
+	SceneID string                  `json:"scene_id"`
+	Result  *types.PMAControlResult `json:"result"`
+}
+
 // GetScenes returns all available scenes using the unified PMA service
 func (h *Handlers) GetScenes(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
@@ -37,9 +50,9 @@ func (h *Handlers) GetScenes(c *gin.Context) {
 		scenes[i] = swr.Entity
 	}
 
-	utils.SendSuccess(c, gin.H{
-		"scenes": scenes,
-		"count":  len(scenes),
+	utils.SendSuccess(c, scenesResponse{
+		Scenes: scenes,
+		Count:  len(scenes),
 	})
 }
 
@@ -113,9 +126,9 @@ func (h *Handlers) ActivateScene(c *gin.Context) {
 		go h.wsHub.BroadcastToAll("scene_activated", data)
 	}
 
-	utils.SendSuccess(c, gin.H{
-		"message":  "Scene activated successfully",
-		"scene_id": sceneID,
-		"result":   result,
+	utils.SendSuccess(c, sceneActivationResponse{
+		Message: "Scene activated successfully",
+		SceneID: sceneID,
+		Result:  result,
 	})
 }
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/shelly_firmware"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// shellyFirmwareCheckRequest selects the devices to check; empty checks every online device
+type shellyFirmwareCheckRequest struct {
+	DeviceIDs []string `json:"device_ids"`
+}
+
+// requireShellyFirmwareService reports whether firmware management is available
+func (h *Handlers) requireShellyFirmwareService(c *gin.Context) bool {
+	if h.shellyFirmwareService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Shelly firmware management not available")
+		return false
+	}
+	return true
+}
+
+// GetShellyFirmware returns the last firmware check result of every device
+func (h *Handlers) GetShellyFirmware(c *gin.Context) {
+	if !h.requireShellyFirmwareService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.shellyFirmwareService.ListFirmware())
+}
+
+// CheckShellyFirmware asks devices for available firmware updates
+func (h *Handlers) CheckShellyFirmware(c *gin.Context) {
+	if !h.requireShellyFirmwareService(c) {
+		return
+	}
+
+	var req shellyFirmwareCheckRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	utils.SendSuccess(c, h.shellyFirmwareService.CheckUpdates(c.Request.Context(), req.DeviceIDs))
+}
+
+// GetShellyFirmwareRollouts lists firmware rollouts, newest first
+func (h *Handlers) GetShellyFirmwareRollouts(c *gin.Context) {
+	if !h.requireShellyFirmwareService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.shellyFirmwareService.ListRollouts())
+}
+
+// StartShellyFirmwareRollout stages a rolling firmware update
+func (h *Handlers) StartShellyFirmwareRollout(c *gin.Context) {
+	if !h.requireShellyFirmwareService(c) {
+		return
+	}
+
+	var req shelly_firmware.RolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rollout, err := h.shellyFirmwareService.StartRollout(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, shelly_firmware.ErrNoDevices) {
+			utils.SendError(c, http.StatusConflict, err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.SendSuccess(c, rollout)
+}
+
+// GetShellyFirmwareRollout returns the progress of a rollout
+func (h *Handlers) GetShellyFirmwareRollout(c *gin.Context) {
+	if !h.requireShellyFirmwareService(c) {
+		return
+	}
+
+	rollout, err := h.shellyFirmwareService.GetRollout(c.Param("id"))
+	if err != nil {
+		utils.SendError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.SendSuccess(c, rollout)
+}
+
+// CancelShellyFirmwareRollout stops a rollout before its remaining devices are updated
+func (h *Handlers) CancelShellyFirmwareRollout(c *gin.Context) {
+	if !h.requireShellyFirmwareService(c) {
+		return
+	}
+
+	rollout, err := h.shellyFirmwareService.CancelRollout(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, shelly_firmware.ErrRolloutFinished) {
+			utils.SendError(c, http.StatusConflict, err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.SendSuccess(c, rollout)
+}
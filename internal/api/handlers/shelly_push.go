@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/shelly"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// shellyPushUpgrader accepts outbound WebSocket connections from Gen2+ devices, which send no Origin
+var shellyPushUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// shellyAdapter returns the registered Shelly adapter, or nil when it is not available
+func (h *Handlers) shellyAdapter() *shelly.ShellyAdapter {
+	if h.adapterRegistry == nil {
+		return nil
+	}
+	adapter, err := h.adapterRegistry.GetAdapterBySource(types.SourceShelly)
+	if err != nil {
+		return nil
+	}
+	shellyAdapter, _ := adapter.(*shelly.ShellyAdapter)
+	return shellyAdapter
+}
+
+// ShellyPushWebSocket receives NotifyStatus and NotifyEvent frames from a Gen2+ device's
+// outbound WebSocket
+func (h *Handlers) ShellyPushWebSocket(c *gin.Context) {
+	adapter := h.shellyAdapter()
+	if adapter == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Shelly adapter not available")
+		return
+	}
+
+	remoteIP, authorized := adapter.AuthorizePush(c.Request)
+	if !authorized {
+		utils.SendError(c, http.StatusUnauthorized, "Push not authorized")
+		return
+	}
+
+	conn, err := shellyPushUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.log.WithError(err).Warn("Failed to upgrade Shelly push connection")
+		return
+	}
+
+	adapter.ServePushConnection(c.Request.Context(), conn, remoteIP)
+}
+
+// ShellyActionWebhook receives Gen1 action URL calls such as button presses and output changes
+func (h *Handlers) ShellyActionWebhook(c *gin.Context) {
+	adapter := h.shellyAdapter()
+	if adapter == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Shelly adapter not available")
+		return
+	}
+
+	remoteIP, authorized := adapter.AuthorizePush(c.Request)
+	if !authorized {
+		utils.SendError(c, http.StatusUnauthorized, "Push not authorized")
+		return
+	}
+
+	if err := adapter.HandleAction(c.Request.Context(), c.Param("device_id"), c.Param("action"), remoteIP); err != nil {
+		utils.SendError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Action received"})
+}
@@ -222,3 +222,26 @@ func (h *Handlers) GetUPSHealth(c *gin.Context) {
 
 	utils.SendSuccess(c, health)
 }
+
+// GetUPSPowerState returns the power failure handling state
+func (h *Handlers) GetUPSPowerState(c *gin.Context) {
+	if h.upsPowerManager == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "UPS power failure handling not enabled")
+		return
+	}
+	utils.SendSuccess(c, h.upsPowerManager.State())
+}
+
+// CancelUPSShutdown stops a running UPS shutdown countdown
+func (h *Handlers) CancelUPSShutdown(c *gin.Context) {
+	if h.upsPowerManager == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "UPS power failure handling not enabled")
+		return
+	}
+
+	if !h.upsPowerManager.CancelShutdown() {
+		utils.SendError(c, http.StatusConflict, "No shutdown countdown is running")
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Shutdown cancelled"})
+}
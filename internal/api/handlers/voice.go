@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// voiceSynthesizeRequest is text to speak
+type voiceSynthesizeRequest struct {
+	Text     string `json:"text" binding:"required"`
+	Language string `json:"language,omitempty"` // Picks the configured voice, the default language when empty
+}
+
+// requireVoiceService reports whether the voice pipeline is available
+func (h *Handlers) requireVoiceService(c *gin.Context) bool {
+	if h.voiceService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Voice pipeline not enabled")
+		return false
+	}
+	return true
+}
+
+// GetVoiceStatus reports the voice services and whether they answer
+func (h *Handlers) GetVoiceStatus(c *gin.Context) {
+	if !h.requireVoiceService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.voiceService.Status(c.Request.Context()))
+}
+
+// SynthesizeSpeech speaks text with the text-to-speech service and returns a WAV file
+func (h *Handlers) SynthesizeSpeech(c *gin.Context) {
+	if !h.requireVoiceService(c) {
+		return
+	}
+
+	var req voiceSynthesizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	audio, err := h.voiceService.Speak(c.Request.Context(), req.Text, req.Language)
+	if err != nil {
+		h.log.WithError(err).Error("Speech synthesis failed")
+		utils.SendError(c, http.StatusBadGateway, "Speech synthesis failed")
+		return
+	}
+	c.Data(http.StatusOK, "audio/wav", audio.WAV())
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// requireWatchdogService reports whether the device health watchdog is available
+func (h *Handlers) requireWatchdogService(c *gin.Context) bool {
+	if h.watchdogService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Device health watchdog not enabled")
+		return false
+	}
+	return true
+}
+
+// GetWatchdogAttention returns the stale, unavailable and low battery entities grouped by
+// device and room
+func (h *Handlers) GetWatchdogAttention(c *gin.Context) {
+	if !h.requireWatchdogService(c) {
+		return
+	}
+	utils.SendSuccess(c, h.watchdogService.Attention())
+}
+
+// GetWatchdogEntity returns the learned update interval and issues of one entity
+func (h *Handlers) GetWatchdogEntity(c *gin.Context) {
+	if !h.requireWatchdogService(c) {
+		return
+	}
+
+	health, ok := h.watchdogService.EntityHealth(c.Param("id"))
+	if !ok {
+		utils.SendError(c, http.StatusNotFound, "Entity not watched")
+		return
+	}
+	utils.SendSuccess(c, health)
+}
+
+// RunWatchdogCheck checks every entity immediately
+func (h *Handlers) RunWatchdogCheck(c *gin.Context) {
+	if !h.requireWatchdogService(c) {
+		return
+	}
+
+	attention, err := h.watchdogService.Check(c.Request.Context())
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SendSuccess(c, attention)
+}
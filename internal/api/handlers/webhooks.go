@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/webhooks"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// webhookRequest is the body of webhook create and update requests. An empty secret keeps the
+// current one on update.
+type webhookRequest struct {
+	Name     string            `json:"name"`
+	URL      string            `json:"url"`
+	Secret   string            `json:"secret,omitempty"`
+	Events   []string          `json:"events,omitempty"`
+	Entities []string          `json:"entities,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Enabled  *bool             `json:"enabled,omitempty"`
+}
+
+// webhookResponse is a webhook without its secret
+type webhookResponse struct {
+	*models.Webhook
+	HasSecret bool `json:"has_secret"`
+}
+
+// webhookDeliveriesResponse is a page of the delivery log of a webhook
+type webhookDeliveriesResponse struct {
+	Deliveries []*models.WebhookDelivery `json:"deliveries"`
+	Total      int                       `json:"total"`
+	Limit      int                       `json:"limit"`
+	Offset     int                       `json:"offset"`
+}
+
+func newWebhookResponse(webhook *models.Webhook) *webhookResponse {
+	return &webhookResponse{Webhook: webhook, HasSecret: webhook.HasSecret()}
+}
+
+func (r *webhookRequest) webhook() *models.Webhook {
+	webhook := &models.Webhook{
+		Name:     r.Name,
+		URL:      r.URL,
+		Secret:   r.Secret,
+		Events:   r.Events,
+		Entities: r.Entities,
+		Headers:  r.Headers,
+		Enabled:  true,
+	}
+	if r.Enabled != nil {
+		webhook.Enabled = *r.Enabled
+	}
+	return webhook
+}
+
+// requireWebhookService reports whether outbound webhooks are available
+func (h *Handlers) requireWebhookService(c *gin.Context) bool {
+	if h.webhookService == nil {
+		utils.SendError(c, http.StatusServiceUnavailable, "Webhooks not available")
+		return false
+	}
+	return true
+}
+
+// webhookID parses the webhook ID path parameter
+func webhookID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid webhook ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// sendWebhookError maps webhook errors to HTTP responses
+func sendWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhooks.ErrWebhookNotFound):
+		utils.SendError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, webhooks.ErrInvalidWebhook):
+		utils.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetWebhookEventTypes lists the event types webhooks can subscribe to
+func (h *Handlers) GetWebhookEventTypes(c *gin.Context) {
+	utils.SendSuccess(c, webhooks.EventTypes)
+}
+
+// GetWebhooks lists all webhooks
+func (h *Handlers) GetWebhooks(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+
+	list := h.webhookService.List()
+	response := make([]*webhookResponse, 0, len(list))
+	for _, webhook := range list {
+		response = append(response, newWebhookResponse(webhook))
+	}
+	utils.SendSuccess(c, response)
+}
+
+// GetWebhook returns one webhook
+func (h *Handlers) GetWebhook(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.Get(id)
+	if err != nil {
+		sendWebhookError(c, err)
+		return
+	}
+	utils.SendSuccess(c, newWebhookResponse(webhook))
+}
+
+// CreateWebhook subscribes a new endpoint to events
+func (h *Handlers) CreateWebhook(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook := req.webhook()
+	if err := h.webhookService.Create(c.Request.Context(), webhook); err != nil {
+		sendWebhookError(c, err)
+		return
+	}
+	utils.SendSuccess(c, newWebhookResponse(webhook))
+}
+
+// UpdateWebhook replaces the settings of a webhook; enabling it clears its failures
+func (h *Handlers) UpdateWebhook(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.webhookService.Update(c.Request.Context(), id, req.webhook())
+	if err != nil {
+		sendWebhookError(c, err)
+		return
+	}
+	utils.SendSuccess(c, newWebhookResponse(webhook))
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func (h *Handlers) DeleteWebhook(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), id); err != nil {
+		sendWebhookError(c, err)
+		return
+	}
+	utils.SendSuccess(c, gin.H{"message": "Webhook deleted"})
+}
+
+// GetWebhookDeliveries returns the delivery log of a webhook, newest first
+func (h *Handlers) GetWebhookDeliveries(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	deliveries, total, err := h.webhookService.Deliveries(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		sendWebhookError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, &webhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+// TestWebhook delivers a test event to a webhook once and returns the attempt
+func (h *Handlers) TestWebhook(c *gin.Context) {
+	if !h.requireWebhookService(c) {
+		return
+	}
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Test(c.Request.Context(), id)
+	if err != nil {
+		sendWebhookError(c, err)
+		return
+	}
+	utils.SendSuccess(c, delivery)
+}
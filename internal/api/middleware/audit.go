@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/audit"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// auditCategories maps the first path segment of audited routes to their category
+var auditCategories = map[string]string{
+	"automation":       audit.CategoryAutomation,
+	"scenes":           audit.CategoryScene,
+	"areas":            audit.CategoryArea,
+	"rooms":            audit.CategoryArea,
+	"users":            audit.CategoryUser,
+	"profile":          audit.CategoryUser,
+	"config":           audit.CategoryConfig,
+	"settings":         audit.CategoryConfig,
+	"display-settings": audit.CategoryConfig,
+	"webhooks":         audit.CategoryConfig,
+	"auth":             audit.CategoryAuth,
+	"kiosk":            audit.CategoryKiosk,
+}
+
+// auditBodyFields are request body fields copied into the details of auth and kiosk entries.
+// Secrets such as passwords and PINs are never read.
+var auditBodyFields = []string{"username", "name", "room_id"}
+
+// maxAuditBodyPeek bounds how much of a request body is inspected
+const maxAuditBodyPeek = 64 << 10
+
+// AuditMiddleware attributes the request context to the authenticated actor, so entity actions
+// run by the handler are recorded against it, and records mutations of automations, scenes,
+// areas, users, configuration, logins and kiosk pairing. Apply it after authentication.
+func AuditMiddleware(recorder *audit.Service, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if recorder == nil {
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), requestActor(c)))
+
+		category := auditCategory(c.Request.Method, c.Request.URL.Path)
+		if category == "" {
+			c.Next()
+			return
+		}
+
+		var fields map[string]string
+		if category == audit.CategoryAuth || category == audit.CategoryKiosk {
+			fields = peekBodyFields(c)
+		}
+
+		c.Next()
+
+		// Logins authenticate during the handler, re-read the actor
+		actor := requestActor(c)
+		if actor.Type == audit.ActorAnonymous && fields["username"] != "" {
+			actor = audit.Actor{Type: audit.ActorUser, Name: fields["username"], SourceIP: actor.SourceIP}
+		}
+
+		status := c.Writer.Status()
+		details := map[string]interface{}{"status": status}
+		for key, value := range fields {
+			details[key] = value
+		}
+		data, _ := json.Marshal(details)
+
+		entry := &models.AuditEntry{
+			ActorType: actor.Type,
+			ActorID:   actor.ID,
+			ActorName: actor.Name,
+			SourceIP:  actor.SourceIP,
+			Category:  category,
+			Action:    c.Request.Method + " " + c.FullPath(),
+			Target:    c.Request.URL.Path,
+			Outcome:   audit.OutcomeSuccess,
+			Details:   data,
+		}
+		if status >= http.StatusBadRequest {
+			entry.Outcome = audit.OutcomeFailure
+			entry.Error = http.StatusText(status)
+			if len(c.Errors) > 0 {
+				entry.Error = c.Errors.String()
+			}
+		}
+
+		if err := recorder.Record(c.Request.Context(), entry); err != nil {
+			logger.WithError(err).WithField("path", entry.Target).Error("Failed to record audit entry")
+		}
+	}
+}
+
+// requestActor derives the actor from what the authentication middlewares stored
+func requestActor(c *gin.Context) audit.Actor {
+	actor := audit.Actor{Type: audit.ActorAnonymous, SourceIP: c.ClientIP()}
+
+	if value, ok := c.Get("kiosk_token"); ok {
+		if token, ok := value.(*models.KioskToken); ok {
+			actor.Type = audit.ActorKiosk
+			actor.ID = token.ID
+			actor.Name = token.Name
+			return actor
+		}
+	}
+
+	if c.GetString("auth_type") == "api_secret" {
+		actor.Type = audit.ActorAPIToken
+		actor.Name = c.GetString("username")
+		return actor
+	}
+
+	if userID, ok := c.Get("user_id"); ok {
+		actor.Type = audit.ActorUser
+		actor.ID = fmt.Sprint(userID)
+		actor.Name = c.GetString("username")
+	}
+	return actor
+}
+
+// auditCategory returns the category of an audited request, or "" for requests that are not
+// recorded. Only mutations are recorded; token validation is not a change.
+func auditCategory(method, path string) string {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return ""
+	}
+
+	// Strip the API prefixes, including the legacy /api/v1/api/... routes
+	for {
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "/api"), "/v1")
+		if trimmed == path {
+			break
+		}
+		path = trimmed
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	category := auditCategories[segments[0]]
+	if category == audit.CategoryAuth && segments[len(segments)-1] == "validate" {
+		return ""
+	}
+	return category
+}
+
+// peekBodyFields reads the whitelisted fields of a JSON body and restores the body for the handler
+func peekBodyFields(c *gin.Context) map[string]string {
+	if c.Request.Body == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBodyPeek))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+	if err != nil {
+		return nil
+	}
+
+	var body map[string]interface{}
+	if json.Unmarshal(data, &body) != nil {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	for _, key := range auditBodyFields {
+		if value, ok := body[key].(string); ok && value != "" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
 	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -13,7 +15,8 @@ import (
 type RateLimiter struct {
 	visitors map[string]*Visitor
 	mu       sync.RWMutex
-	rate     int
+	enabled  bool
+	rate     float64 // tokens per second
 	burst    int
 	cleanup  time.Duration
 }
@@ -24,18 +27,19 @@ type Visitor struct {
 }
 
 type TokenBucket struct {
-	tokens     int
+	tokens     float64
 	capacity   int
-	refillRate int
+	refillRate float64 // tokens per second
 	lastRefill time.Time
 	mu         sync.Mutex
 }
 
-// NewRateLimiter creates a new rate limiter
+// NewRateLimiter creates a new rate limiter allowing rate requests per second
 func NewRateLimiter(rate, burst int) *RateLimiter {
 	rl := &RateLimiter{
 		visitors: make(map[string]*Visitor),
-		rate:     rate,
+		enabled:  true,
+		rate:     float64(rate),
 		burst:    burst,
 		cleanup:  time.Minute * 5,
 	}
@@ -46,9 +50,38 @@ func NewRateLimiter(rate, burst int) *RateLimiter {
 	return rl
 }
 
+// NewRateLimiterFromConfig creates a rate limiter from the security configuration
+func NewRateLimiterFromConfig(cfg config.SecurityRateLimitConfig) *RateLimiter {
+	rl := NewRateLimiter(0, 0)
+	rl.Configure(cfg)
+	return rl
+}
+
+// Configure applies rate limiting settings in place; clients start over with the new limits
+func (rl *RateLimiter) Configure(cfg config.SecurityRateLimitConfig) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.enabled = cfg.Enabled && cfg.RequestsPerMinute > 0
+	rl.rate = float64(cfg.RequestsPerMinute) / 60
+	rl.burst = cfg.BurstSize
+	if rl.burst < 1 {
+		rl.burst = 1
+	}
+	rl.visitors = make(map[string]*Visitor)
+}
+
 // RateLimitMiddleware returns a rate limiting middleware
 func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		rl.mu.RLock()
+		enabled := rl.enabled
+		rl.mu.RUnlock()
+		if !enabled {
+			c.Next()
+			return
+		}
+
 		ip := c.ClientIP()
 
 		if !rl.allow(ip) {
@@ -69,7 +102,7 @@ func (rl *RateLimiter) allow(key string) bool {
 	if !exists {
 		visitor = &Visitor{
 			limiter: &TokenBucket{
-				tokens:     rl.burst,
+				tokens:     float64(rl.burst),
 				capacity:   rl.burst,
 				refillRate: rl.rate,
 				lastRefill: time.Now(),
@@ -90,12 +123,11 @@ func (tb *TokenBucket) consume() bool {
 	now := time.Now()
 	elapsed := now.Sub(tb.lastRefill)
 
-	// Refill tokens
-	tokensToAdd := int(elapsed.Seconds()) * tb.refillRate
-	tb.tokens = minInt(tb.capacity, tb.tokens+tokensToAdd)
+	// Refill tokens, including fractions so frequent requests still refill the bucket
+	tb.tokens = math.Min(float64(tb.capacity), tb.tokens+elapsed.Seconds()*tb.refillRate)
 	tb.lastRefill = now
 
-	if tb.tokens > 0 {
+	if tb.tokens >= 1 {
 		tb.tokens--
 		return true
 	}
@@ -117,10 +149,3 @@ func (rl *RateLimiter) cleanupVisitors() {
 		rl.mu.Unlock()
 	}
 }
-
-func minInt(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
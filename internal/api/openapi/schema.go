@@ -0,0 +1,241 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// generator derives schemas from Go types following the encoding/json rules
+type generator struct {
+	// components collects named struct types; nil inlines every type
+	components map[string]*Schema
+	names      map[reflect.Type]string
+
+	// request schemas require the fields tagged binding:"required", response schemas every
+	// field without omitempty
+	request bool
+
+	// implementations maps interface types to the concrete types their values are encoded from
+	implementations map[reflect.Type][]reflect.Type
+
+	// visiting guards against recursive types while inlining
+	visiting map[reflect.Type]bool
+}
+
+func newResponseGenerator(implementations map[reflect.Type][]reflect.Type) *generator {
+	return &generator{
+		components:      make(map[string]*Schema),
+		names:           make(map[reflect.Type]string),
+		implementations: implementations,
+		visiting:        make(map[reflect.Type]bool),
+	}
+}
+
+func newRequestGenerator(implementations map[reflect.Type][]reflect.Type) *generator {
+	return &generator{request: true, implementations: implementations, visiting: make(map[reflect.Type]bool)}
+}
+
+// schemaOf returns the schema of the type of v, or nil for a nil v
+func (g *generator) schemaOf(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return nullable(g.schemaFor(t.Elem()))
+	}
+
+	// An interface with known implementations is any one of them, or null
+	if concrete := g.implementations[t]; t.Kind() == reflect.Interface && len(concrete) > 0 {
+		schema := &Schema{Nullable: true}
+		for _, c := range concrete {
+			if c.Kind() == reflect.Ptr {
+				c = c.Elem()
+			}
+			schema.AnyOf = append(schema.AnyOf, g.schemaFor(c))
+		}
+		return schema
+	}
+
+	// Custom encodings have no shape that reflection can see
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// Nil slices encode as null
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		// Interfaces and anything else accept any value
+		return &Schema{}
+	}
+}
+
+// structRef returns a reference to the component of a named struct, or the inline schema of an
+// anonymous one
+func (g *generator) structRef(t reflect.Type) *Schema {
+	if g.components == nil || t.Name() == "" {
+		if g.visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+		return g.structSchema(t)
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = componentName(t)
+		for suffix := 2; g.components[name] != nil; suffix++ {
+			name = componentName(t) + "_" + strconv.Itoa(suffix)
+		}
+		g.names[t] = name
+		// Register before generating the fields so recursive types refer to themselves
+		g.components[name] = &Schema{}
+		*g.components[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName is the package name and type name, e.g. models.AuditEntry
+func componentName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+type field struct {
+	schema   *Schema
+	required bool
+	depth    int
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	fields := make(map[string]field)
+	g.collectFields(t, 0, fields)
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema, len(fields))}
+	for name, f := range fields {
+		schema.Properties[name] = f.schema
+		if f.required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// collectFields adds the encoded fields of t; fields of embedded structs are promoted unless a
+// shallower field has the same name
+func (g *generator) collectFields(t reflect.Type, depth int, fields map[string]field) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.collectFields(embedded, depth+1, fields)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if existing, ok := fields[name]; ok && existing.depth <= depth {
+			continue
+		}
+
+		schema := g.schemaFor(f.Type)
+		if hasOption(options, "string") {
+			schema = &Schema{Type: "string"}
+		}
+
+		required := !hasOption(options, "omitempty")
+		if g.request {
+			required = hasOption(f.Tag.Get("binding"), "required")
+		}
+		fields[name] = field{schema: schema, required: required, depth: depth}
+	}
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable allows null in addition to the schema
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	if schema.Type == "" && schema.AllOf == nil {
+		return schema
+	}
+	copied := *schema
+	copied.Nullable = true
+	return &copied
+}
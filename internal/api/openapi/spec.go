@@ -0,0 +1,352 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/frostdev-ops/pma-backend-go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Route describes the typed request and response of one registered route
+type Route struct {
+	Method  string
+	Path    string // as registered with gin, e.g. /api/v1/helpers/:id
+	Summary string
+	Query   []Parameter
+
+	// Request is a value of the JSON request body type; nil for routes without a body
+	Request interface{}
+
+	// Response is a value of the type sent as data of the success envelope
+	Response interface{}
+
+	// ContentType is set for success responses that are not JSON, such as exports
+	ContentType string
+
+	// Status is the success status code when it is not 200, e.g. 201 for creates
+	Status int
+}
+
+// Implementations lists the concrete types that values of an interface type are encoded from,
+// so that fields of the interface type are documented as any one of them
+type Implementations struct {
+	Interface interface{}   // a nil pointer to the interface, e.g. (*types.PMAEntity)(nil)
+	Types     []interface{} // values of the concrete types
+}
+
+// Spec generates the document of the routes under a prefix and validates against it
+type Spec struct {
+	info   Info
+	prefix string
+
+	routes   map[string]*Route
+	requests map[string]*Schema // request body schemas, inlined
+	data     map[string]*Schema // success data schemas, referring to components
+
+	components  map[string]*Schema
+	errorSchema *Schema
+
+	once    sync.Once
+	encoded []byte
+}
+
+// NewSpec generates the schemas of the described routes. Routes under the prefix that are not
+// described are documented without schemas; interfaces without implementations accept any value.
+func NewSpec(info Info, prefix string, routes []Route, implementations ...Implementations) *Spec {
+	concrete := make(map[reflect.Type][]reflect.Type, len(implementations))
+	for _, impl := range implementations {
+		iface := reflect.TypeOf(impl.Interface).Elem()
+		for _, t := range impl.Types {
+			concrete[iface] = append(concrete[iface], reflect.TypeOf(t))
+		}
+	}
+	responses := newResponseGenerator(concrete)
+	requests := newRequestGenerator(concrete)
+
+	s := &Spec{
+		info:        info,
+		prefix:      prefix,
+		routes:      make(map[string]*Route, len(routes)),
+		requests:    make(map[string]*Schema),
+		data:        make(map[string]*Schema),
+		components:  responses.components,
+		errorSchema: responses.schemaOf(utils.ErrorResponse{}),
+	}
+
+	for i := range routes {
+		route := routes[i]
+		key := routeKey(route.Method, route.Path)
+		s.routes[key] = &route
+		if route.Request != nil {
+			s.requests[key] = requests.schemaOf(route.Request)
+		}
+		if route.ContentType == "" {
+			s.data[key] = responses.schemaOf(route.Response)
+		}
+	}
+	return s
+}
+
+// Routes returns the described routes sorted by path and method
+func (s *Spec) Routes() []Route {
+	routes := make([]Route, 0, len(s.routes))
+	for _, route := range s.routes {
+		routes = append(routes, *route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Unregistered returns the described routes that are not registered with the router
+func (s *Spec) Unregistered(registered gin.RoutesInfo) []string {
+	found := make(map[string]bool, len(registered))
+	for _, route := range registered {
+		found[routeKey(route.Method, route.Path)] = true
+	}
+
+	var missing []string
+	for key := range s.routes {
+		if !found[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Document builds the OpenAPI document of the registered routes under the prefix
+func (s *Spec) Document(registered gin.RoutesInfo) *Document {
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       s.info,
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: s.components},
+	}
+
+	routes := append(gin.RoutesInfo(nil), registered...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	operationIDs := make(map[string]int)
+	for _, registeredRoute := range routes {
+		if !strings.HasPrefix(registeredRoute.Path, s.prefix) {
+			continue
+		}
+		openAPIPath, parameters := convertPath(registeredRoute.Path)
+
+		operationID := handlerName(registeredRoute.Handler)
+		if operationID == "" {
+			operationID = strings.ToLower(registeredRoute.Method) + nonWord.ReplaceAllString(openAPIPath, "_")
+		}
+		operationIDs[operationID]++
+		if n := operationIDs[operationID]; n > 1 {
+			operationID += strconv.Itoa(n)
+		}
+
+		op := &Operation{
+			OperationID: operationID,
+			Tags:        []string{pathTag(strings.TrimPrefix(registeredRoute.Path, s.prefix))},
+			Parameters:  parameters,
+			Responses: map[string]*Response{
+				"default": {Description: "Error", Content: jsonContent(s.errorSchema)},
+			},
+		}
+
+		key := routeKey(registeredRoute.Method, registeredRoute.Path)
+		if route, ok := s.routes[key]; ok {
+			op.Typed = true
+			op.Summary = route.Summary
+			op.Parameters = append(op.Parameters, route.Query...)
+			if request := s.requests[key]; request != nil {
+				op.RequestBody = &RequestBody{Required: true, Content: jsonContent(request)}
+			}
+			status := "200"
+			if route.Status != 0 {
+				status = strconv.Itoa(route.Status)
+			}
+			if route.ContentType != "" {
+				op.Responses[status] = &Response{
+					Description: "Success",
+					Content:     map[string]MediaType{route.ContentType: {Schema: &Schema{Type: "string"}}},
+				}
+			} else {
+				op.Responses[status] = &Response{Description: "Success", Content: jsonContent(envelope(s.data[key]))}
+			}
+		} else {
+			op.Responses["200"] = &Response{Description: "Success", Content: jsonContent(&Schema{})}
+		}
+
+		item, ok := doc.Paths[openAPIPath]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[openAPIPath] = item
+		}
+		item[strings.ToLower(registeredRoute.Method)] = op
+	}
+	return doc
+}
+
+// Handler serves the document. The routes are read on the first request, when registration
+// is complete.
+func (s *Spec) Handler(routes func() gin.RoutesInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.once.Do(func() {
+			encoded, err := json.Marshal(s.Document(routes()))
+			if err != nil {
+				encoded, _ = json.Marshal(utils.Response{Success: false, Error: err.Error()})
+			}
+			s.encoded = encoded
+		})
+		c.Data(http.StatusOK, "application/json", s.encoded)
+	}
+}
+
+// ValidationMiddleware rejects JSON request bodies of described routes that do not match
+// their schema
+func (s *Spec) ValidationMiddleware() gin.HandlerFunc {
+	v := &validator{}
+	return func(c *gin.Context) {
+		schema := s.requests[routeKey(c.Request.Method, c.FullPath())]
+		if schema == nil || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if contentType := c.ContentType(); contentType != "" && contentType != gin.MIMEJSON {
+			c.Next()
+			return
+		}
+
+		data, err := io.ReadAll(c.Request.Body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(data))
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Failed to read request body")
+			c.Abort()
+			return
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			// Handlers report missing bodies themselves; some bodies are optional
+			c.Next()
+			return
+		}
+
+		var body interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Request body must be valid JSON")
+			c.Abort()
+			return
+		}
+		if err := v.validate(schema, body, "$"); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidateResponse checks a JSON response of a described route against the spec. Success
+// responses must match the envelope and data schema exactly, without undeclared properties;
+// other responses must be error responses. Routes that are not described are not checked.
+func (s *Spec) ValidateResponse(method, path string, status int, body []byte) error {
+	key := routeKey(method, path)
+	route, ok := s.routes[key]
+	if !ok || (route.ContentType != "" && status < http.StatusBadRequest) {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s: response is not JSON: %w", key, err)
+	}
+
+	schema := s.errorSchema
+	if status >= 200 && status < 300 {
+		schema = envelope(s.data[key])
+	}
+
+	v := &validator{components: s.components, strict: true}
+	if err := v.validate(schema, value, "$"); err != nil {
+		return fmt.Errorf("%s (%d): %w", key, status, err)
+	}
+	return nil
+}
+
+// envelope wraps a data schema in the utils.Response envelope
+func envelope(data *Schema) *Schema {
+	if data == nil {
+		data = &Schema{}
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success":   {Type: "boolean"},
+			"data":      data,
+			"error":     {Type: "string"},
+			"timestamp": {Type: "string", Format: "date-time"},
+			"meta":      {},
+		},
+		Required: []string{"success", "timestamp"},
+	}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{gin.MIMEJSON: {Schema: schema}}
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+var (
+	pathParam   = regexp.MustCompile(`[:*]([^/]+)`)
+	nonWord     = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	handlerFunc = regexp.MustCompile(`\.([A-Za-z0-9_]+)(-fm)?$`)
+)
+
+// convertPath turns gin parameters into OpenAPI ones: /entities/:id becomes /entities/{id}
+func convertPath(ginPath string) (string, []Parameter) {
+	var parameters []Parameter
+	converted := pathParam.ReplaceAllStringFunc(ginPath, func(segment string) string {
+		name := segment[1:]
+		parameters = append(parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		return "{" + name + "}"
+	})
+	return converted, parameters
+}
+
+// handlerName is the method name of a handler, or "" for closures
+func handlerName(handler string) string {
+	match := handlerFunc.FindStringSubmatch(handler)
+	if match == nil || strings.HasPrefix(match[1], "func") {
+		return ""
+	}
+	return match[1]
+}
+
+// pathTag is the first segment of a path below the prefix
+func pathTag(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if segment == "" {
+		return "root"
+	}
+	return segment
+}
@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError is a value that does not match its schema
+type ValidationError struct {
+	Path    string // JSON path of the value, e.g. $.data[0].name
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// validator checks decoded JSON values against schemas
+type validator struct {
+	components map[string]*Schema
+
+	// strict rejects properties an object schema does not declare. Responses are validated
+	// strictly so that fields added outside the spec are caught; requests are not, as handlers
+	// ignore unknown fields.
+	strict bool
+}
+
+func (v *validator) validate(schema *Schema, value interface{}, at string) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		resolved, ok := v.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return &ValidationError{Path: at, Message: "unknown schema " + schema.Ref}
+		}
+		return v.validate(resolved, value, at)
+	}
+
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && schema.AllOf == nil) {
+			return nil
+		}
+		return &ValidationError{Path: at, Message: "must not be null"}
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := v.validate(sub, value, at); err != nil {
+			return err
+		}
+	}
+	if len(schema.AnyOf) > 0 {
+		if err := v.validateAnyOf(schema.AnyOf, value, at); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		return &ValidationError{Path: at, Message: fmt.Sprintf("must be one of %v", schema.Enum)}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return typeError(at, "an object", value)
+		}
+		return v.validateObject(schema, object, at)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return typeError(at, "an array", value)
+		}
+		for i, item := range items {
+			if err := v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return typeError(at, "a string", value)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return &ValidationError{Path: at, Message: "must be an RFC 3339 date-time"}
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok {
+			return typeError(at, "an integer", value)
+		}
+		if n != math.Trunc(n) {
+			return &ValidationError{Path: at, Message: "must be an integer"}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return typeError(at, "a number", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(at, "a boolean", value)
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateObject(schema *Schema, object map[string]interface{}, at string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return &ValidationError{Path: at + "." + name, Message: "is required"}
+		}
+	}
+
+	// Sorted so that the reported error is stable
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, declared := schema.Properties[name]
+		switch {
+		case declared:
+		case schema.AdditionalProperties != nil:
+			property = schema.AdditionalProperties
+		case v.strict && schema.Properties != nil:
+			return &ValidationError{Path: at + "." + name, Message: "is not declared in the schema"}
+		default:
+			continue
+		}
+		if err := v.validate(property, object[name], at+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAnyOf passes when the value matches one of the schemas, reporting the mismatch of
+// the first schema otherwise
+func (v *validator) validateAnyOf(schemas []*Schema, value interface{}, at string) error {
+	var first error
+	for _, sub := range schemas {
+		err := v.validate(sub, value, at)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return fmt.Errorf("matches none of %d schemas: %w", len(schemas), first)
+}
+
+func typeError(at, expected string, value interface{}) error {
+	return &ValidationError{Path: at, Message: fmt.Sprintf("must be %s, got %T", expected, value)}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
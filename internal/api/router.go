@@ -1,16 +1,19 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/frostdev-ops/pma-backend-go/internal/api/handlers"
 	"github.com/frostdev-ops/pma-backend-go/internal/api/middleware"
+	"github.com/frostdev-ops/pma-backend-go/internal/api/openapi"
 	"github.com/frostdev-ops/pma-backend-go/internal/config"
 	"github.com/frostdev-ops/pma-backend-go/internal/database"
 	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
 	"github.com/frostdev-ops/pma-backend-go/pkg/debug"
 	"github.com/frostdev-ops/pma-backend-go/pkg/errors"
 	"github.com/frostdev-ops/pma-backend-go/pkg/logger"
+	"github.com/frostdev-ops/pma-backend-go/pkg/version"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,6 +21,7 @@ import (
 type RouterWithHandlers struct {
 	Router   *gin.Engine
 	Handlers *handlers.Handlers
+	Spec     *openapi.Spec
 }
 
 // NewRouter creates and configures the main HTTP router with enhanced error handling
@@ -66,13 +70,18 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 	router.Use(middleware.LoggingMiddleware(batchLogger))
 	router.Use(middleware.CORSMiddleware())
 
-	// Rate limiting - temporarily disabled for debugging
-	// rateLimiter := middleware.NewRateLimiter(100, 200) // 100 requests/sec, burst 200
-	// router.Use(rateLimiter.RateLimitMiddleware())
-
 	// Initialize handlers - pass the underlying logrus.Logger to handlers
 	h := handlers.NewHandlers(cfg, repos, batchLogger.Logger, wsHub, db, enhancedDB, recoveryManager, nil)
 
+	// Rate limiting per client IP, off unless security.rate_limiting.enabled is set; retuned on
+	// configuration reload
+	rateLimiter := middleware.NewRateLimiterFromConfig(cfg.Security.RateLimiting)
+	h.ConfigManager().Subscribe("rate_limiter", []string{"security.rate_limiting"}, func(ctx context.Context, old, new *config.Config, changes config.Changes) error {
+		rateLimiter.Configure(new.Security.RateLimiting)
+		return nil
+	})
+	router.Use(rateLimiter.RateLimitMiddleware())
+
 	// Handle non-existent routes
 	router.NoRoute(h.HandleNotFound)
 	router.NoMethod(h.HandleMethodNotAllowed)
@@ -83,11 +92,16 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 	// WebSocket endpoint (no auth required for connection)
 	router.GET("/ws", h.WebSocketHandler(wsHub))
 
+	// OpenAPI spec generated from the registered routes and their described types
+	spec := openapi.NewSpec(openapi.Info{Title: "PMA Backend API", Version: version.Version}, "/api/v1", handlers.APIRoutes(), handlers.APIImplementations()...)
+
 	// API v1 routes
 	api := router.Group("/api/v1")
+	api.Use(spec.ValidationMiddleware()) // Reject request bodies that do not match the spec
 	{
 		// Authentication routes (public)
 		auth := api.Group("/auth")
+		auth.Use(h.AuditMiddleware()) // Record logins and PIN changes
 		{
 			// Legacy endpoints (keep for backward compatibility)
 			auth.POST("/register", h.Register)
@@ -115,12 +129,24 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 		public := api.Group("/")
 		{
 			public.GET("/status", h.Health)
+			public.GET("/openapi.json", spec.Handler(router.Routes))
 
 			// SSE stream endpoint (public for real-time updates) - needs special CORS handling
 			public.GET("/events/stream", middleware.CORSMiddlewareSSE(), h.GetEventStream)
 
 			// Image serving endpoint (public for screensaver display)
 			public.GET("/screensaver/images/:filename", h.GetScreensaverImage)
+
+			// Signed notification action links (ntfy buttons); the token authenticates the response
+			public.POST("/notification-actions/:token", h.RespondToNotificationLink)
+
+			// Kiosk pairing with a PIN from an admin pairing session
+			public.POST("/kiosk/pair", h.AuditMiddleware(), h.KioskHandler.PairKiosk)
+
+			// Shelly realtime push; devices authenticate with the push token or their known address
+			public.GET("/shelly/ws", h.ShellyPushWebSocket)
+			public.GET("/shelly/webhook/:device_id/:action", h.ShellyActionWebhook)
+			public.POST("/shelly/webhook/:device_id/:action", h.ShellyActionWebhook)
 		}
 
 		// Mobile upload page (public)
@@ -129,6 +155,7 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 		// Protected API routes - use remote auth middleware
 		protected := api.Group("/")
 		protected.Use(middleware.RemoteAuthMiddleware(cfg)) // Use remote auth middleware
+		protected.Use(h.AuditMiddleware())                  // Attribute actions to the authenticated actor and record changes
 		{
 			// User profile routes
 			profile := protected.Group("/profile")
@@ -151,6 +178,7 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				config.GET("/:key", h.GetConfig)
 				config.PUT("/:key", h.SetConfig)
 				config.GET("/", h.GetAllConfig)
+				config.POST("/reload", h.ReloadConfig)
 			}
 
 			// Entity management using unified PMA type system
@@ -172,6 +200,20 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				entities.GET("/source/:source", h.GetEntitiesBySource)
 				entities.GET("/room/:roomId", h.GetEntitiesByRoom)
 
+				// Group entities
+				entities.GET("/groups", h.GetEntityGroups)
+				entities.POST("/groups", h.CreateEntityGroup)
+				entities.GET("/groups/:id", h.GetEntityGroup)
+				entities.PUT("/groups/:id", h.UpdateEntityGroup)
+				entities.DELETE("/groups/:id", h.DeleteEntityGroup)
+
+				// Entity customizations
+				entities.GET("/customizations", h.GetEntityCustomizations)
+				entities.PUT("/customizations", h.BulkUpdateEntityCustomizations)
+				entities.GET("/:id/customization", h.GetEntityCustomization)
+				entities.PUT("/:id/customization", h.UpdateEntityCustomization)
+				entities.DELETE("/:id/customization", h.DeleteEntityCustomization)
+
 				// Debug endpoints for troubleshooting
 				entities.POST("/debug/sync", h.DebugSyncEntities)
 				entities.GET("/debug/registry", h.DebugEntityRegistry)
@@ -197,6 +239,8 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				rooms.DELETE("/:id", h.DeleteRoom)
 				rooms.GET("/stats", h.GetRoomStats)
 				rooms.POST("/sync-ha", h.SyncRoomsWithHA)
+				rooms.GET("/reconciliation", h.GetRoomReconciliation)
+				rooms.POST("/reconcile", h.ReconcileRooms)
 			}
 
 			// Controller Dashboard endpoints
@@ -226,6 +270,15 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 
 				// Search
 				controllers.GET("/search", h.SearchControllerDashboards)
+
+				// Schema
+				controllers.GET("/schema", h.GetControllerDashboardSchema)
+				controllers.POST("/schema/migrate", h.MigrateControllerDashboards)
+
+				// Revision history
+				controllers.GET("/:id/revisions", h.GetControllerDashboardRevisions)
+				controllers.GET("/:id/revisions/:revision", h.GetControllerDashboardRevision)
+				controllers.POST("/:id/revisions/:revision/rollback", h.RollbackControllerDashboard)
 			}
 
 			// Controller Template endpoints
@@ -233,6 +286,7 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 			{
 				templates.GET("/", h.GetControllerTemplates)
 				templates.POST("/", h.CreateControllerTemplate)
+				templates.GET("/:id/variables", h.GetControllerTemplateVariables)
 				templates.POST("/:id/apply", h.ApplyControllerTemplate)
 			}
 
@@ -283,6 +337,17 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				ai.GET("/summary", h.GetSystemSummary)
 				ai.POST("/analyze/entity/:id", h.AnalyzeEntity)
 				ai.POST("/generate/automation", h.GenerateAutomation)
+				ai.POST("/intents", h.ProcessIntent)
+				ai.GET("/intents/locales", h.GetIntentLocales)
+
+				// Long-term memory
+				ai.GET("/memories", h.GetMemories)
+				ai.GET("/memories/search", h.SearchMemories)
+				ai.POST("/memories", h.CreateMemoryNote)
+				ai.DELETE("/memories/:id", h.DeleteMemory)
+
+				// Home state added to prompts
+				ai.GET("/context/home", h.GetHomeContext)
 
 				// Model Management endpoints
 				models := ai.Group("/models")
@@ -336,6 +401,7 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				automation.POST("/rules/import", h.ImportAutomations)
 				automation.GET("/rules/export", h.ExportAutomations)
 				automation.POST("/rules/validate", h.ValidateAutomation)
+				automation.POST("/import/homeassistant", h.ImportHomeAssistantAutomations)
 				automation.GET("/statistics", h.GetAutomationStatistics)
 				automation.GET("/templates", h.GetAutomationTemplates)
 				automation.GET("/history", h.GetAutomationHistory)
@@ -394,14 +460,72 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				cameras.GET("/:id/stream", h.GetCameraStream)
 				cameras.GET("/:id/snapshot", h.GetCameraSnapshot)
 
-				// Camera events
+				// Camera event archive
 				cameras.GET("/events", h.GetAllCameraEvents)
+				cameras.GET("/events/:eventId", h.GetCameraEvent)
+				cameras.GET("/events/:eventId/:kind", h.GetCameraEventFile)
+				cameras.DELETE("/events/:eventId", h.DeleteCameraEvent)
 				cameras.GET("/:id/events", h.GetCameraEvents)
+				cameras.POST("/entity/:entityId/capture", h.CaptureCameraSnapshot)
+				cameras.GET("/entity/:entityId/latest-snapshot", h.GetLatestCameraSnapshot)
 
 				// Camera statistics
 				cameras.GET("/stats", h.GetCameraStats)
 			}
 
+			// Notification endpoints
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", h.GetNotifications)
+				notifications.GET("/unread-count", h.GetNotificationUnreadCount)
+				notifications.POST("/read-all", h.MarkAllNotificationsRead)
+				notifications.POST("/test", h.SendTestNotification)
+				notifications.GET("/channels", h.GetNotificationChannels)
+				notifications.POST("/push/subscriptions", h.SubscribeWebPush)
+				notifications.DELETE("/push/subscriptions", h.UnsubscribeWebPush)
+				notifications.GET("/:id", h.GetNotification)
+				notifications.DELETE("/:id", h.DeleteNotification)
+				notifications.POST("/:id/read", h.MarkNotificationRead)
+				notifications.POST("/:id/acknowledge", h.AcknowledgeNotification)
+				notifications.POST("/:id/actions/:actionId", h.RespondToNotification)
+			}
+
+			// Presence detection endpoints
+			presenceGroup := protected.Group("/presence")
+			{
+				presenceGroup.GET("/persons", h.GetPresencePersons)
+				presenceGroup.POST("/persons", h.CreatePresencePerson)
+				presenceGroup.GET("/persons/:id", h.GetPresencePerson)
+				presenceGroup.PUT("/persons/:id", h.UpdatePresencePerson)
+				presenceGroup.DELETE("/persons/:id", h.DeletePresencePerson)
+				presenceGroup.POST("/persons/:id/trackers", h.AddPresenceTracker)
+				presenceGroup.DELETE("/persons/:id/trackers/:tracker_id", h.DeletePresenceTracker)
+				presenceGroup.POST("/persons/:id/checkin", h.PresenceCheckIn)
+				presenceGroup.POST("/checkin", h.PresenceCheckInSelf)
+				presenceGroup.GET("/zones", h.GetPresenceZones)
+				presenceGroup.POST("/zones", h.SavePresenceZone)
+				presenceGroup.DELETE("/zones/:id", h.DeletePresenceZone)
+				presenceGroup.POST("/refresh", h.RefreshPresence)
+			}
+
+			// Device health watchdog endpoints
+			watchdogGroup := protected.Group("/watchdog")
+			{
+				watchdogGroup.GET("/attention", h.GetWatchdogAttention)
+				watchdogGroup.GET("/entities/:id", h.GetWatchdogEntity)
+				watchdogGroup.POST("/check", h.RunWatchdogCheck)
+			}
+
+			// Helper entity endpoints
+			helpersGroup := protected.Group("/helpers")
+			{
+				helpersGroup.GET("/", h.GetHelpers)
+				helpersGroup.POST("/", h.CreateHelper)
+				helpersGroup.GET("/:id", h.GetHelper)
+				helpersGroup.PUT("/:id", h.UpdateHelper)
+				helpersGroup.DELETE("/:id", h.DeleteHelper)
+			}
+
 			// Preferences endpoints
 			if h.PreferencesHandler != nil {
 				preferences := protected.Group("/preferences")
@@ -554,6 +678,10 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 
 				// UPS alerts and thresholds
 				ups.PUT("/alerts/thresholds", h.UpdateUPSAlertThresholds)
+
+				// Power failure handling
+				ups.GET("/power", h.GetUPSPowerState)
+				ups.POST("/power/shutdown/cancel", h.CancelUPSShutdown)
 			}
 
 			// System management endpoints
@@ -696,6 +824,14 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				// Configuration and status
 				shelly.PUT("/config", h.UpdateShellyConfig)
 				shelly.GET("/status", h.GetShellyAdapterStatus)
+
+				// Firmware management
+				shelly.GET("/firmware", h.GetShellyFirmware)
+				shelly.POST("/firmware/check", h.CheckShellyFirmware)
+				shelly.GET("/firmware/rollouts", h.GetShellyFirmwareRollouts)
+				shelly.POST("/firmware/rollouts", h.StartShellyFirmwareRollout)
+				shelly.GET("/firmware/rollouts/:id", h.GetShellyFirmwareRollout)
+				shelly.POST("/firmware/rollouts/:id/cancel", h.CancelShellyFirmwareRollout)
 			}
 
 			// Enhanced conversation management endpoints
@@ -962,17 +1098,49 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				events.GET("/status", h.GetEventStatus)
 			}
 
+			// Audit log of control actions and configuration changes
+			auditLog := protected.Group("/audit")
+			{
+				auditLog.GET("/", h.GetAuditLog)
+				auditLog.GET("/verify", h.VerifyAuditLog)
+				auditLog.GET("/export", h.ExportAuditLog)
+			}
+
+			// Outbound webhook subscriptions
+			webhooksGroup := protected.Group("/webhooks")
+			{
+				webhooksGroup.GET("/", h.GetWebhooks)
+				webhooksGroup.POST("/", h.CreateWebhook)
+				webhooksGroup.GET("/events", h.GetWebhookEventTypes)
+				webhooksGroup.GET("/:id", h.GetWebhook)
+				webhooksGroup.PUT("/:id", h.UpdateWebhook)
+				webhooksGroup.DELETE("/:id", h.DeleteWebhook)
+				webhooksGroup.GET("/:id/deliveries", h.GetWebhookDeliveries)
+				webhooksGroup.POST("/:id/test", h.TestWebhook)
+			}
+
+			// Voice pipeline (Wyoming speech services)
+			voiceGroup := protected.Group("/voice")
+			{
+				voiceGroup.GET("/status", h.GetVoiceStatus)
+				voiceGroup.POST("/synthesize", h.SynthesizeSpeech)
+			}
+
 			// MCP (Model Context Protocol) endpoints
 			mcp := protected.Group("/mcp")
 			{
 				mcp.GET("/status", h.GetMCPStatus)
 				mcp.GET("/servers", h.GetMCPServers)
 				mcp.POST("/servers", h.AddMCPServer)
+				mcp.GET("/servers/:serverId", h.GetMCPServer)
 				mcp.DELETE("/servers/:serverId", h.RemoveMCPServer)
 				mcp.GET("/servers/:serverId/connect", h.ConnectMCPServer)
 				mcp.GET("/servers/:serverId/disconnect", h.DisconnectMCPServer)
+				mcp.POST("/servers/:serverId/restart", h.RestartMCPServer)
 				mcp.GET("/tools", h.GetMCPTools)
 				mcp.POST("/tools/execute", h.ExecuteMCPTools)
+				mcp.GET("/resources", h.GetMCPResources)
+				mcp.POST("/resources/read", h.ReadMCPResource)
 			}
 
 			// Kiosk management endpoints (v1 API)
@@ -993,6 +1161,19 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 				// System control
 				kiosk.POST("/screenshot", h.TakeKioskScreenshot)
 				kiosk.POST("/restart", h.RestartKioskSystem)
+
+				// Pairing sessions
+				kiosk.POST("/pair/create", h.KioskHandler.CreatePairingSession)
+				kiosk.DELETE("/pair/:sessionId", h.KioskHandler.CancelPairingSession)
+
+				// Remote management of paired kiosks
+				kiosk.GET("/fleet", h.KioskHandler.GetFleetStatus)
+				kiosk.POST("/devices/:kioskId/commands", h.KioskHandler.SendKioskCommand)
+				kiosk.GET("/devices/:kioskId/commands", h.KioskHandler.GetKioskCommandHistory)
+				kiosk.GET("/commands/:commandId", h.KioskHandler.GetKioskCommand)
+				kiosk.GET("/commands/:commandId/screenshot", h.KioskHandler.GetCommandScreenshot)
+				kiosk.POST("/bundles", h.KioskHandler.PublishBundle)
+				kiosk.POST("/bundles/:version/deploy", h.KioskHandler.DeployBundle)
 			}
 
 			// File upload and screensaver management endpoints
@@ -1005,6 +1186,27 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 			}
 		}
 
+		// Adaptive media streaming (signed URL auth, see GetStreamingURL)
+		if h.MediaHandler != nil {
+			hls := api.Group("/media/hls")
+			{
+				hls.GET("/:id/master.m3u8", h.MediaHandler.GetHLSMasterPlaylist)
+				hls.GET("/:id/renditions/:rendition/:resource", h.MediaHandler.GetHLSRenditionResource)
+			}
+		}
+
+		// Kiosk device endpoints (kiosk token auth)
+		kioskClient := api.Group("/kiosk-client")
+		kioskClient.Use(h.KioskHandler.KioskAuthMiddleware())
+		{
+			kioskClient.POST("/heartbeat", h.KioskHandler.Heartbeat)
+			kioskClient.GET("/commands/pending", h.KioskHandler.GetPendingCommands)
+			kioskClient.POST("/commands/:commandId/ack", h.KioskHandler.AcknowledgeCommand)
+			kioskClient.POST("/commands/:commandId/complete", h.KioskHandler.CompleteCommand)
+			kioskClient.POST("/commands/:commandId/screenshot", h.KioskHandler.UploadCommandScreenshot)
+			kioskClient.GET("/bundles/:version", h.KioskHandler.DownloadBundle)
+		}
+
 		// Legacy display settings endpoints for backward compatibility
 		api.GET("/display-settings", h.GetDisplaySettingsLegacy)
 		api.POST("/display-settings", h.UpdateDisplaySettingsLegacy)
@@ -1018,6 +1220,7 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 
 	// Legacy API routes without v1 prefix for frontend compatibility
 	legacyAPI := router.Group("/api")
+	legacyAPI.Use(h.AuditMiddleware())
 	{
 		// Legacy auth routes (public)
 		legacyAuth := legacyAPI.Group("/auth")
@@ -1134,6 +1337,7 @@ func NewRouter(cfg *config.Config, repos *database.Repositories, batchLogger *lo
 	return &RouterWithHandlers{
 		Router:   router,
 		Handlers: h,
+		Spec:     spec,
 	}
 }
 
@@ -1190,6 +1394,7 @@ func NewRouterWithDebug(cfg *config.Config, repos *database.Repositories, batchL
 	{
 		// Authentication routes (public)
 		auth := api.Group("/auth")
+		auth.Use(h.AuditMiddleware()) // Record logins and PIN changes
 		{
 			// Legacy endpoints (keep for backward compatibility)
 			auth.POST("/register", h.Register)
@@ -25,6 +25,11 @@ type Config struct {
 	Monitoring       MonitoringConfig       `mapstructure:"monitoring"`
 	FileManager      FileManagerConfig      `mapstructure:"file_manager"`
 	Performance      PerformanceConfig      `mapstructure:"performance"`
+	Notifications    NotificationsConfig    `mapstructure:"notifications"`
+	Presence         PresenceConfig         `mapstructure:"presence"`
+	Watchdog         WatchdogConfig         `mapstructure:"watchdog"`
+	Webhooks         WebhooksConfig         `mapstructure:"webhooks"`
+	Voice            VoiceConfig            `mapstructure:"voice"`
 }
 
 type ServerConfig struct {
@@ -131,6 +136,41 @@ type AIConfig struct {
 	DefaultProvider string             `mapstructure:"default_provider"`
 	MaxRetries      int                `mapstructure:"max_retries"`
 	Timeout         string             `mapstructure:"timeout"`
+	Intents         IntentsConfig      `mapstructure:"intents"`
+	Memory          AIMemoryConfig     `mapstructure:"memory"`
+	HomeContext     HomeContextConfig  `mapstructure:"home_context"`
+}
+
+// IntentsConfig contains configuration for local intent recognition, which handles common
+// commands without an LLM
+type IntentsConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	LocalesPath   string  `mapstructure:"locales_path"`   // Directory of the locale files holding the sentence templates
+	DefaultLocale string  `mapstructure:"default_locale"` // Locale used when a request does not name one
+	MinScore      float64 `mapstructure:"min_score"`      // Minimum fuzzy match score (0-1) for entity, room and area names
+}
+
+// AIMemoryConfig contains configuration for long-term conversation memory: facts learned from
+// conversations and user notes, recalled into the system prompt by embedding similarity
+type AIMemoryConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	ExtractFacts      bool    `mapstructure:"extract_facts"`      // Learn facts from conversations, not only from notes
+	EmbeddingProvider string  `mapstructure:"embedding_provider"` // Any provider supporting embeddings when empty
+	EmbeddingModel    string  `mapstructure:"embedding_model"`    // The provider's default embedding model when empty
+	MaxResults        int     `mapstructure:"max_results"`        // Memories recalled per message
+	MinScore          float64 `mapstructure:"min_score"`          // Minimum similarity (0-1) of a recalled memory
+	DuplicateScore    float64 `mapstructure:"duplicate_score"`    // Similarity (0-1) above which a learned fact is already known
+}
+
+// HomeContextConfig contains configuration for the home state added to LLM prompts: entities
+// relevant to the message in detail, the rest summarized by area and room
+type HomeContextConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	MaxTokens    int     `mapstructure:"max_tokens"`    // Upper bound of the rendered context
+	WindowShare  float64 `mapstructure:"window_share"`  // Share (0-1) of the provider's context window the context may use
+	MaxEntities  int     `mapstructure:"max_entities"`  // Entities listed individually at most
+	RecentWindow string  `mapstructure:"recent_window"` // Entities changed this recently count as relevant
+	CacheTTL     string  `mapstructure:"cache_ttl"`     // How long a snapshot of entity states is reused between turns
 }
 
 // AIProviderConfig contains configuration for a specific AI provider
@@ -141,6 +181,7 @@ type AIProviderConfig struct {
 	APIKey         string                 `mapstructure:"api_key,omitempty"`
 	DefaultModel   string                 `mapstructure:"default_model"`
 	MaxTokens      int                    `mapstructure:"max_tokens,omitempty"`
+	ContextWindow  int                    `mapstructure:"context_window,omitempty"` // Tokens the default model reads; a default per provider type when unset
 	AutoStart      bool                   `mapstructure:"auto_start,omitempty"`
 	ResourceLimits AIResourceLimits       `mapstructure:"resource_limits,omitempty"`
 	Models         []string               `mapstructure:"models,omitempty"`
@@ -162,6 +203,8 @@ type DevicesConfig struct {
 	Shelly              ShellyConfig  `mapstructure:"shelly"`
 	UPS                 UPSConfig     `mapstructure:"ups"`
 	Network             NetworkConfig `mapstructure:"network"`
+	BLE                 BLEConfig     `mapstructure:"ble"`
+	Cameras             CamerasConfig `mapstructure:"cameras"`
 }
 
 // RingConfig contains Ring integration configuration
@@ -200,6 +243,31 @@ type ShellyConfig struct {
 	ExcludeLoopback           bool     `mapstructure:"exclude_loopback"`
 	ExcludeDockerInterfaces   bool     `mapstructure:"exclude_docker_interfaces"`
 	MinSubnetSize             int      `mapstructure:"min_subnet_size"`
+
+	// Realtime push from devices instead of polling
+	Push ShellyPushConfig `mapstructure:"push"`
+
+	// Firmware update rollouts
+	Firmware ShellyFirmwareConfig `mapstructure:"firmware"`
+}
+
+// ShellyPushConfig contains settings for realtime updates pushed by Shelly devices. Gen2+
+// devices connect to PMA over an outbound WebSocket, Gen1 devices call action URLs.
+type ShellyPushConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	BaseURL       string `mapstructure:"base_url"`       // URL devices use to reach PMA, e.g. "http://192.168.1.10:3001"
+	Token         string `mapstructure:"token"`          // Shared secret appended to the push URLs
+	AutoConfigure bool   `mapstructure:"auto_configure"` // Point discovered devices at PMA automatically
+}
+
+// ShellyFirmwareConfig contains defaults for rolling firmware updates. Rollout requests may
+// override them.
+type ShellyFirmwareConfig struct {
+	Stage                  string `mapstructure:"stage"`                    // "stable" or "beta"
+	MaxConcurrent          int    `mapstructure:"max_concurrent"`           // Devices updated at the same time
+	MaintenanceWindowStart string `mapstructure:"maintenance_window_start"` // Local time "HH:MM"; empty allows any time
+	MaintenanceWindowEnd   string `mapstructure:"maintenance_window_end"`   // Local time "HH:MM"
+	UpdateTimeout          string `mapstructure:"update_timeout"`           // Time a device gets to come back healthy
 }
 
 // UPSConfig contains UPS integration configuration
@@ -214,6 +282,56 @@ type UPSConfig struct {
 	PollInterval         string   `mapstructure:"poll_interval"`
 	MonitoringInterval   string   `mapstructure:"monitoring_interval"`
 	HistoryRetentionDays int      `mapstructure:"history_retention_days"`
+
+	// Power failure handling
+	Power UPSPowerConfig `mapstructure:"power"`
+}
+
+// UPSPowerConfig describes what happens when mains power fails. Stages, remote shutdowns and
+// the local shutdown run when their power event occurs ("on_battery", "low_battery",
+// "critical_battery" or "power_restored"); everything still pending is cancelled when power
+// returns.
+type UPSPowerConfig struct {
+	Enabled         bool                `mapstructure:"enabled"`
+	LowBattery      float64             `mapstructure:"low_battery"`      // Charge percentage for low_battery
+	CriticalBattery float64             `mapstructure:"critical_battery"` // Charge percentage for critical_battery
+	Stages          []UPSLoadShedStage  `mapstructure:"stages"`
+	RemoteShutdowns []UPSRemoteShutdown `mapstructure:"remote_shutdowns"`
+	Shutdown        UPSShutdownConfig   `mapstructure:"shutdown"`
+}
+
+// UPSLoadShedStage runs an action on non-critical entities
+type UPSLoadShedStage struct {
+	Name     string                 `mapstructure:"name"`
+	On       string                 `mapstructure:"on"`
+	Delay    string                 `mapstructure:"delay"`
+	Entities []string               `mapstructure:"entities"`
+	Action   string                 `mapstructure:"action"` // Defaults to "turn_off"
+	Params   map[string]interface{} `mapstructure:"params"`
+}
+
+// UPSRemoteShutdown shuts down another host powered by the UPS
+type UPSRemoteShutdown struct {
+	Name     string `mapstructure:"name"`
+	Type     string `mapstructure:"type"` // "nut_fsd", "ssh" or "webhook"
+	On       string `mapstructure:"on"`
+	Delay    string `mapstructure:"delay"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	UPSName  string `mapstructure:"ups_name"` // nut_fsd: UPS to flag, defaults to ups_name
+	KeyFile  string `mapstructure:"key_file"` // ssh: private key
+	Command  string `mapstructure:"command"`  // ssh: defaults to "sudo shutdown -h now"
+	URL      string `mapstructure:"url"`      // webhook
+	Token    string `mapstructure:"token"`    // webhook: sent as a bearer token
+}
+
+// UPSShutdownConfig shuts down the PMA host after a countdown that power restoration cancels
+type UPSShutdownConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	On        string `mapstructure:"on"`
+	Countdown string `mapstructure:"countdown"`
 }
 
 // NetworkConfig contains network adapter configuration
@@ -227,6 +345,33 @@ type NetworkConfig struct {
 	AutoReconnect   bool     `mapstructure:"auto_reconnect"`
 }
 
+// BLEConfig contains passive BLE sensor adapter configuration
+type BLEConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	ScanCommand      string   `mapstructure:"scan_command"`
+	PresenceTimeout  string   `mapstructure:"presence_timeout"`
+	RSSIThreshold    int      `mapstructure:"rssi_threshold"`
+	AllowedAddresses []string `mapstructure:"allowed_addresses"`
+	PathLossExponent float64  `mapstructure:"path_loss_exponent"`
+}
+
+// CamerasConfig contains camera integration configuration
+type CamerasConfig struct {
+	Recording CameraRecordingConfig `mapstructure:"recording"`
+}
+
+// CameraRecordingConfig contains defaults for the event snapshot/clip recorder.
+// Individual cameras can override them through the "recording" key of their settings.
+type CameraRecordingConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	RecordClips        bool   `mapstructure:"record_clips"`
+	RetentionDays      int    `mapstructure:"retention_days"`
+	MaxEventsPerCamera int    `mapstructure:"max_events_per_camera"`
+	Cooldown           string `mapstructure:"cooldown"`
+	CleanupInterval    string `mapstructure:"cleanup_interval"`
+	StoragePath        string `mapstructure:"storage_path"`
+}
+
 // RouterConfig contains router/network configuration
 type RouterConfig struct {
 	Enabled           bool   `mapstructure:"enabled"`
@@ -286,6 +431,110 @@ type MockDataConfig struct {
 	RingStreamsBase   string `mapstructure:"ring_streams_base"`
 }
 
+// NotificationsConfig contains notification delivery configuration. Channels are used for a user
+// when they are enabled here and listed in the user's notification preferences.
+type NotificationsConfig struct {
+	Enabled       bool                      `mapstructure:"enabled"`
+	RetentionDays int                       `mapstructure:"retention_days"`
+	Timeout       string                    `mapstructure:"timeout"`
+	ActionBaseURL string                    `mapstructure:"action_base_url"` // Public API URL used for action links in external channels
+	ActionSecret  string                    `mapstructure:"action_secret"`   // Signs action links; links are disabled when empty
+	Email         EmailNotificationConfig   `mapstructure:"email"`
+	Ntfy          NtfyNotificationConfig    `mapstructure:"ntfy"`
+	Gotify        GotifyNotificationConfig  `mapstructure:"gotify"`
+	Webhook       WebhookNotificationConfig `mapstructure:"webhook"`
+	WebPush       WebPushNotificationConfig `mapstructure:"web_push"`
+}
+
+// EmailNotificationConfig contains SMTP settings for the email channel
+type EmailNotificationConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	To       string `mapstructure:"to"` // Fallback recipient when a user has no address configured
+}
+
+// NtfyNotificationConfig contains settings for the ntfy channel
+type NtfyNotificationConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	ServerURL string `mapstructure:"server_url"`
+	Topic     string `mapstructure:"topic"` // Default topic when a user has none configured
+	Token     string `mapstructure:"token"`
+}
+
+// GotifyNotificationConfig contains settings for the Gotify channel
+type GotifyNotificationConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	ServerURL string `mapstructure:"server_url"`
+	Token     string `mapstructure:"token"` // Application token
+}
+
+// WebhookNotificationConfig contains settings for the generic webhook channel
+type WebhookNotificationConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	URL     string `mapstructure:"url"` // Default URL when a user has none configured
+	Secret  string `mapstructure:"secret"`
+}
+
+// WebPushNotificationConfig contains the VAPID settings for the Web Push channel
+type WebPushNotificationConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	VAPIDPublicKey  string `mapstructure:"vapid_public_key"`
+	VAPIDPrivateKey string `mapstructure:"vapid_private_key"`
+	Subject         string `mapstructure:"subject"` // mailto: or https: contact of the sender
+}
+
+// PresenceConfig contains presence detection configuration
+type PresenceConfig struct {
+	Enabled             bool    `mapstructure:"enabled"`
+	ScanInterval        string  `mapstructure:"scan_interval"`
+	DefaultConsiderHome string  `mapstructure:"default_consider_home"` // How long a tracker still counts as home after it was last seen
+	HomeThreshold       float64 `mapstructure:"home_threshold"`        // Probability at which a person is considered home
+	ARPTablePath        string  `mapstructure:"arp_table_path"`
+	PingEnabled         bool    `mapstructure:"ping_enabled"` // Ping IP trackers missing from the ARP table
+	PingTimeout         string  `mapstructure:"ping_timeout"`
+}
+
+// WatchdogConfig contains device health watchdog configuration
+type WatchdogConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	CheckInterval     string   `mapstructure:"check_interval"`
+	StaleFactor       float64  `mapstructure:"stale_factor"`        // Multiple of the learned update interval after which an entity is stale
+	MinStaleAfter     string   `mapstructure:"min_stale_after"`     // Lower bound for the stale timeout
+	DefaultStaleAfter string   `mapstructure:"default_stale_after"` // Stale timeout until an interval has been learned
+	UnavailableAfter  string   `mapstructure:"unavailable_after"`   // How long an entity may be unavailable before it is flagged
+	LowBattery        float64  `mapstructure:"low_battery"`         // Battery percentage flagged as low
+	CriticalBattery   float64  `mapstructure:"critical_battery"`    // Battery percentage raised as a critical alert
+	StaleTypes        []string `mapstructure:"stale_types"`         // Entity types expected to report regularly
+	IgnoreEntities    []string `mapstructure:"ignore_entities"`
+}
+
+// WebhooksConfig contains outbound webhook configuration
+type WebhooksConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	Timeout              string `mapstructure:"timeout"`
+	DisableAfterFailures int    `mapstructure:"disable_after_failures"` // Consecutive failed attempts after which an endpoint is disabled
+	AdapterCheckInterval string `mapstructure:"adapter_check_interval"` // How often adapter connections are checked for status events
+	DeliveryRetention    string `mapstructure:"delivery_retention"`     // How long delivery logs are kept
+}
+
+// VoiceConfig contains configuration for the voice pipeline, which talks to local wake word,
+// speech-to-text and text-to-speech services over the Wyoming protocol
+type VoiceConfig struct {
+	Enabled           bool              `mapstructure:"enabled"`
+	STTURI            string            `mapstructure:"stt_uri"`  // Speech-to-text service, e.g. tcp://localhost:10300 (whisper)
+	TTSURI            string            `mapstructure:"tts_uri"`  // Text-to-speech service, e.g. tcp://localhost:10200 (piper)
+	WakeURI           string            `mapstructure:"wake_uri"` // Wake word service, e.g. tcp://localhost:10400 (openWakeWord); optional
+	WakeWords         []string          `mapstructure:"wake_words"`
+	Language          string            `mapstructure:"language"`            // Default language of voice sessions
+	Voices            map[string]string `mapstructure:"voices"`              // Language -> text-to-speech voice
+	MaxSpeechDuration string            `mapstructure:"max_speech_duration"` // Speech is transcribed once this long, even without a stop
+	Timeout           string            `mapstructure:"timeout"`             // Timeout of each service request
+}
+
 // StorageConfig contains file storage and path configuration
 type StorageConfig struct {
 	BasePath     string `mapstructure:"base_path"`
@@ -367,10 +616,21 @@ type FileStorageConfig struct {
 
 // FileMediaConfig contains media processing configuration
 type FileMediaConfig struct {
-	EnableStreaming   bool     `mapstructure:"enable_streaming"`
-	ThumbnailSizes    []int    `mapstructure:"thumbnail_sizes"`
-	TranscodeProfiles []string `mapstructure:"transcode_profiles"`
-	CachePath         string   `mapstructure:"cache_path"`
+	EnableStreaming   bool      `mapstructure:"enable_streaming"`
+	ThumbnailSizes    []int     `mapstructure:"thumbnail_sizes"`
+	TranscodeProfiles []string  `mapstructure:"transcode_profiles"`
+	CachePath         string    `mapstructure:"cache_path"`
+	HLS               HLSConfig `mapstructure:"hls"`
+}
+
+// HLSConfig contains adaptive (HLS) streaming configuration
+type HLSConfig struct {
+	FFmpegPath        string `mapstructure:"ffmpeg_path"`
+	FFprobePath       string `mapstructure:"ffprobe_path"`
+	SegmentDuration   int    `mapstructure:"segment_duration"` // Seconds per segment; passthrough segments run to the next keyframe after it
+	CacheMaxSize      int64  `mapstructure:"cache_max_size"`   // Bytes of transcoded segments to keep
+	MaxConcurrentJobs int    `mapstructure:"max_concurrent_jobs"`
+	URLTTL            string `mapstructure:"url_ttl"` // Lifetime of signed playlist URLs
 }
 
 // FileBackupConfig contains backup configuration
@@ -722,6 +982,13 @@ func setDefaults() {
 	viper.SetDefault("devices.shelly.exclude_docker_interfaces", true)
 	viper.SetDefault("devices.shelly.min_subnet_size", 16)
 
+	// Realtime push defaults
+	viper.SetDefault("devices.shelly.push.enabled", true)
+	viper.SetDefault("devices.shelly.push.auto_configure", false)
+	viper.SetDefault("devices.shelly.firmware.stage", "stable")
+	viper.SetDefault("devices.shelly.firmware.max_concurrent", 2)
+	viper.SetDefault("devices.shelly.firmware.update_timeout", "10m")
+
 	// Enhanced UPS defaults (replacing previous simple ones)
 	viper.SetDefault("devices.ups.enabled", false)
 	viper.SetDefault("devices.ups.nut_host", "localhost")
@@ -731,6 +998,12 @@ func setDefaults() {
 	viper.SetDefault("devices.ups.poll_interval", "30s")
 	viper.SetDefault("devices.ups.monitoring_interval", "30s")
 	viper.SetDefault("devices.ups.history_retention_days", 30)
+	viper.SetDefault("devices.ups.power.enabled", false)
+	viper.SetDefault("devices.ups.power.low_battery", 20.0)
+	viper.SetDefault("devices.ups.power.critical_battery", 10.0)
+	viper.SetDefault("devices.ups.power.shutdown.enabled", false)
+	viper.SetDefault("devices.ups.power.shutdown.on", "critical_battery")
+	viper.SetDefault("devices.ups.power.shutdown.countdown", "2m")
 
 	// Router defaults
 	viper.SetDefault("router.enabled", true)
@@ -802,7 +1075,7 @@ func setDefaults() {
 	// Security defaults
 	viper.SetDefault("security.enable_cors", true)
 	viper.SetDefault("security.allowed_origins", []string{"*"})
-	viper.SetDefault("security.rate_limiting.enabled", true)
+	viper.SetDefault("security.rate_limiting.enabled", false) // Off unless configured; kiosks behind one NAT share a client IP
 	viper.SetDefault("security.rate_limiting.requests_per_minute", 60)
 	viper.SetDefault("security.rate_limiting.burst_size", 10)
 
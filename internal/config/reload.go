@@ -0,0 +1,292 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// watchDebounce collapses the burst of events editors produce when saving a file
+const watchDebounce = 500 * time.Millisecond
+
+var (
+	// ErrInvalidConfig is returned when a new configuration cannot be read or fails validation
+	ErrInvalidConfig = errors.New("invalid configuration")
+
+	// ErrNoConfigFile is returned when watching while no configuration file was read
+	ErrNoConfigFile = errors.New("no configuration file in use")
+)
+
+// RejectedError is returned when a subscriber cannot apply a new configuration. Subscribers
+// that already applied it are rolled back.
+type RejectedError struct {
+	Subscriber string
+	Err        error
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("configuration rejected by %s: %v", e.Subscriber, e.Err)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// Changes lists the keys that differ between two configurations, e.g. "logging.level".
+// Slices and maps are compared as a whole.
+type Changes []string
+
+// Has reports whether a key or any key below it changed
+func (c Changes) Has(keys ...string) bool {
+	for _, change := range c {
+		for _, key := range keys {
+			if change == key || strings.HasPrefix(change, key+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Diff returns the keys that differ between two configurations
+func Diff(old, new *Config) Changes {
+	var changes Changes
+	diffValues(reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), "", &changes)
+	sort.Strings(changes)
+	return changes
+}
+
+func diffValues(old, new reflect.Value, key string, changes *Changes) {
+	if old.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*changes = append(*changes, key)
+		}
+		return
+	}
+
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if key != "" {
+			name = key + "." + name
+		}
+		diffValues(old.Field(i), new.Field(i), name, changes)
+	}
+}
+
+// ApplyFunc applies a new configuration to a running service. It is called again with the
+// arguments swapped to roll back when it or a later subscriber rejects the change, so a
+// subscriber that fails halfway is restored too.
+type ApplyFunc func(ctx context.Context, old, new *Config, changes Changes) error
+
+type subscriber struct {
+	name  string
+	keys  []string
+	apply ApplyFunc
+}
+
+// ReloadResult describes an applied configuration change
+type ReloadResult struct {
+	Changed []string `json:"changed"`
+	Applied []string `json:"applied"` // Subscribers notified of the change
+
+	// Unhandled changes no subscriber applied; they take effect after a restart
+	Unhandled []string `json:"unhandled"`
+
+	ReloadedAt time.Time `json:"reloaded_at"`
+}
+
+// Manager holds the running configuration and propagates changes to subscribing services.
+// Configurations are never modified once running: a reload swaps in a new one, and services
+// learn about it through their subscription instead of watching a shared struct change.
+type Manager struct {
+	current     atomic.Pointer[Config]
+	subscribers []subscriber
+	logger      *logrus.Logger
+
+	mu         sync.Mutex // Serializes reloads and guards subscribers and lastReload
+	lastReload *ReloadResult
+}
+
+// NewManager creates a manager starting from a copy of cfg; cfg itself is not changed by reloads
+func NewManager(cfg *Config, logger *logrus.Logger) *Manager {
+	m := &Manager{logger: logger}
+	snapshot := *cfg
+	m.current.Store(&snapshot)
+	return m
+}
+
+// Current returns a snapshot of the running configuration, which callers must not modify
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+// LastReload returns the result of the last applied change, or nil
+func (m *Manager) LastReload() *ReloadResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastReload
+}
+
+// Subscribe registers a service to be notified when any of the keys, or keys below them,
+// change. Subscribers are notified in registration order.
+func (m *Manager) Subscribe(name string, keys []string, apply ApplyFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, subscriber{name: name, keys: keys, apply: apply})
+}
+
+// Reload reads the configuration file and environment again and applies the result
+func (m *Manager) Reload(ctx context.Context) (*ReloadResult, error) {
+	next, err := Load()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return m.Apply(ctx, next)
+}
+
+// Apply validates a new configuration and notifies the subscribers of the keys that changed.
+// If a subscriber rejects it, those already notified are rolled back and the running
+// configuration is left unchanged.
+func (m *Manager) Apply(ctx context.Context, next *Config) (*ReloadResult, error) {
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old := m.current.Load()
+	changes := Diff(old, next)
+	result := &ReloadResult{
+		Changed:    changes,
+		Applied:    []string{},
+		Unhandled:  []string{},
+		ReloadedAt: time.Now(),
+	}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	var applied []subscriber
+	for _, sub := range m.subscribers {
+		if !changes.Has(sub.keys...) {
+			continue
+		}
+		if err := sub.apply(ctx, old, next, changes); err != nil {
+			m.rollback(ctx, append(applied, sub), old, next, changes)
+			return nil, &RejectedError{Subscriber: sub.name, Err: err}
+		}
+		applied = append(applied, sub)
+		result.Applied = append(result.Applied, sub.name)
+	}
+
+	for _, change := range changes {
+		handled := false
+		for _, sub := range applied {
+			handled = handled || Changes{change}.Has(sub.keys...)
+		}
+		if !handled {
+			result.Unhandled = append(result.Unhandled, change)
+		}
+	}
+
+	m.current.Store(next)
+	m.lastReload = result
+
+	m.logger.WithFields(logrus.Fields{
+		"changed":   result.Changed,
+		"applied":   result.Applied,
+		"unhandled": result.Unhandled,
+	}).Info("Configuration reloaded")
+	return result, nil
+}
+
+// rollback restores the old configuration in the subscribers that were notified of the new one,
+// in reverse order
+func (m *Manager) rollback(ctx context.Context, applied []subscriber, old, next *Config, changes Changes) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := applied[i].apply(ctx, next, old, changes); err != nil {
+			m.logger.WithError(err).WithField("subscriber", applied[i].name).Error("Failed to roll back configuration change")
+		}
+	}
+}
+
+// Watch reloads the configuration whenever the configuration file changes until the context
+// is cancelled. Invalid or rejected changes are logged and leave the running configuration in
+// place.
+func (m *Manager) Watch(ctx context.Context) error {
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return ErrNoConfigFile
+	}
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	// Watch the directory, editors often replace the file instead of writing to it
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	go m.watch(ctx, watcher, path)
+	m.logger.WithField("file", path).Info("Watching configuration file for changes")
+	return nil
+}
+
+func (m *Manager) watch(ctx context.Context, watcher *fsnotify.Watcher, path string) {
+	defer watcher.Close()
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			name, err := filepath.Abs(event.Name)
+			if err != nil || name != path || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			reload = time.After(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			m.logger.WithError(err).Warn("Configuration file watcher error")
+		case <-reload:
+			reload = nil
+			if _, err := m.Reload(ctx); err != nil {
+				m.logger.WithError(err).Error("Configuration reload failed, keeping the running configuration")
+			}
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validConfig returns the smallest configuration that passes validation
+func validConfig() *Config {
+	return &Config{
+		Server:   ServerConfig{Port: 3001, Host: "localhost"},
+		Database: DatabaseConfig{Path: "pma.db"},
+		Logging:  LoggingConfig{Level: "info"},
+		ExternalServices: ExternalServicesConfig{
+			IPCheckServices: IPCheckServicesConfig{Primary: "https://primary", Fallback: "https://fallback"},
+		},
+		Storage: StorageConfig{BasePath: "data", TempPath: "tmp"},
+		System: SystemConfig{
+			Services: map[string]SystemServiceConfig{"backend": {Enabled: true}},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   Changes
+	}{
+		{
+			name:   "unchanged",
+			modify: func(c *Config) {},
+			want:   nil,
+		},
+		{
+			name:   "nested key",
+			modify: func(c *Config) { c.Devices.Shelly.Push.Token = "secret" },
+			want:   Changes{"devices.shelly.push.token"},
+		},
+		{
+			name: "keys are sorted",
+			modify: func(c *Config) {
+				c.Server.Port = 3002
+				c.Logging.Level = "debug"
+			},
+			want: Changes{"logging.level", "server.port"},
+		},
+		{
+			name:   "slice compared as a whole",
+			modify: func(c *Config) { c.Devices.Shelly.NetworkScanRanges = []string{"192.168.1.0/24"} },
+			want:   Changes{"devices.shelly.network_scan_ranges"},
+		},
+		{
+			name: "map compared as a whole",
+			modify: func(c *Config) {
+				c.System.Services = map[string]SystemServiceConfig{"backend": {Enabled: false}}
+			},
+			want: Changes{"system.services"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := validConfig()
+			next := validConfig()
+			tt.modify(next)
+			assert.Equal(t, tt.want, Diff(old, next))
+		})
+	}
+}
+
+func TestDiffValuesTags(t *testing.T) {
+	type inner struct {
+		Value int `mapstructure:"value,omitempty"`
+	}
+	type tagged struct {
+		Named    string   `mapstructure:"named_key"`
+		Untagged string   // keyed by the lower case field name
+		Skipped  string   `mapstructure:"-"`
+		hidden   string   // unexported fields are not configuration
+		List     []string `mapstructure:"list"`
+		Inner    inner    `mapstructure:"inner"`
+	}
+
+	tests := []struct {
+		name     string
+		old, new tagged
+		want     Changes
+	}{
+		{"tag name", tagged{Named: "a"}, tagged{Named: "b"}, Changes{"named_key"}},
+		{"field name", tagged{Untagged: "a"}, tagged{Untagged: "b"}, Changes{"untagged"}},
+		{"skipped field", tagged{Skipped: "a"}, tagged{Skipped: "b"}, nil},
+		{"unexported field", tagged{hidden: "a"}, tagged{hidden: "b"}, nil},
+		{"slice element", tagged{List: []string{"a", "b"}}, tagged{List: []string{"a", "c"}}, Changes{"list"}},
+		{"nil and empty slice", tagged{List: nil}, tagged{List: []string{}}, Changes{"list"}},
+		{"tag options", tagged{Inner: inner{Value: 1}}, tagged{Inner: inner{Value: 2}}, Changes{"inner.value"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes Changes
+			diffValues(reflect.ValueOf(tt.old), reflect.ValueOf(tt.new), "", &changes)
+			assert.Equal(t, tt.want, changes)
+		})
+	}
+}
+
+func TestChangesHas(t *testing.T) {
+	changes := Changes{"devices.shelly.push.token", "logging.level"}
+
+	assert.True(t, changes.Has("logging.level"))
+	assert.True(t, changes.Has("devices.shelly"))
+	assert.True(t, changes.Has("server", "devices.shelly.push"))
+	assert.False(t, changes.Has("devices.shelly.push.token_file"))
+	assert.False(t, changes.Has("logging.lev"))
+}
+
+func TestManagerApply(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("applies and reports unhandled keys", func(t *testing.T) {
+		start := validConfig()
+		manager := NewManager(start, logger)
+		manager.Subscribe("logger", []string{"logging.level"}, func(ctx context.Context, old, new *Config, changes Changes) error {
+			return nil
+		})
+
+		next := validConfig()
+		next.Logging.Level = "debug"
+		next.Server.Port = 3002
+
+		result, err := manager.Apply(context.Background(), next)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"logging.level", "server.port"}, result.Changed)
+		assert.Equal(t, []string{"logger"}, result.Applied)
+		assert.Equal(t, []string{"server.port"}, result.Unhandled)
+		assert.Same(t, next, manager.Current())
+		assert.Equal(t, "info", start.Logging.Level, "the startup configuration is never modified")
+	})
+
+	t.Run("rejects an invalid configuration", func(t *testing.T) {
+		manager := NewManager(validConfig(), logger)
+		current := manager.Current()
+
+		next := validConfig()
+		next.Server.Port = 0
+
+		_, err := manager.Apply(context.Background(), next)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Same(t, current, manager.Current())
+	})
+
+	t.Run("rolls back in reverse order when a subscriber rejects", func(t *testing.T) {
+		manager := NewManager(validConfig(), logger)
+		current := manager.Current()
+
+		var calls []string
+		subscribe := func(name string, keys []string, reject bool) {
+			manager.Subscribe(name, keys, func(ctx context.Context, old, new *Config, changes Changes) error {
+				calls = append(calls, name+" "+new.Logging.Level)
+				if reject && new.Logging.Level == "debug" {
+					return errors.New("unsupported")
+				}
+				return nil
+			})
+		}
+		subscribe("first", []string{"logging"}, false)
+		subscribe("unrelated", []string{"server"}, false)
+		subscribe("second", []string{"logging.level"}, false)
+		subscribe("rejecting", []string{"logging.level"}, true)
+		subscribe("after", []string{"logging.level"}, false)
+
+		next := validConfig()
+		next.Logging.Level = "debug"
+
+		_, err := manager.Apply(context.Background(), next)
+		var rejected *RejectedError
+		require.ErrorAs(t, err, &rejected)
+		assert.Equal(t, "rejecting", rejected.Subscriber)
+
+		// The rejecting subscriber is rolled back too, subscribers after it are never called
+		assert.Equal(t, []string{
+			"first debug",
+			"second debug",
+			"rejecting debug",
+			"rejecting info",
+			"second info",
+			"first info",
+		}, calls)
+		assert.Same(t, current, manager.Current())
+		assert.Equal(t, "info", manager.Current().Logging.Level)
+		assert.Nil(t, manager.LastReload())
+	})
+}
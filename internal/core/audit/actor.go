@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+)
+
+// Actor types
+const (
+	ActorUser       = "user"
+	ActorKiosk      = "kiosk"
+	ActorAutomation = "automation"
+	ActorAI         = "ai"
+	ActorAPIToken   = "api_token"
+	ActorSystem     = "system"
+	ActorAnonymous  = "anonymous"
+)
+
+// Actor is who or what caused an audited change
+type Actor struct {
+	Type     string `json:"type"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	SourceIP string `json:"source_ip,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context that attributes changes made with it to the actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by WithActor
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	if ctx == nil {
+		return Actor{}, false
+	}
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// actorForAction attributes a control action. An actor on the context wins over the action's
+// own context, which only tells the user, the trigger or the calling subsystem.
+func actorForAction(ctx context.Context, action types.PMAControlAction) Actor {
+	if actor, ok := ActorFromContext(ctx); ok {
+		return actor
+	}
+
+	pmaCtx := action.Context
+	if pmaCtx == nil {
+		return Actor{Type: ActorSystem}
+	}
+
+	source := strings.ToLower(pmaCtx.Source)
+	switch {
+	case pmaCtx.TriggerID != nil && *pmaCtx.TriggerID != "":
+		return Actor{Type: ActorAutomation, ID: *pmaCtx.TriggerID, Name: pmaCtx.Source}
+	case strings.Contains(source, "automation") || strings.Contains(source, "scheduler"):
+		return Actor{Type: ActorAutomation, Name: pmaCtx.Source}
+	case source == "ai" || strings.HasPrefix(source, "mcp"):
+		return Actor{Type: ActorAI, Name: pmaCtx.Source}
+	case strings.HasPrefix(source, "kiosk"):
+		return Actor{Type: ActorKiosk, Name: pmaCtx.Source}
+	case pmaCtx.UserID != nil && *pmaCtx.UserID != "":
+		return Actor{Type: ActorUser, ID: *pmaCtx.UserID}
+	default:
+		return Actor{Type: ActorSystem, Name: pmaCtx.Source}
+	}
+}
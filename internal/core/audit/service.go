@@ -0,0 +1,349 @@
+// Package audit keeps a tamper-evident log of control actions and configuration changes. Every
+// entry stores the hash of the previous one, so editing or deleting a row breaks the chain.
+// The head of the chain is only held in memory, see Service.Verify for what that leaves out.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Categories of audited changes
+const (
+	CategoryEntity     = "entity"
+	CategoryAutomation = "automation"
+	CategoryScene      = "scene"
+	CategoryArea       = "area"
+	CategoryUser       = "user"
+	CategoryConfig     = "config"
+	CategoryAuth       = "auth"
+	CategoryKiosk      = "kiosk"
+)
+
+// Outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Export formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// ErrUnsupportedFormat is returned for unknown export formats
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// errStopWalk ends a walk early without reporting an error
+var errStopWalk = errors.New("stop walk")
+
+// exportColumns is the header row of CSV exports
+var exportColumns = []string{
+	"id", "timestamp", "actor_type", "actor_id", "actor_name", "category", "action", "target",
+	"outcome", "error", "details", "source_ip", "prev_hash", "hash",
+}
+
+// Store persists the log; it is implemented by the audit repository
+type Store interface {
+	CreateEntry(ctx context.Context, entry *models.AuditEntry) error
+	GetLastEntry(ctx context.Context) (*models.AuditEntry, error)
+	ListEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error)
+	WalkEntries(ctx context.Context, filter models.AuditFilter, fn func(*models.AuditEntry) error) error
+}
+
+// VerifyResult is the outcome of a chain verification
+type VerifyResult struct {
+	Valid      bool      `json:"valid"`
+	Checked    int       `json:"checked"`
+	LastHash   string    `json:"last_hash,omitempty"` // Keep it elsewhere to detect later truncation
+	BrokenAt   int64     `json:"broken_at,omitempty"` // ID of the first entry that fails
+	Reason     string    `json:"reason,omitempty"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// Service records and verifies the audit log
+type Service struct {
+	store  Store
+	logger *logrus.Logger
+
+	// mu serializes appends so every entry links to its predecessor
+	mu       sync.Mutex
+	lastHash string
+}
+
+// NewService creates an audit service
+func NewService(store Store, logger *logrus.Logger) *Service {
+	return &Service{store: store, logger: logger}
+}
+
+// Load reads the head of the chain; call it before recording
+func (s *Service) Load(ctx context.Context) error {
+	last, err := s.store.GetLastEntry(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last != nil {
+		s.lastHash = last.Hash
+	}
+	return nil
+}
+
+// Record appends an entry. The actor is taken from the context when the entry has none.
+func (s *Service) Record(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.ActorType == "" {
+		actor, ok := ActorFromContext(ctx)
+		if !ok {
+			actor = Actor{Type: ActorSystem}
+		}
+		entry.ActorType = actor.Type
+		entry.ActorID = actor.ID
+		entry.ActorName = actor.Name
+		if entry.SourceIP == "" {
+			entry.SourceIP = actor.SourceIP
+		}
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeSuccess
+	}
+	if len(entry.Details) == 0 {
+		entry.Details = nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Stored timestamps keep nanoseconds in UTC, hash exactly what will be read back
+	entry.Timestamp = time.Now().UTC()
+	entry.PrevHash = s.lastHash
+	hash, err := hashEntry(entry)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	// Recording must not outlive a request that was cancelled after the change was made
+	if err := s.store.CreateEntry(context.WithoutCancel(ctx), entry); err != nil {
+		return err
+	}
+	s.lastHash = hash
+	return nil
+}
+
+// RecordAction records a control action executed through the unified entity service
+func (s *Service) RecordAction(ctx context.Context, action types.PMAControlAction, result *types.PMAControlResult, err error) {
+	actor := actorForAction(ctx, action)
+	entry := &models.AuditEntry{
+		ActorType: actor.Type,
+		ActorID:   actor.ID,
+		ActorName: actor.Name,
+		SourceIP:  actor.SourceIP,
+		Category:  CategoryEntity,
+		Action:    action.Action,
+		Target:    action.EntityID,
+		Outcome:   OutcomeSuccess,
+	}
+
+	details := map[string]interface{}{}
+	if len(action.Parameters) > 0 {
+		details["parameters"] = action.Parameters
+	}
+	if action.Context != nil {
+		details["source"] = action.Context.Source
+		if action.Context.ID != "" {
+			details["context_id"] = action.Context.ID
+		}
+		if action.Context.Description != "" {
+			details["description"] = action.Context.Description
+		}
+	}
+
+	switch {
+	case err != nil:
+		entry.Outcome = OutcomeFailure
+		entry.Error = err.Error()
+	case result == nil:
+		entry.Outcome = OutcomeFailure
+		entry.Error = "no result"
+	case !result.Success:
+		entry.Outcome = OutcomeFailure
+		if result.Error != nil {
+			entry.Error = result.Error.Message
+			details["error_code"] = result.Error.Code
+		}
+	default:
+		if result.NewState != "" {
+			details["new_state"] = result.NewState
+		}
+	}
+
+	if data, marshalErr := json.Marshal(details); marshalErr == nil {
+		entry.Details = data
+	} else {
+		s.logger.WithError(marshalErr).WithField("entity_id", action.EntityID).Warn("Failed to encode audit details")
+	}
+
+	if recordErr := s.Record(ctx, entry); recordErr != nil {
+		s.logger.WithError(recordErr).WithFields(logrus.Fields{
+			"entity_id": action.EntityID,
+			"action":    action.Action,
+		}).Error("Failed to record audit entry")
+	}
+}
+
+// Query returns entries matching the filter, newest first, with the total match count
+func (s *Service) Query(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	return s.store.ListEntries(ctx, filter)
+}
+
+// Verify walks the whole chain and reports the first entry whose link or hash does not match.
+//
+// Removing entries from the end leaves a valid chain. That is only detected against the head
+// this process recorded or loaded at startup: rows truncated while the server is stopped go
+// unnoticed. Comparing LastHash with a copy kept outside the database closes that gap.
+func (s *Service) Verify(ctx context.Context) (*VerifyResult, error) {
+	s.mu.Lock()
+	head := s.lastHash
+	s.mu.Unlock()
+
+	result := &VerifyResult{Valid: true}
+	prevHash := ""
+	headSeen := head == ""
+
+	err := s.store.WalkEntries(ctx, models.AuditFilter{}, func(entry *models.AuditEntry) error {
+		result.Checked++
+
+		if entry.PrevHash != prevHash {
+			result.fail(entry.ID, "previous hash does not match, an entry was removed or reordered")
+			return errStopWalk
+		}
+		hash, err := hashEntry(entry)
+		if err != nil {
+			result.fail(entry.ID, err.Error())
+			return errStopWalk
+		}
+		if hash != entry.Hash {
+			result.fail(entry.ID, "hash does not match, the entry was modified")
+			return errStopWalk
+		}
+
+		prevHash = entry.Hash
+		if entry.Hash == head {
+			headSeen = true
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, err
+	}
+
+	if result.Valid && !headSeen {
+		result.Valid = false
+		result.Reason = "log ends before the last recorded entry, entries were removed from the end"
+	}
+	result.LastHash = prevHash
+	result.VerifiedAt = time.Now()
+	return result, nil
+}
+
+func (r *VerifyResult) fail(id int64, reason string) {
+	r.Valid = false
+	r.BrokenAt = id
+	r.Reason = reason
+}
+
+// Export writes the entries matching the filter, oldest first, as CSV or JSON lines
+func (s *Service) Export(ctx context.Context, filter models.AuditFilter, format string, w io.Writer) error {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportColumns); err != nil {
+			return err
+		}
+		err := s.store.WalkEntries(ctx, filter, func(entry *models.AuditEntry) error {
+			return writer.Write([]string{
+				strconv.FormatInt(entry.ID, 10),
+				entry.Timestamp.UTC().Format(time.RFC3339Nano),
+				entry.ActorType,
+				entry.ActorID,
+				entry.ActorName,
+				entry.Category,
+				entry.Action,
+				entry.Target,
+				entry.Outcome,
+				entry.Error,
+				string(entry.Details),
+				entry.SourceIP,
+				entry.PrevHash,
+				entry.Hash,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		return s.store.WalkEntries(ctx, filter, func(entry *models.AuditEntry) error {
+			return encoder.Encode(entry)
+		})
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// chainedFields is the canonical form of an entry that is hashed
+type chainedFields struct {
+	PrevHash  string          `json:"prev_hash"`
+	Timestamp string          `json:"timestamp"`
+	ActorType string          `json:"actor_type"`
+	ActorID   string          `json:"actor_id"`
+	ActorName string          `json:"actor_name"`
+	Category  string          `json:"category"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Outcome   string          `json:"outcome"`
+	Error     string          `json:"error"`
+	Details   json.RawMessage `json:"details"`
+	SourceIP  string          `json:"source_ip"`
+}
+
+func hashEntry(entry *models.AuditEntry) (string, error) {
+	data, err := json.Marshal(chainedFields{
+		PrevHash:  entry.PrevHash,
+		Timestamp: entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		ActorType: entry.ActorType,
+		ActorID:   entry.ActorID,
+		ActorName: entry.ActorName,
+		Category:  entry.Category,
+		Action:    entry.Action,
+		Target:    entry.Target,
+		Outcome:   entry.Outcome,
+		Error:     entry.Error,
+		Details:   entry.Details,
+		SourceIP:  entry.SourceIP,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
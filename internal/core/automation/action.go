@@ -10,6 +10,8 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
 )
 
 // Action interface defines action behavior
@@ -153,6 +155,14 @@ type NotificationAction struct {
 	Message string                 `json:"message"`
 	Target  string                 `json:"target,omitempty"` // websocket, email, push, etc.
 	Data    map[string]interface{} `json:"data,omitempty"`
+
+	// AttachSnapshot is a camera entity ID whose latest archived snapshot is attached
+	AttachSnapshot string `json:"attach_snapshot,omitempty"`
+
+	// Delivery through the notification service
+	Priority string                     `json:"priority,omitempty"` // low, medium, high, critical
+	UserID   string                     `json:"user_id,omitempty"`  // Empty notifies every user
+	Actions  []types.NotificationAction `json:"actions,omitempty"`  // Responses fire notification_action events
 }
 
 func NewNotificationAction(id, title, message string) *NotificationAction {
@@ -191,6 +201,23 @@ func (na *NotificationAction) Execute(ctx context.Context, data map[string]inter
 		"data":    na.Data,
 	}
 
+	if na.AttachSnapshot != "" {
+		cameraEntityID, _ := processStringTemplate(na.AttachSnapshot, data)
+		if provider := snapshotProviderFromContext(ctx); provider != nil {
+			attachment, err := provider.LatestSnapshot(ctx, cameraEntityID)
+			if err != nil {
+				return fmt.Errorf("failed to attach snapshot of %s: %v", cameraEntityID, err)
+			}
+			if attachment != nil {
+				notification["attachments"] = []*SnapshotAttachment{attachment}
+			}
+		}
+	}
+
+	if notifier := notifierFromContext(ctx); notifier != nil {
+		return notifier.Notify(ctx, na.buildNotification(title, message, notification, data), na.channels())
+	}
+
 	// Send notification based on target
 	switch na.Target {
 	case "websocket":
@@ -204,6 +231,48 @@ func (na *NotificationAction) Execute(ctx context.Context, data map[string]inter
 	return nil
 }
 
+// buildNotification converts the rendered action into a notification for the notification service
+func (na *NotificationAction) buildNotification(title, message string, rendered, data map[string]interface{}) *types.AINotification {
+	notificationData := make(map[string]interface{}, len(na.Data)+1)
+	for key, value := range na.Data {
+		notificationData[key] = value
+	}
+	if attachments, exists := rendered["attachments"]; exists {
+		notificationData["attachments"] = attachments
+	}
+
+	userID, _ := processStringTemplate(na.UserID, data)
+
+	priority := types.NotificationPriority(na.Priority)
+	if priority == "" {
+		priority = types.NotificationPriorityMedium
+	}
+
+	return &types.AINotification{
+		Type:      types.NotificationTypeAutomationTriggered,
+		Title:     title,
+		Message:   message,
+		Priority:  priority,
+		Timestamp: time.Now(),
+		Data:      notificationData,
+		Actions:   na.Actions,
+		Source:    "automation",
+		UserID:    userID,
+	}
+}
+
+// channels returns the external channels named by Target (comma separated). WebSocket delivery
+// always happens, so a plain "websocket" target leaves the choice to the recipients' preferences.
+func (na *NotificationAction) channels() []string {
+	var channels []string
+	for _, channel := range strings.Split(na.Target, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" && channel != "websocket" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
 func (na *NotificationAction) Clone() Action {
 	data, _ := json.Marshal(na)
 	var clone NotificationAction
@@ -775,6 +844,27 @@ func (af *ActionFactory) CreateAction(config map[string]interface{}) (Action, er
 		if target, exists := config["target"].(string); exists {
 			action.Target = target
 		}
+		if camera, exists := config["attach_snapshot"].(string); exists {
+			action.AttachSnapshot = camera
+		}
+		if priority, exists := config["priority"].(string); exists {
+			action.Priority = priority
+		}
+		if userID, exists := config["user_id"].(string); exists {
+			action.UserID = userID
+		}
+		if data, exists := config["data"].(map[string]interface{}); exists {
+			action.Data = data
+		}
+		if actions, exists := config["actions"]; exists {
+			encoded, err := json.Marshal(actions)
+			if err == nil {
+				err = json.Unmarshal(encoded, &action.Actions)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid notification actions: %v", err)
+			}
+		}
 		return action, nil
 
 	case ActionTypeDelay:
@@ -541,6 +541,89 @@ variables:
 	assert.Equal(t, 255, rule.Variables["brightness"])
 }
 
+func TestHAImporter_Import(t *testing.T) {
+	automations := `
+- id: "1700000000001"
+  alias: Hallway light at night
+  mode: restart
+  triggers:
+    - trigger: state
+      entity_id: [binary_sensor.hall_motion, binary_sensor.stairs_motion]
+      to: "on"
+    - trigger: numeric_state
+      entity_id: sensor.hall_lux
+      below: 10
+  conditions:
+    - condition: time
+      after: "22:00:00"
+      before: "06:00:00"
+  actions:
+    - action: light.turn_on
+      target:
+        entity_id: light.hallway
+      data:
+        brightness_pct: 30
+    - delay: "00:02:00"
+    - choose: []
+- id: "1700000000002"
+  alias: Only templates
+  triggers:
+    - platform: template
+      value_template: "{{ true }}"
+  actions:
+    - service: light.turn_off
+      entity_id: light.hallway
+`
+
+	items, err := ParseHAConfig([]byte(automations))
+	require.NoError(t, err)
+
+	importer := NewHAImporter(func(haEntityID string) (string, bool) {
+		return "ha_" + haEntityID, haEntityID != "light.hallway"
+	})
+	report := importer.Import(HAImportAutomation, items)
+	require.Len(t, report.Items, 2)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, 1, report.Partial)
+	assert.Equal(t, 1, report.Failed)
+
+	partial := report.Items[0]
+	assert.Equal(t, HAImportStatusPartial, partial.Status)
+	assert.Equal(t, "ha_automation_1700000000001", partial.RuleID)
+	assert.Len(t, partial.Unsupported, 3) // restart mode, numeric_state trigger, choose
+	assert.Equal(t, []string{"light.hallway"}, partial.UnmappedEntities)
+	require.NotNil(t, partial.Rule)
+	assert.False(t, partial.Rule.Enabled)
+	require.Len(t, partial.Rule.Triggers, 2)
+	assert.Equal(t, "ha_binary_sensor.stairs_motion", partial.Rule.Triggers[1].(*StateTrigger).EntityID)
+	assert.Len(t, partial.Rule.Conditions, 1)
+	require.Len(t, partial.Rule.Actions, 2)
+	assert.Equal(t, "2m0s", partial.Rule.Actions[1].(*DelayAction).Duration)
+
+	failed := report.Items[1]
+	assert.Equal(t, HAImportStatusFailed, failed.Status)
+	assert.Equal(t, "no supported triggers", failed.Error)
+	assert.Nil(t, failed.Rule)
+
+	scenes := `
+- id: "movie"
+  name: Movie time
+  entities:
+    light.living_room:
+      state: "on"
+      brightness: 40
+    cover.blinds: closed
+`
+	items, err = ParseHAConfig([]byte(scenes))
+	require.NoError(t, err)
+	report = importer.Import(HAImportScene, items)
+	require.Len(t, report.Items, 1)
+	assert.Equal(t, HAImportStatusImported, report.Items[0].Status)
+	require.Len(t, report.Items[0].Rule.Actions, 2)
+	assert.Equal(t, "cover.close_cover", report.Items[0].Rule.Actions[0].(*ServiceAction).Service)
+	assert.Equal(t, "ha_light.living_room", report.Items[0].Rule.Actions[1].(*ServiceAction).EntityID)
+}
+
 func TestExecutionContext(t *testing.T) {
 	logger := logrus.New()
 	ctx := NewExecutionContext(context.Background(), "test-rule", "test-trigger", logger)
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/core/audit"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
 	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
 	"github.com/google/uuid"
@@ -30,9 +31,14 @@ type AutomationEngine struct {
 	contextManager *ExecutionContextManager
 
 	// External dependencies
-	unifiedService *unified.UnifiedEntityService
-	wsHub          *websocket.Hub
-	logger         *logrus.Logger
+	unifiedService   *unified.UnifiedEntityService
+	wsHub            *websocket.Hub
+	snapshotProvider SnapshotProvider
+	notifier         Notifier
+	logger           *logrus.Logger
+
+	// Listeners notified after a rule ran
+	executionListeners []func(RuleExecution)
 
 	// Execution management
 	executionQueue chan *ExecutionRequest
@@ -144,6 +150,48 @@ func NewAutomationEngine(config *EngineConfig, unifiedService *unified.UnifiedEn
 	return engine, nil
 }
 
+// SetSnapshotProvider enables notification actions to attach camera snapshots
+func (ae *AutomationEngine) SetSnapshotProvider(provider SnapshotProvider) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+	ae.snapshotProvider = provider
+}
+
+// SetNotifier routes notification actions through a notification service
+func (ae *AutomationEngine) SetNotifier(notifier Notifier) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+	ae.notifier = notifier
+}
+
+// OnRuleExecuted registers a listener notified after each rule run. Runs whose conditions are
+// not met are not reported.
+func (ae *AutomationEngine) OnRuleExecuted(listener func(RuleExecution)) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+	ae.executionListeners = append(ae.executionListeners, listener)
+}
+
+// notifyRuleExecuted dispatches a finished run to the execution listeners
+func (ae *AutomationEngine) notifyRuleExecuted(execution RuleExecution) {
+	ae.mu.RLock()
+	listeners := make([]func(RuleExecution), len(ae.executionListeners))
+	copy(listeners, ae.executionListeners)
+	ae.mu.RUnlock()
+
+	for _, listener := range listeners {
+		go listener(execution)
+	}
+}
+
+// FireEvent dispatches an event to the triggers of all enabled rules
+func (ae *AutomationEngine) FireEvent(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	ae.handleEvent(event)
+}
+
 // Start starts the automation engine
 func (ae *AutomationEngine) Start(ctx context.Context) error {
 	ae.mu.Lock()
@@ -587,7 +635,7 @@ func (ae *AutomationEngine) handleEvent(event Event) {
 }
 
 // executeRule executes a rule
-func (ae *AutomationEngine) executeRule(execCtx *ExecutionContext, rule *AutomationRule, event Event) error {
+func (ae *AutomationEngine) executeRule(execCtx *ExecutionContext, rule *AutomationRule, event Event) (err error) {
 	start := time.Now()
 
 	ae.logger.WithFields(logrus.Fields{
@@ -619,6 +667,30 @@ func (ae *AutomationEngine) executeRule(execCtx *ExecutionContext, rule *Automat
 	// Update rule status
 	rule.Status = RuleStatusRunning
 
+	conditionsMet := true
+	defer func() {
+		if conditionsMet {
+			end := time.Now()
+			execution := RuleExecution{
+				ID:        execCtx.ID,
+				RuleID:    rule.ID,
+				StartTime: start,
+				EndTime:   &end,
+				Success:   err == nil,
+				Context: map[string]interface{}{
+					"rule_name":      rule.Name,
+					"trigger_type":   event.Type,
+					"trigger_source": event.Source,
+				},
+				Duration: end.Sub(start),
+			}
+			if err != nil {
+				execution.Error = err.Error()
+			}
+			ae.notifyRuleExecuted(execution)
+		}
+	}()
+
 	defer func() {
 		rule.Status = RuleStatusIdle
 		rule.LastRun = &start
@@ -657,15 +729,21 @@ func (ae *AutomationEngine) executeRule(execCtx *ExecutionContext, rule *Automat
 				"rule_id":      rule.ID,
 				"condition_id": condition.GetID(),
 			}).Debug("Rule condition not met")
+			conditionsMet = false
 			return nil // Conditions not met, but not an error
 		}
 	}
 
 	// Execute actions
+	ae.mu.RLock()
+	actionCtx := withSnapshotProvider(execCtx.Context(), ae.snapshotProvider)
+	actionCtx = withNotifier(actionCtx, ae.notifier)
+	ae.mu.RUnlock()
+
 	for i, action := range rule.Actions {
 		actionStart := time.Now()
 
-		err := action.Execute(execCtx.Context(), execCtx.GetAllVariables())
+		err := action.Execute(actionCtx, execCtx.GetAllVariables())
 		actionDuration := time.Since(actionStart)
 
 		execCtx.AddTrace("action", action.GetID(), fmt.Sprintf("action_%d", i), err == nil, actionDuration, err, nil)
@@ -795,8 +873,9 @@ func (w *worker) processRequest(request *ExecutionRequest) {
 	execCtx := w.engine.contextManager.CreateContext(request.Context, request.RuleID, request.TriggerID)
 	defer w.engine.contextManager.RemoveContext(execCtx.ID)
 
-	// Execute rule with timeout
-	ctx, cancel := context.WithTimeout(request.Context, w.engine.config.ExecutionTimeout)
+	// Execute rule with timeout, attributing its actions to the rule
+	ruleCtx := audit.WithActor(request.Context, audit.Actor{Type: audit.ActorAutomation, ID: rule.ID, Name: rule.Name})
+	ctx, cancel := context.WithTimeout(ruleCtx, w.engine.config.ExecutionTimeout)
 	defer cancel()
 
 	execCtx = w.engine.contextManager.CreateContext(ctx, request.RuleID, request.TriggerID)
@@ -0,0 +1,839 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HAImportKind is the kind of Home Assistant configuration being imported
+type HAImportKind string
+
+const (
+	HAImportAutomation HAImportKind = "automation"
+	HAImportScript     HAImportKind = "script"
+	HAImportScene      HAImportKind = "scene"
+)
+
+// Imported scripts and scenes run on these events. The event data carries script_id or scene_id
+// with the Home Assistant ID of the script or scene.
+const (
+	EventTypeScriptRun     = "script.run"
+	EventTypeSceneActivate = "scene.activate"
+)
+
+// HAImportStatus is the outcome of importing one item
+type HAImportStatus string
+
+const (
+	HAImportStatusImported HAImportStatus = "imported"
+	HAImportStatusPartial  HAImportStatus = "partial" // converted without its unsupported parts, imported disabled
+	HAImportStatusFailed   HAImportStatus = "failed"
+)
+
+// HAImportItem reports how one automation, script or scene was converted
+type HAImportItem struct {
+	Kind             HAImportKind    `json:"kind"`
+	SourceID         string          `json:"source_id,omitempty"`
+	Name             string          `json:"name"`
+	Status           HAImportStatus  `json:"status"`
+	RuleID           string          `json:"rule_id,omitempty"`
+	Unsupported      []string        `json:"unsupported,omitempty"`
+	UnmappedEntities []string        `json:"unmapped_entities,omitempty"`
+	Error            string          `json:"error,omitempty"`
+	Rule             *AutomationRule `json:"-"`
+}
+
+// Fail marks the item as failed
+func (i *HAImportItem) Fail(err error) {
+	i.Status = HAImportStatusFailed
+	i.Error = err.Error()
+	i.Rule = nil
+}
+
+func (i *HAImportItem) unsupported(format string, args ...interface{}) {
+	i.Unsupported = append(i.Unsupported, fmt.Sprintf(format, args...))
+}
+
+// HAImportReport is the per-item result of an import
+type HAImportReport struct {
+	Items    []*HAImportItem `json:"items"`
+	Imported int             `json:"imported"`
+	Partial  int             `json:"partial"`
+	Failed   int             `json:"failed"`
+}
+
+// Summarize recounts the items by status
+func (r *HAImportReport) Summarize() {
+	r.Imported, r.Partial, r.Failed = 0, 0, 0
+	for _, item := range r.Items {
+		switch item.Status {
+		case HAImportStatusImported:
+			r.Imported++
+		case HAImportStatusPartial:
+			r.Partial++
+		case HAImportStatusFailed:
+			r.Failed++
+		}
+	}
+}
+
+// EntityMapper maps a Home Assistant entity ID to a PMA entity ID. It returns its best guess
+// and whether the entity is known.
+type EntityMapper func(haEntityID string) (string, bool)
+
+// HAImporter converts Home Assistant automations, scripts and scenes into automation rules.
+// Triggers, conditions and actions that have no PMA equivalent are left out and reported;
+// rules missing parts are disabled so they can be reviewed before they run.
+type HAImporter struct {
+	parser    *RuleParser
+	mapEntity EntityMapper
+}
+
+// NewHAImporter creates an importer that maps entity IDs with mapEntity
+func NewHAImporter(mapEntity EntityMapper) *HAImporter {
+	return &HAImporter{
+		parser:    NewRuleParser(),
+		mapEntity: mapEntity,
+	}
+}
+
+// ParseHAConfig decodes automations.yaml, scripts.yaml or scenes.yaml. Both lists of items and
+// maps keyed by ID (the scripts.yaml layout) are accepted.
+func ParseHAConfig(data []byte) ([]map[string]interface{}, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %v", err)
+	}
+
+	// Round-trip through JSON so values have the types the rule parser expects
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported YAML content: %v", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch value := decoded.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		items := make([]map[string]interface{}, 0, len(value))
+		for i, entry := range value {
+			item, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item %d must be an object", i)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case map[string]interface{}:
+		ids := make([]string, 0, len(value))
+		for id := range value {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		items := make([]map[string]interface{}, 0, len(value))
+		for _, id := range ids {
+			item, ok := value[id].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item %s must be an object", id)
+			}
+			if _, hasID := item["id"]; !hasID {
+				item["id"] = id
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("expected a list or map of items")
+	}
+}
+
+// Import converts items of one kind. Every item is reported; failing items do not stop the import.
+func (im *HAImporter) Import(kind HAImportKind, items []map[string]interface{}) *HAImportReport {
+	report := &HAImportReport{Items: make([]*HAImportItem, 0, len(items))}
+	for _, raw := range items {
+		item := &HAImportItem{Kind: kind}
+		item.SourceID = stringValue(raw["id"])
+
+		switch kind {
+		case HAImportAutomation:
+			item.Name = firstString(raw["alias"], raw["id"])
+			im.convertAutomation(item, raw)
+		case HAImportScript:
+			item.Name = firstString(raw["alias"], raw["id"])
+			im.convertScript(item, raw)
+		case HAImportScene:
+			item.Name = firstString(raw["name"], raw["id"])
+			im.convertScene(item, raw)
+		default:
+			item.Fail(fmt.Errorf("unsupported import kind: %s", kind))
+		}
+
+		report.Items = append(report.Items, item)
+	}
+	report.Summarize()
+	return report
+}
+
+// convertAutomation converts an automation with its triggers, conditions and actions
+func (im *HAImporter) convertAutomation(item *HAImportItem, raw map[string]interface{}) {
+	rule := im.ruleMap(item, raw)
+
+	var triggers []interface{}
+	for i, trigger := range listValue(firstPresent(raw, "triggers", "trigger")) {
+		triggers = append(triggers, im.convertTrigger(item, i, trigger)...)
+	}
+	rule["triggers"] = triggers
+
+	var conditions []interface{}
+	for i, condition := range listValue(firstPresent(raw, "conditions", "condition")) {
+		if converted := im.convertCondition(item, fmt.Sprintf("condition %d", i), condition); converted != nil {
+			conditions = append(conditions, converted)
+		}
+	}
+	if len(conditions) > 0 {
+		rule["conditions"] = conditions
+	}
+
+	rule["actions"] = im.convertActions(item, listValue(firstPresent(raw, "actions", "action")))
+	im.finish(item, rule)
+}
+
+// convertScript converts a script into a rule run by script.run events
+func (im *HAImporter) convertScript(item *HAImportItem, raw map[string]interface{}) {
+	rule := im.ruleMap(item, raw)
+	rule["triggers"] = []interface{}{map[string]interface{}{
+		"id":         "script_run",
+		"type":       string(TriggerTypeEvent),
+		"event_type": EventTypeScriptRun,
+		"event_data": map[string]interface{}{"script_id": item.SourceID},
+	}}
+	if _, ok := raw["fields"]; ok {
+		item.unsupported("script fields")
+	}
+	rule["actions"] = im.convertActions(item, listValue(raw["sequence"]))
+	im.finish(item, rule)
+}
+
+// convertScene converts a scene into a rule run by scene.activate events that sets every entity
+// of the scene to its stored state
+func (im *HAImporter) convertScene(item *HAImportItem, raw map[string]interface{}) {
+	rule := im.ruleMap(item, raw)
+	rule["triggers"] = []interface{}{map[string]interface{}{
+		"id":         "scene_activate",
+		"type":       string(TriggerTypeEvent),
+		"event_type": EventTypeSceneActivate,
+		"event_data": map[string]interface{}{"scene_id": item.SourceID},
+	}}
+
+	entities, _ := raw["entities"].(map[string]interface{})
+	entityIDs := make([]string, 0, len(entities))
+	for entityID := range entities {
+		entityIDs = append(entityIDs, entityID)
+	}
+	sort.Strings(entityIDs)
+
+	var actions []interface{}
+	for _, entityID := range entityIDs {
+		data := map[string]interface{}{}
+		var state string
+		switch value := entities[entityID].(type) {
+		case map[string]interface{}:
+			state = stringValue(value["state"])
+			for key, attribute := range value {
+				if key != "state" {
+					data[key] = attribute
+				}
+			}
+		default:
+			state = stringValue(value)
+		}
+
+		domain := strings.SplitN(entityID, ".", 2)[0]
+		service, ok := sceneService(domain, state)
+		if !ok {
+			item.unsupported("scene state %q of %s", state, entityID)
+			continue
+		}
+
+		action := map[string]interface{}{
+			"id":        fmt.Sprintf("scene_%s", strings.ReplaceAll(entityID, ".", "_")),
+			"service":   service,
+			"entity_id": entityID,
+		}
+		if service == domain+".turn_on" && len(data) > 0 {
+			action["data"] = data
+		}
+		actions = append(actions, action)
+	}
+	rule["actions"] = actions
+	im.finish(item, rule)
+}
+
+// sceneService returns the service that puts an entity of domain into state
+func sceneService(domain, state string) (string, bool) {
+	switch state {
+	case "on":
+		return domain + ".turn_on", true
+	case "off":
+		return domain + ".turn_off", true
+	}
+
+	switch {
+	case domain == "cover" && state == "open":
+		return "cover.open_cover", true
+	case domain == "cover" && state == "closed":
+		return "cover.close_cover", true
+	case domain == "lock" && state == "locked":
+		return "lock.lock", true
+	case domain == "lock" && state == "unlocked":
+		return "lock.unlock", true
+	}
+	return "", false
+}
+
+// ruleMap returns the common fields of the rule converted from raw
+func (im *HAImporter) ruleMap(item *HAImportItem, raw map[string]interface{}) map[string]interface{} {
+	key := item.SourceID
+	if key == "" {
+		key = item.Name
+	}
+	item.RuleID = fmt.Sprintf("ha_%s_%s", item.Kind, slug(key))
+
+	rule := map[string]interface{}{
+		"id":      item.RuleID,
+		"name":    item.Name,
+		"enabled": true,
+		"mode":    string(ExecutionModeSingle),
+	}
+	if item.Name == "" {
+		rule["name"] = item.RuleID
+		item.Name = item.RuleID
+	}
+	if description := stringValue(raw["description"]); description != "" {
+		rule["description"] = description
+	}
+	if variables, ok := raw["variables"].(map[string]interface{}); ok {
+		rule["variables"] = variables
+	}
+
+	switch mode := stringValue(raw["mode"]); mode {
+	case "", string(ExecutionModeSingle):
+	case string(ExecutionModeQueued), string(ExecutionModeParallel):
+		rule["mode"] = mode
+	default:
+		item.unsupported("mode %q, imported as single", mode)
+	}
+	if _, ok := raw["max"]; ok {
+		item.unsupported("max runs")
+	}
+	return rule
+}
+
+// finish parses the converted rule and sets the status of the item
+func (im *HAImporter) finish(item *HAImportItem, ruleMap map[string]interface{}) {
+	if triggers, _ := ruleMap["triggers"].([]interface{}); len(triggers) == 0 {
+		item.Fail(fmt.Errorf("no supported triggers"))
+		return
+	}
+	if actions, _ := ruleMap["actions"].([]interface{}); len(actions) == 0 {
+		item.Fail(fmt.Errorf("no supported actions"))
+		return
+	}
+
+	ruleMap["triggers"] = im.mapEntities(item, ruleMap["triggers"])
+	if conditions, ok := ruleMap["conditions"]; ok {
+		ruleMap["conditions"] = im.mapEntities(item, conditions)
+	}
+	ruleMap["actions"] = im.mapEntities(item, ruleMap["actions"])
+
+	rule, err := im.parser.parseFromMap(ruleMap)
+	if err != nil {
+		item.Fail(err)
+		return
+	}
+	if validation := rule.Validate(); !validation.Valid {
+		messages := make([]string, 0, len(validation.Errors))
+		for _, validationError := range validation.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", validationError.Field, validationError.Message))
+		}
+		item.Fail(fmt.Errorf("invalid rule: %s", strings.Join(messages, "; ")))
+		return
+	}
+
+	rule.Category = "home_assistant"
+	rule.Tags = []string{"imported", string(item.Kind)}
+	item.Rule = rule
+	item.Status = HAImportStatusImported
+	if len(item.Unsupported) > 0 {
+		rule.Enabled = false
+		item.Status = HAImportStatusPartial
+	}
+}
+
+// convertTrigger returns the triggers equivalent to a Home Assistant trigger. A state trigger
+// on several entities becomes one trigger per entity.
+func (im *HAImporter) convertTrigger(item *HAImportItem, index int, value interface{}) []interface{} {
+	trigger, ok := value.(map[string]interface{})
+	if !ok {
+		item.unsupported("trigger %d: not an object", index)
+		return nil
+	}
+	platform := firstString(trigger["platform"], trigger["trigger"])
+	if enabled, ok := trigger["enabled"].(bool); ok && !enabled {
+		item.unsupported("trigger %d: disabled %s trigger", index, platform)
+		return nil
+	}
+
+	allowed := map[string][]string{
+		"state":   {"entity_id", "from", "to", "attribute"},
+		"time":    {"at"},
+		"sun":     {"event", "offset"},
+		"webhook": {"webhook_id", "allowed_methods", "local_only"},
+	}
+	keys, supported := allowed[platform]
+	if !supported {
+		item.unsupported("trigger %d: %s trigger", index, platform)
+		return nil
+	}
+	if extra := unknownKeys(trigger, append(keys, "platform", "trigger", "id", "alias", "enabled")); len(extra) > 0 {
+		item.unsupported("trigger %d: %s trigger option %s", index, platform, strings.Join(extra, ", "))
+		return nil
+	}
+
+	base := map[string]interface{}{"platform": platform}
+	for _, key := range keys {
+		if value, ok := trigger[key]; ok {
+			base[key] = value
+		}
+	}
+	delete(base, "allowed_methods")
+	delete(base, "local_only")
+
+	id := stringValue(trigger["id"])
+	if id == "" {
+		id = fmt.Sprintf("trigger_%d", index)
+	}
+
+	switch platform {
+	case "time":
+		if _, ok := base["at"].(string); !ok {
+			item.unsupported("trigger %d: time trigger at entities or several times", index)
+			return nil
+		}
+	case "sun":
+		if offset, ok := base["offset"]; ok {
+			duration, ok := haDuration(offset)
+			if !ok {
+				item.unsupported("trigger %d: sun trigger offset %v", index, offset)
+				return nil
+			}
+			base["offset"] = duration
+		}
+	case "state":
+		for _, key := range []string{"from", "to"} {
+			if _, isList := base[key].([]interface{}); isList {
+				item.unsupported("trigger %d: state trigger with several %s states", index, key)
+				return nil
+			}
+		}
+		entityIDs := stringList(base["entity_id"])
+		if len(entityIDs) == 0 {
+			item.unsupported("trigger %d: state trigger without entity_id", index)
+			return nil
+		}
+		var triggers []interface{}
+		for i, entityID := range entityIDs {
+			converted := copyMap(base)
+			converted["entity_id"] = entityID
+			converted["id"] = id
+			if len(entityIDs) > 1 {
+				converted["id"] = fmt.Sprintf("%s_%d", id, i)
+			}
+			triggers = append(triggers, converted)
+		}
+		return triggers
+	}
+
+	base["id"] = id
+	return []interface{}{base}
+}
+
+// convertCondition returns the equivalent condition, or nil when it is not supported
+func (im *HAImporter) convertCondition(item *HAImportItem, label string, value interface{}) interface{} {
+	// Shorthand template condition
+	if template, ok := value.(string); ok {
+		return map[string]interface{}{"condition": "template", "value_template": template}
+	}
+
+	condition, ok := value.(map[string]interface{})
+	if !ok {
+		item.unsupported("%s: not an object", label)
+		return nil
+	}
+	conditionType := stringValue(condition["condition"])
+	if enabled, ok := condition["enabled"].(bool); ok && !enabled {
+		item.unsupported("%s: disabled %s condition", label, conditionType)
+		return nil
+	}
+
+	allowed := map[string][]string{
+		"state":         {"entity_id", "state", "attribute"},
+		"time":          {"before", "after", "weekday"},
+		"numeric_state": {"entity_id", "above", "below", "attribute"},
+		"template":      {"value_template"},
+		"and":           {"conditions"},
+		"or":            {"conditions"},
+	}
+	keys, supported := allowed[conditionType]
+	if !supported {
+		item.unsupported("%s: %s condition", label, conditionType)
+		return nil
+	}
+	if extra := unknownKeys(condition, append(keys, "condition", "alias", "enabled")); len(extra) > 0 {
+		item.unsupported("%s: %s condition option %s", label, conditionType, strings.Join(extra, ", "))
+		return nil
+	}
+
+	converted := map[string]interface{}{"condition": conditionType}
+	for _, key := range keys {
+		if value, ok := condition[key]; ok {
+			converted[key] = value
+		}
+	}
+
+	switch conditionType {
+	case "state", "numeric_state":
+		if conditionType == "numeric_state" {
+			for _, key := range []string{"above", "below"} {
+				if value, ok := converted[key]; ok {
+					if _, isNumber := value.(float64); !isNumber {
+						item.unsupported("%s: numeric_state %s an entity", label, key)
+						return nil
+					}
+				}
+			}
+		}
+
+		// Conditions on several entities hold when all of them match
+		entityIDs := stringList(converted["entity_id"])
+		if len(entityIDs) == 0 {
+			item.unsupported("%s: %s condition without entity_id", label, conditionType)
+			return nil
+		}
+		if conditionType == "state" {
+			if _, isList := converted["state"].([]interface{}); isList {
+				item.unsupported("%s: state condition with several states", label)
+				return nil
+			}
+		}
+		if len(entityIDs) == 1 {
+			converted["entity_id"] = entityIDs[0]
+			return converted
+		}
+		var all []interface{}
+		for _, entityID := range entityIDs {
+			single := copyMap(converted)
+			single["entity_id"] = entityID
+			all = append(all, single)
+		}
+		return map[string]interface{}{"condition": "and", "conditions": all}
+
+	case "time":
+		if weekday, ok := converted["weekday"].(string); ok {
+			converted["weekday"] = []interface{}{weekday}
+		}
+		for _, key := range []string{"before", "after"} {
+			if value, ok := converted[key].(string); ok && strings.Contains(value, ".") {
+				item.unsupported("%s: time condition %s an entity", label, key)
+				return nil
+			}
+		}
+
+	case "and", "or":
+		// Leaving out part of a composite condition changes its meaning, so it is all or nothing
+		var subConditions []interface{}
+		for i, sub := range listValue(converted["conditions"]) {
+			before := len(item.Unsupported)
+			subCondition := im.convertCondition(item, fmt.Sprintf("%s.%d", label, i), sub)
+			if subCondition == nil || len(item.Unsupported) > before {
+				return nil
+			}
+			subConditions = append(subConditions, subCondition)
+		}
+		converted["conditions"] = subConditions
+	}
+
+	return converted
+}
+
+// convertActions returns the supported actions of a sequence
+func (im *HAImporter) convertActions(item *HAImportItem, sequence []interface{}) []interface{} {
+	var actions []interface{}
+	for i, value := range sequence {
+		if converted := im.convertAction(item, i, value); converted != nil {
+			actions = append(actions, converted)
+		}
+	}
+	return actions
+}
+
+// convertAction returns the equivalent action, or nil when it is not supported
+func (im *HAImporter) convertAction(item *HAImportItem, index int, value interface{}) interface{} {
+	action, ok := value.(map[string]interface{})
+	if !ok {
+		item.unsupported("action %d: not an object", index)
+		return nil
+	}
+	if enabled, ok := action["enabled"].(bool); ok && !enabled {
+		item.unsupported("action %d: disabled action", index)
+		return nil
+	}
+	id := fmt.Sprintf("action_%d", index)
+
+	// Service calls use "service" before Home Assistant 2024.8 and "action" after
+	if service := firstString(action["action"], action["service"]); service != "" {
+		if extra := unknownKeys(action, []string{"action", "service", "entity_id", "data", "data_template", "target", "alias", "enabled"}); len(extra) > 0 {
+			item.unsupported("action %d: %s option %s", index, service, strings.Join(extra, ", "))
+			return nil
+		}
+
+		converted := map[string]interface{}{"id": id, "service": service}
+		if data, ok := firstPresent(action, "data", "data_template").(map[string]interface{}); ok {
+			converted["data"] = data
+		}
+		target, _ := action["target"].(map[string]interface{})
+		switch entityID := action["entity_id"].(type) {
+		case string:
+			converted["entity_id"] = entityID
+		case []interface{}:
+			if target == nil {
+				target = map[string]interface{}{}
+			}
+			target["entity_id"] = entityID
+		}
+		if target != nil {
+			converted["target"] = target
+		}
+		return converted
+	}
+
+	if sceneID, ok := action["scene"].(string); ok {
+		return map[string]interface{}{"id": id, "service": "scene.turn_on", "entity_id": sceneID}
+	}
+
+	if delay, ok := action["delay"]; ok {
+		duration, ok := haDuration(delay)
+		if !ok {
+			item.unsupported("action %d: delay %v", index, delay)
+			return nil
+		}
+		return map[string]interface{}{"id": id, "delay": duration}
+	}
+
+	for _, kind := range []string{"choose", "if", "repeat", "parallel", "sequence", "wait_template", "wait_for_trigger", "event", "stop", "variables", "condition", "device_id"} {
+		if _, ok := action[kind]; ok {
+			item.unsupported("action %d: %s", index, strings.ReplaceAll(kind, "_", " "))
+			return nil
+		}
+	}
+	item.unsupported("action %d: unknown action", index)
+	return nil
+}
+
+// entityIDPattern matches Home Assistant entity IDs
+var entityIDPattern = regexp.MustCompile(`^[a-z0-9_]+\.[a-z0-9_]+$`)
+
+// mapEntities replaces the Home Assistant entity IDs in entity_id fields with PMA entity IDs
+func (im *HAImporter) mapEntities(item *HAImportItem, value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, field := range typed {
+			if key == "entity_id" {
+				typed[key] = im.mapEntityIDs(item, field)
+			} else {
+				typed[key] = im.mapEntities(item, field)
+			}
+		}
+	case []interface{}:
+		for i, element := range typed {
+			typed[i] = im.mapEntities(item, element)
+		}
+	}
+	return value
+}
+
+func (im *HAImporter) mapEntityIDs(item *HAImportItem, value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return im.mapEntityID(item, typed)
+	case []interface{}:
+		mapped := make([]interface{}, len(typed))
+		for i, element := range typed {
+			mapped[i] = im.mapEntityIDs(item, element)
+		}
+		return mapped
+	}
+	return value
+}
+
+func (im *HAImporter) mapEntityID(item *HAImportItem, entityID string) string {
+	if im.mapEntity == nil || !entityIDPattern.MatchString(entityID) {
+		return entityID
+	}
+	mapped, known := im.mapEntity(entityID)
+	if !known {
+		for _, unmapped := range item.UnmappedEntities {
+			if unmapped == entityID {
+				return mapped
+			}
+		}
+		item.UnmappedEntities = append(item.UnmappedEntities, entityID)
+	}
+	return mapped
+}
+
+// haDuration converts a Home Assistant duration ("HH:MM:SS", seconds or a map of units) to a
+// Go duration string
+func haDuration(value interface{}) (string, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return (time.Duration(typed * float64(time.Second))).String(), true
+	case string:
+		negative := strings.HasPrefix(typed, "-")
+		parts := strings.Split(strings.TrimPrefix(typed, "-"), ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			if duration, err := time.ParseDuration(typed); err == nil {
+				return duration.String(), true
+			}
+			return "", false
+		}
+		var total time.Duration
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+		for i, part := range parts {
+			number, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return "", false
+			}
+			total += time.Duration(number * float64(units[i]))
+		}
+		if negative {
+			total = -total
+		}
+		return total.String(), true
+	case map[string]interface{}:
+		units := map[string]time.Duration{
+			"days":         24 * time.Hour,
+			"hours":        time.Hour,
+			"minutes":      time.Minute,
+			"seconds":      time.Second,
+			"milliseconds": time.Millisecond,
+		}
+		var total time.Duration
+		for key, field := range typed {
+			number, ok := field.(float64)
+			unit, known := units[key]
+			if !ok || !known {
+				return "", false
+			}
+			total += time.Duration(number * float64(unit))
+		}
+		return total.String(), true
+	}
+	return "", false
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns an ID or name into a rule ID fragment
+func slug(value string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(value), "_"), "_")
+}
+
+func stringValue(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typed)
+	}
+	return ""
+}
+
+func firstString(values ...interface{}) string {
+	for _, value := range values {
+		if s := stringValue(value); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func firstPresent(m map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := m[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// listValue wraps a single item in a list
+func listValue(value interface{}) []interface{} {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return typed
+	}
+	return []interface{}{value}
+}
+
+func stringList(value interface{}) []string {
+	var list []string
+	for _, element := range listValue(value) {
+		if s, ok := element.(string); ok && s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func unknownKeys(m map[string]interface{}, known []string) []string {
+	var extra []string
+	for key := range m {
+		found := false
+		for _, k := range known {
+			if key == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return extra
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
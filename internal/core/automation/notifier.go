@@ -0,0 +1,32 @@
+package automation
+
+import (
+	"context"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+)
+
+// EventTypeNotificationAction is fired when a user responds to an actionable notification.
+// The event data carries notification_id, action_id, user_id, response and the notification's data.
+const EventTypeNotificationAction = "notification_action"
+
+// Notifier delivers notifications created by rules. Channels restricts delivery to the named
+// channels; when empty the recipients' notification preferences decide.
+type Notifier interface {
+	Notify(ctx context.Context, notification *types.AINotification, channels []string) error
+}
+
+type notifierKey struct{}
+
+// withNotifier makes the notifier available to actions executed with ctx
+func withNotifier(ctx context.Context, notifier Notifier) context.Context {
+	if notifier == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, notifierKey{}, notifier)
+}
+
+func notifierFromContext(ctx context.Context) Notifier {
+	notifier, _ := ctx.Value(notifierKey{}).(Notifier)
+	return notifier
+}
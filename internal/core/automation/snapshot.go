@@ -0,0 +1,37 @@
+package automation
+
+import (
+	"context"
+	"time"
+)
+
+// SnapshotAttachment references an archived camera snapshot attached to a notification
+type SnapshotAttachment struct {
+	CameraEntityID string    `json:"camera_entity_id"`
+	EventID        int       `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	FileID         string    `json:"file_id"`
+	URL            string    `json:"url"`
+	ThumbnailURL   string    `json:"thumbnail_url,omitempty"`
+	CapturedAt     time.Time `json:"captured_at"`
+}
+
+// SnapshotProvider resolves the latest archived snapshot of a camera
+type SnapshotProvider interface {
+	LatestSnapshot(ctx context.Context, cameraEntityID string) (*SnapshotAttachment, error)
+}
+
+type snapshotProviderKey struct{}
+
+// withSnapshotProvider makes the provider available to actions executed with ctx
+func withSnapshotProvider(ctx context.Context, provider SnapshotProvider) context.Context {
+	if provider == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, snapshotProviderKey{}, provider)
+}
+
+func snapshotProviderFromContext(ctx context.Context) SnapshotProvider {
+	provider, _ := ctx.Value(snapshotProviderKey{}).(SnapshotProvider)
+	return provider
+}
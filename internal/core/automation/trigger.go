@@ -19,6 +19,23 @@ type Event struct {
 	Timestamp time.Time              `json:"timestamp"`
 }
 
+// EventTypeDeviceEvent is fired for momentary device events such as button presses. The event
+// data carries device_id, component and event (e.g. single_push, double_push, long_push).
+const EventTypeDeviceEvent = "device_event"
+
+// EventTypePowerEvent is fired when the UPS reports a power change. The event data carries
+// event (on_battery, low_battery, critical_battery or power_restored), ups, ups_status,
+// battery_charge and battery_runtime.
+const EventTypePowerEvent = "power_event"
+
+// Timer helper events. The event data carries duration, remaining and finishes_at.
+const (
+	EventTypeTimerStarted   = "timer.started"
+	EventTypeTimerPaused    = "timer.paused"
+	EventTypeTimerCancelled = "timer.cancelled"
+	EventTypeTimerFinished  = "timer.finished"
+)
+
 // TriggerHandler is called when a trigger fires
 type TriggerHandler func(ctx context.Context, trigger Trigger, event Event) error
 
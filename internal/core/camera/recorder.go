@@ -0,0 +1,659 @@
+package camera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/devices"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/filemanager"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/media"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// Event types stored in the archive
+const (
+	EventTypeMotion = "motion"
+	EventTypeDing   = "ding"
+	EventTypeManual = "manual"
+)
+
+// Clip download states
+const (
+	ClipStatusNone       = "none"
+	ClipStatusPending    = "pending"
+	ClipStatusDownloaded = "downloaded"
+	ClipStatusFailed     = "failed"
+)
+
+// FileCategory is the file manager category of all archived snapshots, thumbnails and clips
+const FileCategory = "camera"
+
+const (
+	maxSnapshotSize = 20 * 1024 * 1024
+	// Events reported later than this are not given a snapshot since it would show the present
+	maxEventAge = 10 * time.Minute
+)
+
+// Ring publishes recordings a while after the event ends, so clip downloads are retried
+var clipRetryDelays = []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 5 * time.Minute}
+
+var thumbnailOptions = media.ThumbnailOptions{Width: 320, Height: 180, Quality: 75, Format: "jpeg", Fit: "resize"}
+
+// Recorder errors
+var (
+	ErrRecordingDisabled = errors.New("recording disabled for camera")
+	ErrCooldown          = errors.New("camera recorded too recently")
+	ErrFileNotFound      = errors.New("camera file not found")
+)
+
+// SnapshotSource is implemented by adapters that can fetch a still image of their cameras
+type SnapshotSource interface {
+	GetCameraSnapshot(ctx context.Context, entityID string) (io.ReadCloser, string, error)
+}
+
+// ClipSource is implemented by adapters that keep recordings of camera events
+type ClipSource interface {
+	DownloadEventRecording(ctx context.Context, eventID string) (io.ReadCloser, string, error)
+}
+
+// deviceEventSource is implemented by adapters that push device events (Ring)
+type deviceEventSource interface {
+	Subscribe(callback func(devices.DeviceEvent)) error
+}
+
+// Trigger describes a camera event to record
+type Trigger struct {
+	CameraEntityID  string
+	EventType       string
+	Source          types.PMASourceType
+	TriggerEntityID string
+	ExternalID      string
+	OccurredAt      time.Time
+	Metadata        map[string]interface{}
+}
+
+// Recorder archives snapshots and clips of camera events into the file manager
+type Recorder struct {
+	config     config.CameraRecordingConfig
+	defaults   RecordingSettings
+	repo       repositories.CameraRepository
+	files      filemanager.FileManager
+	thumbnails *media.ThumbnailGenerator
+	entities   *unified.UnifiedEntityService
+	logger     *logrus.Logger
+
+	lastRecorded map[string]time.Time
+	mutex        sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRecorder creates a camera event recorder
+func NewRecorder(
+	cfg config.CameraRecordingConfig,
+	repo repositories.CameraRepository,
+	files filemanager.FileManager,
+	thumbnails *media.ThumbnailGenerator,
+	entities *unified.UnifiedEntityService,
+	logger *logrus.Logger,
+) *Recorder {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Recorder{
+		config:       cfg,
+		defaults:     defaultRecordingSettings(cfg),
+		repo:         repo,
+		files:        files,
+		thumbnails:   thumbnails,
+		entities:     entities,
+		logger:       logger,
+		lastRecorded: make(map[string]time.Time),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start subscribes to camera triggers and starts the retention loop
+func (r *Recorder) Start() error {
+	r.entities.AddStateChangeListener(r.handleStateChange)
+
+	registry := r.entities.GetRegistryManager().GetAdapterRegistry()
+	if adapter, err := registry.GetAdapterBySource(types.SourceRing); err == nil {
+		r.subscribeDeviceEvents(adapter)
+	}
+	// A configuration reload replaces the Ring adapter, subscribe to the new one
+	r.entities.AddAdapterReplacedListener(func(source types.PMASourceType, adapter types.PMAAdapter) {
+		if source == types.SourceRing {
+			r.subscribeDeviceEvents(adapter)
+		}
+	})
+
+	cleanupInterval, err := time.ParseDuration(r.config.CleanupInterval)
+	if err != nil || cleanupInterval <= 0 {
+		cleanupInterval = time.Hour
+	}
+
+	r.wg.Add(1)
+	go r.retentionLoop(cleanupInterval)
+
+	r.logger.WithField("enabled_by_default", r.defaults.Enabled).Info("Camera event recorder started")
+	return nil
+}
+
+// subscribeDeviceEvents records motion and ding events of an adapter that reports them
+func (r *Recorder) subscribeDeviceEvents(adapter types.PMAAdapter) {
+	source, ok := adapter.(deviceEventSource)
+	if !ok {
+		return
+	}
+	if err := source.Subscribe(r.handleDeviceEvent); err != nil {
+		r.logger.WithError(err).WithField("source", adapter.GetSourceType()).Warn("Failed to subscribe to device events")
+	}
+}
+
+// Stop stops the retention loop and pending clip downloads
+func (r *Recorder) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// RecordEvent captures a snapshot for the trigger, stores the event and schedules its clip download
+func (r *Recorder) RecordEvent(ctx context.Context, trigger Trigger) (*models.CameraEvent, error) {
+	camera, _ := r.repo.GetByEntityID(ctx, trigger.CameraEntityID)
+	settings := applyCameraSettings(r.defaults, camera)
+
+	if !settings.Enabled && trigger.EventType != EventTypeManual {
+		return nil, ErrRecordingDisabled
+	}
+
+	if trigger.ExternalID != "" {
+		existing, err := r.repo.GetEventByExternalID(ctx, string(trigger.Source), trigger.ExternalID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
+	if trigger.EventType != EventTypeManual && !r.reserve(trigger.CameraEntityID, settings.Cooldown) {
+		return nil, ErrCooldown
+	}
+
+	if trigger.Source == "" {
+		trigger.Source = r.sourceOf(ctx, trigger.CameraEntityID)
+	}
+	if trigger.OccurredAt.IsZero() {
+		trigger.OccurredAt = time.Now()
+	}
+
+	metadata := make(map[string]interface{}, len(trigger.Metadata)+1)
+	for key, value := range trigger.Metadata {
+		metadata[key] = value
+	}
+
+	event := &models.CameraEvent{
+		CameraEntityID:  trigger.CameraEntityID,
+		EventType:       trigger.EventType,
+		Source:          string(trigger.Source),
+		TriggerEntityID: trigger.TriggerEntityID,
+		ExternalID:      trigger.ExternalID,
+		ClipStatus:      ClipStatusNone,
+		OccurredAt:      trigger.OccurredAt,
+	}
+
+	if err := r.captureSnapshot(ctx, event, trigger.Source); err != nil {
+		r.logger.WithError(err).WithField("camera", event.CameraEntityID).Warn("Failed to capture camera snapshot")
+		metadata["snapshot_error"] = err.Error()
+	}
+
+	var clipSource ClipSource
+	if settings.RecordClips && trigger.ExternalID != "" {
+		if source, ok := r.adapterFor(trigger.Source).(ClipSource); ok {
+			clipSource = source
+			event.ClipStatus = ClipStatusPending
+		}
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event metadata: %w", err)
+	}
+	event.Metadata = metadataJSON
+
+	if err := r.repo.CreateEvent(ctx, event); err != nil {
+		r.deleteEventFiles(event)
+		return nil, err
+	}
+
+	if clipSource != nil {
+		r.wg.Add(1)
+		go r.downloadClip(*event, clipSource)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"event_id":   event.ID,
+		"camera":     event.CameraEntityID,
+		"event_type": event.EventType,
+		"snapshot":   event.SnapshotFileID != "",
+		"clip":       event.ClipStatus,
+	}).Info("Recorded camera event")
+
+	return event, nil
+}
+
+// CaptureSnapshot records a manual snapshot of a camera, bypassing the cooldown
+func (r *Recorder) CaptureSnapshot(ctx context.Context, cameraEntityID string) (*models.CameraEvent, error) {
+	return r.RecordEvent(ctx, Trigger{CameraEntityID: cameraEntityID, EventType: EventTypeManual})
+}
+
+// LatestSnapshot returns the newest event of a camera that has a snapshot, or nil if there is none
+func (r *Recorder) LatestSnapshot(ctx context.Context, cameraEntityID string) (*models.CameraEvent, error) {
+	return r.repo.GetLatestEvent(ctx, cameraEntityID)
+}
+
+// GetEvent returns an archived event
+func (r *Recorder) GetEvent(ctx context.Context, id int) (*models.CameraEvent, error) {
+	return r.repo.GetEvent(ctx, id)
+}
+
+// Timeline returns archived events, newest first, with the total number of matches
+func (r *Recorder) Timeline(ctx context.Context, filter models.CameraEventFilter) ([]*models.CameraEvent, int, error) {
+	return r.repo.ListEvents(ctx, filter)
+}
+
+// DeleteEvent removes an event and its files
+func (r *Recorder) DeleteEvent(ctx context.Context, id int) error {
+	event, err := r.repo.GetEvent(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	r.deleteEventFiles(event)
+	return r.repo.DeleteEvent(ctx, id)
+}
+
+// OpenFile opens an archived file. Only files of the camera category are served.
+func (r *Recorder) OpenFile(fileID string) (io.ReadCloser, *filemanager.File, error) {
+	if fileID == "" {
+		return nil, nil, ErrFileNotFound
+	}
+
+	info, err := r.files.GetFileInfo(fileID)
+	if err != nil || info.Category != FileCategory {
+		return nil, nil, ErrFileNotFound
+	}
+
+	reader, err := r.files.Download(fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reader, info, nil
+}
+
+// handleStateChange turns motion/doorbell sensor activations into recordings
+func (r *Recorder) handleStateChange(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+	eventType, ok := classifyTrigger(entityID, oldState, newState)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, time.Minute)
+	defer cancel()
+
+	cameraEntityID := r.cameraForTrigger(ctx, entityID)
+	if cameraEntityID == "" {
+		return
+	}
+
+	_, err := r.RecordEvent(ctx, Trigger{
+		CameraEntityID:  cameraEntityID,
+		EventType:       eventType,
+		TriggerEntityID: entityID,
+		Metadata: map[string]interface{}{
+			"trigger_state":  string(newState),
+			"trigger_source": string(source),
+		},
+	})
+	if err != nil && !errors.Is(err, ErrRecordingDisabled) && !errors.Is(err, ErrCooldown) {
+		r.logger.WithError(err).WithField("trigger", entityID).Warn("Failed to record camera event")
+	}
+}
+
+// handleDeviceEvent records motion and doorbell events pushed by device adapters
+func (r *Recorder) handleDeviceEvent(event devices.DeviceEvent) {
+	var eventType string
+	switch event.EventType {
+	case devices.EventTypeMotionDetected:
+		eventType = EventTypeMotion
+	case devices.EventTypeDoorbellPressed, devices.EventTypeDoorbellPress:
+		eventType = EventTypeDing
+	default:
+		return
+	}
+
+	if !event.Timestamp.IsZero() && time.Since(event.Timestamp) > maxEventAge {
+		return
+	}
+
+	externalID, _ := event.Data["ring_event_id_str"].(string)
+	if externalID == "" {
+		if id, ok := event.Data["ring_event_id"]; ok {
+			externalID = fmt.Sprintf("%v", id)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, time.Minute)
+	defer cancel()
+
+	_, err := r.RecordEvent(ctx, Trigger{
+		CameraEntityID: event.DeviceID,
+		EventType:      eventType,
+		Source:         types.PMASourceType(event.AdapterType),
+		ExternalID:     externalID,
+		OccurredAt:     event.Timestamp,
+		Metadata:       event.Data,
+	})
+	if err != nil && !errors.Is(err, ErrRecordingDisabled) && !errors.Is(err, ErrCooldown) {
+		r.logger.WithError(err).WithField("device_id", event.DeviceID).Warn("Failed to record camera event")
+	}
+}
+
+// cameraForTrigger finds the camera a trigger entity belongs to. Explicit trigger_entities in the
+// camera settings win; otherwise a camera with the same name stem is looked up
+// (binary_sensor.front_door_motion -> camera.front_door).
+func (r *Recorder) cameraForTrigger(ctx context.Context, triggerEntityID string) string {
+	if cameras, err := r.repo.GetAll(ctx); err == nil {
+		for _, camera := range cameras {
+			settings := applyCameraSettings(r.defaults, camera)
+			for _, trigger := range settings.TriggerEntities {
+				if trigger == triggerEntityID || "ha_"+trigger == triggerEntityID {
+					return camera.EntityID
+				}
+			}
+		}
+	}
+
+	prefix, objectID := splitEntityID(triggerEntityID)
+	stem := triggerStem(objectID)
+	if stem == "" {
+		return ""
+	}
+
+	cameraEntityID := prefix + "camera." + stem
+	entity, err := r.entities.GetByID(ctx, cameraEntityID, unified.GetEntityOptions{})
+	if err != nil || entity == nil || entity.Entity.GetType() != types.EntityTypeCamera {
+		return ""
+	}
+
+	return cameraEntityID
+}
+
+// captureSnapshot fetches the current image of the camera and stores it with a thumbnail
+func (r *Recorder) captureSnapshot(ctx context.Context, event *models.CameraEvent, source types.PMASourceType) error {
+	if time.Since(event.OccurredAt) > maxEventAge {
+		return fmt.Errorf("event is too old for a snapshot")
+	}
+
+	snapshotSource, ok := r.adapterFor(source).(SnapshotSource)
+	if !ok {
+		return fmt.Errorf("%s cameras do not provide snapshots", source)
+	}
+
+	reader, mimeType, err := snapshotSource.GetCameraSnapshot(ctx, event.CameraEntityID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxSnapshotSize))
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+
+	file, err := r.store(event, "snapshot", extensionFor(mimeType), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	event.SnapshotFileID = file.ID
+
+	r.storeThumbnail(event, bytes.NewReader(data), mimeType)
+	return nil
+}
+
+// downloadClip fetches the recording of an event, retrying while the source prepares it
+func (r *Recorder) downloadClip(event models.CameraEvent, source ClipSource) {
+	defer r.wg.Done()
+
+	event.ClipStatus = ClipStatusFailed
+	for attempt, delay := range clipRetryDelays {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if err := r.fetchClip(&event, source); err != nil {
+			r.logger.WithError(err).WithFields(logrus.Fields{
+				"event_id": event.ID,
+				"attempt":  attempt + 1,
+			}).Debug("Camera clip not available yet")
+			continue
+		}
+
+		event.ClipStatus = ClipStatusDownloaded
+		break
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, 30*time.Second)
+	defer cancel()
+
+	if err := r.repo.UpdateEventFiles(ctx, &event); err != nil {
+		r.logger.WithError(err).WithField("event_id", event.ID).Error("Failed to update camera event clip")
+		return
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"event_id": event.ID,
+		"status":   event.ClipStatus,
+	}).Info("Camera event clip processed")
+}
+
+func (r *Recorder) fetchClip(event *models.CameraEvent, source ClipSource) error {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Minute)
+	defer cancel()
+
+	reader, mimeType, err := source.DownloadEventRecording(ctx, event.ExternalID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if !strings.HasPrefix(mimeType, "video/") {
+		mimeType = "video/mp4"
+	}
+
+	file, err := r.store(event, "clip", extensionFor(mimeType), reader)
+	if err != nil {
+		return err
+	}
+	event.ClipFileID = file.ID
+
+	// Events without a snapshot get their thumbnail from the clip instead
+	if event.ThumbnailFileID == "" {
+		if clip, err := r.files.Download(file.ID); err == nil {
+			r.storeThumbnail(event, clip, mimeType)
+			clip.Close()
+		}
+	}
+
+	return nil
+}
+
+func (r *Recorder) storeThumbnail(event *models.CameraEvent, reader io.Reader, mimeType string) {
+	if r.thumbnails == nil {
+		return
+	}
+
+	thumbnail, err := r.thumbnails.GenerateWithOptions(reader, mimeType, thumbnailOptions)
+	if err != nil {
+		r.logger.WithError(err).WithField("camera", event.CameraEntityID).Debug("Failed to generate camera thumbnail")
+		return
+	}
+
+	file, err := r.store(event, "thumbnail", ".jpg", bytes.NewReader(thumbnail))
+	if err != nil {
+		r.logger.WithError(err).WithField("camera", event.CameraEntityID).Warn("Failed to store camera thumbnail")
+		return
+	}
+	event.ThumbnailFileID = file.ID
+}
+
+// store uploads one file of an event into the camera category of the file manager
+func (r *Recorder) store(event *models.CameraEvent, kind, extension string, content io.Reader) (*filemanager.File, error) {
+	name := strings.NewReplacer(".", "_", "/", "_", " ", "_").Replace(event.CameraEntityID)
+	filename := fmt.Sprintf("%s_%s_%s%s", name, event.OccurredAt.UTC().Format("20060102T150405"), kind, extension)
+
+	file, err := r.files.Upload(filename, content, filemanager.FileMetadata{
+		Category:    FileCategory,
+		Description: fmt.Sprintf("%s %s of %s", event.EventType, kind, event.CameraEntityID),
+		Tags:        []string{"camera", event.EventType, kind},
+		Properties: map[string]interface{}{
+			"camera_entity_id": event.CameraEntityID,
+			"event_type":       event.EventType,
+			"occurred_at":      event.OccurredAt,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store camera %s: %w", kind, err)
+	}
+
+	return file, nil
+}
+
+func (r *Recorder) deleteEventFiles(event *models.CameraEvent) {
+	for _, fileID := range []string{event.SnapshotFileID, event.ThumbnailFileID, event.ClipFileID} {
+		if fileID == "" {
+			continue
+		}
+		if err := r.files.Delete(fileID); err != nil {
+			r.logger.WithError(err).WithField("file_id", fileID).Warn("Failed to delete camera file")
+		}
+	}
+}
+
+// reserve claims a recording slot for a camera unless it recorded within the cooldown
+func (r *Recorder) reserve(cameraEntityID string, cooldown time.Duration) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if last, ok := r.lastRecorded[cameraEntityID]; ok && time.Since(last) < cooldown {
+		return false
+	}
+	r.lastRecorded[cameraEntityID] = time.Now()
+	return true
+}
+
+func (r *Recorder) sourceOf(ctx context.Context, entityID string) types.PMASourceType {
+	entity, err := r.entities.GetByID(ctx, entityID, unified.GetEntityOptions{})
+	if err != nil || entity == nil || entity.Entity == nil {
+		return ""
+	}
+	return entity.Entity.GetSource()
+}
+
+func (r *Recorder) adapterFor(source types.PMASourceType) types.PMAAdapter {
+	adapter, err := r.entities.GetRegistryManager().GetAdapterRegistry().GetAdapterBySource(source)
+	if err != nil {
+		return nil
+	}
+	return adapter
+}
+
+var (
+	dingKeywords   = []string{"ding", "doorbell", "visitor", "button", "press"}
+	motionKeywords = []string{"motion", "occupancy", "person", "vehicle", "animal"}
+)
+
+// classifyTrigger reports whether a state change is a camera trigger and which event it represents.
+// Binary sensors trigger when they turn on, event entities on every new event.
+func classifyTrigger(entityID string, oldState, newState types.PMAEntityState) (string, bool) {
+	_, objectID := splitEntityID(entityID)
+	domain := strings.SplitN(strings.TrimPrefix(entityID, "ha_"), ".", 2)[0]
+
+	switch domain {
+	case "binary_sensor":
+		if newState != types.StateOn || oldState == types.StateOn {
+			return "", false
+		}
+	case "event":
+		if newState == oldState || newState == types.StateUnavailable || newState == types.StateUnknown {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	for _, keyword := range dingKeywords {
+		if strings.Contains(objectID, keyword) {
+			return EventTypeDing, true
+		}
+	}
+	return EventTypeMotion, true
+}
+
+// triggerStem strips a known trigger suffix from an object ID, returning "" if there is none
+func triggerStem(objectID string) string {
+	for _, keyword := range append(append([]string{}, dingKeywords...), motionKeywords...) {
+		for _, suffix := range []string{"_" + keyword + "_detected", "_" + keyword} {
+			if strings.HasSuffix(objectID, suffix) {
+				return strings.TrimSuffix(objectID, suffix)
+			}
+		}
+	}
+	return ""
+}
+
+// splitEntityID splits "ha_binary_sensor.front_door_motion" into "ha_" and "front_door_motion"
+func splitEntityID(entityID string) (string, string) {
+	prefix := ""
+	if strings.HasPrefix(entityID, "ha_") {
+		prefix = "ha_"
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(entityID, prefix), ".", 2)
+	if len(parts) != 2 {
+		return prefix, ""
+	}
+	return prefix, parts[1]
+}
+
+func extensionFor(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/png"):
+		return ".png"
+	case strings.HasPrefix(mimeType, "image/"):
+		return ".jpg"
+	default:
+		return ".mp4"
+	}
+}
@@ -0,0 +1,66 @@
+package camera
+
+import (
+	"context"
+	"time"
+)
+
+// retentionLoop periodically applies the retention rules of every camera
+func (r *Recorder) retentionLoop(interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(r.ctx, 5*time.Minute)
+			if _, err := r.Cleanup(ctx); err != nil {
+				r.logger.WithError(err).Warn("Camera event cleanup failed")
+			}
+			cancel()
+		}
+	}
+}
+
+// Cleanup deletes events (and their files) that are older than their camera's retention
+// period or exceed its event limit. It returns the number of deleted events.
+func (r *Recorder) Cleanup(ctx context.Context) (int, error) {
+	cameras, err := r.repo.GetEventCameras(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, cameraEntityID := range cameras {
+		camera, _ := r.repo.GetByEntityID(ctx, cameraEntityID)
+		settings := applyCameraSettings(r.defaults, camera)
+
+		var cutoff time.Time
+		if settings.RetentionDays > 0 {
+			cutoff = time.Now().AddDate(0, 0, -settings.RetentionDays)
+		}
+
+		expired, err := r.repo.GetExpiredEvents(ctx, cameraEntityID, cutoff, settings.MaxEvents)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, event := range expired {
+			r.deleteEventFiles(event)
+			if err := r.repo.DeleteEvent(ctx, event.ID); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+
+	if deleted > 0 {
+		r.logger.WithField("deleted", deleted).Info("Removed expired camera events")
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,87 @@
+package camera
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// RecordingSettings are the effective recording rules of one camera
+type RecordingSettings struct {
+	Enabled         bool          `json:"enabled"`
+	RecordClips     bool          `json:"record_clips"`
+	RetentionDays   int           `json:"retention_days"`
+	MaxEvents       int           `json:"max_events"`
+	Cooldown        time.Duration `json:"cooldown"`
+	TriggerEntities []string      `json:"trigger_entities,omitempty"`
+}
+
+// recordingOverrides is the "recording" object of a camera's settings JSON. Pointer fields
+// distinguish "not set" from an explicit false/zero.
+type recordingOverrides struct {
+	Enabled         *bool    `json:"enabled"`
+	RecordClips     *bool    `json:"record_clips"`
+	RetentionDays   *int     `json:"retention_days"`
+	MaxEvents       *int     `json:"max_events"`
+	Cooldown        string   `json:"cooldown"`
+	TriggerEntities []string `json:"trigger_entities"`
+}
+
+// defaultRecordingSettings converts the global recorder configuration
+func defaultRecordingSettings(cfg config.CameraRecordingConfig) RecordingSettings {
+	cooldown, err := time.ParseDuration(cfg.Cooldown)
+	if err != nil {
+		cooldown = 30 * time.Second
+	}
+
+	return RecordingSettings{
+		Enabled:       cfg.Enabled,
+		RecordClips:   cfg.RecordClips,
+		RetentionDays: cfg.RetentionDays,
+		MaxEvents:     cfg.MaxEventsPerCamera,
+		Cooldown:      cooldown,
+	}
+}
+
+// applyCameraSettings overlays the per-camera overrides stored in the camera's settings
+func applyCameraSettings(defaults RecordingSettings, camera *models.Camera) RecordingSettings {
+	settings := defaults
+	if camera == nil {
+		return settings
+	}
+	if !camera.IsEnabled {
+		settings.Enabled = false
+	}
+	if len(camera.Settings) == 0 {
+		return settings
+	}
+
+	var wrapper struct {
+		Recording *recordingOverrides `json:"recording"`
+	}
+	if err := json.Unmarshal(camera.Settings, &wrapper); err != nil || wrapper.Recording == nil {
+		return settings
+	}
+
+	overrides := wrapper.Recording
+	if overrides.Enabled != nil {
+		settings.Enabled = *overrides.Enabled && camera.IsEnabled
+	}
+	if overrides.RecordClips != nil {
+		settings.RecordClips = *overrides.RecordClips
+	}
+	if overrides.RetentionDays != nil {
+		settings.RetentionDays = *overrides.RetentionDays
+	}
+	if overrides.MaxEvents != nil {
+		settings.MaxEvents = *overrides.MaxEvents
+	}
+	if cooldown, err := time.ParseDuration(overrides.Cooldown); err == nil {
+		settings.Cooldown = cooldown
+	}
+	settings.TriggerEntities = overrides.TriggerEntities
+
+	return settings
+}
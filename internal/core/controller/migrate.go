@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// legacyElementTypes maps element types of unversioned dashboards to their current names
+var legacyElementTypes = map[string]string{
+	"display_value": "sensor",
+}
+
+// schemaMigration upgrades a dashboard's elements from one schema version to the next
+type schemaMigration func(elements []map[string]interface{}) ([]map[string]interface{}, error)
+
+// schemaMigrations maps a source schema version to the migration that upgrades it by one version
+var schemaMigrations = map[int]schemaMigration{
+	1: migrateElementsV1ToV2,
+}
+
+// MigrateDashboard upgrades a dashboard's element JSON to CurrentSchemaVersion in place.
+// Dashboards without a schema version are treated as legacy version 1 documents.
+// It reports whether any migration was applied.
+func MigrateDashboard(dashboard *models.ControllerDashboard) (bool, error) {
+	if dashboard.SchemaVersion <= 0 {
+		dashboard.SchemaVersion = 1
+	}
+	if dashboard.SchemaVersion == CurrentSchemaVersion {
+		return false, nil
+	}
+	if dashboard.SchemaVersion > CurrentSchemaVersion {
+		return false, fmt.Errorf("dashboard schema version %d is newer than supported version %d", dashboard.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	elements := []map[string]interface{}{}
+	if strings.TrimSpace(dashboard.ElementsJSON) != "" {
+		if err := json.Unmarshal([]byte(dashboard.ElementsJSON), &elements); err != nil {
+			return false, fmt.Errorf("failed to parse elements for migration: %w", err)
+		}
+	}
+
+	for version := dashboard.SchemaVersion; version < CurrentSchemaVersion; version++ {
+		migration, ok := schemaMigrations[version]
+		if !ok {
+			return false, fmt.Errorf("no migration available from schema version %d", version)
+		}
+
+		migrated, err := migration(elements)
+		if err != nil {
+			return false, fmt.Errorf("migration from schema version %d failed: %w", version, err)
+		}
+		elements = migrated
+	}
+
+	data, err := json.Marshal(elements)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode migrated elements: %w", err)
+	}
+
+	dashboard.ElementsJSON = string(data)
+	dashboard.SchemaVersion = CurrentSchemaVersion
+	return true, nil
+}
+
+// migrateElementsV1ToV2 assigns unique element IDs, renames legacy element types, normalizes
+// positions and slider ranges, and moves legacy config.entity_id references into explicit
+// entity bindings
+func migrateElementsV1ToV2(elements []map[string]interface{}) ([]map[string]interface{}, error) {
+	seenIDs := make(map[string]bool)
+
+	for i, element := range elements {
+		if element == nil {
+			return nil, fmt.Errorf("element %d is not an object", i)
+		}
+
+		// Unique element IDs
+		id, _ := element["id"].(string)
+		id = strings.TrimSpace(id)
+		if id == "" {
+			id = fmt.Sprintf("element_%d", i+1)
+		}
+		base := id
+		for suffix := 2; seenIDs[id]; suffix++ {
+			id = fmt.Sprintf("%s_%d", base, suffix)
+		}
+		seenIDs[id] = true
+		element["id"] = id
+
+		// Element types are lowercase identifiers
+		elementType, _ := element["type"].(string)
+		elementType = strings.ToLower(strings.TrimSpace(elementType))
+		if current, ok := legacyElementTypes[elementType]; ok {
+			elementType = current
+		}
+		if _, ok := element["type"].(string); ok {
+			element["type"] = elementType
+		}
+
+		// Positions use explicit width/height instead of the legacy w/h shorthand
+		position, _ := element["position"].(map[string]interface{})
+		if position == nil {
+			position = make(map[string]interface{})
+		}
+		renameKey(position, "w", "width")
+		renameKey(position, "h", "height")
+		for key, fallback := range map[string]float64{"x": 0, "y": 0, "width": 1, "height": 1} {
+			if _, ok := position[key].(float64); !ok {
+				position[key] = fallback
+			}
+		}
+		element["position"] = position
+
+		// Config, style and behavior are always objects
+		for _, key := range []string{"config", "style", "behavior"} {
+			if _, ok := element[key].(map[string]interface{}); !ok {
+				element[key] = make(map[string]interface{})
+			}
+		}
+		config := element["config"].(map[string]interface{})
+
+		// Legacy sliders kept their range next to the label instead of in the slider object
+		if elementType == "slider" {
+			slider, _ := config["slider"].(map[string]interface{})
+			if slider == nil {
+				slider = make(map[string]interface{})
+			}
+			for _, key := range []string{"min", "max", "step", "unit"} {
+				if value, ok := config[key]; ok {
+					if _, exists := slider[key]; !exists {
+						slider[key] = value
+					}
+					delete(config, key)
+				}
+			}
+			config["slider"] = slider
+		}
+
+		// Legacy single-entity elements referenced their entity from config
+		bindings, _ := element["entity_bindings"].([]interface{})
+		if entityID, ok := config["entity_id"].(string); ok && entityID != "" {
+			if len(bindings) == 0 {
+				bindings = append(bindings, map[string]interface{}{
+					"entity_id": entityID,
+				})
+			}
+			delete(config, "entity_id")
+		}
+
+		for j, rawBinding := range bindings {
+			binding, ok := rawBinding.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if bindingID, _ := binding["id"].(string); bindingID == "" {
+				binding["id"] = fmt.Sprintf("%s_binding_%d", id, j+1)
+			}
+			if property, _ := binding["property"].(string); property == "" {
+				binding["property"] = "state"
+			}
+			if mode, _ := binding["update_mode"].(string); mode == "" {
+				binding["update_mode"] = "realtime"
+			}
+		}
+		if bindings == nil {
+			bindings = []interface{}{}
+		}
+		element["entity_bindings"] = bindings
+	}
+
+	return elements, nil
+}
+
+func renameKey(m map[string]interface{}, from, to string) {
+	if value, ok := m[from]; ok {
+		if _, exists := m[to]; !exists {
+			m[to] = value
+		}
+		delete(m, from)
+	}
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+)
+
+// maxDashboardRevisions is the number of revisions retained per dashboard
+const maxDashboardRevisions = 50
+
+// GetDashboardRevisions returns the revision history of a dashboard, newest first
+func (s *Service) GetDashboardRevisions(ctx context.Context, dashboardID int, limit int, userID *int) ([]*models.ControllerDashboardRevision, error) {
+	if userID != nil {
+		_, err := s.controllerRepo.CheckUserAccess(ctx, dashboardID, *userID)
+		if err != nil {
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	revisions, err := s.controllerRepo.GetRevisions(ctx, dashboardID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetDashboardRevision returns a single dashboard revision
+func (s *Service) GetDashboardRevision(ctx context.Context, dashboardID int, revision int, userID *int) (*models.ControllerDashboardRevision, error) {
+	if userID != nil {
+		_, err := s.controllerRepo.CheckUserAccess(ctx, dashboardID, *userID)
+		if err != nil {
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	rev, err := s.controllerRepo.GetRevision(ctx, dashboardID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+// RollbackDashboard restores a dashboard to the content of an earlier revision.
+// The rollback itself is recorded as a new revision, so it can be undone.
+func (s *Service) RollbackDashboard(ctx context.Context, dashboardID int, revision int, userID *int) (*models.ControllerDashboard, error) {
+	if userID != nil {
+		permission, err := s.controllerRepo.CheckUserAccess(ctx, dashboardID, *userID)
+		if err != nil || (permission != "admin" && permission != "edit") {
+			return nil, fmt.Errorf("insufficient permissions to update dashboard")
+		}
+	}
+
+	current, err := s.controllerRepo.GetDashboardByID(ctx, dashboardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+
+	rev, err := s.controllerRepo.GetRevision(ctx, dashboardID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard revision: %w", err)
+	}
+
+	// Make sure the state being replaced is preserved in history
+	s.ensureRevision(ctx, current, userID)
+
+	restored := *current
+	restored.Name = rev.Name
+	restored.Description = rev.Description
+	restored.Category = rev.Category
+	restored.LayoutConfig = rev.LayoutConfig
+	restored.ElementsJSON = rev.ElementsJSON
+	restored.StyleConfig = rev.StyleConfig
+	restored.AccessConfig = rev.AccessConfig
+	restored.Tags = rev.Tags
+	restored.SchemaVersion = rev.SchemaVersion
+	restored.Version = current.Version + 1
+
+	// Old revisions may predate the current schema
+	if err := s.prepareDashboard(&restored); err != nil {
+		return nil, fmt.Errorf("dashboard validation failed: %w", err)
+	}
+
+	if err := s.controllerRepo.UpdateDashboard(ctx, &restored); err != nil {
+		return nil, fmt.Errorf("failed to roll back dashboard: %w", err)
+	}
+
+	s.recordRevision(ctx, &restored, fmt.Sprintf("Rolled back to revision %d", revision), userID)
+
+	s.logUsage(ctx, dashboardID, userID, "rollback", nil, nil, map[string]interface{}{
+		"from_revision": current.Version,
+		"to_revision":   revision,
+	})
+
+	s.broadcastDashboardUpdate("dashboard_updated", dashboardID, userID)
+
+	s.logger.WithFields(logrus.Fields{
+		"dashboard_id": dashboardID,
+		"revision":     revision,
+		"new_version":  restored.Version,
+		"user_id":      userID,
+	}).Info("Dashboard rolled back successfully")
+
+	return &restored, nil
+}
+
+// recordRevision stores a snapshot of the dashboard's current content and prunes old revisions
+func (s *Service) recordRevision(ctx context.Context, dashboard *models.ControllerDashboard, summary string, userID *int) {
+	revision := &models.ControllerDashboardRevision{
+		DashboardID:   dashboard.ID,
+		Revision:      dashboard.Version,
+		SchemaVersion: dashboard.SchemaVersion,
+		Name:          dashboard.Name,
+		Description:   dashboard.Description,
+		Category:      dashboard.Category,
+		LayoutConfig:  dashboard.LayoutConfig,
+		ElementsJSON:  dashboard.ElementsJSON,
+		StyleConfig:   dashboard.StyleConfig,
+		AccessConfig:  dashboard.AccessConfig,
+		Tags:          dashboard.Tags,
+		ChangeSummary: summary,
+		UserID:        userID,
+	}
+
+	if err := s.controllerRepo.CreateRevision(ctx, revision); err != nil {
+		s.logger.WithError(err).WithField("dashboard_id", dashboard.ID).Warn("Failed to record dashboard revision")
+		return
+	}
+
+	if err := s.controllerRepo.PruneRevisions(ctx, dashboard.ID, maxDashboardRevisions); err != nil {
+		s.logger.WithError(err).WithField("dashboard_id", dashboard.ID).Warn("Failed to prune dashboard revisions")
+	}
+}
+
+// ensureRevision records the dashboard's current state if it has no revision yet, which is
+// the case for dashboards created before revision history existed
+func (s *Service) ensureRevision(ctx context.Context, dashboard *models.ControllerDashboard, userID *int) {
+	if _, err := s.controllerRepo.GetRevision(ctx, dashboard.ID, dashboard.Version); err == nil {
+		return
+	}
+	s.recordRevision(ctx, dashboard, "Snapshot of existing dashboard", userID)
+}
@@ -0,0 +1,514 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// CurrentSchemaVersion is the controller dashboard element schema version produced by this backend.
+//
+// Version history:
+//   - 1: legacy, unversioned element JSON (elements may lack IDs, bindings may live in config.entity_id)
+//   - 2: every element has a unique ID, a known type, a sized position and explicit entity bindings
+const CurrentSchemaVersion = 2
+
+// Binding update modes accepted in entity bindings
+var validUpdateModes = map[string]bool{
+	"realtime":  true,
+	"polling":   true,
+	"on_change": true,
+	"manual":    true,
+}
+
+// configField describes a typed key of an element's config object
+type configField struct {
+	Kind     string   // string, number, boolean, object, array
+	Required bool     // Key must be present
+	Enum     []string // Allowed values for string fields
+}
+
+// elementSpec describes the schema of a single element type
+type elementSpec struct {
+	Description    string
+	RequiresEntity bool // Element must have at least one entity binding
+	Config         map[string]configField
+}
+
+// elementSpecs defines the element types accepted by the current schema version
+var elementSpecs = map[string]elementSpec{
+	"button": {
+		Description:    "Momentary button that runs an entity action",
+		RequiresEntity: false,
+		Config: map[string]configField{
+			"label":  {Kind: "string"},
+			"icon":   {Kind: "string"},
+			"action": {Kind: "string"},
+		},
+	},
+	"switch": {
+		Description:    "Two-state toggle bound to an entity",
+		RequiresEntity: true,
+		Config: map[string]configField{
+			"label":  {Kind: "string"},
+			"icon":   {Kind: "string"},
+			"switch": {Kind: "object"},
+		},
+	},
+	"slider": {
+		Description:    "Numeric slider that sets an entity value",
+		RequiresEntity: true,
+		Config: map[string]configField{
+			"label":       {Kind: "string"},
+			"slider":      {Kind: "object"},
+			"orientation": {Kind: "string", Enum: []string{"horizontal", "vertical"}},
+		},
+	},
+	"joystick": {
+		Description: "Two-axis joystick control",
+		Config: map[string]configField{
+			"label":    {Kind: "string"},
+			"mode":     {Kind: "string", Enum: []string{"static", "dynamic"}},
+			"deadzone": {Kind: "number"},
+		},
+	},
+	"dpad": {
+		Description: "Directional pad control",
+		Config: map[string]configField{
+			"label":   {Kind: "string"},
+			"actions": {Kind: "object"},
+		},
+	},
+	"text": {
+		Description: "Static text or label",
+		Config: map[string]configField{
+			"text":  {Kind: "string"},
+			"align": {Kind: "string", Enum: []string{"left", "center", "right"}},
+		},
+	},
+	"sensor": {
+		Description:    "Read-only display of an entity state",
+		RequiresEntity: true,
+		Config: map[string]configField{
+			"label":     {Kind: "string"},
+			"unit":      {Kind: "string"},
+			"precision": {Kind: "number"},
+		},
+	},
+	"gauge": {
+		Description:    "Radial gauge for a numeric entity state",
+		RequiresEntity: true,
+		Config: map[string]configField{
+			"label": {Kind: "string"},
+			"min":   {Kind: "number"},
+			"max":   {Kind: "number"},
+			"unit":  {Kind: "string"},
+		},
+	},
+	"chart": {
+		Description:    "History chart for one or more entities",
+		RequiresEntity: true,
+		Config: map[string]configField{
+			"label":      {Kind: "string"},
+			"chart_type": {Kind: "string", Enum: []string{"line", "bar", "area"}},
+			"hours":      {Kind: "number"},
+		},
+	},
+	"camera": {
+		Description:    "Live camera view",
+		RequiresEntity: true,
+		Config: map[string]configField{
+			"label":   {Kind: "string"},
+			"refresh": {Kind: "number"},
+		},
+	},
+	"image": {
+		Description: "Static image",
+		Config: map[string]configField{
+			"url": {Kind: "string", Required: true},
+			"fit": {Kind: "string", Enum: []string{"contain", "cover", "fill"}},
+		},
+	},
+	"scene": {
+		Description: "Scene activation button",
+		Config: map[string]configField{
+			"label":    {Kind: "string"},
+			"scene_id": {Kind: "string", Required: true},
+		},
+	},
+	"group": {
+		Description: "Visual container for other elements",
+		Config: map[string]configField{
+			"title":    {Kind: "string"},
+			"children": {Kind: "array"},
+		},
+	},
+	"spacer": {
+		Description: "Empty layout spacer",
+		Config:      map[string]configField{},
+	},
+}
+
+// SchemaFieldError describes a single schema violation
+type SchemaFieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError aggregates all schema violations found in a dashboard
+type SchemaValidationError struct {
+	SchemaVersion int                `json:"schema_version"`
+	Errors        []SchemaFieldError `json:"errors"`
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Path, fieldErr.Message))
+	}
+	return fmt.Sprintf("schema v%d validation failed: %s", e.SchemaVersion, strings.Join(messages, "; "))
+}
+
+func (e *SchemaValidationError) add(path, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, SchemaFieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateDashboardSchema validates the layout and elements of a dashboard against the
+// current schema version. The dashboard must already be migrated to CurrentSchemaVersion.
+func ValidateDashboardSchema(dashboard *models.ControllerDashboard) error {
+	result := &SchemaValidationError{SchemaVersion: CurrentSchemaVersion}
+
+	if dashboard.SchemaVersion != CurrentSchemaVersion {
+		result.add("schema_version", "expected %d, got %d", CurrentSchemaVersion, dashboard.SchemaVersion)
+		return result
+	}
+
+	layout, err := dashboard.GetLayout()
+	if err != nil {
+		result.add("layout_config", "invalid JSON: %v", err)
+	} else {
+		if layout.Columns < 1 || layout.Columns > 48 {
+			result.add("layout_config.columns", "must be between 1 and 48")
+		}
+		if layout.Rows < 1 {
+			result.add("layout_config.rows", "must be at least 1")
+		}
+		if layout.GridSize < 1 {
+			result.add("layout_config.grid_size", "must be at least 1")
+		}
+		if layout.Gap < 0 {
+			result.add("layout_config.gap", "cannot be negative")
+		}
+	}
+
+	var elements []map[string]interface{}
+	if err := json.Unmarshal([]byte(dashboard.ElementsJSON), &elements); err != nil {
+		result.add("elements_json", "must be a JSON array of objects: %v", err)
+		return result
+	}
+
+	seenIDs := make(map[string]bool)
+	for i, element := range elements {
+		path := fmt.Sprintf("elements[%d]", i)
+
+		id, _ := element["id"].(string)
+		if id == "" {
+			result.add(path+".id", "is required")
+		} else if seenIDs[id] {
+			result.add(path+".id", "duplicate element id %q", id)
+		}
+		seenIDs[id] = true
+
+		elementType, _ := element["type"].(string)
+		spec, known := elementSpecs[elementType]
+		if !known {
+			result.add(path+".type", "unknown element type %q", elementType)
+		}
+
+		validatePosition(result, path+".position", element["position"], layout)
+
+		bindings := validateBindings(result, path+".entity_bindings", element["entity_bindings"])
+		if known && spec.RequiresEntity && bindings == 0 {
+			result.add(path+".entity_bindings", "%s elements require at least one entity binding", elementType)
+		}
+
+		for _, key := range []string{"style", "behavior"} {
+			if value, ok := element[key]; ok && value != nil {
+				if _, isMap := value.(map[string]interface{}); !isMap {
+					result.add(path+"."+key, "must be an object")
+				}
+			}
+		}
+
+		config, hasConfig := element["config"].(map[string]interface{})
+		if element["config"] != nil && !hasConfig {
+			result.add(path+".config", "must be an object")
+			continue
+		}
+		if known {
+			validateConfig(result, path+".config", elementType, spec, config)
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result
+	}
+	return nil
+}
+
+func validatePosition(result *SchemaValidationError, path string, raw interface{}, layout *models.DashboardLayout) {
+	position, ok := raw.(map[string]interface{})
+	if !ok {
+		result.add(path, "is required and must be an object")
+		return
+	}
+
+	values := make(map[string]float64)
+	for _, key := range []string{"x", "y", "width", "height"} {
+		number, ok := position[key].(float64)
+		if !ok {
+			result.add(path+"."+key, "must be a number")
+			continue
+		}
+		if number != float64(int(number)) {
+			result.add(path+"."+key, "must be an integer")
+		}
+		values[key] = number
+	}
+
+	if values["x"] < 0 || values["y"] < 0 {
+		result.add(path, "x and y cannot be negative")
+	}
+	if values["width"] < 1 || values["height"] < 1 {
+		result.add(path, "width and height must be at least 1")
+	}
+	if layout != nil && layout.Columns > 0 && values["x"]+values["width"] > float64(layout.Columns) {
+		result.add(path, "element extends past the %d layout columns", layout.Columns)
+	}
+}
+
+func validateBindings(result *SchemaValidationError, path string, raw interface{}) int {
+	if raw == nil {
+		return 0
+	}
+	bindings, ok := raw.([]interface{})
+	if !ok {
+		result.add(path, "must be an array")
+		return 0
+	}
+
+	for i, rawBinding := range bindings {
+		bindingPath := fmt.Sprintf("%s[%d]", path, i)
+		binding, ok := rawBinding.(map[string]interface{})
+		if !ok {
+			result.add(bindingPath, "must be an object")
+			continue
+		}
+		if entityID, _ := binding["entity_id"].(string); entityID == "" {
+			result.add(bindingPath+".entity_id", "is required")
+		} else if !strings.Contains(entityID, ".") {
+			result.add(bindingPath+".entity_id", "%q is not a valid entity id", entityID)
+		}
+		if mode, _ := binding["update_mode"].(string); mode != "" && !validUpdateModes[mode] {
+			result.add(bindingPath+".update_mode", "unsupported update mode %q", mode)
+		}
+	}
+
+	return len(bindings)
+}
+
+func validateConfig(result *SchemaValidationError, path, elementType string, spec elementSpec, config map[string]interface{}) {
+	for key, field := range spec.Config {
+		value, present := config[key]
+		if !present || value == nil {
+			if field.Required {
+				result.add(path+"."+key, "is required for %s elements", elementType)
+			}
+			continue
+		}
+
+		if !matchesKind(value, field.Kind) {
+			result.add(path+"."+key, "must be of type %s", field.Kind)
+			continue
+		}
+
+		if len(field.Enum) > 0 {
+			str := value.(string)
+			allowed := false
+			for _, option := range field.Enum {
+				if option == str {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				result.add(path+"."+key, "must be one of %s", strings.Join(field.Enum, ", "))
+			}
+		}
+	}
+
+	// Type-specific cross-field checks
+	switch elementType {
+	case "slider":
+		if slider, ok := config["slider"].(map[string]interface{}); ok {
+			min, hasMin := slider["min"].(float64)
+			max, hasMax := slider["max"].(float64)
+			if hasMin && hasMax && min >= max {
+				result.add(path+".slider", "min must be less than max")
+			}
+			if step, ok := slider["step"].(float64); ok && step <= 0 {
+				result.add(path+".slider.step", "must be greater than 0")
+			}
+		}
+	case "gauge":
+		min, hasMin := config["min"].(float64)
+		max, hasMax := config["max"].(float64)
+		if hasMin && hasMax && min >= max {
+			result.add(path, "min must be less than max")
+		}
+	}
+}
+
+func matchesKind(value interface{}, kind string) bool {
+	switch kind {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return true
+}
+
+// DashboardJSONSchema returns a JSON Schema (draft-07) document describing the elements of
+// the current controller dashboard schema version
+func DashboardJSONSchema() map[string]interface{} {
+	types := make([]string, 0, len(elementSpecs))
+	for elementType := range elementSpecs {
+		types = append(types, elementType)
+	}
+	sort.Strings(types)
+
+	variants := make([]interface{}, 0, len(types))
+	for _, elementType := range types {
+		spec := elementSpecs[elementType]
+
+		properties := make(map[string]interface{})
+		required := []string{}
+		for key, field := range spec.Config {
+			property := map[string]interface{}{"type": field.Kind}
+			if len(field.Enum) > 0 {
+				property["enum"] = field.Enum
+			}
+			properties[key] = property
+			if field.Required {
+				required = append(required, key)
+			}
+		}
+		sort.Strings(required)
+
+		variant := map[string]interface{}{
+			"description": spec.Description,
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": elementType}},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"config": map[string]interface{}{
+						"type":       "object",
+						"properties": properties,
+						"required":   required,
+					},
+				},
+			},
+		}
+		if spec.RequiresEntity {
+			variant["then"].(map[string]interface{})["properties"].(map[string]interface{})["entity_bindings"] = map[string]interface{}{
+				"minItems": 1,
+			}
+		}
+		variants = append(variants, variant)
+	}
+
+	modes := make([]string, 0, len(validUpdateModes))
+	for mode := range validUpdateModes {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+
+	integer := func(minimum int) map[string]interface{} {
+		return map[string]interface{}{"type": "integer", "minimum": minimum}
+	}
+
+	return map[string]interface{}{
+		"$schema":        "http://json-schema.org/draft-07/schema#",
+		"$id":            fmt.Sprintf("pma://controller/dashboard/v%d", CurrentSchemaVersion),
+		"title":          "PMA Controller Dashboard",
+		"schema_version": CurrentSchemaVersion,
+		"type":           "object",
+		"properties": map[string]interface{}{
+			"layout": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"columns":    map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 48},
+					"rows":       integer(1),
+					"grid_size":  integer(1),
+					"gap":        integer(0),
+					"responsive": map[string]interface{}{"type": "boolean"},
+				},
+			},
+			"elements": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"id", "type", "position"},
+					"properties": map[string]interface{}{
+						"id":   map[string]interface{}{"type": "string", "minLength": 1},
+						"type": map[string]interface{}{"type": "string", "enum": types},
+						"position": map[string]interface{}{
+							"type":     "object",
+							"required": []string{"x", "y", "width", "height"},
+							"properties": map[string]interface{}{
+								"x":       integer(0),
+								"y":       integer(0),
+								"width":   integer(1),
+								"height":  integer(1),
+								"z_index": map[string]interface{}{"type": "integer"},
+							},
+						},
+						"config":   map[string]interface{}{"type": "object"},
+						"style":    map[string]interface{}{"type": "object"},
+						"behavior": map[string]interface{}{"type": "object"},
+						"entity_bindings": map[string]interface{}{
+							"type": "array",
+							"items": map[string]interface{}{
+								"type":     "object",
+								"required": []string{"entity_id"},
+								"properties": map[string]interface{}{
+									"id":          map[string]interface{}{"type": "string"},
+									"entity_id":   map[string]interface{}{"type": "string", "pattern": `^[a-z0-9_]+\..+$`},
+									"property":    map[string]interface{}{"type": "string"},
+									"update_mode": map[string]interface{}{"type": "string", "enum": modes},
+								},
+							},
+						},
+					},
+					"allOf": variants,
+				},
+			},
+		},
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/frostdev-ops/pma-backend-go/internal/config"
@@ -22,6 +20,7 @@ type Service struct {
 	controllerRepo repositories.ControllerRepository
 	userRepo       repositories.UserRepository
 	entityRepo     repositories.EntityRepository
+	roomRepo       repositories.RoomRepository
 	unifiedService *unified.UnifiedEntityService
 	wsHub          *websocket.Hub
 	logger         *logrus.Logger
@@ -33,6 +32,7 @@ func NewService(
 	controllerRepo repositories.ControllerRepository,
 	userRepo repositories.UserRepository,
 	entityRepo repositories.EntityRepository,
+	roomRepo repositories.RoomRepository,
 	unifiedService *unified.UnifiedEntityService,
 	wsHub *websocket.Hub,
 	logger *logrus.Logger,
@@ -42,6 +42,7 @@ func NewService(
 		controllerRepo: controllerRepo,
 		userRepo:       userRepo,
 		entityRepo:     entityRepo,
+		roomRepo:       roomRepo,
 		unifiedService: unifiedService,
 		wsHub:          wsHub,
 		logger:         logger,
@@ -67,9 +68,9 @@ func (s *Service) CreateDashboard(ctx context.Context, dashboard *models.Control
 	}
 	dashboard.UserID = userID
 
-	// Ensure default configurations are valid JSON
-	if err := s.setDefaultConfigurations(dashboard); err != nil {
-		return nil, fmt.Errorf("failed to set default configurations: %w", err)
+	// Ensure default configurations are valid JSON and match the current schema
+	if err := s.prepareDashboard(dashboard); err != nil {
+		return nil, fmt.Errorf("dashboard validation failed: %w", err)
 	}
 
 	// Create dashboard
@@ -78,6 +79,8 @@ func (s *Service) CreateDashboard(ctx context.Context, dashboard *models.Control
 		return nil, fmt.Errorf("failed to create dashboard: %w", err)
 	}
 
+	s.recordRevision(ctx, dashboard, "Created", userID)
+
 	// Log usage
 	s.logUsage(ctx, dashboard.ID, userID, "create", nil, nil, nil)
 
@@ -108,6 +111,11 @@ func (s *Service) GetDashboard(ctx context.Context, dashboardID int, userID *int
 		return nil, fmt.Errorf("failed to get dashboard: %w", err)
 	}
 
+	// Serve older dashboards in the current schema; they are persisted on the next save
+	if _, err := MigrateDashboard(dashboard); err != nil {
+		s.logger.WithError(err).WithField("dashboard_id", dashboardID).Warn("Failed to migrate dashboard schema")
+	}
+
 	// Update last accessed timestamp
 	go func() {
 		s.controllerRepo.UpdateLastAccessed(context.Background(), dashboardID)
@@ -166,12 +174,27 @@ func (s *Service) UpdateDashboard(ctx context.Context, dashboard *models.Control
 		return fmt.Errorf("dashboard validation failed: %w", err)
 	}
 
+	existing, err := s.controllerRepo.GetDashboardByID(ctx, dashboard.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get dashboard: %w", err)
+	}
+
+	if err := s.prepareDashboard(dashboard); err != nil {
+		return fmt.Errorf("dashboard validation failed: %w", err)
+	}
+
+	// Preserve the state being replaced, then bump the revision
+	s.ensureRevision(ctx, existing, userID)
+	dashboard.Version = existing.Version + 1
+
 	// Update dashboard
-	err := s.controllerRepo.UpdateDashboard(ctx, dashboard)
+	err = s.controllerRepo.UpdateDashboard(ctx, dashboard)
 	if err != nil {
 		return fmt.Errorf("failed to update dashboard: %w", err)
 	}
 
+	s.recordRevision(ctx, dashboard, "Updated", userID)
+
 	// Log usage
 	s.logUsage(ctx, dashboard.ID, userID, "update", nil, nil, nil)
 
@@ -329,8 +352,13 @@ func (s *Service) ApplyTemplate(ctx context.Context, templateID int, dashboardNa
 		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	// Apply variables to template
-	processedData, err := s.applyTemplateVariables(templateData, variables)
+	// Resolve typed variables, then apply them to the template
+	resolved, err := s.resolveTemplateVariables(ctx, template, variables)
+	if err != nil {
+		return nil, fmt.Errorf("template validation failed: %w", err)
+	}
+
+	processedData, err := s.applyTemplateVariables(templateData, resolved)
 	if err != nil {
 		return nil, fmt.Errorf("failed to apply template variables: %w", err)
 	}
@@ -340,9 +368,15 @@ func (s *Service) ApplyTemplate(ctx context.Context, templateID int, dashboardNa
 		Name:        dashboardName,
 		Description: template.Description,
 		Category:    template.Category,
+		Version:     1,
 		UserID:      userID,
 	}
 
+	// Templates declare the element schema version they were written for
+	if schemaVersion, ok := processedData["schema_version"].(float64); ok {
+		dashboard.SchemaVersion = int(schemaVersion)
+	}
+
 	// Set configurations from template
 	if layout, ok := processedData["layout"]; ok {
 		layoutJSON, _ := json.Marshal(layout)
@@ -357,9 +391,12 @@ func (s *Service) ApplyTemplate(ctx context.Context, templateID int, dashboardNa
 		dashboard.StyleConfig = string(styleJSON)
 	}
 
-	// Set default configurations
-	if err := s.setDefaultConfigurations(dashboard); err != nil {
-		return nil, fmt.Errorf("failed to set default configurations: %w", err)
+	// Set default configurations and validate the generated elements
+	if err := s.validateDashboard(dashboard); err != nil {
+		return nil, fmt.Errorf("dashboard validation failed: %w", err)
+	}
+	if err := s.prepareDashboard(dashboard); err != nil {
+		return nil, fmt.Errorf("dashboard validation failed: %w", err)
 	}
 
 	// Create dashboard
@@ -368,6 +405,8 @@ func (s *Service) ApplyTemplate(ctx context.Context, templateID int, dashboardNa
 		return nil, fmt.Errorf("failed to create dashboard from template: %w", err)
 	}
 
+	s.recordRevision(ctx, dashboard, fmt.Sprintf("Created from template %d", templateID), userID)
+
 	// Increment template usage
 	go s.controllerRepo.IncrementTemplateUsage(context.Background(), templateID)
 
@@ -491,11 +530,17 @@ func (s *Service) ExportDashboard(ctx context.Context, dashboardID int, userID *
 
 // ImportDashboard imports a dashboard from JSON
 func (s *Service) ImportDashboard(ctx context.Context, data map[string]interface{}, userID *int) (*models.ControllerDashboard, error) {
+	if err := s.prepareImport(data); err != nil {
+		return nil, fmt.Errorf("dashboard validation failed: %w", err)
+	}
+
 	dashboard, err := s.controllerRepo.ImportDashboard(ctx, data, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to import dashboard: %w", err)
 	}
 
+	s.recordRevision(ctx, dashboard, "Imported", userID)
+
 	// Log usage
 	s.logUsage(ctx, dashboard.ID, userID, "import", nil, nil, nil)
 
@@ -580,9 +625,126 @@ func (s *Service) validateTemplate(template *models.ControllerTemplate) error {
 	if err != nil {
 		return fmt.Errorf("invalid template JSON: %w", err)
 	}
+	return validateTemplateVariables(template)
+}
+
+// prepareDashboard fills in default configurations, migrates the elements to the current
+// schema version and validates them against that schema
+func (s *Service) prepareDashboard(dashboard *models.ControllerDashboard) error {
+	if err := s.setDefaultConfigurations(dashboard); err != nil {
+		return fmt.Errorf("failed to set default configurations: %w", err)
+	}
+
+	if _, err := MigrateDashboard(dashboard); err != nil {
+		return err
+	}
+
+	return ValidateDashboardSchema(dashboard)
+}
+
+// prepareImport migrates and validates the dashboard contained in an export document,
+// rewriting it in the current schema version
+func (s *Service) prepareImport(data map[string]interface{}) error {
+	dashboardData, ok := data["dashboard"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid dashboard data structure")
+	}
+
+	name, _ := dashboardData["name"].(string)
+	dashboard := &models.ControllerDashboard{Name: name}
+	if err := s.validateDashboard(dashboard); err != nil {
+		return err
+	}
+	for _, key := range []string{"description", "category"} {
+		if _, ok := dashboardData[key].(string); !ok {
+			dashboardData[key] = ""
+		}
+	}
+
+	if schemaVersion, ok := dashboardData["schema_version"].(float64); ok {
+		dashboard.SchemaVersion = int(schemaVersion)
+	}
+	for key, target := range map[string]*string{
+		"layout":   &dashboard.LayoutConfig,
+		"elements": &dashboard.ElementsJSON,
+		"style":    &dashboard.StyleConfig,
+		"access":   &dashboard.AccessConfig,
+		"tags":     &dashboard.Tags,
+	} {
+		if value, ok := dashboardData[key]; ok && value != nil {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*target = string(encoded)
+		}
+	}
+
+	if err := s.prepareDashboard(dashboard); err != nil {
+		return err
+	}
+
+	dashboardData["layout"] = json.RawMessage(dashboard.LayoutConfig)
+	dashboardData["elements"] = json.RawMessage(dashboard.ElementsJSON)
+	dashboardData["style"] = json.RawMessage(dashboard.StyleConfig)
+	dashboardData["access"] = json.RawMessage(dashboard.AccessConfig)
+	dashboardData["tags"] = json.RawMessage(dashboard.Tags)
+	dashboardData["schema_version"] = dashboard.SchemaVersion
+
 	return nil
 }
 
+// MigrateAllDashboards upgrades every stored dashboard to the current schema version,
+// recording a revision for each dashboard that changed
+func (s *Service) MigrateAllDashboards(ctx context.Context) (map[string]interface{}, error) {
+	dashboards, err := s.controllerRepo.GetAllDashboards(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboards: %w", err)
+	}
+
+	migrated := 0
+	failures := make(map[string]string)
+	for _, dashboard := range dashboards {
+		if dashboard.SchemaVersion <= 0 {
+			dashboard.SchemaVersion = 1
+		}
+		original := *dashboard
+		changed, err := MigrateDashboard(dashboard)
+		if err == nil && changed {
+			err = ValidateDashboardSchema(dashboard)
+		}
+		if err != nil {
+			failures[fmt.Sprintf("%d", dashboard.ID)] = err.Error()
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		s.ensureRevision(ctx, &original, nil)
+		dashboard.Version++
+		if err := s.controllerRepo.UpdateDashboard(ctx, dashboard); err != nil {
+			failures[fmt.Sprintf("%d", dashboard.ID)] = err.Error()
+			continue
+		}
+		s.recordRevision(ctx, dashboard, fmt.Sprintf("Migrated from schema version %d", original.SchemaVersion), nil)
+		migrated++
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"migrated": migrated,
+		"failed":   len(failures),
+		"total":    len(dashboards),
+	}).Info("Controller dashboard schema migration completed")
+
+	return map[string]interface{}{
+		"schema_version": CurrentSchemaVersion,
+		"total":          len(dashboards),
+		"migrated":       migrated,
+		"failed":         failures,
+	}, nil
+}
+
 // setDefaultConfigurations ensures dashboard has valid default configurations
 func (s *Service) setDefaultConfigurations(dashboard *models.ControllerDashboard) error {
 	// Set default layout if empty
@@ -666,7 +828,7 @@ func (s *Service) processTemplateValue(value interface{}, variables map[string]i
 	switch v := value.(type) {
 	case string:
 		// Replace variable placeholders like {{variable_name}}
-		return s.substituteStringVariables(v, variables), nil
+		return substituteValue(v, variables), nil
 
 	case map[string]interface{}:
 		// Recursively process nested maps
@@ -698,42 +860,6 @@ func (s *Service) processTemplateValue(value interface{}, variables map[string]i
 	}
 }
 
-// substituteStringVariables replaces variable placeholders in strings
-func (s *Service) substituteStringVariables(str string, variables map[string]interface{}) string {
-	// Use regular expression to find and replace {{variable_name}} patterns
-	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
-
-	return re.ReplaceAllStringFunc(str, func(match string) string {
-		// Extract variable name (remove {{ and }})
-		varName := strings.TrimSpace(match[2 : len(match)-2])
-
-		// Look up variable value
-		if value, exists := variables[varName]; exists {
-			// Convert value to string
-			switch v := value.(type) {
-			case string:
-				return v
-			case int, int64, float64:
-				return fmt.Sprintf("%v", v)
-			case bool:
-				if v {
-					return "true"
-				}
-				return "false"
-			default:
-				// For complex types, try JSON marshaling
-				if data, err := json.Marshal(v); err == nil {
-					return string(data)
-				}
-				return fmt.Sprintf("%v", v)
-			}
-		}
-
-		// Variable not found, return original placeholder
-		return match
-	})
-}
-
 // executeElementActions executes actions for a dashboard element
 func (s *Service) executeElementActions(ctx context.Context, element *models.DashboardElement) error {
 	// Handle different element types and their actions
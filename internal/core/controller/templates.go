@@ -0,0 +1,397 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// Template variable types
+const (
+	VariableTypeString  = "string"
+	VariableTypeNumber  = "number"
+	VariableTypeBoolean = "boolean"
+	VariableTypeColor   = "color"
+	VariableTypeSelect  = "select"
+	VariableTypeEntity  = "entity" // Entity picker
+	VariableTypeRoom    = "room"   // Room picker
+)
+
+var (
+	validVariableTypes = map[string]bool{
+		VariableTypeString:  true,
+		VariableTypeNumber:  true,
+		VariableTypeBoolean: true,
+		VariableTypeColor:   true,
+		VariableTypeSelect:  true,
+		VariableTypeEntity:  true,
+		VariableTypeRoom:    true,
+	}
+
+	// legacyVariableTypes maps variable types of older templates to their current names
+	legacyVariableTypes = map[string]string{
+		"text": VariableTypeString,
+	}
+
+	variableNamePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	colorPattern         = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	placeholderPattern   = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+	wholePlaceholderExpr = regexp.MustCompile(`^\{\{\s*([^}]+?)\s*\}\}$`)
+)
+
+// VariableChoice is a selectable value offered by a template variable picker
+type VariableChoice struct {
+	Value interface{} `json:"value"`
+	Label string      `json:"label"`
+	Group string      `json:"group,omitempty"`
+}
+
+// TemplateVariableInfo describes a template variable together with the choices available for it
+type TemplateVariableInfo struct {
+	models.TemplateVariable
+	Choices []VariableChoice `json:"choices,omitempty"`
+}
+
+// templateVariables parses a template's variables, renaming legacy variable types
+func templateVariables(template *models.ControllerTemplate) ([]models.TemplateVariable, error) {
+	variables, err := template.GetVariables()
+	if err != nil {
+		return nil, err
+	}
+	for i := range variables {
+		if current, ok := legacyVariableTypes[variables[i].Type]; ok {
+			variables[i].Type = current
+		}
+	}
+	return variables, nil
+}
+
+// validateTemplateVariables checks variable definitions and that every placeholder in the
+// template refers to a declared variable
+func validateTemplateVariables(template *models.ControllerTemplate) error {
+	if strings.TrimSpace(template.VariablesJSON) == "" {
+		return nil
+	}
+
+	variables, err := templateVariables(template)
+	if err != nil {
+		return fmt.Errorf("invalid variables JSON: %w", err)
+	}
+
+	declared := make(map[string]bool)
+	for _, variable := range variables {
+		if !variableNamePattern.MatchString(variable.Name) {
+			return fmt.Errorf("invalid variable name %q", variable.Name)
+		}
+		if declared[variable.Name] {
+			return fmt.Errorf("duplicate variable %q", variable.Name)
+		}
+		declared[variable.Name] = true
+
+		if !validVariableTypes[variable.Type] {
+			return fmt.Errorf("variable %q has unsupported type %q", variable.Name, variable.Type)
+		}
+		if variable.Type == VariableTypeSelect && len(variable.Options) == 0 {
+			return fmt.Errorf("select variable %q requires options", variable.Name)
+		}
+		if variable.Min != nil && variable.Max != nil && *variable.Min > *variable.Max {
+			return fmt.Errorf("variable %q has min greater than max", variable.Name)
+		}
+		if variable.Default != nil {
+			if _, err := coerceBasicVariable(variable, variable.Default); err != nil {
+				return fmt.Errorf("invalid default for variable %q: %w", variable.Name, err)
+			}
+		}
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template.TemplateJSON, -1) {
+		name := strings.TrimSpace(match[1])
+		if !declared[name] {
+			return fmt.Errorf("template references undeclared variable %q", name)
+		}
+	}
+
+	return nil
+}
+
+// resolveTemplateVariables applies defaults, checks required variables and converts each
+// provided value to its declared type. Entity and room pickers are verified to exist.
+// Variables that are not declared by the template are passed through unchanged.
+func (s *Service) resolveTemplateVariables(ctx context.Context, template *models.ControllerTemplate, provided map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(provided))
+	for name, value := range provided {
+		resolved[name] = value
+	}
+
+	if strings.TrimSpace(template.VariablesJSON) == "" {
+		return resolved, nil
+	}
+
+	variables, err := templateVariables(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template variables: %w", err)
+	}
+
+	for _, variable := range variables {
+		value, ok := provided[variable.Name]
+		if !ok || value == nil || value == "" {
+			value = variable.Default
+		}
+		if value == nil || value == "" {
+			if variable.Required {
+				return nil, fmt.Errorf("variable %q is required", variable.Name)
+			}
+			delete(resolved, variable.Name)
+			continue
+		}
+
+		var coerced interface{}
+		switch variable.Type {
+		case VariableTypeEntity:
+			coerced, err = s.resolveEntityVariable(ctx, variable, value)
+		case VariableTypeRoom:
+			coerced, err = s.resolveRoomVariable(ctx, value)
+		default:
+			coerced, err = coerceBasicVariable(variable, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", variable.Name, err)
+		}
+		resolved[variable.Name] = coerced
+	}
+
+	return resolved, nil
+}
+
+// coerceBasicVariable converts a value to the type of a non-picker variable
+func coerceBasicVariable(variable models.TemplateVariable, value interface{}) (interface{}, error) {
+	switch variable.Type {
+	case VariableTypeNumber:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number, got %q", v)
+			}
+			number = parsed
+		default:
+			return nil, fmt.Errorf("expected a number")
+		}
+		if variable.Min != nil && number < *variable.Min {
+			return nil, fmt.Errorf("must be at least %v", *variable.Min)
+		}
+		if variable.Max != nil && number > *variable.Max {
+			return nil, fmt.Errorf("must be at most %v", *variable.Max)
+		}
+		return number, nil
+
+	case VariableTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("expected a boolean, got %q", v)
+			}
+			return parsed, nil
+		}
+		return nil, fmt.Errorf("expected a boolean")
+
+	case VariableTypeColor:
+		str, ok := value.(string)
+		if !ok || !colorPattern.MatchString(str) {
+			return nil, fmt.Errorf("expected a hex color like #ff8800")
+		}
+		return str, nil
+
+	case VariableTypeSelect:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected one of %s", strings.Join(variable.Options, ", "))
+		}
+		for _, option := range variable.Options {
+			if option == str {
+				return str, nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of %s", strings.Join(variable.Options, ", "))
+
+	case VariableTypeEntity, VariableTypeRoom, VariableTypeString:
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+		return fmt.Sprintf("%v", value), nil
+	}
+
+	return value, nil
+}
+
+// resolveEntityVariable verifies an entity picker value refers to an existing entity of the
+// expected domain
+func (s *Service) resolveEntityVariable(ctx context.Context, variable models.TemplateVariable, value interface{}) (interface{}, error) {
+	entityID, ok := value.(string)
+	if !ok || !strings.Contains(entityID, ".") {
+		return nil, fmt.Errorf("expected an entity id like light.kitchen")
+	}
+
+	if variable.EntityType != "" && !strings.HasPrefix(entityID, variable.EntityType+".") {
+		return nil, fmt.Errorf("entity %s is not a %s entity", entityID, variable.EntityType)
+	}
+
+	if s.unifiedService != nil {
+		if _, err := s.unifiedService.GetByID(ctx, entityID, unified.GetEntityOptions{}); err != nil {
+			return nil, fmt.Errorf("entity %s not found", entityID)
+		}
+	} else if s.entityRepo != nil {
+		if _, err := s.entityRepo.GetByID(ctx, entityID); err != nil {
+			return nil, fmt.Errorf("entity %s not found", entityID)
+		}
+	}
+
+	return entityID, nil
+}
+
+// resolveRoomVariable verifies a room picker value refers to an existing room
+func (s *Service) resolveRoomVariable(ctx context.Context, value interface{}) (interface{}, error) {
+	var roomID int
+	switch v := value.(type) {
+	case float64:
+		roomID = int(v)
+	case int:
+		roomID = v
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected a room id, got %q", v)
+		}
+		roomID = parsed
+	default:
+		return nil, fmt.Errorf("expected a room id")
+	}
+
+	if s.roomRepo != nil {
+		if _, err := s.roomRepo.GetByID(ctx, roomID); err != nil {
+			return nil, fmt.Errorf("room %d not found", roomID)
+		}
+	}
+
+	return roomID, nil
+}
+
+// GetTemplateVariables returns a template's typed variables with the choices available to
+// entity, room and select pickers
+func (s *Service) GetTemplateVariables(ctx context.Context, templateID int) ([]TemplateVariableInfo, error) {
+	template, err := s.controllerRepo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	if strings.TrimSpace(template.VariablesJSON) == "" {
+		return []TemplateVariableInfo{}, nil
+	}
+
+	variables, err := templateVariables(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template variables: %w", err)
+	}
+
+	infos := make([]TemplateVariableInfo, 0, len(variables))
+	for _, variable := range variables {
+		info := TemplateVariableInfo{TemplateVariable: variable}
+
+		switch variable.Type {
+		case VariableTypeSelect:
+			for _, option := range variable.Options {
+				info.Choices = append(info.Choices, VariableChoice{Value: option, Label: option})
+			}
+
+		case VariableTypeEntity:
+			if s.unifiedService != nil {
+				entities, err := s.unifiedService.GetAll(ctx, unified.GetAllOptions{Domain: variable.EntityType, IncludeRoom: true})
+				if err != nil {
+					return nil, fmt.Errorf("failed to list entities for variable %q: %w", variable.Name, err)
+				}
+				for _, entity := range entities {
+					choice := VariableChoice{
+						Value: entity.Entity.GetID(),
+						Label: entity.Entity.GetFriendlyName(),
+					}
+					if entity.Room != nil {
+						choice.Group = entity.Room.Name
+					}
+					info.Choices = append(info.Choices, choice)
+				}
+				sort.Slice(info.Choices, func(i, j int) bool {
+					return info.Choices[i].Label < info.Choices[j].Label
+				})
+			}
+
+		case VariableTypeRoom:
+			if s.roomRepo != nil {
+				rooms, err := s.roomRepo.GetAll(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list rooms for variable %q: %w", variable.Name, err)
+				}
+				for _, room := range rooms {
+					info.Choices = append(info.Choices, VariableChoice{Value: room.ID, Label: room.Name})
+				}
+			}
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// substituteValue replaces placeholders in a template string. A string consisting of a single
+// placeholder is replaced by the typed variable value so numbers and booleans keep their type.
+func substituteValue(str string, variables map[string]interface{}) interface{} {
+	if match := wholePlaceholderExpr.FindStringSubmatch(str); match != nil {
+		if value, exists := variables[match[1]]; exists {
+			return value
+		}
+		return str
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(str, func(match string) string {
+		varName := strings.TrimSpace(match[2 : len(match)-2])
+
+		value, exists := variables[varName]
+		if !exists {
+			// Variable not found, return original placeholder
+			return match
+		}
+
+		switch v := value.(type) {
+		case string:
+			return v
+		case int, int64, float64:
+			return fmt.Sprintf("%v", v)
+		case bool:
+			if v {
+				return "true"
+			}
+			return "false"
+		default:
+			// For complex types, try JSON marshaling
+			if data, err := json.Marshal(v); err == nil {
+				return string(data)
+			}
+			return fmt.Sprintf("%v", v)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// TestApplySeededTemplates applies every template the migrations seed, picking an entity of
+// the requested domain for each entity variable
+func TestApplySeededTemplates(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "pma.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.Migrate(db, "../../../migrations"))
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	service := NewService(sqlite.NewControllerRepository(db), nil, nil, nil, nil, nil, logger, nil)
+
+	templates, err := service.GetTemplates(ctx, nil, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, templates)
+
+	for _, template := range templates {
+		t.Run(template.Name, func(t *testing.T) {
+			require.NoError(t, validateTemplateVariables(template))
+
+			variables, err := templateVariables(template)
+			require.NoError(t, err)
+			values := make(map[string]interface{})
+			for _, variable := range variables {
+				if variable.Type == VariableTypeEntity {
+					values[variable.Name] = variable.EntityType + ".seeded_template_test"
+				}
+			}
+
+			dashboard, err := service.ApplyTemplate(ctx, template.ID, template.Name+" dashboard", values, nil)
+			require.NoError(t, err)
+			assert.Equal(t, CurrentSchemaVersion, dashboard.SchemaVersion)
+			assert.NotContains(t, dashboard.ElementsJSON, "{{")
+		})
+	}
+}
+
+func TestMigrateLegacyElements(t *testing.T) {
+	dashboard := &models.ControllerDashboard{
+		Name:         "Legacy",
+		LayoutConfig: `{"columns":12,"rows":8,"grid_size":64,"gap":8}`,
+		ElementsJSON: `[{"type":"slider","position":{"x":0,"y":0,"w":4,"h":2},"config":{"label":"Brightness","min":0,"max":100,"step":1,"entity_id":"light.desk"}},` +
+			`{"type":"display_value","position":{"x":4,"y":0,"w":3,"h":1},"config":{"label":"Temperature","unit":"°C","entity_id":"sensor.desk"}}]`,
+	}
+
+	migrated, err := MigrateDashboard(dashboard)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	require.NoError(t, ValidateDashboardSchema(dashboard))
+
+	elements, err := dashboard.GetElements()
+	require.NoError(t, err)
+	require.Len(t, elements, 2)
+	assert.Equal(t, map[string]interface{}{"min": 0.0, "max": 100.0, "step": 1.0}, elements[0].Config["slider"])
+	assert.Equal(t, "sensor", elements[1].Type)
+	assert.Equal(t, "sensor.desk", elements[1].EntityBindings[0].EntityID)
+}
@@ -40,6 +40,10 @@ func NewLocalStorage(cfg *config.FileManagerConfig, db *sql.DB, logger *logrus.L
 	}
 
 	for _, dir := range dirs {
+		if dir == "" {
+			// Not every file manager instance uses every area
+			continue
+		}
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
@@ -0,0 +1,556 @@
+package homecontext
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/sirupsen/logrus"
+)
+
+// Relevance of an entity to a message
+const (
+	scoreEntityID = 10 // The message names the entity ID
+	scoreName     = 6  // The message contains every word of the entity name
+	scoreRoom     = 4  // The message names the entity's room
+	scoreArea     = 3  // The message names the entity's area
+	scoreType     = 3  // The message names the entity type
+	scoreRecent   = 2  // The entity changed recently
+)
+
+// typeWords are the words that name each entity type in a message
+var typeWords = map[types.PMAEntityType][]string{
+	types.EntityTypeLight:        {"light", "lights", "lamp", "lamps", "lighting", "bright", "dim"},
+	types.EntityTypeSwitch:       {"switch", "switches", "plug", "plugs", "outlet", "outlets"},
+	types.EntityTypeClimate:      {"thermostat", "thermostats", "heating", "heater", "cooling", "climate", "hvac", "warm", "cold"},
+	types.EntityTypeSensor:       {"sensor", "sensors", "temperature", "humidity", "power", "energy", "battery"},
+	types.EntityTypeBinarySensor: {"motion", "door", "doors", "window", "windows", "occupancy", "leak", "open"},
+	types.EntityTypeLock:         {"lock", "locks", "locked", "unlocked", "door", "doors"},
+	types.EntityTypeCover:        {"blind", "blinds", "shade", "shades", "curtain", "curtains", "shutter", "shutters", "garage", "cover", "covers"},
+	types.EntityTypeFan:          {"fan", "fans"},
+	types.EntityTypeMediaPlayer:  {"tv", "television", "speaker", "speakers", "music", "media", "playing"},
+	types.EntityTypeCamera:       {"camera", "cameras"},
+	types.EntityTypePerson:       {"home", "away", "who", "person", "people"},
+}
+
+// inactiveStates are states that are not worth pointing out in summaries
+var inactiveStates = map[string]bool{
+	"off": true, "closed": true, "locked": true, "idle": true, "standby": true, "paused": true,
+	"unavailable": true, "unknown": true, "not_home": true, "docked": true, "disarmed": true, "": true,
+}
+
+// salientAttributes are the attributes shown for entities listed individually, in order
+var salientAttributes = []string{
+	"brightness", "current_temperature", "temperature", "target_temperature", "hvac_mode",
+	"current_position", "position", "media_title", "battery_level",
+}
+
+// EntityService is the part of the unified entity service the builder uses
+type EntityService interface {
+	GetAll(ctx context.Context, options unified.GetAllOptions) ([]*unified.EntityWithRoom, error)
+}
+
+// Builder renders the home state for LLM prompts: the entities relevant to the message in
+// detail, and every entity summarized by area and room, within the token budget of the
+// provider. Entity states are read once per snapshot and reused between turns.
+type Builder struct {
+	entities     EntityService
+	cfg          config.HomeContextConfig
+	recentWindow time.Duration
+	cacheTTL     time.Duration
+	logger       *logrus.Logger
+
+	mu       sync.Mutex
+	snapshot *snapshot
+}
+
+// snapshot holds the entity states read for a few turns and the contexts rendered from them
+type snapshot struct {
+	takenAt  time.Time
+	entries  []*entry
+	rendered map[string]*ai.HomeContext
+}
+
+// entry is an entity with the names the message may use for it
+type entry struct {
+	entity    types.PMAEntity
+	name      string
+	room      string
+	area      string
+	nameWords []string
+	idPhrase  string
+}
+
+// NewBuilder creates a home context builder
+func NewBuilder(cfg config.HomeContextConfig, entities EntityService, logger *logrus.Logger) *Builder {
+	if cfg.MaxEntities <= 0 {
+		cfg.MaxEntities = 40
+	}
+	return &Builder{
+		entities:     entities,
+		cfg:          cfg,
+		recentWindow: parseDuration(cfg.RecentWindow, 15*time.Minute),
+		cacheTTL:     parseDuration(cfg.CacheTTL, 30*time.Second),
+		logger:       logger,
+	}
+}
+
+// ObserveEntities drops the snapshot when a device changes state, so the assistant does not
+// see a light as off right after turning it on. Sensor readings refresh with the snapshot.
+func (b *Builder) ObserveEntities(entities *unified.UnifiedEntityService) {
+	entities.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+		if domain, _, _ := strings.Cut(entityID, "."); domain != string(types.EntityTypeSensor) {
+			b.Invalidate()
+		}
+	})
+}
+
+// Invalidate drops the snapshot; the next context reads the entity states again
+func (b *Builder) Invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.snapshot = nil
+}
+
+// HomeContext renders the home state relevant to the message within the budget
+func (b *Builder) HomeContext(ctx context.Context, message string, budget ai.TokenBudget) (*ai.HomeContext, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.snapshot == nil || time.Since(b.snapshot.takenAt) > b.cacheTTL {
+		snap, err := b.takeSnapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.snapshot = snap
+	}
+	snap := b.snapshot
+
+	relevant := b.relevant(snap, message)
+	ids := make([]string, len(relevant))
+	for i, e := range relevant {
+		ids[i] = e.entity.GetID()
+	}
+	key := fmt.Sprintf("%s|%d|%s", budget.Provider, budget.MaxTokens, strings.Join(ids, ","))
+	if cached, ok := snap.rendered[key]; ok {
+		home := *cached
+		home.Cached = true
+		return &home, nil
+	}
+
+	home := render(snap, relevant, budget)
+	snap.rendered[key] = home
+	result := *home
+	return &result, nil
+}
+
+func (b *Builder) takeSnapshot(ctx context.Context) (*snapshot, error) {
+	all, err := b.entities.GetAll(ctx, unified.GetAllOptions{IncludeRoom: true, IncludeArea: true, ExcludeHidden: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	snap := &snapshot{takenAt: time.Now(), rendered: make(map[string]*ai.HomeContext)}
+	for _, item := range all {
+		if item == nil || item.Entity == nil {
+			continue
+		}
+		e := &entry{entity: item.Entity, name: item.Entity.GetFriendlyName()}
+		if e.name == "" {
+			e.name = item.Entity.GetID()
+		}
+		if item.Room != nil {
+			e.room = item.Room.Name
+		}
+		if item.Area != nil {
+			e.area = item.Area.Name
+		}
+		// Words of the room repeated in the name ("Kitchen Ceiling") do not identify the entity
+		roomWords := make(map[string]bool)
+		for _, word := range strings.Fields(normalize(e.room)) {
+			roomWords[word] = true
+		}
+		for _, word := range strings.Fields(normalize(e.name)) {
+			if len(word) > 2 && !roomWords[word] {
+				e.nameWords = append(e.nameWords, word)
+			}
+		}
+		if _, object, ok := strings.Cut(item.Entity.GetID(), "."); ok {
+			e.idPhrase = normalize(object)
+		}
+		snap.entries = append(snap.entries, e)
+	}
+	return snap, nil
+}
+
+// relevant returns the entities relevant to the message, most relevant first
+func (b *Builder) relevant(snap *snapshot, message string) []*entry {
+	text := " " + normalize(message) + " "
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(text) {
+		words[word] = true
+	}
+
+	mentionedTypes := make(map[types.PMAEntityType]bool)
+	for entityType, names := range typeWords {
+		for _, name := range names {
+			if words[name] {
+				mentionedTypes[entityType] = true
+				break
+			}
+		}
+	}
+
+	// Rooms and areas named in the message
+	mentionedPlaces := make(map[string]bool)
+	for _, e := range snap.entries {
+		for _, place := range []string{e.room, e.area} {
+			if place != "" && strings.Contains(text, " "+normalize(place)+" ") {
+				mentionedPlaces[place] = true
+			}
+		}
+	}
+
+	type scored struct {
+		e     *entry
+		score int
+	}
+	var candidates []scored
+	for _, e := range snap.entries {
+		score := 0
+		if e.idPhrase != "" && strings.Contains(text, " "+e.idPhrase+" ") {
+			score += scoreEntityID
+		} else if len(e.nameWords) > 0 && containsAll(words, e.nameWords) {
+			score += scoreName
+		}
+
+		// A place and a type named together ("kitchen lights") narrow each other: the lights
+		// of other rooms and the other devices of the kitchen are not relevant
+		placeScore := 0
+		if mentionedPlaces[e.room] {
+			placeScore = scoreRoom
+		} else if mentionedPlaces[e.area] {
+			placeScore = scoreArea
+		}
+		typeMatched := mentionedTypes[e.entity.GetType()]
+		if len(mentionedTypes) == 0 || typeMatched {
+			score += placeScore
+		}
+		if typeMatched && (len(mentionedPlaces) == 0 || placeScore > 0) {
+			score += scoreType
+		}
+
+		if e.entity.GetType() != types.EntityTypeSensor && snap.takenAt.Sub(e.entity.GetLastUpdated()) <= b.recentWindow {
+			score += scoreRecent
+		}
+		if score > 0 {
+			candidates = append(candidates, scored{e: e, score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		ti, tj := candidates[i].e.entity.GetLastUpdated(), candidates[j].e.entity.GetLastUpdated()
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return candidates[i].e.name < candidates[j].e.name
+	})
+
+	if len(candidates) > b.cfg.MaxEntities {
+		candidates = candidates[:b.cfg.MaxEntities]
+	}
+	relevant := make([]*entry, len(candidates))
+	for i, c := range candidates {
+		relevant[i] = c.e
+	}
+	return relevant
+}
+
+// render lays out the relevant entities and the best summary that fit the budget. The one-line
+// totals are reserved first, so the prompt always knows the size of the home. Text is measured
+// as a whole, since estimates of the parts need not add up to the estimate of the total.
+func render(snap *snapshot, relevant []*entry, budget ai.TokenBudget) *ai.HomeContext {
+	home := &ai.HomeContext{GeneratedAt: snap.takenAt}
+	if len(snap.entries) == 0 {
+		return home
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Home state at %s (%d entities).\n", snap.takenAt.Format("2006-01-02 15:04"), len(snap.entries))
+	totals := summarizeTotals(snap.entries)
+	fits := func(text ...string) bool {
+		return budget.Tokens(strings.TrimRight(b.String()+strings.Join(text, ""), "\n")) <= budget.MaxTokens
+	}
+	if !fits(totals) {
+		return home
+	}
+
+	if heading := "Devices relevant to this message:\n"; len(relevant) > 0 && fits(heading, totals) {
+		b.WriteString(heading)
+		for i, e := range relevant {
+			line := entityLine(e, snap.takenAt)
+			if !fits(line, totals) {
+				if more := fmt.Sprintf("- ...and %d more\n", len(relevant)-i); fits(more, totals) {
+					b.WriteString(more)
+				}
+				break
+			}
+			b.WriteString(line)
+			home.Relevant++
+		}
+	}
+
+	// The most detailed summary that fits
+	for _, summary := range []string{summarizeRooms(snap.entries), summarizeAreas(snap.entries), totals} {
+		if fits(summary) {
+			b.WriteString(summary)
+			break
+		}
+	}
+
+	home.Text = strings.TrimRight(b.String(), "\n")
+	home.Tokens = budget.Tokens(home.Text)
+	home.Summarized = len(snap.entries) - home.Relevant
+	return home
+}
+
+// entityLine describes one entity: "- Ceiling (light.kitchen_ceiling) in Kitchen: on, brightness 80, changed 2m ago"
+func entityLine(e *entry, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- %s (%s)", e.name, e.entity.GetID())
+	if location := joinNonEmpty(e.area, e.room); location != "" {
+		fmt.Fprintf(&b, " in %s", location)
+	}
+	fmt.Fprintf(&b, ": %s", stateText(e.entity))
+
+	attributes := e.entity.GetAttributes()
+	for _, key := range salientAttributes {
+		if value, ok := attributes[key]; ok && value != nil && value != "" {
+			fmt.Fprintf(&b, ", %s %v", strings.ReplaceAll(key, "_", " "), value)
+		}
+	}
+	if !e.entity.IsAvailable() {
+		b.WriteString(", unavailable")
+	}
+	if changed := e.entity.GetLastUpdated(); !changed.IsZero() {
+		fmt.Fprintf(&b, ", changed %s ago", formatAge(now.Sub(changed)))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// stateText is the state with the unit of sensors
+func stateText(entity types.PMAEntity) string {
+	state := string(entity.GetState())
+	if unit, ok := entity.GetAttributes()["unit_of_measurement"].(string); ok && unit != "" {
+		return state + " " + unit
+	}
+	return state
+}
+
+// summarizeRooms lists every area with its rooms
+func summarizeRooms(entries []*entry) string {
+	var b strings.Builder
+	b.WriteString("All devices by area and room:\n")
+	for _, area := range groupBy(entries, func(e *entry) string { return e.area }) {
+		indent := ""
+		if area.name != "" {
+			fmt.Fprintf(&b, "%s:\n", area.name)
+			indent = "  "
+		}
+		for _, room := range groupBy(area.entries, func(e *entry) string { return e.room }) {
+			name := room.name
+			if name == "" {
+				name = "No room"
+				if area.name == "" {
+					name = "Unassigned"
+				}
+			}
+			fmt.Fprintf(&b, "%s- %s: %s\n", indent, name, summarize(room.entries))
+		}
+	}
+	return b.String()
+}
+
+// summarizeAreas lists every area without its rooms
+func summarizeAreas(entries []*entry) string {
+	var b strings.Builder
+	b.WriteString("All devices by area:\n")
+	for _, area := range groupBy(entries, func(e *entry) string { return e.area }) {
+		name := area.name
+		if name == "" {
+			name = "No area"
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", name, summarize(area.entries))
+	}
+	return b.String()
+}
+
+// summarizeTotals counts the entities of the whole home
+func summarizeTotals(entries []*entry) string {
+	return "All devices: " + summarize(entries) + "\n"
+}
+
+// group is entities sharing a room or area
+type group struct {
+	name    string
+	entries []*entry
+}
+
+// groupBy groups entries by name, named groups first in alphabetical order
+func groupBy(entries []*entry, name func(*entry) string) []group {
+	index := make(map[string]int)
+	var groups []group
+	for _, e := range entries {
+		key := name(e)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group{name: key})
+		}
+		groups[i].entries = append(groups[i].entries, e)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].name == "") != (groups[j].name == "") {
+			return groups[j].name == ""
+		}
+		return groups[i].name < groups[j].name
+	})
+	return groups
+}
+
+// summarize counts entities by type, pointing out active states, unavailable entities and the
+// average temperature: "3 light (1 on), 2 sensor, 1 unavailable, 21.5 °C"
+func summarize(entries []*entry) string {
+	type typeCount struct {
+		total  int
+		states map[string]int
+	}
+	counts := make(map[types.PMAEntityType]*typeCount)
+	var order []types.PMAEntityType
+	unavailable := 0
+	var temperatureSum float64
+	temperatures := 0
+	temperatureUnit := ""
+
+	for _, e := range entries {
+		entityType := e.entity.GetType()
+		count, ok := counts[entityType]
+		if !ok {
+			count = &typeCount{states: make(map[string]int)}
+			counts[entityType] = count
+			order = append(order, entityType)
+		}
+		count.total++
+		count.states[string(e.entity.GetState())]++
+		if !e.entity.IsAvailable() {
+			unavailable++
+		}
+
+		attributes := e.entity.GetAttributes()
+		if unit, _ := attributes["unit_of_measurement"].(string); entityType == types.EntityTypeSensor && (unit == "°C" || unit == "°F") {
+			var value float64
+			if _, err := fmt.Sscanf(string(e.entity.GetState()), "%g", &value); err == nil {
+				temperatureSum += value
+				temperatures++
+				temperatureUnit = unit
+			}
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return order[i] < order[j] })
+	parts := make([]string, 0, len(order)+2)
+	for _, entityType := range order {
+		count := counts[entityType]
+		part := fmt.Sprintf("%d %s", count.total, strings.ReplaceAll(string(entityType), "_", " "))
+		if state, active := mostActiveState(count.states); active > 0 && entityType != types.EntityTypeSensor {
+			part += fmt.Sprintf(" (%d %s)", active, state)
+		}
+		parts = append(parts, part)
+	}
+	if unavailable > 0 {
+		parts = append(parts, fmt.Sprintf("%d unavailable", unavailable))
+	}
+	if temperatures > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f %s", temperatureSum/float64(temperatures), temperatureUnit))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mostActiveState returns the most common active state and how many entities are active
+func mostActiveState(states map[string]int) (string, int) {
+	best, bestCount, active := "", 0, 0
+	for state, count := range states {
+		if inactiveStates[state] {
+			continue
+		}
+		active += count
+		if count > bestCount || (count == bestCount && state < best) {
+			best, bestCount = state, count
+		}
+	}
+	return best, active
+}
+
+func containsAll(words map[string]bool, required []string) bool {
+	for _, word := range required {
+		if !words[word] {
+			return false
+		}
+	}
+	return true
+}
+
+func joinNonEmpty(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " / ")
+}
+
+// formatAge renders a duration in its largest unit: "45s", "3m", "2h", "4d"
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// normalize lowercases text and turns everything but letters and digits into single spaces
+func normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
@@ -0,0 +1,156 @@
+package homecontext
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEntities serves a fixed home and counts the reads
+type fakeEntities struct {
+	entities []*unified.EntityWithRoom
+	reads    int
+}
+
+func (f *fakeEntities) GetAll(ctx context.Context, options unified.GetAllOptions) ([]*unified.EntityWithRoom, error) {
+	f.reads++
+	return f.entities, nil
+}
+
+func (f *fakeEntities) add(id string, entityType types.PMAEntityType, name, state string, area *types.PMAArea, room *types.PMARoom, changed time.Time, attributes map[string]interface{}) {
+	f.entities = append(f.entities, &unified.EntityWithRoom{
+		Entity: &types.PMABaseEntity{
+			ID:           id,
+			Type:         entityType,
+			FriendlyName: name,
+			State:        types.PMAEntityState(state),
+			Attributes:   attributes,
+			LastUpdated:  changed,
+			Available:    true,
+		},
+		Room: room,
+		Area: area,
+	})
+}
+
+func newTestHome() *fakeEntities {
+	downstairs := &types.PMAArea{ID: "downstairs", Name: "Downstairs"}
+	upstairs := &types.PMAArea{ID: "upstairs", Name: "Upstairs"}
+	kitchen := &types.PMARoom{ID: "kitchen", Name: "Kitchen"}
+	living := &types.PMARoom{ID: "living", Name: "Living Room"}
+	bedroom := &types.PMARoom{ID: "bedroom", Name: "Bedroom"}
+	old := time.Now().Add(-3 * time.Hour)
+
+	home := &fakeEntities{}
+	home.add("light.kitchen_ceiling", types.EntityTypeLight, "Kitchen Ceiling", "on", downstairs, kitchen, old, map[string]interface{}{"brightness": 80})
+	home.add("light.kitchen_counter", types.EntityTypeLight, "Kitchen Counter", "off", downstairs, kitchen, old, nil)
+	home.add("sensor.kitchen_temperature", types.EntityTypeSensor, "Kitchen Temperature", "21.5", downstairs, kitchen, old, map[string]interface{}{"unit_of_measurement": "°C"})
+	home.add("switch.coffee_maker", types.EntityTypeSwitch, "Coffee Maker", "off", downstairs, kitchen, old, nil)
+	home.add("media_player.living_tv", types.EntityTypeMediaPlayer, "TV", "playing", downstairs, living, time.Now().Add(-2*time.Minute), nil)
+	home.add("light.bedroom_lamp", types.EntityTypeLight, "Bedroom Lamp", "off", upstairs, bedroom, old, nil)
+	home.add("cover.bedroom_blinds", types.EntityTypeCover, "Bedroom Blinds", "open", upstairs, bedroom, old, map[string]interface{}{"current_position": 100})
+	for i := 0; i < 20; i++ {
+		home.add(fmt.Sprintf("sensor.bedroom_%d", i), types.EntityTypeSensor, fmt.Sprintf("Bedroom Sensor %d", i), "19", upstairs, bedroom, time.Now(), map[string]interface{}{"unit_of_measurement": "°C"})
+	}
+	return home
+}
+
+func newTestBuilder(entities EntityService) *Builder {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return NewBuilder(config.HomeContextConfig{Enabled: true}, entities, logger)
+}
+
+func TestBuilder_RelevantEntities(t *testing.T) {
+	builder := newTestBuilder(newTestHome())
+	budget := ai.TokenBudget{MaxTokens: 2000}
+
+	home, err := builder.HomeContext(context.Background(), "Turn off the kitchen lights", budget)
+	require.NoError(t, err)
+
+	// Both kitchen lights, then the recently changed TV; not the kitchen sensor or switch
+	assert.Equal(t, 3, home.Relevant)
+	assert.Contains(t, home.Text, "- Kitchen Ceiling (light.kitchen_ceiling) in Downstairs / Kitchen: on, brightness 80, changed 3h ago")
+	assert.Contains(t, home.Text, "- Kitchen Counter (light.kitchen_counter)")
+	assert.Contains(t, home.Text, "- TV (media_player.living_tv)")
+	assert.NotContains(t, home.Text, "(switch.coffee_maker)")
+	assert.NotContains(t, home.Text, "(sensor.kitchen_temperature)")
+
+	// Everything is still counted by area and room
+	assert.Contains(t, home.Text, "All devices by area and room:")
+	assert.Contains(t, home.Text, "  - Kitchen: 2 light (1 on), 1 sensor, 1 switch, 21.5 °C")
+	assert.Contains(t, home.Text, "  - Bedroom: 1 cover (1 open), 1 light, 20 sensor, 19.0 °C")
+	assert.Equal(t, 24, home.Summarized)
+	assert.Equal(t, budget.Tokens(home.Text), home.Tokens)
+
+	home, err = builder.HomeContext(context.Background(), "is the coffee maker on?", budget)
+	require.NoError(t, err)
+	assert.Contains(t, home.Text, "Devices relevant to this message:\n- Coffee Maker (switch.coffee_maker)")
+}
+
+func TestBuilder_FitsBudget(t *testing.T) {
+	builder := newTestBuilder(newTestHome())
+	message := "what is on in the bedroom?"
+
+	full, err := builder.HomeContext(context.Background(), message, ai.TokenBudget{MaxTokens: 2000})
+	require.NoError(t, err)
+	require.Contains(t, full.Text, "All devices by area and room:")
+
+	// Smaller budgets drop relevant entities, then fall back to coarser summaries
+	previous := full
+	for _, maxTokens := range []int{full.Tokens - 1, 150, 90, 60} {
+		home, err := builder.HomeContext(context.Background(), message, ai.TokenBudget{MaxTokens: maxTokens})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, home.Tokens, maxTokens)
+		assert.LessOrEqual(t, home.Relevant, previous.Relevant)
+		assert.Contains(t, home.Text, "All devices")
+		previous = home
+	}
+	assert.Contains(t, previous.Text, "All devices: ")
+
+	// A budget too small for the totals adds nothing
+	home, err := builder.HomeContext(context.Background(), message, ai.TokenBudget{MaxTokens: 5})
+	require.NoError(t, err)
+	assert.Empty(t, home.Text)
+
+	// The provider's estimate is used
+	perByte := ai.TokenBudget{MaxTokens: 2000, Provider: "bytes", Estimate: func(text string) int { return len(text) }}
+	home, err = builder.HomeContext(context.Background(), message, perByte)
+	require.NoError(t, err)
+	assert.Equal(t, len(home.Text), home.Tokens)
+	assert.Less(t, home.Relevant, full.Relevant)
+}
+
+func TestBuilder_CachesSnapshot(t *testing.T) {
+	entities := newTestHome()
+	builder := newTestBuilder(entities)
+	budget := ai.TokenBudget{MaxTokens: 2000, Provider: "ollama"}
+
+	first, err := builder.HomeContext(context.Background(), "kitchen lights", budget)
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+
+	second, err := builder.HomeContext(context.Background(), "the lights in the kitchen please", budget)
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.Text, second.Text)
+
+	_, err = builder.HomeContext(context.Background(), "bedroom blinds", budget)
+	require.NoError(t, err)
+	assert.Equal(t, 1, entities.reads)
+
+	builder.Invalidate()
+	third, err := builder.HomeContext(context.Background(), "kitchen lights", budget)
+	require.NoError(t, err)
+	assert.False(t, third.Cached)
+	assert.Equal(t, 2, entities.reads)
+}
@@ -0,0 +1,471 @@
+package intents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Intents
+const (
+	IntentTurnOn         = "turn_on"
+	IntentTurnOff        = "turn_off"
+	IntentSetBrightness  = "set_brightness"
+	IntentSetTemperature = "set_temperature"
+	IntentSetPosition    = "set_position"
+	IntentGetState       = "get_state"
+	IntentLock           = "lock"
+	IntentUnlock         = "unlock"
+)
+
+// actionSource is the context source of the actions the engine executes
+const actionSource = "intent"
+
+// responseFailure is the response used when no targeted entity could be controlled
+const responseFailure = "failure"
+
+// Defaults
+const (
+	defaultLocalesPath   = "./locales"
+	defaultLocale        = "en-US"
+	defaultMinScore      = 0.75
+	unitOfMeasurementKey = "unit_of_measurement"
+)
+
+// intentSpec describes what an intent applies to
+type intentSpec struct {
+	domains    []types.PMAEntityType // Entity types the intent applies to, any when empty
+	areaDomain types.PMAEntityType   // Entity type targeted when only a room or area is named
+	needsValue bool
+	query      bool // Queries target a single entity and change nothing
+}
+
+var switchableDomains = []types.PMAEntityType{
+	types.EntityTypeLight, types.EntityTypeSwitch, types.EntityTypeFan, types.EntityTypeInputBoolean,
+	types.EntityTypeCover, types.EntityTypeClimate, types.EntityTypeMediaPlayer,
+}
+
+var intentSpecs = map[string]intentSpec{
+	IntentTurnOn:         {domains: switchableDomains, areaDomain: types.EntityTypeLight},
+	IntentTurnOff:        {domains: switchableDomains, areaDomain: types.EntityTypeLight},
+	IntentSetBrightness:  {domains: []types.PMAEntityType{types.EntityTypeLight}, areaDomain: types.EntityTypeLight, needsValue: true},
+	IntentSetTemperature: {domains: []types.PMAEntityType{types.EntityTypeClimate}, areaDomain: types.EntityTypeClimate, needsValue: true},
+	IntentSetPosition:    {domains: []types.PMAEntityType{types.EntityTypeCover}, areaDomain: types.EntityTypeCover, needsValue: true},
+	IntentGetState:       {query: true},
+	IntentLock:           {domains: []types.PMAEntityType{types.EntityTypeLock}, areaDomain: types.EntityTypeLock},
+	IntentUnlock:         {domains: []types.PMAEntityType{types.EntityTypeLock}, areaDomain: types.EntityTypeLock},
+}
+
+// allows reports whether the intent applies to an entity type
+func (s intentSpec) allows(entityType types.PMAEntityType) bool {
+	if len(s.domains) == 0 {
+		return true
+	}
+	for _, domain := range s.domains {
+		if domain == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+// EntityService is the part of the unified entity service the engine uses
+type EntityService interface {
+	GetAll(ctx context.Context, options unified.GetAllOptions) ([]*unified.EntityWithRoom, error)
+	ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error)
+}
+
+// Result is the outcome of a recognized command
+type Result struct {
+	Intent    string   `json:"intent"`
+	Locale    string   `json:"locale"`
+	Text      string   `json:"text"`
+	Target    string   `json:"target"`
+	Score     float64  `json:"score"`
+	EntityIDs []string `json:"entity_ids"`
+	Failed    []string `json:"failed,omitempty"`
+	Value     *float64 `json:"value,omitempty"`
+	State     string   `json:"state,omitempty"`
+	Success   bool     `json:"success"`
+	Response  string   `json:"response"`
+}
+
+// target is what a command refers to
+type target struct {
+	name     string
+	entities []types.PMAEntity
+	score    float64
+}
+
+// Engine recognizes common commands from sentence templates and executes them through
+// the unified entity service, without an LLM
+type Engine struct {
+	entities      EntityService
+	locales       map[string]*Locale
+	defaultLocale string
+	minScore      float64
+	logger        *logrus.Logger
+}
+
+// NewEngine creates an intent engine with the locales found in the configured directory
+func NewEngine(cfg config.IntentsConfig, entities EntityService, logger *logrus.Logger) (*Engine, error) {
+	if cfg.LocalesPath == "" {
+		cfg.LocalesPath = defaultLocalesPath
+	}
+	if cfg.DefaultLocale == "" {
+		cfg.DefaultLocale = defaultLocale
+	}
+	if cfg.MinScore <= 0 || cfg.MinScore > 1 {
+		cfg.MinScore = defaultMinScore
+	}
+
+	locales, err := LoadLocales(cfg.LocalesPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(locales) == 0 {
+		return nil, fmt.Errorf("no locale in %s defines intents", cfg.LocalesPath)
+	}
+
+	return &Engine{
+		entities:      entities,
+		locales:       locales,
+		defaultLocale: cfg.DefaultLocale,
+		minScore:      cfg.MinScore,
+		logger:        logger,
+	}, nil
+}
+
+// Locales returns the names of the loaded locales
+func (e *Engine) Locales() []string {
+	names := make([]string, 0, len(e.locales))
+	for name := range e.locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleIntent implements ai.IntentHandler
+func (e *Engine) HandleIntent(ctx context.Context, text, locale string) (*ai.IntentResult, bool) {
+	result, err := e.Process(ctx, text, locale)
+	if err != nil {
+		e.logger.WithError(err).Warn("Intent recognition failed, falling back to the LLM")
+		return nil, false
+	}
+	if result == nil {
+		return nil, false
+	}
+	return &ai.IntentResult{
+		Intent:    result.Intent,
+		Locale:    result.Locale,
+		Response:  result.Response,
+		Success:   result.Success,
+		EntityIDs: result.EntityIDs,
+	}, true
+}
+
+// Process recognizes and executes a command. It returns nil when no intent matches, in which
+// case the command is left to the LLM.
+func (e *Engine) Process(ctx context.Context, text, locale string) (*Result, error) {
+	normalized := normalize(text)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	var entities []*unified.EntityWithRoom
+	for _, loc := range e.candidateLocales(locale) {
+		for _, s := range loc.sentences {
+			match := s.pattern.FindStringSubmatch(normalized)
+			if match == nil {
+				continue
+			}
+
+			if entities == nil {
+				all, err := e.entities.GetAll(ctx, unified.GetAllOptions{IncludeRoom: true, IncludeArea: true})
+				if err != nil {
+					return nil, fmt.Errorf("failed to list entities: %w", err)
+				}
+				entities = all
+			}
+
+			spec := intentSpecs[s.intent]
+			t := e.resolve(loc, spec, match[s.pattern.SubexpIndex(slotName)], entities)
+			if t == nil {
+				continue
+			}
+
+			var value *float64
+			if index := s.pattern.SubexpIndex(slotValue); index >= 0 {
+				parsed, err := strconv.ParseFloat(match[index], 64)
+				if err != nil {
+					continue
+				}
+				value = &parsed
+			}
+
+			result := e.execute(ctx, loc, s.intent, t, value)
+			result.Text = text
+			e.logger.WithFields(logrus.Fields{
+				"intent":   result.Intent,
+				"locale":   result.Locale,
+				"target":   result.Target,
+				"entities": len(result.EntityIDs),
+				"success":  result.Success,
+			}).Info("Handled command locally")
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// candidateLocales returns the locale asked for, matched exactly or by language, or every
+// locale starting with the default one when none is given
+func (e *Engine) candidateLocales(name string) []*Locale {
+	if name != "" {
+		if loc, ok := e.locales[name]; ok {
+			return []*Locale{loc}
+		}
+		language := strings.ToLower(strings.SplitN(strings.ReplaceAll(name, "_", "-"), "-", 2)[0])
+		for _, locName := range e.Locales() {
+			if strings.ToLower(strings.SplitN(locName, "-", 2)[0]) == language {
+				return []*Locale{e.locales[locName]}
+			}
+		}
+	}
+
+	var locales []*Locale
+	if loc, ok := e.locales[e.defaultLocale]; ok {
+		locales = append(locales, loc)
+	}
+	for _, locName := range e.Locales() {
+		if locName != e.defaultLocale {
+			locales = append(locales, e.locales[locName])
+		}
+	}
+	return locales
+}
+
+// resolve finds the entities a phrase refers to: a single entity by its name, or the entities
+// of a type in a room or area, as in "kitchen lights". The closer match wins, a single
+// entity on a tie.
+func (e *Engine) resolve(loc *Locale, spec intentSpec, phrase string, entities []*unified.EntityWithRoom) *target {
+	// A phrase may name the entity type along with the entity, as in "front door lock"
+	domain, rest := loc.findDomain(strings.Fields(phrase))
+
+	var best *target
+	for _, item := range entities {
+		entity := item.Entity
+		if entity == nil || !spec.allows(entity.GetType()) {
+			continue
+		}
+		score := e.entityScore(loc, phrase, item)
+		if entity.GetType() == domain && len(rest) > 0 {
+			score = max(score, e.entityScore(loc, strings.Join(rest, " "), item))
+		}
+		if score >= e.minScore && (best == nil || score > best.score) {
+			best = &target{name: entity.GetFriendlyName(), entities: []types.PMAEntity{entity}, score: score}
+		}
+	}
+
+	if spec.query {
+		return best
+	}
+	if group := e.resolveGroup(loc, spec, phrase, entities); group != nil && (best == nil || group.score > best.score) {
+		return group
+	}
+	return best
+}
+
+// entityScore is how closely a phrase matches the name of an entity, alone or with its room
+// or area, or its object ID
+func (e *Engine) entityScore(loc *Locale, phrase string, item *unified.EntityWithRoom) float64 {
+	name := item.Entity.GetFriendlyName()
+	names := []string{name}
+	if item.Room != nil {
+		names = append(names, item.Room.Name+" "+name)
+	}
+	if item.Area != nil {
+		names = append(names, item.Area.Name+" "+name)
+	}
+	id := item.Entity.GetID()
+	if dot := strings.LastIndex(id, "."); dot >= 0 {
+		names = append(names, strings.ReplaceAll(id[dot+1:], "_", " "))
+	}
+
+	best := 0.0
+	for _, candidate := range names {
+		if score := loc.score(phrase, candidate); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// resolveGroup finds the entities of a type in the room or area named by a phrase. The type
+// is named by a domain word, or implied by the intent.
+func (e *Engine) resolveGroup(loc *Locale, spec intentSpec, phrase string, entities []*unified.EntityWithRoom) *target {
+	domain, rest := loc.findDomain(strings.Fields(phrase))
+	if domain == "" {
+		domain = spec.areaDomain
+	}
+	if domain == "" || !spec.allows(domain) || len(rest) == 0 {
+		return nil
+	}
+	location := strings.Join(rest, " ")
+
+	// Find the best matching room or area among those holding entities of the type
+	bestKey, bestScore := "", 0.0
+	for _, item := range entities {
+		if item.Entity == nil || item.Entity.GetType() != domain {
+			continue
+		}
+		for key, name := range locationNames(item) {
+			if score := loc.score(location, name); score >= e.minScore && score > bestScore {
+				bestKey, bestScore = key, score
+			}
+		}
+	}
+	if bestKey == "" {
+		return nil
+	}
+
+	group := &target{name: phrase, score: bestScore}
+	for _, item := range entities {
+		if item.Entity == nil || item.Entity.GetType() != domain {
+			continue
+		}
+		if _, ok := locationNames(item)[bestKey]; ok {
+			group.entities = append(group.entities, item.Entity)
+		}
+	}
+	return group
+}
+
+// locationNames returns the room and area of an entity keyed by kind and ID
+func locationNames(item *unified.EntityWithRoom) map[string]string {
+	names := make(map[string]string, 2)
+	if item.Room != nil {
+		names["room:"+item.Room.ID] = item.Room.Name
+	}
+	if item.Area != nil {
+		names["area:"+item.Area.ID] = item.Area.Name
+	}
+	return names
+}
+
+// execute runs the intent on its target entities
+func (e *Engine) execute(ctx context.Context, loc *Locale, intent string, t *target, value *float64) *Result {
+	result := &Result{
+		Intent: intent,
+		Locale: loc.Name,
+		Target: t.name,
+		Score:  t.score,
+		Value:  value,
+	}
+	replacements := map[string]string{"name": t.name}
+	if value != nil {
+		replacements["value"] = strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+
+	if intent == IntentGetState {
+		entity := t.entities[0]
+		result.EntityIDs = []string{entity.GetID()}
+		result.State = loc.stateText(entity.GetState())
+		if unit, ok := entity.GetAttributes()[unitOfMeasurementKey].(string); ok && unit != "" {
+			result.State += " " + unit
+		}
+		result.Success = true
+		replacements["state"] = result.State
+		result.Response = loc.response(intent, replacements)
+		return result
+	}
+
+	for _, entity := range t.entities {
+		result.EntityIDs = append(result.EntityIDs, entity.GetID())
+		action := controlAction(intent, entity, value)
+		controlResult, err := e.entities.ExecuteAction(ctx, action)
+		if err == nil && controlResult != nil && controlResult.Success {
+			result.Success = true
+			continue
+		}
+
+		result.Failed = append(result.Failed, entity.GetID())
+		if err == nil && controlResult != nil && controlResult.Error != nil {
+			err = errors.New(controlResult.Error.Message)
+		}
+		e.logger.WithFields(logrus.Fields{
+			"entity_id": entity.GetID(),
+			"action":    action.Action,
+		}).WithError(err).Warn("Failed to execute intent action")
+	}
+
+	if result.Success {
+		result.Response = loc.response(intent, replacements)
+	} else {
+		result.Response = loc.response(responseFailure, replacements)
+	}
+	return result
+}
+
+// controlAction is the entity action carrying out an intent
+func controlAction(intent string, entity types.PMAEntity, value *float64) types.PMAControlAction {
+	action := types.PMAControlAction{
+		EntityID:   entity.GetID(),
+		Parameters: map[string]interface{}{},
+		Context: &types.PMAContext{
+			ID:          uuid.New().String(),
+			Source:      actionSource,
+			Timestamp:   time.Now(),
+			Description: "Intent " + intent,
+		},
+	}
+	isCover := entity.GetType() == types.EntityTypeCover
+
+	switch intent {
+	case IntentTurnOn:
+		action.Action = "turn_on"
+		if isCover {
+			action.Action = "open"
+		}
+	case IntentTurnOff:
+		action.Action = "turn_off"
+		if isCover {
+			action.Action = "close"
+		}
+	case IntentSetBrightness:
+		brightness := clampPercent(*value)
+		if brightness == 0 {
+			action.Action = "turn_off"
+			break
+		}
+		action.Action = "turn_on"
+		action.Parameters["brightness"] = brightness / 100
+	case IntentSetTemperature:
+		action.Action = "set_temperature"
+		action.Parameters["temperature"] = *value
+	case IntentSetPosition:
+		action.Action = "set_position"
+		action.Parameters["position"] = int(clampPercent(*value))
+	case IntentLock:
+		action.Action = "lock"
+	case IntentUnlock:
+		action.Action = "unlock"
+	}
+	return action
+}
+
+func clampPercent(value float64) float64 {
+	return max(0, min(100, value))
+}
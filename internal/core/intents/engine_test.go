@@ -0,0 +1,131 @@
+package intents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEntityService struct {
+	entities []*unified.EntityWithRoom
+	actions  []types.PMAControlAction
+}
+
+func (f *fakeEntityService) GetAll(ctx context.Context, options unified.GetAllOptions) ([]*unified.EntityWithRoom, error) {
+	return f.entities, nil
+}
+
+func (f *fakeEntityService) ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error) {
+	f.actions = append(f.actions, action)
+	return &types.PMAControlResult{Success: true, EntityID: action.EntityID, Action: action.Action}, nil
+}
+
+func testEntity(id string, entityType types.PMAEntityType, name string, state types.PMAEntityState, room *types.PMARoom) *unified.EntityWithRoom {
+	return &unified.EntityWithRoom{
+		Entity: &types.PMABaseEntity{
+			ID:           id,
+			Type:         entityType,
+			FriendlyName: name,
+			State:        state,
+			Attributes:   map[string]interface{}{},
+		},
+		Room: room,
+	}
+}
+
+func TestEngine_Process(t *testing.T) {
+	kitchen := &types.PMARoom{ID: "kitchen", Name: "Kitchen"}
+	living := &types.PMARoom{ID: "living", Name: "Salón"}
+	service := &fakeEntityService{entities: []*unified.EntityWithRoom{
+		testEntity("ha_light.kitchen_ceiling", types.EntityTypeLight, "Ceiling Light", types.StateOff, kitchen),
+		testEntity("ha_light.kitchen_counter", types.EntityTypeLight, "Counter Light", types.StateOff, kitchen),
+		testEntity("ha_light.floor_lamp", types.EntityTypeLight, "Floor Lamp", types.StateOn, living),
+		testEntity("ha_climate.thermostat", types.EntityTypeClimate, "Thermostat", types.StateOn, living),
+		testEntity("ha_lock.front_door", types.EntityTypeLock, "Front Door", types.StateLocked, nil),
+	}}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	engine, err := NewEngine(config.IntentsConfig{LocalesPath: "../../../locales"}, service, logger)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"en-US", "es-ES"}, engine.Locales())
+
+	tests := []struct {
+		text     string
+		locale   string
+		intent   string
+		entities []string
+		action   string
+		params   map[string]interface{}
+		response string
+	}{
+		{
+			text: "Turn on the kitchen lights", intent: IntentTurnOn,
+			entities: []string{"ha_light.kitchen_ceiling", "ha_light.kitchen_counter"}, action: "turn_on",
+			response: "Turned on kitchen lights",
+		},
+		{
+			text: "set the floor lamp to 40 percent", intent: IntentSetBrightness,
+			entities: []string{"ha_light.floor_lamp"}, action: "turn_on", params: map[string]interface{}{"brightness": 0.4},
+			response: "Set Floor Lamp to 40% brightness",
+		},
+		{
+			text: "Set the thermostat to 21.5 degrees", intent: IntentSetTemperature,
+			entities: []string{"ha_climate.thermostat"}, action: "set_temperature", params: map[string]interface{}{"temperature": 21.5},
+			response: "Set Thermostat to 21.5 degrees",
+		},
+		{
+			text: "unlock the frnt door", intent: IntentUnlock,
+			entities: []string{"ha_lock.front_door"}, action: "unlock",
+			response: "Unlocked Front Door",
+		},
+		{
+			text: "¿Cuál es el estado de la cerradura Front Door?", locale: "es", intent: IntentGetState,
+			entities: []string{"ha_lock.front_door"},
+			response: "Front Door está bloqueado",
+		},
+		{
+			text: "Apaga las luces del salon", locale: "es-ES", intent: IntentTurnOff,
+			entities: []string{"ha_light.floor_lamp"}, action: "turn_off",
+			response: "He apagado luces del salon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			service.actions = nil
+			result, err := engine.Process(context.Background(), tt.text, tt.locale)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			assert.Equal(t, tt.intent, result.Intent)
+			assert.True(t, result.Success)
+			assert.ElementsMatch(t, tt.entities, result.EntityIDs)
+			assert.Equal(t, tt.response, result.Response)
+			if tt.action == "" {
+				assert.Empty(t, service.actions)
+				return
+			}
+			require.Len(t, service.actions, len(tt.entities))
+			for _, action := range service.actions {
+				assert.Equal(t, tt.action, action.Action)
+				for key, value := range tt.params {
+					assert.Equal(t, value, action.Parameters[key])
+				}
+			}
+		})
+	}
+
+	// Commands without a matching intent or entity are left to the LLM
+	for _, text := range []string{"tell me a joke", "turn on the garage lights", "turn off the lights"} {
+		result, err := engine.Process(context.Background(), text, "")
+		require.NoError(t, err)
+		assert.Nil(t, result, text)
+	}
+}
@@ -0,0 +1,285 @@
+package intents
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+)
+
+// Sentence template slots
+const (
+	slotName  = "name"
+	slotValue = "value"
+)
+
+var slotPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// slotExpressions are the expressions slots match in normalized text
+var slotExpressions = map[string]string{
+	slotName:  `(?P<name>.+)`,
+	slotValue: `(?P<value>\d+(?:\.\d+)?)`,
+}
+
+// LocaleIntents is the "intents" section of a locale file.
+//
+// Sentences are templates in which "[the]" is optional, "(turn|switch)" is one of the
+// alternatives and "{name}" and "{value}" are the target and numeric value slots.
+type LocaleIntents struct {
+	Sentences map[string][]string `json:"sentences"`
+	Responses map[string]string   `json:"responses"` // Per intent, plus "failure"; {name}, {value} and {state} are replaced
+	Domains   map[string][]string `json:"domains"`   // Entity type -> words naming it, as in "kitchen lights"
+	StopWords []string            `json:"stop_words"`
+	States    map[string]string   `json:"states"` // Entity state -> how it is said
+}
+
+// localeFile is the part of a locale file read by the intent engine
+type localeFile struct {
+	Locale  string         `json:"locale"`
+	Intents *LocaleIntents `json:"intents"`
+}
+
+// sentence is a compiled sentence template
+type sentence struct {
+	intent   string
+	pattern  *regexp.Regexp
+	literals int // Length of the fixed text, more specific sentences are tried first
+}
+
+// Locale holds the compiled intent data of one locale
+type Locale struct {
+	Name      string
+	sentences []sentence
+	responses map[string]string
+	domains   map[string]types.PMAEntityType // Normalized domain word -> entity type
+	stopWords map[string]bool
+	states    map[string]string
+}
+
+// LoadLocales loads the locales with an "intents" section from the JSON files in dir
+func LoadLocales(dir string) (map[string]*Locale, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	locales := make(map[string]*Locale)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var file localeFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if file.Intents == nil {
+			continue
+		}
+
+		name := file.Locale
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		locale, err := NewLocale(name, file.Intents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intents in %s: %w", path, err)
+		}
+		locales[name] = locale
+	}
+	return locales, nil
+}
+
+// NewLocale compiles the intent data of a locale
+func NewLocale(name string, data *LocaleIntents) (*Locale, error) {
+	locale := &Locale{
+		Name:      name,
+		responses: data.Responses,
+		domains:   make(map[string]types.PMAEntityType),
+		stopWords: make(map[string]bool),
+		states:    data.States,
+	}
+
+	for intent, templates := range data.Sentences {
+		spec, ok := intentSpecs[intent]
+		if !ok {
+			return nil, fmt.Errorf("unknown intent %q", intent)
+		}
+		for _, template := range templates {
+			compiled, err := compileTemplate(intent, template)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range compiled {
+				if spec.needsValue != (s.pattern.SubexpIndex(slotValue) >= 0) || s.pattern.SubexpIndex(slotName) < 0 {
+					return nil, fmt.Errorf("sentence %q of intent %s has the wrong slots", template, intent)
+				}
+			}
+			locale.sentences = append(locale.sentences, compiled...)
+		}
+	}
+	sort.SliceStable(locale.sentences, func(i, j int) bool {
+		if locale.sentences[i].literals != locale.sentences[j].literals {
+			return locale.sentences[i].literals > locale.sentences[j].literals
+		}
+		return locale.sentences[i].pattern.String() < locale.sentences[j].pattern.String()
+	})
+
+	for domain, words := range data.Domains {
+		for _, word := range words {
+			locale.domains[normalize(word)] = types.PMAEntityType(domain)
+		}
+	}
+	for _, word := range data.StopWords {
+		locale.stopWords[normalize(word)] = true
+	}
+	return locale, nil
+}
+
+// compileTemplate expands the optional parts and alternatives of a template into one
+// anchored pattern per variant
+func compileTemplate(intent, template string) ([]sentence, error) {
+	variants, err := expandTemplate(template)
+	if err != nil {
+		return nil, fmt.Errorf("sentence %q: %w", template, err)
+	}
+
+	sentences := make([]sentence, 0, len(variants))
+	seen := make(map[string]bool)
+	for _, variant := range variants {
+		var parts []string
+		literals := 0
+		last := 0
+		for _, loc := range slotPattern.FindAllStringSubmatchIndex(variant, -1) {
+			if text := normalize(variant[last:loc[0]]); text != "" {
+				parts = append(parts, regexp.QuoteMeta(text))
+				literals += len(text)
+			}
+			slot := variant[loc[2]:loc[3]]
+			expression, ok := slotExpressions[slot]
+			if !ok {
+				return nil, fmt.Errorf("sentence %q: unknown slot {%s}", template, slot)
+			}
+			parts = append(parts, expression)
+			last = loc[1]
+		}
+		if text := normalize(variant[last:]); text != "" {
+			parts = append(parts, regexp.QuoteMeta(text))
+			literals += len(text)
+		}
+
+		expression := "^" + strings.Join(parts, " ") + "$"
+		if seen[expression] {
+			continue
+		}
+		seen[expression] = true
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("sentence %q: %w", template, err)
+		}
+		sentences = append(sentences, sentence{intent: intent, pattern: pattern, literals: literals})
+	}
+	return sentences, nil
+}
+
+// expandTemplate returns every variant of a template, "[a]" being "a" or nothing and
+// "(a|b)" being "a" or "b"
+func expandTemplate(template string) ([]string, error) {
+	start := strings.IndexAny(template, "[(")
+	if start < 0 {
+		if strings.ContainsAny(template, "])|") {
+			return nil, fmt.Errorf("unbalanced brackets")
+		}
+		return []string{template}, nil
+	}
+
+	end, options := -1, []string{}
+	depth, optionStart := 0, start+1
+	for i := start; i < len(template) && end < 0; i++ {
+		switch template[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth == 0 {
+				end = i
+				options = append(options, template[optionStart:i])
+			}
+		case '|':
+			if depth == 1 {
+				options = append(options, template[optionStart:i])
+				optionStart = i + 1
+			}
+		}
+	}
+	if end < 0 || (template[start] == '[') != (template[end] == ']') {
+		return nil, fmt.Errorf("unbalanced brackets")
+	}
+	if template[start] == '[' {
+		options = append(options, "")
+	}
+
+	var variants []string
+	for _, option := range options {
+		expanded, err := expandTemplate(template[:start] + option + template[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, expanded...)
+	}
+	return variants, nil
+}
+
+// response formats the response of an intent
+func (l *Locale) response(key string, replacements map[string]string) string {
+	text, ok := l.responses[key]
+	if !ok {
+		text = key
+	}
+	for slot, value := range replacements {
+		text = strings.ReplaceAll(text, "{"+slot+"}", value)
+	}
+	return text
+}
+
+// stateText is how a state is said in the locale
+func (l *Locale) stateText(state types.PMAEntityState) string {
+	if text, ok := l.states[string(state)]; ok {
+		return text
+	}
+	return strings.ReplaceAll(string(state), "_", " ")
+}
+
+// significant drops the stop words of normalized text, unless nothing else is left
+func (l *Locale) significant(text string) []string {
+	words := strings.Fields(text)
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		if !l.stopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return words
+	}
+	return kept
+}
+
+// findDomain finds the longest domain word in the words of a phrase, returning the entity
+// type it names and the remaining words
+func (l *Locale) findDomain(words []string) (types.PMAEntityType, []string) {
+	for size := len(words); size > 0; size-- {
+		for i := 0; i+size <= len(words); i++ {
+			if domain, ok := l.domains[strings.Join(words[i:i+size], " ")]; ok {
+				rest := append(append([]string{}, words[:i]...), words[i+size:]...)
+				return domain, rest
+			}
+		}
+	}
+	return "", words
+}
@@ -0,0 +1,88 @@
+package intents
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// accentFolds maps accented letters to their base letter, so "salón" matches "salon"
+var accentFolds = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// normalize lowercases text, folds accents and reduces it to words separated by single
+// spaces. Decimal separators between digits are kept as a point.
+func normalize(text string) string {
+	runes := []rune(strings.ToLower(text))
+	var b strings.Builder
+	for i, r := range runes {
+		if folded, ok := accentFolds[r]; ok {
+			r = folded
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case (r == '.' || r == ',') && i > 0 && i < len(runes)-1 && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]):
+			b.WriteRune('.')
+		case r == '\'' || r == '’':
+			// "what's" reads "whats"
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// score is how closely a spoken phrase matches a name, from 0 to 1. Stop words are ignored
+// and word order only matters when it helps.
+func (l *Locale) score(phrase, name string) float64 {
+	spoken, named := l.significant(phrase), l.significant(normalize(name))
+	best := similarity(strings.Join(spoken, " "), strings.Join(named, " "))
+
+	sort.Strings(spoken)
+	sort.Strings(named)
+	if sorted := similarity(strings.Join(spoken, " "), strings.Join(named, " ")); sorted > best {
+		best = sorted
+	}
+	return best
+}
+
+// similarity is one minus the edit distance of two strings relative to the longer one
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein is the number of single rune edits turning a into b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,211 @@
+package kiosk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// maxScreenshotSize bounds screenshot uploads from kiosks
+const maxScreenshotSize = 20 * 1024 * 1024
+
+// BundleInfo describes a published frontend bundle that kiosks can install
+type BundleInfo struct {
+	Version     string    `json:"version"`
+	SHA256      string    `json:"sha256"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// ======== SCREENSHOTS ========
+
+// SaveScreenshot stores a screenshot uploaded by a kiosk for a screenshot command
+// and completes the command with the stored file's location
+func (s *ServiceImpl) SaveScreenshot(ctx context.Context, tokenID, commandID, format string, data io.Reader) (string, error) {
+	command, err := s.repo.GetCommand(ctx, commandID)
+	if err != nil {
+		return "", err
+	}
+	if command.KioskTokenID != tokenID {
+		return "", fmt.Errorf("command does not belong to this kiosk")
+	}
+	if CommandType(command.CommandType) != CommandScreenshot {
+		return "", fmt.Errorf("command %s is not a screenshot command", commandID)
+	}
+
+	ext := "png"
+	if format == "jpeg" || format == "jpg" {
+		ext = "jpg"
+	}
+
+	dir := filepath.Join(s.storageDir, "screenshots", tokenID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s.%s", commandID, ext))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create screenshot file: %w", err)
+	}
+	defer file.Close()
+
+	written, err := io.Copy(file, io.LimitReader(data, maxScreenshotSize+1))
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write screenshot: %w", err)
+	}
+	if written > maxScreenshotSize {
+		os.Remove(path)
+		return "", fmt.Errorf("screenshot exceeds maximum size of %d bytes", maxScreenshotSize)
+	}
+
+	url := fmt.Sprintf("/api/v1/kiosk/commands/%s/screenshot", commandID)
+	err = s.CompleteCommand(ctx, commandID, map[string]interface{}{
+		"screenshot_url": url,
+		"size":           written,
+		"format":         ext,
+	}, "")
+	if err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// GetScreenshotPath returns the on-disk location of a screenshot captured for a command
+func (s *ServiceImpl) GetScreenshotPath(ctx context.Context, commandID string) (string, error) {
+	command, err := s.repo.GetCommand(ctx, commandID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, ext := range []string{"png", "jpg"} {
+		path := filepath.Join(s.storageDir, "screenshots", command.KioskTokenID, fmt.Sprintf("%s.%s", commandID, ext))
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("screenshot not found")
+}
+
+// ======== FRONTEND BUNDLES ========
+
+// PublishBundle stores a frontend bundle and records its SHA-256 checksum
+func (s *ServiceImpl) PublishBundle(ctx context.Context, version string, data io.Reader) (*BundleInfo, error) {
+	if !versionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid bundle version: %q", version)
+	}
+
+	dir := filepath.Join(s.storageDir, "bundles")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	bundlePath := filepath.Join(dir, version+".zip")
+	tmpPath := bundlePath + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle file: %w", err)
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hasher), data)
+	file.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, bundlePath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to store bundle: %w", err)
+	}
+
+	info := &BundleInfo{
+		Version:     version,
+		SHA256:      hex.EncodeToString(hasher.Sum(nil)),
+		Size:        size,
+		URL:         fmt.Sprintf("/api/v1/kiosk-client/bundles/%s", version),
+		PublishedAt: time.Now().UTC(),
+	}
+
+	infoBytes, _ := json.MarshalIndent(info, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, version+".json"), infoBytes, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bundle metadata: %w", err)
+	}
+
+	s.logger.WithField("version", version).WithField("sha256", info.SHA256).Info("Kiosk frontend bundle published")
+	return info, nil
+}
+
+// GetBundle returns a published bundle's metadata and file path
+func (s *ServiceImpl) GetBundle(version string) (*BundleInfo, string, error) {
+	if !versionPattern.MatchString(version) {
+		return nil, "", fmt.Errorf("invalid bundle version: %q", version)
+	}
+
+	dir := filepath.Join(s.storageDir, "bundles")
+	infoBytes, err := os.ReadFile(filepath.Join(dir, version+".json"))
+	if err != nil {
+		return nil, "", fmt.Errorf("bundle %s not found", version)
+	}
+
+	var info BundleInfo
+	if err := json.Unmarshal(infoBytes, &info); err != nil {
+		return nil, "", fmt.Errorf("invalid bundle metadata: %w", err)
+	}
+
+	return &info, filepath.Join(dir, version+".zip"), nil
+}
+
+// DeployBundle sends an update_bundle command for a published bundle to the given kiosks,
+// or to every active kiosk when no IDs are provided
+func (s *ServiceImpl) DeployBundle(ctx context.Context, version string, tokenIDs []string) ([]*models.KioskCommand, error) {
+	info, _, err := s.GetBundle(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokenIDs) == 0 {
+		tokens, err := s.repo.GetAllTokens(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			if token.Active {
+				tokenIDs = append(tokenIDs, token.ID)
+			}
+		}
+	}
+
+	commands := make([]*models.KioskCommand, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		command, err := s.SendTypedCommand(ctx, tokenID, &CommandRequest{
+			Type: CommandUpdateBundle,
+			Payload: map[string]interface{}{
+				"version": info.Version,
+				"url":     info.URL,
+				"sha256":  info.SHA256,
+				"size":    info.Size,
+			},
+		})
+		if err != nil {
+			return commands, fmt.Errorf("failed to queue bundle update for kiosk %s: %w", tokenID, err)
+		}
+		commands = append(commands, command)
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,235 @@
+package kiosk
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CommandType identifies a typed remote management command for kiosk devices
+type CommandType string
+
+const (
+	CommandReload           CommandType = "reload"
+	CommandNavigate         CommandType = "navigate"
+	CommandSetBrightness    CommandType = "set_brightness"
+	CommandScreenshot       CommandType = "screenshot"
+	CommandShowAnnouncement CommandType = "show_announcement"
+	CommandUpdateBundle     CommandType = "update_bundle"
+)
+
+// WebSocket message types used by the kiosk real-time protocol
+const (
+	// Kiosk -> server
+	MessageTypeKioskRegister      = "kiosk_register"
+	MessageTypeKioskHeartbeat     = "kiosk_heartbeat"
+	MessageTypeKioskCommandAck    = "kiosk_command_ack"
+	MessageTypeKioskCommandResult = "kiosk_command_result"
+
+	// Server -> kiosk
+	MessageTypeKioskRegistered = "kiosk_registered"
+	MessageTypeKioskCommand    = "kiosk_command"
+
+	// Server -> admin clients
+	MessageTypeKioskCommandUpdate = "kiosk_command_update"
+	MessageTypeKioskStatusChanged = "kiosk_status_changed"
+)
+
+// Default command timeouts, measured from delivery to the kiosk
+var defaultCommandTimeouts = map[CommandType]time.Duration{
+	CommandReload:           30 * time.Second,
+	CommandNavigate:         15 * time.Second,
+	CommandSetBrightness:    15 * time.Second,
+	CommandScreenshot:       60 * time.Second,
+	CommandShowAnnouncement: 15 * time.Second,
+	CommandUpdateBundle:     10 * time.Minute,
+}
+
+// CommandRequest is a typed command submitted by an administrator
+type CommandRequest struct {
+	Type           CommandType            `json:"type" binding:"required"`
+	Payload        map[string]interface{} `json:"payload,omitempty"`
+	TimeoutSeconds int                    `json:"timeout_seconds,omitempty"`
+}
+
+// ReloadPayload reloads the kiosk frontend
+type ReloadPayload struct {
+	ClearCache bool `json:"clear_cache,omitempty"`
+}
+
+// NavigatePayload navigates the kiosk frontend to a route
+type NavigatePayload struct {
+	Path string `json:"path"`
+}
+
+// SetBrightnessPayload sets the kiosk display brightness
+type SetBrightnessPayload struct {
+	Brightness int `json:"brightness"`
+}
+
+// ScreenshotPayload asks the kiosk to capture its screen and upload it
+type ScreenshotPayload struct {
+	Format    string `json:"format,omitempty"`  // png, jpeg
+	Quality   int    `json:"quality,omitempty"` // 1-100, jpeg only
+	UploadURL string `json:"upload_url,omitempty"`
+}
+
+// AnnouncementPayload shows an on-screen announcement
+type AnnouncementPayload struct {
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+	Level           string `json:"level,omitempty"` // info, warning, critical
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+}
+
+// UpdateBundlePayload instructs the kiosk to download and install a frontend bundle
+type UpdateBundlePayload struct {
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size,omitempty"`
+}
+
+var (
+	sha256Pattern  = regexp.MustCompile(`^[a-f0-9]{64}$`)
+	versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+)
+
+// IsValidCommandType reports whether the command type is part of the typed protocol
+func IsValidCommandType(commandType CommandType) bool {
+	_, ok := defaultCommandTimeouts[commandType]
+	return ok
+}
+
+// DefaultCommandTimeout returns the default result timeout for a command type
+func DefaultCommandTimeout(commandType CommandType) time.Duration {
+	if timeout, ok := defaultCommandTimeouts[commandType]; ok {
+		return timeout
+	}
+	return 60 * time.Second
+}
+
+// Timeout returns the effective timeout for the request
+func (r *CommandRequest) Timeout() time.Duration {
+	if r.TimeoutSeconds > 0 {
+		return time.Duration(r.TimeoutSeconds) * time.Second
+	}
+	return DefaultCommandTimeout(r.Type)
+}
+
+// Validate checks the command type and decodes the payload into its typed form,
+// normalizing the payload map with defaults applied
+func (r *CommandRequest) Validate() error {
+	if !IsValidCommandType(r.Type) {
+		return fmt.Errorf("unsupported command type: %s", r.Type)
+	}
+	if r.TimeoutSeconds < 0 || r.TimeoutSeconds > 3600 {
+		return fmt.Errorf("timeout_seconds must be between 0 and 3600")
+	}
+
+	switch r.Type {
+	case CommandReload:
+		var p ReloadPayload
+		if err := decodePayload(r.Payload, &p); err != nil {
+			return err
+		}
+		return r.setPayload(p)
+
+	case CommandNavigate:
+		var p NavigatePayload
+		if err := decodePayload(r.Payload, &p); err != nil {
+			return err
+		}
+		if p.Path == "" || !strings.HasPrefix(p.Path, "/") {
+			return fmt.Errorf("navigate requires an absolute path starting with '/'")
+		}
+		return r.setPayload(p)
+
+	case CommandSetBrightness:
+		var p SetBrightnessPayload
+		if err := decodePayload(r.Payload, &p); err != nil {
+			return err
+		}
+		if p.Brightness < 0 || p.Brightness > 100 {
+			return fmt.Errorf("brightness must be between 0 and 100")
+		}
+		return r.setPayload(p)
+
+	case CommandScreenshot:
+		var p ScreenshotPayload
+		if err := decodePayload(r.Payload, &p); err != nil {
+			return err
+		}
+		if p.Format == "" {
+			p.Format = "png"
+		}
+		if p.Format != "png" && p.Format != "jpeg" {
+			return fmt.Errorf("screenshot format must be png or jpeg")
+		}
+		if p.Quality < 0 || p.Quality > 100 {
+			return fmt.Errorf("screenshot quality must be between 1 and 100")
+		}
+		return r.setPayload(p)
+
+	case CommandShowAnnouncement:
+		var p AnnouncementPayload
+		if err := decodePayload(r.Payload, &p); err != nil {
+			return err
+		}
+		if strings.TrimSpace(p.Message) == "" {
+			return fmt.Errorf("announcement message is required")
+		}
+		if p.Level == "" {
+			p.Level = "info"
+		}
+		if p.Level != "info" && p.Level != "warning" && p.Level != "critical" {
+			return fmt.Errorf("announcement level must be info, warning or critical")
+		}
+		if p.DurationSeconds < 0 {
+			return fmt.Errorf("announcement duration cannot be negative")
+		}
+		return r.setPayload(p)
+
+	case CommandUpdateBundle:
+		var p UpdateBundlePayload
+		if err := decodePayload(r.Payload, &p); err != nil {
+			return err
+		}
+		if !versionPattern.MatchString(p.Version) {
+			return fmt.Errorf("invalid bundle version: %q", p.Version)
+		}
+		p.SHA256 = strings.ToLower(p.SHA256)
+		if !sha256Pattern.MatchString(p.SHA256) {
+			return fmt.Errorf("bundle sha256 must be a 64 character hex digest")
+		}
+		return r.setPayload(p)
+	}
+
+	return nil
+}
+
+func (r *CommandRequest) setPayload(payload interface{}) error {
+	normalized := make(map[string]interface{})
+	if err := decodePayload(payload, &normalized); err != nil {
+		return err
+	}
+	r.Payload = normalized
+	return nil
+}
+
+// decodePayload converts a loosely typed payload into the target struct via JSON
+func decodePayload(payload interface{}, target interface{}) error {
+	if payload == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("invalid command payload: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid command payload: %w", err)
+	}
+	return nil
+}
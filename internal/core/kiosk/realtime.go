@@ -0,0 +1,569 @@
+package kiosk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// clientMetadataTokenID is the WebSocket client metadata key holding the kiosk token ID
+	clientMetadataTokenID = "kiosk_token_id"
+
+	monitorInterval = 5 * time.Second
+)
+
+// FleetEntry summarizes the connection and health state of a paired kiosk
+type FleetEntry struct {
+	KioskID         string     `json:"kiosk_id"`
+	Name            string     `json:"name"`
+	RoomID          string     `json:"room_id"`
+	Active          bool       `json:"active"`
+	Status          string     `json:"status"`
+	Connected       bool       `json:"connected"`
+	LastHeartbeat   *time.Time `json:"last_heartbeat,omitempty"`
+	PendingCommands int        `json:"pending_commands"`
+}
+
+// commandTimeout returns the result timeout stored with a command, or its type's default
+func commandTimeout(command *models.KioskCommand) time.Duration {
+	if command.TimeoutSeconds > 0 {
+		return time.Duration(command.TimeoutSeconds) * time.Second
+	}
+	return DefaultCommandTimeout(CommandType(command.CommandType))
+}
+
+// commandDeadline returns when a delivered command times out. The timeout runs from
+// delivery rather than creation, so commands queued while a kiosk was offline get
+// their full timeout once it picks them up.
+func commandDeadline(command *models.KioskCommand) time.Time {
+	start := command.CreatedAt
+	if command.SentAt.Valid {
+		start = command.SentAt.Time
+	} else if command.AcknowledgedAt.Valid {
+		start = command.AcknowledgedAt.Time
+	}
+	return start.Add(commandTimeout(command))
+}
+
+// ======== TYPED COMMANDS ========
+
+// SendTypedCommand validates and queues a typed command, delivering it immediately
+// over the WebSocket hub when the kiosk is connected
+func (s *ServiceImpl) SendTypedCommand(ctx context.Context, tokenID string, request *CommandRequest) (*models.KioskCommand, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	commandID := uuid.New().String()
+
+	// Screenshots are uploaded back over HTTP to a per-command endpoint
+	if request.Type == CommandScreenshot {
+		request.Payload["upload_url"] = fmt.Sprintf("/api/v1/kiosk-client/commands/%s/screenshot", commandID)
+	}
+
+	commandData, err := json.Marshal(request.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode command payload: %w", err)
+	}
+
+	command := &models.KioskCommand{
+		ID:             commandID,
+		KioskTokenID:   tokenID,
+		CommandType:    string(request.Type),
+		CommandData:    commandData,
+		Status:         "pending",
+		CreatedAt:      time.Now(),
+		ExpiresAt:      time.Now().Add(24 * time.Hour),
+		TimeoutSeconds: int(request.Timeout().Seconds()),
+	}
+
+	if err := s.repo.CreateCommand(ctx, command); err != nil {
+		return nil, fmt.Errorf("failed to create command: %w", err)
+	}
+
+	_ = s.LogActivity(ctx, tokenID, "info", "system",
+		fmt.Sprintf("Remote command queued: %s", request.Type),
+		map[string]interface{}{
+			"command_id": command.ID,
+			"payload":    request.Payload,
+		})
+
+	if s.deliverCommand(ctx, command) {
+		command.Status = "sent"
+	}
+
+	return command, nil
+}
+
+// GetCommand retrieves a command by ID
+func (s *ServiceImpl) GetCommand(ctx context.Context, commandID string) (*models.KioskCommand, error) {
+	return s.repo.GetCommand(ctx, commandID)
+}
+
+// GetCommandHistory retrieves recent commands for a kiosk
+func (s *ServiceImpl) GetCommandHistory(ctx context.Context, tokenID string, limit int) ([]*models.KioskCommand, error) {
+	return s.repo.GetCommandsByToken(ctx, tokenID, limit)
+}
+
+// deliverCommand pushes a command to the kiosk's WebSocket topic and starts its timeout.
+// It returns false when the kiosk is not connected; the command then stays pending
+// until the kiosk registers or polls.
+func (s *ServiceImpl) deliverCommand(ctx context.Context, command *models.KioskCommand) bool {
+	if s.wsHub == nil {
+		return false
+	}
+
+	topic := kioskTopic(command.KioskTokenID)
+	if s.wsHub.TopicSubscriberCount(topic) == 0 {
+		return false
+	}
+
+	var payload map[string]interface{}
+	_ = json.Unmarshal(command.CommandData, &payload)
+
+	timeout := commandTimeout(command)
+	s.wsHub.BroadcastToTopic(topic, MessageTypeKioskCommand, map[string]interface{}{
+		"command_id":      command.ID,
+		"command_type":    command.CommandType,
+		"payload":         payload,
+		"expires_at":      command.ExpiresAt.UTC().Format(time.RFC3339),
+		"timeout_seconds": int(timeout.Seconds()),
+	})
+
+	if err := s.repo.UpdateCommandStatus(ctx, command.ID, "sent"); err != nil {
+		s.logger.WithError(err).WithField("command_id", command.ID).Warn("Failed to mark kiosk command as sent")
+	}
+	s.broadcastCommandUpdate(ctx, command.ID)
+
+	return true
+}
+
+// deliverPendingCommands pushes every queued command for a kiosk that just connected
+func (s *ServiceImpl) deliverPendingCommands(ctx context.Context, tokenID string) {
+	commands, err := s.repo.GetPendingCommands(ctx, tokenID)
+	if err != nil {
+		s.logger.WithError(err).WithField("kiosk_id", tokenID).Warn("Failed to load pending kiosk commands")
+		return
+	}
+
+	for _, command := range commands {
+		if command.Status == "pending" {
+			s.deliverCommand(ctx, command)
+		}
+	}
+}
+
+// broadcastCommandUpdate notifies admin clients of a command status change
+func (s *ServiceImpl) broadcastCommandUpdate(ctx context.Context, commandID string) {
+	if s.wsHub == nil {
+		return
+	}
+
+	command, err := s.repo.GetCommand(ctx, commandID)
+	if err != nil {
+		return
+	}
+
+	update := map[string]interface{}{
+		"command_id":   command.ID,
+		"kiosk_id":     command.KioskTokenID,
+		"command_type": command.CommandType,
+		"status":       command.Status,
+	}
+	if len(command.ResultData) > 0 {
+		var result map[string]interface{}
+		if json.Unmarshal(command.ResultData, &result) == nil {
+			update["result_data"] = result
+		}
+	}
+	if command.ErrorMessage.Valid && command.ErrorMessage.String != "" {
+		update["error"] = command.ErrorMessage.String
+	}
+
+	s.wsHub.BroadcastToAll(MessageTypeKioskCommandUpdate, update)
+}
+
+// ======== WEBSOCKET PROTOCOL ========
+
+// registerRealtimeHandlers wires the kiosk protocol into the WebSocket hub
+func (s *ServiceImpl) registerRealtimeHandlers() {
+	s.wsHub.RegisterMessageHandler(MessageTypeKioskRegister, s.handleRegisterMessage)
+	s.wsHub.RegisterMessageHandler(MessageTypeKioskHeartbeat, s.handleHeartbeatMessage)
+	s.wsHub.RegisterMessageHandler(MessageTypeKioskCommandAck, s.handleCommandAckMessage)
+	s.wsHub.RegisterMessageHandler(MessageTypeKioskCommandResult, s.handleCommandResultMessage)
+	s.wsHub.OnClientDisconnect(s.handleClientDisconnect)
+}
+
+func (s *ServiceImpl) handleRegisterMessage(client *websocket.Client, msg websocket.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, _ := msg.Data["token"].(string)
+	kioskToken, err := s.ValidateToken(ctx, token)
+	if err != nil || !kioskToken.Active {
+		client.Send(websocket.EventTypeError, map[string]interface{}{
+			"error": "Invalid or expired kiosk token",
+		})
+		return
+	}
+
+	client.SetMetadata(clientMetadataTokenID, kioskToken.ID)
+	s.wsHub.SubscribeClientToTopic(client, kioskTopic(kioskToken.ID))
+
+	if err := s.RecordHeartbeat(ctx, kioskToken.ID); err != nil {
+		s.logger.WithError(err).Warn("Failed to record kiosk heartbeat on register")
+	}
+
+	_ = s.LogActivity(ctx, kioskToken.ID, "info", "system", "Kiosk connected for real-time commands",
+		map[string]interface{}{"client_id": client.ID})
+
+	client.Send(MessageTypeKioskRegistered, map[string]interface{}{
+		"kiosk_id": kioskToken.ID,
+		"room_id":  kioskToken.RoomID,
+	})
+
+	s.deliverPendingCommands(ctx, kioskToken.ID)
+}
+
+func (s *ServiceImpl) handleHeartbeatMessage(client *websocket.Client, msg websocket.Message) {
+	tokenID, ok := clientTokenID(client)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.RecordHeartbeat(ctx, tokenID); err != nil {
+		s.logger.WithError(err).WithField("kiosk_id", tokenID).Warn("Failed to record kiosk heartbeat")
+	}
+}
+
+func (s *ServiceImpl) handleCommandAckMessage(client *websocket.Client, msg websocket.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	command, ok := s.clientCommand(ctx, client, msg)
+	if !ok {
+		return
+	}
+
+	if err := s.AcknowledgeCommand(ctx, command.ID); err != nil {
+		s.logger.WithError(err).WithField("command_id", command.ID).Warn("Failed to acknowledge kiosk command")
+	}
+}
+
+func (s *ServiceImpl) handleCommandResultMessage(client *websocket.Client, msg websocket.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	command, ok := s.clientCommand(ctx, client, msg)
+	if !ok {
+		return
+	}
+
+	success, _ := msg.Data["success"].(bool)
+	resultData, _ := msg.Data["result_data"].(map[string]interface{})
+	errorMsg := ""
+	if !success {
+		errorMsg, _ = msg.Data["error"].(string)
+		if errorMsg == "" {
+			errorMsg = "command failed on kiosk"
+		}
+	}
+
+	if err := s.CompleteCommand(ctx, command.ID, resultData, errorMsg); err != nil {
+		s.logger.WithError(err).WithField("command_id", command.ID).Warn("Failed to complete kiosk command")
+	}
+}
+
+func (s *ServiceImpl) handleClientDisconnect(client *websocket.Client) {
+	tokenID, ok := clientTokenID(client)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_ = s.LogActivity(ctx, tokenID, "info", "system", "Kiosk real-time connection closed",
+		map[string]interface{}{"client_id": client.ID})
+}
+
+// clientCommand resolves the command referenced by a kiosk message and verifies ownership
+func (s *ServiceImpl) clientCommand(ctx context.Context, client *websocket.Client, msg websocket.Message) (*models.KioskCommand, bool) {
+	tokenID, ok := clientTokenID(client)
+	if !ok {
+		return nil, false
+	}
+
+	commandID, _ := msg.Data["command_id"].(string)
+	if commandID == "" {
+		return nil, false
+	}
+
+	command, err := s.repo.GetCommand(ctx, commandID)
+	if err != nil || command.KioskTokenID != tokenID {
+		s.logger.WithFields(logrus.Fields{
+			"kiosk_id":   tokenID,
+			"command_id": commandID,
+		}).Warn("Kiosk referenced unknown command")
+		return nil, false
+	}
+
+	return command, true
+}
+
+// ======== BACKGROUND MONITORING ========
+
+// Start begins command timeout tracking and offline detection
+func (s *ServiceImpl) Start(ctx context.Context) error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("kiosk monitor already running")
+	}
+
+	s.stopChan = make(chan struct{})
+	s.running = true
+	go s.monitorLoop(ctx, s.stopChan)
+
+	s.logger.Info("Kiosk command and heartbeat monitor started")
+	return nil
+}
+
+// Stop stops background monitoring
+func (s *ServiceImpl) Stop() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+}
+
+func (s *ServiceImpl) monitorLoop(ctx context.Context, stop chan struct{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.WithField("panic", r).Error("Kiosk monitor panic recovered")
+		}
+	}()
+
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s.failTimedOutCommands(ctx, now)
+			s.detectOfflineKiosks(ctx, now)
+		}
+	}
+}
+
+// failTimedOutCommands marks delivered commands without a result as failed. Commands
+// are read from the database, so those picked up by polling or in flight across a
+// restart time out too.
+func (s *ServiceImpl) failTimedOutCommands(ctx context.Context, now time.Time) {
+	commands, err := s.repo.GetInFlightCommands(ctx)
+	if err != nil {
+		s.logger.WithError(err).Debug("Failed to load in-flight kiosk commands")
+		return
+	}
+
+	for _, cmd := range commands {
+		if !now.After(commandDeadline(cmd)) {
+			continue
+		}
+
+		errorMsg := fmt.Sprintf("command %s timed out waiting for kiosk result", cmd.CommandType)
+		timedOut, err := s.repo.TimeOutCommand(ctx, cmd.ID, errorMsg)
+		if err != nil {
+			s.logger.WithError(err).WithField("command_id", cmd.ID).Warn("Failed to mark kiosk command as timed out")
+			continue
+		}
+		if !timedOut {
+			continue // a result arrived in the meantime
+		}
+
+		_ = s.LogActivity(ctx, cmd.KioskTokenID, "warn", "system", errorMsg,
+			map[string]interface{}{"command_id": cmd.ID})
+		s.broadcastCommandUpdate(ctx, cmd.ID)
+	}
+}
+
+// detectOfflineKiosks marks kiosks whose heartbeats stopped as offline and raises alerts
+func (s *ServiceImpl) detectOfflineKiosks(ctx context.Context, now time.Time) {
+	statuses, err := s.repo.GetAllDeviceStatuses(ctx)
+	if err != nil {
+		s.logger.WithError(err).Debug("Failed to load kiosk statuses for offline detection")
+		return
+	}
+
+	for _, status := range statuses {
+		if status.Status != "online" || now.Sub(status.LastHeartbeat) <= s.offlineAfter {
+			continue
+		}
+
+		if err := s.repo.SetDeviceStatus(ctx, status.KioskTokenID, "offline"); err != nil {
+			s.logger.WithError(err).WithField("kiosk_id", status.KioskTokenID).Warn("Failed to mark kiosk offline")
+			continue
+		}
+		s.handleKioskOffline(ctx, status)
+	}
+}
+
+func (s *ServiceImpl) handleKioskOffline(ctx context.Context, status *models.KioskDeviceStatus) {
+	tokenID := status.KioskTokenID
+	name := s.kioskName(ctx, tokenID)
+
+	s.logger.WithFields(logrus.Fields{
+		"kiosk_id":       tokenID,
+		"last_heartbeat": status.LastHeartbeat,
+	}).Warn("Kiosk went offline")
+
+	_ = s.LogActivity(ctx, tokenID, "warn", "system", "Kiosk heartbeat lost, marked offline",
+		map[string]interface{}{"last_heartbeat": status.LastHeartbeat})
+
+	if s.alertManager != nil {
+		alertID := "kiosk_offline_" + tokenID
+		err := s.alertManager.CreateAlert(monitor.Alert{
+			ID:       alertID,
+			Severity: monitor.AlertSeverityWarning,
+			Source:   "kiosk",
+			Message:  fmt.Sprintf("Kiosk '%s' is offline (no heartbeat since %s)", name, status.LastHeartbeat.Format(time.RFC3339)),
+			Details: map[string]interface{}{
+				"kiosk_id":       tokenID,
+				"kiosk_name":     name,
+				"last_heartbeat": status.LastHeartbeat,
+			},
+		})
+		if err == nil {
+			s.offlineAlertsMu.Lock()
+			s.offlineAlerts[tokenID] = alertID
+			s.offlineAlertsMu.Unlock()
+		}
+	}
+
+	if s.wsHub != nil {
+		s.wsHub.BroadcastToAll(MessageTypeKioskStatusChanged, map[string]interface{}{
+			"kiosk_id":       tokenID,
+			"kiosk_name":     name,
+			"status":         "offline",
+			"last_heartbeat": status.LastHeartbeat,
+		})
+	}
+}
+
+func (s *ServiceImpl) handleKioskOnline(ctx context.Context, tokenID string) {
+	s.logger.WithField("kiosk_id", tokenID).Info("Kiosk back online")
+
+	s.offlineAlertsMu.Lock()
+	alertID, hasAlert := s.offlineAlerts[tokenID]
+	delete(s.offlineAlerts, tokenID)
+	s.offlineAlertsMu.Unlock()
+
+	if hasAlert && s.alertManager != nil {
+		_ = s.alertManager.ResolveAlertBy(alertID, "kiosk_heartbeat")
+	}
+
+	if s.wsHub != nil {
+		s.wsHub.BroadcastToAll(MessageTypeKioskStatusChanged, map[string]interface{}{
+			"kiosk_id":   tokenID,
+			"kiosk_name": s.kioskName(ctx, tokenID),
+			"status":     "online",
+		})
+	}
+}
+
+// GetFleetStatus returns the connection state of every paired kiosk
+func (s *ServiceImpl) GetFleetStatus(ctx context.Context) ([]*FleetEntry, error) {
+	tokens, err := s.repo.GetAllTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.repo.GetAllDeviceStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statusByToken := make(map[string]*models.KioskDeviceStatus, len(statuses))
+	for _, status := range statuses {
+		statusByToken[status.KioskTokenID] = status
+	}
+
+	fleet := make([]*FleetEntry, 0, len(tokens))
+	for _, token := range tokens {
+		entry := &FleetEntry{
+			KioskID: token.ID,
+			Name:    token.Name,
+			RoomID:  token.RoomID,
+			Active:  token.Active,
+			Status:  "unknown",
+		}
+
+		if status, ok := statusByToken[token.ID]; ok {
+			entry.Status = status.Status
+			lastHeartbeat := status.LastHeartbeat
+			entry.LastHeartbeat = &lastHeartbeat
+		}
+		if s.wsHub != nil {
+			entry.Connected = s.wsHub.TopicSubscriberCount(kioskTopic(token.ID)) > 0
+		}
+		if pending, err := s.repo.GetPendingCommands(ctx, token.ID); err == nil {
+			entry.PendingCommands = len(pending)
+		}
+
+		fleet = append(fleet, entry)
+	}
+
+	return fleet, nil
+}
+
+// kioskName returns a kiosk's display name, falling back to its ID
+func (s *ServiceImpl) kioskName(ctx context.Context, tokenID string) string {
+	tokens, err := s.repo.GetAllTokens(ctx)
+	if err == nil {
+		for _, token := range tokens {
+			if token.ID == tokenID {
+				return token.Name
+			}
+		}
+	}
+	return tokenID
+}
+
+// kioskTopic returns the WebSocket topic a kiosk subscribes to for commands
+func kioskTopic(tokenID string) string {
+	return "kiosk:" + tokenID
+}
+
+// ClientKioskID returns the ID of the kiosk a WebSocket client registered as, if any
+func ClientKioskID(client *websocket.Client) (string, bool) {
+	return clientTokenID(client)
+}
+
+// clientTokenID returns the kiosk token ID a WebSocket client registered with
+func clientTokenID(client *websocket.Client) (string, bool) {
+	value, ok := client.GetMetadata(clientMetadataTokenID)
+	if !ok {
+		return "", false
+	}
+	tokenID, ok := value.(string)
+	return tokenID, ok && tokenID != ""
+}
@@ -6,13 +6,17 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
 	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
 	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -57,6 +61,23 @@ type Service interface {
 	AcknowledgeCommand(ctx context.Context, commandID string) error
 	CompleteCommand(ctx context.Context, commandID string, resultData map[string]interface{}, errorMsg string) error
 
+	// Typed real-time commands
+	SendTypedCommand(ctx context.Context, tokenID string, request *CommandRequest) (*models.KioskCommand, error)
+	GetCommand(ctx context.Context, commandID string) (*models.KioskCommand, error)
+	GetCommandHistory(ctx context.Context, tokenID string, limit int) ([]*models.KioskCommand, error)
+	SaveScreenshot(ctx context.Context, tokenID, commandID, format string, data io.Reader) (string, error)
+	GetScreenshotPath(ctx context.Context, commandID string) (string, error)
+
+	// Frontend bundle distribution
+	PublishBundle(ctx context.Context, version string, data io.Reader) (*BundleInfo, error)
+	GetBundle(version string) (*BundleInfo, string, error)
+	DeployBundle(ctx context.Context, version string, tokenIDs []string) ([]*models.KioskCommand, error)
+
+	// Fleet monitoring
+	GetFleetStatus(ctx context.Context) ([]*FleetEntry, error)
+	Start(ctx context.Context) error
+	Stop()
+
 	// Statistics and health
 	GetKioskStats(ctx context.Context) (*models.KioskStatsResponse, error)
 	CleanupExpiredData(ctx context.Context) error
@@ -67,9 +88,20 @@ type ServiceImpl struct {
 	repo             repositories.KioskRepository
 	entityRepo       repositories.EntityRepository
 	roomRepo         repositories.RoomRepository
+	wsHub            *websocket.Hub
+	alertManager     *monitor.AlertManager
 	logger           *logrus.Logger
 	pinExpiryMinutes int
 	tokenExpiryHours int
+
+	// Offline detection
+	storageDir      string
+	offlineAfter    time.Duration
+	offlineAlerts   map[string]string // token ID -> alert ID
+	offlineAlertsMu sync.Mutex
+	stopChan        chan struct{}
+	running         bool
+	runMu           sync.Mutex
 }
 
 // NewService creates a new kiosk service
@@ -77,16 +109,32 @@ func NewService(
 	repo repositories.KioskRepository,
 	entityRepo repositories.EntityRepository,
 	roomRepo repositories.RoomRepository,
+	wsHub *websocket.Hub,
 	logger *logrus.Logger,
 ) Service {
-	return &ServiceImpl{
+	s := &ServiceImpl{
 		repo:             repo,
 		entityRepo:       entityRepo,
 		roomRepo:         roomRepo,
+		wsHub:            wsHub,
 		logger:           logger,
 		pinExpiryMinutes: 5,    // 5 minutes for PIN expiry
 		tokenExpiryHours: 2160, // 90 days for token expiry
+		storageDir:       "./data/kiosk",
+		offlineAfter:     90 * time.Second, // three missed 30s heartbeats
+		offlineAlerts:    make(map[string]string),
 	}
+
+	if wsHub != nil {
+		s.registerRealtimeHandlers()
+	}
+
+	return s
+}
+
+// SetAlertManager sets the alert manager used for offline-kiosk alerts
+func (s *ServiceImpl) SetAlertManager(alertManager *monitor.AlertManager) {
+	s.alertManager = alertManager
 }
 
 // ======== PAIRING AND TOKEN MANAGEMENT ========
@@ -602,9 +650,25 @@ func (s *ServiceImpl) GetDeviceStatus(ctx context.Context, tokenID string) (*mod
 	return s.repo.GetDeviceStatus(ctx, tokenID)
 }
 
-// RecordHeartbeat records a heartbeat for a kiosk device
+// RecordHeartbeat records a heartbeat for a kiosk device, bringing it back online if it was offline
 func (s *ServiceImpl) RecordHeartbeat(ctx context.Context, tokenID string) error {
-	return s.repo.UpdateHeartbeat(ctx, tokenID)
+	wasOffline := false
+	if status, err := s.repo.GetDeviceStatus(ctx, tokenID); err == nil && status.Status == "offline" {
+		wasOffline = true
+	}
+
+	if err := s.repo.UpdateHeartbeat(ctx, tokenID); err != nil {
+		return err
+	}
+
+	if wasOffline {
+		if err := s.repo.SetDeviceStatus(ctx, tokenID, "online"); err != nil {
+			s.logger.WithError(err).Warn("Failed to mark kiosk online")
+		}
+		s.handleKioskOnline(ctx, tokenID)
+	}
+
+	return nil
 }
 
 // ======== REMOTE COMMAND MANAGEMENT ========
@@ -630,20 +694,46 @@ func (s *ServiceImpl) SendCommand(ctx context.Context, tokenID, commandType stri
 	return command.ID, nil
 }
 
-// GetPendingCommands retrieves pending commands for a kiosk token
+// GetPendingCommands retrieves pending commands for a polling kiosk. Commands returned
+// for the first time are marked as sent so their result timeout starts.
 func (s *ServiceImpl) GetPendingCommands(ctx context.Context, tokenID string) ([]*models.KioskCommand, error) {
-	return s.repo.GetPendingCommands(ctx, tokenID)
+	commands, err := s.repo.GetPendingCommands(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, command := range commands {
+		if command.Status != "pending" {
+			continue
+		}
+		if err := s.repo.UpdateCommandStatus(ctx, command.ID, "sent"); err != nil {
+			s.logger.WithError(err).WithField("command_id", command.ID).Warn("Failed to mark kiosk command as sent")
+			continue
+		}
+		command.Status = "sent"
+		s.broadcastCommandUpdate(ctx, command.ID)
+	}
+
+	return commands, nil
 }
 
 // AcknowledgeCommand marks a command as acknowledged
 func (s *ServiceImpl) AcknowledgeCommand(ctx context.Context, commandID string) error {
-	return s.repo.UpdateCommandStatus(ctx, commandID, "acknowledged")
+	if err := s.repo.UpdateCommandStatus(ctx, commandID, "acknowledged"); err != nil {
+		return err
+	}
+	s.broadcastCommandUpdate(ctx, commandID)
+	return nil
 }
 
 // CompleteCommand marks a command as completed with result data
 func (s *ServiceImpl) CompleteCommand(ctx context.Context, commandID string, resultData map[string]interface{}, errorMsg string) error {
 	resultDataBytes, _ := json.Marshal(resultData)
-	return s.repo.CompleteCommand(ctx, commandID, resultDataBytes, errorMsg)
+	if err := s.repo.CompleteCommand(ctx, commandID, resultDataBytes, errorMsg); err != nil {
+		return err
+	}
+	s.broadcastCommandUpdate(ctx, commandID)
+	return nil
 }
 
 // ======== STATISTICS AND HEALTH ========
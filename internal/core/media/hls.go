@@ -0,0 +1,691 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/filemanager"
+	"github.com/sirupsen/logrus"
+)
+
+// SourceRendition is the name of the passthrough rendition that remuxes the original streams
+const SourceRendition = "source"
+
+// HLS defaults
+const (
+	defaultHLSSegmentDuration = 6
+	defaultHLSCacheMaxSize    = 2 * 1024 * 1024 * 1024
+	defaultHLSConcurrentJobs  = 2
+	hlsJobTimeout             = 2 * time.Minute
+
+	// hlsKeyframeSeekMargin is added to passthrough seeks: probed keyframe times are rounded
+	// to microseconds, and a seek just before a keyframe would snap to the previous one
+	hlsKeyframeSeekMargin = 0.001
+)
+
+var segmentNamePattern = regexp.MustCompile(`^segment_(\d{5})\.ts$`)
+
+// HLSRendition is one quality level of an adaptive stream
+type HLSRendition struct {
+	Name         string `json:"name"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	VideoBitrate int    `json:"video_bitrate"` // kbps
+	AudioBitrate int    `json:"audio_bitrate"` // kbps
+	Passthrough  bool   `json:"passthrough"`
+}
+
+// Bandwidth returns the peak bandwidth advertised in the master playlist in bits per second
+func (r HLSRendition) Bandwidth() int {
+	return int(float64(r.VideoBitrate+r.AudioBitrate) * 1000 * 1.1)
+}
+
+// ProbeResult is the subset of ffprobe output needed to plan an adaptive stream
+type ProbeResult struct {
+	Duration     float64 `json:"duration"`
+	VideoCodec   string  `json:"video_codec"`
+	VideoProfile string  `json:"video_profile"`
+	Width        int     `json:"width"`
+	Height       int     `json:"height"`
+	Bitrate      int     `json:"bitrate"` // kbps
+	AudioCodec   string  `json:"audio_codec"`
+
+	// Keyframes are the video keyframe times from the start of the file, probed for
+	// passthrough compatible sources because remuxed segments can only be cut on them
+	Keyframes []float64 `json:"-"`
+
+	startTime float64
+}
+
+// PassthroughCompatible reports whether the source streams can be remuxed into MPEG-TS
+// segments without transcoding
+func (p *ProbeResult) PassthroughCompatible() bool {
+	return p.VideoCodec == "h264" && (p.AudioCodec == "" || p.AudioCodec == "aac" || p.AudioCodec == "mp3")
+}
+
+// hlsProbeEntry caches a probe result for a specific version of a file
+type hlsProbeEntry struct {
+	result  *ProbeResult
+	modTime time.Time
+}
+
+// hlsSegment is the time range of one segment in the source
+type hlsSegment struct {
+	start    float64
+	duration float64
+}
+
+// hlsSegmentJob is an in-flight segment transcode other requests can wait on
+type hlsSegmentJob struct {
+	done chan struct{}
+	err  error
+}
+
+// HLSPackager generates HLS playlists and produces segments on demand with ffmpeg
+type HLSPackager struct {
+	config          config.HLSConfig
+	segmentDuration int
+	renditions      []HLSRendition
+	cache           *SegmentCache
+	logger          *logrus.Logger
+	jobs            chan struct{}
+	inFlight        map[string]*hlsSegmentJob
+	probes          map[string]*hlsProbeEntry
+	mutex           sync.Mutex
+}
+
+// NewHLSPackager creates a packager caching segments below cacheRoot
+func NewHLSPackager(cfg config.HLSConfig, cacheRoot string, logger *logrus.Logger) *HLSPackager {
+	if cfg.FFmpegPath == "" {
+		cfg.FFmpegPath = "ffmpeg"
+	}
+	if cfg.FFprobePath == "" {
+		cfg.FFprobePath = "ffprobe"
+	}
+	if cfg.SegmentDuration <= 0 {
+		cfg.SegmentDuration = defaultHLSSegmentDuration
+	}
+	if cfg.CacheMaxSize <= 0 {
+		cfg.CacheMaxSize = defaultHLSCacheMaxSize
+	}
+	if cfg.MaxConcurrentJobs <= 0 {
+		cfg.MaxConcurrentJobs = defaultHLSConcurrentJobs
+	}
+
+	return &HLSPackager{
+		config:          cfg,
+		segmentDuration: cfg.SegmentDuration,
+		renditions:      GetDefaultHLSRenditions(),
+		cache:           NewSegmentCache(cacheRoot, cfg.CacheMaxSize, logger),
+		logger:          logger,
+		jobs:            make(chan struct{}, cfg.MaxConcurrentJobs),
+		inFlight:        make(map[string]*hlsSegmentJob),
+		probes:          make(map[string]*hlsProbeEntry),
+	}
+}
+
+// GetDefaultHLSRenditions returns the transcoded renditions, derived from the default transcode profiles
+func GetDefaultHLSRenditions() []HLSRendition {
+	profiles := GetDefaultTranscodeProfiles()
+	renditions := make([]HLSRendition, 0, len(profiles))
+	for _, profile := range profiles {
+		width, height := parseResolution(profile.Resolution)
+		renditions = append(renditions, HLSRendition{
+			Name:         profile.Name,
+			Width:        width,
+			Height:       height,
+			VideoBitrate: profile.Bitrate,
+			AudioBitrate: 128,
+		})
+	}
+	return renditions
+}
+
+// Probe inspects a media file with ffprobe. Results are cached until the file changes.
+func (p *HLSPackager) Probe(ctx context.Context, fileID, path string) (*ProbeResult, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat media file: %w", err)
+	}
+
+	p.mutex.Lock()
+	if entry, ok := p.probes[fileID]; ok && entry.modTime.Equal(stat.ModTime()) {
+		p.mutex.Unlock()
+		return entry.result, nil
+	}
+	p.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.config.FFprobePath,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	result, err := parseProbeOutput(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.PassthroughCompatible() {
+		keyframes, err := p.probeKeyframes(path, result.startTime)
+		if err != nil {
+			// Without cut points the source is only offered transcoded
+			p.logger.WithError(err).WithField("file_id", fileID).Warn("Failed to probe keyframes for HLS passthrough")
+		}
+		result.Keyframes = keyframes
+	}
+
+	p.mutex.Lock()
+	p.probes[fileID] = &hlsProbeEntry{result: result, modTime: stat.ModTime()}
+	p.mutex.Unlock()
+
+	return result, nil
+}
+
+// probeKeyframes lists the video keyframe times of a file from its packet flags, which needs
+// no decoding. Times are relative to startTime, the way ffmpeg input seeking measures them.
+func (p *HLSPackager) probeKeyframes(path string, startTime float64) ([]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hlsJobTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.config.FFprobePath,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,flags",
+		"-of", "csv=print_section=0",
+		path,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	keyframes := parseKeyframes(output, startTime)
+	if len(keyframes) == 0 {
+		return nil, fmt.Errorf("file has no video keyframes")
+	}
+	return keyframes, nil
+}
+
+// RenditionsFor returns the renditions offered for a source: transcoded levels no larger than
+// the source, plus a passthrough rendition when the source codecs are HLS compatible and its
+// keyframes are known
+func (p *HLSPackager) RenditionsFor(probe *ProbeResult) []HLSRendition {
+	renditions := make([]HLSRendition, 0, len(p.renditions)+1)
+
+	if probe.PassthroughCompatible() && len(probe.Keyframes) > 0 {
+		bitrate := probe.Bitrate
+		if bitrate == 0 {
+			bitrate = 4000
+		}
+		renditions = append(renditions, HLSRendition{
+			Name:         SourceRendition,
+			Width:        probe.Width,
+			Height:       probe.Height,
+			VideoBitrate: bitrate,
+			Passthrough:  true,
+		})
+	}
+
+	for _, rendition := range p.renditions {
+		if probe.Height > 0 && rendition.Height > probe.Height {
+			continue
+		}
+		renditions = append(renditions, rendition)
+	}
+
+	// Always offer at least the smallest rendition for low resolution sources
+	if len(renditions) == 0 && len(p.renditions) > 0 {
+		renditions = append(renditions, p.renditions[len(p.renditions)-1])
+	}
+
+	return renditions
+}
+
+// FindRendition returns the named rendition if it is offered for the source
+func (p *HLSPackager) FindRendition(probe *ProbeResult, name string) (HLSRendition, bool) {
+	for _, rendition := range p.RenditionsFor(probe) {
+		if rendition.Name == name {
+			return rendition, true
+		}
+	}
+	return HLSRendition{}, false
+}
+
+// MasterPlaylist builds the multivariant playlist listing every rendition
+func (p *HLSPackager) MasterPlaylist(probe *ProbeResult, token string) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+
+	for _, rendition := range p.RenditionsFor(probe) {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d", rendition.Bandwidth())
+		if rendition.Width > 0 && rendition.Height > 0 {
+			fmt.Fprintf(&b, ",RESOLUTION=%dx%d", rendition.Width, rendition.Height)
+		}
+		if codecs := rendition.codecs(probe); codecs != "" {
+			fmt.Fprintf(&b, ",CODECS=\"%s\"", codecs)
+		}
+		fmt.Fprintf(&b, ",NAME=\"%s\"\n", rendition.Name)
+		b.WriteString(withToken(fmt.Sprintf("renditions/%s/index.m3u8", rendition.Name), token))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// MediaPlaylist builds the VOD playlist of one rendition. Segments are listed up front and
+// produced when first requested.
+func (p *HLSPackager) MediaPlaylist(probe *ProbeResult, rendition HLSRendition, token string) string {
+	segments := p.segments(probe, rendition)
+
+	targetDuration := 0.0
+	for _, segment := range segments {
+		targetDuration = math.Max(targetDuration, segment.duration)
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(targetDuration)))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+
+	for i, segment := range segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n", segment.duration)
+		b.WriteString(withToken(segmentName(i), token))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.String()
+}
+
+// Segment returns the path of a cached segment, producing it first if necessary.
+// Concurrent requests for the same segment share one ffmpeg job.
+func (p *HLSPackager) Segment(ctx context.Context, fileID, sourcePath string, probe *ProbeResult, rendition HLSRendition, index int) (string, error) {
+	if index < 0 || index >= len(p.segments(probe, rendition)) {
+		return "", fmt.Errorf("segment %d out of range", index)
+	}
+
+	outputPath := filepath.Join(p.cache.Root(), fileID, rendition.Name, segmentName(index))
+	if p.cache.Get(outputPath) {
+		p.prefetch(fileID, sourcePath, probe, rendition, index+1)
+		return outputPath, nil
+	}
+
+	if err := p.produce(ctx, sourcePath, outputPath, probe, rendition, index); err != nil {
+		return "", err
+	}
+
+	p.prefetch(fileID, sourcePath, probe, rendition, index+1)
+	return outputPath, nil
+}
+
+// ParseSegmentName extracts the index from a segment file name
+func ParseSegmentName(name string) (int, error) {
+	match := segmentNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, fmt.Errorf("invalid segment name %q", name)
+	}
+	return strconv.Atoi(match[1])
+}
+
+// InvalidateFile drops cached probe data and segments for a file
+func (p *HLSPackager) InvalidateFile(fileID string) {
+	p.mutex.Lock()
+	delete(p.probes, fileID)
+	p.mutex.Unlock()
+	p.cache.RemoveFile(fileID)
+}
+
+// CacheStats returns the number and total size of cached segments
+func (p *HLSPackager) CacheStats() (int, int64) {
+	return p.cache.Stats()
+}
+
+// produce runs (or waits for) the ffmpeg job that writes a segment
+func (p *HLSPackager) produce(ctx context.Context, sourcePath, outputPath string, probe *ProbeResult, rendition HLSRendition, index int) error {
+	p.mutex.Lock()
+	if job, ok := p.inFlight[outputPath]; ok {
+		p.mutex.Unlock()
+		select {
+		case <-job.done:
+			return job.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	job := &hlsSegmentJob{done: make(chan struct{})}
+	p.inFlight[outputPath] = job
+	p.mutex.Unlock()
+
+	// The job is detached from the request so an aborted request does not waste the work
+	go func() {
+		job.err = p.transcodeSegment(sourcePath, outputPath, probe, rendition, index)
+		if job.err == nil {
+			p.cache.Add(outputPath)
+		}
+
+		p.mutex.Lock()
+		delete(p.inFlight, outputPath)
+		p.mutex.Unlock()
+		close(job.done)
+	}()
+
+	select {
+	case <-job.done:
+		return job.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// prefetch produces the next segment in the background when a job slot is free
+func (p *HLSPackager) prefetch(fileID, sourcePath string, probe *ProbeResult, rendition HLSRendition, index int) {
+	if index >= len(p.segments(probe, rendition)) {
+		return
+	}
+
+	outputPath := filepath.Join(p.cache.Root(), fileID, rendition.Name, segmentName(index))
+	if p.cache.Get(outputPath) {
+		return
+	}
+
+	// Only prefetch when there is spare capacity, player requests take priority
+	if len(p.jobs) >= cap(p.jobs) {
+		return
+	}
+
+	go func() {
+		if err := p.produce(context.Background(), sourcePath, outputPath, probe, rendition, index); err != nil {
+			p.logger.WithError(err).WithField("segment", outputPath).Debug("HLS segment prefetch failed")
+		}
+	}()
+}
+
+// transcodeSegment writes one MPEG-TS segment with ffmpeg
+func (p *HLSPackager) transcodeSegment(sourcePath, outputPath string, probe *ProbeResult, rendition HLSRendition, index int) error {
+	p.jobs <- struct{}{}
+	defer func() { <-p.jobs }()
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create segment directory: %w", err)
+	}
+
+	segment := p.segments(probe, rendition)[index]
+	tempPath := outputPath + ".part"
+	args := p.buildSegmentArgs(sourcePath, tempPath, rendition, segment.start, segment.duration)
+
+	ctx, cancel := context.WithTimeout(context.Background(), hlsJobTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	cmd := exec.CommandContext(ctx, p.config.FFmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		os.Remove(tempPath)
+		p.logger.WithFields(logrus.Fields{
+			"error":     err.Error(),
+			"stderr":    stderr.String(),
+			"rendition": rendition.Name,
+			"segment":   index,
+		}).Error("FFmpeg HLS segment failed")
+		return fmt.Errorf("segment transcoding failed: %w", err)
+	}
+
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to store segment: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"rendition": rendition.Name,
+		"segment":   index,
+		"took":      time.Since(startedAt).String(),
+	}).Debug("HLS segment produced")
+
+	return nil
+}
+
+// buildSegmentArgs constructs the ffmpeg arguments for one segment. Output timestamps are
+// offset to the segment start so consecutive segments play back as one continuous stream.
+func (p *HLSPackager) buildSegmentArgs(inputPath, outputPath string, rendition HLSRendition, start, duration float64) []string {
+	seek := start
+	if rendition.Passthrough && start > 0 {
+		seek += hlsKeyframeSeekMargin
+	}
+
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-y",
+		"-ss", formatSeconds(seek),
+		"-i", inputPath,
+		"-t", formatSeconds(start + duration - seek),
+		"-map", "0:v:0",
+		"-map", "0:a:0?",
+	}
+
+	if rendition.Passthrough {
+		// Passthrough segments start on a keyframe (see segments), so input seeking lands
+		// exactly on the cut and -t stops before the keyframe that starts the next segment
+		args = append(args, "-c", "copy", "-bsf:v", "h264_mp4toannexb")
+	} else {
+		args = append(args,
+			"-c:v", "libx264",
+			"-preset", "veryfast",
+			"-profile:v", "main",
+			"-pix_fmt", "yuv420p",
+			"-vf", fmt.Sprintf("scale=-2:%d", rendition.Height),
+			"-b:v", fmt.Sprintf("%dk", rendition.VideoBitrate),
+			"-maxrate", fmt.Sprintf("%dk", rendition.VideoBitrate*11/10),
+			"-bufsize", fmt.Sprintf("%dk", rendition.VideoBitrate*2),
+			"-force_key_frames", "expr:gte(t,0)",
+			"-c:a", "aac",
+			"-b:a", fmt.Sprintf("%dk", rendition.AudioBitrate),
+			"-ac", "2",
+		)
+	}
+
+	args = append(args,
+		"-output_ts_offset", formatSeconds(seek),
+		"-muxdelay", "0",
+		"-f", "mpegts",
+		outputPath,
+	)
+
+	return args
+}
+
+// segments returns the time ranges a rendition is cut into. Transcoded renditions are cut
+// every segment duration and keyframed at each cut. Passthrough renditions can only be cut
+// on source keyframes: a new segment starts at the first keyframe at least a segment duration
+// after the previous cut, so segments may run longer than the configured duration.
+func (p *HLSPackager) segments(probe *ProbeResult, rendition HLSRendition) []hlsSegment {
+	if probe.Duration <= 0 {
+		return nil
+	}
+
+	cuts := []float64{0}
+	if rendition.Passthrough {
+		for _, keyframe := range probe.Keyframes {
+			if keyframe < probe.Duration && keyframe-cuts[len(cuts)-1] >= float64(p.segmentDuration) {
+				cuts = append(cuts, keyframe)
+			}
+		}
+	} else {
+		for cut := float64(p.segmentDuration); cut < probe.Duration; cut += float64(p.segmentDuration) {
+			cuts = append(cuts, cut)
+		}
+	}
+
+	segments := make([]hlsSegment, len(cuts))
+	for i, cut := range cuts {
+		end := probe.Duration
+		if i+1 < len(cuts) {
+			end = cuts[i+1]
+		}
+		segments[i] = hlsSegment{start: cut, duration: end - cut}
+	}
+	return segments
+}
+
+// codecs returns the RFC 6381 codec string advertised for a rendition
+func (r HLSRendition) codecs(probe *ProbeResult) string {
+	video := "avc1.4d401f" // Main profile, level 3.1
+	if r.Passthrough {
+		switch strings.ToLower(probe.VideoProfile) {
+		case "high":
+			video = "avc1.640028"
+		case "baseline", "constrained baseline":
+			video = "avc1.42e01e"
+		}
+	}
+
+	if r.Passthrough && probe.AudioCodec == "" {
+		return video
+	}
+	if r.Passthrough && probe.AudioCodec == "mp3" {
+		return video + ",mp4a.40.34"
+	}
+	return video + ",mp4a.40.2"
+}
+
+// parseProbeOutput decodes ffprobe JSON output
+func parseProbeOutput(output []byte) (*ProbeResult, error) {
+	var probe struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Profile   string `json:"profile"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration  string `json:"duration"`
+			StartTime string `json:"start_time"`
+			BitRate   string `json:"bit_rate"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	result := &ProbeResult{}
+	result.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	result.startTime, _ = strconv.ParseFloat(probe.Format.StartTime, 64)
+	if bitrate, err := strconv.Atoi(probe.Format.BitRate); err == nil {
+		result.Bitrate = bitrate / 1000
+	}
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			if result.VideoCodec == "" {
+				result.VideoCodec = stream.CodecName
+				result.VideoProfile = stream.Profile
+				result.Width = stream.Width
+				result.Height = stream.Height
+			}
+		case "audio":
+			if result.AudioCodec == "" {
+				result.AudioCodec = stream.CodecName
+			}
+		}
+	}
+
+	if result.VideoCodec == "" {
+		return nil, fmt.Errorf("file has no video stream")
+	}
+	if result.Duration <= 0 {
+		return nil, fmt.Errorf("could not determine media duration")
+	}
+
+	return result, nil
+}
+
+// parseKeyframes extracts the keyframe times from ffprobe packet output in CSV form
+// (pts_time,flags per line), relative to startTime and in ascending order
+func parseKeyframes(output []byte, startTime float64) []float64 {
+	var keyframes []float64
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 2 || !strings.Contains(fields[1], "K") {
+			continue
+		}
+		pts, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue // pts_time is N/A for packets without a timestamp
+		}
+		keyframes = append(keyframes, math.Max(pts-startTime, 0))
+	}
+	sort.Float64s(keyframes)
+	return keyframes
+}
+
+// Helper functions
+
+func segmentName(index int) string {
+	return fmt.Sprintf("segment_%05d.ts", index)
+}
+
+func withToken(uri, token string) string {
+	if token == "" {
+		return uri
+	}
+	return uri + "?token=" + token
+}
+
+// formatSeconds keeps the microsecond precision of probed keyframe times, so a passthrough
+// segment ends just before the keyframe that starts the next one
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 6, 64)
+}
+
+func parseResolution(resolution string) (int, int) {
+	parts := strings.SplitN(resolution, "x", 2)
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	width, _ := strconv.Atoi(parts[0])
+	height, _ := strconv.Atoi(parts[1])
+	return width, height
+}
+
+// resolveSourcePath returns the on-disk path of a stored file
+func resolveSourcePath(cfg *config.FileManagerConfig, file *filemanager.File) string {
+	if filepath.IsAbs(file.Path) {
+		return file.Path
+	}
+	return filepath.Join(cfg.Storage.BasePath, file.Path)
+}
@@ -0,0 +1,176 @@
+package media
+
+import (
+	"io"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPackager(t *testing.T) *HLSPackager {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewHLSPackager(config.HLSConfig{SegmentDuration: 6}, t.TempDir(), logger)
+}
+
+// passthroughProbe is a 720p H.264 source with keyframes roughly every 2.5 seconds
+func passthroughProbe() *ProbeResult {
+	return &ProbeResult{
+		Duration:     15,
+		VideoCodec:   "h264",
+		VideoProfile: "High",
+		Width:        1280,
+		Height:       720,
+		Bitrate:      3000,
+		AudioCodec:   "aac",
+		Keyframes:    []float64{0, 2.5, 5, 6.5, 9, 12.7, 14, 15},
+	}
+}
+
+func TestSegments(t *testing.T) {
+	p := newTestPackager(t)
+	probe := passthroughProbe()
+
+	transcoded := p.segments(probe, HLSRendition{Name: "720p"})
+	assert.Equal(t, []hlsSegment{{0, 6}, {6, 6}, {12, 3}}, transcoded)
+
+	// Cuts fall on the first keyframe at least a segment duration after the previous cut
+	passthrough := p.segments(probe, HLSRendition{Name: SourceRendition, Passthrough: true})
+	require.Len(t, passthrough, 3)
+	assert.Equal(t, hlsSegment{0, 6.5}, passthrough[0])
+	assert.Equal(t, 6.5, passthrough[1].start)
+	assert.InDelta(t, 6.2, passthrough[1].duration, 1e-9)
+	assert.Equal(t, 12.7, passthrough[2].start)
+	assert.InDelta(t, 2.3, passthrough[2].duration, 1e-9)
+
+	assert.Empty(t, p.segments(&ProbeResult{}, HLSRendition{Name: "720p"}))
+}
+
+func TestRenditionsFor(t *testing.T) {
+	p := newTestPackager(t)
+
+	names := func(renditions []HLSRendition) []string {
+		list := make([]string, 0, len(renditions))
+		for _, rendition := range renditions {
+			list = append(list, rendition.Name)
+		}
+		return list
+	}
+
+	assert.Equal(t, []string{SourceRendition, "720p", "480p", "360p"}, names(p.RenditionsFor(passthroughProbe())))
+
+	hevc := passthroughProbe()
+	hevc.VideoCodec = "hevc"
+	hevc.Height = 480
+	assert.Equal(t, []string{"480p", "360p"}, names(p.RenditionsFor(hevc)), "no passthrough or upscaling")
+
+	withoutKeyframes := passthroughProbe()
+	withoutKeyframes.Keyframes = nil
+	assert.NotContains(t, names(p.RenditionsFor(withoutKeyframes)), SourceRendition)
+
+	assert.Equal(t, []string{"360p"}, names(p.RenditionsFor(&ProbeResult{VideoCodec: "mpeg4", Height: 240})), "the smallest rendition is always offered")
+
+	_, found := p.FindRendition(hevc, "720p")
+	assert.False(t, found)
+}
+
+func TestMasterPlaylist(t *testing.T) {
+	p := newTestPackager(t)
+
+	want := "#EXTM3U\n" +
+		"#EXT-X-VERSION:3\n" +
+		"#EXT-X-INDEPENDENT-SEGMENTS\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=3300000,RESOLUTION=1280x720,CODECS=\"avc1.640028,mp4a.40.2\",NAME=\"source\"\n" +
+		"renditions/source/index.m3u8?token=tok\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=2340800,RESOLUTION=1280x720,CODECS=\"avc1.4d401f,mp4a.40.2\",NAME=\"720p\"\n" +
+		"renditions/720p/index.m3u8?token=tok\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=1240800,RESOLUTION=854x480,CODECS=\"avc1.4d401f,mp4a.40.2\",NAME=\"480p\"\n" +
+		"renditions/480p/index.m3u8?token=tok\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=690800,RESOLUTION=640x360,CODECS=\"avc1.4d401f,mp4a.40.2\",NAME=\"360p\"\n" +
+		"renditions/360p/index.m3u8?token=tok\n"
+
+	assert.Equal(t, want, p.MasterPlaylist(passthroughProbe(), "tok"))
+}
+
+func TestMediaPlaylist(t *testing.T) {
+	p := newTestPackager(t)
+	probe := passthroughProbe()
+
+	want := "#EXTM3U\n" +
+		"#EXT-X-VERSION:3\n" +
+		"#EXT-X-PLAYLIST-TYPE:VOD\n" +
+		"#EXT-X-TARGETDURATION:7\n" +
+		"#EXT-X-MEDIA-SEQUENCE:0\n" +
+		"#EXTINF:6.500,\n" +
+		"segment_00000.ts?token=tok\n" +
+		"#EXTINF:6.200,\n" +
+		"segment_00001.ts?token=tok\n" +
+		"#EXTINF:2.300,\n" +
+		"segment_00002.ts?token=tok\n" +
+		"#EXT-X-ENDLIST\n"
+
+	source, found := p.FindRendition(probe, SourceRendition)
+	require.True(t, found)
+	assert.Equal(t, want, p.MediaPlaylist(probe, source, "tok"))
+
+	transcoded, _ := p.FindRendition(probe, "480p")
+	playlist := p.MediaPlaylist(probe, transcoded, "")
+	assert.Contains(t, playlist, "#EXT-X-TARGETDURATION:6\n")
+	assert.Contains(t, playlist, "#EXTINF:3.000,\nsegment_00002.ts\n", "no token without signing")
+}
+
+func TestBuildSegmentArgs(t *testing.T) {
+	p := newTestPackager(t)
+
+	args := p.buildSegmentArgs("in.mp4", "out.ts", HLSRendition{Name: SourceRendition, Passthrough: true}, 6.5, 6.2)
+	assert.Equal(t, []string{"-ss", "6.501000"}, args[4:6], "passthrough seeks past the keyframe")
+	assert.Equal(t, []string{"-t", "6.199000"}, args[8:10], "the segment still ends at the next cut")
+	assert.Contains(t, args, "copy")
+	assert.Equal(t, []string{"-output_ts_offset", "6.501000", "-muxdelay", "0", "-f", "mpegts", "out.ts"}, args[len(args)-7:])
+
+	args = p.buildSegmentArgs("in.mp4", "out.ts", HLSRendition{Name: "480p", Height: 480, VideoBitrate: 1000, AudioBitrate: 128}, 0, 6)
+	assert.Equal(t, []string{"-ss", "0.000000"}, args[4:6])
+	assert.Equal(t, []string{"-t", "6.000000"}, args[8:10])
+	assert.Contains(t, args, "scale=-2:480")
+	assert.Contains(t, args, "1000k")
+}
+
+func TestParseSegmentName(t *testing.T) {
+	index, err := ParseSegmentName(segmentName(42))
+	require.NoError(t, err)
+	assert.Equal(t, 42, index)
+
+	for _, name := range []string{"segment_1.ts", "segment_00001.mp4", "../segment_00001.ts"} {
+		_, err := ParseSegmentName(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	output := []byte(`{
+		"streams": [
+			{"codec_type": "audio", "codec_name": "aac"},
+			{"codec_type": "video", "codec_name": "h264", "profile": "Main", "width": 1920, "height": 1080}
+		],
+		"format": {"duration": "61.5", "start_time": "1.4", "bit_rate": "4500000"}
+	}`)
+
+	probe, err := parseProbeOutput(output)
+	require.NoError(t, err)
+	assert.Equal(t, 61.5, probe.Duration)
+	assert.Equal(t, 1.4, probe.startTime)
+	assert.Equal(t, 4500, probe.Bitrate)
+	assert.Equal(t, "h264", probe.VideoCodec)
+	assert.Equal(t, 1080, probe.Height)
+	assert.True(t, probe.PassthroughCompatible())
+
+	_, err = parseProbeOutput([]byte(`{"streams": [{"codec_type": "audio", "codec_name": "aac"}], "format": {"duration": "10"}}`))
+	assert.Error(t, err, "audio only")
+
+	keyframes := parseKeyframes([]byte("3.4,K_\n1.4,K_\n2.0,__\nN/A,K_\n"), 1.4)
+	assert.InDeltaSlice(t, []float64{0, 2}, keyframes, 1e-9)
+}
@@ -0,0 +1,152 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// segmentCacheEntry tracks one cached segment file
+type segmentCacheEntry struct {
+	size       int64
+	lastAccess time.Time
+}
+
+// SegmentCache keeps transcoded HLS segments on disk and evicts the least recently used
+// segments once the total size exceeds its limit
+type SegmentCache struct {
+	root      string
+	maxSize   int64
+	totalSize int64
+	entries   map[string]*segmentCacheEntry
+	mutex     sync.Mutex
+	logger    *logrus.Logger
+}
+
+// NewSegmentCache creates a cache rooted at root, indexing segments left by a previous run
+func NewSegmentCache(root string, maxSize int64, logger *logrus.Logger) *SegmentCache {
+	cache := &SegmentCache{
+		root:    root,
+		maxSize: maxSize,
+		entries: make(map[string]*segmentCacheEntry),
+		logger:  logger,
+	}
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if filepath.Ext(path) != ".ts" {
+			// Leftover partial outputs from an interrupted transcode
+			os.Remove(path)
+			return nil
+		}
+		cache.entries[path] = &segmentCacheEntry{size: info.Size(), lastAccess: info.ModTime()}
+		cache.totalSize += info.Size()
+		return nil
+	})
+
+	cache.mutex.Lock()
+	cache.evictLocked("")
+	cache.mutex.Unlock()
+
+	return cache
+}
+
+// Root returns the cache directory
+func (c *SegmentCache) Root() string {
+	return c.root
+}
+
+// Get reports whether a segment is cached and marks it as recently used
+func (c *SegmentCache) Get(path string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok {
+		return false
+	}
+	if _, err := os.Stat(path); err != nil {
+		c.totalSize -= entry.size
+		delete(c.entries, path)
+		return false
+	}
+
+	entry.lastAccess = time.Now()
+	return true
+}
+
+// Add records a newly written segment and evicts old segments if needed
+func (c *SegmentCache) Add(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if entry, ok := c.entries[path]; ok {
+		c.totalSize -= entry.size
+	}
+	c.entries[path] = &segmentCacheEntry{size: info.Size(), lastAccess: time.Now()}
+	c.totalSize += info.Size()
+
+	c.evictLocked(path)
+}
+
+// RemoveFile drops all cached segments of a file
+func (c *SegmentCache) RemoveFile(fileID string) {
+	dir := filepath.Join(c.root, fileID)
+
+	c.mutex.Lock()
+	for path, entry := range c.entries {
+		if filepath.Dir(filepath.Dir(path)) == dir {
+			c.totalSize -= entry.size
+			delete(c.entries, path)
+		}
+	}
+	c.mutex.Unlock()
+
+	os.RemoveAll(dir)
+}
+
+// Stats returns the number of cached segments and their total size
+func (c *SegmentCache) Stats() (int, int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries), c.totalSize
+}
+
+// evictLocked removes least recently used segments until the cache fits, never evicting keep
+func (c *SegmentCache) evictLocked(keep string) {
+	if c.maxSize <= 0 {
+		return
+	}
+
+	for c.totalSize > c.maxSize {
+		var oldestPath string
+		var oldest *segmentCacheEntry
+		for path, entry := range c.entries {
+			if path == keep {
+				continue
+			}
+			if oldest == nil || entry.lastAccess.Before(oldest.lastAccess) {
+				oldestPath, oldest = path, entry
+			}
+		}
+		if oldest == nil {
+			return
+		}
+
+		if err := os.Remove(oldestPath); err != nil && !os.IsNotExist(err) {
+			c.logger.WithError(err).WithField("path", oldestPath).Warn("Failed to evict HLS segment")
+		}
+		c.totalSize -= oldest.size
+		delete(c.entries, oldestPath)
+	}
+}
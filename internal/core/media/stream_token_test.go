@@ -0,0 +1,60 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTokenSigner(t *testing.T) {
+	signer := NewStreamTokenSigner([]byte("key"), time.Hour)
+	token := signer.Sign("file-1")
+
+	assert.NoError(t, signer.Verify("file-1", token))
+	assert.ErrorIs(t, signer.Verify("file-1", ""), ErrStreamTokenMissing)
+	assert.ErrorIs(t, signer.Verify("file-2", token), ErrStreamTokenInvalid, "tokens are bound to one file")
+	assert.ErrorIs(t, NewStreamTokenSigner([]byte("other"), time.Hour).Verify("file-1", token), ErrStreamTokenInvalid)
+}
+
+func TestStreamTokenSignerTampered(t *testing.T) {
+	signer := NewStreamTokenSigner([]byte("key"), time.Hour)
+	token := signer.Sign("file-1")
+	expires, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"extended expiry", fmt.Sprintf("%d.%s", time.Now().Add(24*time.Hour).Unix(), signature)},
+		{"altered signature", expires + "." + strings.ToUpper(signature)},
+		{"missing signature", expires},
+		{"non-numeric expiry", "soon." + signature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, signer.Verify("file-1", tt.token), ErrStreamTokenInvalid)
+		})
+	}
+}
+
+func TestStreamTokenSignerExpired(t *testing.T) {
+	signer := NewStreamTokenSigner([]byte("key"), time.Hour)
+	expires := time.Now().Add(-time.Minute).Unix()
+	token := fmt.Sprintf("%d.%s", expires, signer.signature("file-1", expires))
+
+	assert.ErrorIs(t, signer.Verify("file-1", token), ErrStreamTokenExpired)
+}
+
+func TestDeriveStreamSigningKey(t *testing.T) {
+	key := DeriveStreamSigningKey("jwt-secret")
+
+	assert.Len(t, key, 32)
+	assert.NotEqual(t, []byte("jwt-secret"), key, "the secret itself is not used")
+	assert.Equal(t, key, DeriveStreamSigningKey("jwt-secret"), "derived keys survive restarts")
+	assert.NotEqual(t, key, DeriveStreamSigningKey("other-secret"))
+	assert.Nil(t, DeriveStreamSigningKey(""))
+}
@@ -2,6 +2,7 @@ package media
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"os"
@@ -24,6 +25,12 @@ type MediaStreamer interface {
 	GetMediaInfo(fileID string) (*MediaInfo, error)
 	TranscodeVideo(fileID string, profile TranscodeProfile) error
 	GetStreamingURL(fileID string, options StreamOptions) (string, error)
+
+	// Adaptive (HLS) streaming
+	GetHLSMasterPlaylist(ctx context.Context, fileID string, token string) (string, error)
+	GetHLSMediaPlaylist(ctx context.Context, fileID string, rendition string, token string) (string, error)
+	GetHLSSegment(ctx context.Context, fileID string, rendition string, segment string) (string, error)
+	VerifyStreamToken(fileID string, token string) error
 }
 
 // StreamOptions contains options for media streaming
@@ -69,12 +76,21 @@ type LocalMediaStreamer struct {
 	logger      *logrus.Logger
 	processor   *MediaProcessor
 	thumbnails  *ThumbnailGenerator
+	hls         *HLSPackager
+	tokens      *StreamTokenSigner
 }
 
-// NewLocalMediaStreamer creates a new local media streamer
+// NewLocalMediaStreamer creates a new local media streamer. Signed stream URLs use a random
+// key until SetStreamSigningKey is called.
 func NewLocalMediaStreamer(cfg *config.FileManagerConfig, fm filemanager.FileManager, logger *logrus.Logger) *LocalMediaStreamer {
 	processor := NewMediaProcessor(cfg, logger)
 	thumbnails := NewThumbnailGenerator(cfg, logger)
+	hls := NewHLSPackager(cfg.Media.HLS, filepath.Join(cfg.Media.CachePath, "hls"), logger)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		logger.WithError(err).Warn("Failed to generate stream signing key")
+	}
 
 	return &LocalMediaStreamer{
 		config:      cfg,
@@ -82,9 +98,19 @@ func NewLocalMediaStreamer(cfg *config.FileManagerConfig, fm filemanager.FileMan
 		logger:      logger,
 		processor:   processor,
 		thumbnails:  thumbnails,
+		hls:         hls,
+		tokens:      NewStreamTokenSigner(key, parseDurationOr(cfg.Media.HLS.URLTTL, 6*time.Hour)),
 	}
 }
 
+// SetStreamSigningKey sets the key used to sign stream URLs, so URLs stay valid across restarts
+func (lms *LocalMediaStreamer) SetStreamSigningKey(key []byte) {
+	if len(key) == 0 {
+		return
+	}
+	lms.tokens = NewStreamTokenSigner(key, parseDurationOr(lms.config.Media.HLS.URLTTL, 6*time.Hour))
+}
+
 // StreamVideo implements MediaStreamer.StreamVideo
 func (lms *LocalMediaStreamer) StreamVideo(fileID string, options StreamOptions) (io.ReadSeeker, error) {
 	return lms.streamMedia(fileID, options, "video")
@@ -304,10 +330,27 @@ func (lms *LocalMediaStreamer) buildFFmpegArgs(inputPath, outputPath string, pro
 	return args
 }
 
-// GetStreamingURL implements MediaStreamer.GetStreamingURL
+// GetStreamingURL implements MediaStreamer.GetStreamingURL. Video files get a signed HLS
+// playlist URL unless another format is requested; the optional quality selects a rendition.
 func (lms *LocalMediaStreamer) GetStreamingURL(fileID string, options StreamOptions) (string, error) {
-	// Generate a streaming URL (this would typically be used with a streaming server)
-	baseURL := "/api/media/" + fileID + "/stream"
+	file, err := lms.fileManager.GetFileInfo(fileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if options.Format == "hls" || (options.Format == "" && lms.isMediaFile(file.MimeType, "video")) {
+		token := lms.tokens.Sign(fileID)
+		if options.Quality != "" && options.Quality != "auto" {
+			return withToken(fmt.Sprintf("/api/v1/media/hls/%s/renditions/%s/index.m3u8", fileID, options.Quality), token), nil
+		}
+		return withToken(fmt.Sprintf("/api/v1/media/hls/%s/master.m3u8", fileID), token), nil
+	}
+
+	mediaType := "video"
+	if lms.isMediaFile(file.MimeType, "audio") {
+		mediaType = "audio"
+	}
+	baseURL := "/api/v1/media/stream/" + mediaType + "/" + fileID
 
 	var params []string
 	if options.Quality != "" {
@@ -330,6 +373,77 @@ func (lms *LocalMediaStreamer) GetStreamingURL(fileID string, options StreamOpti
 	return baseURL, nil
 }
 
+// GetHLSMasterPlaylist implements MediaStreamer.GetHLSMasterPlaylist
+func (lms *LocalMediaStreamer) GetHLSMasterPlaylist(ctx context.Context, fileID string, token string) (string, error) {
+	_, probe, err := lms.probeVideo(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	return lms.hls.MasterPlaylist(probe, token), nil
+}
+
+// GetHLSMediaPlaylist implements MediaStreamer.GetHLSMediaPlaylist
+func (lms *LocalMediaStreamer) GetHLSMediaPlaylist(ctx context.Context, fileID string, rendition string, token string) (string, error) {
+	_, probe, err := lms.probeVideo(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	selected, ok := lms.hls.FindRendition(probe, rendition)
+	if !ok {
+		return "", fmt.Errorf("rendition %s not available", rendition)
+	}
+	return lms.hls.MediaPlaylist(probe, selected, token), nil
+}
+
+// GetHLSSegment implements MediaStreamer.GetHLSSegment and returns the path of the segment file
+func (lms *LocalMediaStreamer) GetHLSSegment(ctx context.Context, fileID string, rendition string, segment string) (string, error) {
+	index, err := ParseSegmentName(segment)
+	if err != nil {
+		return "", err
+	}
+
+	sourcePath, probe, err := lms.probeVideo(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+
+	selected, ok := lms.hls.FindRendition(probe, rendition)
+	if !ok {
+		return "", fmt.Errorf("rendition %s not available", rendition)
+	}
+
+	return lms.hls.Segment(ctx, fileID, sourcePath, probe, selected, index)
+}
+
+// VerifyStreamToken implements MediaStreamer.VerifyStreamToken
+func (lms *LocalMediaStreamer) VerifyStreamToken(fileID string, token string) error {
+	return lms.tokens.Verify(fileID, token)
+}
+
+// GetHLSCacheStats returns the number and total size of cached HLS segments
+func (lms *LocalMediaStreamer) GetHLSCacheStats() (int, int64) {
+	return lms.hls.CacheStats()
+}
+
+// probeVideo resolves a video file on disk and probes it for adaptive streaming
+func (lms *LocalMediaStreamer) probeVideo(ctx context.Context, fileID string) (string, *ProbeResult, error) {
+	file, err := lms.fileManager.GetFileInfo(fileID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if !lms.isMediaFile(file.MimeType, "video") {
+		return "", nil, fmt.Errorf("file is not a video file")
+	}
+
+	sourcePath := resolveSourcePath(lms.config, file)
+	probe, err := lms.hls.Probe(ctx, fileID, sourcePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to probe video: %w", err)
+	}
+
+	return sourcePath, probe, nil
+}
+
 // Helper methods
 
 // isMediaFile checks if a file is a media file of the specified type
@@ -383,6 +497,14 @@ func (lms *LocalMediaStreamer) cacheMediaInfo(info *MediaInfo) {
 	lms.logger.Debugf("Caching media info for file %s", info.FileID)
 }
 
+// parseDurationOr parses a duration string, returning fallback when it is empty or invalid
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return duration
+	}
+	return fallback
+}
+
 // GetDefaultTranscodeProfiles returns default transcoding profiles
 func GetDefaultTranscodeProfiles() []TranscodeProfile {
 	return []TranscodeProfile{
@@ -0,0 +1,435 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrMemoryNotFound is returned for unknown memory IDs and memories of other users
+	ErrMemoryNotFound = errors.New("memory not found")
+	// ErrEmptyMemory is returned for notes without content
+	ErrEmptyMemory = errors.New("memory content is required")
+)
+
+const (
+	// maxFactsPerExchange bounds the facts learned from one exchange
+	maxFactsPerExchange = 5
+	// maxMemoryLength bounds the length of a memory in characters
+	maxMemoryLength = 500
+	// lexicalMinScore is the minimum word overlap of a memory recalled without embeddings
+	lexicalMinScore = 0.2
+	// learnTimeout bounds fact extraction, which runs after the response was sent
+	learnTimeout = 2 * time.Minute
+)
+
+// extractionPrompt asks the LLM for the lasting facts of an exchange
+const extractionPrompt = `You extract lasting facts worth remembering from a smart home assistant conversation: household members, what rooms are used for, preferences, routines and the names people use for devices.
+Ignore one-off commands, current device states, questions, and anything the assistant said that the user did not state or confirm.
+Reply with only a JSON array, empty when nothing is worth remembering, of objects like {"fact": "The guest room is Maria's office", "personal": false}.
+Write each fact as a short standalone sentence. Set "personal" to true for preferences of the user themselves rather than facts about the household.
+
+Conversation:
+`
+
+// LLM embeds text and extracts facts; it is implemented by ai.LLMManager
+type LLM interface {
+	Chat(ctx context.Context, messages []ai.ChatMessage, opts ai.ChatOptions) (*ai.ChatResponse, error)
+	Embed(ctx context.Context, texts []string, opts ai.EmbeddingOptions) (*ai.EmbeddingResponse, error)
+}
+
+// Service stores what the assistant remembers and retrieves the memories relevant to a message
+// by embedding similarity, or by word overlap when no provider supports embeddings
+type Service struct {
+	repo   repositories.AIMemoryRepository
+	llm    LLM
+	cfg    config.AIMemoryConfig
+	logger *logrus.Logger
+
+	// learnMu serializes learning so concurrent exchanges do not store the same fact twice
+	learnMu sync.Mutex
+}
+
+// NewService creates a memory service
+func NewService(repo repositories.AIMemoryRepository, llm LLM, cfg config.AIMemoryConfig, logger *logrus.Logger) *Service {
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = 5
+	}
+	if cfg.MinScore <= 0 {
+		cfg.MinScore = 0.55
+	}
+	if cfg.DuplicateScore <= 0 {
+		cfg.DuplicateScore = 0.92
+	}
+	return &Service{repo: repo, llm: llm, cfg: cfg, logger: logger}
+}
+
+// List returns the memories visible to a user, newest first, and their total count
+func (s *Service) List(ctx context.Context, filter models.AIMemoryFilter) ([]*models.AIMemory, int, error) {
+	return s.repo.ListMemories(ctx, filter)
+}
+
+// AddNote stores a note written by a user. Shared notes are household memories every user sees.
+func (s *Service) AddNote(ctx context.Context, userID, content string, shared bool) (*models.AIMemory, error) {
+	content = cleanFact(content)
+	if content == "" {
+		return nil, ErrEmptyMemory
+	}
+
+	memory := &models.AIMemory{UserID: userID, Content: content, Source: models.AIMemorySourceNote}
+	if shared {
+		memory.UserID = ""
+	}
+	// Notes are kept without an embedding when none can be made; recall embeds them later
+	if embeddings, err := s.embed(ctx, []string{content}); err == nil {
+		memory.Embedding = embeddings.Vectors[0]
+		memory.EmbeddingModel = embeddingModel(embeddings)
+	} else {
+		s.logger.WithError(err).Debug("Storing note without embedding")
+	}
+
+	if err := s.repo.CreateMemory(ctx, memory); err != nil {
+		return nil, err
+	}
+	return memory, nil
+}
+
+// Delete forgets a memory visible to the user
+func (s *Service) Delete(ctx context.Context, userID string, id int64) error {
+	memory, err := s.repo.GetMemory(ctx, id)
+	if err != nil || (memory.UserID != "" && memory.UserID != userID) {
+		return fmt.Errorf("%w: %d", ErrMemoryNotFound, id)
+	}
+	return s.repo.DeleteMemory(ctx, id)
+}
+
+// Search returns up to limit memories visible to the user that are relevant to the text, best first
+func (s *Service) Search(ctx context.Context, userID, text string, limit int) ([]ai.RecalledMemory, error) {
+	memories, err := s.repo.GetUserMemories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memories) == 0 || strings.TrimSpace(text) == "" {
+		return []ai.RecalledMemory{}, nil
+	}
+
+	scores, minScore := s.score(ctx, text, memories)
+	results := []ai.RecalledMemory{}
+	for i, memory := range memories {
+		if scores[i] < minScore {
+			continue
+		}
+		results = append(results, ai.RecalledMemory{
+			ID:             memory.ID,
+			Content:        memory.Content,
+			Source:         memory.Source,
+			ConversationID: memory.ConversationID,
+			Score:          math.Round(scores[i]*1000) / 1000,
+			CreatedAt:      memory.CreatedAt,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Recall returns the memories to remind the assistant of when answering the text
+func (s *Service) Recall(ctx context.Context, userID, text string) ([]ai.RecalledMemory, error) {
+	results, err := s.Search(ctx, userID, text, s.cfg.MaxResults)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	if err := s.repo.RecordRecalls(ctx, ids, time.Now()); err != nil {
+		s.logger.WithError(err).Warn("Failed to record memory recalls")
+	}
+	return results, nil
+}
+
+// Learn asks the LLM for the lasting facts of an exchange and stores those not already known
+func (s *Service) Learn(ctx context.Context, userID, conversationID string, exchange []ai.ChatMessage) {
+	if !s.cfg.ExtractFacts || len(exchange) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, learnTimeout)
+	defer cancel()
+
+	facts, err := s.extractFacts(ctx, exchange)
+	if err != nil {
+		s.logger.WithError(err).WithField("conversation_id", conversationID).Warn("Failed to extract facts from conversation")
+		return
+	}
+	if len(facts) == 0 {
+		return
+	}
+
+	s.learnMu.Lock()
+	defer s.learnMu.Unlock()
+
+	known, err := s.repo.GetUserMemories(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to load memories")
+		return
+	}
+
+	texts := make([]string, len(facts))
+	for i, fact := range facts {
+		texts[i] = fact.Fact
+	}
+	embeddings, err := s.embed(ctx, texts)
+	if err != nil {
+		s.logger.WithError(err).Debug("Storing learned facts without embeddings")
+	}
+
+	for i, fact := range facts {
+		memory := &models.AIMemory{
+			UserID:         "",
+			Content:        fact.Fact,
+			Source:         models.AIMemorySourceConversation,
+			ConversationID: conversationID,
+		}
+		if fact.Personal {
+			memory.UserID = userID
+		}
+		if embeddings != nil {
+			memory.Embedding = embeddings.Vectors[i]
+			memory.EmbeddingModel = embeddingModel(embeddings)
+		}
+		if s.isKnown(memory, known) {
+			continue
+		}
+
+		if err := s.repo.CreateMemory(ctx, memory); err != nil {
+			s.logger.WithError(err).Warn("Failed to store learned fact")
+			continue
+		}
+		known = append(known, memory)
+		s.logger.WithFields(logrus.Fields{
+			"memory_id":       memory.ID,
+			"conversation_id": conversationID,
+		}).Info("Learned fact from conversation")
+	}
+}
+
+// extractedFact is a fact the LLM found in an exchange
+type extractedFact struct {
+	Fact     string `json:"fact"`
+	Personal bool   `json:"personal"`
+}
+
+// extractFacts asks the LLM for the facts of an exchange. The instructions travel in the user
+// message, as providers handle system prompts differently.
+func (s *Service) extractFacts(ctx context.Context, exchange []ai.ChatMessage) ([]extractedFact, error) {
+	var prompt strings.Builder
+	prompt.WriteString(extractionPrompt)
+	for _, message := range exchange {
+		role := "User"
+		if message.Role == "assistant" {
+			role = "Assistant"
+		}
+		fmt.Fprintf(&prompt, "%s: %s\n", role, message.Content)
+	}
+
+	response, err := s.llm.Chat(ctx, []ai.ChatMessage{{Role: "user", Content: prompt.String(), Timestamp: time.Now()}},
+		ai.ChatOptions{Temperature: 0.1, MaxTokens: 500, ToolChoice: "none"})
+	if err != nil {
+		return nil, err
+	}
+	return parseFacts(response.Message.Content)
+}
+
+// parseFacts reads the JSON array of an extraction response. Models often wrap it in prose or
+// code fences, and small models return plain strings.
+func parseFacts(content string) ([]extractedFact, error) {
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(content[start:end+1]), &items); err != nil {
+		return nil, fmt.Errorf("invalid fact list: %w", err)
+	}
+
+	var facts []extractedFact
+	for _, item := range items {
+		var fact extractedFact
+		if err := json.Unmarshal(item, &fact); err != nil {
+			if err := json.Unmarshal(item, &fact.Fact); err != nil {
+				continue
+			}
+		}
+		if fact.Fact = cleanFact(fact.Fact); fact.Fact != "" {
+			facts = append(facts, fact)
+		}
+		if len(facts) == maxFactsPerExchange {
+			break
+		}
+	}
+	return facts, nil
+}
+
+// isKnown reports whether a memory with the same meaning is already stored
+func (s *Service) isKnown(memory *models.AIMemory, known []*models.AIMemory) bool {
+	content := strings.ToLower(memory.Content)
+	for _, other := range known {
+		if strings.ToLower(other.Content) == content {
+			return true
+		}
+		if memory.EmbeddingModel != "" && other.EmbeddingModel == memory.EmbeddingModel &&
+			cosineSimilarity(memory.Embedding, other.Embedding) >= s.cfg.DuplicateScore {
+			return true
+		}
+	}
+	return false
+}
+
+// score rates how relevant each memory is to the text, and returns the minimum relevant score.
+// Memories without an embedding of the current model are embedded and saved first.
+func (s *Service) score(ctx context.Context, text string, memories []*models.AIMemory) ([]float64, float64) {
+	scores := make([]float64, len(memories))
+
+	query, err := s.embed(ctx, []string{text})
+	if err != nil {
+		s.logger.WithError(err).Debug("Recalling memories by word overlap")
+		words := significantWords(text)
+		for i, memory := range memories {
+			scores[i] = wordOverlap(words, significantWords(memory.Content))
+		}
+		return scores, lexicalMinScore
+	}
+
+	model := embeddingModel(query)
+	s.reembed(ctx, memories, model)
+	for i, memory := range memories {
+		if memory.EmbeddingModel == model {
+			scores[i] = cosineSimilarity(query.Vectors[0], memory.Embedding)
+		}
+	}
+	return scores, s.cfg.MinScore
+}
+
+// reembed embeds the memories whose embedding was not made with the model, such as notes
+// stored while no provider supported embeddings or after the embedding model changed
+func (s *Service) reembed(ctx context.Context, memories []*models.AIMemory, model string) {
+	var stale []*models.AIMemory
+	var texts []string
+	for _, memory := range memories {
+		if memory.EmbeddingModel != model || len(memory.Embedding) == 0 {
+			stale = append(stale, memory)
+			texts = append(texts, memory.Content)
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	embeddings, err := s.embed(ctx, texts)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to embed memories")
+		return
+	}
+	if embeddingModel(embeddings) != model {
+		// Another provider answered; the memories keep their embeddings until the next recall
+		return
+	}
+	for i, memory := range stale {
+		memory.Embedding = embeddings.Vectors[i]
+		memory.EmbeddingModel = model
+		if err := s.repo.UpdateMemoryEmbedding(ctx, memory.ID, memory.Embedding, model); err != nil {
+			s.logger.WithError(err).WithField("memory_id", memory.ID).Warn("Failed to save memory embedding")
+		}
+	}
+}
+
+func (s *Service) embed(ctx context.Context, texts []string) (*ai.EmbeddingResponse, error) {
+	response, err := s.llm.Embed(ctx, texts, ai.EmbeddingOptions{
+		Provider: s.cfg.EmbeddingProvider,
+		Model:    s.cfg.EmbeddingModel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Vectors))
+	}
+	return response, nil
+}
+
+// embeddingModel identifies the embeddings of a response; only vectors of the same model compare
+func embeddingModel(response *ai.EmbeddingResponse) string {
+	return response.Provider + "/" + response.Model
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// significantWords returns the distinct lowercase words longer than three letters
+func significantWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) > 3 {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// wordOverlap is the cosine similarity of two word sets
+func wordOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / math.Sqrt(float64(len(a)*len(b)))
+}
+
+// cleanFact trims a fact to a single bounded line
+func cleanFact(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxMemoryLength {
+		text = string(runes[:maxMemoryLength])
+	}
+	return text
+}
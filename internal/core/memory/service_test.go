@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hash/fnv"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// fakeLLM embeds text as hashed word counts and extracts a fixed reply
+type fakeLLM struct {
+	facts    string
+	model    string
+	embedErr error
+	prompts  []string
+}
+
+func (f *fakeLLM) Chat(ctx context.Context, messages []ai.ChatMessage, opts ai.ChatOptions) (*ai.ChatResponse, error) {
+	f.prompts = append(f.prompts, messages[len(messages)-1].Content)
+	return &ai.ChatResponse{Message: ai.ChatMessage{Role: "assistant", Content: f.facts}}, nil
+}
+
+func (f *fakeLLM) Embed(ctx context.Context, texts []string, opts ai.EmbeddingOptions) (*ai.EmbeddingResponse, error) {
+	if f.embedErr != nil {
+		return nil, f.embedErr
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, 1024)
+		for _, word := range strings.Fields(strings.ToLower(strings.Trim(text, "?."))) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			vector[h.Sum32()%1024]++
+		}
+		vectors[i] = vector
+	}
+	return &ai.EmbeddingResponse{Vectors: vectors, Provider: "fake", Model: f.model}, nil
+}
+
+func newTestService(t *testing.T, llm *fakeLLM) *Service {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migration, err := os.ReadFile("../../../migrations/033_ai_memories.up.sql")
+	require.NoError(t, err)
+	_, err = db.Exec(string(migration))
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	return NewService(sqlite.NewAIMemoryRepository(db), llm, config.AIMemoryConfig{
+		Enabled:      true,
+		ExtractFacts: true,
+		MinScore:     0.4,
+	}, logger)
+}
+
+func TestService_LearnAndRecall(t *testing.T) {
+	ctx := context.Background()
+	llm := &fakeLLM{
+		model: "words",
+		facts: "Sure:\n```json\n[{\"fact\": \"The guest room is Maria's office\", \"personal\": false}, " +
+			"{\"fact\": \"Alex likes the living room at 20 degrees\", \"personal\": true}, \"  \"]\n```",
+	}
+	service := newTestService(t, llm)
+
+	exchange := []ai.ChatMessage{
+		{Role: "user", Content: "Maria works from the guest room, it's her office now"},
+		{Role: "assistant", Content: "Got it."},
+	}
+	service.Learn(ctx, "alex", "conv-1", exchange)
+	require.Len(t, llm.prompts, 1)
+	assert.Contains(t, llm.prompts[0], "User: Maria works from the guest room")
+
+	memories, total, err := service.List(ctx, models.AIMemoryFilter{UserID: "alex"})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	assert.Equal(t, "Alex likes the living room at 20 degrees", memories[0].Content)
+	assert.Equal(t, "alex", memories[0].UserID)
+	assert.Equal(t, "", memories[1].UserID, "household facts are shared")
+	assert.Equal(t, models.AIMemorySourceConversation, memories[1].Source)
+	assert.Equal(t, "conv-1", memories[1].ConversationID)
+	assert.Equal(t, "fake/words", memories[1].EmbeddingModel)
+
+	// Learning the same facts again stores nothing
+	service.Learn(ctx, "alex", "conv-2", exchange)
+	_, total, err = service.List(ctx, models.AIMemoryFilter{UserID: "alex"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	// Another user sees the household fact only
+	recalled, err := service.Recall(ctx, "sam", "who uses the guest room?")
+	require.NoError(t, err)
+	require.Len(t, recalled, 1)
+	assert.Equal(t, "The guest room is Maria's office", recalled[0].Content)
+	assert.Equal(t, "conv-1", recalled[0].ConversationID)
+	assert.Contains(t, ai.MemoryPrompt(recalled), ai.MemoryCitation(recalled[0].ID)+" The guest room is Maria's office")
+
+	_, total, err = service.List(ctx, models.AIMemoryFilter{UserID: "sam"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.ErrorIs(t, service.Delete(ctx, "sam", memories[0].ID), ErrMemoryNotFound)
+
+	// Notes are embedded with the current model; a model change re-embeds stored memories
+	note, err := service.AddNote(ctx, "sam", "The dog sleeps in the laundry room", true)
+	require.NoError(t, err)
+	assert.Equal(t, "fake/words", note.EmbeddingModel)
+
+	llm.model = "words-v2"
+	recalled, err = service.Recall(ctx, "sam", "where does the dog sleep")
+	require.NoError(t, err)
+	require.Len(t, recalled, 1)
+	assert.Equal(t, note.ID, recalled[0].ID)
+
+	stored, _, err := service.List(ctx, models.AIMemoryFilter{UserID: "sam"})
+	require.NoError(t, err)
+	for _, memory := range stored {
+		assert.Equal(t, "fake/words-v2", memory.EmbeddingModel)
+	}
+	assert.Equal(t, 1, stored[0].RecallCount)
+
+	require.NoError(t, service.Delete(ctx, "sam", note.ID))
+	_, total, err = service.List(ctx, models.AIMemoryFilter{UserID: "sam"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestService_RecallWithoutEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	llm := &fakeLLM{embedErr: errors.New("no provider supports embeddings")}
+	service := newTestService(t, llm)
+
+	_, err := service.AddNote(ctx, "alex", "The garage door opener is called Big Red", false)
+	require.NoError(t, err)
+	_, err = service.AddNote(ctx, "alex", "Dinner is usually at seven", false)
+	require.NoError(t, err)
+
+	recalled, err := service.Recall(ctx, "alex", "open the garage door")
+	require.NoError(t, err)
+	require.Len(t, recalled, 1)
+	assert.Equal(t, "The garage door opener is called Big Red", recalled[0].Content)
+
+	_, err = service.AddNote(ctx, "alex", "   ", false)
+	assert.ErrorIs(t, err, ErrEmptyMemory)
+}
@@ -516,6 +516,67 @@ func (ae *AlertingEngine) ResolveAlert(alertID, userID string) error {
 	return nil
 }
 
+// RaiseAlert fires an alert raised by a service rather than by a rule
+func (ae *AlertingEngine) RaiseAlert(alertID, name string, severity AlertSeverity, labels, annotations map[string]string) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	if existing, exists := ae.activeAlerts[alertID]; exists && existing.State == StateFiring {
+		return
+	}
+
+	now := time.Now()
+	alert := &ActiveAlert{
+		ID:          alertID,
+		RuleName:    name,
+		Severity:    severity,
+		State:       StateFiring,
+		Labels:      make(map[string]string),
+		Annotations: make(map[string]string),
+		StartsAt:    now,
+		Fingerprint: alertID,
+		History: []*AlertEvent{{
+			Timestamp:   now,
+			Type:        EventFiring,
+			Description: "Alert raised by " + name,
+		}},
+	}
+	for k, v := range labels {
+		alert.Labels[k] = v
+	}
+	for k, v := range annotations {
+		alert.Annotations[k] = v
+	}
+	ae.activeAlerts[alertID] = alert
+
+	ae.logger.Warnf("Alert firing: %s (%s)", name, alertID)
+	ae.sendNotification(alert)
+}
+
+// ClearAlert resolves an alert raised with RaiseAlert
+func (ae *AlertingEngine) ClearAlert(alertID, resolvedBy string) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	alert, exists := ae.activeAlerts[alertID]
+	if !exists || alert.State == StateResolved {
+		return
+	}
+
+	now := time.Now()
+	alert.State = StateResolved
+	alert.EndsAt = &now
+	alert.History = append(alert.History, &AlertEvent{
+		Timestamp:   now,
+		Type:        EventResolved,
+		Description: fmt.Sprintf("Alert resolved by %s", resolvedBy),
+		User:        resolvedBy,
+	})
+
+	ae.logger.Infof("Alert resolved: %s", alert.RuleName)
+	ae.sendResolutionNotification(alert)
+}
+
 // evaluationLoop runs the main evaluation loop
 func (ae *AlertingEngine) evaluationLoop(ctx context.Context) {
 	ticker := time.NewTicker(ae.evaluationInterval)
@@ -0,0 +1,100 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+)
+
+// Channel names used in the notification preferences of a user
+const (
+	ChannelWebSocket = "websocket"
+	ChannelEmail     = "email"
+	ChannelNtfy      = "ntfy"
+	ChannelGotify    = "gotify"
+	ChannelWebhook   = "webhook"
+	ChannelWebPush   = "push"
+)
+
+// Recipient is the user a notification is delivered to, together with the channel settings
+// from the "config" object of the matching channel in their notification preferences
+type Recipient struct {
+	UserID string                 `json:"user_id"`
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// String returns a channel setting of the recipient or the fallback if it is not set
+func (r Recipient) String(key, fallback string) string {
+	if value, ok := r.Config[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Message is what a channel delivers: the notification plus delivery context
+type Message struct {
+	Notification *types.AINotification `json:"notification"`
+	// Quiet is set when the notification is delivered during the recipient's quiet hours
+	Quiet bool `json:"quiet,omitempty"`
+	// ActionURLs maps action IDs to signed URLs that record a response without authentication.
+	// It is empty unless notifications.action_base_url is configured.
+	ActionURLs map[string]string `json:"action_urls,omitempty"`
+}
+
+// Channel delivers notifications through one transport
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, message *Message, recipient Recipient) error
+}
+
+// Broadcaster is the part of the WebSocket hub used by the WebSocket channel
+type Broadcaster interface {
+	BroadcastNotification(notification interface{})
+}
+
+// WebSocketChannel pushes notifications to connected clients. The payload carries the
+// recipient so clients can ignore notifications addressed to other users.
+type WebSocketChannel struct {
+	hub Broadcaster
+}
+
+// NewWebSocketChannel creates a new WebSocketChannel
+func NewWebSocketChannel(hub Broadcaster) *WebSocketChannel {
+	return &WebSocketChannel{hub: hub}
+}
+
+// Name returns the channel name
+func (c *WebSocketChannel) Name() string {
+	return ChannelWebSocket
+}
+
+// Send broadcasts the notification as a "notification" message
+func (c *WebSocketChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	if c.hub == nil {
+		return fmt.Errorf("websocket hub not available")
+	}
+
+	c.hub.BroadcastNotification(map[string]interface{}{
+		"notification": message.Notification,
+		"user_id":      recipient.UserID,
+		"quiet":        message.Quiet,
+	})
+	return nil
+}
+
+// priorityLevel maps a notification priority onto a 1 (min) to 5 (max) scale, which is
+// what ntfy uses and what the other channels scale from
+func priorityLevel(priority types.NotificationPriority) int {
+	switch types.NotificationPriority(strings.ToLower(string(priority))) {
+	case types.NotificationPriorityLow:
+		return 2
+	case types.NotificationPriorityHigh:
+		return 4
+	case types.NotificationPriorityCritical:
+		return 5
+	default:
+		return 3
+	}
+}
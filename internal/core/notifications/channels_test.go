@@ -0,0 +1,361 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage() *Message {
+	return &Message{
+		Notification: &types.AINotification{
+			ID:        "n-1",
+			Type:      types.NotificationTypeSecurityAlert,
+			Title:     "Front door opened",
+			Message:   "The front door was opened while away",
+			Priority:  types.NotificationPriorityCritical,
+			Timestamp: time.Now(),
+			Data:      map[string]interface{}{"entity_id": "ha_binary_sensor.front_door"},
+			Actions: []types.NotificationAction{
+				{ID: "lock", Label: "Lock", Type: types.ActionTypeButton},
+				{ID: "view", Label: "View camera", Type: types.ActionTypeRedirect, URL: "https://pma.local/cameras"},
+			},
+			Categories: []string{"security"},
+		},
+		ActionURLs: map[string]string{"lock": "https://pma.local/api/v1/notification-actions/token"},
+	}
+}
+
+// fakeSMTPServer is a minimal SMTP server that records the DATA of each mail
+type fakeSMTPServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	rcpts    []string
+	messages []string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestEmailChannel_SendsThroughSMTP(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	channel := NewEmailChannel(config.EmailNotificationConfig{
+		Enabled: true,
+		Host:    host,
+		Port:    portNumber,
+		From:    "pma@localhost",
+		To:      "fallback@example.com",
+	})
+
+	err := channel.Send(context.Background(), testMessage(), Recipient{
+		UserID: "1",
+		Config: map[string]interface{}{"address": "owner@example.com"},
+	})
+	require.NoError(t, err)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.messages, 1)
+	assert.Equal(t, []string{"owner@example.com"}, server.rcpts)
+	assert.Contains(t, server.messages[0], "Subject: [CRITICAL] Front door opened")
+	assert.Contains(t, server.messages[0], "The front door was opened while away")
+	assert.Contains(t, server.messages[0], "View camera: https://pma.local/cameras")
+}
+
+func TestEmailChannel_RequiresAddress(t *testing.T) {
+	channel := NewEmailChannel(config.EmailNotificationConfig{Enabled: true, Host: "127.0.0.1", Port: 1})
+
+	err := channel.Send(context.Background(), testMessage(), Recipient{UserID: "1"})
+	assert.Error(t, err)
+}
+
+func TestNtfyChannel_PublishesJSON(t *testing.T) {
+	var received map[string]interface{}
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	channel := NewNtfyChannel(config.NtfyNotificationConfig{ServerURL: server.URL, Topic: "home", Token: "tk_secret"}, time.Second)
+
+	err := channel.Send(context.Background(), testMessage(), Recipient{
+		UserID: "1",
+		Config: map[string]interface{}{"topic": "alerts"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer tk_secret", authorization)
+	assert.Equal(t, "alerts", received["topic"])
+	assert.Equal(t, float64(5), received["priority"])
+
+	actions := received["actions"].([]interface{})
+	require.Len(t, actions, 2)
+	lock := actions[0].(map[string]interface{})
+	assert.Equal(t, "http", lock["action"])
+	assert.Equal(t, "https://pma.local/api/v1/notification-actions/token", lock["url"])
+	assert.Equal(t, "view", actions[1].(map[string]interface{})["action"])
+}
+
+func TestGotifyChannel_PostsMessage(t *testing.T) {
+	var received map[string]interface{}
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		key = r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	channel := NewGotifyChannel(config.GotifyNotificationConfig{ServerURL: server.URL, Token: "app-token"}, time.Second)
+
+	require.NoError(t, channel.Send(context.Background(), testMessage(), Recipient{UserID: "1"}))
+	assert.Equal(t, "/message", path)
+	assert.Equal(t, "app-token", key)
+	assert.Equal(t, float64(10), received["priority"])
+	assert.Equal(t, "Front door opened", received["title"])
+}
+
+func TestGotifyChannel_ReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	channel := NewGotifyChannel(config.GotifyNotificationConfig{ServerURL: server.URL, Token: "bad"}, time.Second)
+
+	err := channel.Send(context.Background(), testMessage(), Recipient{UserID: "1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestWebhookChannel_SignsPayload(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-PMA-Signature")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	channel := NewWebhookChannel(config.WebhookNotificationConfig{URL: server.URL, Secret: "s3cret"}, time.Second)
+
+	require.NoError(t, channel.Send(context.Background(), testMessage(), Recipient{UserID: "7"}))
+	assert.Equal(t, Sign("s3cret", body), signature)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "notification", payload["event"])
+	assert.Equal(t, "7", payload["user_id"])
+}
+
+type memorySubscriptions struct {
+	mu            sync.Mutex
+	subscriptions []*models.PushSubscription
+}
+
+func (m *memorySubscriptions) GetPushSubscriptions(ctx context.Context, userID string) ([]*models.PushSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*models.PushSubscription(nil), m.subscriptions...), nil
+}
+
+func (m *memorySubscriptions) DeletePushSubscription(ctx context.Context, endpoint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, subscription := range m.subscriptions {
+		if subscription.Endpoint == endpoint {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// decryptWebPush reverses encryptWebPush from the receiver's side
+func decryptWebPush(t *testing.T, body []byte, receiver *ecdh.PrivateKey, auth []byte) []byte {
+	salt := body[:16]
+	recordSize := binary.BigEndian.Uint32(body[16:20])
+	keyLength := int(body[20])
+	senderPublic := body[21 : 21+keyLength]
+	ciphertext := body[21+keyLength:]
+	require.Equal(t, uint32(webPushRecordSize), recordSize)
+
+	senderKey, err := ecdh.P256().NewPublicKey(senderPublic)
+	require.NoError(t, err)
+	shared, err := receiver.ECDH(senderKey)
+	require.NoError(t, err)
+
+	keyInfo := append([]byte("WebPush: info\x00"), receiver.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, senderPublic...)
+	ikm := hkdfSHA256(auth, shared, keyInfo, 32)
+	cek := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestWebPushChannel_EncryptsAndAuthenticates(t *testing.T) {
+	receiver, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	var body []byte
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	store := &memorySubscriptions{subscriptions: []*models.PushSubscription{{
+		UserID:   "1",
+		Endpoint: server.URL + "/push/abc",
+		P256dh:   base64.RawURLEncoding.EncodeToString(receiver.PublicKey().Bytes()),
+		Auth:     base64.URLEncoding.EncodeToString(auth), // padded, as some browsers send it
+	}}}
+
+	channel, err := NewWebPushChannel(config.WebPushNotificationConfig{
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		Subject:         "mailto:admin@example.com",
+	}, store, time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, channel.Send(context.Background(), testMessage(), Recipient{UserID: "1"}))
+
+	assert.Equal(t, "aes128gcm", headers.Get("Content-Encoding"))
+	assert.Equal(t, "high", headers.Get("Urgency"))
+	assert.True(t, strings.HasPrefix(headers.Get("Authorization"), "vapid t="))
+	assert.Contains(t, headers.Get("Authorization"), "k="+publicKey)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(decryptWebPush(t, body, receiver, auth), &payload))
+	assert.Equal(t, "Front door opened", payload["title"])
+	assert.Equal(t, "n-1", payload["id"])
+}
+
+func TestWebPushChannel_RemovesExpiredSubscriptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	receiver, _ := ecdh.P256().GenerateKey(rand.Reader)
+	publicKey, privateKey, _ := GenerateVAPIDKeys()
+	store := &memorySubscriptions{subscriptions: []*models.PushSubscription{{
+		UserID:   "1",
+		Endpoint: server.URL + "/push/gone",
+		P256dh:   base64.RawURLEncoding.EncodeToString(receiver.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(make([]byte, 16)),
+	}}}
+
+	channel, err := NewWebPushChannel(config.WebPushNotificationConfig{VAPIDPublicKey: publicKey, VAPIDPrivateKey: privateKey}, store, time.Second)
+	require.NoError(t, err)
+
+	assert.Error(t, channel.Send(context.Background(), testMessage(), Recipient{UserID: "1"}))
+	assert.Empty(t, store.subscriptions)
+}
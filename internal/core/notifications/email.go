@@ -0,0 +1,127 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+)
+
+// EmailChannel sends notifications as plain text mail over SMTP
+type EmailChannel struct {
+	cfg config.EmailNotificationConfig
+}
+
+// NewEmailChannel creates a new EmailChannel
+func NewEmailChannel(cfg config.EmailNotificationConfig) *EmailChannel {
+	return &EmailChannel{cfg: cfg}
+}
+
+// Name returns the channel name
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send mails the notification to the recipient's "address" (or the configured fallback)
+func (c *EmailChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	to := recipient.String("address", c.cfg.To)
+	if to == "" {
+		return fmt.Errorf("no email address configured for user %s", recipient.UserID)
+	}
+	if c.cfg.Host == "" {
+		return fmt.Errorf("smtp host not configured")
+	}
+
+	port := c.cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if c.cfg.Username != "" {
+		auth = smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+	}
+
+	body := c.buildMessage(message, to)
+
+	// net/smtp has no context support, so honor cancellation by abandoning the send
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, c.cfg.From, strings.Split(to, ","), body)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMessage renders the RFC 5322 message
+func (c *EmailChannel) buildMessage(message *Message, to string) []byte {
+	n := message.Notification
+
+	subject := n.Title
+	if subject == "" {
+		subject = "PMA notification"
+	}
+	if level := priorityLevel(n.Priority); level >= 4 {
+		subject = fmt.Sprintf("[%s] %s", strings.ToUpper(string(n.Priority)), subject)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", c.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@pma>\r\n", n.ID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	if level := priorityLevel(n.Priority); level >= 4 {
+		buf.WriteString("X-Priority: 1\r\n")
+	}
+	buf.WriteString("\r\n")
+
+	buf.WriteString(n.Message)
+	buf.WriteString("\r\n")
+
+	if len(n.Actions) > 0 {
+		buf.WriteString("\r\nActions:\r\n")
+		for _, action := range n.Actions {
+			if action.URL != "" {
+				fmt.Fprintf(&buf, "  %s: %s\r\n", action.Label, action.URL)
+			} else {
+				fmt.Fprintf(&buf, "  %s (respond in the PMA app)\r\n", action.Label)
+			}
+		}
+	}
+
+	if len(n.Data) > 0 {
+		keys := make([]string, 0, len(n.Data))
+		for key := range n.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteString("\r\nDetails:\r\n")
+		for _, key := range keys {
+			fmt.Fprintf(&buf, "  %s: %v\r\n", key, n.Data[key])
+		}
+	}
+
+	fmt.Fprintf(&buf, "\r\n--\r\nPriority: %s, sent %s\r\n", n.Priority, n.Timestamp.Format(time.RFC1123))
+	return buf.Bytes()
+}
@@ -0,0 +1,230 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+)
+
+// postJSON posts body as JSON and treats any non-2xx status as an error
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	return nil
+}
+
+// NtfyChannel publishes notifications to an ntfy server
+type NtfyChannel struct {
+	cfg    config.NtfyNotificationConfig
+	client *http.Client
+}
+
+// NewNtfyChannel creates a new NtfyChannel
+func NewNtfyChannel(cfg config.NtfyNotificationConfig, timeout time.Duration) *NtfyChannel {
+	return &NtfyChannel{cfg: cfg, client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns the channel name
+func (c *NtfyChannel) Name() string {
+	return ChannelNtfy
+}
+
+// Send publishes to the recipient's "topic" (and optional "server_url") or the configured defaults
+func (c *NtfyChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	server := strings.TrimRight(recipient.String("server_url", c.cfg.ServerURL), "/")
+	topic := recipient.String("topic", c.cfg.Topic)
+	if server == "" || topic == "" {
+		return fmt.Errorf("no ntfy topic configured for user %s", recipient.UserID)
+	}
+
+	n := message.Notification
+	payload := map[string]interface{}{
+		"topic":    topic,
+		"title":    n.Title,
+		"message":  n.Message,
+		"priority": priorityLevel(n.Priority),
+	}
+	if len(n.Categories) > 0 {
+		payload["tags"] = n.Categories
+	}
+
+	// ntfy renders up to three action buttons
+	var actions []map[string]interface{}
+	for _, action := range n.Actions {
+		if len(actions) == 3 {
+			break
+		}
+		switch {
+		case message.ActionURLs[action.ID] != "":
+			actions = append(actions, map[string]interface{}{
+				"action": "http",
+				"label":  action.Label,
+				"url":    message.ActionURLs[action.ID],
+				"method": http.MethodPost,
+				"clear":  true,
+			})
+		case action.URL != "":
+			actions = append(actions, map[string]interface{}{
+				"action": "view",
+				"label":  action.Label,
+				"url":    action.URL,
+			})
+		}
+	}
+	if len(actions) > 0 {
+		payload["actions"] = actions
+	}
+
+	headers := map[string]string{}
+	if token := recipient.String("token", c.cfg.Token); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	return postJSON(ctx, c.client, server, payload, headers)
+}
+
+// GotifyChannel publishes notifications to a Gotify server
+type GotifyChannel struct {
+	cfg    config.GotifyNotificationConfig
+	client *http.Client
+}
+
+// NewGotifyChannel creates a new GotifyChannel
+func NewGotifyChannel(cfg config.GotifyNotificationConfig, timeout time.Duration) *GotifyChannel {
+	return &GotifyChannel{cfg: cfg, client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns the channel name
+func (c *GotifyChannel) Name() string {
+	return ChannelGotify
+}
+
+// Send posts a message with the recipient's application "token" or the configured one
+func (c *GotifyChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	server := strings.TrimRight(recipient.String("server_url", c.cfg.ServerURL), "/")
+	token := recipient.String("token", c.cfg.Token)
+	if server == "" || token == "" {
+		return fmt.Errorf("no gotify server or token configured for user %s", recipient.UserID)
+	}
+
+	n := message.Notification
+	payload := map[string]interface{}{
+		"title":    n.Title,
+		"message":  n.Message,
+		"priority": gotifyPriority(n.Priority),
+	}
+	for _, action := range n.Actions {
+		if action.URL != "" {
+			payload["extras"] = map[string]interface{}{
+				"client::notification": map[string]interface{}{
+					"click": map[string]string{"url": action.URL},
+				},
+			}
+			break
+		}
+	}
+
+	return postJSON(ctx, c.client, server+"/message", payload, map[string]string{"X-Gotify-Key": token})
+}
+
+// gotifyPriority maps onto Gotify's 0-10 scale, where 8 and above are shown as high priority
+func gotifyPriority(priority types.NotificationPriority) int {
+	switch priorityLevel(priority) {
+	case 2:
+		return 2
+	case 4:
+		return 8
+	case 5:
+		return 10
+	default:
+		return 5
+	}
+}
+
+// WebhookChannel posts notifications as JSON to an arbitrary URL
+type WebhookChannel struct {
+	cfg    config.WebhookNotificationConfig
+	client *http.Client
+}
+
+// NewWebhookChannel creates a new WebhookChannel
+func NewWebhookChannel(cfg config.WebhookNotificationConfig, timeout time.Duration) *WebhookChannel {
+	return &WebhookChannel{cfg: cfg, client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns the channel name
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Send posts to the recipient's "url" or the configured one. When a secret is set the body is
+// signed with HMAC-SHA256 in the X-PMA-Signature header ("sha256=<hex>").
+func (c *WebhookChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	url := recipient.String("url", c.cfg.URL)
+	if url == "" {
+		return fmt.Errorf("no webhook url configured for user %s", recipient.UserID)
+	}
+
+	payload := map[string]interface{}{
+		"event":        "notification",
+		"user_id":      recipient.UserID,
+		"quiet":        message.Quiet,
+		"notification": message.Notification,
+	}
+	if len(message.ActionURLs) > 0 {
+		payload["action_urls"] = message.ActionURLs
+	}
+
+	headers := map[string]string{}
+	if secret := recipient.String("secret", c.cfg.Secret); secret != "" {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+		headers["X-PMA-Signature"] = Sign(secret, data)
+		return postJSON(ctx, c.client, url, json.RawMessage(data), headers)
+	}
+
+	return postJSON(ctx, c.client, url, payload, headers)
+}
+
+// Sign returns the X-PMA-Signature header value for a webhook body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,149 @@
+package notifications
+
+import (
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/preferences"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+)
+
+// target is a channel selected for one recipient
+type target struct {
+	channel string
+	config  map[string]interface{}
+}
+
+// effectivePriority applies the user's per-type priority override
+func effectivePriority(prefs *preferences.NotificationPreferences, n *types.AINotification) types.NotificationPriority {
+	if prefs != nil {
+		if priority, ok := prefs.Priorities[string(n.Type)]; ok && priority != "" {
+			return types.NotificationPriority(priority)
+		}
+	}
+	if n.Priority == "" {
+		return types.NotificationPriorityMedium
+	}
+	return n.Priority
+}
+
+// inQuietHours reports whether t falls into the quiet hours window. Windows may span midnight;
+// a window whose start equals its end is treated as empty.
+func inQuietHours(q preferences.QuietHoursConfig, t time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+
+	start, okStart := parseClock(q.StartTime)
+	end, okEnd := parseClock(q.EndTime)
+	if !okStart || !okEnd || start == end {
+		return false
+	}
+
+	if q.Timezone != "" {
+		if loc, err := time.LoadLocation(q.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	now := t.Hour()*60 + t.Minute()
+
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseClock parses "HH:MM" into minutes since midnight
+func parseClock(value string) (int, bool) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+// overridesQuietHours reports whether a notification is delivered despite quiet hours. Critical
+// notifications always are; otherwise an override entry has to name the priority, the type, a
+// category, or a type prefix ("security" matches "security_alert").
+func overridesQuietHours(q preferences.QuietHoursConfig, n *types.AINotification, priority types.NotificationPriority) bool {
+	if priority == types.NotificationPriorityCritical {
+		return true
+	}
+
+	for _, override := range q.Override {
+		switch {
+		case override == string(priority),
+			override == string(n.Type),
+			strings.HasPrefix(string(n.Type), override+"_"):
+			return true
+		}
+		for _, category := range n.Categories {
+			if override == category {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// selectTargets picks the channels a notification is delivered through for one user. WebSocket
+// delivery is always included. A subscription for the notification type can disable external
+// delivery or limit it to its channels; restrict, when set, limits it further.
+func selectTargets(prefs *preferences.NotificationPreferences, n *types.AINotification, available map[string]Channel, restrict []string) []target {
+	targets := []target{{channel: ChannelWebSocket}}
+	if prefs == nil || !prefs.Enabled {
+		return targets
+	}
+
+	var allowed map[string]bool
+	for _, subscription := range prefs.Subscriptions {
+		if subscription.Type != string(n.Type) && subscription.Type != "*" {
+			continue
+		}
+		if !subscription.Enabled {
+			return targets
+		}
+		if len(subscription.Channels) > 0 {
+			allowed = toSet(subscription.Channels)
+		}
+		break
+	}
+	if len(restrict) > 0 {
+		restricted := toSet(restrict)
+		if allowed == nil {
+			allowed = restricted
+		} else {
+			for channel := range allowed {
+				if !restricted[channel] {
+					delete(allowed, channel)
+				}
+			}
+		}
+	}
+
+	seen := map[string]bool{ChannelWebSocket: true}
+	for _, channel := range prefs.Channels {
+		if !channel.Enabled || seen[channel.Type] {
+			continue
+		}
+		if _, ok := available[channel.Type]; !ok {
+			continue
+		}
+		if allowed != nil && !allowed[channel.Type] {
+			continue
+		}
+		seen[channel.Type] = true
+		targets = append(targets, target{channel: channel.Type, config: channel.Config})
+	}
+
+	return targets
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
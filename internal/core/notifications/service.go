@@ -0,0 +1,550 @@
+package notifications
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/preferences"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Delivery status values recorded per channel
+const (
+	DeliverySent       = "sent"
+	DeliveryFailed     = "failed"
+	DeliverySuppressed = "suppressed"
+)
+
+var (
+	// ErrUnknownAction is returned when a response names an action the notification does not offer
+	ErrUnknownAction = errors.New("notification has no such action")
+	// ErrAlreadyResponded is returned when an actionable notification was already answered
+	ErrAlreadyResponded = errors.New("notification was already responded to")
+	// ErrInvalidActionToken is returned for malformed or forged action link tokens
+	ErrInvalidActionToken = errors.New("invalid action token")
+)
+
+// Delivery is the outcome of sending a notification through one channel
+type Delivery struct {
+	Channel string    `json:"channel"`
+	Status  string    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// PreferencesSource provides the notification preferences of users
+type PreferencesSource interface {
+	GetUserPreferences(userID string) (*preferences.UserPreferences, error)
+}
+
+// UserSource lists the users that notifications without a recipient are sent to
+type UserSource interface {
+	GetAll(ctx context.Context) ([]*models.User, error)
+}
+
+// ResponseHandler is called after a user responded to an actionable notification
+type ResponseHandler func(ctx context.Context, notification *models.Notification, response *types.NotificationResponse)
+
+// Service routes notifications to channels according to user preferences and keeps their state
+type Service struct {
+	cfg     config.NotificationsConfig
+	repo    repositories.NotificationRepository
+	users   UserSource
+	prefs   PreferencesSource
+	logger  *logrus.Logger
+	timeout time.Duration
+
+	mu               sync.RWMutex
+	channels         map[string]Channel
+	responseHandlers []ResponseHandler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates the notification service with the channels enabled in the configuration
+func NewService(cfg config.NotificationsConfig, repo repositories.NotificationRepository, users UserSource, prefs PreferencesSource, hub Broadcaster, logger *logrus.Logger) *Service {
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	s := &Service{
+		cfg:      cfg,
+		repo:     repo,
+		users:    users,
+		prefs:    prefs,
+		logger:   logger,
+		timeout:  timeout,
+		channels: make(map[string]Channel),
+	}
+
+	s.RegisterChannel(NewWebSocketChannel(hub))
+	if cfg.Email.Enabled {
+		s.RegisterChannel(NewEmailChannel(cfg.Email))
+	}
+	if cfg.Ntfy.Enabled {
+		s.RegisterChannel(NewNtfyChannel(cfg.Ntfy, timeout))
+	}
+	if cfg.Gotify.Enabled {
+		s.RegisterChannel(NewGotifyChannel(cfg.Gotify, timeout))
+	}
+	if cfg.Webhook.Enabled {
+		s.RegisterChannel(NewWebhookChannel(cfg.Webhook, timeout))
+	}
+	if cfg.WebPush.Enabled {
+		channel, err := NewWebPushChannel(cfg.WebPush, repo, timeout)
+		if err != nil {
+			logger.WithError(err).Warn("Web Push notifications disabled")
+		} else {
+			s.RegisterChannel(channel)
+		}
+	}
+
+	return s
+}
+
+// RegisterChannel adds or replaces a delivery channel
+func (s *Service) RegisterChannel(channel Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel.Name()] = channel
+}
+
+// Channels returns the names of the registered channels
+func (s *Service) Channels() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.channels))
+	for name := range s.channels {
+		names = append(names, name)
+	}
+	return names
+}
+
+// OnResponse registers a handler for responses to actionable notifications
+func (s *Service) OnResponse(handler ResponseHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responseHandlers = append(s.responseHandlers, handler)
+}
+
+// Start starts the retention loop
+func (s *Service) Start(ctx context.Context) {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	if s.cfg.RetentionDays > 0 {
+		s.wg.Add(1)
+		go s.retentionLoop()
+	}
+}
+
+// Stop stops the retention loop
+func (s *Service) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// SendNotification delivers a notification according to the recipients' preferences
+func (s *Service) SendNotification(ctx context.Context, notification *types.AINotification) error {
+	_, err := s.Send(ctx, notification, nil)
+	return err
+}
+
+// Notify implements automation.Notifier
+func (s *Service) Notify(ctx context.Context, notification *types.AINotification, channels []string) error {
+	_, err := s.Send(ctx, notification, channels)
+	return err
+}
+
+// Send stores and delivers a notification to its user, or to every user when UserID is empty.
+// Channels, when set, limits external delivery to the named channels. It returns the stored
+// notifications, one per recipient.
+func (s *Service) Send(ctx context.Context, notification *types.AINotification, channels []string) ([]*models.Notification, error) {
+	if notification.Title == "" && notification.Message == "" {
+		return nil, fmt.Errorf("notification needs a title or message")
+	}
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
+	if notification.Type == "" {
+		notification.Type = types.NotificationTypeSystemInfo
+	}
+
+	recipients, err := s.recipients(ctx, notification.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []*models.Notification
+	var errs []string
+	for _, userID := range recipients {
+		record, err := s.deliver(ctx, notification, userID, channels)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", userID, err))
+			continue
+		}
+		stored = append(stored, record)
+	}
+
+	if len(stored) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("failed to send notification: %s", strings.Join(errs, "; "))
+	}
+	return stored, nil
+}
+
+// recipients resolves the users a notification goes to
+func (s *Service) recipients(ctx context.Context, userID string) ([]string, error) {
+	if userID != "" {
+		return []string{userID}, nil
+	}
+	if s.users == nil {
+		return []string{"1"}, nil
+	}
+
+	users, err := s.users.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	if len(users) == 0 {
+		// Authentication disabled: preferences of the default user apply
+		return []string{"1"}, nil
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, strconv.Itoa(user.ID))
+	}
+	return ids, nil
+}
+
+// deliver stores the notification for one user and sends it through the selected channels
+func (s *Service) deliver(ctx context.Context, source *types.AINotification, userID string, restrict []string) (*models.Notification, error) {
+	var prefs *preferences.NotificationPreferences
+	if s.prefs != nil {
+		userPrefs, err := s.prefs.GetUserPreferences(userID)
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to load notification preferences, using defaults")
+			userPrefs = preferences.DefaultPreferences()
+		}
+		prefs = &userPrefs.Notifications
+	}
+
+	// Every recipient gets an own copy with its own ID so read/acknowledged state is per user
+	n := *source
+	n.ID = uuid.New().String()
+	n.UserID = userID
+	n.Priority = effectivePriority(prefs, &n)
+	n.Acknowledged, n.AcknowledgedAt, n.AcknowledgedBy = false, nil, ""
+
+	record, err := toRecord(&n)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	quiet := prefs != nil && inQuietHours(prefs.QuietHours, time.Now()) &&
+		!overridesQuietHours(prefs.QuietHours, &n, n.Priority)
+
+	message := &Message{Notification: &n, Quiet: quiet, ActionURLs: s.actionURLs(&n)}
+
+	s.mu.RLock()
+	available := make(map[string]Channel, len(s.channels))
+	for name, channel := range s.channels {
+		available[name] = channel
+	}
+	s.mu.RUnlock()
+
+	targets := selectTargets(prefs, &n, available, restrict)
+	deliveries := make([]Delivery, len(targets))
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		if quiet && t.channel != ChannelWebSocket {
+			deliveries[i] = Delivery{Channel: t.channel, Status: DeliverySuppressed, At: time.Now()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, t target) {
+			defer wg.Done()
+			deliveries[i] = s.send(ctx, available[t.channel], message, Recipient{UserID: userID, Config: t.config})
+		}(i, t)
+	}
+	wg.Wait()
+
+	encoded, _ := json.Marshal(deliveries)
+	record.Deliveries = encoded
+	if err := s.repo.UpdateDeliveries(ctx, record.ID, encoded); err != nil {
+		s.logger.WithError(err).WithField("notification_id", record.ID).Warn("Failed to store notification deliveries")
+	}
+
+	return record, nil
+}
+
+// send delivers through one channel with the configured timeout
+func (s *Service) send(ctx context.Context, channel Channel, message *Message, recipient Recipient) Delivery {
+	sendCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	delivery := Delivery{Channel: channel.Name(), Status: DeliverySent}
+	if err := channel.Send(sendCtx, message, recipient); err != nil {
+		delivery.Status = DeliveryFailed
+		delivery.Error = err.Error()
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"channel":         channel.Name(),
+			"notification_id": message.Notification.ID,
+			"user_id":         recipient.UserID,
+		}).Warn("Notification delivery failed")
+	}
+	delivery.At = time.Now()
+	return delivery
+}
+
+// Get returns a stored notification
+func (s *Service) Get(ctx context.Context, id string) (*models.Notification, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns the notifications matching the filter and the total match count
+func (s *Service) List(ctx context.Context, filter models.NotificationFilter) ([]*models.Notification, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// UnreadCount returns the number of unread notifications of a user
+func (s *Service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks a notification as read
+func (s *Service) MarkRead(ctx context.Context, id string) error {
+	return s.repo.MarkRead(ctx, id, time.Now())
+}
+
+// MarkAllRead marks all notifications of a user as read
+func (s *Service) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	return s.repo.MarkAllRead(ctx, userID, time.Now())
+}
+
+// Acknowledge marks a notification as acknowledged by a user
+func (s *Service) Acknowledge(ctx context.Context, id, userID string) error {
+	return s.repo.Acknowledge(ctx, id, userID, time.Now())
+}
+
+// Delete removes a notification
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Respond records a user's response to an action of a notification, acknowledges the
+// notification and notifies the response handlers
+func (s *Service) Respond(ctx context.Context, id, actionID, userID string, response map[string]interface{}) (*models.Notification, error) {
+	record, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.RespondedAt != nil {
+		return nil, ErrAlreadyResponded
+	}
+
+	var actions []types.NotificationAction
+	json.Unmarshal(record.Actions, &actions)
+
+	found := false
+	for _, action := range actions {
+		if action.ID == actionID && !action.Disabled {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrUnknownAction
+	}
+
+	now := time.Now()
+	result := &types.NotificationResponse{
+		NotificationID: id,
+		ActionID:       actionID,
+		UserID:         userID,
+		Response:       response,
+		Timestamp:      now,
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	if err := s.repo.SaveResponse(ctx, id, encoded, now); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Acknowledge(ctx, id, userID, now); err != nil {
+		return nil, err
+	}
+
+	record.Response = encoded
+	record.RespondedAt = &now
+	if record.AcknowledgedAt == nil {
+		record.AcknowledgedAt = &now
+		record.AcknowledgedBy = userID
+	}
+	if record.ReadAt == nil {
+		record.ReadAt = &now
+	}
+
+	s.mu.RLock()
+	handlers := append([]ResponseHandler(nil), s.responseHandlers...)
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		go func(handler ResponseHandler) {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.WithField("panic", r).Error("Notification response handler panicked")
+				}
+			}()
+			handler(context.Background(), record, result)
+		}(handler)
+	}
+
+	return record, nil
+}
+
+// RespondWithToken records a response from a signed action link
+func (s *Service) RespondWithToken(ctx context.Context, token string) (*models.Notification, error) {
+	id, actionID, userID, err := s.parseActionToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.Respond(ctx, id, actionID, userID, map[string]interface{}{"via": "link"})
+}
+
+// actionURLs builds signed response links for the actions of a notification
+func (s *Service) actionURLs(n *types.AINotification) map[string]string {
+	if s.cfg.ActionBaseURL == "" || s.cfg.ActionSecret == "" || len(n.Actions) == 0 {
+		return nil
+	}
+
+	base := strings.TrimRight(s.cfg.ActionBaseURL, "/")
+	urls := make(map[string]string, len(n.Actions))
+	for _, action := range n.Actions {
+		if action.Disabled || action.URL != "" {
+			continue
+		}
+		urls[action.ID] = fmt.Sprintf("%s/api/v1/notification-actions/%s", base, s.actionToken(n.ID, action.ID, n.UserID))
+	}
+	return urls
+}
+
+// actionToken encodes and signs the notification, action and user of a response link
+func (s *Service) actionToken(id, actionID, userID string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(id + "\n" + actionID + "\n" + userID))
+	return payload + "." + s.signToken(payload)
+}
+
+func (s *Service) parseActionToken(token string) (id, actionID, userID string, err error) {
+	if s.cfg.ActionSecret == "" {
+		return "", "", "", ErrInvalidActionToken
+	}
+
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signToken(payload))) {
+		return "", "", "", ErrInvalidActionToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", "", ErrInvalidActionToken
+	}
+	parts := strings.Split(string(decoded), "\n")
+	if len(parts) != 3 {
+		return "", "", "", ErrInvalidActionToken
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
+
+func (s *Service) signToken(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.ActionSecret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retentionLoop deletes notifications older than the retention period
+func (s *Service) retentionLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := time.Now().AddDate(0, 0, -s.cfg.RetentionDays)
+			deleted, err := s.repo.DeleteOlderThan(s.ctx, cutoff)
+			if err != nil {
+				s.logger.WithError(err).Warn("Notification cleanup failed")
+			} else if deleted > 0 {
+				s.logger.WithField("deleted", deleted).Info("Removed old notifications")
+			}
+		}
+	}
+}
+
+// toRecord converts a notification into its stored form
+func toRecord(n *types.AINotification) (*models.Notification, error) {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification data: %w", err)
+	}
+	if n.Data == nil {
+		data = []byte("{}")
+	}
+	actions, _ := json.Marshal(n.Actions)
+	if n.Actions == nil {
+		actions = []byte("[]")
+	}
+	categories, _ := json.Marshal(n.Categories)
+	if n.Categories == nil {
+		categories = []byte("[]")
+	}
+
+	return &models.Notification{
+		ID:         n.ID,
+		UserID:     n.UserID,
+		Type:       string(n.Type),
+		Title:      n.Title,
+		Message:    n.Message,
+		Priority:   string(n.Priority),
+		Source:     n.Source,
+		RoomID:     n.RoomID,
+		DeviceID:   n.DeviceID,
+		Data:       data,
+		Actions:    actions,
+		Categories: categories,
+		ExpiresAt:  n.ExpiresAt,
+		CreatedAt:  n.Timestamp,
+	}, nil
+}
@@ -0,0 +1,266 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/preferences"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// recordingChannel remembers what it was asked to deliver
+type recordingChannel struct {
+	name string
+	mu   sync.Mutex
+	sent []*Message
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, message)
+	return nil
+}
+
+func (c *recordingChannel) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sent)
+}
+
+type staticPreferences struct {
+	prefs *preferences.UserPreferences
+}
+
+func (s staticPreferences) GetUserPreferences(userID string) (*preferences.UserPreferences, error) {
+	return s.prefs, nil
+}
+
+// quietAllDay returns quiet hours that cover the current time
+func quietAllDay() preferences.QuietHoursConfig {
+	now := time.Now().UTC()
+	return preferences.QuietHoursConfig{
+		Enabled:   true,
+		StartTime: now.Add(-time.Hour).Format("15:04"),
+		EndTime:   now.Add(time.Hour).Format("15:04"),
+		Timezone:  "UTC",
+		Override:  []string{"security"},
+	}
+}
+
+func newTestService(t *testing.T, notificationPrefs preferences.NotificationPreferences) (*Service, *recordingChannel, *recordingChannel) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migration, err := os.ReadFile("../../../migrations/024_notifications.up.sql")
+	require.NoError(t, err)
+	_, err = db.Exec(string(migration))
+	require.NoError(t, err)
+
+	prefs := preferences.DefaultPreferences()
+	prefs.Notifications = notificationPrefs
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	service := NewService(config.NotificationsConfig{
+		ActionBaseURL: "https://pma.local",
+		ActionSecret:  "secret",
+	}, sqlite.NewNotificationRepository(db), nil, staticPreferences{prefs: prefs}, nil, logger)
+
+	websocket := &recordingChannel{name: ChannelWebSocket}
+	ntfy := &recordingChannel{name: ChannelNtfy}
+	service.RegisterChannel(websocket)
+	service.RegisterChannel(ntfy)
+
+	return service, websocket, ntfy
+}
+
+func deliveryStatuses(t *testing.T, record *models.Notification) map[string]string {
+	var deliveries []Delivery
+	require.NoError(t, json.Unmarshal(record.Deliveries, &deliveries))
+
+	statuses := make(map[string]string)
+	for _, delivery := range deliveries {
+		statuses[delivery.Channel] = delivery.Status
+	}
+	return statuses
+}
+
+func TestService_DeliversThroughPreferredChannels(t *testing.T) {
+	service, websocket, ntfy := newTestService(t, preferences.NotificationPreferences{
+		Enabled: true,
+		Channels: []preferences.NotificationChannel{
+			{Type: "ntfy", Enabled: true, Config: map[string]interface{}{"topic": "home"}},
+			{Type: "email", Enabled: true}, // not configured on the server
+		},
+	})
+
+	sent, err := service.Send(context.Background(), &types.AINotification{
+		Type:    types.NotificationTypeSystemInfo,
+		Title:   "Hello",
+		Message: "World",
+		UserID:  "1",
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+
+	assert.Equal(t, 1, websocket.count())
+	assert.Equal(t, 1, ntfy.count())
+	assert.Equal(t, map[string]string{"websocket": DeliverySent, "ntfy": DeliverySent}, deliveryStatuses(t, sent[0]))
+
+	stored, err := service.Get(context.Background(), sent[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(types.NotificationPriorityMedium), stored.Priority)
+	assert.Nil(t, stored.ReadAt)
+}
+
+func TestService_QuietHoursSuppressExternalChannels(t *testing.T) {
+	service, websocket, ntfy := newTestService(t, preferences.NotificationPreferences{
+		Enabled:    true,
+		Channels:   []preferences.NotificationChannel{{Type: "ntfy", Enabled: true}},
+		QuietHours: quietAllDay(),
+	})
+
+	sent, err := service.Send(context.Background(), &types.AINotification{
+		Type:   types.NotificationTypeSystemInfo,
+		Title:  "Update available",
+		UserID: "1",
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, websocket.count())
+	assert.True(t, websocket.sent[0].Quiet)
+	assert.Equal(t, 0, ntfy.count())
+	assert.Equal(t, DeliverySuppressed, deliveryStatuses(t, sent[0])["ntfy"])
+}
+
+func TestService_OverridesBypassQuietHours(t *testing.T) {
+	service, _, ntfy := newTestService(t, preferences.NotificationPreferences{
+		Enabled:    true,
+		Channels:   []preferences.NotificationChannel{{Type: "ntfy", Enabled: true}},
+		QuietHours: quietAllDay(),
+		Priorities: map[string]preferences.NotificationPriority{"device_offline": preferences.PriorityCritical},
+	})
+	ctx := context.Background()
+
+	// "security" override matches the security_alert type prefix
+	_, err := service.Send(ctx, &types.AINotification{Type: types.NotificationTypeSecurityAlert, Title: "Alarm", UserID: "1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ntfy.count())
+
+	// the user's priority override raises device_offline to critical
+	sent, err := service.Send(ctx, &types.AINotification{Type: "device_offline", Title: "Fridge offline", UserID: "1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ntfy.count())
+	assert.Equal(t, string(types.NotificationPriorityCritical), sent[0].Priority)
+}
+
+func TestService_RestrictAndSubscriptions(t *testing.T) {
+	service, _, ntfy := newTestService(t, preferences.NotificationPreferences{
+		Enabled:  true,
+		Channels: []preferences.NotificationChannel{{Type: "ntfy", Enabled: true}},
+		Subscriptions: []preferences.NotificationSubscription{
+			{Type: "automation_failed", Enabled: false},
+		},
+	})
+	ctx := context.Background()
+
+	_, err := service.Send(ctx, &types.AINotification{Type: "automation_failed", Title: "Rule failed", UserID: "1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, ntfy.count())
+
+	_, err = service.Send(ctx, &types.AINotification{Type: "system_info", Title: "Info", UserID: "1"}, []string{"email"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, ntfy.count())
+}
+
+func TestService_RespondAcknowledgesAndNotifiesHandlers(t *testing.T) {
+	service, _, ntfy := newTestService(t, preferences.NotificationPreferences{
+		Enabled:  true,
+		Channels: []preferences.NotificationChannel{{Type: "ntfy", Enabled: true}},
+	})
+	ctx := context.Background()
+
+	responses := make(chan *types.NotificationResponse, 1)
+	service.OnResponse(func(ctx context.Context, notification *models.Notification, response *types.NotificationResponse) {
+		responses <- response
+	})
+
+	sent, err := service.Send(ctx, &types.AINotification{
+		Type:    types.NotificationTypeSecurityAlert,
+		Title:   "Garage open",
+		UserID:  "1",
+		Actions: []types.NotificationAction{{ID: "close", Label: "Close", Type: types.ActionTypeButton}},
+	}, nil)
+	require.NoError(t, err)
+
+	// the external channel received a signed response link
+	actionURL := ntfy.sent[0].ActionURLs["close"]
+	require.True(t, strings.HasPrefix(actionURL, "https://pma.local/api/v1/notification-actions/"))
+
+	_, err = service.Respond(ctx, sent[0].ID, "open", "1", nil)
+	assert.ErrorIs(t, err, ErrUnknownAction)
+
+	_, err = service.RespondWithToken(ctx, "forged.token")
+	assert.ErrorIs(t, err, ErrInvalidActionToken)
+
+	token := strings.TrimPrefix(actionURL, "https://pma.local/api/v1/notification-actions/")
+	record, err := service.RespondWithToken(ctx, token)
+	require.NoError(t, err)
+	assert.NotNil(t, record.AcknowledgedAt)
+
+	select {
+	case response := <-responses:
+		assert.Equal(t, "close", response.ActionID)
+		assert.Equal(t, "1", response.UserID)
+	case <-time.After(time.Second):
+		t.Fatal("response handler was not called")
+	}
+
+	_, err = service.Respond(ctx, sent[0].ID, "close", "1", nil)
+	assert.ErrorIs(t, err, ErrAlreadyResponded)
+
+	stored, err := service.Get(ctx, sent[0].ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.ReadAt)
+	assert.NotNil(t, stored.RespondedAt)
+	assert.Equal(t, "1", stored.AcknowledgedBy)
+}
+
+func TestInQuietHours(t *testing.T) {
+	overnight := preferences.QuietHoursConfig{Enabled: true, StartTime: "22:00", EndTime: "07:00", Timezone: "UTC"}
+	at := func(clock string) time.Time {
+		parsed, _ := time.Parse("15:04", clock)
+		return time.Date(2024, 1, 1, parsed.Hour(), parsed.Minute(), 0, 0, time.UTC)
+	}
+
+	assert.True(t, inQuietHours(overnight, at("23:30")))
+	assert.True(t, inQuietHours(overnight, at("06:59")))
+	assert.False(t, inQuietHours(overnight, at("07:00")))
+	assert.False(t, inQuietHours(overnight, at("12:00")))
+
+	daytime := preferences.QuietHoursConfig{Enabled: true, StartTime: "13:00", EndTime: "15:00"}
+	assert.True(t, inQuietHours(daytime, at("14:00")))
+	assert.False(t, inQuietHours(daytime, at("15:30")))
+
+	overnight.Enabled = false
+	assert.False(t, inQuietHours(overnight, at("23:30")))
+}
@@ -0,0 +1,321 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// webPushRecordSize is the aes128gcm record size; payloads are sent as a single record
+const webPushRecordSize = 4096
+
+// maxWebPushPayload leaves room for the padding delimiter and the GCM tag within one record
+const maxWebPushPayload = webPushRecordSize - 17
+
+// PushSubscriptionStore provides the Web Push subscriptions of users
+type PushSubscriptionStore interface {
+	GetPushSubscriptions(ctx context.Context, userID string) ([]*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, endpoint string) error
+}
+
+// WebPushChannel delivers notifications to browsers through their push services, encrypting
+// payloads per RFC 8291 and authenticating with VAPID (RFC 8292)
+type WebPushChannel struct {
+	cfg           config.WebPushNotificationConfig
+	subscriptions PushSubscriptionStore
+	client        *http.Client
+	signingKey    *ecdsa.PrivateKey
+}
+
+// NewWebPushChannel creates a new WebPushChannel from base64url encoded VAPID keys
+func NewWebPushChannel(cfg config.WebPushNotificationConfig, subscriptions PushSubscriptionStore, timeout time.Duration) (*WebPushChannel, error) {
+	signingKey, err := parseVAPIDPrivateKey(cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebPushChannel{
+		cfg:           cfg,
+		subscriptions: subscriptions,
+		client:        &http.Client{Timeout: timeout},
+		signingKey:    signingKey,
+	}, nil
+}
+
+// Name returns the channel name
+func (c *WebPushChannel) Name() string {
+	return ChannelWebPush
+}
+
+// Send pushes the notification to every subscription of the recipient. Subscriptions the push
+// service reports as gone are removed. It fails only if no subscription could be reached.
+func (c *WebPushChannel) Send(ctx context.Context, message *Message, recipient Recipient) error {
+	subscriptions, err := c.subscriptions.GetPushSubscriptions(ctx, recipient.UserID)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return fmt.Errorf("no push subscriptions for user %s", recipient.UserID)
+	}
+
+	payload, err := webPushPayload(message)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	delivered := 0
+	for _, subscription := range subscriptions {
+		gone, err := c.push(ctx, subscription, payload, message)
+		if gone {
+			c.subscriptions.DeletePushSubscription(ctx, subscription.Endpoint)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		delivered++
+	}
+
+	if delivered == 0 {
+		return fmt.Errorf("web push failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// push sends one encrypted message. gone reports whether the subscription has expired.
+func (c *WebPushChannel) push(ctx context.Context, subscription *models.PushSubscription, payload []byte, message *Message) (gone bool, err error) {
+	body, err := encryptWebPush(payload, subscription.P256dh, subscription.Auth)
+	if err != nil {
+		return false, err
+	}
+
+	authorization, err := c.vapidAuthorization(subscription.Endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", "86400")
+	req.Header.Set("Urgency", webPushUrgency(priorityLevel(message.Notification.Priority), message.Quiet))
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return true, fmt.Errorf("push subscription expired")
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return false, fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+
+	return false, nil
+}
+
+// vapidAuthorization builds the "vapid" Authorization header for the endpoint's push service
+func (c *WebPushChannel) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	subject := c.cfg.Subject
+	if subject == "" {
+		subject = "mailto:admin@localhost"
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": subject,
+	})
+	signed, err := token.SignedString(c.signingKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, c.cfg.VAPIDPublicKey), nil
+}
+
+// webPushPayload is the JSON handed to the service worker. Data is dropped if the payload
+// would not fit into a single record.
+func webPushPayload(message *Message) ([]byte, error) {
+	n := message.Notification
+	payload := map[string]interface{}{
+		"id":       n.ID,
+		"type":     n.Type,
+		"title":    n.Title,
+		"body":     n.Message,
+		"priority": n.Priority,
+		"actions":  n.Actions,
+		"silent":   message.Quiet,
+		"data":     n.Data,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode push payload: %w", err)
+	}
+	if len(data) <= maxWebPushPayload {
+		return data, nil
+	}
+
+	delete(payload, "data")
+	data, err = json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode push payload: %w", err)
+	}
+	if len(data) > maxWebPushPayload {
+		return nil, fmt.Errorf("push payload too large (%d bytes)", len(data))
+	}
+	return data, nil
+}
+
+func webPushUrgency(level int, quiet bool) string {
+	switch {
+	case quiet:
+		return "very-low"
+	case level >= 5:
+		return "high"
+	case level <= 2:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// encryptWebPush encrypts payload for a subscription using the aes128gcm content coding
+func encryptWebPush(payload []byte, p256dh, authSecret string) ([]byte, error) {
+	receiverKeyBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	receiverKey, err := ecdh.P256().NewPublicKey(receiverKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	auth, err := decodeBase64URL(authSecret)
+	if err != nil || len(auth) == 0 {
+		return nil, fmt.Errorf("invalid auth secret")
+	}
+
+	senderKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := senderKey.ECDH(receiverKey)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	senderPublic := senderKey.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), receiverKeyBytes...)
+	keyInfo = append(keyInfo, senderPublic...)
+	ikm := hkdfSHA256(auth, sharedSecret, keyInfo, 32)
+
+	cek := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single (and therefore last) record is terminated by the 0x02 padding delimiter
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	header := make([]byte, 0, 16+4+1+len(senderPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(senderPublic)))
+	header = append(header, senderPublic...)
+
+	return append(header, ciphertext...), nil
+}
+
+// hkdfSHA256 implements HKDF (RFC 5869) for outputs of at most one SHA-256 block
+func hkdfSHA256(salt, ikm, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
+
+// parseVAPIDPrivateKey decodes a base64url encoded P-256 private scalar
+func parseVAPIDPrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	if _, err := ecdh.P256().NewPrivateKey(raw); err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(raw)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(raw)
+	return key, nil
+}
+
+// GenerateVAPIDKeys creates a new base64url encoded VAPID key pair
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers are inconsistent
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
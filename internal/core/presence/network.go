@@ -0,0 +1,95 @@
+package presence
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// arpIncomplete is the flag value of ARP entries that never got a reply
+const arpIncomplete = 0x0
+
+// arpTable maps both IP addresses and MAC addresses of reachable neighbours to their IP
+type arpTable map[string]string
+
+// readARPTable parses a table in the /proc/net/arp format:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.20     0x1         0x2         aa:bb:cc:dd:ee:ff     *        eth0
+func readARPTable(path string) (arpTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table := make(arpTable)
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		flags, err := strconv.ParseInt(strings.TrimPrefix(fields[2], "0x"), 16, 64)
+		if err != nil || flags == arpIncomplete {
+			continue
+		}
+
+		ip := fields[0]
+		table[ip] = ip
+		if mac, err := net.ParseMAC(fields[3]); err == nil {
+			table[mac.String()] = ip
+		}
+	}
+
+	return table, scanner.Err()
+}
+
+// lookup reports whether the identifier (a MAC or IP address) is in the table
+func (t arpTable) lookup(identifier string) bool {
+	_, ok := t[normalizeNetworkIdentifier(identifier)]
+	return ok
+}
+
+// normalizeNetworkIdentifier returns MAC addresses in lower case colon notation and IPs unchanged
+func normalizeNetworkIdentifier(identifier string) string {
+	identifier = strings.TrimSpace(identifier)
+	if mac, err := net.ParseMAC(identifier); err == nil {
+		return mac.String()
+	}
+	return identifier
+}
+
+// validNetworkIdentifier reports whether the identifier is a MAC or IP address
+func validNetworkIdentifier(identifier string) bool {
+	identifier = strings.TrimSpace(identifier)
+	if _, err := net.ParseMAC(identifier); err == nil {
+		return true
+	}
+	return net.ParseIP(identifier) != nil
+}
+
+// ping sends a single ICMP echo request using the system ping binary
+func ping(ctx context.Context, ip string, timeout time.Duration) bool {
+	if net.ParseIP(ip) == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout+time.Second)
+	defer cancel()
+
+	seconds := int(timeout.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	cmd := exec.CommandContext(ctx, "ping", "-c", "1", "-W", strconv.Itoa(seconds), ip)
+	return cmd.Run() == nil
+}
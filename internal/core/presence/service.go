@@ -0,0 +1,1049 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/ble"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// Tracker and person states besides zone IDs
+const (
+	StateHome    = "home"
+	StateNotHome = "not_home"
+	StateUnknown = "unknown"
+)
+
+// EntityPrefix is the entity ID prefix of person entities
+const EntityPrefix = "person."
+
+// priorHome is the probability of being home before any tracker is considered
+const priorHome = 0.5
+
+// defaultLikelihoods holds P(tracker reports home | home) and P(tracker reports home | away) per
+// tracker type. Phones drop off WiFi when idle and BLE is noisy, while a check-in is deliberate.
+var defaultLikelihoods = map[string][2]float64{
+	models.TrackerTypeNetwork: {0.8, 0.05},
+	models.TrackerTypeBLE:     {0.7, 0.05},
+	models.TrackerTypeEntity:  {0.9, 0.05},
+	models.TrackerTypeApp:     {0.95, 0.02},
+}
+
+var (
+	// ErrPersonNotFound is returned for unknown person IDs
+	ErrPersonNotFound = errors.New("person not found")
+	// ErrTrackerNotFound is returned for unknown tracker IDs
+	ErrTrackerNotFound = errors.New("tracker not found")
+	// ErrInvalidTracker is returned when a tracker definition cannot be used
+	ErrInvalidTracker = errors.New("invalid tracker")
+	// ErrNoAppTracker is returned when a check-in cannot be matched to an app tracker
+	ErrNoAppTracker = errors.New("person has no matching app tracker")
+)
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// TrackerStatus is the current view of one tracker
+type TrackerStatus struct {
+	*models.PresenceTracker
+	State string `json:"state"`
+}
+
+// PersonStatus is the current presence of a person
+type PersonStatus struct {
+	ID                  string           `json:"id"`
+	EntityID            string           `json:"entity_id"`
+	Name                string           `json:"name"`
+	UserID              string           `json:"user_id,omitempty"`
+	Picture             string           `json:"picture,omitempty"`
+	ConsiderHomeSeconds int              `json:"consider_home_seconds"`
+	State               string           `json:"state"`
+	Zone                string           `json:"zone,omitempty"`
+	Confidence          float64          `json:"confidence"`
+	Trackers            []*TrackerStatus `json:"trackers"`
+	UpdatedAt           time.Time        `json:"updated_at"`
+}
+
+// Change describes a person moving between home, away and zones
+type Change struct {
+	PersonID   string  `json:"person_id"`
+	EntityID   string  `json:"entity_id"`
+	OldState   string  `json:"old_state"`
+	NewState   string  `json:"new_state"`
+	Zone       string  `json:"zone,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ChangeHandler is called after the state of a person changed
+type ChangeHandler func(ctx context.Context, change *Change)
+
+// CheckIn is a location report from the mobile app. State, Zone or coordinates may be given;
+// coordinates are resolved against the configured zones.
+type CheckIn struct {
+	Device    string   `json:"device"`
+	State     string   `json:"state"`
+	Zone      string   `json:"zone"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// person is the in-memory state of a person
+type person struct {
+	*models.PresencePerson
+	state      string
+	zone       string
+	confidence float64
+	trackers   map[int]string // tracker ID -> evaluated state
+	updatedAt  time.Time
+	published  bool
+}
+
+// signals is what the environment reported during one evaluation
+type signals struct {
+	arp          arpTable
+	reachable    map[string]bool
+	bleAvailable bool
+	ble          map[string]*ble.BLEDevice
+	entities     map[string]string
+}
+
+// Service combines trackers into person entities with a home, away or zone state
+type Service struct {
+	cfg          config.PresenceConfig
+	repo         repositories.PresenceRepository
+	entities     *unified.UnifiedEntityService
+	logger       *logrus.Logger
+	interval     time.Duration
+	considerHome time.Duration
+	pingTimeout  time.Duration
+	threshold    float64
+
+	mutex    sync.RWMutex
+	persons  map[string]*person
+	zones    []*models.PresenceZone
+	handlers []ChangeHandler
+
+	evalMutex sync.Mutex
+	trigger   chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewService creates the presence service. entities may be nil, in which case no person
+// entities are published and entity trackers report nothing.
+func NewService(cfg config.PresenceConfig, repo repositories.PresenceRepository, entities *unified.UnifiedEntityService, logger *logrus.Logger) *Service {
+	s := &Service{
+		cfg:          cfg,
+		repo:         repo,
+		entities:     entities,
+		logger:       logger,
+		interval:     parseDuration(cfg.ScanInterval, 30*time.Second),
+		considerHome: parseDuration(cfg.DefaultConsiderHome, 3*time.Minute),
+		pingTimeout:  parseDuration(cfg.PingTimeout, time.Second),
+		threshold:    cfg.HomeThreshold,
+		persons:      make(map[string]*person),
+		trigger:      make(chan struct{}, 1),
+	}
+	if s.threshold <= 0 || s.threshold >= 1 {
+		s.threshold = 0.6
+	}
+	if s.cfg.ARPTablePath == "" {
+		s.cfg.ARPTablePath = "/proc/net/arp"
+	}
+	return s
+}
+
+// OnChange registers a handler for person state changes
+func (s *Service) OnChange(handler ChangeHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// Start loads persons and zones, publishes person entities and starts the scan loop
+func (s *Service) Start(ctx context.Context) error {
+	if err := s.load(ctx); err != nil {
+		return err
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	if s.entities != nil {
+		s.entities.AddStateChangeListener(s.handleEntityStateChange)
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+
+	s.logger.WithFields(logrus.Fields{
+		"persons":  len(s.persons),
+		"zones":    len(s.zones),
+		"interval": s.interval,
+	}).Info("Presence detection started")
+	return nil
+}
+
+// Stop stops the scan loop
+func (s *Service) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Service) load(ctx context.Context) error {
+	persons, err := s.repo.ListPersons(ctx)
+	if err != nil {
+		return err
+	}
+	zones, err := s.repo.ListZones(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.zones = zones
+	for _, record := range persons {
+		s.persons[record.ID] = newPerson(record)
+	}
+	return nil
+}
+
+func newPerson(record *models.PresencePerson) *person {
+	return &person{
+		PresencePerson: record,
+		state:          StateUnknown,
+		trackers:       make(map[int]string),
+		updatedAt:      record.UpdatedAt,
+	}
+}
+
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.evaluate(s.ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.evaluate(s.ctx)
+		case <-s.trigger:
+			s.evaluate(s.ctx)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// requestEvaluation schedules an evaluation on the scan loop without waiting for it
+func (s *Service) requestEvaluation() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// handleEntityStateChange re-evaluates as soon as an entity used as a tracker changes
+func (s *Service) handleEntityStateChange(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+	if strings.HasPrefix(entityID, EntityPrefix) {
+		return
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, p := range s.persons {
+		for _, tracker := range p.Trackers {
+			if tracker.Type == models.TrackerTypeEntity && tracker.Identifier == entityID {
+				s.requestEvaluation()
+				return
+			}
+		}
+	}
+}
+
+// ========================================
+// Persons, trackers and zones
+// ========================================
+
+// ListPersons returns the presence of every person
+func (s *Service) ListPersons() []*PersonStatus {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	statuses := make([]*PersonStatus, 0, len(s.persons))
+	for _, p := range s.persons {
+		statuses = append(statuses, p.status())
+	}
+	sortStatuses(statuses)
+	return statuses
+}
+
+// GetPerson returns the presence of one person
+func (s *Service) GetPerson(id string) (*PersonStatus, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p, ok := s.persons[id]
+	if !ok {
+		return nil, ErrPersonNotFound
+	}
+	return p.status(), nil
+}
+
+// GetPersonForUser returns the person linked to a PMA user
+func (s *Service) GetPersonForUser(userID string) (*PersonStatus, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, p := range s.persons {
+		if p.UserID != "" && p.UserID == userID {
+			return p.status(), nil
+		}
+	}
+	return nil, ErrPersonNotFound
+}
+
+// CreatePerson adds a person. The ID is derived from the name when it is empty.
+func (s *Service) CreatePerson(ctx context.Context, record *models.PresencePerson) (*PersonStatus, error) {
+	if strings.TrimSpace(record.Name) == "" {
+		return nil, fmt.Errorf("person name is required")
+	}
+	if record.ID == "" {
+		record.ID = slugify(record.Name)
+	} else {
+		record.ID = slugify(record.ID)
+	}
+	if record.ID == "" {
+		return nil, fmt.Errorf("person ID must contain letters or digits")
+	}
+	if record.ConsiderHomeSeconds < 0 {
+		return nil, fmt.Errorf("consider_home_seconds cannot be negative")
+	}
+
+	s.mutex.Lock()
+	if _, exists := s.persons[record.ID]; exists {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("person %s already exists", record.ID)
+	}
+	if err := s.repo.CreatePerson(ctx, record); err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	s.persons[record.ID] = newPerson(record)
+	s.mutex.Unlock()
+
+	s.evaluate(ctx)
+	return s.GetPerson(record.ID)
+}
+
+// UpdatePerson changes the name, user link, picture or timeout of a person
+func (s *Service) UpdatePerson(ctx context.Context, id string, update *models.PresencePerson) (*PersonStatus, error) {
+	if update.ConsiderHomeSeconds < 0 {
+		return nil, fmt.Errorf("consider_home_seconds cannot be negative")
+	}
+
+	s.mutex.Lock()
+	p, ok := s.persons[id]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrPersonNotFound
+	}
+
+	record := *p.PresencePerson
+	if update.Name != "" {
+		record.Name = update.Name
+	}
+	record.UserID = update.UserID
+	record.Picture = update.Picture
+	record.ConsiderHomeSeconds = update.ConsiderHomeSeconds
+
+	if err := s.repo.UpdatePerson(ctx, &record); err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	p.PresencePerson = &record
+	s.mutex.Unlock()
+
+	s.evaluate(ctx)
+	return s.GetPerson(id)
+}
+
+// DeletePerson removes a person, their trackers and their entity
+func (s *Service) DeletePerson(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.persons[id]; !ok {
+		return ErrPersonNotFound
+	}
+	if err := s.repo.DeletePerson(ctx, id); err != nil {
+		return err
+	}
+	delete(s.persons, id)
+
+	if s.entities != nil {
+		if err := s.entities.GetRegistryManager().GetEntityRegistry().UnregisterEntity(EntityPrefix + id); err != nil {
+			s.logger.WithError(err).WithField("person_id", id).Debug("Person entity was not registered")
+		}
+	}
+	return nil
+}
+
+// AddTracker attaches a tracker to a person. Likelihoods default per tracker type.
+func (s *Service) AddTracker(ctx context.Context, personID string, tracker *models.PresenceTracker) (*PersonStatus, error) {
+	if err := s.normalizeTracker(tracker); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	p, ok := s.persons[personID]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrPersonNotFound
+	}
+
+	tracker.PersonID = personID
+	if err := s.repo.CreateTracker(ctx, tracker); err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	p.Trackers = append(p.Trackers, tracker)
+	s.mutex.Unlock()
+
+	s.evaluate(ctx)
+	return s.GetPerson(personID)
+}
+
+// RemoveTracker detaches a tracker from a person
+func (s *Service) RemoveTracker(ctx context.Context, personID string, trackerID int) (*PersonStatus, error) {
+	s.mutex.Lock()
+	p, ok := s.persons[personID]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrPersonNotFound
+	}
+
+	index := -1
+	for i, tracker := range p.Trackers {
+		if tracker.ID == trackerID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.mutex.Unlock()
+		return nil, ErrTrackerNotFound
+	}
+
+	if err := s.repo.DeleteTracker(ctx, personID, trackerID); err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	p.Trackers = append(p.Trackers[:index], p.Trackers[index+1:]...)
+	delete(p.trackers, trackerID)
+	s.mutex.Unlock()
+
+	s.evaluate(ctx)
+	return s.GetPerson(personID)
+}
+
+func (s *Service) normalizeTracker(tracker *models.PresenceTracker) error {
+	tracker.Identifier = strings.TrimSpace(tracker.Identifier)
+	if tracker.Identifier == "" {
+		return fmt.Errorf("%w: identifier is required", ErrInvalidTracker)
+	}
+
+	likelihoods, ok := defaultLikelihoods[tracker.Type]
+	if !ok {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidTracker, tracker.Type)
+	}
+
+	switch tracker.Type {
+	case models.TrackerTypeNetwork:
+		if !validNetworkIdentifier(tracker.Identifier) {
+			return fmt.Errorf("%w: %q is not a MAC or IP address", ErrInvalidTracker, tracker.Identifier)
+		}
+		tracker.Identifier = normalizeNetworkIdentifier(tracker.Identifier)
+	case models.TrackerTypeBLE:
+		tracker.Identifier = strings.ToLower(tracker.Identifier)
+	case models.TrackerTypeEntity:
+		if strings.HasPrefix(tracker.Identifier, EntityPrefix) {
+			return fmt.Errorf("%w: person entities cannot be used as trackers", ErrInvalidTracker)
+		}
+	}
+
+	if tracker.ProbGivenHome == 0 {
+		tracker.ProbGivenHome = likelihoods[0]
+	}
+	if tracker.ProbGivenAway == 0 {
+		tracker.ProbGivenAway = likelihoods[1]
+	}
+	if !validProbability(tracker.ProbGivenHome) || !validProbability(tracker.ProbGivenAway) {
+		return fmt.Errorf("%w: probabilities must be between 0 and 1", ErrInvalidTracker)
+	}
+	if tracker.ConsiderHomeSeconds < 0 {
+		return fmt.Errorf("%w: consider_home_seconds cannot be negative", ErrInvalidTracker)
+	}
+
+	return nil
+}
+
+// CheckIn records a location report from the mobile app for a person
+func (s *Service) CheckIn(ctx context.Context, personID string, checkIn *CheckIn) (*PersonStatus, error) {
+	state, err := s.resolveCheckIn(checkIn)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	p, ok := s.persons[personID]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrPersonNotFound
+	}
+
+	var tracker *models.PresenceTracker
+	var candidates int
+	for _, t := range p.Trackers {
+		if t.Type != models.TrackerTypeApp {
+			continue
+		}
+		candidates++
+		if checkIn.Device == "" || t.Identifier == checkIn.Device {
+			tracker = t
+			if checkIn.Device != "" {
+				break
+			}
+		}
+	}
+	// Without a device name the check-in is only unambiguous for a single app tracker
+	if tracker == nil || (checkIn.Device == "" && candidates > 1) {
+		s.mutex.Unlock()
+		return nil, ErrNoAppTracker
+	}
+
+	now := time.Now().UTC()
+	if state == StateHome {
+		tracker.LastSeen = &now
+	}
+	tracker.LastState = state
+	tracker.LastReported = &now
+	trackerID, lastSeen := tracker.ID, tracker.LastSeen
+	s.mutex.Unlock()
+
+	if err := s.repo.UpdateTrackerObservation(ctx, trackerID, lastSeen, state, now); err != nil {
+		return nil, err
+	}
+
+	s.evaluate(ctx)
+	return s.GetPerson(personID)
+}
+
+func (s *Service) resolveCheckIn(checkIn *CheckIn) (string, error) {
+	switch {
+	case checkIn.Latitude != nil && checkIn.Longitude != nil:
+		s.mutex.RLock()
+		zone := resolveZone(s.zones, *checkIn.Latitude, *checkIn.Longitude)
+		s.mutex.RUnlock()
+		if zone == "" {
+			return StateNotHome, nil
+		}
+		return zone, nil
+	case checkIn.Zone != "":
+		if !s.hasZone(checkIn.Zone) {
+			return "", fmt.Errorf("unknown zone %q", checkIn.Zone)
+		}
+		return checkIn.Zone, nil
+	case checkIn.State == StateHome || checkIn.State == StateNotHome:
+		return checkIn.State, nil
+	}
+	return "", fmt.Errorf("check-in needs a state (home or not_home), a zone or coordinates")
+}
+
+func (s *Service) hasZone(id string) bool {
+	if id == HomeZoneID {
+		return true
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, zone := range s.zones {
+		if zone.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ListZones returns the configured zones
+func (s *Service) ListZones() []*models.PresenceZone {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	zones := make([]*models.PresenceZone, len(s.zones))
+	copy(zones, s.zones)
+	return zones
+}
+
+// SaveZone creates or replaces a zone
+func (s *Service) SaveZone(ctx context.Context, zone *models.PresenceZone) error {
+	zone.ID = slugify(zone.ID)
+	if zone.ID == "" {
+		zone.ID = slugify(zone.Name)
+	}
+	if zone.ID == "" {
+		return fmt.Errorf("zone ID or name is required")
+	}
+	if zone.Name == "" {
+		zone.Name = zone.ID
+	}
+	if zone.Latitude < -90 || zone.Latitude > 90 || zone.Longitude < -180 || zone.Longitude > 180 {
+		return fmt.Errorf("invalid zone coordinates")
+	}
+	if zone.RadiusMeters <= 0 {
+		zone.RadiusMeters = 100
+	}
+
+	if err := s.repo.SaveZone(ctx, zone); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, existing := range s.zones {
+		if existing.ID == zone.ID {
+			s.zones[i] = zone
+			return nil
+		}
+	}
+	s.zones = append(s.zones, zone)
+	return nil
+}
+
+// DeleteZone deletes a zone
+func (s *Service) DeleteZone(ctx context.Context, id string) error {
+	if err := s.repo.DeleteZone(ctx, id); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, zone := range s.zones {
+		if zone.ID == id {
+			s.zones = append(s.zones[:i], s.zones[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Refresh evaluates every person immediately
+func (s *Service) Refresh(ctx context.Context) []*PersonStatus {
+	s.evaluate(ctx)
+	return s.ListPersons()
+}
+
+// ========================================
+// Evaluation
+// ========================================
+
+// evaluate collects tracker signals, recomputes every person and publishes the results
+func (s *Service) evaluate(ctx context.Context) {
+	s.evalMutex.Lock()
+	defer s.evalMutex.Unlock()
+
+	signals := s.collectSignals(ctx)
+	now := time.Now().UTC()
+
+	type observation struct {
+		id       int
+		lastSeen *time.Time
+		state    string
+	}
+
+	var changes []*Change
+	var observations []observation
+	var published []*types.PMABaseEntity
+
+	s.mutex.Lock()
+	for _, p := range s.persons {
+		oldState := p.state
+		oldZone := p.zone
+		oldConfidence := p.confidence
+
+		for _, tracker := range p.Trackers {
+			state := s.observe(tracker, p, signals, now)
+			p.trackers[tracker.ID] = state
+
+			if tracker.Type != models.TrackerTypeApp && state != StateUnknown && state != tracker.LastState {
+				tracker.LastState = state
+				tracker.LastReported = &now
+				observations = append(observations, observation{tracker.ID, tracker.LastSeen, state})
+			}
+		}
+
+		p.confidence, p.zone = combine(p.Trackers, p.trackers)
+		p.state = s.personState(p)
+
+		changed := p.state != oldState
+		if p.published && !changed && p.zone == oldZone && math.Abs(p.confidence-oldConfidence) < 0.001 {
+			continue
+		}
+		p.updatedAt = now
+
+		// The first evaluation after startup only establishes the state
+		wasPublished := p.published
+		p.published = true
+		published = append(published, s.personEntity(p, now))
+		if changed && wasPublished {
+			changes = append(changes, &Change{
+				PersonID:   p.ID,
+				EntityID:   EntityPrefix + p.ID,
+				OldState:   oldState,
+				NewState:   p.state,
+				Zone:       p.zone,
+				Confidence: p.confidence,
+			})
+		}
+	}
+	handlers := append([]ChangeHandler(nil), s.handlers...)
+	s.mutex.Unlock()
+
+	for _, o := range observations {
+		if err := s.repo.UpdateTrackerObservation(ctx, o.id, o.lastSeen, o.state, now); err != nil {
+			s.logger.WithError(err).WithField("tracker_id", o.id).Warn("Failed to store tracker observation")
+		}
+	}
+
+	for _, entity := range published {
+		s.publish(ctx, entity)
+	}
+
+	for _, change := range changes {
+		s.logger.WithFields(logrus.Fields{
+			"person_id":  change.PersonID,
+			"old_state":  change.OldState,
+			"new_state":  change.NewState,
+			"confidence": change.Confidence,
+		}).Info("Person presence changed")
+
+		for _, handler := range handlers {
+			handler(ctx, change)
+		}
+	}
+}
+
+// collectSignals reads the ARP table, BLE devices and tracker entities, and pings IP trackers
+// that are missing from the ARP table
+func (s *Service) collectSignals(ctx context.Context) *signals {
+	sig := &signals{
+		reachable: make(map[string]bool),
+		ble:       make(map[string]*ble.BLEDevice),
+		entities:  make(map[string]string),
+	}
+
+	table, err := readARPTable(s.cfg.ARPTablePath)
+	if err != nil {
+		s.logger.WithError(err).Debug("Failed to read ARP table")
+	}
+	sig.arp = table
+
+	if s.entities != nil {
+		if adapter, err := s.entities.GetRegistryManager().GetAdapterRegistry().GetAdapterBySource(types.SourceBLE); err == nil {
+			if bleAdapter, ok := adapter.(*ble.BLEAdapter); ok {
+				sig.bleAvailable = true
+				for _, device := range bleAdapter.GetDevices() {
+					sig.ble[strings.ToLower(device.Key)] = device
+					sig.ble[strings.ToLower(device.Address)] = device
+				}
+			}
+		}
+	}
+
+	var pingTargets []string
+	s.mutex.RLock()
+	for _, p := range s.persons {
+		for _, tracker := range p.Trackers {
+			switch tracker.Type {
+			case models.TrackerTypeNetwork:
+				if s.cfg.PingEnabled && !sig.arp.lookup(tracker.Identifier) && net.ParseIP(tracker.Identifier) != nil {
+					pingTargets = append(pingTargets, tracker.Identifier)
+				}
+			case models.TrackerTypeEntity:
+				if s.entities == nil {
+					continue
+				}
+				if entity, err := s.entities.GetRegistryManager().GetEntityRegistry().GetEntity(tracker.Identifier); err == nil && entity != nil {
+					sig.entities[tracker.Identifier] = string(entity.GetState())
+				}
+			}
+		}
+	}
+	s.mutex.RUnlock()
+
+	if len(pingTargets) > 0 {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, ip := range pingTargets {
+			wg.Add(1)
+			go func(ip string) {
+				defer wg.Done()
+				if ping(ctx, ip, s.pingTimeout) {
+					mu.Lock()
+					sig.reachable[ip] = true
+					mu.Unlock()
+				}
+			}(ip)
+		}
+		wg.Wait()
+	}
+
+	return sig
+}
+
+// observe returns what a tracker currently reports: home, not_home, a zone ID or unknown
+func (s *Service) observe(tracker *models.PresenceTracker, p *person, sig *signals, now time.Time) string {
+	window := s.considerHomeFor(tracker, p)
+
+	switch tracker.Type {
+	case models.TrackerTypeNetwork:
+		if sig.arp.lookup(tracker.Identifier) || sig.reachable[tracker.Identifier] {
+			tracker.LastSeen = &now
+		}
+		return seenWithin(tracker, window, now)
+
+	case models.TrackerTypeBLE:
+		if !sig.bleAvailable {
+			return StateUnknown
+		}
+		device, ok := sig.ble[tracker.Identifier]
+		if ok && (tracker.MinRSSI == 0 || (device.RSSI != nil && *device.RSSI >= tracker.MinRSSI)) {
+			if tracker.LastSeen == nil || device.LastSeen.After(*tracker.LastSeen) {
+				seen := device.LastSeen.UTC()
+				tracker.LastSeen = &seen
+			}
+		}
+		return seenWithin(tracker, window, now)
+
+	case models.TrackerTypeEntity:
+		state, ok := sig.entities[tracker.Identifier]
+		if !ok {
+			return StateUnknown
+		}
+		switch strings.ToLower(state) {
+		case StateHome, string(types.StateOn):
+			tracker.LastSeen = &now
+			return StateHome
+		case StateNotHome, string(types.StateOff), "away":
+			return seenWithin(tracker, window, now)
+		case "", StateUnknown, string(types.StateUnavailable):
+			return StateUnknown
+		default:
+			return state
+		}
+
+	case models.TrackerTypeApp:
+		if tracker.LastState == "" {
+			return StateUnknown
+		}
+		if tracker.LastState == StateNotHome {
+			return seenWithin(tracker, window, now)
+		}
+		return tracker.LastState
+	}
+
+	return StateUnknown
+}
+
+// seenWithin reports home while the tracker was last seen within the consider-home window
+func seenWithin(tracker *models.PresenceTracker, window time.Duration, now time.Time) string {
+	if tracker.LastSeen != nil && now.Sub(*tracker.LastSeen) <= window {
+		return StateHome
+	}
+	return StateNotHome
+}
+
+func (s *Service) considerHomeFor(tracker *models.PresenceTracker, p *person) time.Duration {
+	if tracker.ConsiderHomeSeconds > 0 {
+		return time.Duration(tracker.ConsiderHomeSeconds) * time.Second
+	}
+	if p.ConsiderHomeSeconds > 0 {
+		return time.Duration(p.ConsiderHomeSeconds) * time.Second
+	}
+	return s.considerHome
+}
+
+// combine turns tracker states into the probability of being home with a Bayesian update per
+// tracker, and returns the zone most recently reported by a tracker
+func combine(trackers []*models.PresenceTracker, states map[int]string) (float64, string) {
+	odds := priorHome / (1 - priorHome)
+	var zone string
+	var zoneReported time.Time
+
+	for _, tracker := range trackers {
+		state := states[tracker.ID]
+		switch state {
+		case StateUnknown, "":
+			continue
+		case StateHome:
+			odds *= tracker.ProbGivenHome / tracker.ProbGivenAway
+		default:
+			odds *= (1 - tracker.ProbGivenHome) / (1 - tracker.ProbGivenAway)
+			if state != StateNotHome && tracker.LastReported != nil && tracker.LastReported.After(zoneReported) {
+				zone, zoneReported = state, *tracker.LastReported
+			}
+		}
+	}
+
+	return odds / (1 + odds), zone
+}
+
+// personState maps the combined probability to home, a zone or not_home
+func (s *Service) personState(p *person) string {
+	known := false
+	for _, state := range p.trackers {
+		if state != StateUnknown {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return StateUnknown
+	}
+
+	if p.confidence >= s.threshold {
+		return StateHome
+	}
+	if p.zone != "" && p.zone != HomeZoneID {
+		return p.zone
+	}
+	return StateNotHome
+}
+
+// ========================================
+// Entities
+// ========================================
+
+func (s *Service) personEntity(p *person, now time.Time) *types.PMABaseEntity {
+	trackers := make([]map[string]interface{}, 0, len(p.Trackers))
+	for _, tracker := range p.Trackers {
+		trackers = append(trackers, map[string]interface{}{
+			"id":         tracker.ID,
+			"type":       tracker.Type,
+			"identifier": tracker.Identifier,
+			"state":      p.trackers[tracker.ID],
+			"last_seen":  tracker.LastSeen,
+		})
+	}
+
+	icon := "mdi:account"
+	if p.state == StateHome {
+		icon = "mdi:home-account"
+	}
+
+	return &types.PMABaseEntity{
+		ID:           EntityPrefix + p.ID,
+		Type:         types.EntityTypePerson,
+		FriendlyName: p.Name,
+		Icon:         icon,
+		State:        types.PMAEntityState(p.state),
+		Attributes: map[string]interface{}{
+			"confidence":     math.Round(p.confidence*1000) / 1000,
+			"zone":           p.zone,
+			"user_id":        p.UserID,
+			"entity_picture": p.Picture,
+			"trackers":       trackers,
+		},
+		LastUpdated:  now,
+		Capabilities: []types.PMACapability{},
+		Metadata: &types.PMAMetadata{
+			Source:         types.SourcePMA,
+			SourceEntityID: p.ID,
+			LastSynced:     now,
+			QualityScore:   1.0,
+			IsVirtual:      true,
+		},
+		Available: true,
+	}
+}
+
+// publish registers or updates the person entity through the unified service, so the change
+// is broadcast like any other entity change
+func (s *Service) publish(ctx context.Context, entity *types.PMABaseEntity) {
+	if s.entities == nil {
+		return
+	}
+
+	if err := s.entities.PublishEntity(ctx, entity); err != nil {
+		s.logger.WithError(err).WithField("entity_id", entity.ID).Warn("Failed to publish person entity")
+	}
+}
+
+// ========================================
+// Helpers
+// ========================================
+
+func (p *person) status() *PersonStatus {
+	trackers := make([]*TrackerStatus, 0, len(p.Trackers))
+	for _, tracker := range p.Trackers {
+		copied := *tracker
+		state, ok := p.trackers[tracker.ID]
+		if !ok {
+			state = StateUnknown
+		}
+		trackers = append(trackers, &TrackerStatus{PresenceTracker: &copied, State: state})
+	}
+
+	return &PersonStatus{
+		ID:                  p.ID,
+		EntityID:            EntityPrefix + p.ID,
+		Name:                p.Name,
+		UserID:              p.UserID,
+		Picture:             p.Picture,
+		ConsiderHomeSeconds: p.ConsiderHomeSeconds,
+		State:               p.state,
+		Zone:                p.zone,
+		Confidence:          math.Round(p.confidence*1000) / 1000,
+		Trackers:            trackers,
+		UpdatedAt:           p.updatedAt,
+	}
+}
+
+func sortStatuses(statuses []*PersonStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		return strings.ToLower(statuses[i].Name) < strings.ToLower(statuses[j].Name)
+	})
+}
+
+func slugify(value string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(value), "_"), "_")
+}
+
+func validProbability(p float64) bool {
+	return p > 0 && p < 1
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
@@ -0,0 +1,252 @@
+package presence
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/ble"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService() *Service {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewService(config.PresenceConfig{DefaultConsiderHome: "3m"}, nil, nil, logger)
+}
+
+func tracker(id int, trackerType string) *models.PresenceTracker {
+	likelihoods := defaultLikelihoods[trackerType]
+	return &models.PresenceTracker{
+		ID:            id,
+		Type:          trackerType,
+		ProbGivenHome: likelihoods[0],
+		ProbGivenAway: likelihoods[1],
+	}
+}
+
+func emptySignals() *signals {
+	return &signals{
+		arp:       arpTable{},
+		reachable: map[string]bool{},
+		ble:       map[string]*ble.BLEDevice{},
+		entities:  map[string]string{},
+	}
+}
+
+func TestCombine(t *testing.T) {
+	network := tracker(1, models.TrackerTypeNetwork)
+	app := tracker(2, models.TrackerTypeApp)
+	trackers := []*models.PresenceTracker{network, app}
+
+	t.Run("no evidence keeps the prior", func(t *testing.T) {
+		probability, zone := combine(trackers, map[int]string{1: StateUnknown})
+		assert.InDelta(t, priorHome, probability, 1e-9)
+		assert.Empty(t, zone)
+	})
+
+	t.Run("home evidence", func(t *testing.T) {
+		probability, _ := combine(trackers, map[int]string{1: StateHome})
+		// Odds of 1 times 0.8/0.05
+		assert.InDelta(t, 16.0/17.0, probability, 1e-9)
+	})
+
+	t.Run("away evidence", func(t *testing.T) {
+		probability, _ := combine(trackers, map[int]string{1: StateNotHome})
+		// Odds of 1 times 0.2/0.95
+		assert.InDelta(t, 0.2/1.15, probability, 1e-9)
+	})
+
+	t.Run("conflicting evidence favours the stronger tracker", func(t *testing.T) {
+		probability, _ := combine(trackers, map[int]string{1: StateNotHome, 2: StateHome})
+		assert.Greater(t, probability, 0.6)
+	})
+
+	t.Run("most recently reported zone wins", func(t *testing.T) {
+		earlier := time.Now().Add(-time.Hour)
+		later := time.Now()
+		first := tracker(3, models.TrackerTypeApp)
+		first.LastReported = &later
+		second := tracker(4, models.TrackerTypeApp)
+		second.LastReported = &earlier
+
+		probability, zone := combine([]*models.PresenceTracker{first, second}, map[int]string{3: "work", 4: "gym"})
+		assert.Equal(t, "work", zone)
+		assert.Less(t, probability, priorHome, "a zone counts as evidence of being away")
+	})
+}
+
+func TestPersonState(t *testing.T) {
+	s := newTestService()
+
+	tests := []struct {
+		name       string
+		trackers   map[int]string
+		confidence float64
+		zone       string
+		want       string
+	}{
+		{"no trackers", map[int]string{}, 0.5, "", StateUnknown},
+		{"only unknown trackers", map[int]string{1: StateUnknown}, 0.9, "", StateUnknown},
+		{"above threshold", map[int]string{1: StateHome}, 0.6, "", StateHome},
+		{"below threshold", map[int]string{1: StateNotHome}, 0.3, "", StateNotHome},
+		{"in a zone", map[int]string{1: "work"}, 0.1, "work", "work"},
+		{"home zone below threshold", map[int]string{1: HomeZoneID}, 0.4, HomeZoneID, StateNotHome},
+		{"confidence beats zone", map[int]string{1: "work", 2: StateHome}, 0.8, "work", StateHome},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &person{PresencePerson: &models.PresencePerson{}, trackers: tt.trackers, confidence: tt.confidence, zone: tt.zone}
+			assert.Equal(t, tt.want, s.personState(p))
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	now := time.Now().UTC()
+	recently := now.Add(-time.Minute)
+	longAgo := now.Add(-time.Hour)
+
+	t.Run("network", func(t *testing.T) {
+		s := newTestService()
+		p := &person{PresencePerson: &models.PresencePerson{}}
+
+		inARP := tracker(1, models.TrackerTypeNetwork)
+		inARP.Identifier = "AA-BB-CC-DD-EE-FF"
+		sig := emptySignals()
+		sig.arp["aa:bb:cc:dd:ee:ff"] = "192.168.1.20"
+		assert.Equal(t, StateHome, s.observe(inARP, p, sig, now))
+		assert.Equal(t, now, *inARP.LastSeen)
+
+		pinged := tracker(2, models.TrackerTypeNetwork)
+		pinged.Identifier = "192.168.1.30"
+		sig.reachable["192.168.1.30"] = true
+		assert.Equal(t, StateHome, s.observe(pinged, p, sig, now))
+
+		lingering := tracker(3, models.TrackerTypeNetwork)
+		lingering.Identifier = "192.168.1.40"
+		lingering.LastSeen = &recently
+		assert.Equal(t, StateHome, s.observe(lingering, p, sig, now), "seen within consider home")
+
+		gone := tracker(4, models.TrackerTypeNetwork)
+		gone.Identifier = "192.168.1.50"
+		gone.LastSeen = &longAgo
+		assert.Equal(t, StateNotHome, s.observe(gone, p, sig, now))
+	})
+
+	t.Run("consider home precedence", func(t *testing.T) {
+		s := newTestService()
+		seen := now.Add(-5 * time.Minute)
+		sig := emptySignals()
+
+		withDefault := tracker(1, models.TrackerTypeNetwork)
+		withDefault.LastSeen = &seen
+		assert.Equal(t, StateNotHome, s.observe(withDefault, &person{PresencePerson: &models.PresencePerson{}}, sig, now))
+
+		personWindow := &person{PresencePerson: &models.PresencePerson{ConsiderHomeSeconds: 600}}
+		assert.Equal(t, StateHome, s.observe(withDefault, personWindow, sig, now))
+
+		withDefault.ConsiderHomeSeconds = 60
+		assert.Equal(t, StateNotHome, s.observe(withDefault, personWindow, sig, now), "the tracker window wins")
+	})
+
+	t.Run("ble", func(t *testing.T) {
+		s := newTestService()
+		p := &person{PresencePerson: &models.PresencePerson{}}
+		weak, strong := -90, -60
+
+		tag := tracker(1, models.TrackerTypeBLE)
+		tag.Identifier = "aa:bb:cc:dd:ee:01"
+		tag.MinRSSI = -80
+
+		assert.Equal(t, StateUnknown, s.observe(tag, p, emptySignals(), now), "no BLE adapter")
+
+		sig := emptySignals()
+		sig.bleAvailable = true
+		sig.ble[tag.Identifier] = &ble.BLEDevice{RSSI: &weak, LastSeen: now}
+		assert.Equal(t, StateNotHome, s.observe(tag, p, sig, now), "too weak to count")
+		assert.Nil(t, tag.LastSeen)
+
+		sig.ble[tag.Identifier] = &ble.BLEDevice{RSSI: &strong, LastSeen: recently}
+		assert.Equal(t, StateHome, s.observe(tag, p, sig, now))
+		assert.Equal(t, recently, *tag.LastSeen)
+	})
+
+	t.Run("entity", func(t *testing.T) {
+		s := newTestService()
+		p := &person{PresencePerson: &models.PresencePerson{}}
+		sig := emptySignals()
+
+		tests := []struct {
+			state string
+			want  string
+		}{
+			{"home", StateHome},
+			{"on", StateHome},
+			{"not_home", StateNotHome},
+			{"off", StateNotHome},
+			{"unavailable", StateUnknown},
+			{"work", "work"},
+		}
+		for _, tt := range tests {
+			entity := tracker(1, models.TrackerTypeEntity)
+			entity.Identifier = "device_tracker.phone"
+			sig.entities[entity.Identifier] = tt.state
+			assert.Equal(t, tt.want, s.observe(entity, p, sig, now), "entity state %s", tt.state)
+		}
+
+		missing := tracker(2, models.TrackerTypeEntity)
+		missing.Identifier = "device_tracker.missing"
+		assert.Equal(t, StateUnknown, s.observe(missing, p, sig, now))
+	})
+
+	t.Run("app", func(t *testing.T) {
+		s := newTestService()
+		p := &person{PresencePerson: &models.PresencePerson{}}
+		app := tracker(1, models.TrackerTypeApp)
+
+		assert.Equal(t, StateUnknown, s.observe(app, p, emptySignals(), now), "never checked in")
+
+		app.LastState = "work"
+		assert.Equal(t, "work", s.observe(app, p, emptySignals(), now))
+
+		app.LastState = StateNotHome
+		app.LastSeen = &recently
+		assert.Equal(t, StateHome, s.observe(app, p, emptySignals(), now), "left within consider home")
+
+		app.LastSeen = &longAgo
+		assert.Equal(t, StateNotHome, s.observe(app, p, emptySignals(), now))
+	})
+}
+
+func TestResolveZone(t *testing.T) {
+	zones := []*models.PresenceZone{
+		{ID: "city", Latitude: 52.52, Longitude: 13.405, RadiusMeters: 10000},
+		{ID: "office", Latitude: 52.5205, Longitude: 13.4095, RadiusMeters: 200},
+	}
+
+	assert.Equal(t, "office", resolveZone(zones, 52.5206, 13.4096), "the smallest containing zone wins")
+	assert.Equal(t, "city", resolveZone(zones, 52.55, 13.40))
+	assert.Empty(t, resolveZone(zones, 48.137, 11.575))
+}
+
+func TestReadARPTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arp")
+	content := "IP address       HW type     Flags       HW address            Mask     Device\n" +
+		"192.168.1.20     0x1         0x2         AA:BB:CC:DD:EE:FF     *        eth0\n" +
+		"192.168.1.21     0x1         0x0         00:00:00:00:00:00     *        eth0\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	table, err := readARPTable(path)
+	require.NoError(t, err)
+	assert.True(t, table.lookup("192.168.1.20"))
+	assert.True(t, table.lookup("aa-bb-cc-dd-ee-ff"))
+	assert.False(t, table.lookup("192.168.1.21"), "incomplete entries are skipped")
+}
@@ -0,0 +1,42 @@
+package presence
+
+import (
+	"math"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// HomeZoneID is the ID of the zone that counts as being home
+const HomeZoneID = "home"
+
+const earthRadiusMeters = 6371000.0
+
+// distanceMeters returns the great-circle distance between two coordinates
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// resolveZone returns the smallest zone containing the coordinates, or "" when there is none
+func resolveZone(zones []*models.PresenceZone, latitude, longitude float64) string {
+	var best *models.PresenceZone
+	for _, zone := range zones {
+		if distanceMeters(latitude, longitude, zone.Latitude, zone.Longitude) > zone.RadiusMeters {
+			continue
+		}
+		if best == nil || zone.RadiusMeters < best.RadiusMeters {
+			best = zone
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+	return best.ID
+}
@@ -25,6 +25,10 @@ type QueueProcessor struct {
 	running     bool
 	handlers    map[string]ActionHandler
 
+	// Handlers registered with RegisterHandler. Only their action types are picked up,
+	// the default handlers are stubs.
+	registered map[string]ActionHandler
+
 	// Settings that can be updated dynamically
 	pollInterval    time.Duration
 	maxConcurrent   int
@@ -74,6 +78,7 @@ func NewQueueProcessor(queueRepo *sqlite.QueueRepository, service QueueServiceIn
 		workerCount:     5, // Default worker count
 		stopChan:        make(chan bool),
 		handlers:        make(map[string]ActionHandler),
+		registered:      make(map[string]ActionHandler),
 		pollInterval:    time.Second * 1,  // Default 1 second
 		maxConcurrent:   5,                // Default 5 concurrent workers
 		cleanupInterval: time.Minute * 10, // Default 10 minute cleanup interval
@@ -293,6 +298,15 @@ func (p *QueueProcessor) loadSettings(ctx context.Context) error {
 	return nil
 }
 
+// RegisterHandler registers the handler for action types with its handler name, replacing a
+// handler registered before
+func (p *QueueProcessor) RegisterHandler(handler ActionHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[handler.GetHandlerName()] = handler
+	p.registered[handler.GetHandlerName()] = handler
+}
+
 func (p *QueueProcessor) registerDefaultHandlers() {
 	// Register handlers for different action types
 	p.handlers["entity_state_change"] = &EntityStateHandler{logger: p.logger}
@@ -305,6 +319,19 @@ func (p *QueueProcessor) registerDefaultHandlers() {
 	p.handlers["bulk_operation"] = &BulkOperationHandler{logger: p.logger}
 }
 
+// handlerNames lists the handler names registered with RegisterHandler, the action types
+// workers pick up
+func (p *QueueProcessor) handlerNames() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.registered))
+	for name := range p.registered {
+		names = append(names, name)
+	}
+	return names
+}
+
 func (p *QueueProcessor) processAction(ctx context.Context, action *models.QueuedAction) bool {
 	// Check if dependencies are met
 	if dependenciesMet, err := p.queueRepo.CheckDependenciesMet(ctx, action.ID); err != nil {
@@ -327,7 +354,9 @@ func (p *QueueProcessor) processAction(ctx context.Context, action *models.Queue
 	}
 
 	// Get handler for action type
+	p.mu.RLock()
 	handler, exists := p.handlers[action.ActionType.HandlerName]
+	p.mu.RUnlock()
 	if !exists {
 		p.logger.WithField("handler_name", action.ActionType.HandlerName).Error("No handler found for action type")
 		p.queueRepo.CompleteAction(ctx, action.ID, false, nil,
@@ -512,8 +541,9 @@ func (w *QueueWorker) run(ctx context.Context) {
 func (w *QueueWorker) processNextAction(ctx context.Context) {
 	w.lastActivity = time.Now()
 
-	// Get next action to process
-	actions, err := w.processor.queueRepo.GetNextActionsToProcess(ctx, 1)
+	// Get next action to process. Only actions with a registered handler are picked, so actions
+	// of types nothing handles yet stay pending instead of failing.
+	actions, err := w.processor.queueRepo.GetNextActionsToProcess(ctx, 1, w.processor.handlerNames())
 	if err != nil {
 		w.logger.WithError(err).Error("Failed to get next action")
 		w.errorCount++
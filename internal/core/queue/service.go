@@ -32,6 +32,11 @@ func NewQueueService(queueRepo *sqlite.QueueRepository, wsHub *websocket.Hub, lo
 	return service
 }
 
+// RegisterHandler registers the handler for action types with its handler name
+func (s *QueueService) RegisterHandler(handler ActionHandler) {
+	s.processor.RegisterHandler(handler)
+}
+
 // Start initializes and starts the queue processing workers
 func (s *QueueService) Start(ctx context.Context) error {
 	s.logger.Info("Starting queue service")
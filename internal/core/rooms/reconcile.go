@@ -0,0 +1,305 @@
+package rooms
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// ExternalAreaSource lists the area IDs that currently exist in an external system
+type ExternalAreaSource interface {
+	GetAreaIDs(ctx context.Context) ([]string, error)
+}
+
+// OrphanedAssignment is an entity that references a room which no longer exists
+type OrphanedAssignment struct {
+	EntityID string `json:"entity_id"`
+	RoomID   string `json:"room_id"`
+	Source   string `json:"source"` // assignment or entity
+	Fixed    bool   `json:"fixed"`
+}
+
+// UnassignedRoom is a room that does not belong to any area
+type UnassignedRoom struct {
+	RoomID              string `json:"room_id"`
+	Name                string `json:"name"`
+	HomeAssistantAreaID string `json:"home_assistant_area_id,omitempty"`
+	SuggestedAreaID     *int   `json:"suggested_area_id,omitempty"`
+	Fixed               bool   `json:"fixed"`
+}
+
+// StaleAreaMapping is a mapping to a Home Assistant area that no longer exists
+type StaleAreaMapping struct {
+	MappingID      int    `json:"mapping_id"`
+	PMAAreaID      int    `json:"pma_area_id"`
+	ExternalAreaID string `json:"external_area_id"`
+	Fixed          bool   `json:"fixed"`
+}
+
+// ReconcileReport describes the drift found between rooms, entities and areas
+type ReconcileReport struct {
+	Fix                 bool                  `json:"fix"`
+	OrphanedAssignments []*OrphanedAssignment `json:"orphaned_assignments"`
+	UnassignedRooms     []*UnassignedRoom     `json:"unassigned_rooms"`
+	StaleAreaMappings   []*StaleAreaMapping   `json:"stale_area_mappings"`
+	Errors              []string              `json:"errors,omitempty"`
+	StartedAt           time.Time             `json:"started_at"`
+	CompletedAt         time.Time             `json:"completed_at"`
+}
+
+// IssueCount returns the number of problems found
+func (r *ReconcileReport) IssueCount() int {
+	return len(r.OrphanedAssignments) + len(r.UnassignedRooms) + len(r.StaleAreaMappings)
+}
+
+// Reconciler keeps the room hierarchy and the area hierarchy consistent
+type Reconciler struct {
+	rooms      *RoomService
+	areaRepo   repositories.AreaRepository
+	haAreas    ExternalAreaSource
+	logger     *logrus.Logger
+	mutex      sync.RWMutex
+	lastReport *ReconcileReport
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+}
+
+// NewReconciler creates a reconciler. haAreas may be nil when Home Assistant is not configured.
+func NewReconciler(rooms *RoomService, areaRepo repositories.AreaRepository, haAreas ExternalAreaSource, logger *logrus.Logger) *Reconciler {
+	return &Reconciler{
+		rooms:    rooms,
+		areaRepo: areaRepo,
+		haAreas:  haAreas,
+		logger:   logger,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs a report-only reconciliation on the given interval
+func (r *Reconciler) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		r.Reconcile(ctx, false)
+		for {
+			select {
+			case <-ticker.C:
+				r.Reconcile(ctx, false)
+			case <-r.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic reconciliation
+func (r *Reconciler) Stop() {
+	r.stopOnce.Do(func() { close(r.stopChan) })
+}
+
+// LastReport returns the report of the most recent run
+func (r *Reconciler) LastReport() *ReconcileReport {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.lastReport
+}
+
+// Reconcile looks for orphaned room references on entities, rooms without an area and Home
+// Assistant area mappings whose area is gone. With fix set, what can be repaired safely is:
+// orphaned references are cleared, rooms are assigned to the area mapped from their HA area
+// (or an area with the same name), and stale mappings are removed while keeping the area.
+func (r *Reconciler) Reconcile(ctx context.Context, fix bool) *ReconcileReport {
+	report := &ReconcileReport{
+		Fix:                 fix,
+		OrphanedAssignments: make([]*OrphanedAssignment, 0),
+		UnassignedRooms:     make([]*UnassignedRoom, 0),
+		StaleAreaMappings:   make([]*StaleAreaMapping, 0),
+		StartedAt:           time.Now(),
+	}
+
+	if r.rooms.repo == nil {
+		report.Errors = append(report.Errors, "room repository not available")
+	} else {
+		r.reconcileAssignments(ctx, report)
+		r.reconcileUnassignedRooms(ctx, report)
+	}
+	if r.areaRepo != nil {
+		r.reconcileAreaMappings(ctx, report)
+	}
+
+	report.CompletedAt = time.Now()
+
+	r.mutex.Lock()
+	r.lastReport = report
+	r.mutex.Unlock()
+
+	entry := r.logger.WithFields(logrus.Fields{
+		"fix":                  fix,
+		"orphaned_assignments": len(report.OrphanedAssignments),
+		"unassigned_rooms":     len(report.UnassignedRooms),
+		"stale_area_mappings":  len(report.StaleAreaMappings),
+		"errors":               len(report.Errors),
+	})
+	if report.IssueCount() > 0 || len(report.Errors) > 0 {
+		entry.Warn("Room reconciliation found issues")
+	} else {
+		entry.Debug("Room reconciliation completed")
+	}
+
+	return report
+}
+
+func (r *Reconciler) reconcileAssignments(ctx context.Context, report *ReconcileReport) {
+	repo := r.rooms.repo
+
+	assignments, err := repo.GetEntityAssignments(ctx)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, assignment := range assignments {
+		if _, err := r.rooms.GetRoomByID(ctx, assignment.RoomKey); err == nil {
+			continue
+		}
+
+		orphan := &OrphanedAssignment{EntityID: assignment.EntityID, RoomID: assignment.RoomKey, Source: "assignment"}
+		if report.Fix {
+			if err := repo.UnassignEntity(ctx, assignment.EntityID); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			} else {
+				orphan.Fixed = true
+			}
+		}
+		report.OrphanedAssignments = append(report.OrphanedAssignments, orphan)
+	}
+
+	legacy, err := repo.GetOrphanedEntityRooms(ctx)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for entityID, roomID := range legacy {
+		orphan := &OrphanedAssignment{EntityID: entityID, RoomID: strconv.Itoa(roomID), Source: "entity"}
+		if report.Fix {
+			if err := repo.ClearEntityRoom(ctx, entityID); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			} else {
+				orphan.Fixed = true
+			}
+		}
+		report.OrphanedAssignments = append(report.OrphanedAssignments, orphan)
+	}
+}
+
+func (r *Reconciler) reconcileUnassignedRooms(ctx context.Context, report *ReconcileReport) {
+	repo := r.rooms.repo
+
+	unassigned, err := repo.GetUnassignedRooms(ctx)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+	if len(unassigned) == 0 {
+		return
+	}
+
+	// Candidate areas: the mapped area of the room's HA area, otherwise an area with the same name
+	mappedAreas := make(map[string]int)
+	namedAreas := make(map[string]int)
+	if r.areaRepo != nil {
+		if mappings, err := r.areaRepo.GetAreaMappingsBySystem(ctx, models.ExternalSystemHomeAssistant); err == nil {
+			for _, mapping := range mappings {
+				mappedAreas[mapping.ExternalAreaID] = mapping.PMAAreaID
+			}
+		} else {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		if areas, err := r.areaRepo.GetAllAreas(ctx, false); err == nil {
+			for _, area := range areas {
+				namedAreas[strings.ToLower(area.Name)] = area.ID
+			}
+		} else {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	for _, room := range unassigned {
+		entry := &UnassignedRoom{RoomID: room.RoomKey.String, Name: room.Name}
+		if entry.RoomID == "" {
+			entry.RoomID = roomKeyFromID(room.ID)
+		}
+
+		var areaID int
+		var found bool
+		if room.HomeAssistantAreaID.Valid {
+			entry.HomeAssistantAreaID = room.HomeAssistantAreaID.String
+			areaID, found = mappedAreas[room.HomeAssistantAreaID.String]
+		}
+		if !found {
+			areaID, found = namedAreas[strings.ToLower(room.Name)]
+		}
+		if found {
+			entry.SuggestedAreaID = &areaID
+			if report.Fix {
+				if err := repo.AssignToArea(ctx, room.ID, &areaID); err != nil {
+					report.Errors = append(report.Errors, err.Error())
+				} else {
+					entry.Fixed = true
+				}
+			}
+		}
+
+		report.UnassignedRooms = append(report.UnassignedRooms, entry)
+	}
+}
+
+func (r *Reconciler) reconcileAreaMappings(ctx context.Context, report *ReconcileReport) {
+	if r.haAreas == nil {
+		return
+	}
+
+	mappings, err := r.areaRepo.GetAreaMappingsBySystem(ctx, models.ExternalSystemHomeAssistant)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+	if len(mappings) == 0 {
+		return
+	}
+
+	areaIDs, err := r.haAreas.GetAreaIDs(ctx)
+	if err != nil {
+		// Without the current HA areas every mapping would look stale
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to list Home Assistant areas: %v", err))
+		return
+	}
+
+	existing := make(map[string]bool, len(areaIDs))
+	for _, id := range areaIDs {
+		existing[id] = true
+	}
+
+	for _, mapping := range mappings {
+		if existing[mapping.ExternalAreaID] {
+			continue
+		}
+
+		stale := &StaleAreaMapping{MappingID: mapping.ID, PMAAreaID: mapping.PMAAreaID, ExternalAreaID: mapping.ExternalAreaID}
+		if report.Fix {
+			if err := r.areaRepo.DeleteAreaMapping(ctx, mapping.ID); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			} else {
+				stale.Fixed = true
+			}
+		}
+		report.StaleAreaMappings = append(report.StaleAreaMappings, stale)
+	}
+}
@@ -7,24 +7,78 @@ import (
 	"time"
 
 	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
 	"github.com/sirupsen/logrus"
 )
 
-// RoomService manages rooms and their entity assignments
+// RoomService manages rooms and their entity assignments. Rooms are cached in memory and
+// written through to the room repository so they survive restarts.
 type RoomService struct {
-	rooms  map[string]*types.PMARoom
-	logger *logrus.Logger
-	mutex  sync.RWMutex
+	rooms       map[string]*types.PMARoom
+	entityRooms map[string]string // entity ID -> room ID
+	repo        repositories.RoomRepository
+	logger      *logrus.Logger
+	mutex       sync.RWMutex
 }
 
-// NewRoomService creates a new room service
-func NewRoomService(logger *logrus.Logger) *RoomService {
+// NewRoomService creates a new room service. A nil repository keeps rooms in memory only.
+func NewRoomService(repo repositories.RoomRepository, logger *logrus.Logger) *RoomService {
 	return &RoomService{
-		rooms:  make(map[string]*types.PMARoom),
-		logger: logger,
+		rooms:       make(map[string]*types.PMARoom),
+		entityRooms: make(map[string]string),
+		repo:        repo,
+		logger:      logger,
 	}
 }
 
+// Load reads the persisted rooms and entity assignments into memory
+func (s *RoomService) Load(ctx context.Context) error {
+	if s.repo == nil {
+		return nil
+	}
+
+	stored, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load rooms: %w", err)
+	}
+
+	assignments, err := s.repo.GetEntityAssignments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load entity assignments: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rooms = make(map[string]*types.PMARoom, len(stored))
+	s.entityRooms = make(map[string]string, len(assignments))
+	for _, model := range stored {
+		room := roomFromModel(model)
+		s.rooms[room.ID] = room
+	}
+
+	// Assignments to rooms that no longer exist are left for the reconciliation job
+	orphaned := 0
+	for _, assignment := range assignments {
+		room, exists := s.rooms[assignment.RoomKey]
+		if !exists {
+			orphaned++
+			continue
+		}
+		room.EntityIDs = append(room.EntityIDs, assignment.EntityID)
+		s.entityRooms[assignment.EntityID] = room.ID
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"rooms":                len(s.rooms),
+		"entity_assignments":   len(s.entityRooms),
+		"orphaned_assignments": orphaned,
+	}).Info("Rooms loaded")
+
+	return nil
+}
+
 // GetAllRooms returns all rooms
 func (s *RoomService) GetAllRooms(ctx context.Context) ([]*types.PMARoom, error) {
 	s.mutex.RLock()
@@ -51,6 +105,15 @@ func (s *RoomService) GetRoomByID(ctx context.Context, roomID string) (*types.PM
 	return room, nil
 }
 
+// GetRoomForEntity returns the ID of the room an entity is assigned to
+func (s *RoomService) GetRoomForEntity(entityID string) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	roomID, exists := s.entityRooms[entityID]
+	return roomID, exists
+}
+
 // CreateRoom creates a new room
 func (s *RoomService) CreateRoom(ctx context.Context, room *types.PMARoom) error {
 	if room.ID == "" {
@@ -72,12 +135,19 @@ func (s *RoomService) CreateRoom(ctx context.Context, room *types.PMARoom) error
 	room.CreatedAt = time.Now()
 	room.UpdatedAt = time.Now()
 
-	// Initialize entity list if nil
-	if room.EntityIDs == nil {
-		room.EntityIDs = make([]string, 0)
+	if err := s.persistRoom(ctx, room); err != nil {
+		return err
 	}
 
+	// Entities listed on the new room are assigned to it
+	entityIDs := room.EntityIDs
+	room.EntityIDs = make([]string, 0, len(entityIDs))
 	s.rooms[room.ID] = room
+	for _, entityID := range entityIDs {
+		if err := s.assignLocked(ctx, entityID, room.ID); err != nil {
+			return err
+		}
+	}
 
 	s.logger.WithFields(logrus.Fields{
 		"room_id":   room.ID,
@@ -87,7 +157,8 @@ func (s *RoomService) CreateRoom(ctx context.Context, room *types.PMARoom) error
 	return nil
 }
 
-// UpdateRoom updates an existing room
+// UpdateRoom updates an existing room. Entity assignments are kept; they are changed through
+// AssignEntityToRoom and UnassignEntityFromRoom.
 func (s *RoomService) UpdateRoom(ctx context.Context, room *types.PMARoom) error {
 	if room.ID == "" {
 		return fmt.Errorf("room ID is required")
@@ -102,9 +173,14 @@ func (s *RoomService) UpdateRoom(ctx context.Context, room *types.PMARoom) error
 		return fmt.Errorf("room not found: %s", room.ID)
 	}
 
-	// Preserve creation time and update timestamp
+	// Preserve creation time, assignments and update timestamp
 	room.CreatedAt = existingRoom.CreatedAt
 	room.UpdatedAt = time.Now()
+	room.EntityIDs = existingRoom.EntityIDs
+
+	if err := s.persistRoom(ctx, room); err != nil {
+		return err
+	}
 
 	s.rooms[room.ID] = room
 
@@ -116,43 +192,55 @@ func (s *RoomService) UpdateRoom(ctx context.Context, room *types.PMARoom) error
 	return nil
 }
 
-// DeleteRoom deletes a room
+// DeleteRoom deletes a room and its entity assignments
 func (s *RoomService) DeleteRoom(ctx context.Context, roomID string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// Check if room exists
-	if _, exists := s.rooms[roomID]; !exists {
+	room, exists := s.rooms[roomID]
+	if !exists {
 		return fmt.Errorf("room not found: %s", roomID)
 	}
 
+	if s.repo != nil {
+		if stored, err := s.repo.GetByKey(ctx, roomID); err == nil {
+			if err := s.repo.Delete(ctx, stored.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.DeleteEntityAssignments(ctx, roomID); err != nil {
+			return err
+		}
+	}
+
+	for _, entityID := range room.EntityIDs {
+		delete(s.entityRooms, entityID)
+	}
 	delete(s.rooms, roomID)
 
 	s.logger.WithField("room_id", roomID).Info("Room deleted")
 	return nil
 }
 
-// AssignEntityToRoom assigns an entity to a room
+// AssignEntityToRoom assigns an entity to a room, moving it out of its previous room
 func (s *RoomService) AssignEntityToRoom(ctx context.Context, entityID, roomID string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// Check if room exists
-	room, exists := s.rooms[roomID]
-	if !exists {
+	if _, exists := s.rooms[roomID]; !exists {
 		return fmt.Errorf("room not found: %s", roomID)
 	}
 
 	// Check if entity is already in the room
-	for _, existingEntityID := range room.EntityIDs {
-		if existingEntityID == entityID {
-			return nil // Already assigned
-		}
+	if s.entityRooms[entityID] == roomID {
+		return nil // Already assigned
 	}
 
-	// Add entity to room
-	room.EntityIDs = append(room.EntityIDs, entityID)
-	room.UpdatedAt = time.Now()
+	if err := s.assignLocked(ctx, entityID, roomID); err != nil {
+		return err
+	}
 
 	s.logger.WithFields(logrus.Fields{
 		"entity_id": entityID,
@@ -173,22 +261,26 @@ func (s *RoomService) UnassignEntityFromRoom(ctx context.Context, entityID, room
 		return fmt.Errorf("room not found: %s", roomID)
 	}
 
-	// Find and remove entity
-	for i, existingEntityID := range room.EntityIDs {
-		if existingEntityID == entityID {
-			room.EntityIDs = append(room.EntityIDs[:i], room.EntityIDs[i+1:]...)
-			room.UpdatedAt = time.Now()
-
-			s.logger.WithFields(logrus.Fields{
-				"entity_id": entityID,
-				"room_id":   roomID,
-			}).Info("Entity unassigned from room")
+	if s.entityRooms[entityID] != roomID {
+		return fmt.Errorf("entity not found in room: %s", entityID)
+	}
 
-			return nil
+	if s.repo != nil {
+		if err := s.repo.UnassignEntity(ctx, entityID); err != nil {
+			return err
 		}
 	}
 
-	return fmt.Errorf("entity not found in room: %s", entityID)
+	room.EntityIDs = removeEntityID(room.EntityIDs, entityID)
+	room.UpdatedAt = time.Now()
+	delete(s.entityRooms, entityID)
+
+	s.logger.WithFields(logrus.Fields{
+		"entity_id": entityID,
+		"room_id":   roomID,
+	}).Info("Entity unassigned from room")
+
+	return nil
 }
 
 // GetEntitiesInRoom returns all entities in a room
@@ -224,8 +316,17 @@ func (s *RoomService) ReassignEntities(ctx context.Context, fromRoomID, toRoomID
 		return fmt.Errorf("destination room not found: %s", toRoomID)
 	}
 
+	if s.repo != nil {
+		if err := s.repo.MoveEntities(ctx, fromRoomID, toRoomID); err != nil {
+			return err
+		}
+	}
+
 	// Move entities
 	entityCount := len(fromRoom.EntityIDs)
+	for _, entityID := range fromRoom.EntityIDs {
+		s.entityRooms[entityID] = toRoomID
+	}
 	toRoom.EntityIDs = append(toRoom.EntityIDs, fromRoom.EntityIDs...)
 	fromRoom.EntityIDs = make([]string, 0)
 
@@ -254,27 +355,51 @@ func (s *RoomService) SyncRoomsFromSource(ctx context.Context, adapter types.PMA
 	defer s.mutex.Unlock()
 
 	syncCount := 0
+	failed := 0
 	for _, room := range rooms {
+		entityIDs := room.EntityIDs
+
 		// Check if room already exists
 		existingRoom, exists := s.rooms[room.ID]
 		if exists {
 			// Update existing room
 			room.CreatedAt = existingRoom.CreatedAt
 			room.UpdatedAt = time.Now()
+			room.EntityIDs = existingRoom.EntityIDs
 		} else {
 			// New room
 			room.CreatedAt = time.Now()
 			room.UpdatedAt = time.Now()
-			syncCount++
+			room.EntityIDs = make([]string, 0, len(entityIDs))
+		}
+
+		if err := s.persistRoom(ctx, room); err != nil {
+			failed++
+			s.logger.WithError(err).WithField("room_id", room.ID).Warn("Failed to persist synced room")
+			continue
 		}
 
 		s.rooms[room.ID] = room
+		if !exists {
+			syncCount++
+		}
+
+		// Entities reported by the adapter are assigned to the room
+		for _, entityID := range entityIDs {
+			if s.entityRooms[entityID] == room.ID {
+				continue
+			}
+			if err := s.assignLocked(ctx, entityID, room.ID); err != nil {
+				s.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to assign synced entity")
+			}
+		}
 	}
 
 	s.logger.WithFields(logrus.Fields{
 		"adapter_id":      adapter.GetID(),
 		"rooms_synced":    len(rooms),
 		"new_rooms_added": syncCount,
+		"rooms_failed":    failed,
 	}).Info("Rooms synchronized from adapter")
 
 	return nil
@@ -301,3 +426,53 @@ func (s *RoomService) GetRoomStats() map[string]interface{} {
 		}(),
 	}
 }
+
+// persistRoom creates or updates the stored copy of a room. Callers hold the mutex.
+func (s *RoomService) persistRoom(ctx context.Context, room *types.PMARoom) error {
+	if s.repo == nil {
+		return nil
+	}
+
+	stored, err := s.repo.GetByKey(ctx, room.ID)
+	if err != nil {
+		stored = &models.Room{}
+		applyRoomToModel(room, stored)
+		return s.repo.Create(ctx, stored)
+	}
+
+	applyRoomToModel(room, stored)
+	return s.repo.Update(ctx, stored)
+}
+
+// assignLocked assigns an entity to a room and removes it from its previous room. Callers hold
+// the mutex and have checked that the room exists.
+func (s *RoomService) assignLocked(ctx context.Context, entityID, roomID string) error {
+	if s.repo != nil {
+		if err := s.repo.AssignEntity(ctx, entityID, roomID); err != nil {
+			return err
+		}
+	}
+
+	if previousID, assigned := s.entityRooms[entityID]; assigned {
+		if previous, exists := s.rooms[previousID]; exists {
+			previous.EntityIDs = removeEntityID(previous.EntityIDs, entityID)
+			previous.UpdatedAt = time.Now()
+		}
+	}
+
+	room := s.rooms[roomID]
+	room.EntityIDs = append(room.EntityIDs, entityID)
+	room.UpdatedAt = time.Now()
+	s.entityRooms[entityID] = roomID
+
+	return nil
+}
+
+func removeEntityID(entityIDs []string, entityID string) []string {
+	for i, existingEntityID := range entityIDs {
+		if existingEntityID == entityID {
+			return append(entityIDs[:i], entityIDs[i+1:]...)
+		}
+	}
+	return entityIDs
+}
@@ -0,0 +1,275 @@
+package rooms
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRoomRepository keeps rooms and assignments in memory. Methods the room service
+// does not use are left to the embedded interface.
+type memoryRoomRepository struct {
+	repositories.RoomRepository
+	rooms       map[int]*models.Room
+	assignments map[string]string // entity ID -> room key
+	nextID      int
+}
+
+func newMemoryRoomRepository() *memoryRoomRepository {
+	return &memoryRoomRepository{rooms: make(map[int]*models.Room), assignments: make(map[string]string)}
+}
+
+func (r *memoryRoomRepository) Create(ctx context.Context, room *models.Room) error {
+	r.nextID++
+	room.ID = r.nextID
+	copied := *room
+	r.rooms[room.ID] = &copied
+	return nil
+}
+
+func (r *memoryRoomRepository) GetAll(ctx context.Context) ([]*models.Room, error) {
+	rooms := make([]*models.Room, 0, len(r.rooms))
+	for _, room := range r.rooms {
+		copied := *room
+		rooms = append(rooms, &copied)
+	}
+	return rooms, nil
+}
+
+func (r *memoryRoomRepository) Update(ctx context.Context, room *models.Room) error {
+	copied := *room
+	r.rooms[room.ID] = &copied
+	return nil
+}
+
+func (r *memoryRoomRepository) Delete(ctx context.Context, id int) error {
+	delete(r.rooms, id)
+	return nil
+}
+
+func (r *memoryRoomRepository) GetByKey(ctx context.Context, roomKey string) (*models.Room, error) {
+	for _, room := range r.rooms {
+		if room.RoomKey.String == roomKey {
+			copied := *room
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("room not found")
+}
+
+func (r *memoryRoomRepository) GetEntityAssignments(ctx context.Context) ([]*models.RoomEntityAssignment, error) {
+	assignments := make([]*models.RoomEntityAssignment, 0, len(r.assignments))
+	for entityID, roomKey := range r.assignments {
+		assignments = append(assignments, &models.RoomEntityAssignment{EntityID: entityID, RoomKey: roomKey})
+	}
+	return assignments, nil
+}
+
+func (r *memoryRoomRepository) AssignEntity(ctx context.Context, entityID, roomKey string) error {
+	r.assignments[entityID] = roomKey
+	return nil
+}
+
+func (r *memoryRoomRepository) UnassignEntity(ctx context.Context, entityID string) error {
+	delete(r.assignments, entityID)
+	return nil
+}
+
+func (r *memoryRoomRepository) MoveEntities(ctx context.Context, fromRoomKey, toRoomKey string) error {
+	for entityID, roomKey := range r.assignments {
+		if roomKey == fromRoomKey {
+			r.assignments[entityID] = toRoomKey
+		}
+	}
+	return nil
+}
+
+func (r *memoryRoomRepository) DeleteEntityAssignments(ctx context.Context, roomKey string) error {
+	for entityID, assigned := range r.assignments {
+		if assigned == roomKey {
+			delete(r.assignments, entityID)
+		}
+	}
+	return nil
+}
+
+func newTestService(repo repositories.RoomRepository) *RoomService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewRoomService(repo, logger)
+}
+
+// reload returns a fresh service loaded from the repository, as after a restart
+func reload(t *testing.T, repo repositories.RoomRepository) *RoomService {
+	t.Helper()
+	s := newTestService(repo)
+	require.NoError(t, s.Load(context.Background()))
+	return s
+}
+
+func TestRoomsSurviveRestart(t *testing.T) {
+	repo := newMemoryRoomRepository()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	parentID := "ground_floor"
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: parentID, Name: "Ground floor"}))
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{
+		ID:          "kitchen",
+		Name:        "Kitchen",
+		Icon:        "mdi:stove",
+		Description: "Back of the house",
+		ParentID:    &parentID,
+		EntityIDs:   []string{"light.kitchen", "sensor.kitchen_temperature"},
+		Attributes:  map[string]interface{}{"floor": "0"},
+		Metadata:    &types.PMAMetadata{Source: types.SourceHomeAssistant, SourceEntityID: "kitchen_area"},
+	}))
+
+	restarted := reload(t, repo)
+	room, err := restarted.GetRoomByID(ctx, "kitchen")
+	require.NoError(t, err)
+	assert.Equal(t, "Kitchen", room.Name)
+	assert.Equal(t, "mdi:stove", room.Icon)
+	assert.Equal(t, "Back of the house", room.Description)
+	require.NotNil(t, room.ParentID)
+	assert.Equal(t, parentID, *room.ParentID)
+	assert.Equal(t, map[string]interface{}{"floor": "0"}, room.Attributes)
+	require.NotNil(t, room.Metadata)
+	assert.Equal(t, types.SourceHomeAssistant, room.Metadata.Source)
+	assert.ElementsMatch(t, []string{"light.kitchen", "sensor.kitchen_temperature"}, room.EntityIDs)
+
+	roomID, assigned := restarted.GetRoomForEntity("light.kitchen")
+	assert.True(t, assigned)
+	assert.Equal(t, "kitchen", roomID)
+
+	stored, err := repo.GetByKey(ctx, "kitchen")
+	require.NoError(t, err)
+	assert.Equal(t, "kitchen_area", stored.HomeAssistantAreaID.String, "synced rooms remember their area")
+}
+
+func TestUpdateRoomKeepsAssignments(t *testing.T) {
+	repo := newMemoryRoomRepository()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "office", Name: "Office", Icon: "mdi:desk", EntityIDs: []string{"light.desk"}}))
+	require.NoError(t, s.UpdateRoom(ctx, &types.PMARoom{ID: "office", Name: "Study"}))
+
+	restarted := reload(t, repo)
+	room, err := restarted.GetRoomByID(ctx, "office")
+	require.NoError(t, err)
+	assert.Equal(t, "Study", room.Name)
+	assert.Empty(t, room.Icon, "cleared fields are cleared in the store")
+	assert.Equal(t, []string{"light.desk"}, room.EntityIDs)
+	assert.Len(t, repo.rooms, 1, "updates do not create a second row")
+
+	assert.Error(t, s.UpdateRoom(ctx, &types.PMARoom{ID: "attic", Name: "Attic"}))
+}
+
+func TestAssignEntityToRoom(t *testing.T) {
+	repo := newMemoryRoomRepository()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "kitchen", Name: "Kitchen"}))
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "hall", Name: "Hall"}))
+
+	require.NoError(t, s.AssignEntityToRoom(ctx, "light.lamp", "kitchen"))
+	require.NoError(t, s.AssignEntityToRoom(ctx, "light.lamp", "kitchen"), "assigning twice is a no-op")
+	require.NoError(t, s.AssignEntityToRoom(ctx, "light.lamp", "hall"))
+
+	kitchen, _ := s.GetEntitiesInRoom(ctx, "kitchen")
+	hall, _ := s.GetEntitiesInRoom(ctx, "hall")
+	assert.Empty(t, kitchen, "an entity is in one room at a time")
+	assert.Equal(t, []string{"light.lamp"}, hall)
+	assert.Equal(t, "hall", repo.assignments["light.lamp"])
+
+	assert.Error(t, s.AssignEntityToRoom(ctx, "light.lamp", "attic"))
+	assert.Error(t, s.UnassignEntityFromRoom(ctx, "light.lamp", "kitchen"))
+
+	require.NoError(t, s.UnassignEntityFromRoom(ctx, "light.lamp", "hall"))
+	_, assigned := s.GetRoomForEntity("light.lamp")
+	assert.False(t, assigned)
+	assert.Empty(t, repo.assignments)
+}
+
+func TestReassignEntities(t *testing.T) {
+	repo := newMemoryRoomRepository()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "old", Name: "Old", EntityIDs: []string{"light.a", "light.b"}}))
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "new", Name: "New", EntityIDs: []string{"light.c"}}))
+
+	require.NoError(t, s.ReassignEntities(ctx, "old", "new"))
+
+	restarted := reload(t, repo)
+	old, _ := restarted.GetEntitiesInRoom(ctx, "old")
+	moved, _ := restarted.GetEntitiesInRoom(ctx, "new")
+	assert.Empty(t, old)
+	assert.ElementsMatch(t, []string{"light.a", "light.b", "light.c"}, moved)
+
+	assert.Error(t, s.ReassignEntities(ctx, "old", "attic"))
+}
+
+func TestDeleteRoom(t *testing.T) {
+	repo := newMemoryRoomRepository()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "garage", Name: "Garage", EntityIDs: []string{"cover.door"}}))
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "porch", Name: "Porch", EntityIDs: []string{"light.porch"}}))
+
+	require.NoError(t, s.DeleteRoom(ctx, "garage"))
+	_, assigned := s.GetRoomForEntity("cover.door")
+	assert.False(t, assigned)
+	assert.Equal(t, map[string]string{"light.porch": "porch"}, repo.assignments)
+
+	restarted := reload(t, repo)
+	_, err := restarted.GetRoomByID(ctx, "garage")
+	assert.Error(t, err)
+	assert.Error(t, s.DeleteRoom(ctx, "garage"))
+}
+
+func TestLoadSkipsOrphanedAssignments(t *testing.T) {
+	repo := newMemoryRoomRepository()
+	ctx := context.Background()
+	require.NoError(t, newTestService(repo).CreateRoom(ctx, &types.PMARoom{ID: "den", Name: "Den"}))
+	repo.assignments["light.den"] = "den"
+	repo.assignments["light.gone"] = "demolished"
+
+	s := reload(t, repo)
+	_, assigned := s.GetRoomForEntity("light.gone")
+	assert.False(t, assigned)
+	assert.Equal(t, "demolished", repo.assignments["light.gone"], "orphans are left for the reconciler")
+
+	roomID, _ := s.GetRoomForEntity("light.den")
+	assert.Equal(t, "den", roomID)
+}
+
+func TestRoomFromModelFallsBackToRowID(t *testing.T) {
+	room := roomFromModel(&models.Room{ID: 7, Name: "Legacy"})
+	assert.Equal(t, "7", room.ID, "rooms created before room keys are addressed by their row ID")
+	assert.Nil(t, room.ParentID)
+	assert.Nil(t, room.Metadata)
+	assert.NotNil(t, room.EntityIDs)
+}
+
+func TestCreateRoomValidation(t *testing.T) {
+	s := newTestService(nil)
+	ctx := context.Background()
+
+	assert.Error(t, s.CreateRoom(ctx, &types.PMARoom{Name: "No ID"}))
+	assert.Error(t, s.CreateRoom(ctx, &types.PMARoom{ID: "unnamed"}))
+	require.NoError(t, s.CreateRoom(ctx, &types.PMARoom{ID: "loft", Name: "Loft"}))
+	assert.Error(t, s.CreateRoom(ctx, &types.PMARoom{ID: "loft", Name: "Loft"}))
+	assert.NoError(t, s.Load(ctx), "without a repository rooms stay in memory")
+}
@@ -0,0 +1,84 @@
+package rooms
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+// roomFromModel converts a stored room into a PMA room
+func roomFromModel(model *models.Room) *types.PMARoom {
+	room := &types.PMARoom{
+		ID:        model.RoomKey.String,
+		Name:      model.Name,
+		EntityIDs: make([]string, 0),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+	if room.ID == "" {
+		room.ID = roomKeyFromID(model.ID)
+	}
+	if model.Icon.Valid {
+		room.Icon = model.Icon.String
+	}
+	if model.Description.Valid {
+		room.Description = model.Description.String
+	}
+	if model.ParentKey.Valid {
+		parentID := model.ParentKey.String
+		room.ParentID = &parentID
+	}
+	if model.Metadata.Valid && model.Metadata.String != "" {
+		var metadata types.PMAMetadata
+		if err := json.Unmarshal([]byte(model.Metadata.String), &metadata); err == nil {
+			room.Metadata = &metadata
+		}
+	}
+	if model.Attributes.Valid && model.Attributes.String != "" {
+		_ = json.Unmarshal([]byte(model.Attributes.String), &room.Attributes)
+	}
+
+	return room
+}
+
+// applyRoomToModel copies the PMA room fields onto a stored room, keeping its area assignment
+func applyRoomToModel(room *types.PMARoom, model *models.Room) {
+	model.Name = room.Name
+	model.RoomKey = sql.NullString{String: room.ID, Valid: true}
+	model.Icon = nullString(room.Icon)
+	model.Description = nullString(room.Description)
+	model.ParentKey = sql.NullString{}
+	if room.ParentID != nil {
+		model.ParentKey = nullString(*room.ParentID)
+	}
+
+	model.Metadata = sql.NullString{}
+	if room.Metadata != nil {
+		if data, err := json.Marshal(room.Metadata); err == nil {
+			model.Metadata = sql.NullString{String: string(data), Valid: true}
+		}
+
+		// Rooms synced from Home Assistant remember their HA area for reconciliation
+		if room.Metadata.Source == types.SourceHomeAssistant && room.Metadata.SourceEntityID != "" {
+			model.HomeAssistantAreaID = sql.NullString{String: room.Metadata.SourceEntityID, Valid: true}
+		}
+	}
+
+	model.Attributes = sql.NullString{}
+	if len(room.Attributes) > 0 {
+		if data, err := json.Marshal(room.Attributes); err == nil {
+			model.Attributes = sql.NullString{String: string(data), Valid: true}
+		}
+	}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func roomKeyFromID(id int) string {
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,708 @@
+package shelly_firmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/shelly"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// WebSocket message types
+const (
+	MessageTypeFirmwareCheck  = "shelly_firmware_check"
+	MessageTypeRolloutUpdate  = "shelly_firmware_rollout"
+	MessageTypeDeviceProgress = "shelly_firmware_device"
+)
+
+const (
+	defaultMaxConcurrent      = 2
+	defaultUpdateTimeout      = 10 * time.Minute
+	checkConcurrency          = 5
+	firmwareRequestTimeout    = 30 * time.Second
+	updateStartupGracePeriod  = 20 * time.Second
+	progressPollInterval      = 10 * time.Second
+	windowPollInterval        = time.Minute
+	maxFinishedRolloutsToKeep = 20
+)
+
+// Rollout states
+const (
+	RolloutPending   = "pending"   // waiting for the maintenance window
+	RolloutRunning   = "running"   // updating devices
+	RolloutCompleted = "completed" // every device updated
+	RolloutFailed    = "failed"    // halted after a device failed
+	RolloutCancelled = "cancelled"
+)
+
+// Device update states
+const (
+	DeviceQueued    = "queued"
+	DeviceUpdating  = "updating"  // update requested, device is installing and rebooting
+	DeviceVerifying = "verifying" // device is back, checking version and health
+	DeviceSucceeded = "succeeded"
+	DeviceFailed    = "failed"
+	DeviceSkipped   = "skipped"
+)
+
+var (
+	// ErrRolloutNotFound is returned for unknown rollout IDs
+	ErrRolloutNotFound = errors.New("rollout not found")
+	// ErrRolloutFinished is returned when cancelling a rollout that already ended
+	ErrRolloutFinished = errors.New("rollout already finished")
+	// ErrNoDevices is returned when a rollout would not update any device
+	ErrNoDevices = errors.New("no devices to update")
+)
+
+// WSHub interface for WebSocket broadcasting
+type WSHub interface {
+	BroadcastToAll(messageType string, data interface{})
+}
+
+// firmwareClient is the part of the Shelly client used for firmware updates
+type firmwareClient interface {
+	GetDevice(deviceID string) *shelly.EnhancedShellyDevice
+	GetOnlineDevices() []*shelly.EnhancedShellyDevice
+	CheckFirmwareUpdate(ctx context.Context, device *shelly.EnhancedShellyDevice) (*shelly.FirmwareInfo, error)
+	StartFirmwareUpdate(ctx context.Context, device *shelly.EnhancedShellyDevice, stage string) error
+	GetFirmwareVersion(ctx context.Context, device *shelly.EnhancedShellyDevice) (string, error)
+	CheckDeviceHealth(ctx context.Context, device *shelly.EnhancedShellyDevice) bool
+}
+
+// MaintenanceWindow limits when updates may start, in local time. Windows may span midnight.
+type MaintenanceWindow struct {
+	Start string `json:"start"` // "HH:MM"
+	End   string `json:"end"`   // "HH:MM"
+}
+
+// RolloutRequest describes a rollout to start
+type RolloutRequest struct {
+	DeviceIDs         []string           `json:"device_ids"` // empty updates every outdated device
+	Stage             string             `json:"stage"`
+	MaxConcurrent     int                `json:"max_concurrent"`
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window"`
+	ContinueOnFailure bool               `json:"continue_on_failure"`
+}
+
+// DeviceUpdate tracks the update of one device in a rollout
+type DeviceUpdate struct {
+	DeviceID    string     `json:"device_id"`
+	Name        string     `json:"name"`
+	FromVersion string     `json:"from_version"`
+	ToVersion   string     `json:"to_version"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Rollout is a staged firmware update of several devices
+type Rollout struct {
+	ID                string             `json:"id"`
+	Status            string             `json:"status"`
+	Stage             string             `json:"stage"`
+	MaxConcurrent     int                `json:"max_concurrent"`
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty"`
+	ContinueOnFailure bool               `json:"continue_on_failure"`
+	Devices           []*DeviceUpdate    `json:"devices"`
+	Succeeded         int                `json:"succeeded"`
+	Failed            int                `json:"failed"`
+	CreatedAt         time.Time          `json:"created_at"`
+	StartedAt         *time.Time         `json:"started_at,omitempty"`
+	CompletedAt       *time.Time         `json:"completed_at,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// Service checks Shelly devices for firmware updates and runs rolling updates
+type Service struct {
+	clientMutex   sync.RWMutex
+	client        firmwareClient // Replaced when the Shelly adapter is reconfigured
+	wsHub         WSHub
+	logger        *logrus.Logger
+	stage         string
+	maxConcurrent int
+	window        *MaintenanceWindow
+	updateTimeout time.Duration
+	gracePeriod   time.Duration // wait before polling an updating device
+	pollInterval  time.Duration
+
+	mutex    sync.RWMutex
+	firmware map[string]*shelly.FirmwareInfo
+	rollouts map[string]*Rollout
+	ctx      context.Context
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewService creates a firmware service for the devices known to the client
+func NewService(cfg config.ShellyFirmwareConfig, client *shelly.ShellyClient, wsHub WSHub, logger *logrus.Logger) *Service {
+	s := &Service{
+		client:        client,
+		wsHub:         wsHub,
+		logger:        logger,
+		stage:         cfg.Stage,
+		maxConcurrent: cfg.MaxConcurrent,
+		updateTimeout: defaultUpdateTimeout,
+		gracePeriod:   updateStartupGracePeriod,
+		pollInterval:  progressPollInterval,
+		firmware:      make(map[string]*shelly.FirmwareInfo),
+		rollouts:      make(map[string]*Rollout),
+	}
+	if s.stage == "" {
+		s.stage = shelly.FirmwareStageStable
+	}
+	if s.maxConcurrent <= 0 {
+		s.maxConcurrent = defaultMaxConcurrent
+	}
+	if timeout, err := time.ParseDuration(cfg.UpdateTimeout); err == nil && timeout > 0 {
+		s.updateTimeout = timeout
+	}
+	if cfg.MaintenanceWindowStart != "" && cfg.MaintenanceWindowEnd != "" {
+		window := &MaintenanceWindow{Start: cfg.MaintenanceWindowStart, End: cfg.MaintenanceWindowEnd}
+		if err := window.validate(); err != nil {
+			logger.WithError(err).Warn("Ignoring invalid Shelly firmware maintenance window")
+		} else {
+			s.window = window
+		}
+	}
+
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	return s
+}
+
+// Stop cancels running rollouts and waits for them to end
+func (s *Service) Stop() {
+	s.stop()
+	s.wg.Wait()
+}
+
+// ListFirmware returns the result of the last update check of every device
+func (s *Service) ListFirmware() []*shelly.FirmwareInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]*shelly.FirmwareInfo, 0, len(s.firmware))
+	for _, info := range s.firmware {
+		copied := *info
+		list = append(list, &copied)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].DeviceID < list[j].DeviceID })
+	return list
+}
+
+// CheckUpdates asks devices for available updates. Without device IDs every online device is
+// checked. Devices that cannot be reached are reported with an error.
+func (s *Service) CheckUpdates(ctx context.Context, deviceIDs []string) []*shelly.FirmwareInfo {
+	devices := s.resolveDevices(deviceIDs)
+
+	results := make([]*shelly.FirmwareInfo, len(devices))
+	sem := make(chan struct{}, checkConcurrency)
+	var wg sync.WaitGroup
+
+	for i, device := range devices {
+		wg.Add(1)
+		go func(i int, device *shelly.EnhancedShellyDevice) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			checkCtx, cancel := context.WithTimeout(ctx, firmwareRequestTimeout)
+			defer cancel()
+
+			info, err := s.getClient().CheckFirmwareUpdate(checkCtx, device)
+			if err != nil {
+				info = &shelly.FirmwareInfo{
+					DeviceID:       device.ID,
+					Name:           device.Name,
+					Model:          device.Model,
+					IP:             device.IP,
+					Generation:     device.Generation,
+					CurrentVersion: device.FirmwareVersion,
+					Error:          err.Error(),
+					CheckedAt:      time.Now(),
+				}
+			}
+			results[i] = info
+		}(i, device)
+	}
+	wg.Wait()
+
+	s.mutex.Lock()
+	for _, info := range results {
+		s.firmware[info.DeviceID] = info
+	}
+	s.mutex.Unlock()
+
+	outdated := 0
+	for _, info := range results {
+		if info.UpdateAvailable {
+			outdated++
+		}
+	}
+	s.logger.WithFields(logrus.Fields{"checked": len(results), "outdated": outdated}).Info("Checked Shelly firmware")
+	s.broadcast(MessageTypeFirmwareCheck, map[string]interface{}{
+		"devices":  results,
+		"checked":  len(results),
+		"outdated": outdated,
+	})
+
+	return results
+}
+
+// StartRollout stages a rolling update. Devices without a known update are checked first;
+// devices already on the target version are skipped.
+func (s *Service) StartRollout(ctx context.Context, req RolloutRequest) (*Rollout, error) {
+	stage := req.Stage
+	if stage == "" {
+		stage = s.stage
+	}
+	if stage != shelly.FirmwareStageStable && stage != shelly.FirmwareStageBeta {
+		return nil, fmt.Errorf("invalid stage %q", stage)
+	}
+
+	maxConcurrent := req.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = s.maxConcurrent
+	}
+
+	window := req.MaintenanceWindow
+	if window == nil {
+		window = s.window
+	} else if err := window.validate(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range req.DeviceIDs {
+		if s.getClient().GetDevice(id) == nil {
+			return nil, fmt.Errorf("device not found: %s", id)
+		}
+	}
+
+	// Refresh the update information of the requested devices
+	infos := s.CheckUpdates(ctx, req.DeviceIDs)
+
+	rollout := &Rollout{
+		ID:                uuid.New().String(),
+		Status:            RolloutPending,
+		Stage:             stage,
+		MaxConcurrent:     maxConcurrent,
+		MaintenanceWindow: window,
+		ContinueOnFailure: req.ContinueOnFailure,
+		CreatedAt:         time.Now(),
+	}
+	for _, info := range infos {
+		target := info.AvailableVersion(stage)
+		if info.Error != "" || target == "" || target == info.CurrentVersion {
+			continue
+		}
+		rollout.Devices = append(rollout.Devices, &DeviceUpdate{
+			DeviceID:    info.DeviceID,
+			Name:        info.Name,
+			FromVersion: info.CurrentVersion,
+			ToVersion:   target,
+			Status:      DeviceQueued,
+		})
+	}
+	if len(rollout.Devices) == 0 {
+		return nil, ErrNoDevices
+	}
+	sort.Slice(rollout.Devices, func(i, j int) bool { return rollout.Devices[i].DeviceID < rollout.Devices[j].DeviceID })
+
+	var rolloutCtx context.Context
+	rolloutCtx, rollout.cancel = context.WithCancel(s.ctx)
+
+	s.mutex.Lock()
+	s.rollouts[rollout.ID] = rollout
+	s.pruneRollouts()
+	s.mutex.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"rollout_id":     rollout.ID,
+		"devices":        len(rollout.Devices),
+		"stage":          stage,
+		"max_concurrent": maxConcurrent,
+	}).Info("Staged Shelly firmware rollout")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runRollout(rolloutCtx, rollout)
+	}()
+
+	return s.GetRollout(rollout.ID)
+}
+
+// GetRollout returns a snapshot of a rollout
+func (s *Service) GetRollout(id string) (*Rollout, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rollout, ok := s.rollouts[id]
+	if !ok {
+		return nil, ErrRolloutNotFound
+	}
+	return rollout.snapshot(), nil
+}
+
+// ListRollouts returns snapshots of all rollouts, newest first
+func (s *Service) ListRollouts() []*Rollout {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]*Rollout, 0, len(s.rollouts))
+	for _, rollout := range s.rollouts {
+		list = append(list, rollout.snapshot())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// CancelRollout stops a rollout. Updates a device already installs are not interrupted.
+func (s *Service) CancelRollout(id string) (*Rollout, error) {
+	s.mutex.Lock()
+	rollout, ok := s.rollouts[id]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrRolloutNotFound
+	}
+	if rollout.finished() {
+		s.mutex.Unlock()
+		return nil, ErrRolloutFinished
+	}
+	rollout.cancel()
+	s.mutex.Unlock()
+
+	s.logger.WithField("rollout_id", id).Info("Cancelling Shelly firmware rollout")
+	return s.GetRollout(id)
+}
+
+// runRollout waits for the maintenance window and updates the devices with limited concurrency
+func (s *Service) runRollout(ctx context.Context, rollout *Rollout) {
+	s.broadcastRollout(rollout)
+
+	if rollout.MaintenanceWindow != nil {
+		ticker := time.NewTicker(windowPollInterval)
+		for !rollout.MaintenanceWindow.Contains(time.Now()) {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				s.finishRollout(rollout, RolloutCancelled)
+				return
+			case <-ticker.C:
+			}
+		}
+		ticker.Stop()
+	}
+
+	s.mutex.Lock()
+	now := time.Now()
+	rollout.Status = RolloutRunning
+	rollout.StartedAt = &now
+	s.mutex.Unlock()
+	s.broadcastRollout(rollout)
+
+	// Without ContinueOnFailure a failed device stops further updates; updates in progress finish
+	haltCtx, halt := context.WithCancel(ctx)
+	defer halt()
+
+	sem := make(chan struct{}, rollout.MaxConcurrent)
+	var wg sync.WaitGroup
+
+	for _, update := range rollout.Devices {
+		select {
+		case sem <- struct{}{}:
+		case <-haltCtx.Done():
+		}
+		if haltCtx.Err() != nil {
+			s.setDeviceStatus(rollout, update, DeviceSkipped, "")
+			continue
+		}
+		if !s.withinWindow(rollout) {
+			// Remaining devices wait for the next rollout
+			<-sem
+			s.setDeviceStatus(rollout, update, DeviceSkipped, "outside maintenance window")
+			continue
+		}
+
+		wg.Add(1)
+		go func(update *DeviceUpdate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := s.updateDevice(ctx, rollout, update); err != nil && !rollout.ContinueOnFailure {
+				halt()
+			}
+		}(update)
+	}
+	wg.Wait()
+
+	status := RolloutCompleted
+	switch {
+	case ctx.Err() != nil:
+		status = RolloutCancelled
+	case rollout.Failed > 0 && !rollout.ContinueOnFailure:
+		status = RolloutFailed
+	}
+	s.finishRollout(rollout, status)
+}
+
+// updateDevice installs the update on one device and waits until it is back and healthy on the
+// new version
+func (s *Service) updateDevice(ctx context.Context, rollout *Rollout, update *DeviceUpdate) error {
+	device := s.getClient().GetDevice(update.DeviceID)
+	if device == nil {
+		err := fmt.Errorf("device no longer known")
+		s.setDeviceStatus(rollout, update, DeviceFailed, err.Error())
+		return err
+	}
+
+	s.setDeviceStatus(rollout, update, DeviceUpdating, "")
+
+	startCtx, cancel := context.WithTimeout(ctx, firmwareRequestTimeout)
+	err := s.getClient().StartFirmwareUpdate(startCtx, device, rollout.Stage)
+	cancel()
+	if err != nil {
+		s.setDeviceStatus(rollout, update, DeviceFailed, err.Error())
+		return err
+	}
+
+	if err := s.waitForUpdate(ctx, rollout, update, device); err != nil {
+		s.setDeviceStatus(rollout, update, DeviceFailed, err.Error())
+		return err
+	}
+
+	s.setDeviceStatus(rollout, update, DeviceSucceeded, "")
+
+	s.mutex.Lock()
+	if info, ok := s.firmware[update.DeviceID]; ok {
+		info.CurrentVersion = update.ToVersion
+		info.UpdateAvailable = false
+		info.StableVersion = ""
+		if info.BetaVersion == update.ToVersion {
+			info.BetaVersion = ""
+		}
+	}
+	s.mutex.Unlock()
+	return nil
+}
+
+// waitForUpdate polls the device until it reports the target version and passes a health check
+func (s *Service) waitForUpdate(ctx context.Context, rollout *Rollout, update *DeviceUpdate, device *shelly.EnhancedShellyDevice) error {
+	deadline := time.NewTimer(s.updateTimeout)
+	defer deadline.Stop()
+
+	// The device keeps answering with the old version until it reboots
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-deadline.C:
+		return fmt.Errorf("update timed out")
+	case <-time.After(s.gracePeriod):
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	verifying := false
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, firmwareRequestTimeout)
+		version, err := s.getClient().GetFirmwareVersion(checkCtx, device)
+		if err == nil && version != update.FromVersion {
+			if !verifying {
+				verifying = true
+				s.setDeviceStatus(rollout, update, DeviceVerifying, "")
+			}
+			if s.getClient().CheckDeviceHealth(checkCtx, device) {
+				cancel()
+				s.mutex.Lock()
+				update.ToVersion = version
+				s.mutex.Unlock()
+				return nil
+			}
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			if verifying {
+				return fmt.Errorf("device failed health check after update")
+			}
+			return fmt.Errorf("device did not come back with new firmware within %s", s.updateTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) withinWindow(rollout *Rollout) bool {
+	return rollout.MaintenanceWindow == nil || rollout.MaintenanceWindow.Contains(time.Now())
+}
+
+func (s *Service) setDeviceStatus(rollout *Rollout, update *DeviceUpdate, status, message string) {
+	s.mutex.Lock()
+	now := time.Now()
+	update.Status = status
+	update.Error = message
+	switch status {
+	case DeviceUpdating:
+		update.StartedAt = &now
+	case DeviceSucceeded:
+		update.CompletedAt = &now
+		rollout.Succeeded++
+	case DeviceFailed:
+		update.CompletedAt = &now
+		rollout.Failed++
+	}
+	progress := *update
+	s.mutex.Unlock()
+
+	fields := logrus.Fields{"rollout_id": rollout.ID, "device_id": update.DeviceID, "status": status}
+	if status == DeviceFailed {
+		s.logger.WithFields(fields).WithField("error", message).Warn("Shelly firmware update failed")
+	} else {
+		s.logger.WithFields(fields).Info("Shelly firmware update progress")
+	}
+
+	s.broadcast(MessageTypeDeviceProgress, map[string]interface{}{
+		"rollout_id": rollout.ID,
+		"device":     progress,
+	})
+}
+
+func (s *Service) finishRollout(rollout *Rollout, status string) {
+	s.mutex.Lock()
+	now := time.Now()
+	rollout.Status = status
+	rollout.CompletedAt = &now
+	for _, update := range rollout.Devices {
+		if update.Status == DeviceQueued {
+			update.Status = DeviceSkipped
+		}
+	}
+	s.mutex.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"rollout_id": rollout.ID,
+		"status":     status,
+		"succeeded":  rollout.Succeeded,
+		"failed":     rollout.Failed,
+	}).Info("Shelly firmware rollout finished")
+	s.broadcastRollout(rollout)
+}
+
+func (s *Service) broadcastRollout(rollout *Rollout) {
+	s.mutex.RLock()
+	snapshot := rollout.snapshot()
+	s.mutex.RUnlock()
+	s.broadcast(MessageTypeRolloutUpdate, snapshot)
+}
+
+func (s *Service) broadcast(messageType string, data interface{}) {
+	if s.wsHub != nil {
+		s.wsHub.BroadcastToAll(messageType, data)
+	}
+}
+
+// SetClient switches to the client of a reconfigured Shelly adapter
+func (s *Service) SetClient(client *shelly.ShellyClient) {
+	s.clientMutex.Lock()
+	defer s.clientMutex.Unlock()
+	s.client = client
+}
+
+func (s *Service) getClient() firmwareClient {
+	s.clientMutex.RLock()
+	defer s.clientMutex.RUnlock()
+	return s.client
+}
+
+// resolveDevices returns the requested devices, or every online device
+func (s *Service) resolveDevices(deviceIDs []string) []*shelly.EnhancedShellyDevice {
+	if len(deviceIDs) == 0 {
+		return s.getClient().GetOnlineDevices()
+	}
+
+	devices := make([]*shelly.EnhancedShellyDevice, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		if device := s.getClient().GetDevice(id); device != nil {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
+// pruneRollouts drops the oldest finished rollouts; callers hold the mutex
+func (s *Service) pruneRollouts() {
+	finished := make([]*Rollout, 0, len(s.rollouts))
+	for _, rollout := range s.rollouts {
+		if rollout.finished() {
+			finished = append(finished, rollout)
+		}
+	}
+	if len(finished) <= maxFinishedRolloutsToKeep {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CreatedAt.Before(finished[j].CreatedAt) })
+	for _, rollout := range finished[:len(finished)-maxFinishedRolloutsToKeep] {
+		delete(s.rollouts, rollout.ID)
+	}
+}
+
+// snapshot copies the rollout; callers hold the service mutex
+func (r *Rollout) snapshot() *Rollout {
+	copied := *r
+	copied.cancel = nil
+	copied.Devices = make([]*DeviceUpdate, len(r.Devices))
+	for i, update := range r.Devices {
+		u := *update
+		copied.Devices[i] = &u
+	}
+	return &copied
+}
+
+func (r *Rollout) finished() bool {
+	return r.Status == RolloutCompleted || r.Status == RolloutFailed || r.Status == RolloutCancelled
+}
+
+// Contains reports whether t falls inside the window
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	start, errStart := parseClock(w.Start)
+	end, errEnd := parseClock(w.End)
+	if errStart != nil || errEnd != nil {
+		return true
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+func (w *MaintenanceWindow) validate() error {
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("invalid maintenance window start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("invalid maintenance window end: %w", err)
+	}
+	return nil
+}
+
+// parseClock returns the minutes since midnight of an "HH:MM" time
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
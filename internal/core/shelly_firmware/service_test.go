@@ -0,0 +1,341 @@
+package shelly_firmware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/shelly"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDevice is a device of the fake client
+type fakeDevice struct {
+	version  string
+	stable   string
+	beta     string
+	checkErr error
+	startErr error
+	stuck    bool // keeps the old version after an update
+	started  bool
+}
+
+// fakeClient emulates devices that install an update and come back on the new version
+type fakeClient struct {
+	mu       sync.Mutex
+	devices  map[string]*fakeDevice
+	started  []string
+	inFlight int
+	peak     int
+}
+
+func newFakeClient(devices map[string]*fakeDevice) *fakeClient {
+	return &fakeClient{devices: devices}
+}
+
+func (c *fakeClient) GetDevice(deviceID string) *shelly.EnhancedShellyDevice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.devices[deviceID]; !ok {
+		return nil
+	}
+	return &shelly.EnhancedShellyDevice{ID: deviceID, Name: deviceID, IsOnline: true}
+}
+
+func (c *fakeClient) GetOnlineDevices() []*shelly.EnhancedShellyDevice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	devices := make([]*shelly.EnhancedShellyDevice, 0, len(c.devices))
+	for id := range c.devices {
+		devices = append(devices, &shelly.EnhancedShellyDevice{ID: id, Name: id, IsOnline: true})
+	}
+	return devices
+}
+
+func (c *fakeClient) CheckFirmwareUpdate(ctx context.Context, device *shelly.EnhancedShellyDevice) (*shelly.FirmwareInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := c.devices[device.ID]
+	if d.checkErr != nil {
+		return nil, d.checkErr
+	}
+	return &shelly.FirmwareInfo{
+		DeviceID:        device.ID,
+		Name:            device.Name,
+		CurrentVersion:  d.version,
+		StableVersion:   d.stable,
+		BetaVersion:     d.beta,
+		UpdateAvailable: d.stable != "",
+		CheckedAt:       time.Now(),
+	}, nil
+}
+
+func (c *fakeClient) StartFirmwareUpdate(ctx context.Context, device *shelly.EnhancedShellyDevice, stage string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = append(c.started, device.ID)
+	d := c.devices[device.ID]
+	if d.startErr != nil {
+		return d.startErr
+	}
+	d.started = true
+	c.inFlight++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	return nil
+}
+
+func (c *fakeClient) GetFirmwareVersion(ctx context.Context, device *shelly.EnhancedShellyDevice) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := c.devices[device.ID]
+	if d.started && !d.stuck {
+		d.started = false
+		c.inFlight--
+		d.version = d.stable
+	}
+	return d.version, nil
+}
+
+func (c *fakeClient) CheckDeviceHealth(ctx context.Context, device *shelly.EnhancedShellyDevice) bool {
+	return true
+}
+
+func (c *fakeClient) startOrder() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.started...)
+}
+
+func newTestService(t *testing.T, client *fakeClient) *Service {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	s := NewService(config.ShellyFirmwareConfig{UpdateTimeout: "200ms"}, nil, nil, logger)
+	s.client = client
+	s.gracePeriod = 5 * time.Millisecond
+	s.pollInterval = time.Millisecond
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// waitFinished waits for a rollout to end and returns its final state
+func waitFinished(t *testing.T, s *Service, id string) *Rollout {
+	t.Helper()
+	var rollout *Rollout
+	require.Eventually(t, func() bool {
+		var err error
+		rollout, err = s.GetRollout(id)
+		return err == nil && rollout.finished()
+	}, 5*time.Second, 5*time.Millisecond)
+	return rollout
+}
+
+func deviceStatuses(rollout *Rollout) map[string]string {
+	statuses := make(map[string]string, len(rollout.Devices))
+	for _, update := range rollout.Devices {
+		statuses[update.DeviceID] = update.Status
+	}
+	return statuses
+}
+
+func TestStartRolloutSelectsDevices(t *testing.T) {
+	client := newFakeClient(map[string]*fakeDevice{
+		"shelly-c":       {version: "1.0.0", stable: "1.1.0"},
+		"shelly-a":       {version: "1.0.0", stable: "1.1.0", beta: "1.2.0-beta1"},
+		"shelly-current": {version: "1.1.0"},
+		"shelly-offline": {checkErr: errors.New("unreachable")},
+		"shelly-b":       {version: "1.0.0", beta: "1.2.0-beta1"},
+	})
+	s := newTestService(t, client)
+	// Keep the rollout pending so the plan can be inspected
+	s.window = excludingNow()
+
+	rollout, err := s.StartRollout(context.Background(), RolloutRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, RolloutPending, rollout.Status)
+	require.Len(t, rollout.Devices, 2, "devices without an update or unreachable are left out")
+	assert.Equal(t, "shelly-a", rollout.Devices[0].DeviceID, "devices are updated in ID order")
+	assert.Equal(t, "1.1.0", rollout.Devices[0].ToVersion)
+	assert.Equal(t, "shelly-c", rollout.Devices[1].DeviceID)
+
+	beta, err := s.StartRollout(context.Background(), RolloutRequest{Stage: shelly.FirmwareStageBeta})
+	require.NoError(t, err)
+	require.Len(t, beta.Devices, 3)
+	assert.Equal(t, []string{"shelly-a", "shelly-b", "shelly-c"}, []string{beta.Devices[0].DeviceID, beta.Devices[1].DeviceID, beta.Devices[2].DeviceID})
+	assert.Equal(t, "1.2.0-beta1", beta.Devices[1].ToVersion)
+	assert.Equal(t, "1.1.0", beta.Devices[2].ToVersion, "stable is used when there is no beta")
+
+	_, err = s.StartRollout(context.Background(), RolloutRequest{DeviceIDs: []string{"shelly-current"}})
+	assert.ErrorIs(t, err, ErrNoDevices)
+	_, err = s.StartRollout(context.Background(), RolloutRequest{DeviceIDs: []string{"shelly-unknown"}})
+	assert.Error(t, err)
+	_, err = s.StartRollout(context.Background(), RolloutRequest{Stage: "nightly"})
+	assert.Error(t, err)
+	_, err = s.StartRollout(context.Background(), RolloutRequest{MaintenanceWindow: &MaintenanceWindow{Start: "25:00", End: "03:00"}})
+	assert.Error(t, err)
+}
+
+func TestRolloutUpdatesInOrder(t *testing.T) {
+	client := newFakeClient(map[string]*fakeDevice{
+		"shelly-3": {version: "1.0.0", stable: "1.1.0"},
+		"shelly-1": {version: "1.0.0", stable: "1.1.0"},
+		"shelly-2": {version: "1.0.0", stable: "1.1.0"},
+	})
+	s := newTestService(t, client)
+
+	started, err := s.StartRollout(context.Background(), RolloutRequest{MaxConcurrent: 1})
+	require.NoError(t, err)
+
+	rollout := waitFinished(t, s, started.ID)
+	assert.Equal(t, RolloutCompleted, rollout.Status)
+	assert.Equal(t, 3, rollout.Succeeded)
+	assert.Equal(t, []string{"shelly-1", "shelly-2", "shelly-3"}, client.startOrder())
+	for _, update := range rollout.Devices {
+		assert.Equal(t, DeviceSucceeded, update.Status)
+		assert.NotNil(t, update.StartedAt)
+		assert.NotNil(t, update.CompletedAt)
+	}
+
+	for _, info := range s.ListFirmware() {
+		assert.Equal(t, "1.1.0", info.CurrentVersion)
+		assert.False(t, info.UpdateAvailable)
+	}
+}
+
+func TestRolloutConcurrencyLimit(t *testing.T) {
+	devices := make(map[string]*fakeDevice)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		devices[id] = &fakeDevice{version: "1.0.0", stable: "1.1.0"}
+	}
+	client := newFakeClient(devices)
+	s := newTestService(t, client)
+	s.gracePeriod = 20 * time.Millisecond
+
+	started, err := s.StartRollout(context.Background(), RolloutRequest{MaxConcurrent: 2})
+	require.NoError(t, err)
+
+	rollout := waitFinished(t, s, started.ID)
+	assert.Equal(t, RolloutCompleted, rollout.Status)
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	assert.Equal(t, 2, client.peak)
+}
+
+func TestRolloutFailure(t *testing.T) {
+	newClient := func() *fakeClient {
+		return newFakeClient(map[string]*fakeDevice{
+			"shelly-1": {version: "1.0.0", stable: "1.1.0"},
+			"shelly-2": {version: "1.0.0", stable: "1.1.0", startErr: errors.New("busy")},
+			"shelly-3": {version: "1.0.0", stable: "1.1.0"},
+		})
+	}
+
+	t.Run("halts the rollout", func(t *testing.T) {
+		client := newClient()
+		s := newTestService(t, client)
+
+		started, err := s.StartRollout(context.Background(), RolloutRequest{MaxConcurrent: 1})
+		require.NoError(t, err)
+
+		rollout := waitFinished(t, s, started.ID)
+		assert.Equal(t, RolloutFailed, rollout.Status)
+		assert.Equal(t, map[string]string{
+			"shelly-1": DeviceSucceeded,
+			"shelly-2": DeviceFailed,
+			"shelly-3": DeviceSkipped,
+		}, deviceStatuses(rollout))
+		assert.Equal(t, "busy", rollout.Devices[1].Error)
+		assert.Equal(t, []string{"shelly-1", "shelly-2"}, client.startOrder(), "nothing starts after a failure")
+	})
+
+	t.Run("continues on failure", func(t *testing.T) {
+		client := newClient()
+		s := newTestService(t, client)
+
+		started, err := s.StartRollout(context.Background(), RolloutRequest{MaxConcurrent: 1, ContinueOnFailure: true})
+		require.NoError(t, err)
+
+		rollout := waitFinished(t, s, started.ID)
+		assert.Equal(t, RolloutCompleted, rollout.Status)
+		assert.Equal(t, 2, rollout.Succeeded)
+		assert.Equal(t, 1, rollout.Failed)
+		assert.Equal(t, []string{"shelly-1", "shelly-2", "shelly-3"}, client.startOrder())
+	})
+
+	t.Run("device does not come back", func(t *testing.T) {
+		client := newFakeClient(map[string]*fakeDevice{"shelly-1": {version: "1.0.0", stable: "1.1.0", stuck: true}})
+		s := newTestService(t, client)
+
+		started, err := s.StartRollout(context.Background(), RolloutRequest{})
+		require.NoError(t, err)
+
+		rollout := waitFinished(t, s, started.ID)
+		assert.Equal(t, RolloutFailed, rollout.Status)
+		assert.Contains(t, rollout.Devices[0].Error, "did not come back")
+	})
+}
+
+func TestCancelRollout(t *testing.T) {
+	client := newFakeClient(map[string]*fakeDevice{"shelly-1": {version: "1.0.0", stable: "1.1.0"}})
+	s := newTestService(t, client)
+
+	started, err := s.StartRollout(context.Background(), RolloutRequest{MaintenanceWindow: excludingNow()})
+	require.NoError(t, err)
+	assert.Equal(t, RolloutPending, started.Status, "the rollout waits for its window")
+
+	_, err = s.CancelRollout(started.ID)
+	require.NoError(t, err)
+
+	rollout := waitFinished(t, s, started.ID)
+	assert.Equal(t, RolloutCancelled, rollout.Status)
+	assert.Equal(t, DeviceSkipped, rollout.Devices[0].Status)
+	assert.Empty(t, client.startOrder())
+
+	_, err = s.CancelRollout(started.ID)
+	assert.ErrorIs(t, err, ErrRolloutFinished)
+	_, err = s.CancelRollout("missing")
+	assert.ErrorIs(t, err, ErrRolloutNotFound)
+}
+
+func TestMaintenanceWindowContains(t *testing.T) {
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("15:04", clock)
+		return t
+	}
+
+	tests := []struct {
+		window MaintenanceWindow
+		time   string
+		want   bool
+	}{
+		{MaintenanceWindow{Start: "02:00", End: "05:00"}, "02:00", true},
+		{MaintenanceWindow{Start: "02:00", End: "05:00"}, "04:59", true},
+		{MaintenanceWindow{Start: "02:00", End: "05:00"}, "05:00", false},
+		{MaintenanceWindow{Start: "02:00", End: "05:00"}, "01:59", false},
+		{MaintenanceWindow{Start: "23:00", End: "03:00"}, "23:30", true},
+		{MaintenanceWindow{Start: "23:00", End: "03:00"}, "00:15", true},
+		{MaintenanceWindow{Start: "23:00", End: "03:00"}, "12:00", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.window.Contains(at(tt.time)), "%s-%s at %s", tt.window.Start, tt.window.End, tt.time)
+	}
+}
+
+// excludingNow returns a maintenance window that starts in two hours
+func excludingNow() *MaintenanceWindow {
+	now := time.Now()
+	return &MaintenanceWindow{
+		Start: now.Add(2 * time.Hour).Format("15:04"),
+		End:   now.Add(3 * time.Hour).Format("15:04"),
+	}
+}
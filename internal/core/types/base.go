@@ -13,6 +13,8 @@ const (
 	SourceShelly        PMASourceType = "shelly"
 	SourceUPS           PMASourceType = "ups"
 	SourceNetwork       PMASourceType = "network"
+	SourceBLE           PMASourceType = "ble"
+	SourceHelper        PMASourceType = "helper"
 	SourcePMA           PMASourceType = "pma"
 )
 
@@ -32,6 +34,13 @@ const (
 	EntityTypeBinarySensor PMAEntityType = "binary_sensor"
 	EntityTypeDevice       PMAEntityType = "device"
 	EntityTypeGeneric      PMAEntityType = "generic"
+	EntityTypePerson       PMAEntityType = "person"
+	EntityTypeInputBoolean PMAEntityType = "input_boolean"
+	EntityTypeInputNumber  PMAEntityType = "input_number"
+	EntityTypeInputSelect  PMAEntityType = "input_select"
+	EntityTypeInputText    PMAEntityType = "input_text"
+	EntityTypeCounter      PMAEntityType = "counter"
+	EntityTypeTimer        PMAEntityType = "timer"
 )
 
 // PMAEntityState represents the possible states of an entity
@@ -46,8 +55,11 @@ const (
 	StateUnlocked    PMAEntityState = "unlocked"
 	StateIdle        PMAEntityState = "idle"
 	StateActive      PMAEntityState = "active"
+	StatePaused      PMAEntityState = "paused"
 	StateUnavailable PMAEntityState = "unavailable"
 	StateUnknown     PMAEntityState = "unknown"
+	StateHome        PMAEntityState = "home"
+	StateNotHome     PMAEntityState = "not_home"
 )
 
 // PMACapability represents capabilities that entities can support
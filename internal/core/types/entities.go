@@ -77,6 +77,9 @@ func (e *PMABaseEntity) GetDeviceID() *string                  { return e.Device
 func (e *PMABaseEntity) GetMetadata() *PMAMetadata             { return e.Metadata }
 func (e *PMABaseEntity) IsAvailable() bool                     { return e.Available }
 
+// GetBaseEntity returns the base of an entity; entity types embedding PMABaseEntity inherit it
+func (e *PMABaseEntity) GetBaseEntity() *PMABaseEntity { return e }
+
 func (e *PMABaseEntity) GetSource() PMASourceType {
 	if e.Metadata != nil {
 		return e.Metadata.Source
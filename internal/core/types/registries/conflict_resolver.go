@@ -112,6 +112,7 @@ func (r *DefaultConflictResolver) ResolvePrioritySource(sources []types.PMASourc
 		priorityOrder := []types.PMASourceType{
 			types.SourceShelly,        // Specialized sensor devices
 			types.SourceUPS,           // Power sensors
+			types.SourceBLE,           // Passive BLE sensors
 			types.SourceHomeAssistant, // General sensors
 			types.SourceNetwork,       // Network sensors
 		}
@@ -42,6 +42,8 @@ func NewDefaultSourcePriorityManager(logger *logrus.Logger) *DefaultSourcePriori
 		types.SourceShelly:        3,  // Smart switches/devices
 		types.SourceUPS:           4,  // Power management
 		types.SourceNetwork:       5,  // Network devices
+		types.SourceBLE:           6,  // Passive BLE sensors
+		types.SourceHelper:        9,  // Helper entities
 		types.SourcePMA:           10, // Virtual/computed entities
 	}
 
@@ -177,6 +179,8 @@ func (m *DefaultSourcePriorityManager) ResetToDefaults() {
 		types.SourceShelly:        3,
 		types.SourceUPS:           4,
 		types.SourceNetwork:       5,
+		types.SourceBLE:           6,
+		types.SourceHelper:        9,
 		types.SourcePMA:           10,
 	}
 
@@ -239,6 +243,8 @@ func (m *DefaultSourcePriorityManager) IsValidSource(source types.PMASourceType)
 		types.SourceShelly:        true,
 		types.SourceUPS:           true,
 		types.SourceNetwork:       true,
+		types.SourceBLE:           true,
+		types.SourceHelper:        true,
 		types.SourcePMA:           true,
 	}
 
@@ -330,6 +330,7 @@ func (r *PMATypeRegistry) getEntityTypeDescription(entityType PMAEntityType) str
 		EntityTypeBinarySensor: "Binary sensor providing true/false state information",
 		EntityTypeDevice:       "Generic device with basic control capabilities",
 		EntityTypeGeneric:      "Generic entity with basic PMA functionality",
+		EntityTypePerson:       "Person whose home, away or zone state is derived from presence trackers",
 	}
 
 	if desc, exists := descriptions[entityType]; exists {
@@ -353,6 +354,7 @@ func (r *PMATypeRegistry) getDefaultCapabilities(entityType PMAEntityType) []PMA
 		EntityTypeBinarySensor: {CapabilityConnectivity},
 		EntityTypeDevice:       {CapabilityConnectivity},
 		EntityTypeGeneric:      {},
+		EntityTypePerson:       {},
 	}
 
 	if caps, exists := capabilities[entityType]; exists {
@@ -376,6 +378,7 @@ func (r *PMATypeRegistry) getDefaultActions(entityType PMAEntityType) []string {
 		EntityTypeBinarySensor: {},
 		EntityTypeDevice:       {"turn_on", "turn_off"},
 		EntityTypeGeneric:      {"turn_on", "turn_off"},
+		EntityTypePerson:       {},
 	}
 
 	if acts, exists := actions[entityType]; exists {
@@ -0,0 +1,518 @@
+package unified
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+// Entity customization errors
+var (
+	ErrCustomizationNotFound = errors.New("customization not found")
+	ErrInvalidCustomization  = errors.New("invalid customization")
+)
+
+// customizableTypes are the entity types an entity can be shown as, e.g. a switch driving a light.
+// Actions still go to the source under the original entity ID.
+var customizableTypes = map[types.PMAEntityType]bool{
+	types.EntityTypeLight:        true,
+	types.EntityTypeSwitch:       true,
+	types.EntityTypeFan:          true,
+	types.EntityTypeCover:        true,
+	types.EntityTypeLock:         true,
+	types.EntityTypeSensor:       true,
+	types.EntityTypeBinarySensor: true,
+	types.EntityTypeGeneric:      true,
+}
+
+// baseEntity is implemented by every entity embedding types.PMABaseEntity
+type baseEntity interface {
+	GetBaseEntity() *types.PMABaseEntity
+}
+
+// entityOriginal holds the values of the source that a customization replaced, so they can be
+// restored when the customization changes
+type entityOriginal struct {
+	entity       types.PMAEntity // the customized instance
+	friendlyName string
+	icon         string
+	entityType   types.PMAEntityType
+	state        types.PMAEntityState
+	attributes   map[string]interface{}
+	available    bool
+	unit         string // unit of the source, from the unit_of_measurement attribute or the sensor
+	deviceClass  string
+}
+
+// customizationState holds the loaded customizations and the original values of customized
+// entities
+type customizationState struct {
+	mutex     sync.RWMutex
+	repo      repositories.EntityCustomizationRepository
+	byEntity  map[string]*models.EntityCustomization
+	originals map[string]*entityOriginal
+}
+
+// SetCustomizationRepository sets the repository entity customizations are stored in
+func (s *UnifiedEntityService) SetCustomizationRepository(repo repositories.EntityCustomizationRepository) {
+	s.customizations.mutex.Lock()
+	defer s.customizations.mutex.Unlock()
+	s.customizations.repo = repo
+}
+
+// LoadCustomizations loads the stored customizations and applies them to the registered
+// entities. Entities synced later are customized as they are registered.
+func (s *UnifiedEntityService) LoadCustomizations(ctx context.Context) error {
+	cs := &s.customizations
+	if cs.repo == nil {
+		return fmt.Errorf("customization repository not configured")
+	}
+
+	customizations, err := cs.repo.ListCustomizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load customizations: %w", err)
+	}
+
+	cs.mutex.Lock()
+	cs.byEntity = make(map[string]*models.EntityCustomization, len(customizations))
+	for _, customization := range customizations {
+		cs.byEntity[customization.EntityID] = customization
+	}
+	if cs.originals == nil {
+		cs.originals = make(map[string]*entityOriginal)
+	}
+	cs.mutex.Unlock()
+
+	for _, customization := range customizations {
+		s.reapplyCustomization(ctx, customization.EntityID)
+	}
+
+	s.logger.WithField("customizations", len(customizations)).Info("Entity customizations loaded")
+	return nil
+}
+
+// ListCustomizations returns all entity customizations
+func (s *UnifiedEntityService) ListCustomizations() []*models.EntityCustomization {
+	cs := &s.customizations
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	customizations := make([]*models.EntityCustomization, 0, len(cs.byEntity))
+	for _, customization := range cs.byEntity {
+		copied := *customization
+		customizations = append(customizations, &copied)
+	}
+	sort.Slice(customizations, func(i, j int) bool {
+		return customizations[i].EntityID < customizations[j].EntityID
+	})
+	return customizations
+}
+
+// GetCustomization returns the customization of an entity
+func (s *UnifiedEntityService) GetCustomization(entityID string) (*models.EntityCustomization, error) {
+	cs := &s.customizations
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	customization, ok := cs.byEntity[entityID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCustomizationNotFound, entityID)
+	}
+	copied := *customization
+	return &copied, nil
+}
+
+// SaveCustomizations stores customizations and applies them to the registered entities. All
+// customizations are validated before any is stored; empty customizations reset their entity.
+func (s *UnifiedEntityService) SaveCustomizations(ctx context.Context, customizations []*models.EntityCustomization) error {
+	cs := &s.customizations
+	if cs.repo == nil {
+		return fmt.Errorf("customization repository not configured")
+	}
+
+	seen := make(map[string]bool, len(customizations))
+	var save []*models.EntityCustomization
+	var reset []string
+	for _, customization := range customizations {
+		if err := validateCustomization(customization); err != nil {
+			return err
+		}
+		if seen[customization.EntityID] {
+			return fmt.Errorf("%w: %s is listed more than once", ErrInvalidCustomization, customization.EntityID)
+		}
+		seen[customization.EntityID] = true
+
+		if customization.IsEmpty() {
+			reset = append(reset, customization.EntityID)
+		} else {
+			save = append(save, customization)
+		}
+	}
+
+	cs.mutex.RLock()
+	for _, customization := range save {
+		if existing, ok := cs.byEntity[customization.EntityID]; ok {
+			customization.CreatedAt = existing.CreatedAt
+		}
+	}
+	cs.mutex.RUnlock()
+
+	if len(save) > 0 {
+		if err := cs.repo.SaveCustomizations(ctx, save); err != nil {
+			return err
+		}
+	}
+	for _, entityID := range reset {
+		if !s.hasCustomization(entityID) {
+			continue
+		}
+		if err := cs.repo.DeleteCustomization(ctx, entityID); err != nil {
+			return err
+		}
+	}
+
+	cs.mutex.Lock()
+	if cs.byEntity == nil {
+		cs.byEntity = make(map[string]*models.EntityCustomization)
+	}
+	if cs.originals == nil {
+		cs.originals = make(map[string]*entityOriginal)
+	}
+	for _, customization := range save {
+		copied := *customization
+		cs.byEntity[customization.EntityID] = &copied
+	}
+	for _, entityID := range reset {
+		delete(cs.byEntity, entityID)
+	}
+	cs.mutex.Unlock()
+
+	for _, customization := range customizations {
+		s.reapplyCustomization(ctx, customization.EntityID)
+	}
+	return nil
+}
+
+// DeleteCustomization removes the customization of an entity and restores its source values
+func (s *UnifiedEntityService) DeleteCustomization(ctx context.Context, entityID string) error {
+	cs := &s.customizations
+	if cs.repo == nil {
+		return fmt.Errorf("customization repository not configured")
+	}
+	if !s.hasCustomization(entityID) {
+		return fmt.Errorf("%w: %s", ErrCustomizationNotFound, entityID)
+	}
+
+	if err := cs.repo.DeleteCustomization(ctx, entityID); err != nil {
+		return err
+	}
+
+	cs.mutex.Lock()
+	delete(cs.byEntity, entityID)
+	cs.mutex.Unlock()
+
+	s.reapplyCustomization(ctx, entityID)
+	return nil
+}
+
+func (s *UnifiedEntityService) hasCustomization(entityID string) bool {
+	s.customizations.mutex.RLock()
+	defer s.customizations.mutex.RUnlock()
+	_, ok := s.customizations.byEntity[entityID]
+	return ok
+}
+
+// isHidden reports whether an entity is hidden or disabled by its customization
+func (s *UnifiedEntityService) isHidden(entityID string) bool {
+	s.customizations.mutex.RLock()
+	defer s.customizations.mutex.RUnlock()
+	customization, ok := s.customizations.byEntity[entityID]
+	return ok && (customization.Hidden || customization.Disabled)
+}
+
+// isDisabled reports whether an entity is disabled by its customization
+func (s *UnifiedEntityService) isDisabled(entityID string) bool {
+	s.customizations.mutex.RLock()
+	defer s.customizations.mutex.RUnlock()
+	customization, ok := s.customizations.byEntity[entityID]
+	return ok && customization.Disabled
+}
+
+// reapplyCustomization customizes a registered entity again after its customization changed.
+// The entity is re-registered so the registry indexes follow a changed type.
+func (s *UnifiedEntityService) reapplyCustomization(ctx context.Context, entityID string) {
+	registry := s.registryManager.GetEntityRegistry()
+
+	s.mutex.Lock()
+	entity, err := registry.GetEntity(entityID)
+	if err != nil || entity == nil {
+		s.mutex.Unlock()
+		return
+	}
+	oldState := entity.GetState()
+	if err := registry.UnregisterEntity(entityID); err != nil {
+		s.mutex.Unlock()
+		s.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to customize entity")
+		return
+	}
+	s.applyCustomization(entity)
+	err = registry.RegisterEntity(entity)
+	s.mutex.Unlock()
+	if err != nil {
+		s.logger.WithError(err).WithField("entity_id", entityID).Error("Failed to register customized entity")
+		return
+	}
+
+	if s.redisCache != nil {
+		if err := s.redisCache.SetEntity(ctx, entityID, entity); err != nil {
+			s.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to save entity to Redis cache")
+		}
+	}
+	if s.eventEmitter != nil {
+		s.eventEmitter.BroadcastPMAEntityStateChange(entityID, oldState, entity.GetState(), map[string]interface{}{
+			"entity":        entity,
+			"change_source": "customization",
+			"timestamp":     time.Now().UTC(),
+		})
+	}
+	if oldState != entity.GetState() {
+		s.notifyStateChangeListeners(entityID, oldState, entity.GetState(), entity.GetSource())
+	}
+}
+
+// applyCustomization applies the customization of an entity after adapter conversion. An
+// instance that was customized before is restored first, so changed customizations apply
+// cleanly.
+func (s *UnifiedEntityService) applyCustomization(entity types.PMAEntity) {
+	be, ok := entity.(baseEntity)
+	if !ok {
+		return
+	}
+	base := be.GetBaseEntity()
+	entityID := base.ID
+
+	cs := &s.customizations
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if original, ok := cs.originals[entityID]; ok && original.entity == entity {
+		restoreOriginal(entity, base, original)
+	}
+	customization, ok := cs.byEntity[entityID]
+	if !ok {
+		delete(cs.originals, entityID)
+		return
+	}
+
+	original := &entityOriginal{
+		entity:       entity,
+		friendlyName: base.FriendlyName,
+		icon:         base.Icon,
+		entityType:   base.Type,
+		state:        base.State,
+		attributes:   base.Attributes,
+		available:    base.Available,
+		unit:         stringAttribute(base.Attributes, "unit_of_measurement"),
+		deviceClass:  stringAttribute(base.Attributes, "device_class"),
+	}
+	if sensor, ok := entity.(*types.PMASensorEntity); ok {
+		if sensor.Unit != "" {
+			original.unit = sensor.Unit
+		}
+		if sensor.DeviceClass != "" {
+			original.deviceClass = sensor.DeviceClass
+		}
+	}
+	cs.originals[entityID] = original
+
+	attributes := make(map[string]interface{}, len(base.Attributes)+4)
+	for key, value := range base.Attributes {
+		attributes[key] = value
+	}
+	attributes["customized"] = true
+
+	if customization.FriendlyName != "" {
+		base.FriendlyName = customization.FriendlyName
+	}
+	if customization.Icon != "" {
+		base.Icon = customization.Icon
+	}
+	if customization.EntityType != "" {
+		base.Type = types.PMAEntityType(customization.EntityType)
+	}
+	if customization.DeviceClass != "" {
+		attributes["device_class"] = customization.DeviceClass
+		if sensor, ok := entity.(*types.PMASensorEntity); ok {
+			sensor.DeviceClass = customization.DeviceClass
+		}
+	}
+	if customization.Unit != "" && customization.Unit != original.unit {
+		base.State = types.PMAEntityState(convertCustomizedState(string(base.State), original.unit, customization.Unit))
+		attributes["unit_of_measurement"] = customization.Unit
+		if original.unit != "" {
+			attributes["source_unit_of_measurement"] = original.unit
+		}
+		if sensor, ok := entity.(*types.PMASensorEntity); ok {
+			sensor.Unit = customization.Unit
+			if value, err := strconv.ParseFloat(string(base.State), 64); err == nil {
+				sensor.NumericValue = &value
+			}
+		}
+	}
+	if customization.Hidden {
+		attributes["hidden"] = true
+	}
+	if customization.Disabled {
+		attributes["disabled"] = true
+		base.Available = false
+	}
+	base.Attributes = attributes
+}
+
+// restoreOriginal puts back the source values of a customized entity
+func restoreOriginal(entity types.PMAEntity, base *types.PMABaseEntity, original *entityOriginal) {
+	base.FriendlyName = original.friendlyName
+	base.Icon = original.icon
+	base.Type = original.entityType
+	base.State = original.state
+	base.Attributes = original.attributes
+	base.Available = original.available
+	if sensor, ok := entity.(*types.PMASensorEntity); ok {
+		sensor.Unit = original.unit
+		sensor.DeviceClass = original.deviceClass
+		if value, err := strconv.ParseFloat(string(original.state), 64); err == nil {
+			sensor.NumericValue = &value
+		}
+	}
+}
+
+// customizeState converts a state reported by the source to the customized unit of the entity
+func (s *UnifiedEntityService) customizeState(entityID, state string) string {
+	cs := &s.customizations
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	customization, ok := cs.byEntity[entityID]
+	if !ok || customization.Unit == "" {
+		return state
+	}
+	original, ok := cs.originals[entityID]
+	if !ok {
+		return state
+	}
+
+	original.state = types.PMAEntityState(state)
+	return convertCustomizedState(state, original.unit, customization.Unit)
+}
+
+// validateCustomization checks a customization and trims its fields
+func validateCustomization(customization *models.EntityCustomization) error {
+	customization.EntityID = strings.TrimSpace(customization.EntityID)
+	customization.FriendlyName = strings.TrimSpace(customization.FriendlyName)
+	customization.Icon = strings.TrimSpace(customization.Icon)
+	customization.EntityType = strings.TrimSpace(customization.EntityType)
+	customization.Unit = strings.TrimSpace(customization.Unit)
+	customization.DeviceClass = strings.TrimSpace(customization.DeviceClass)
+
+	if customization.EntityID == "" {
+		return fmt.Errorf("%w: entity_id is required", ErrInvalidCustomization)
+	}
+	if customization.EntityType != "" && !customizableTypes[types.PMAEntityType(customization.EntityType)] {
+		return fmt.Errorf("%w: entity type %s cannot be used for customization", ErrInvalidCustomization, customization.EntityType)
+	}
+	return nil
+}
+
+// convertCustomizedState converts a numeric state between units. States that are not numeric or
+// units without a known conversion are left as they are.
+func convertCustomizedState(state, from, to string) string {
+	value, err := strconv.ParseFloat(state, 64)
+	if err != nil {
+		return state
+	}
+	converted, ok := convertUnit(value, from, to)
+	if !ok {
+		return state
+	}
+	return strconv.FormatFloat(math.Round(converted*100)/100, 'f', -1, 64)
+}
+
+// unitScale is the factor that converts a unit to the base unit of its quantity
+type unitScale struct {
+	quantity string
+	factor   float64
+}
+
+var unitScales = map[string]unitScale{
+	"W": {"power", 1}, "kW": {"power", 1e3}, "MW": {"power", 1e6},
+	"Wh": {"energy", 1}, "kWh": {"energy", 1e3}, "MWh": {"energy", 1e6},
+	"mm": {"length", 1e-3}, "cm": {"length", 1e-2}, "m": {"length", 1}, "km": {"length", 1e3},
+	"in": {"length", 0.0254}, "ft": {"length", 0.3048}, "yd": {"length", 0.9144}, "mi": {"length", 1609.344},
+	"Pa": {"pressure", 1}, "hPa": {"pressure", 100}, "kPa": {"pressure", 1e3}, "mbar": {"pressure", 100},
+	"bar": {"pressure", 1e5}, "psi": {"pressure", 6894.757}, "inHg": {"pressure", 3386.389}, "mmHg": {"pressure", 133.322},
+	"m/s": {"speed", 1}, "km/h": {"speed", 1 / 3.6}, "mph": {"speed", 0.44704}, "kn": {"speed", 0.514444},
+	"mL": {"volume", 1e-3}, "L": {"volume", 1}, "m³": {"volume", 1e3}, "gal": {"volume", 3.785411784},
+	"g": {"mass", 1}, "kg": {"mass", 1e3}, "lb": {"mass", 453.59237}, "oz": {"mass", 28.349523125},
+	"ms": {"duration", 1e-3}, "s": {"duration", 1}, "min": {"duration", 60}, "h": {"duration", 3600}, "d": {"duration", 86400},
+	"mA": {"current", 1e-3}, "A": {"current", 1},
+	"mV": {"voltage", 1e-3}, "V": {"voltage", 1},
+}
+
+// convertUnit converts a value between two units of the same quantity
+func convertUnit(value float64, from, to string) (float64, bool) {
+	if from == to {
+		return value, true
+	}
+	if celsius, ok := toCelsius(value, from); ok {
+		return fromCelsius(celsius, to)
+	}
+
+	fromScale, ok := unitScales[from]
+	if !ok {
+		return 0, false
+	}
+	toScale, ok := unitScales[to]
+	if !ok || toScale.quantity != fromScale.quantity {
+		return 0, false
+	}
+	return value * fromScale.factor / toScale.factor, true
+}
+
+func toCelsius(value float64, unit string) (float64, bool) {
+	switch unit {
+	case "°C":
+		return value, true
+	case "°F":
+		return (value - 32) * 5 / 9, true
+	case "K":
+		return value - 273.15, true
+	}
+	return 0, false
+}
+
+func fromCelsius(value float64, unit string) (float64, bool) {
+	switch unit {
+	case "°C":
+		return value, true
+	case "°F":
+		return value*9/5 + 32, true
+	case "K":
+		return value + 273.15, true
+	}
+	return 0, false
+}
+
+func stringAttribute(attributes map[string]interface{}, key string) string {
+	value, _ := attributes[key].(string)
+	return value
+}
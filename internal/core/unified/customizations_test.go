@@ -0,0 +1,205 @@
+package unified
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCustomizationRepository keeps customizations in memory
+type memoryCustomizationRepository struct {
+	customizations map[string]*models.EntityCustomization
+}
+
+func (r *memoryCustomizationRepository) ListCustomizations(ctx context.Context) ([]*models.EntityCustomization, error) {
+	var list []*models.EntityCustomization
+	for _, customization := range r.customizations {
+		list = append(list, customization)
+	}
+	return list, nil
+}
+
+func (r *memoryCustomizationRepository) GetCustomization(ctx context.Context, entityID string) (*models.EntityCustomization, error) {
+	customization, ok := r.customizations[entityID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return customization, nil
+}
+
+func (r *memoryCustomizationRepository) SaveCustomizations(ctx context.Context, customizations []*models.EntityCustomization) error {
+	for _, customization := range customizations {
+		copied := *customization
+		r.customizations[customization.EntityID] = &copied
+	}
+	return nil
+}
+
+func (r *memoryCustomizationRepository) DeleteCustomization(ctx context.Context, entityID string) error {
+	delete(r.customizations, entityID)
+	return nil
+}
+
+func newCustomizationTestService(t *testing.T) (*UnifiedEntityService, *memoryCustomizationRepository) {
+	t.Helper()
+	logger := logrus.New()
+	service := NewUnifiedEntityService(types.NewPMATypeRegistry(logger), &config.Config{}, logger)
+	repo := &memoryCustomizationRepository{customizations: make(map[string]*models.EntityCustomization)}
+	service.SetCustomizationRepository(repo)
+	return service, repo
+}
+
+func temperatureSensor() *types.PMASensorEntity {
+	value := 20.0
+	return &types.PMASensorEntity{
+		PMABaseEntity: &types.PMABaseEntity{
+			ID:           "sensor.outside_temperature",
+			Type:         types.EntityTypeSensor,
+			FriendlyName: "Outside",
+			Icon:         "mdi:thermometer",
+			State:        "20",
+			Attributes:   map[string]interface{}{"unit_of_measurement": "°C"},
+			Available:    true,
+		},
+		Unit:         "°C",
+		DeviceClass:  "temperature",
+		NumericValue: &value,
+	}
+}
+
+func TestCustomizationOverlay(t *testing.T) {
+	service, repo := newCustomizationTestService(t)
+	ctx := context.Background()
+
+	sensor := temperatureSensor()
+	require.NoError(t, service.registryManager.GetEntityRegistry().RegisterEntity(sensor))
+
+	require.NoError(t, service.SaveCustomizations(ctx, []*models.EntityCustomization{{
+		EntityID:     " sensor.outside_temperature ",
+		FriendlyName: "Garden",
+		Icon:         "mdi:flower",
+		Unit:         "°F",
+		DeviceClass:  "outdoor_temperature",
+		Hidden:       true,
+	}}))
+	require.Contains(t, repo.customizations, "sensor.outside_temperature", "fields are trimmed before saving")
+
+	assert.Equal(t, "Garden", sensor.FriendlyName)
+	assert.Equal(t, "mdi:flower", sensor.Icon)
+	assert.Equal(t, types.PMAEntityState("68"), sensor.State)
+	assert.Equal(t, "°F", sensor.Unit)
+	assert.Equal(t, 68.0, *sensor.NumericValue)
+	assert.Equal(t, "outdoor_temperature", sensor.DeviceClass)
+	assert.Equal(t, "°F", sensor.Attributes["unit_of_measurement"])
+	assert.Equal(t, "°C", sensor.Attributes["source_unit_of_measurement"])
+	assert.Equal(t, true, sensor.Attributes["customized"])
+	assert.True(t, service.isHidden(sensor.ID))
+	assert.False(t, service.isDisabled(sensor.ID))
+
+	// Source updates arrive in the source unit
+	assert.Equal(t, "unknown", service.customizeState(sensor.ID, "unknown"))
+	assert.Equal(t, "77", service.customizeState(sensor.ID, "25"))
+	assert.Equal(t, "25", service.customizeState("sensor.other", "25"))
+
+	// A changed customization applies to the source values, not the customized ones
+	require.NoError(t, service.SaveCustomizations(ctx, []*models.EntityCustomization{{
+		EntityID: sensor.ID,
+		Unit:     "K",
+		Disabled: true,
+	}}))
+	assert.Equal(t, "Outside", sensor.FriendlyName)
+	assert.Equal(t, "mdi:thermometer", sensor.Icon)
+	assert.Equal(t, types.PMAEntityState("298.15"), sensor.State, "converted from the last source state")
+	assert.False(t, sensor.Available)
+	assert.NotContains(t, sensor.Attributes, "hidden")
+	assert.True(t, service.isDisabled(sensor.ID))
+
+	// Deleting restores the source
+	require.NoError(t, service.DeleteCustomization(ctx, sensor.ID))
+	assert.Equal(t, types.PMAEntityState("25"), sensor.State)
+	assert.Equal(t, "°C", sensor.Unit)
+	assert.Equal(t, "temperature", sensor.DeviceClass)
+	assert.True(t, sensor.Available)
+	assert.NotContains(t, sensor.Attributes, "customized")
+	assert.Empty(t, repo.customizations)
+
+	assert.ErrorIs(t, service.DeleteCustomization(ctx, sensor.ID), ErrCustomizationNotFound)
+}
+
+func TestCustomizationEntityType(t *testing.T) {
+	service, _ := newCustomizationTestService(t)
+	ctx := context.Background()
+
+	plug := &types.PMASwitchEntity{PMABaseEntity: &types.PMABaseEntity{
+		ID:         "switch.lamp_plug",
+		Type:       types.EntityTypeSwitch,
+		State:      types.StateOn,
+		Attributes: map[string]interface{}{},
+		Available:  true,
+	}}
+	registry := service.registryManager.GetEntityRegistry()
+	require.NoError(t, registry.RegisterEntity(plug))
+
+	require.NoError(t, service.SaveCustomizations(ctx, []*models.EntityCustomization{{EntityID: plug.ID, EntityType: "light"}}))
+	lights, err := registry.GetEntitiesByType(types.EntityTypeLight)
+	require.NoError(t, err)
+	require.Len(t, lights, 1, "the registry index follows the customized type")
+	assert.Equal(t, plug.ID, lights[0].GetID())
+
+	// An empty customization resets the entity
+	require.NoError(t, service.SaveCustomizations(ctx, []*models.EntityCustomization{{EntityID: plug.ID}}))
+	assert.Equal(t, types.EntityTypeSwitch, plug.Type)
+	assert.Empty(t, service.ListCustomizations())
+}
+
+func TestSaveCustomizationsValidation(t *testing.T) {
+	service, repo := newCustomizationTestService(t)
+
+	tests := []struct {
+		name           string
+		customizations []*models.EntityCustomization
+	}{
+		{"missing entity", []*models.EntityCustomization{{FriendlyName: "Nameless"}}},
+		{"unsupported type", []*models.EntityCustomization{{EntityID: "switch.a", EntityType: "camera"}}},
+		{"duplicate entity", []*models.EntityCustomization{{EntityID: "switch.a", Icon: "mdi:a"}, {EntityID: "switch.a", Icon: "mdi:b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.SaveCustomizations(context.Background(), tt.customizations)
+			assert.ErrorIs(t, err, ErrInvalidCustomization)
+			assert.Empty(t, repo.customizations, "nothing is saved when any customization is invalid")
+		})
+	}
+}
+
+func TestConvertCustomizedState(t *testing.T) {
+	tests := []struct {
+		state, from, to string
+		want            string
+	}{
+		{"0", "°C", "°F", "32"},
+		{"212", "°F", "°C", "100"},
+		{"0", "K", "°C", "-273.15"},
+		{"1500", "W", "kW", "1.5"},
+		{"2.5", "kWh", "Wh", "2500"},
+		{"100", "km/h", "mph", "62.14"},
+		{"1013", "hPa", "inHg", "29.91"},
+		{"90", "min", "h", "1.5"},
+		{"5", "kW", "kWh", "5"},
+		{"5", "W", "°C", "5"},
+		{"5", "", "W", "5"},
+		{"on", "W", "kW", "on"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, convertCustomizedState(tt.state, tt.from, tt.to), "%s %s to %s", tt.state, tt.from, tt.to)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/ble"
 	"github.com/frostdev-ops/pma-backend-go/internal/adapters/homeassistant"
 	"github.com/frostdev-ops/pma-backend-go/internal/adapters/network"
 	"github.com/frostdev-ops/pma-backend-go/internal/adapters/ring"
@@ -35,6 +36,32 @@ type EventEmitter interface {
 	BroadcastPMAAdapterStatus(adapterID, adapterName, source, status string, health interface{}, metrics interface{})
 }
 
+// StateChangeListener is notified after an entity state change has been applied
+type StateChangeListener func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType)
+
+// stateChange is an applied state change waiting to be delivered to the listeners
+type stateChange struct {
+	oldState types.PMAEntityState
+	newState types.PMAEntityState
+	source   types.PMASourceType
+}
+
+// stateChangeQueue holds the state changes not yet delivered, per entity and oldest first.
+// An entity has a queue while a dispatcher is delivering its changes.
+type stateChangeQueue struct {
+	mutex   sync.Mutex
+	pending map[string][]stateChange
+}
+
+// AdapterReplacedListener is notified when a reconfigure registered a new adapter for a source,
+// so services holding the previous adapter can attach to the new one
+type AdapterReplacedListener func(source types.PMASourceType, adapter types.PMAAdapter)
+
+// ActionAuditor records every executed control action, including rejected ones
+type ActionAuditor interface {
+	RecordAction(ctx context.Context, action types.PMAControlAction, result *types.PMAControlResult, err error)
+}
+
 // UnifiedEntityService manages all entities through the PMA type system
 type UnifiedEntityService struct {
 	typeRegistry    *types.PMATypeRegistry
@@ -43,6 +70,12 @@ type UnifiedEntityService struct {
 	mutex           sync.RWMutex
 	roomService     RoomServiceInterface
 	eventEmitter    EventEmitter
+	listeners       []StateChangeListener
+	stateChanges    stateChangeQueue
+	adapterWatchers []AdapterReplacedListener
+	actionAuditor   ActionAuditor
+	groupState      groupState
+	customizations  customizationState
 
 	// Redis-based caching
 	redisCache      *cache.RedisEntityCache
@@ -120,15 +153,177 @@ func (s *UnifiedEntityService) SetEventEmitter(eventEmitter EventEmitter) {
 	s.logger.Info("Event emitter configured for real-time WebSocket updates")
 }
 
+// SetActionAuditor sets the auditor control actions are recorded with
+func (s *UnifiedEntityService) SetActionAuditor(auditor ActionAuditor) {
+	s.actionAuditor = auditor
+}
+
+// AddStateChangeListener registers a listener for entity state changes. Listeners run outside
+// the caller's goroutine. Changes of one entity reach the listeners one at a time and in the
+// order they were applied; changes of different entities are dispatched concurrently.
+func (s *UnifiedEntityService) AddStateChangeListener(listener StateChangeListener) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// notifyStateChangeListeners queues a state change for the listeners, starting a dispatcher for
+// the entity unless one is already draining its queue
+func (s *UnifiedEntityService) notifyStateChangeListeners(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+	d := &s.stateChanges
+	d.mutex.Lock()
+	if d.pending == nil {
+		d.pending = make(map[string][]stateChange)
+	}
+	queue, dispatching := d.pending[entityID]
+	d.pending[entityID] = append(queue, stateChange{oldState: oldState, newState: newState, source: source})
+	d.mutex.Unlock()
+
+	if !dispatching {
+		go s.dispatchStateChanges(entityID)
+	}
+}
+
+// dispatchStateChanges delivers the queued changes of an entity in order until its queue is
+// empty. The change being delivered stays queued, marking the entity as dispatching.
+func (s *UnifiedEntityService) dispatchStateChanges(entityID string) {
+	d := &s.stateChanges
+	for {
+		d.mutex.Lock()
+		queue := d.pending[entityID]
+		if len(queue) == 0 {
+			delete(d.pending, entityID)
+			d.mutex.Unlock()
+			return
+		}
+		change := queue[0]
+		d.mutex.Unlock()
+
+		s.mutex.RLock()
+		listeners := make([]StateChangeListener, len(s.listeners))
+		copy(listeners, s.listeners)
+		s.mutex.RUnlock()
+
+		for _, listener := range listeners {
+			s.callStateChangeListener(listener, entityID, change)
+		}
+
+		d.mutex.Lock()
+		d.pending[entityID] = d.pending[entityID][1:]
+		d.mutex.Unlock()
+	}
+}
+
+func (s *UnifiedEntityService) callStateChangeListener(listener StateChangeListener, entityID string, change stateChange) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.WithField("panic", r).WithField("entity_id", entityID).Error("State change listener panicked")
+		}
+	}()
+	listener(entityID, change.oldState, change.newState, change.source)
+}
+
+// AddAdapterReplacedListener registers a listener for adapters replaced by ReconfigureAdapter.
+// Listeners run before the new adapter is synced.
+func (s *UnifiedEntityService) AddAdapterReplacedListener(listener AdapterReplacedListener) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.adapterWatchers = append(s.adapterWatchers, listener)
+}
+
+// configuredSources are the sources whose adapters are built from the configuration, in
+// initialization order
+var configuredSources = []types.PMASourceType{
+	types.SourceHomeAssistant,
+	types.SourceRing,
+	types.SourceShelly,
+	types.SourceUPS,
+	types.SourceBLE,
+	types.SourceNetwork,
+}
+
 // InitializeAdapters initializes all configured adapters
 func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 	var errors []error
+	for _, source := range configuredSources {
+		if err := s.initializeAdapter(source, config); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("adapter initialization had %d errors: %v", len(errors), errors)
+	}
+
+	return nil
+}
+
+// initializeAdapter builds the adapter of a source from the configuration; sources that are
+// not configured are skipped
+func (s *UnifiedEntityService) initializeAdapter(source types.PMASourceType, config *config.Config) error {
+	switch source {
+	case types.SourceHomeAssistant:
+		return s.initializeHomeAssistantAdapter(config)
+	case types.SourceRing:
+		return s.initializeRingAdapter(config)
+	case types.SourceShelly:
+		return s.initializeShellyAdapter(config)
+	case types.SourceUPS:
+		return s.initializeUPSAdapter(config)
+	case types.SourceBLE:
+		return s.initializeBLEAdapter(config)
+	case types.SourceNetwork:
+		return s.initializeNetworkAdapter(config)
+	}
+	return fmt.Errorf("adapters for source %s are not built from the configuration", source)
+}
+
+// ReconfigureAdapter replaces the adapter of a source with one built from a new configuration.
+// The running adapter is disconnected and unregistered; if the source is still configured the
+// new adapter is registered and synced. Entities of the source stay registered and are
+// refreshed by the sync.
+func (s *UnifiedEntityService) ReconfigureAdapter(ctx context.Context, source types.PMASourceType, config *config.Config) error {
+	registry := s.registryManager.GetAdapterRegistry()
+	if existing, err := registry.GetAdapterBySource(source); err == nil {
+		if err := existing.Disconnect(ctx); err != nil {
+			s.logger.WithError(err).WithField("source", source).Warn("Failed to disconnect adapter before reconfiguring")
+		}
+		if err := registry.UnregisterAdapter(existing.GetID()); err != nil {
+			return err
+		}
+	}
+
+	if err := s.initializeAdapter(source, config); err != nil {
+		return err
+	}
+	adapter, err := registry.GetAdapterBySource(source)
+	if err != nil {
+		s.logger.WithField("source", source).Info("Adapter removed, source is no longer configured")
+		return nil
+	}
+
+	s.mutex.RLock()
+	watchers := make([]AdapterReplacedListener, len(s.adapterWatchers))
+	copy(watchers, s.adapterWatchers)
+	s.mutex.RUnlock()
+	for _, watcher := range watchers {
+		watcher(source, adapter)
+	}
+
+	if _, err := s.SyncFromSource(ctx, source); err != nil {
+		s.logger.WithError(err).WithField("source", source).Warn("Failed to sync reconfigured adapter")
+	}
+	s.logger.WithField("source", source).Info("Adapter reconfigured")
+	return nil
+}
 
-	// Initialize HomeAssistant adapter
+// initializeHomeAssistantAdapter registers and connects the Home Assistant adapter when a URL and
+// token are configured
+func (s *UnifiedEntityService) initializeHomeAssistantAdapter(config *config.Config) error {
 	if config.HomeAssistant.URL != "" && config.HomeAssistant.Token != "" {
 		haAdapter := homeassistant.NewHomeAssistantAdapter(config, s.logger)
 		if err := s.RegisterAdapter(haAdapter); err != nil {
-			errors = append(errors, fmt.Errorf("failed to register HA adapter: %w", err))
+			return fmt.Errorf("failed to register HA adapter: %w", err)
 		} else {
 			s.logger.Info("HomeAssistant adapter registered successfully")
 
@@ -161,14 +356,18 @@ func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 
 			if err := haAdapter.Connect(connectCtx); err != nil {
 				s.logger.WithError(err).Error("Failed to connect Home Assistant adapter during startup")
-				errors = append(errors, fmt.Errorf("failed to connect HA adapter: %w", err))
+				return fmt.Errorf("failed to connect HA adapter: %w", err)
 			} else {
 				s.logger.Info("Home Assistant adapter connected successfully during startup")
 			}
 		}
 	}
 
-	// Initialize Ring adapter
+	return nil
+}
+
+// initializeRingAdapter registers the Ring adapter when enabled with credentials
+func (s *UnifiedEntityService) initializeRingAdapter(config *config.Config) error {
 	if config.Devices.Ring.Enabled && config.Devices.Ring.Email != "" && config.Devices.Ring.Password != "" {
 		ringConfig := ring.RingAdapterConfig{
 			Credentials: ring.RingCredentials{
@@ -179,13 +378,17 @@ func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 		}
 		ringAdapter := ring.NewRingAdapter(ringConfig, config, s.logger)
 		if err := s.RegisterAdapter(ringAdapter); err != nil {
-			errors = append(errors, fmt.Errorf("failed to register Ring adapter: %w", err))
+			return fmt.Errorf("failed to register Ring adapter: %w", err)
 		} else {
 			s.logger.Info("Ring adapter registered successfully")
 		}
 	}
 
-	// Initialize Shelly adapter with enhanced discovery
+	return nil
+}
+
+// initializeShellyAdapter registers the Shelly adapter with enhanced discovery when enabled
+func (s *UnifiedEntityService) initializeShellyAdapter(config *config.Config) error {
 	if config.Devices.Shelly.Enabled {
 		discoveryInterval, err := time.ParseDuration(config.Devices.Shelly.DiscoveryInterval)
 		if err != nil {
@@ -236,6 +439,12 @@ func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 			ExcludeLoopback:           config.Devices.Shelly.ExcludeLoopback,
 			ExcludeDockerInterfaces:   config.Devices.Shelly.ExcludeDockerInterfaces,
 			MinSubnetSize:             config.Devices.Shelly.MinSubnetSize,
+
+			// Realtime push configuration
+			PushEnabled:       config.Devices.Shelly.Push.Enabled,
+			PushBaseURL:       config.Devices.Shelly.Push.BaseURL,
+			PushToken:         config.Devices.Shelly.Push.Token,
+			AutoConfigurePush: config.Devices.Shelly.Push.AutoConfigure,
 		}
 		// Create debug logger for Shelly adapter
 		debugConfig := &debug.DebugConfig{
@@ -251,14 +460,33 @@ func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 		}
 
 		shellyAdapter := shelly.NewShellyAdapter(shellyConfig, s.logger, debugLogger)
+
+		// Feed pushed device updates into the unified service
+		shellyAdapter.SetEventHandler(func(entityID, oldState, newState string) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			metadata := map[string]interface{}{
+				"old_state": oldState,
+				"realtime":  true,
+			}
+			if err := s.HandleExternalStateChange(ctx, entityID, newState, types.SourceShelly, metadata); err != nil {
+				s.logger.WithError(err).WithField("entity_id", entityID).Debug("Failed to apply Shelly push update")
+			}
+		})
+
 		if err := s.RegisterAdapter(shellyAdapter); err != nil {
-			errors = append(errors, fmt.Errorf("failed to register Shelly adapter: %w", err))
+			return fmt.Errorf("failed to register Shelly adapter: %w", err)
 		} else {
 			s.logger.Info("Enhanced Shelly adapter registered successfully")
 		}
 	}
 
-	// Initialize UPS adapter
+	return nil
+}
+
+// initializeUPSAdapter registers the NUT UPS adapter when enabled with a host
+func (s *UnifiedEntityService) initializeUPSAdapter(config *config.Config) error {
 	if config.Devices.UPS.Enabled && config.Devices.UPS.NUTHost != "" {
 		pollInterval, err := time.ParseDuration(config.Devices.UPS.PollInterval)
 		if err != nil {
@@ -281,13 +509,58 @@ func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 		}
 		upsAdapter := ups.NewUPSAdapter(upsConfig, s.logger)
 		if err := s.RegisterAdapter(upsAdapter); err != nil {
-			errors = append(errors, fmt.Errorf("failed to register UPS adapter: %w", err))
+			return fmt.Errorf("failed to register UPS adapter: %w", err)
 		} else {
 			s.logger.Info("UPS adapter registered successfully")
 		}
 	}
 
-	// Initialize Network adapter
+	return nil
+}
+
+// initializeBLEAdapter registers the BLE passive sensor adapter and starts scanning when enabled
+func (s *UnifiedEntityService) initializeBLEAdapter(config *config.Config) error {
+	if config.Devices.BLE.Enabled {
+		presenceTimeout, err := time.ParseDuration(config.Devices.BLE.PresenceTimeout)
+		if err != nil {
+			presenceTimeout = 2 * time.Minute // Default fallback
+		}
+
+		bleConfig := ble.BLEAdapterConfig{
+			ScanCommand:      config.Devices.BLE.ScanCommand,
+			PresenceTimeout:  presenceTimeout,
+			RSSIThreshold:    config.Devices.BLE.RSSIThreshold,
+			AllowedAddresses: config.Devices.BLE.AllowedAddresses,
+			PathLossExponent: config.Devices.BLE.PathLossExponent,
+		}
+		bleAdapter := ble.NewBLEAdapter(bleConfig, nil, s.logger)
+		if err := s.RegisterAdapter(bleAdapter); err != nil {
+			return fmt.Errorf("failed to register BLE adapter: %w", err)
+		} else {
+			s.logger.Info("BLE adapter registered successfully")
+
+			// Forward advertisement driven state changes to the unified service
+			bleAdapter.SetEventHandler(func(entityID, oldState, newState string) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+
+				if _, err := s.UpdateEntityState(ctx, entityID, newState, types.SourceBLE); err != nil {
+					s.logger.WithError(err).WithField("entity_id", entityID).Debug("Failed to update BLE entity state")
+				}
+			})
+
+			// Devices are only discovered while scanning, so start right away
+			if err := bleAdapter.Connect(context.Background()); err != nil {
+				s.logger.WithError(err).Warn("Failed to start BLE scanning during startup")
+			}
+		}
+	}
+
+	return nil
+}
+
+// initializeNetworkAdapter registers the network adapter when enabled with a router URL
+func (s *UnifiedEntityService) initializeNetworkAdapter(config *config.Config) error {
 	if config.Devices.Network.Enabled && config.Router.BaseURL != "" {
 		scanInterval, err := time.ParseDuration(config.Devices.Network.ScanInterval)
 		if err != nil {
@@ -301,16 +574,12 @@ func (s *UnifiedEntityService) InitializeAdapters(config *config.Config) error {
 		}
 		networkAdapter := network.NewNetworkAdapter(networkConfig, s.logger)
 		if err := s.RegisterAdapter(networkAdapter); err != nil {
-			errors = append(errors, fmt.Errorf("failed to register Network adapter: %w", err))
+			return fmt.Errorf("failed to register Network adapter: %w", err)
 		} else {
 			s.logger.Info("Network adapter registered successfully")
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("adapter initialization had %d errors: %v", len(errors), errors)
-	}
-
 	return nil
 }
 
@@ -617,6 +886,14 @@ func (s *UnifiedEntityService) Search(ctx context.Context, query string, options
 
 // ExecuteAction executes a control action on an entity
 func (s *UnifiedEntityService) ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error) {
+	result, err := s.executeAction(ctx, action)
+	if s.actionAuditor != nil {
+		s.actionAuditor.RecordAction(ctx, action, result, err)
+	}
+	return result, err
+}
+
+func (s *UnifiedEntityService) executeAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error) {
 	// Validate the action
 	if err := s.validateAction(action); err != nil {
 		return &types.PMAControlResult{
@@ -648,6 +925,25 @@ func (s *UnifiedEntityService) ExecuteAction(ctx context.Context, action types.P
 		}, nil
 	}
 
+	if s.isDisabled(action.EntityID) {
+		return &types.PMAControlResult{
+			Success:     false,
+			EntityID:    action.EntityID,
+			Action:      action.Action,
+			ProcessedAt: time.Now(),
+			Error: &types.PMAError{
+				Code:    "ENTITY_DISABLED",
+				Message: fmt.Sprintf("Entity is disabled: %s", action.EntityID),
+				Source:  "unified_service",
+			},
+		}, nil
+	}
+
+	// Group entities have no adapter, their actions fan out to the members
+	if group, ok := entity.(*GroupEntity); ok {
+		return s.executeGroupAction(ctx, group, action), nil
+	}
+
 	// Get the appropriate adapter for this entity's source
 	adapter, err := s.registryManager.GetAdapterRegistry().GetAdapterBySource(entity.GetSource())
 	if err != nil {
@@ -835,6 +1131,7 @@ func (s *UnifiedEntityService) SyncFromSource(ctx context.Context, source types.
 
 			if err != nil {
 				// Entity doesn't exist, register it
+				s.applyCustomization(entity)
 				if err := s.registryManager.GetEntityRegistry().RegisterEntity(entity); err != nil {
 					errMsg := fmt.Sprintf("Failed to register entity %s: %v", entity.GetID(), err)
 					errors = append(errors, errMsg)
@@ -874,6 +1171,7 @@ func (s *UnifiedEntityService) SyncFromSource(ctx context.Context, source types.
 					// Store old state for broadcasting
 					oldState := existingEntity.GetState()
 
+					s.applyCustomization(entity)
 					if err := s.registryManager.GetEntityRegistry().UpdateEntity(entity); err != nil {
 						errMsg := fmt.Sprintf("Failed to update entity %s: %v", entity.GetID(), err)
 						errors = append(errors, errMsg)
@@ -960,6 +1258,9 @@ func (s *UnifiedEntityService) SyncFromSource(ctx context.Context, source types.
 		"error_count":         len(errors),
 	}).Info("Entity sync completed")
 
+	// Syncs replace members without notifying listeners, so refresh the groups here
+	s.recomputeAllGroups(ctx)
+
 	// Broadcast sync completion (non-blocking)
 	if s.eventEmitter != nil {
 		status := "completed"
@@ -1240,12 +1541,17 @@ func (s *UnifiedEntityService) performPeriodicSync() {
 // Helper methods
 
 func (s *UnifiedEntityService) filterEntities(entities []types.PMAEntity, options GetAllOptions) []types.PMAEntity {
-	if options.Domain == "" && !options.AvailableOnly && len(options.Capabilities) == 0 {
+	if options.Domain == "" && !options.AvailableOnly && len(options.Capabilities) == 0 && !options.ExcludeHidden {
 		return entities
 	}
 
 	var filtered []types.PMAEntity
 	for _, entity := range entities {
+		// Filter out entities the user hid or disabled
+		if options.ExcludeHidden && s.isHidden(entity.GetID()) {
+			continue
+		}
+
 		// Filter by domain (entity type)
 		if options.Domain != "" && entity.GetType() != types.PMAEntityType(options.Domain) {
 			continue
@@ -1412,8 +1718,11 @@ func (s *UnifiedEntityService) UpdateEntityState(ctx context.Context, entityID s
 		// Get cache size for debugging
 		cacheSize, _ = s.redisCache.GetCacheSize(ctx)
 	} else {
-		exists = false
-		cacheSize = 0
+		// Without Redis the registry is the only copy of the entity
+		s.mutex.RLock()
+		registryEntity, err := s.registryManager.GetEntityRegistry().GetEntity(entityID)
+		s.mutex.RUnlock()
+		entity, exists = registryEntity, err == nil && registryEntity != nil
 	}
 
 	if !exists {
@@ -1426,6 +1735,9 @@ func (s *UnifiedEntityService) UpdateEntityState(ctx context.Context, entityID s
 		return nil, nil
 	}
 
+	// Sources report states in their own unit, convert them to a customized one
+	newState = s.customizeState(entityID, newState)
+
 	// Store old state for comparison (no mutex needed for read-only comparison)
 	oldState := entity.GetState()
 
@@ -1444,9 +1756,8 @@ func (s *UnifiedEntityService) UpdateEntityState(ctx context.Context, entityID s
 		"new_state": newState,
 	}).Debug("Entity state changed, updating")
 
-	// Update the entity state directly instead of cloning
-	// This is more memory efficient
-	s.updateEntityStateDirectly(entity, newState)
+	// Readers share the registry entity, so the change is made on a copy that replaces it
+	entity = s.entityWithState(entity, newState)
 
 	// Save the updated entity back to the cache (no mutex needed, Redis handles concurrency)
 	if s.redisCache != nil {
@@ -1485,9 +1796,91 @@ func (s *UnifiedEntityService) UpdateEntityState(ctx context.Context, entityID s
 		}).Info("📡 Real-time state change broadcast completed")
 	}
 
+	s.notifyStateChangeListeners(entityID, oldState, types.PMAEntityState(newState), source)
+
 	return entity, nil
 }
 
+// PublishEntity registers or replaces an entity owned by PMA itself, such as a group, a person
+// or a helper, broadcasting the update and notifying listeners when its state changed
+func (s *UnifiedEntityService) PublishEntity(ctx context.Context, entity types.PMAEntity) error {
+	entityID := entity.GetID()
+	s.applyCustomization(entity)
+
+	s.mutex.Lock()
+	existing, err := s.registryManager.GetEntityRegistry().GetEntity(entityID)
+	if err != nil || existing == nil {
+		err = s.registryManager.GetEntityRegistry().RegisterEntity(entity)
+		s.mutex.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to register entity: %w", err)
+		}
+
+		if s.redisCache != nil {
+			if err := s.redisCache.SetEntity(ctx, entityID, entity); err != nil {
+				s.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to save entity to Redis cache")
+			}
+		}
+		if s.eventEmitter != nil {
+			s.eventEmitter.BroadcastPMAEntityAdded(entity)
+		}
+		return nil
+	}
+
+	if existing.GetSource() != entity.GetSource() {
+		s.mutex.Unlock()
+		return fmt.Errorf("entity %s belongs to source %s", entityID, existing.GetSource())
+	}
+	oldState := existing.GetState()
+	err = s.registryManager.GetEntityRegistry().UpdateEntity(entity)
+	s.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update entity: %w", err)
+	}
+
+	if s.redisCache != nil {
+		if err := s.redisCache.SetEntity(ctx, entityID, entity); err != nil {
+			s.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to save entity to Redis cache")
+		}
+	}
+
+	// Attribute-only changes are broadcast too, e.g. a sensor aggregate
+	if s.eventEmitter != nil {
+		s.eventEmitter.BroadcastPMAEntityStateChange(entityID, oldState, entity.GetState(), map[string]interface{}{
+			"entity":        entity,
+			"change_source": entity.GetSource(),
+			"timestamp":     time.Now().UTC(),
+		})
+	}
+	if oldState != entity.GetState() {
+		s.notifyStateChangeListeners(entityID, oldState, entity.GetState(), entity.GetSource())
+	}
+
+	return nil
+}
+
+// UnpublishEntity removes an entity registered with PublishEntity
+func (s *UnifiedEntityService) UnpublishEntity(entityID string) {
+	s.mutex.Lock()
+	entity, err := s.registryManager.GetEntityRegistry().GetEntity(entityID)
+	if err == nil {
+		err = s.registryManager.GetEntityRegistry().UnregisterEntity(entityID)
+	}
+	s.mutex.Unlock()
+	if err != nil {
+		return
+	}
+
+	if s.redisCache != nil {
+		if err := s.redisCache.DeleteEntity(context.Background(), entityID); err != nil {
+			s.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to remove entity from Redis cache")
+		}
+	}
+	if s.eventEmitter != nil {
+		s.eventEmitter.BroadcastPMAEntityRemoved(entityID, entity.GetSource())
+	}
+}
+
 // HandleExternalStateChange handles state changes from external sources (physical switches, automations, etc.)
 func (s *UnifiedEntityService) HandleExternalStateChange(ctx context.Context, entityID string, newState string, source types.PMASourceType, metadata map[string]interface{}) error {
 	s.logger.WithFields(logrus.Fields{
@@ -1562,20 +1955,38 @@ func (s *UnifiedEntityService) updateGenericEntityState(entity types.PMAEntity,
 	}).Debug("Updating generic entity state")
 }
 
-// updateEntityStateDirectly updates the state of an entity directly
-func (s *UnifiedEntityService) updateEntityStateDirectly(entity types.PMAEntity, newState string) {
+// entityWithState returns a copy of an entity with a new state, leaving the entity itself
+// untouched. Attribute maps and other references are shared with the original.
+func (s *UnifiedEntityService) entityWithState(entity types.PMAEntity, newState string) types.PMAEntity {
+	withState := func(base *types.PMABaseEntity) *types.PMABaseEntity {
+		updated := *base
+		updated.State = types.PMAEntityState(newState)
+		updated.LastUpdated = time.Now()
+		return &updated
+	}
+
 	switch e := entity.(type) {
 	case *types.PMASwitchEntity:
-		e.State = types.PMAEntityState(newState)
-		e.LastUpdated = time.Now()
+		updated := *e
+		updated.PMABaseEntity = withState(e.PMABaseEntity)
+		return &updated
 	case *types.PMALightEntity:
-		e.State = types.PMAEntityState(newState)
-		e.LastUpdated = time.Now()
+		updated := *e
+		updated.PMABaseEntity = withState(e.PMABaseEntity)
+		return &updated
 	case *types.PMASensorEntity:
-		e.State = types.PMAEntityState(newState)
-		e.LastUpdated = time.Now()
+		updated := *e
+		updated.PMABaseEntity = withState(e.PMABaseEntity)
+		return &updated
+	case *GroupEntity:
+		updated := *e
+		updated.PMABaseEntity = withState(e.PMABaseEntity)
+		return &updated
+	case *types.PMABaseEntity:
+		return withState(e)
 	default:
 		s.logger.WithField("entity_id", entity.GetID()).Warn("Attempted to update state for unknown entity type")
+		return entity
 	}
 }
 
@@ -1588,6 +1999,7 @@ type GetAllOptions struct {
 	IncludeArea   bool                  `json:"include_area,omitempty"`
 	AvailableOnly bool                  `json:"available_only,omitempty"`
 	Capabilities  []types.PMACapability `json:"capabilities,omitempty"`
+	ExcludeHidden bool                  `json:"exclude_hidden,omitempty"` // leave out hidden and disabled entities
 }
 
 // GetEntityOptions defines options for retrieving a single entity
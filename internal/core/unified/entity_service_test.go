@@ -1,12 +1,15 @@
 package unified
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/frostdev-ops/pma-backend-go/internal/config"
 	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUnifiedEntityService_NewService(t *testing.T) {
@@ -20,7 +23,7 @@ func TestUnifiedEntityService_NewService(t *testing.T) {
 	assert.NotNil(t, service.registryManager)
 	assert.NotNil(t, service.typeRegistry)
 	assert.Equal(t, typeRegistry, service.typeRegistry)
-	assert.NotNil(t, service.entityCache)
+	assert.Nil(t, service.redisCache) // Redis is disabled, entities live in the registry only
 }
 
 func TestUnifiedEntityService_GetRegistryManager(t *testing.T) {
@@ -47,3 +50,90 @@ func TestUnifiedEntityService_RegistryComponents(t *testing.T) {
 	assert.NotNil(t, rm.GetConflictResolver())
 	assert.NotNil(t, rm.GetPriorityManager())
 }
+
+func TestUnifiedEntityService_UpdateEntityStateWithoutRedis(t *testing.T) {
+	logger := logrus.New()
+	cfg := &config.Config{}
+	typeRegistry := types.NewPMATypeRegistry(logger)
+	service := NewUnifiedEntityService(typeRegistry, cfg, logger)
+
+	entity := &types.PMABaseEntity{
+		ID:       "light.kitchen",
+		Type:     types.EntityTypeLight,
+		State:    types.StateOff,
+		Metadata: &types.PMAMetadata{Source: types.SourceShelly},
+	}
+	require.NoError(t, service.GetRegistryManager().GetEntityRegistry().RegisterEntity(entity))
+
+	type stateChange struct {
+		entityID           string
+		oldState, newState types.PMAEntityState
+		source             types.PMASourceType
+	}
+	changes := make(chan stateChange, 2)
+	service.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+		changes <- stateChange{entityID, oldState, newState, source}
+	})
+
+	updated, err := service.UpdateEntityState(context.Background(), "light.kitchen", "on", types.SourceShelly)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, types.StateOn, updated.GetState())
+
+	stored, err := service.GetRegistryManager().GetEntityRegistry().GetEntity("light.kitchen")
+	require.NoError(t, err)
+	assert.Equal(t, types.StateOn, stored.GetState())
+
+	// Readers holding the previous registry entity never see it change
+	assert.Equal(t, types.StateOff, entity.GetState())
+
+	select {
+	case change := <-changes:
+		assert.Equal(t, stateChange{"light.kitchen", types.StateOff, types.StateOn, types.SourceShelly}, change)
+	case <-time.After(time.Second):
+		t.Fatal("state change listener was not notified")
+	}
+
+	// An unchanged state is not a change
+	_, err = service.UpdateEntityState(context.Background(), "light.kitchen", "on", types.SourceShelly)
+	require.NoError(t, err)
+	select {
+	case change := <-changes:
+		t.Fatalf("unexpected state change %+v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Unknown entities are ignored
+	updated, err = service.UpdateEntityState(context.Background(), "light.unknown", "on", types.SourceShelly)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+}
+
+func TestUnifiedEntityService_StateChangesReachListenersInOrder(t *testing.T) {
+	logger := logrus.New()
+	service := NewUnifiedEntityService(types.NewPMATypeRegistry(logger), &config.Config{}, logger)
+
+	received := make(chan types.PMAEntityState, 10)
+	service.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+		if newState == types.StateOn {
+			time.Sleep(20 * time.Millisecond) // A slow first delivery must not be overtaken
+		}
+		received <- newState
+	})
+
+	states := []types.PMAEntityState{types.StateOn, types.StateOff, types.StateOn, types.StateOff}
+	previous := types.StateOff
+	for _, state := range states {
+		service.notifyStateChangeListeners("light.kitchen", previous, state, types.SourceShelly)
+		previous = state
+	}
+
+	for i, want := range states {
+		select {
+		case got := <-received:
+			assert.Equal(t, want, got, "change %d", i)
+		case <-time.After(time.Second):
+			t.Fatalf("change %d was not delivered", i)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package unified
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// Group entity errors
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
+	ErrInvalidGroup  = errors.New("invalid group")
+)
+
+// groupEntityInfix separates the entity type from the group ID in group entity IDs, so groups
+// cannot collide with entities from adapters
+const groupEntityInfix = ".group_"
+
+// groupRefreshDelay is how long to wait after a fan-out action before recomputing the group,
+// giving the member refreshes time to land
+const groupRefreshDelay = 2 * time.Second
+
+var groupIDPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// groupActiveStates is the state each groupable type counts as "active" for any/all semantics
+var groupActiveStates = map[types.PMAEntityType]types.PMAEntityState{
+	types.EntityTypeLight:  types.StateOn,
+	types.EntityTypeSwitch: types.StateOn,
+	types.EntityTypeCover:  types.StateOpen,
+	types.EntityTypeLock:   types.StateUnlocked,
+}
+
+// groupInactiveStates is the opposite of groupActiveStates
+var groupInactiveStates = map[types.PMAEntityType]types.PMAEntityState{
+	types.EntityTypeLight:  types.StateOff,
+	types.EntityTypeSwitch: types.StateOff,
+	types.EntityTypeCover:  types.StateClosed,
+	types.EntityTypeLock:   types.StateLocked,
+}
+
+// groupToggleActions maps a toggle to the action that sets every member to the opposite state
+var groupToggleActions = map[types.PMAEntityType][2]string{
+	types.EntityTypeLight:  {"turn_on", "turn_off"},
+	types.EntityTypeSwitch: {"turn_on", "turn_off"},
+	types.EntityTypeCover:  {"open", "close"},
+	types.EntityTypeLock:   {"unlock", "lock"},
+}
+
+// GroupEntity is the virtual entity of a user-defined group
+type GroupEntity struct {
+	*types.PMABaseEntity
+	Members   []string `json:"members"`
+	Mode      string   `json:"mode,omitempty"`
+	Aggregate string   `json:"aggregate,omitempty"`
+}
+
+// GetAvailableActions returns the actions the group fans out to its members
+func (g *GroupEntity) GetAvailableActions() []string {
+	switch g.Type {
+	case types.EntityTypeLight:
+		return []string{"turn_on", "turn_off", "toggle", "set_brightness"}
+	case types.EntityTypeSwitch:
+		return []string{"turn_on", "turn_off", "toggle"}
+	case types.EntityTypeCover:
+		return []string{"open", "close", "stop", "set_position", "toggle"}
+	case types.EntityTypeLock:
+		return []string{"lock", "unlock", "toggle"}
+	}
+	return []string{}
+}
+
+// GroupEntityID returns the entity ID of a group
+func GroupEntityID(group *models.EntityGroup) string {
+	return group.EntityType + groupEntityInfix + group.ID
+}
+
+// groupState holds the loaded groups and which groups each member belongs to
+type groupState struct {
+	mutex    sync.RWMutex
+	repo     repositories.EntityGroupRepository
+	groups   map[string]*models.EntityGroup // keyed by group entity ID
+	byMember map[string][]string            // member entity ID -> group entity IDs
+	watching bool
+}
+
+// SetGroupRepository sets the repository group entities are stored in
+func (s *UnifiedEntityService) SetGroupRepository(repo repositories.EntityGroupRepository) {
+	s.groupState.mutex.Lock()
+	defer s.groupState.mutex.Unlock()
+	s.groupState.repo = repo
+}
+
+// LoadGroups publishes every stored group as an entity and starts recomputing groups when
+// their members change
+func (s *UnifiedEntityService) LoadGroups(ctx context.Context) error {
+	gs := &s.groupState
+	if gs.repo == nil {
+		return fmt.Errorf("group repository not configured")
+	}
+
+	groups, err := gs.repo.ListGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load groups: %w", err)
+	}
+
+	gs.mutex.Lock()
+	gs.groups = make(map[string]*models.EntityGroup, len(groups))
+	for _, group := range groups {
+		gs.groups[GroupEntityID(group)] = group
+	}
+	gs.reindex()
+	startWatching := !gs.watching
+	gs.watching = true
+	gs.mutex.Unlock()
+
+	if startWatching {
+		s.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+			s.recomputeGroupsOf(context.Background(), entityID)
+		})
+	}
+
+	for _, group := range groups {
+		s.recomputeGroup(ctx, GroupEntityID(group))
+	}
+
+	s.logger.WithField("groups", len(groups)).Info("Group entities loaded")
+	return nil
+}
+
+// ListGroups returns all groups
+func (s *UnifiedEntityService) ListGroups() []*models.EntityGroup {
+	gs := &s.groupState
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	groups := make([]*models.EntityGroup, 0, len(gs.groups))
+	for _, group := range gs.groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// GetGroup returns a group by its ID
+func (s *UnifiedEntityService) GetGroup(id string) (*models.EntityGroup, error) {
+	gs := &s.groupState
+	gs.mutex.RLock()
+	defer gs.mutex.RUnlock()
+
+	for _, group := range gs.groups {
+		if group.ID == id {
+			return group, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+}
+
+// CreateGroup stores a new group and publishes its entity
+func (s *UnifiedEntityService) CreateGroup(ctx context.Context, group *models.EntityGroup) error {
+	if err := s.validateGroup(group); err != nil {
+		return err
+	}
+	if _, err := s.GetGroup(group.ID); err == nil {
+		return fmt.Errorf("%w: %s", ErrGroupExists, group.ID)
+	}
+
+	gs := &s.groupState
+	if gs.repo == nil {
+		return fmt.Errorf("group repository not configured")
+	}
+	if err := gs.repo.CreateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	gs.mutex.Lock()
+	if gs.groups == nil {
+		gs.groups = make(map[string]*models.EntityGroup)
+	}
+	gs.groups[GroupEntityID(group)] = group
+	gs.reindex()
+	gs.mutex.Unlock()
+
+	s.recomputeGroup(ctx, GroupEntityID(group))
+	return nil
+}
+
+// UpdateGroup replaces the settings and members of a group
+func (s *UnifiedEntityService) UpdateGroup(ctx context.Context, group *models.EntityGroup) error {
+	existing, err := s.GetGroup(group.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateGroup(group); err != nil {
+		return err
+	}
+
+	gs := &s.groupState
+	group.CreatedAt = existing.CreatedAt
+	if err := gs.repo.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	oldEntityID := GroupEntityID(existing)
+	gs.mutex.Lock()
+	delete(gs.groups, oldEntityID)
+	gs.groups[GroupEntityID(group)] = group
+	gs.reindex()
+	gs.mutex.Unlock()
+
+	// Changing the type changes the entity ID
+	if oldEntityID != GroupEntityID(group) {
+		s.UnpublishEntity(oldEntityID)
+	}
+	s.recomputeGroup(ctx, GroupEntityID(group))
+	return nil
+}
+
+// DeleteGroup deletes a group and removes its entity
+func (s *UnifiedEntityService) DeleteGroup(ctx context.Context, id string) error {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return err
+	}
+
+	gs := &s.groupState
+	if err := gs.repo.DeleteGroup(ctx, id); err != nil {
+		return err
+	}
+
+	gs.mutex.Lock()
+	delete(gs.groups, GroupEntityID(group))
+	gs.reindex()
+	gs.mutex.Unlock()
+
+	s.UnpublishEntity(GroupEntityID(group))
+	return nil
+}
+
+// validateGroup fills in defaults and checks the group against the entities it references
+func (s *UnifiedEntityService) validateGroup(group *models.EntityGroup) error {
+	group.ID = strings.TrimSpace(group.ID)
+	group.Name = strings.TrimSpace(group.Name)
+
+	if !groupIDPattern.MatchString(group.ID) {
+		return fmt.Errorf("%w: id must contain only lowercase letters, digits and underscores", ErrInvalidGroup)
+	}
+	if group.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidGroup)
+	}
+	if len(group.Members) == 0 {
+		return fmt.Errorf("%w: at least one member is required", ErrInvalidGroup)
+	}
+
+	entityType := types.PMAEntityType(group.EntityType)
+	if entityType == types.EntityTypeSensor {
+		group.Mode = ""
+		switch group.Aggregate {
+		case "":
+			group.Aggregate = models.GroupAggregateMean
+		case models.GroupAggregateMin, models.GroupAggregateMax, models.GroupAggregateMean,
+			models.GroupAggregateMedian, models.GroupAggregateSum:
+		default:
+			return fmt.Errorf("%w: unknown aggregate %q", ErrInvalidGroup, group.Aggregate)
+		}
+	} else {
+		if _, ok := groupActiveStates[entityType]; !ok {
+			return fmt.Errorf("%w: entity type must be light, switch, cover, lock or sensor", ErrInvalidGroup)
+		}
+		group.Aggregate = ""
+		switch group.Mode {
+		case "":
+			group.Mode = models.GroupModeAny
+		case models.GroupModeAny, models.GroupModeAll:
+		default:
+			return fmt.Errorf("%w: mode must be any or all", ErrInvalidGroup)
+		}
+	}
+
+	seen := make(map[string]bool, len(group.Members))
+	for _, memberID := range group.Members {
+		if seen[memberID] {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidGroup, memberID)
+		}
+		seen[memberID] = true
+
+		member, err := s.registryManager.GetEntityRegistry().GetEntity(memberID)
+		if err != nil {
+			return fmt.Errorf("%w: member %s not found", ErrInvalidGroup, memberID)
+		}
+		if _, isGroup := member.(*GroupEntity); isGroup {
+			return fmt.Errorf("%w: member %s is a group", ErrInvalidGroup, memberID)
+		}
+		if member.GetType() != entityType {
+			return fmt.Errorf("%w: member %s is a %s, not a %s", ErrInvalidGroup, memberID, member.GetType(), entityType)
+		}
+	}
+
+	return nil
+}
+
+// recomputeGroupsOf recomputes every group the entity is a member of
+func (s *UnifiedEntityService) recomputeGroupsOf(ctx context.Context, entityID string) {
+	s.groupState.mutex.RLock()
+	groupIDs := append([]string(nil), s.groupState.byMember[entityID]...)
+	s.groupState.mutex.RUnlock()
+
+	for _, groupID := range groupIDs {
+		s.recomputeGroup(ctx, groupID)
+	}
+}
+
+// recomputeAllGroups recomputes every group, used after syncs which update members without
+// notifying listeners
+func (s *UnifiedEntityService) recomputeAllGroups(ctx context.Context) {
+	s.groupState.mutex.RLock()
+	groupIDs := make([]string, 0, len(s.groupState.groups))
+	for groupID := range s.groupState.groups {
+		groupIDs = append(groupIDs, groupID)
+	}
+	s.groupState.mutex.RUnlock()
+
+	for _, groupID := range groupIDs {
+		s.recomputeGroup(ctx, groupID)
+	}
+}
+
+// recomputeGroup derives the state of a group from its members and publishes it
+func (s *UnifiedEntityService) recomputeGroup(ctx context.Context, groupEntityID string) {
+	s.groupState.mutex.RLock()
+	group, ok := s.groupState.groups[groupEntityID]
+	s.groupState.mutex.RUnlock()
+	if !ok {
+		return
+	}
+
+	members := make([]types.PMAEntity, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if member, err := s.registryManager.GetEntityRegistry().GetEntity(memberID); err == nil {
+			members = append(members, member)
+		}
+	}
+
+	if err := s.PublishEntity(ctx, buildGroupEntity(group, members)); err != nil {
+		s.logger.WithError(err).WithField("entity_id", groupEntityID).Warn("Failed to publish group entity")
+	}
+}
+
+// executeGroupAction fans an action out to all members of a group in parallel. Sensor groups
+// only aggregate their members and accept no actions.
+func (s *UnifiedEntityService) executeGroupAction(ctx context.Context, group *GroupEntity, action types.PMAControlAction) *types.PMAControlResult {
+	start := time.Now()
+
+	if group.Type == types.EntityTypeSensor {
+		return &types.PMAControlResult{
+			Success:     false,
+			EntityID:    group.ID,
+			Action:      action.Action,
+			ProcessedAt: time.Now(),
+			Error: &types.PMAError{
+				Code:    "UNSUPPORTED_ACTION",
+				Message: fmt.Sprintf("Sensor groups do not support actions: %s", action.Action),
+				Source:  "unified_service",
+			},
+		}
+	}
+
+	memberAction := action.Action
+	if memberAction == "toggle" {
+		toggle := groupToggleActions[group.Type]
+		memberAction = toggle[0]
+		if group.State == groupActiveStates[group.Type] {
+			memberAction = toggle[1]
+		}
+	}
+
+	type memberResult struct {
+		entityID string
+		err      string
+	}
+	results := make(chan memberResult, len(group.Members))
+
+	var wg sync.WaitGroup
+	for _, memberID := range group.Members {
+		wg.Add(1)
+		go func(memberID string) {
+			defer wg.Done()
+
+			result, err := s.ExecuteAction(ctx, types.PMAControlAction{
+				Action:     memberAction,
+				Parameters: action.Parameters,
+				EntityID:   memberID,
+				Context:    action.Context,
+			})
+			switch {
+			case err != nil:
+				results <- memberResult{memberID, err.Error()}
+			case !result.Success && result.Error != nil:
+				results <- memberResult{memberID, result.Error.Message}
+			case !result.Success:
+				results <- memberResult{memberID, "action failed"}
+			default:
+				results <- memberResult{entityID: memberID}
+			}
+		}(memberID)
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := []string{}
+	failed := map[string]string{}
+	for result := range results {
+		if result.err != "" {
+			failed[result.entityID] = result.err
+		} else {
+			succeeded = append(succeeded, result.entityID)
+		}
+	}
+	sort.Strings(succeeded)
+
+	controlResult := &types.PMAControlResult{
+		Success:  len(failed) == 0,
+		EntityID: group.ID,
+		Action:   action.Action,
+		Attributes: map[string]interface{}{
+			"member_action": memberAction,
+			"succeeded":     succeeded,
+			"failed":        failed,
+		},
+		ProcessedAt: time.Now(),
+		Duration:    time.Since(start),
+	}
+	if len(failed) > 0 {
+		code := "PARTIAL_FAILURE"
+		if len(succeeded) == 0 {
+			code = "GROUP_ACTION_FAILED"
+		}
+		controlResult.Error = &types.PMAError{
+			Code:    code,
+			Message: fmt.Sprintf("%d of %d members failed", len(failed), len(group.Members)),
+			Source:  "unified_service",
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"entity_id": group.ID,
+		"action":    memberAction,
+		"succeeded": len(succeeded),
+		"failed":    len(failed),
+	}).Info("Group action executed")
+
+	groupID := group.ID
+	go func() {
+		time.Sleep(groupRefreshDelay)
+		s.recomputeGroup(context.Background(), groupID)
+	}()
+
+	return controlResult
+}
+
+// reindex rebuilds the member index; callers hold the mutex
+func (gs *groupState) reindex() {
+	gs.byMember = make(map[string][]string)
+	for groupEntityID, group := range gs.groups {
+		for _, memberID := range group.Members {
+			gs.byMember[memberID] = append(gs.byMember[memberID], groupEntityID)
+		}
+	}
+}
+
+// buildGroupEntity derives the entity of a group from the current state of its members
+func buildGroupEntity(group *models.EntityGroup, members []types.PMAEntity) *GroupEntity {
+	entityType := types.PMAEntityType(group.EntityType)
+	now := time.Now()
+
+	entity := &GroupEntity{
+		PMABaseEntity: &types.PMABaseEntity{
+			ID:           GroupEntityID(group),
+			Type:         entityType,
+			FriendlyName: group.Name,
+			Icon:         group.Icon,
+			State:        types.StateUnavailable,
+			Attributes: map[string]interface{}{
+				"entity_id":    group.Members,
+				"member_count": len(group.Members),
+				"group_id":     group.ID,
+			},
+			LastUpdated:  now,
+			Capabilities: []types.PMACapability{},
+			Metadata: &types.PMAMetadata{
+				Source:         types.SourcePMA,
+				SourceEntityID: group.ID,
+				LastSynced:     now,
+				QualityScore:   1.0,
+				IsVirtual:      true,
+			},
+		},
+		Members:   group.Members,
+		Mode:      group.Mode,
+		Aggregate: group.Aggregate,
+	}
+	if group.RoomID != "" {
+		roomID := group.RoomID
+		entity.RoomID = &roomID
+	}
+
+	var available []types.PMAEntity
+	for _, member := range members {
+		if member.IsAvailable() && member.GetState() != types.StateUnavailable && member.GetState() != types.StateUnknown {
+			available = append(available, member)
+		}
+	}
+	entity.Attributes["available_count"] = len(available)
+	if len(available) == 0 {
+		return entity
+	}
+	entity.Available = true
+
+	if entityType == types.EntityTypeSensor {
+		applySensorAggregate(entity, group, available)
+		return entity
+	}
+
+	activeState := groupActiveStates[entityType]
+	active := 0
+	var brightness []float64
+	for _, member := range available {
+		state := member.GetState()
+		if state == activeState || (entityType == types.EntityTypeCover && state == "opening") {
+			active++
+			if value, ok := groupToFloat(member.GetAttributes()["brightness"]); ok {
+				brightness = append(brightness, value)
+			}
+		}
+	}
+
+	entity.State = groupInactiveStates[entityType]
+	if (group.Mode == models.GroupModeAll && active == len(available)) || (group.Mode != models.GroupModeAll && active > 0) {
+		entity.State = activeState
+	}
+	entity.Attributes["group_mode"] = group.Mode
+	entity.Attributes["active_count"] = active
+
+	if entityType == types.EntityTypeLight {
+		if len(brightness) > 0 {
+			entity.Attributes["brightness"] = math.Round(aggregate(models.GroupAggregateMean, brightness))
+		}
+		entity.Capabilities = []types.PMACapability{types.CapabilityDimmable}
+	}
+
+	return entity
+}
+
+// applySensorAggregate sets the state of a sensor group to the aggregate of its numeric members
+func applySensorAggregate(entity *GroupEntity, group *models.EntityGroup, members []types.PMAEntity) {
+	var values []float64
+	unit := group.Unit
+	for _, member := range members {
+		value, err := strconv.ParseFloat(string(member.GetState()), 64)
+		if err != nil {
+			continue
+		}
+		values = append(values, value)
+
+		if unit == "" {
+			if sensor, ok := member.(*types.PMASensorEntity); ok && sensor.Unit != "" {
+				unit = sensor.Unit
+			} else if memberUnit, ok := member.GetAttributes()["unit_of_measurement"].(string); ok {
+				unit = memberUnit
+			}
+		}
+	}
+
+	entity.Attributes["aggregate"] = group.Aggregate
+	entity.Attributes["valid_count"] = len(values)
+	if unit != "" {
+		entity.Attributes["unit_of_measurement"] = unit
+	}
+
+	if len(values) == 0 {
+		entity.State = types.StateUnknown
+		return
+	}
+
+	value := math.Round(aggregate(group.Aggregate, values)*100) / 100
+	entity.State = types.PMAEntityState(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// aggregate combines numeric values
+func aggregate(kind string, values []float64) float64 {
+	switch kind {
+	case models.GroupAggregateMin:
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Min(result, v)
+		}
+		return result
+	case models.GroupAggregateMax:
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Max(result, v)
+		}
+		return result
+	case models.GroupAggregateSum:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	case models.GroupAggregateMedian:
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		middle := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[middle-1] + sorted[middle]) / 2
+		}
+		return sorted[middle]
+	default:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	}
+}
+
+func groupToFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,180 @@
+package unified
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteGroupAction_RejectsSensorGroups(t *testing.T) {
+	logger := logrus.New()
+	service := NewUnifiedEntityService(types.NewPMATypeRegistry(logger), &config.Config{}, logger)
+
+	group := &GroupEntity{
+		PMABaseEntity: &types.PMABaseEntity{ID: "sensor.group_temperatures", Type: types.EntityTypeSensor},
+		Members:       []string{"sensor.kitchen_temperature", "sensor.hall_temperature"},
+		Aggregate:     "mean",
+	}
+
+	result := service.executeGroupAction(context.Background(), group, types.PMAControlAction{Action: "toggle"})
+	assert.False(t, result.Success)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "UNSUPPORTED_ACTION", result.Error.Code)
+}
+
+// groupMember returns an available member entity in the given state
+func groupMember(id string, entityType types.PMAEntityType, state types.PMAEntityState) *types.PMABaseEntity {
+	return &types.PMABaseEntity{ID: id, Type: entityType, State: state, Available: true, Attributes: map[string]interface{}{}}
+}
+
+func TestAggregate(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+
+	tests := []struct {
+		kind   string
+		values []float64
+		want   float64
+	}{
+		{models.GroupAggregateMin, values, 1},
+		{models.GroupAggregateMax, values, 4},
+		{models.GroupAggregateSum, values, 10},
+		{models.GroupAggregateMean, values, 2.5},
+		{models.GroupAggregateMedian, values, 2.5},
+		{models.GroupAggregateMedian, []float64{5, 1, 3}, 3},
+		{models.GroupAggregateMedian, []float64{7}, 7},
+		{"", []float64{1, 2}, 1.5},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, aggregate(tt.kind, tt.values), "%s of %v", tt.kind, tt.values)
+	}
+	assert.Equal(t, []float64{4, 1, 3, 2}, values, "median sorts a copy")
+}
+
+func TestApplySensorAggregate(t *testing.T) {
+	sensor := &types.PMASensorEntity{PMABaseEntity: groupMember("sensor.kitchen", types.EntityTypeSensor, "21.5"), Unit: "°C"}
+	text := groupMember("sensor.mode", types.EntityTypeSensor, "heating")
+	attributeUnit := groupMember("sensor.hall", types.EntityTypeSensor, "20")
+	attributeUnit.Attributes["unit_of_measurement"] = "°F"
+
+	t.Run("non-numeric members are skipped", func(t *testing.T) {
+		group := &models.EntityGroup{ID: "temperatures", EntityType: "sensor", Aggregate: models.GroupAggregateMean}
+		entity := buildGroupEntity(group, []types.PMAEntity{text, sensor, attributeUnit})
+
+		assert.Equal(t, types.PMAEntityState("20.75"), entity.State)
+		assert.Equal(t, 2, entity.Attributes["valid_count"])
+		assert.Equal(t, 3, entity.Attributes["available_count"])
+		assert.Equal(t, "°C", entity.Attributes["unit_of_measurement"], "the first numeric member's unit is used")
+	})
+
+	t.Run("only non-numeric members", func(t *testing.T) {
+		group := &models.EntityGroup{ID: "modes", EntityType: "sensor", Aggregate: models.GroupAggregateSum}
+		entity := buildGroupEntity(group, []types.PMAEntity{text})
+
+		assert.Equal(t, types.StateUnknown, entity.State)
+		assert.Equal(t, 0, entity.Attributes["valid_count"])
+		assert.True(t, entity.Available)
+	})
+
+	t.Run("group unit wins", func(t *testing.T) {
+		group := &models.EntityGroup{ID: "temperatures", EntityType: "sensor", Aggregate: models.GroupAggregateMax, Unit: "K"}
+		entity := buildGroupEntity(group, []types.PMAEntity{attributeUnit, sensor})
+
+		assert.Equal(t, types.PMAEntityState("21.5"), entity.State)
+		assert.Equal(t, "K", entity.Attributes["unit_of_measurement"])
+	})
+}
+
+func TestBuildGroupEntityState(t *testing.T) {
+	on := groupMember("light.a", types.EntityTypeLight, types.StateOn)
+	on.Attributes["brightness"] = 200.0
+	dimmed := groupMember("light.b", types.EntityTypeLight, types.StateOn)
+	dimmed.Attributes["brightness"] = 100
+	off := groupMember("light.c", types.EntityTypeLight, types.StateOff)
+	unavailable := groupMember("light.d", types.EntityTypeLight, types.StateUnavailable)
+
+	tests := []struct {
+		name          string
+		entityType    string
+		mode          string
+		members       []types.PMAEntity
+		wantState     types.PMAEntityState
+		wantAvailable bool
+	}{
+		{"any with one on", "light", models.GroupModeAny, []types.PMAEntity{on, off}, types.StateOn, true},
+		{"any with all off", "light", models.GroupModeAny, []types.PMAEntity{off}, types.StateOff, true},
+		{"all with one off", "light", models.GroupModeAll, []types.PMAEntity{on, dimmed, off}, types.StateOff, true},
+		{"all with every member on", "light", models.GroupModeAll, []types.PMAEntity{on, dimmed}, types.StateOn, true},
+		{"all ignores unavailable members", "light", models.GroupModeAll, []types.PMAEntity{on, unavailable}, types.StateOn, true},
+		{"no available members", "light", models.GroupModeAny, []types.PMAEntity{unavailable}, types.StateUnavailable, false},
+		{
+			"opening cover counts as open", "cover", models.GroupModeAll,
+			[]types.PMAEntity{groupMember("cover.a", types.EntityTypeCover, "opening"), groupMember("cover.b", types.EntityTypeCover, types.StateOpen)},
+			types.StateOpen, true,
+		},
+		{
+			"unlocked lock is active", "lock", models.GroupModeAny,
+			[]types.PMAEntity{groupMember("lock.a", types.EntityTypeLock, types.StateLocked), groupMember("lock.b", types.EntityTypeLock, types.StateUnlocked)},
+			types.StateUnlocked, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &models.EntityGroup{ID: "test", EntityType: tt.entityType, Mode: tt.mode}
+			entity := buildGroupEntity(group, tt.members)
+
+			assert.Equal(t, tt.entityType+".group_test", entity.ID)
+			assert.Equal(t, tt.wantState, entity.State)
+			assert.Equal(t, tt.wantAvailable, entity.Available)
+		})
+	}
+
+	t.Run("brightness is the mean of members that are on", func(t *testing.T) {
+		group := &models.EntityGroup{ID: "test", EntityType: "light", Mode: models.GroupModeAny}
+		entity := buildGroupEntity(group, []types.PMAEntity{on, dimmed, off})
+
+		assert.Equal(t, 150.0, entity.Attributes["brightness"])
+		assert.Equal(t, 2, entity.Attributes["active_count"])
+	})
+}
+
+func TestExecuteGroupAction_ToggleDirection(t *testing.T) {
+	logger := logrus.New()
+	service := NewUnifiedEntityService(types.NewPMATypeRegistry(logger), &config.Config{}, logger)
+
+	tests := []struct {
+		entityType types.PMAEntityType
+		state      types.PMAEntityState
+		want       string
+	}{
+		{types.EntityTypeLight, types.StateOn, "turn_off"},
+		{types.EntityTypeLight, types.StateOff, "turn_on"},
+		{types.EntityTypeSwitch, types.StateUnavailable, "turn_on"},
+		{types.EntityTypeCover, types.StateOpen, "close"},
+		{types.EntityTypeCover, types.StateClosed, "open"},
+		{types.EntityTypeLock, types.StateUnlocked, "lock"},
+		{types.EntityTypeLock, types.StateLocked, "unlock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.entityType)+" "+string(tt.state), func(t *testing.T) {
+			group := &GroupEntity{
+				PMABaseEntity: &types.PMABaseEntity{ID: string(tt.entityType) + ".group_test", Type: tt.entityType, State: tt.state},
+				Members:       []string{string(tt.entityType) + ".missing"},
+			}
+
+			// The member does not exist, so the action fails, but the direction is still reported
+			result := service.executeGroupAction(context.Background(), group, types.PMAControlAction{Action: "toggle"})
+			assert.Equal(t, tt.want, result.Attributes["member_action"])
+			require.NotNil(t, result.Error)
+			assert.Equal(t, "GROUP_ACTION_FAILED", result.Error.Code)
+		})
+	}
+}
@@ -585,6 +585,8 @@ func NewConcreteSourcePriorityManager() *ConcreteSourcePriorityManager {
 	manager.priorities[types.SourceShelly] = 70
 	manager.priorities[types.SourceUPS] = 60
 	manager.priorities[types.SourceNetwork] = 50
+	manager.priorities[types.SourceBLE] = 40
+	manager.priorities[types.SourceHelper] = 20
 	manager.priorities[types.SourcePMA] = 10
 
 	return manager
@@ -0,0 +1,641 @@
+package ups
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/adapters/ups"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/system"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+)
+
+// PowerEvent is a change of the mains power situation
+type PowerEvent string
+
+const (
+	PowerEventOnBattery       PowerEvent = "on_battery"
+	PowerEventLowBattery      PowerEvent = "low_battery"
+	PowerEventCriticalBattery PowerEvent = "critical_battery"
+	PowerEventRestored        PowerEvent = "power_restored"
+)
+
+// Remote shutdown types
+const (
+	RemoteShutdownNUTFSD  = "nut_fsd"
+	RemoteShutdownSSH     = "ssh"
+	RemoteShutdownWebhook = "webhook"
+)
+
+// WebSocket message types
+const (
+	MessageTypePowerEvent        = "ups_power_event"
+	MessageTypeShutdownCountdown = "ups_shutdown_countdown"
+)
+
+const (
+	defaultShedAction        = "turn_off"
+	defaultSSHCommand        = "sudo shutdown -h now"
+	defaultShutdownCountdown = 2 * time.Minute
+	remoteShutdownTimeout    = 30 * time.Second
+	countdownBroadcastEvery  = 10 * time.Second
+)
+
+// powerLevels orders the outage events by severity
+var powerLevels = []PowerEvent{PowerEventOnBattery, PowerEventLowBattery, PowerEventCriticalBattery}
+
+// EntityController executes the entity actions of load shedding stages
+type EntityController interface {
+	ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error)
+}
+
+// SystemShutdowner shuts down the host PMA runs on
+type SystemShutdowner interface {
+	ShutdownSystem(ctx context.Context, action system.PowerAction) error
+}
+
+// PowerEventHandler is called for every power event
+type PowerEventHandler func(event PowerEvent, status *models.UPSStatus)
+
+// loadShedStage is a parsed UPSLoadShedStage
+type loadShedStage struct {
+	config.UPSLoadShedStage
+	on    PowerEvent
+	delay time.Duration
+}
+
+// remoteShutdown is a parsed UPSRemoteShutdown
+type remoteShutdown struct {
+	config.UPSRemoteShutdown
+	on    PowerEvent
+	delay time.Duration
+}
+
+// PowerState reports the current power situation and pending steps
+type PowerState struct {
+	Event           PowerEvent `json:"event"` // last event, "" while nothing happened
+	OnBattery       bool       `json:"on_battery"`
+	OnBatterySince  *time.Time `json:"on_battery_since,omitempty"`
+	BatteryCharge   float64    `json:"battery_charge"`
+	BatteryRuntime  int        `json:"battery_runtime"`
+	CompletedSteps  []string   `json:"completed_steps"`
+	PendingSteps    []string   `json:"pending_steps"`
+	ShutdownPending bool       `json:"shutdown_pending"`
+	ShutdownAt      *time.Time `json:"shutdown_at,omitempty"`
+}
+
+// PowerManager turns UPS status changes into power events and runs the configured power
+// failure sequence: load shedding, remote shutdowns and finally a local shutdown countdown.
+type PowerManager struct {
+	upsName         string
+	nutHost         string
+	nutPort         int
+	lowBattery      float64
+	criticalBattery float64
+	stages          []loadShedStage
+	remotes         []remoteShutdown
+	shutdownEnabled bool
+	shutdownOn      PowerEvent
+	countdown       time.Duration
+
+	entities   EntityController
+	shutdowner SystemShutdowner
+	wsHub      WSHub
+	logger     *logrus.Logger
+	httpClient *http.Client
+
+	mutex          sync.Mutex
+	handlers       []PowerEventHandler
+	level          int // index+1 into powerLevels, 0 while on mains power
+	lastEvent      PowerEvent
+	lastStatus     *models.UPSStatus
+	onBatterySince *time.Time
+	outageCtx      context.Context
+	cancelOutage   context.CancelFunc
+	completed      []string
+	pending        map[string]bool
+	cancelShutdown context.CancelFunc
+	shutdownAt     *time.Time
+}
+
+// NewPowerManager validates the power configuration of a UPS
+func NewPowerManager(cfg config.UPSPowerConfig, nutHost string, nutPort int, upsName string, wsHub WSHub, logger *logrus.Logger) (*PowerManager, error) {
+	pm := &PowerManager{
+		upsName:         upsName,
+		nutHost:         nutHost,
+		nutPort:         nutPort,
+		lowBattery:      cfg.LowBattery,
+		criticalBattery: cfg.CriticalBattery,
+		shutdownEnabled: cfg.Shutdown.Enabled,
+		countdown:       defaultShutdownCountdown,
+		wsHub:           wsHub,
+		logger:          logger,
+		httpClient:      &http.Client{Timeout: remoteShutdownTimeout},
+		pending:         make(map[string]bool),
+	}
+	if pm.lowBattery <= 0 {
+		pm.lowBattery = 20
+	}
+	if pm.criticalBattery <= 0 {
+		pm.criticalBattery = 10
+	}
+
+	for i, stage := range cfg.Stages {
+		on, delay, err := parseTrigger(stage.On, stage.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i, err)
+		}
+		if stage.Name == "" {
+			stage.Name = fmt.Sprintf("stage %d", i+1)
+		}
+		if stage.Action == "" {
+			stage.Action = defaultShedAction
+		}
+		if len(stage.Entities) == 0 {
+			return nil, fmt.Errorf("stage %s: no entities", stage.Name)
+		}
+		pm.stages = append(pm.stages, loadShedStage{UPSLoadShedStage: stage, on: on, delay: delay})
+	}
+
+	for i, remote := range cfg.RemoteShutdowns {
+		on, delay, err := parseTrigger(remote.On, remote.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("remote shutdown %d: %w", i, err)
+		}
+		if remote.Name == "" {
+			remote.Name = fmt.Sprintf("%s %d", remote.Type, i+1)
+		}
+		switch remote.Type {
+		case RemoteShutdownNUTFSD:
+			if remote.Host == "" {
+				remote.Host = nutHost
+			}
+			if remote.Port == 0 {
+				remote.Port = nutPort
+			}
+			if remote.UPSName == "" {
+				remote.UPSName = upsName
+			}
+		case RemoteShutdownSSH:
+			if remote.Host == "" {
+				return nil, fmt.Errorf("remote shutdown %s: host is required", remote.Name)
+			}
+			if remote.Command == "" {
+				remote.Command = defaultSSHCommand
+			}
+		case RemoteShutdownWebhook:
+			if remote.URL == "" {
+				return nil, fmt.Errorf("remote shutdown %s: url is required", remote.Name)
+			}
+		default:
+			return nil, fmt.Errorf("remote shutdown %s: unknown type %q", remote.Name, remote.Type)
+		}
+		pm.remotes = append(pm.remotes, remoteShutdown{UPSRemoteShutdown: remote, on: on, delay: delay})
+	}
+
+	if cfg.Shutdown.Enabled {
+		on, countdown, err := parseTrigger(cfg.Shutdown.On, cfg.Shutdown.Countdown)
+		if err != nil {
+			return nil, fmt.Errorf("shutdown: %w", err)
+		}
+		if on == PowerEventRestored {
+			return nil, fmt.Errorf("shutdown: cannot run on %s", on)
+		}
+		pm.shutdownOn = on
+		if countdown > 0 {
+			pm.countdown = countdown
+		}
+	}
+
+	return pm, nil
+}
+
+// SetEntityController sets the controller used for load shedding
+func (pm *PowerManager) SetEntityController(entities EntityController) {
+	pm.entities = entities
+}
+
+// SetSystemShutdowner sets the service that shuts down the local host
+func (pm *PowerManager) SetSystemShutdowner(shutdowner SystemShutdowner) {
+	pm.shutdowner = shutdowner
+}
+
+// OnEvent registers a handler for power events
+func (pm *PowerManager) OnEvent(handler PowerEventHandler) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.handlers = append(pm.handlers, handler)
+}
+
+// Observe evaluates a UPS status and raises the power events it implies. Events are raised
+// once per outage and in order of severity. The charge thresholds apply only when the UPS
+// reports its battery charge; otherwise only its low battery flag counts.
+func (pm *PowerManager) Observe(status *models.UPSStatus, chargeReported bool) {
+	onBattery, lowFlag := parseUPSFlags(status.Status)
+
+	level := 0
+	if onBattery {
+		level = 1
+		if lowFlag || (chargeReported && status.BatteryCharge <= pm.lowBattery) {
+			level = 2
+		}
+		if chargeReported && status.BatteryCharge <= pm.criticalBattery {
+			level = 3
+		}
+	}
+
+	pm.mutex.Lock()
+	pm.lastStatus = status
+	previous := pm.level
+	if level > previous {
+		pm.level = level
+	}
+	if level == 0 {
+		pm.level = 0
+	}
+	pm.mutex.Unlock()
+
+	switch {
+	case level == 0 && previous > 0:
+		pm.raise(PowerEventRestored, status)
+	case level > previous:
+		for _, event := range powerLevels[previous:level] {
+			pm.raise(event, status)
+		}
+	}
+}
+
+// raise records an event, notifies handlers and starts the steps configured for it
+func (pm *PowerManager) raise(event PowerEvent, status *models.UPSStatus) {
+	pm.mutex.Lock()
+	pm.lastEvent = event
+	if event == PowerEventOnBattery {
+		// A new outage supersedes steps still waiting from the last restoration
+		if pm.cancelOutage != nil {
+			pm.cancelOutage()
+		}
+		now := time.Now()
+		pm.onBatterySince = &now
+		pm.completed = nil
+		pm.pending = make(map[string]bool)
+		pm.outageCtx, pm.cancelOutage = context.WithCancel(context.Background())
+	}
+	if event == PowerEventRestored {
+		// Whatever has not happened yet is no longer needed
+		if pm.cancelOutage != nil {
+			pm.cancelOutage()
+		}
+		if pm.cancelShutdown != nil {
+			pm.cancelShutdown()
+			pm.cancelShutdown = nil
+			pm.shutdownAt = nil
+		}
+		pm.onBatterySince = nil
+		pm.pending = make(map[string]bool)
+		pm.outageCtx, pm.cancelOutage = context.WithCancel(context.Background())
+	}
+	if pm.outageCtx == nil {
+		pm.outageCtx, pm.cancelOutage = context.WithCancel(context.Background())
+	}
+	ctx := pm.outageCtx
+	handlers := append([]PowerEventHandler(nil), pm.handlers...)
+	pm.mutex.Unlock()
+
+	pm.logger.WithFields(logrus.Fields{
+		"event":          event,
+		"ups_status":     status.Status,
+		"battery_charge": status.BatteryCharge,
+	}).Warn("UPS power event")
+
+	if pm.wsHub != nil {
+		pm.wsHub.BroadcastToTopic("ups", MessageTypePowerEvent, map[string]interface{}{
+			"event":           event,
+			"ups_status":      status.Status,
+			"battery_charge":  status.BatteryCharge,
+			"battery_runtime": status.BatteryRuntime,
+			"timestamp":       time.Now(),
+		})
+	}
+	for _, handler := range handlers {
+		handler(event, status)
+	}
+
+	for _, stage := range pm.stages {
+		if stage.on == event {
+			stage := stage
+			pm.schedule(ctx, "load shedding "+stage.Name, stage.delay, func(ctx context.Context) error {
+				return pm.shedLoad(ctx, stage)
+			})
+		}
+	}
+	for _, remote := range pm.remotes {
+		if remote.on == event {
+			remote := remote
+			pm.schedule(ctx, "remote shutdown "+remote.Name, remote.delay, func(ctx context.Context) error {
+				return pm.shutdownRemote(ctx, remote, event, status)
+			})
+		}
+	}
+	if pm.shutdownEnabled && pm.shutdownOn == event {
+		pm.startShutdownCountdown(ctx, event)
+	}
+}
+
+// schedule runs a step after its delay unless the outage context is cancelled first
+func (pm *PowerManager) schedule(ctx context.Context, name string, delay time.Duration, step func(ctx context.Context) error) {
+	pm.mutex.Lock()
+	pm.pending[name] = true
+	pm.mutex.Unlock()
+
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			pm.logger.WithField("step", name).Info("Power failure step cancelled")
+			return
+		case <-timer.C:
+		}
+
+		err := step(ctx)
+
+		pm.mutex.Lock()
+		delete(pm.pending, name)
+		result := name
+		if err != nil {
+			result = fmt.Sprintf("%s (failed: %v)", name, err)
+		}
+		pm.completed = append(pm.completed, result)
+		pm.mutex.Unlock()
+
+		if err != nil {
+			pm.logger.WithError(err).WithField("step", name).Error("Power failure step failed")
+		} else {
+			pm.logger.WithField("step", name).Info("Power failure step completed")
+		}
+	}()
+}
+
+// shedLoad runs the stage action on each of its entities
+func (pm *PowerManager) shedLoad(ctx context.Context, stage loadShedStage) error {
+	if pm.entities == nil {
+		return fmt.Errorf("no entity controller")
+	}
+
+	failed := 0
+	for _, entityID := range stage.Entities {
+		_, err := pm.entities.ExecuteAction(ctx, types.PMAControlAction{
+			EntityID:   entityID,
+			Action:     stage.Action,
+			Parameters: stage.Params,
+			Context: &types.PMAContext{
+				ID:          fmt.Sprintf("ups_power_%d", time.Now().UnixNano()),
+				Source:      "ups_power",
+				Description: "Load shedding: " + stage.Name,
+				Timestamp:   time.Now(),
+			},
+		})
+		if err != nil {
+			failed++
+			pm.logger.WithError(err).WithField("entity_id", entityID).Warn("Failed to shed load")
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entities failed", failed, len(stage.Entities))
+	}
+	return nil
+}
+
+// shutdownRemote shuts down another host
+func (pm *PowerManager) shutdownRemote(ctx context.Context, remote remoteShutdown, event PowerEvent, status *models.UPSStatus) error {
+	ctx, cancel := context.WithTimeout(ctx, remoteShutdownTimeout)
+	defer cancel()
+
+	switch remote.Type {
+	case RemoteShutdownNUTFSD:
+		client := ups.NewNUTClient(remote.Host, remote.Port, pm.logger)
+		if err := client.Connect(ctx); err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.ForcedShutdown(ctx, remote.UPSName, remote.Username, remote.Password)
+
+	case RemoteShutdownSSH:
+		target := remote.Host
+		if remote.Username != "" {
+			target = remote.Username + "@" + remote.Host
+		}
+		args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+		if remote.Port != 0 {
+			args = append(args, "-p", strconv.Itoa(remote.Port))
+		}
+		if remote.KeyFile != "" {
+			args = append(args, "-i", remote.KeyFile)
+		}
+		args = append(args, target, remote.Command)
+
+		output, err := exec.CommandContext(ctx, "ssh", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("ssh: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+
+	case RemoteShutdownWebhook:
+		body, err := json.Marshal(map[string]interface{}{
+			"event":           event,
+			"ups":             pm.upsName,
+			"ups_status":      status.Status,
+			"battery_charge":  status.BatteryCharge,
+			"battery_runtime": status.BatteryRuntime,
+			"timestamp":       time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, remote.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if remote.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+remote.Token)
+		}
+
+		resp, err := pm.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown remote shutdown type %q", remote.Type)
+}
+
+// startShutdownCountdown shuts down the local host when the countdown ends, unless power
+// returns or the countdown is cancelled
+func (pm *PowerManager) startShutdownCountdown(outageCtx context.Context, event PowerEvent) {
+	pm.mutex.Lock()
+	if pm.cancelShutdown != nil {
+		pm.mutex.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(outageCtx)
+	shutdownAt := time.Now().Add(pm.countdown)
+	pm.cancelShutdown = cancel
+	pm.shutdownAt = &shutdownAt
+	pm.mutex.Unlock()
+
+	pm.logger.WithFields(logrus.Fields{"event": event, "shutdown_at": shutdownAt}).Warn("System shutdown countdown started")
+
+	go func() {
+		ticker := time.NewTicker(countdownBroadcastEvery)
+		defer ticker.Stop()
+		timer := time.NewTimer(pm.countdown)
+		defer timer.Stop()
+
+		pm.broadcastCountdown("running", shutdownAt)
+		for {
+			select {
+			case <-ctx.Done():
+				pm.logger.Info("System shutdown countdown cancelled")
+				pm.broadcastCountdown("cancelled", shutdownAt)
+				return
+			case <-ticker.C:
+				pm.broadcastCountdown("running", shutdownAt)
+			case <-timer.C:
+				// A failed shutdown must not keep later countdowns from starting
+				pm.mutex.Lock()
+				if pm.shutdownAt != nil && pm.shutdownAt.Equal(shutdownAt) {
+					pm.cancelShutdown = nil
+					pm.shutdownAt = nil
+				}
+				pm.mutex.Unlock()
+				cancel()
+
+				pm.broadcastCountdown("shutting_down", shutdownAt)
+				pm.shutdownLocal(event)
+				return
+			}
+		}
+	}()
+}
+
+func (pm *PowerManager) shutdownLocal(event PowerEvent) {
+	if pm.shutdowner == nil {
+		pm.logger.Error("UPS shutdown due but no system service is available")
+		return
+	}
+
+	err := pm.shutdowner.ShutdownSystem(context.Background(), system.PowerAction{
+		Action:    "shutdown",
+		Reason:    fmt.Sprintf("UPS %s", event),
+		RequestBy: "ups_power",
+	})
+	if err != nil {
+		pm.logger.WithError(err).Error("UPS-initiated system shutdown failed")
+	}
+}
+
+func (pm *PowerManager) broadcastCountdown(state string, shutdownAt time.Time) {
+	if pm.wsHub == nil {
+		return
+	}
+	remaining := time.Until(shutdownAt).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	pm.wsHub.BroadcastToTopic("ups", MessageTypeShutdownCountdown, map[string]interface{}{
+		"state":             state,
+		"shutdown_at":       shutdownAt,
+		"remaining_seconds": int(remaining.Seconds()),
+	})
+}
+
+// CancelShutdown stops a running shutdown countdown. It reports false when none was running.
+func (pm *PowerManager) CancelShutdown() bool {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if pm.cancelShutdown == nil {
+		return false
+	}
+	pm.cancelShutdown()
+	pm.cancelShutdown = nil
+	pm.shutdownAt = nil
+	pm.logger.Warn("System shutdown countdown cancelled by user")
+	return true
+}
+
+// State returns the current power situation
+func (pm *PowerManager) State() *PowerState {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	state := &PowerState{
+		Event:           pm.lastEvent,
+		OnBattery:       pm.level > 0,
+		OnBatterySince:  pm.onBatterySince,
+		CompletedSteps:  append([]string{}, pm.completed...),
+		PendingSteps:    make([]string, 0, len(pm.pending)),
+		ShutdownPending: pm.cancelShutdown != nil,
+		ShutdownAt:      pm.shutdownAt,
+	}
+	for name := range pm.pending {
+		state.PendingSteps = append(state.PendingSteps, name)
+	}
+	if pm.lastStatus != nil {
+		state.BatteryCharge = pm.lastStatus.BatteryCharge
+		state.BatteryRuntime = pm.lastStatus.BatteryRuntime
+	}
+	return state
+}
+
+// parseUPSFlags reads the NUT ups.status flags, e.g. "OB DISCHRG LB"
+func parseUPSFlags(status string) (onBattery, lowBattery bool) {
+	for _, flag := range strings.Fields(status) {
+		switch flag {
+		case "OB":
+			onBattery = true
+		case "LB":
+			lowBattery = true
+		}
+	}
+	return onBattery, lowBattery
+}
+
+func parseTrigger(on, delay string) (PowerEvent, time.Duration, error) {
+	event := PowerEvent(on)
+	switch event {
+	case PowerEventOnBattery, PowerEventLowBattery, PowerEventCriticalBattery, PowerEventRestored:
+	case "":
+		event = PowerEventOnBattery
+	default:
+		return "", 0, fmt.Errorf("unknown power event %q", on)
+	}
+
+	if delay == "" {
+		return event, 0, nil
+	}
+	duration, err := time.ParseDuration(delay)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid delay %q: %w", delay, err)
+	}
+	return event, duration, nil
+}
@@ -0,0 +1,247 @@
+package ups
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/system"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingController records the entity actions of load shedding
+type recordingController struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (c *recordingController) ExecuteAction(ctx context.Context, action types.PMAControlAction) (*types.PMAControlResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.actions = append(c.actions, action.Action+" "+action.EntityID)
+	return &types.PMAControlResult{Success: true}, nil
+}
+
+func (c *recordingController) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.actions...)
+}
+
+// recordingShutdowner counts local shutdowns
+type recordingShutdowner struct {
+	mu        sync.Mutex
+	shutdowns int
+}
+
+func (s *recordingShutdowner) ShutdownSystem(ctx context.Context, action system.PowerAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdowns++
+	return nil
+}
+
+func (s *recordingShutdowner) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdowns
+}
+
+func newTestPowerManager(t *testing.T, cfg config.UPSPowerConfig) (*PowerManager, *recordingController, *recordingShutdowner) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	pm, err := NewPowerManager(cfg, "localhost", 3493, "ups", nil, logger)
+	require.NoError(t, err)
+
+	controller := &recordingController{}
+	shutdowner := &recordingShutdowner{}
+	pm.SetEntityController(controller)
+	pm.SetSystemShutdowner(shutdowner)
+	return pm, controller, shutdowner
+}
+
+// recordEvents returns a function listing the events raised so far
+func recordEvents(pm *PowerManager) func() []PowerEvent {
+	var mu sync.Mutex
+	var events []PowerEvent
+	pm.OnEvent(func(event PowerEvent, status *models.UPSStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	return func() []PowerEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]PowerEvent(nil), events...)
+	}
+}
+
+func TestNewPowerManagerValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.UPSPowerConfig
+	}{
+		{"unknown event", config.UPSPowerConfig{Stages: []config.UPSLoadShedStage{{On: "brownout", Entities: []string{"switch.a"}}}}},
+		{"invalid delay", config.UPSPowerConfig{Stages: []config.UPSLoadShedStage{{Delay: "soon", Entities: []string{"switch.a"}}}}},
+		{"stage without entities", config.UPSPowerConfig{Stages: []config.UPSLoadShedStage{{Name: "empty"}}}},
+		{"ssh without host", config.UPSPowerConfig{RemoteShutdowns: []config.UPSRemoteShutdown{{Type: RemoteShutdownSSH}}}},
+		{"webhook without url", config.UPSPowerConfig{RemoteShutdowns: []config.UPSRemoteShutdown{{Type: RemoteShutdownWebhook}}}},
+		{"unknown remote type", config.UPSPowerConfig{RemoteShutdowns: []config.UPSRemoteShutdown{{Type: "ipmi"}}}},
+		{"shutdown on restore", config.UPSPowerConfig{Shutdown: config.UPSShutdownConfig{Enabled: true, On: string(PowerEventRestored)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPowerManager(tt.cfg, "localhost", 3493, "ups", nil, logrus.New())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestObserveRaisesEventsInOrder(t *testing.T) {
+	pm, _, _ := newTestPowerManager(t, config.UPSPowerConfig{LowBattery: 30, CriticalBattery: 10})
+	events := recordEvents(pm)
+
+	pm.Observe(&models.UPSStatus{Status: "OL", BatteryCharge: 100}, true)
+	assert.Empty(t, events(), "nothing happens on mains power")
+
+	// A sudden drop raises every skipped level in order
+	pm.Observe(&models.UPSStatus{Status: "OB DISCHRG", BatteryCharge: 8}, true)
+	assert.Equal(t, []PowerEvent{PowerEventOnBattery, PowerEventLowBattery, PowerEventCriticalBattery}, events())
+
+	// Events are raised once per outage, even if the charge recovers while on battery
+	pm.Observe(&models.UPSStatus{Status: "OB DISCHRG", BatteryCharge: 50}, true)
+	pm.Observe(&models.UPSStatus{Status: "OB DISCHRG", BatteryCharge: 5}, true)
+	assert.Len(t, events(), 3)
+
+	pm.Observe(&models.UPSStatus{Status: "OL CHRG", BatteryCharge: 9}, true)
+	assert.Equal(t, PowerEventRestored, events()[3])
+	assert.False(t, pm.State().OnBattery)
+
+	// The next outage starts over
+	pm.Observe(&models.UPSStatus{Status: "OB", BatteryCharge: 90}, true)
+	assert.Equal(t, PowerEventOnBattery, events()[4])
+	assert.True(t, pm.State().OnBattery)
+}
+
+func TestObserveLowBatteryFlag(t *testing.T) {
+	pm, _, _ := newTestPowerManager(t, config.UPSPowerConfig{})
+	events := recordEvents(pm)
+
+	// Without a reported charge only the LB flag counts
+	pm.Observe(&models.UPSStatus{Status: "OB", BatteryCharge: 0}, false)
+	assert.Equal(t, []PowerEvent{PowerEventOnBattery}, events())
+
+	pm.Observe(&models.UPSStatus{Status: "OB LB", BatteryCharge: 0}, false)
+	assert.Equal(t, []PowerEvent{PowerEventOnBattery, PowerEventLowBattery}, events())
+}
+
+func TestLoadSheddingStages(t *testing.T) {
+	pm, controller, _ := newTestPowerManager(t, config.UPSPowerConfig{
+		LowBattery: 30,
+		Stages: []config.UPSLoadShedStage{
+			{Name: "comfort", Entities: []string{"switch.heater", "light.garden"}},
+			{Name: "media", On: string(PowerEventLowBattery), Action: "turn_off", Entities: []string{"switch.tv"}},
+			{Name: "later", Delay: "1h", Entities: []string{"switch.freezer"}},
+		},
+	})
+
+	pm.Observe(&models.UPSStatus{Status: "OB", BatteryCharge: 80}, true)
+	require.Eventually(t, func() bool { return len(controller.recorded()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.ElementsMatch(t, []string{"turn_off switch.heater", "turn_off light.garden"}, controller.recorded())
+	assert.Equal(t, []string{"load shedding later"}, pm.State().PendingSteps)
+
+	pm.Observe(&models.UPSStatus{Status: "OB", BatteryCharge: 25}, true)
+	require.Eventually(t, func() bool { return len(controller.recorded()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "turn_off switch.tv", controller.recorded()[2])
+
+	state := pm.State()
+	assert.ElementsMatch(t, []string{"load shedding comfort", "load shedding media"}, state.CompletedSteps)
+
+	// Restoring power cancels the delayed stage
+	pm.Observe(&models.UPSStatus{Status: "OL", BatteryCharge: 25}, true)
+	assert.Empty(t, pm.State().PendingSteps)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, controller.recorded(), 3)
+}
+
+func TestShutdownCountdown(t *testing.T) {
+	t.Run("cancelled when power returns", func(t *testing.T) {
+		pm, _, shutdowner := newTestPowerManager(t, config.UPSPowerConfig{
+			Shutdown: config.UPSShutdownConfig{Enabled: true, On: string(PowerEventOnBattery), Countdown: "100ms"},
+		})
+
+		pm.Observe(&models.UPSStatus{Status: "OB"}, false)
+		state := pm.State()
+		assert.True(t, state.ShutdownPending)
+		require.NotNil(t, state.ShutdownAt)
+
+		pm.Observe(&models.UPSStatus{Status: "OL"}, false)
+		assert.False(t, pm.State().ShutdownPending)
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Zero(t, shutdowner.count())
+	})
+
+	t.Run("cancelled by the user", func(t *testing.T) {
+		pm, _, shutdowner := newTestPowerManager(t, config.UPSPowerConfig{
+			Shutdown: config.UPSShutdownConfig{Enabled: true, Countdown: "100ms"},
+		})
+
+		assert.False(t, pm.CancelShutdown(), "no countdown is running")
+		pm.Observe(&models.UPSStatus{Status: "OB"}, false)
+		assert.True(t, pm.CancelShutdown())
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Zero(t, shutdowner.count())
+	})
+
+	t.Run("shuts down when it ends", func(t *testing.T) {
+		pm, _, shutdowner := newTestPowerManager(t, config.UPSPowerConfig{
+			CriticalBattery: 10,
+			Shutdown:        config.UPSShutdownConfig{Enabled: true, On: string(PowerEventCriticalBattery), Countdown: "20ms"},
+		})
+
+		pm.Observe(&models.UPSStatus{Status: "OB", BatteryCharge: 50}, true)
+		assert.False(t, pm.State().ShutdownPending, "the countdown waits for its event")
+
+		pm.Observe(&models.UPSStatus{Status: "OB", BatteryCharge: 5}, true)
+		require.Eventually(t, func() bool { return shutdowner.count() == 1 }, time.Second, 5*time.Millisecond)
+		assert.False(t, pm.State().ShutdownPending)
+	})
+}
+
+func TestRemoteShutdownWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pm, _, _ := newTestPowerManager(t, config.UPSPowerConfig{
+		RemoteShutdowns: []config.UPSRemoteShutdown{{Name: "nas", Type: RemoteShutdownWebhook, URL: server.URL, Token: "t0ken"}},
+	})
+
+	pm.Observe(&models.UPSStatus{Status: "OB"}, false)
+	require.Eventually(t, func() bool { return len(pm.State().CompletedSteps) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"remote shutdown nas"}, pm.State().CompletedSteps)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "Bearer t0ken", authorization)
+}
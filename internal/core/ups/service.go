@@ -20,6 +20,7 @@ type Service struct {
 	monitoring bool
 	config     Config
 	stopChan   chan struct{}
+	power      *PowerManager
 }
 
 // WSHub interface for WebSocket broadcasting
@@ -99,6 +100,11 @@ func NewService(config Config, upsRepo repositories.UPSRepository, wsHub WSHub,
 	}
 }
 
+// SetPowerManager makes monitored status changes drive the power failure sequence
+func (s *Service) SetPowerManager(power *PowerManager) {
+	s.power = power
+}
+
 // GetCurrentStatus retrieves the current UPS status from NUT and database
 func (s *Service) GetCurrentStatus(ctx context.Context) (*UPSStatus, error) {
 	// Get latest database status
@@ -143,15 +149,22 @@ func (s *Service) GetCurrentStatus(ctx context.Context) (*UPSStatus, error) {
 
 // getLiveUPSStatus gets current UPS status from NUT server
 func (s *Service) getLiveUPSStatus(ctx context.Context) (*models.UPSStatus, error) {
+	status, _, err := s.readUPSStatus(ctx)
+	return status, err
+}
+
+// readUPSStatus gets current UPS status from NUT server and whether it reported the battery
+// charge, which the status otherwise shows as 0
+func (s *Service) readUPSStatus(ctx context.Context) (*models.UPSStatus, bool, error) {
 	if err := s.nutClient.Connect(ctx); err != nil {
-		return nil, fmt.Errorf("failed to connect to NUT server: %w", err)
+		return nil, false, fmt.Errorf("failed to connect to NUT server: %w", err)
 	}
 	defer s.nutClient.Close()
 
 	// Get UPS data from NUT
 	upsData, err := s.nutClient.GetUPSData(ctx, s.config.UPSName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get UPS data: %w", err)
+		return nil, false, fmt.Errorf("failed to get UPS data: %w", err)
 	}
 
 	// Convert to database model
@@ -166,7 +179,7 @@ func (s *Service) getLiveUPSStatus(ctx context.Context) (*models.UPSStatus, erro
 		LastUpdated:    time.Now(),
 	}
 
-	return status, nil
+	return status, upsData.BatteryCharge != nil, nil
 }
 
 // getFloatValue safely extracts float value from pointer
@@ -229,11 +242,15 @@ func (s *Service) monitoringLoop(ctx context.Context) {
 
 // collectAndStoreStatus collects current UPS status and stores it
 func (s *Service) collectAndStoreStatus(ctx context.Context) error {
-	status, err := s.getLiveUPSStatus(ctx)
+	status, chargeReported, err := s.readUPSStatus(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get live UPS status: %w", err)
 	}
 
+	if s.power != nil {
+		s.power.Observe(status, chargeReported)
+	}
+
 	// Store in database
 	if err := s.upsRepo.CreateStatus(ctx, status); err != nil {
 		return fmt.Errorf("failed to store UPS status: %w", err)
@@ -0,0 +1,45 @@
+package voice
+
+import (
+	"github.com/frostdev-ops/pma-backend-go/internal/core/kiosk"
+	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
+)
+
+// RegisterHandlers registers the voice protocol messages with the WebSocket hub. Sessions end
+// when their client disconnects.
+func (s *Service) RegisterHandlers(hub *websocket.Hub) {
+	hub.RegisterMessageHandler(MessageTypeVoiceStart, s.handleStartMessage)
+	hub.RegisterMessageHandler(MessageTypeVoiceAudio, s.handleAudioMessage)
+	hub.RegisterMessageHandler(MessageTypeVoiceStop, s.handleStopMessage)
+	hub.RegisterMessageHandler(MessageTypeVoiceCancel, s.handleCancelMessage)
+	hub.OnClientDisconnect(s.handleClientDisconnect)
+}
+
+func (s *Service) handleStartMessage(client *websocket.Client, msg websocket.Message) {
+	s.StartSession(client.ID, clientSpeaker(client), client, msg.Data)
+}
+
+func (s *Service) handleAudioMessage(client *websocket.Client, msg websocket.Message) {
+	s.WriteAudio(client.ID, msg.Data)
+}
+
+func (s *Service) handleStopMessage(client *websocket.Client, msg websocket.Message) {
+	s.StopSession(client.ID)
+}
+
+func (s *Service) handleCancelMessage(client *websocket.Client, msg websocket.Message) {
+	s.CancelSession(client.ID)
+}
+
+func (s *Service) handleClientDisconnect(client *websocket.Client) {
+	s.CancelSession(client.ID)
+}
+
+// clientSpeaker identifies who is speaking: the kiosk the client registered as, or the client
+// itself. Voice conversations are kept per speaker.
+func clientSpeaker(client *websocket.Client) string {
+	if kioskID, ok := kiosk.ClientKioskID(client); ok {
+		return "kiosk:" + kioskID
+	}
+	return "voice:" + client.ID
+}
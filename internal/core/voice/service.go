@@ -0,0 +1,585 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// WebSocket message types used by the voice protocol
+const (
+	// Client -> server
+	MessageTypeVoiceStart  = "voice_start"
+	MessageTypeVoiceAudio  = "voice_audio"
+	MessageTypeVoiceStop   = "voice_stop"
+	MessageTypeVoiceCancel = "voice_cancel"
+
+	// Server -> client
+	MessageTypeVoiceStarted      = "voice_started"
+	MessageTypeVoiceWakeDetected = "voice_wake_detected"
+	MessageTypeVoiceTranscript   = "voice_transcript"
+	MessageTypeVoiceResponse     = "voice_response"
+	MessageTypeVoiceSpeech       = "voice_tts_audio"
+	MessageTypeVoiceFinished     = "voice_finished"
+	MessageTypeVoiceError        = "voice_error"
+)
+
+// Session stages
+const (
+	StageWake       = "wake"       // Audio goes to the wake word service
+	StageListening  = "listening"  // Audio goes to the speech-to-text service
+	StageProcessing = "processing" // The transcript is being answered
+)
+
+// Service names reported by Status
+const (
+	ServiceSTT  = "stt"
+	ServiceTTS  = "tts"
+	ServiceWake = "wake"
+)
+
+// speechChunkSize is the number of audio bytes sent per voice_tts_audio message
+const speechChunkSize = 32 * 1024
+
+// conversationTitle is the title of the conversations voice sessions continue
+const conversationTitle = "Voice"
+
+var (
+	ErrNotConfigured = errors.New("voice service not configured")
+	ErrNotUnderstood = errors.New("no intent matched and no conversation service is available")
+)
+
+// Sender delivers messages to the client of a session; *websocket.Client implements it
+type Sender interface {
+	Send(messageType string, data map[string]interface{}) bool
+}
+
+// Conversations is the part of the conversation service voice sessions use
+type Conversations interface {
+	CreateConversation(ctx context.Context, userID string, req *ai.CreateConversationRequest) (*ai.Conversation, error)
+	SendMessage(ctx context.Context, userID, conversationID string, req *ai.SendMessageRequest) (*ai.EnhancedChatResponse, error)
+}
+
+// Response is the answer to a transcript
+type Response struct {
+	Text     string `json:"text"`
+	Intent   string `json:"intent,omitempty"`
+	Provider string `json:"provider"`
+}
+
+// ServiceStatus is the reachability of one Wyoming service
+type ServiceStatus struct {
+	Name      string                 `json:"name"`
+	URI       string                 `json:"uri"`
+	Available bool                   `json:"available"`
+	Info      map[string]interface{} `json:"info,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Status describes the voice pipeline
+type Status struct {
+	Language       string          `json:"language"`
+	WakeWords      []string        `json:"wake_words,omitempty"`
+	Services       []ServiceStatus `json:"services"`
+	ActiveSessions int             `json:"active_sessions"`
+}
+
+// session is the voice interaction of one client, from wake word or start to the spoken answer
+type session struct {
+	id       string
+	clientID string
+	speaker  string
+	out      Sender
+	language string
+	format   AudioFormat
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	stage string
+	wake  *Detection
+	stt   *Transcription
+	timer *time.Timer
+}
+
+// Service runs voice sessions: audio streamed by WebSocket clients goes to a wake word and a
+// speech-to-text service, transcripts are answered by the intent engine or the conversation
+// service and answers are spoken by a text-to-speech service
+type Service struct {
+	sttURI            string
+	ttsURI            string
+	wakeURI           string
+	wakeWords         []string
+	language          string
+	voices            map[string]string
+	maxSpeechDuration time.Duration
+	timeout           time.Duration
+	logger            *logrus.Logger
+
+	intents       ai.IntentHandler
+	conversations Conversations
+
+	mu              sync.Mutex
+	sessions        map[string]*session // Client ID -> session
+	conversationIDs map[string]string   // Speaker -> conversation ID
+}
+
+// NewService creates the voice service
+func NewService(cfg config.VoiceConfig, logger *logrus.Logger) *Service {
+	s := &Service{
+		sttURI:            cfg.STTURI,
+		ttsURI:            cfg.TTSURI,
+		wakeURI:           cfg.WakeURI,
+		wakeWords:         cfg.WakeWords,
+		language:          cfg.Language,
+		voices:            cfg.Voices,
+		maxSpeechDuration: parseDuration(cfg.MaxSpeechDuration, 15*time.Second),
+		timeout:           parseDuration(cfg.Timeout, 30*time.Second),
+		logger:            logger,
+		sessions:          make(map[string]*session),
+		conversationIDs:   make(map[string]string),
+	}
+	if s.language == "" {
+		s.language = "en-US"
+	}
+	return s
+}
+
+// SetIntentHandler sets the handler that answers common commands without the LLM
+func (s *Service) SetIntentHandler(handler ai.IntentHandler) {
+	s.intents = handler
+}
+
+// SetConversationService sets the conversation service that answers transcripts, after
+// its own intent handling
+func (s *Service) SetConversationService(conversations Conversations) {
+	s.conversations = conversations
+}
+
+// Status reports the configured services and whether they answer
+func (s *Service) Status(ctx context.Context) *Status {
+	status := &Status{Language: s.language, WakeWords: s.wakeWords, Services: []ServiceStatus{}}
+	for _, service := range []struct{ name, uri string }{
+		{ServiceSTT, s.sttURI},
+		{ServiceTTS, s.ttsURI},
+		{ServiceWake, s.wakeURI},
+	} {
+		if service.uri == "" {
+			continue
+		}
+		serviceStatus := ServiceStatus{Name: service.name, URI: service.uri}
+		if info, err := Describe(ctx, service.uri, s.timeout); err != nil {
+			serviceStatus.Error = err.Error()
+		} else {
+			serviceStatus.Available = true
+			serviceStatus.Info = info
+		}
+		status.Services = append(status.Services, serviceStatus)
+	}
+
+	s.mu.Lock()
+	status.ActiveSessions = len(s.sessions)
+	s.mu.Unlock()
+	return status
+}
+
+// Speak synthesizes text with the voice configured for the language
+func (s *Service) Speak(ctx context.Context, text, language string) (*Audio, error) {
+	if s.ttsURI == "" {
+		return nil, ErrNotConfigured
+	}
+	if language == "" {
+		language = s.language
+	}
+	return Synthesize(ctx, s.ttsURI, s.timeout, text, s.voiceFor(language))
+}
+
+// voiceFor returns the voice of a language, matched exactly or by its first part. Keys are
+// compared in lower case, as the configuration loader lowercases them.
+func (s *Service) voiceFor(language string) string {
+	if voiceName, ok := s.voices[strings.ToLower(language)]; ok {
+		return voiceName
+	}
+	return s.voices[languageCode(language)]
+}
+
+// Respond answers a transcript. The conversation service handles intents before asking the
+// LLM; without it only intents are answered.
+func (s *Service) Respond(ctx context.Context, speaker, text, language string) (*Response, error) {
+	if s.conversations != nil {
+		conversationID, err := s.conversationFor(ctx, speaker)
+		if err != nil {
+			return nil, err
+		}
+		reply, err := s.conversations.SendMessage(ctx, speaker, conversationID, &ai.SendMessageRequest{
+			Content:  text,
+			Metadata: map[string]interface{}{"locale": language, "source": "voice"},
+		})
+		if err != nil {
+			// Start a new conversation next time, in case this one is gone
+			s.mu.Lock()
+			delete(s.conversationIDs, speaker)
+			s.mu.Unlock()
+			return nil, err
+		}
+		return &Response{
+			Text:     reply.Message.Content,
+			Intent:   reply.Response.Metadata["intent"],
+			Provider: reply.Provider,
+		}, nil
+	}
+
+	if s.intents != nil {
+		if result, ok := s.intents.HandleIntent(ctx, text, language); ok {
+			return &Response{Text: result.Response, Intent: result.Intent, Provider: ai.IntentProvider}, nil
+		}
+	}
+	return nil, ErrNotUnderstood
+}
+
+// conversationFor returns the conversation voice sessions of a speaker continue
+func (s *Service) conversationFor(ctx context.Context, speaker string) (string, error) {
+	s.mu.Lock()
+	conversationID, ok := s.conversationIDs[speaker]
+	s.mu.Unlock()
+	if ok {
+		return conversationID, nil
+	}
+
+	conversation, err := s.conversations.CreateConversation(ctx, speaker, &ai.CreateConversationRequest{
+		Title:    conversationTitle,
+		Metadata: map[string]interface{}{"source": "voice"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	s.mu.Lock()
+	s.conversationIDs[speaker] = conversation.ID
+	s.mu.Unlock()
+	return conversation.ID, nil
+}
+
+// StartSession starts the voice session of a client, replacing any session it had. With
+// "wake_word" set the session waits for a wake word before listening.
+func (s *Service) StartSession(clientID, speaker string, out Sender, data map[string]interface{}) {
+	s.CancelSession(clientID)
+
+	if s.sttURI == "" {
+		out.Send(MessageTypeVoiceError, map[string]interface{}{"error": "Speech-to-text service not configured"})
+		return
+	}
+	wake, _ := data["wake_word"].(bool)
+	if wake && s.wakeURI == "" {
+		out.Send(MessageTypeVoiceError, map[string]interface{}{"error": "Wake word service not configured"})
+		return
+	}
+
+	sess := &session{
+		id:       uuid.New().String(),
+		clientID: clientID,
+		speaker:  speaker,
+		out:      out,
+		language: s.language,
+		format:   formatFromData(data, DefaultAudioFormat),
+	}
+	if language, ok := data["language"].(string); ok && language != "" {
+		sess.language = language
+	}
+	sess.ctx, sess.cancel = context.WithCancel(context.Background())
+
+	// Hold the session until it is registered, so a fast wake word cannot end it first
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	var err error
+	if wake {
+		err = s.awaitWakeWord(sess)
+	} else {
+		err = s.listen(sess)
+	}
+	if err != nil {
+		sess.cancel()
+		s.logger.WithError(err).WithField("client_id", clientID).Warn("Failed to start voice session")
+		out.Send(MessageTypeVoiceError, map[string]interface{}{"session_id": sess.id, "error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.sessions[clientID] = sess
+	s.mu.Unlock()
+
+	out.Send(MessageTypeVoiceStarted, map[string]interface{}{
+		"session_id": sess.id,
+		"stage":      sess.stage,
+		"language":   sess.language,
+		"format":     sess.format,
+	})
+}
+
+// awaitWakeWord streams the session audio to the wake word service until it detects one
+func (s *Service) awaitWakeWord(sess *session) error {
+	detection, err := DetectWakeWord(sess.ctx, s.wakeURI, s.timeout, sess.format, s.wakeWords)
+	if err != nil {
+		return err
+	}
+	sess.wake = detection
+	sess.stage = StageWake
+
+	go func() {
+		name, ok := <-detection.Detected()
+
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		if sess.stage != StageWake || sess.ctx.Err() != nil {
+			return
+		}
+		detection.Close()
+		sess.wake = nil
+
+		if !ok {
+			s.failLocked(sess, errors.New("wake word service disconnected"))
+			return
+		}
+		if err := s.listen(sess); err != nil {
+			s.failLocked(sess, err)
+			return
+		}
+		sess.out.Send(MessageTypeVoiceWakeDetected, map[string]interface{}{"session_id": sess.id, "name": name})
+	}()
+	return nil
+}
+
+// listen streams the session audio to the speech-to-text service, finishing after the
+// maximum speech duration
+func (s *Service) listen(sess *session) error {
+	transcription, err := Transcribe(sess.ctx, s.sttURI, s.timeout, sess.format, languageCode(sess.language))
+	if err != nil {
+		return err
+	}
+	sess.stt = transcription
+	sess.stage = StageListening
+	sess.timer = time.AfterFunc(s.maxSpeechDuration, func() { s.finish(sess) })
+	return nil
+}
+
+// WriteAudio passes base64 encoded audio of the client session to the current service
+func (s *Service) WriteAudio(clientID string, data map[string]interface{}) {
+	sess := s.session(clientID)
+	if sess == nil {
+		return
+	}
+	encoded, _ := data["audio"].(string)
+	chunk, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(chunk) == 0 {
+		return
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.ctx.Err() != nil {
+		return
+	}
+	switch sess.stage {
+	case StageWake:
+		err = sess.wake.Write(chunk)
+	case StageListening:
+		err = sess.stt.Write(chunk)
+	}
+	if err != nil {
+		s.failLocked(sess, err)
+	}
+}
+
+// StopSession marks the end of speech. A session still waiting for its wake word ends.
+func (s *Service) StopSession(clientID string) {
+	sess := s.session(clientID)
+	if sess == nil {
+		return
+	}
+
+	sess.mu.Lock()
+	stage := sess.stage
+	sess.mu.Unlock()
+	if stage == StageWake {
+		s.CancelSession(clientID)
+		return
+	}
+	go s.finish(sess)
+}
+
+// CancelSession abandons the session of a client
+func (s *Service) CancelSession(clientID string) {
+	sess := s.session(clientID)
+	if sess == nil {
+		return
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	s.endLocked(sess)
+}
+
+// finish transcribes the speech of a session, answers it and speaks the answer
+func (s *Service) finish(sess *session) {
+	sess.mu.Lock()
+	if sess.stage != StageListening || sess.ctx.Err() != nil {
+		sess.mu.Unlock()
+		return
+	}
+	sess.stage = StageProcessing
+	sess.timer.Stop()
+	transcription := sess.stt
+	sess.stt = nil
+	sess.mu.Unlock()
+
+	text, err := transcription.Finish(s.timeout)
+	if err != nil {
+		s.fail(sess, err)
+		return
+	}
+	sess.out.Send(MessageTypeVoiceTranscript, map[string]interface{}{"session_id": sess.id, "text": text})
+
+	if text != "" {
+		response, err := s.Respond(sess.ctx, sess.speaker, text, sess.language)
+		if err != nil {
+			s.fail(sess, err)
+			return
+		}
+		sess.out.Send(MessageTypeVoiceResponse, map[string]interface{}{
+			"session_id": sess.id,
+			"text":       response.Text,
+			"intent":     response.Intent,
+			"provider":   response.Provider,
+		})
+		if s.ttsURI != "" && response.Text != "" {
+			if err := s.sendSpeech(sess, response.Text); err != nil {
+				s.fail(sess, err)
+				return
+			}
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id": sess.id,
+		"speaker":    sess.speaker,
+		"language":   sess.language,
+	}).Info("Voice session finished")
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.out.Send(MessageTypeVoiceFinished, map[string]interface{}{"session_id": sess.id})
+	s.endLocked(sess)
+}
+
+// sendSpeech synthesizes the answer and sends it to the client in base64 encoded chunks
+func (s *Service) sendSpeech(sess *session, text string) error {
+	audio, err := s.Speak(sess.ctx, text, sess.language)
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; offset == 0 || offset < len(audio.PCM); offset += speechChunkSize {
+		end := min(offset+speechChunkSize, len(audio.PCM))
+		if !sess.out.Send(MessageTypeVoiceSpeech, map[string]interface{}{
+			"session_id": sess.id,
+			"format":     audio.Format,
+			"audio":      base64.StdEncoding.EncodeToString(audio.PCM[offset:end]),
+			"final":      end == len(audio.PCM),
+		}) {
+			return errors.New("client stopped receiving audio")
+		}
+	}
+	return nil
+}
+
+// fail reports an error to the client and ends the session
+func (s *Service) fail(sess *session, err error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	s.failLocked(sess, err)
+}
+
+func (s *Service) failLocked(sess *session, err error) {
+	if sess.ctx.Err() != nil {
+		return
+	}
+	s.logger.WithError(err).WithField("session_id", sess.id).Warn("Voice session failed")
+	sess.out.Send(MessageTypeVoiceError, map[string]interface{}{"session_id": sess.id, "error": err.Error()})
+	s.endLocked(sess)
+}
+
+// endLocked releases the connections of a session and forgets it
+func (s *Service) endLocked(sess *session) {
+	sess.cancel()
+	if sess.timer != nil {
+		sess.timer.Stop()
+	}
+	if sess.wake != nil {
+		sess.wake.Close()
+		sess.wake = nil
+	}
+	if sess.stt != nil {
+		sess.stt.Close()
+		sess.stt = nil
+	}
+
+	s.mu.Lock()
+	if s.sessions[sess.clientID] == sess {
+		delete(s.sessions, sess.clientID)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Service) session(clientID string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[clientID]
+}
+
+// languageCode returns the language part of a locale, "es" for "es-ES"
+func languageCode(locale string) string {
+	return strings.ToLower(strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0])
+}
+
+// WAV returns the audio as a WAV file
+func (a *Audio) WAV() []byte {
+	var buf bytes.Buffer
+	blockAlign := a.Format.Channels * a.Format.Width
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(a.PCM)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(a.Format.Channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(a.Format.Rate))
+	binary.Write(&buf, binary.LittleEndian, uint32(a.Format.Rate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(a.Format.Width*8))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(a.PCM)))
+	buf.Write(a.PCM)
+	return buf.Bytes()
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
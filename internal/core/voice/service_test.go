@@ -0,0 +1,196 @@
+package voice
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/ai"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWyomingServer answers like whisper, piper and openWakeWord together: it detects the wake
+// word on the first audio chunk, transcribes any audio as the configured transcript and
+// synthesizes two chunks of audio
+type fakeWyomingServer struct {
+	listener   net.Listener
+	transcript string
+	received   chan *Event
+}
+
+func newFakeWyomingServer(t *testing.T, transcript string) *fakeWyomingServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeWyomingServer{listener: listener, transcript: transcript, received: make(chan *Event, 100)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (f *fakeWyomingServer) uri() string {
+	return "tcp://" + f.listener.Addr().String()
+}
+
+func (f *fakeWyomingServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	mode := ""
+	for {
+		event, err := ReadEvent(reader)
+		if err != nil {
+			return
+		}
+		f.received <- event
+
+		switch event.Type {
+		case EventDescribe:
+			WriteEvent(conn, &Event{Type: EventInfo, Data: map[string]interface{}{"asr": []interface{}{}}})
+		case EventTranscribe, EventDetect:
+			mode = event.Type
+		case EventAudioChunk:
+			if mode == EventDetect {
+				WriteEvent(conn, &Event{Type: EventDetection, Data: map[string]interface{}{"name": "ok_nabu"}})
+				mode = ""
+			}
+		case EventAudioStop:
+			if mode == EventTranscribe {
+				WriteEvent(conn, &Event{Type: EventTranscript, Data: map[string]interface{}{"text": " " + f.transcript + " "}})
+			}
+		case EventSynthesize:
+			format := map[string]interface{}{"rate": 22050, "width": 2, "channels": 1}
+			WriteEvent(conn, &Event{Type: EventAudioStart, Data: format})
+			WriteEvent(conn, &Event{Type: EventAudioChunk, Data: format, Payload: []byte{1, 2, 3, 4}})
+			WriteEvent(conn, &Event{Type: EventAudioChunk, Data: format, Payload: []byte{5, 6}})
+			WriteEvent(conn, &Event{Type: EventAudioStop})
+		}
+	}
+}
+
+// message is a message sent to the voice client
+type message struct {
+	Type string
+	Data map[string]interface{}
+}
+
+type recordingSender struct {
+	messages chan message
+}
+
+func (r *recordingSender) Send(messageType string, data map[string]interface{}) bool {
+	r.messages <- message{Type: messageType, Data: data}
+	return true
+}
+
+func (r *recordingSender) next(t *testing.T) message {
+	select {
+	case msg := <-r.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a voice message")
+		return message{}
+	}
+}
+
+type fakeIntentHandler struct {
+	texts   []string
+	locales []string
+}
+
+func (f *fakeIntentHandler) HandleIntent(ctx context.Context, text, locale string) (*ai.IntentResult, bool) {
+	f.texts = append(f.texts, text)
+	f.locales = append(f.locales, locale)
+	return &ai.IntentResult{Intent: "turn_on", Locale: "en-US", Response: "Turned on kitchen lights", Success: true}, true
+}
+
+func TestService_Session(t *testing.T) {
+	server := newFakeWyomingServer(t, "turn on the kitchen lights")
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	service := NewService(config.VoiceConfig{
+		STTURI:    server.uri(),
+		TTSURI:    server.uri(),
+		WakeURI:   server.uri(),
+		WakeWords: []string{"ok_nabu"},
+		Voices:    map[string]string{"en": "en_US-lessac-medium"},
+	}, logger)
+	intents := &fakeIntentHandler{}
+	service.SetIntentHandler(intents)
+
+	status := service.Status(context.Background())
+	require.Len(t, status.Services, 3)
+	assert.True(t, status.Services[0].Available)
+
+	sender := &recordingSender{messages: make(chan message, 100)}
+	service.StartSession("client-1", "kiosk:1", sender, map[string]interface{}{"wake_word": true, "language": "en-US"})
+	started := sender.next(t)
+	require.Equal(t, MessageTypeVoiceStarted, started.Type)
+	assert.Equal(t, StageWake, started.Data["stage"])
+	sessionID := started.Data["session_id"]
+
+	chunk := map[string]interface{}{"audio": base64.StdEncoding.EncodeToString([]byte{0, 1, 0, 1})}
+	service.WriteAudio("client-1", chunk)
+	wake := sender.next(t)
+	require.Equal(t, MessageTypeVoiceWakeDetected, wake.Type)
+	assert.Equal(t, "ok_nabu", wake.Data["name"])
+
+	service.WriteAudio("client-1", chunk)
+	service.WriteAudio("client-1", chunk)
+	service.StopSession("client-1")
+
+	transcript := sender.next(t)
+	require.Equal(t, MessageTypeVoiceTranscript, transcript.Type)
+	assert.Equal(t, "turn on the kitchen lights", transcript.Data["text"])
+	assert.Equal(t, sessionID, transcript.Data["session_id"])
+
+	response := sender.next(t)
+	require.Equal(t, MessageTypeVoiceResponse, response.Type)
+	assert.Equal(t, "Turned on kitchen lights", response.Data["text"])
+	assert.Equal(t, ai.IntentProvider, response.Data["provider"])
+	assert.Equal(t, []string{"turn on the kitchen lights"}, intents.texts)
+	assert.Equal(t, []string{"en-US"}, intents.locales)
+
+	speech := sender.next(t)
+	require.Equal(t, MessageTypeVoiceSpeech, speech.Type)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4, 5, 6}), speech.Data["audio"])
+	assert.Equal(t, AudioFormat{Rate: 22050, Width: 2, Channels: 1}, speech.Data["format"])
+	assert.Equal(t, true, speech.Data["final"])
+
+	assert.Equal(t, MessageTypeVoiceFinished, sender.next(t).Type)
+	assert.Nil(t, service.session("client-1"))
+
+	// The services saw the configured wake word, the language and the voice
+	var transcribe, synthesize, detect *Event
+	for len(server.received) > 0 {
+		event := <-server.received
+		switch event.Type {
+		case EventTranscribe:
+			transcribe = event
+		case EventSynthesize:
+			synthesize = event
+		case EventDetect:
+			detect = event
+		}
+	}
+	require.NotNil(t, detect)
+	assert.Equal(t, []interface{}{"ok_nabu"}, detect.Data["names"])
+	require.NotNil(t, transcribe)
+	assert.Equal(t, "en", transcribe.Data["language"])
+	require.NotNil(t, synthesize)
+	assert.Equal(t, map[string]interface{}{"name": "en_US-lessac-medium"}, synthesize.Data["voice"])
+}
@@ -0,0 +1,394 @@
+package voice
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Wyoming event types
+const (
+	EventDescribe    = "describe"
+	EventInfo        = "info"
+	EventTranscribe  = "transcribe"
+	EventTranscript  = "transcript"
+	EventSynthesize  = "synthesize"
+	EventDetect      = "detect"
+	EventDetection   = "detection"
+	EventNotDetected = "not-detected"
+	EventAudioStart  = "audio-start"
+	EventAudioChunk  = "audio-chunk"
+	EventAudioStop   = "audio-stop"
+	EventError       = "error"
+)
+
+// wyomingVersion is the protocol version sent in event headers
+const wyomingVersion = "1.5.2"
+
+// maxHeaderSize bounds the JSON header line of an event
+const maxHeaderSize = 1 << 20
+
+// Event is a Wyoming protocol event: a JSON header line, optionally followed by additional
+// JSON data and a binary payload
+type Event struct {
+	Type    string
+	Data    map[string]interface{}
+	Payload []byte
+}
+
+// eventHeader is the JSON line starting every event
+type eventHeader struct {
+	Type          string                 `json:"type"`
+	Version       string                 `json:"version,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	DataLength    int                    `json:"data_length,omitempty"`
+	PayloadLength int                    `json:"payload_length,omitempty"`
+}
+
+// WriteEvent writes an event, sending its data after the header line
+func WriteEvent(w io.Writer, event *Event) error {
+	header := eventHeader{Type: event.Type, Version: wyomingVersion, PayloadLength: len(event.Payload)}
+
+	var data []byte
+	if len(event.Data) > 0 {
+		var err error
+		if data, err = json.Marshal(event.Data); err != nil {
+			return err
+		}
+		header.DataLength = len(data)
+	}
+
+	line, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 0, len(line)+1+len(data)+len(event.Payload))
+	buf = append(buf, line...)
+	buf = append(buf, '\n')
+	buf = append(buf, data...)
+	buf = append(buf, event.Payload...)
+	_, err = w.Write(buf)
+	return err
+}
+
+// ReadEvent reads an event. Data in the header line and additional data are merged.
+func ReadEvent(r *bufio.Reader) (*Event, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header eventHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, fmt.Errorf("invalid event header: %w", err)
+	}
+	if header.Type == "" {
+		return nil, errors.New("invalid event header: missing type")
+	}
+
+	event := &Event{Type: header.Type, Data: header.Data}
+	if event.Data == nil {
+		event.Data = make(map[string]interface{})
+	}
+	if header.DataLength > 0 {
+		data := make([]byte, header.DataLength)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		var extra map[string]interface{}
+		if err := json.Unmarshal(data, &extra); err != nil {
+			return nil, fmt.Errorf("invalid event data: %w", err)
+		}
+		for key, value := range extra {
+			event.Data[key] = value
+		}
+	}
+	if header.PayloadLength > 0 {
+		event.Payload = make([]byte, header.PayloadLength)
+		if _, err := io.ReadFull(r, event.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// readLine reads one header line, refusing lines longer than maxHeaderSize
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxHeaderSize {
+			return nil, errors.New("event header too large")
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+// AudioFormat describes raw PCM audio
+type AudioFormat struct {
+	Rate     int `json:"rate"`     // Samples per second
+	Width    int `json:"width"`    // Bytes per sample
+	Channels int `json:"channels"` // Interleaved channels
+}
+
+// DefaultAudioFormat is 16 kHz, 16-bit mono audio, as expected by most speech services
+var DefaultAudioFormat = AudioFormat{Rate: 16000, Width: 2, Channels: 1}
+
+func (f AudioFormat) data() map[string]interface{} {
+	return map[string]interface{}{"rate": f.Rate, "width": f.Width, "channels": f.Channels}
+}
+
+// formatFromData reads an audio format from event data, keeping the fields it does not hold
+func formatFromData(data map[string]interface{}, format AudioFormat) AudioFormat {
+	if rate, ok := data["rate"].(float64); ok && rate > 0 {
+		format.Rate = int(rate)
+	}
+	if width, ok := data["width"].(float64); ok && width > 0 {
+		format.Width = int(width)
+	}
+	if channels, ok := data["channels"].(float64); ok && channels > 0 {
+		format.Channels = int(channels)
+	}
+	return format
+}
+
+// Conn is a connection to a Wyoming service
+type Conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+// Dial connects to a Wyoming service at a "tcp://host:port" or "host:port" address
+func Dial(ctx context.Context, uri string, timeout time.Duration) (*Conn, error) {
+	address := strings.TrimPrefix(uri, "tcp://")
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", uri, err)
+	}
+	return &Conn{conn: conn, reader: bufio.NewReader(conn), timeout: timeout}, nil
+}
+
+// Write sends an event
+func (c *Conn) Write(event *Event) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	return WriteEvent(c.conn, event)
+}
+
+// Read waits for the next event until the deadline
+func (c *Conn) Read(deadline time.Time) (*Event, error) {
+	c.conn.SetReadDeadline(deadline)
+	event, err := ReadEvent(c.reader)
+	if err != nil {
+		return nil, err
+	}
+	if event.Type == EventError {
+		text, _ := event.Data["text"].(string)
+		return nil, fmt.Errorf("service error: %s", text)
+	}
+	return event, nil
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Describe asks a service for its info event
+func Describe(ctx context.Context, uri string, timeout time.Duration) (map[string]interface{}, error) {
+	conn, err := Dial(ctx, uri, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.Write(&Event{Type: EventDescribe}); err != nil {
+		return nil, err
+	}
+	for {
+		event, err := conn.Read(time.Now().Add(timeout))
+		if err != nil {
+			return nil, err
+		}
+		if event.Type == EventInfo {
+			return event.Data, nil
+		}
+	}
+}
+
+// Transcription streams audio to a speech-to-text service
+type Transcription struct {
+	conn   *Conn
+	format AudioFormat
+}
+
+// Transcribe starts streaming audio in the given format to a speech-to-text service
+func Transcribe(ctx context.Context, uri string, timeout time.Duration, format AudioFormat, language string) (*Transcription, error) {
+	conn, err := Dial(ctx, uri, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+	if language != "" {
+		data["language"] = language
+	}
+	if err := conn.Write(&Event{Type: EventTranscribe, Data: data}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.Write(&Event{Type: EventAudioStart, Data: format.data()}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Transcription{conn: conn, format: format}, nil
+}
+
+// Write sends a chunk of audio
+func (t *Transcription) Write(chunk []byte) error {
+	return t.conn.Write(&Event{Type: EventAudioChunk, Data: t.format.data(), Payload: chunk})
+}
+
+// Finish ends the audio and waits for the transcript
+func (t *Transcription) Finish(timeout time.Duration) (string, error) {
+	defer t.conn.Close()
+
+	if err := t.conn.Write(&Event{Type: EventAudioStop}); err != nil {
+		return "", err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		event, err := t.conn.Read(deadline)
+		if err != nil {
+			return "", fmt.Errorf("failed to read transcript: %w", err)
+		}
+		if event.Type == EventTranscript {
+			text, _ := event.Data["text"].(string)
+			return strings.TrimSpace(text), nil
+		}
+	}
+}
+
+// Close abandons the transcription
+func (t *Transcription) Close() error {
+	return t.conn.Close()
+}
+
+// Detection streams audio to a wake word service and reports detections
+type Detection struct {
+	conn     *Conn
+	format   AudioFormat
+	detected chan string
+}
+
+// DetectWakeWord starts streaming audio in the given format to a wake word service. Names
+// restrict the wake words detected, any wake word the service knows when empty.
+func DetectWakeWord(ctx context.Context, uri string, timeout time.Duration, format AudioFormat, names []string) (*Detection, error) {
+	conn, err := Dial(ctx, uri, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+	if len(names) > 0 {
+		data["names"] = names
+	}
+	if err := conn.Write(&Event{Type: EventDetect, Data: data}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.Write(&Event{Type: EventAudioStart, Data: format.data()}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	d := &Detection{conn: conn, format: format, detected: make(chan string, 1)}
+	go d.readDetections()
+	return d, nil
+}
+
+// readDetections reports the first detection, until the connection is closed
+func (d *Detection) readDetections() {
+	defer close(d.detected)
+	for {
+		event, err := d.conn.Read(time.Time{})
+		if err != nil {
+			return
+		}
+		if event.Type == EventDetection {
+			name, _ := event.Data["name"].(string)
+			d.detected <- name
+			return
+		}
+	}
+}
+
+// Detected receives the name of the detected wake word. It is closed without a value when the
+// connection ends first.
+func (d *Detection) Detected() <-chan string {
+	return d.detected
+}
+
+// Write sends a chunk of audio
+func (d *Detection) Write(chunk []byte) error {
+	return d.conn.Write(&Event{Type: EventAudioChunk, Data: d.format.data(), Payload: chunk})
+}
+
+// Close stops detection
+func (d *Detection) Close() error {
+	return d.conn.Close()
+}
+
+// Audio is synthesized speech
+type Audio struct {
+	Format AudioFormat
+	PCM    []byte
+}
+
+// Synthesize asks a text-to-speech service to speak text, with the named voice if any
+func Synthesize(ctx context.Context, uri string, timeout time.Duration, text, voiceName string) (*Audio, error) {
+	conn, err := Dial(ctx, uri, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	data := map[string]interface{}{"text": text}
+	if voiceName != "" {
+		data["voice"] = map[string]interface{}{"name": voiceName}
+	}
+	if err := conn.Write(&Event{Type: EventSynthesize, Data: data}); err != nil {
+		return nil, err
+	}
+
+	audio := &Audio{Format: DefaultAudioFormat}
+	deadline := time.Now().Add(timeout)
+	for {
+		event, err := conn.Read(deadline)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read synthesized audio: %w", err)
+		}
+		switch event.Type {
+		case EventAudioStart:
+			audio.Format = formatFromData(event.Data, audio.Format)
+		case EventAudioChunk:
+			audio.Format = formatFromData(event.Data, audio.Format)
+			audio.PCM = append(audio.PCM, event.Payload...)
+		case EventAudioStop:
+			return audio, nil
+		}
+	}
+}
@@ -0,0 +1,634 @@
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+	"github.com/sirupsen/logrus"
+)
+
+// Issue kinds
+const (
+	IssueStale       = "stale"
+	IssueUnavailable = "unavailable"
+	IssueLowBattery  = "low_battery"
+)
+
+// alertSource is the source of alerts raised by the watchdog
+const alertSource = "watchdog"
+
+// intervalSmoothing weights a new update gap in the learned interval
+const intervalSmoothing = 0.3
+
+// minSamples is the number of update gaps needed before a learned interval is trusted
+const minSamples = 3
+
+// Issue is a problem with one entity
+type Issue struct {
+	EntityID    string                 `json:"entity_id"`
+	Name        string                 `json:"name"`
+	EntityType  types.PMAEntityType    `json:"entity_type"`
+	Source      types.PMASourceType    `json:"source"`
+	Kind        string                 `json:"kind"`
+	Severity    monitor.AlertSeverity  `json:"severity"`
+	Message     string                 `json:"message"`
+	Since       time.Time              `json:"since"`
+	LastUpdated time.Time              `json:"last_updated"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+}
+
+// DeviceGroup collects the issues of the entities of one device. Entities without a device
+// form a group of their own.
+type DeviceGroup struct {
+	Key      string                `json:"key"`
+	DeviceID string                `json:"device_id,omitempty"`
+	Name     string                `json:"name"`
+	RoomID   string                `json:"room_id,omitempty"`
+	RoomName string                `json:"room_name,omitempty"`
+	Severity monitor.AlertSeverity `json:"severity"`
+	Issues   []*Issue              `json:"issues"`
+}
+
+// RoomGroup lists the devices needing attention in a room
+type RoomGroup struct {
+	RoomID     string   `json:"room_id"`
+	RoomName   string   `json:"room_name"`
+	Devices    []string `json:"devices"` // DeviceGroup keys
+	IssueCount int      `json:"issue_count"`
+}
+
+// Summary counts the issues by kind
+type Summary struct {
+	Stale       int `json:"stale"`
+	Unavailable int `json:"unavailable"`
+	LowBattery  int `json:"low_battery"`
+	Devices     int `json:"devices"`
+}
+
+// Attention is everything that needs attention
+type Attention struct {
+	CheckedAt time.Time      `json:"checked_at"`
+	Summary   Summary        `json:"summary"`
+	Devices   []*DeviceGroup `json:"devices"`
+	Rooms     []*RoomGroup   `json:"rooms"`
+}
+
+// EntityHealth is what the watchdog learned about an entity
+type EntityHealth struct {
+	EntityID         string     `json:"entity_id"`
+	LastUpdated      time.Time  `json:"last_updated"`
+	ExpectedInterval string     `json:"expected_interval,omitempty"`
+	Samples          int        `json:"samples"`
+	StaleAfter       string     `json:"stale_after,omitempty"`
+	UnavailableSince *time.Time `json:"unavailable_since,omitempty"`
+	Issues           []*Issue   `json:"issues"`
+}
+
+// entityStats is the learned update behaviour of an entity
+type entityStats struct {
+	lastUpdated      time.Time
+	interval         time.Duration // exponentially smoothed gap between updates
+	samples          int
+	unavailableSince *time.Time
+}
+
+// Service watches all entities for missing updates, unavailability and low batteries
+type Service struct {
+	entities          *unified.UnifiedEntityService
+	alertManager      *monitor.AlertManager
+	logger            *logrus.Logger
+	interval          time.Duration
+	staleFactor       float64
+	minStaleAfter     time.Duration
+	defaultStaleAfter time.Duration
+	unavailableAfter  time.Duration
+	lowBattery        float64
+	criticalBattery   float64
+	staleTypes        map[types.PMAEntityType]bool
+	ignore            map[string]bool
+
+	mutex     sync.RWMutex
+	stats     map[string]*entityStats
+	attention *Attention
+	alerts    map[string]string // device group key -> alert signature
+
+	checkMutex sync.Mutex
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewService creates the watchdog
+func NewService(cfg config.WatchdogConfig, entities *unified.UnifiedEntityService, logger *logrus.Logger) *Service {
+	s := &Service{
+		entities:          entities,
+		logger:            logger,
+		interval:          parseDuration(cfg.CheckInterval, 5*time.Minute),
+		staleFactor:       cfg.StaleFactor,
+		minStaleAfter:     parseDuration(cfg.MinStaleAfter, 30*time.Minute),
+		defaultStaleAfter: parseDuration(cfg.DefaultStaleAfter, 24*time.Hour),
+		unavailableAfter:  parseDuration(cfg.UnavailableAfter, 10*time.Minute),
+		lowBattery:        cfg.LowBattery,
+		criticalBattery:   cfg.CriticalBattery,
+		staleTypes:        make(map[types.PMAEntityType]bool),
+		ignore:            make(map[string]bool),
+		stats:             make(map[string]*entityStats),
+		alerts:            make(map[string]string),
+		attention:         &Attention{Devices: []*DeviceGroup{}, Rooms: []*RoomGroup{}},
+	}
+	if s.staleFactor <= 1 {
+		s.staleFactor = 4
+	}
+	if s.lowBattery <= 0 {
+		s.lowBattery = 20
+	}
+	if s.criticalBattery <= 0 || s.criticalBattery > s.lowBattery {
+		s.criticalBattery = s.lowBattery / 4
+	}
+
+	staleTypes := cfg.StaleTypes
+	if len(staleTypes) == 0 {
+		staleTypes = []string{string(types.EntityTypeSensor), string(types.EntityTypeBinarySensor)}
+	}
+	for _, entityType := range staleTypes {
+		s.staleTypes[types.PMAEntityType(entityType)] = true
+	}
+	for _, entityID := range cfg.IgnoreEntities {
+		s.ignore[entityID] = true
+	}
+
+	return s
+}
+
+// SetAlertManager sets the alert manager used for device health alerts
+func (s *Service) SetAlertManager(alertManager *monitor.AlertManager) {
+	s.alertManager = alertManager
+}
+
+// Start starts learning update intervals and the periodic check
+func (s *Service) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.entities.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+		s.observe(entityID, time.Now())
+	})
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Check(ctx); err != nil {
+					s.logger.WithError(err).Warn("Device health check failed")
+				}
+			}
+		}
+	}()
+
+	s.logger.WithField("interval", s.interval).Info("Device health watchdog started")
+}
+
+// Stop stops the periodic check
+func (s *Service) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Attention returns the result of the last check
+func (s *Service) Attention() *Attention {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.attention
+}
+
+// EntityHealth returns what the watchdog learned about an entity
+func (s *Service) EntityHealth(entityID string) (*EntityHealth, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats, ok := s.stats[entityID]
+	if !ok {
+		return nil, false
+	}
+
+	health := &EntityHealth{
+		EntityID:         entityID,
+		LastUpdated:      stats.lastUpdated,
+		Samples:          stats.samples,
+		UnavailableSince: stats.unavailableSince,
+		Issues:           []*Issue{},
+	}
+	if stats.samples > 0 {
+		health.ExpectedInterval = stats.interval.Round(time.Second).String()
+	}
+	health.StaleAfter = s.staleAfter(stats).String()
+	for _, group := range s.attention.Devices {
+		for _, issue := range group.Issues {
+			if issue.EntityID == entityID {
+				health.Issues = append(health.Issues, issue)
+			}
+		}
+	}
+	return health, true
+}
+
+// Check evaluates every entity, updates the alerts and returns what needs attention
+func (s *Service) Check(ctx context.Context) (*Attention, error) {
+	s.checkMutex.Lock()
+	defer s.checkMutex.Unlock()
+
+	entities, err := s.entities.GetAll(ctx, unified.GetAllOptions{IncludeRoom: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	now := time.Now()
+	groups := make(map[string]*DeviceGroup)
+
+	for _, item := range entities {
+		entity := item.Entity
+		if entity == nil || s.ignore[entity.GetID()] {
+			continue
+		}
+
+		// Updates between checks that did not change the state are only visible here
+		s.observe(entity.GetID(), entity.GetLastUpdated())
+
+		issues := s.evaluate(entity, now)
+		if len(issues) == 0 {
+			continue
+		}
+
+		key, deviceID := groupKey(entity)
+		group, ok := groups[key]
+		if !ok {
+			group = &DeviceGroup{
+				Key:      key,
+				DeviceID: deviceID,
+				Name:     entity.GetFriendlyName(),
+				Severity: monitor.AlertSeverityInfo,
+			}
+			if item.Room != nil {
+				group.RoomID = item.Room.ID
+				group.RoomName = item.Room.Name
+			}
+			groups[key] = group
+		}
+		for _, issue := range issues {
+			group.Issues = append(group.Issues, issue)
+			group.Severity = maxSeverity(group.Severity, issue.Severity)
+		}
+	}
+
+	attention := buildAttention(groups, now)
+
+	s.mutex.Lock()
+	s.attention = attention
+	s.mutex.Unlock()
+
+	s.updateAlerts(groups)
+
+	s.logger.WithFields(logrus.Fields{
+		"stale":       attention.Summary.Stale,
+		"unavailable": attention.Summary.Unavailable,
+		"low_battery": attention.Summary.LowBattery,
+	}).Debug("Device health check completed")
+
+	return attention, nil
+}
+
+// observe records an update of an entity and refines its learned update interval
+func (s *Service) observe(entityID string, updatedAt time.Time) {
+	if updatedAt.IsZero() {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats, ok := s.stats[entityID]
+	if !ok {
+		s.stats[entityID] = &entityStats{lastUpdated: updatedAt}
+		return
+	}
+	if !updatedAt.After(stats.lastUpdated) {
+		return
+	}
+
+	gap := updatedAt.Sub(stats.lastUpdated)
+	stats.lastUpdated = updatedAt
+
+	if stats.samples == 0 {
+		stats.interval = gap
+	} else {
+		// An outage should not teach the watchdog that long silences are normal
+		if stats.samples >= minSamples && gap > 4*stats.interval {
+			gap = 4 * stats.interval
+		}
+		stats.interval = time.Duration(intervalSmoothing*float64(gap) + (1-intervalSmoothing)*float64(stats.interval))
+	}
+	stats.samples++
+}
+
+// evaluate returns the issues of one entity
+func (s *Service) evaluate(entity types.PMAEntity, now time.Time) []*Issue {
+	var issues []*Issue
+
+	newIssue := func(kind string, severity monitor.AlertSeverity, since time.Time, message string) *Issue {
+		return &Issue{
+			EntityID:    entity.GetID(),
+			Name:        entity.GetFriendlyName(),
+			EntityType:  entity.GetType(),
+			Source:      entity.GetSource(),
+			Kind:        kind,
+			Severity:    severity,
+			Message:     message,
+			Since:       since,
+			LastUpdated: entity.GetLastUpdated(),
+		}
+	}
+
+	s.mutex.Lock()
+	stats, ok := s.stats[entity.GetID()]
+	if !ok {
+		stats = &entityStats{lastUpdated: entity.GetLastUpdated()}
+		s.stats[entity.GetID()] = stats
+	}
+
+	unavailable := !entity.IsAvailable() || entity.GetState() == types.StateUnavailable
+	if unavailable && stats.unavailableSince == nil {
+		stats.unavailableSince = &now
+	} else if !unavailable {
+		stats.unavailableSince = nil
+	}
+	var unavailableSince time.Time
+	if stats.unavailableSince != nil {
+		unavailableSince = *stats.unavailableSince
+	}
+	staleAfter := s.staleAfter(stats)
+	lastUpdated := stats.lastUpdated
+	expected := stats.interval
+	samples := stats.samples
+	s.mutex.Unlock()
+
+	if unavailable {
+		if now.Sub(unavailableSince) >= s.unavailableAfter {
+			issues = append(issues, newIssue(IssueUnavailable, monitor.AlertSeverityWarning, unavailableSince,
+				fmt.Sprintf("%s has been unavailable since %s", entity.GetFriendlyName(), unavailableSince.Format(time.RFC3339))))
+		}
+	} else if s.staleTypes[entity.GetType()] && !lastUpdated.IsZero() && now.Sub(lastUpdated) > staleAfter {
+		issue := newIssue(IssueStale, monitor.AlertSeverityWarning, lastUpdated,
+			fmt.Sprintf("%s has not reported for %s", entity.GetFriendlyName(), now.Sub(lastUpdated).Round(time.Minute)))
+		issue.Details = map[string]interface{}{"stale_after": staleAfter.String()}
+		if samples >= minSamples {
+			issue.Details["expected_interval"] = expected.Round(time.Second).String()
+		}
+		issues = append(issues, issue)
+	}
+
+	if level, ok := batteryLevel(entity); ok && level <= s.lowBattery {
+		severity := monitor.AlertSeverityWarning
+		if level <= s.criticalBattery {
+			severity = monitor.AlertSeverityCritical
+		}
+		issue := newIssue(IssueLowBattery, severity, entity.GetLastUpdated(),
+			fmt.Sprintf("%s battery is at %.0f%%", entity.GetFriendlyName(), level))
+		issue.Details = map[string]interface{}{"battery_level": level}
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// staleAfter returns how long an entity may stay silent; callers hold the mutex
+func (s *Service) staleAfter(stats *entityStats) time.Duration {
+	if stats.samples < minSamples {
+		return s.defaultStaleAfter
+	}
+	staleAfter := time.Duration(s.staleFactor * float64(stats.interval))
+	if staleAfter < s.minStaleAfter {
+		staleAfter = s.minStaleAfter
+	}
+	return staleAfter
+}
+
+// updateAlerts raises one alert per device group and resolves alerts of recovered devices
+func (s *Service) updateAlerts(groups map[string]*DeviceGroup) {
+	if s.alertManager == nil {
+		return
+	}
+
+	for key, group := range groups {
+		signature := groupSignature(group)
+		if s.alerts[key] == signature {
+			continue
+		}
+
+		kinds := make([]string, 0, len(group.Issues))
+		entityIDs := make([]string, 0, len(group.Issues))
+		for _, issue := range group.Issues {
+			kinds = appendUnique(kinds, strings.ReplaceAll(issue.Kind, "_", " "))
+			entityIDs = appendUnique(entityIDs, issue.EntityID)
+		}
+
+		message := group.Issues[0].Message
+		if len(group.Issues) > 1 {
+			message = fmt.Sprintf("%s needs attention: %s", group.Name, strings.Join(kinds, ", "))
+		}
+		if group.RoomName != "" {
+			message += fmt.Sprintf(" (%s)", group.RoomName)
+		}
+
+		err := s.alertManager.CreateAlert(monitor.Alert{
+			ID:       alertID(key),
+			Severity: group.Severity,
+			Source:   alertSource,
+			Message:  message,
+			Details: map[string]interface{}{
+				"device_id": group.DeviceID,
+				"room_id":   group.RoomID,
+				"entities":  entityIDs,
+				"issues":    group.Issues,
+			},
+		})
+		if err != nil {
+			s.logger.WithError(err).WithField("device", key).Warn("Failed to raise device health alert")
+			continue
+		}
+		s.alerts[key] = signature
+	}
+
+	for key := range s.alerts {
+		if _, ok := groups[key]; ok {
+			continue
+		}
+		_ = s.alertManager.ResolveAlertBy(alertID(key), alertSource)
+		delete(s.alerts, key)
+	}
+}
+
+// buildAttention sorts the groups by severity and groups them by room
+func buildAttention(groups map[string]*DeviceGroup, now time.Time) *Attention {
+	attention := &Attention{
+		CheckedAt: now,
+		Devices:   make([]*DeviceGroup, 0, len(groups)),
+		Rooms:     []*RoomGroup{},
+	}
+
+	rooms := make(map[string]*RoomGroup)
+	for _, group := range groups {
+		sort.Slice(group.Issues, func(i, j int) bool {
+			if group.Issues[i].EntityID != group.Issues[j].EntityID {
+				return group.Issues[i].EntityID < group.Issues[j].EntityID
+			}
+			return group.Issues[i].Kind < group.Issues[j].Kind
+		})
+		attention.Devices = append(attention.Devices, group)
+
+		for _, issue := range group.Issues {
+			switch issue.Kind {
+			case IssueStale:
+				attention.Summary.Stale++
+			case IssueUnavailable:
+				attention.Summary.Unavailable++
+			case IssueLowBattery:
+				attention.Summary.LowBattery++
+			}
+		}
+
+		room, ok := rooms[group.RoomID]
+		if !ok {
+			room = &RoomGroup{RoomID: group.RoomID, RoomName: group.RoomName}
+			rooms[group.RoomID] = room
+			attention.Rooms = append(attention.Rooms, room)
+		}
+		room.Devices = append(room.Devices, group.Key)
+		room.IssueCount += len(group.Issues)
+	}
+	attention.Summary.Devices = len(attention.Devices)
+
+	sort.Slice(attention.Devices, func(i, j int) bool {
+		a, b := attention.Devices[i], attention.Devices[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(attention.Rooms, func(i, j int) bool {
+		return attention.Rooms[i].RoomName < attention.Rooms[j].RoomName
+	})
+	for _, room := range attention.Rooms {
+		sort.Strings(room.Devices)
+	}
+
+	return attention
+}
+
+// groupKey returns the device an entity belongs to, falling back to the entity itself
+func groupKey(entity types.PMAEntity) (key, deviceID string) {
+	if id := entity.GetDeviceID(); id != nil && *id != "" {
+		return string(entity.GetSource()) + ":" + *id, *id
+	}
+	if metadata := entity.GetMetadata(); metadata != nil && metadata.SourceDeviceID != nil && *metadata.SourceDeviceID != "" {
+		return string(entity.GetSource()) + ":" + *metadata.SourceDeviceID, *metadata.SourceDeviceID
+	}
+	return entity.GetID(), ""
+}
+
+// batteryLevel reads a battery percentage from the attributes of an entity or from the state
+// of a battery sensor
+func batteryLevel(entity types.PMAEntity) (float64, bool) {
+	attributes := entity.GetAttributes()
+	for _, key := range []string{"battery_level", "battery"} {
+		if level, ok := toFloat(attributes[key]); ok {
+			return level, true
+		}
+	}
+
+	if entity.GetType() == types.EntityTypeSensor {
+		if deviceClass, _ := attributes["device_class"].(string); deviceClass == "battery" {
+			return toFloat(string(entity.GetState()))
+		}
+	}
+	return 0, false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func groupSignature(group *DeviceGroup) string {
+	parts := make([]string, 0, len(group.Issues)+1)
+	parts = append(parts, string(group.Severity))
+	for _, issue := range group.Issues {
+		parts = append(parts, issue.EntityID+"/"+issue.Kind)
+	}
+	return strings.Join(parts, ",")
+}
+
+func alertID(key string) string {
+	return "watchdog_" + key
+}
+
+func severityRank(severity monitor.AlertSeverity) int {
+	switch severity {
+	case monitor.AlertSeverityCritical:
+		return 2
+	case monitor.AlertSeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+func maxSeverity(a, b monitor.AlertSeverity) monitor.AlertSeverity {
+	if severityRank(b) > severityRank(a) {
+		return b
+	}
+	return a
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
@@ -0,0 +1,225 @@
+package watchdog
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService() *Service {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewService(config.WatchdogConfig{
+		StaleFactor:       4,
+		MinStaleAfter:     "30m",
+		DefaultStaleAfter: "24h",
+		UnavailableAfter:  "10m",
+		LowBattery:        20,
+		CriticalBattery:   5,
+	}, nil, logger)
+}
+
+func sensor(id string, state types.PMAEntityState, lastUpdated time.Time) *types.PMABaseEntity {
+	return &types.PMABaseEntity{
+		ID:           id,
+		Type:         types.EntityTypeSensor,
+		FriendlyName: id,
+		State:        state,
+		Available:    true,
+		Attributes:   map[string]interface{}{},
+		LastUpdated:  lastUpdated,
+	}
+}
+
+func issueKinds(issues []*Issue) []string {
+	kinds := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return kinds
+}
+
+func TestObserveLearnsInterval(t *testing.T) {
+	s := newTestService()
+	start := time.Now()
+
+	s.observe("sensor.a", start)
+	assert.Zero(t, s.stats["sensor.a"].samples, "the first update has no gap")
+
+	s.observe("sensor.a", start.Add(10*time.Minute))
+	assert.Equal(t, 10*time.Minute, s.stats["sensor.a"].interval)
+
+	// Older or repeated timestamps are ignored
+	s.observe("sensor.a", start.Add(5*time.Minute))
+	s.observe("sensor.a", start.Add(10*time.Minute))
+	assert.Equal(t, 1, s.stats["sensor.a"].samples)
+
+	// Later gaps are smoothed
+	s.observe("sensor.a", start.Add(30*time.Minute))
+	assert.Equal(t, 13*time.Minute, s.stats["sensor.a"].interval)
+
+	s.observe("sensor.a", start.Add(43*time.Minute))
+	assert.Equal(t, 13*time.Minute, s.stats["sensor.a"].interval)
+	assert.Equal(t, 3, s.stats["sensor.a"].samples)
+
+	// Once trusted, an outage counts as at most four intervals
+	s.observe("sensor.a", start.Add(43*time.Minute+10*time.Hour))
+	assert.Equal(t, time.Duration(0.3*float64(52*time.Minute)+0.7*float64(13*time.Minute)), s.stats["sensor.a"].interval)
+
+	s.observe("sensor.b", time.Time{})
+	assert.NotContains(t, s.stats, "sensor.b", "entities without an update time are not tracked")
+}
+
+func TestStaleAfter(t *testing.T) {
+	s := newTestService()
+
+	assert.Equal(t, 24*time.Hour, s.staleAfter(&entityStats{samples: 2, interval: time.Minute}), "too few samples")
+	assert.Equal(t, 2*time.Hour, s.staleAfter(&entityStats{samples: 3, interval: 30 * time.Minute}))
+	assert.Equal(t, 30*time.Minute, s.staleAfter(&entityStats{samples: 10, interval: time.Minute}), "never below the minimum")
+}
+
+func TestEvaluateStale(t *testing.T) {
+	now := time.Now()
+
+	t.Run("learned interval", func(t *testing.T) {
+		s := newTestService()
+		for i := 0; i <= 3; i++ {
+			s.observe("sensor.temperature", now.Add(-3*time.Hour+time.Duration(i)*15*time.Minute))
+		}
+		// Last update 2h15m ago, stale after 4 * 15m = 1h
+		issues := s.evaluate(sensor("sensor.temperature", "21", now.Add(-2*time.Hour-15*time.Minute)), now)
+		require.Len(t, issues, 1)
+		assert.Equal(t, IssueStale, issues[0].Kind)
+		assert.Equal(t, "1h0m0s", issues[0].Details["stale_after"])
+		assert.Equal(t, "15m0s", issues[0].Details["expected_interval"])
+	})
+
+	t.Run("default before learning", func(t *testing.T) {
+		s := newTestService()
+		assert.Empty(t, s.evaluate(sensor("sensor.new", "21", now.Add(-23*time.Hour)), now))
+		assert.Equal(t, []string{IssueStale}, issueKinds(s.evaluate(sensor("sensor.old", "21", now.Add(-25*time.Hour)), now)))
+	})
+
+	t.Run("only watched types go stale", func(t *testing.T) {
+		s := newTestService()
+		light := sensor("light.hall", types.StateOn, now.Add(-48*time.Hour))
+		light.Type = types.EntityTypeLight
+		assert.Empty(t, s.evaluate(light, now))
+	})
+}
+
+func TestEvaluateUnavailable(t *testing.T) {
+	s := newTestService()
+	now := time.Now()
+	entity := sensor("sensor.door", types.StateUnavailable, now)
+
+	assert.Empty(t, s.evaluate(entity, now), "unavailable for less than the threshold")
+	assert.Empty(t, s.evaluate(entity, now.Add(9*time.Minute)))
+
+	issues := s.evaluate(entity, now.Add(11*time.Minute))
+	require.Len(t, issues, 1)
+	assert.Equal(t, IssueUnavailable, issues[0].Kind)
+	assert.Equal(t, now, issues[0].Since)
+
+	// Recovering resets the clock
+	entity.State = "closed"
+	assert.Empty(t, s.evaluate(entity, now.Add(12*time.Minute)))
+	entity.Available = false
+	assert.Empty(t, s.evaluate(entity, now.Add(13*time.Minute)))
+}
+
+func TestEvaluateBattery(t *testing.T) {
+	s := newTestService()
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		entity       func() *types.PMABaseEntity
+		wantSeverity monitor.AlertSeverity
+	}{
+		{"healthy", func() *types.PMABaseEntity {
+			e := sensor("sensor.motion", "off", now)
+			e.Attributes["battery_level"] = 80
+			return e
+		}, ""},
+		{"low attribute", func() *types.PMABaseEntity {
+			e := sensor("sensor.motion", "off", now)
+			e.Attributes["battery"] = "15%"
+			return e
+		}, monitor.AlertSeverityWarning},
+		{"critical attribute", func() *types.PMABaseEntity {
+			e := sensor("sensor.motion", "off", now)
+			e.Attributes["battery_level"] = 4.0
+			return e
+		}, monitor.AlertSeverityCritical},
+		{"battery sensor state", func() *types.PMABaseEntity {
+			e := sensor("sensor.motion_battery", "18", now)
+			e.Attributes["device_class"] = "battery"
+			return e
+		}, monitor.AlertSeverityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := s.evaluate(tt.entity(), now)
+			if tt.wantSeverity == "" {
+				assert.Empty(t, issues)
+				return
+			}
+			require.Len(t, issues, 1)
+			assert.Equal(t, IssueLowBattery, issues[0].Kind)
+			assert.Equal(t, tt.wantSeverity, issues[0].Severity)
+		})
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	deviceID := "device-1"
+	sourceDevice := "shelly-abc"
+
+	withDevice := sensor("sensor.a", "1", time.Now())
+	withDevice.DeviceID = &deviceID
+	withDevice.Metadata = &types.PMAMetadata{Source: types.SourceHomeAssistant}
+	key, id := groupKey(withDevice)
+	assert.Equal(t, "homeassistant:device-1", key)
+	assert.Equal(t, deviceID, id)
+
+	withSourceDevice := sensor("sensor.b", "1", time.Now())
+	withSourceDevice.Metadata = &types.PMAMetadata{Source: types.SourceShelly, SourceDeviceID: &sourceDevice}
+	key, id = groupKey(withSourceDevice)
+	assert.Equal(t, "shelly:shelly-abc", key)
+	assert.Equal(t, sourceDevice, id)
+
+	key, id = groupKey(sensor("sensor.c", "1", time.Now()))
+	assert.Equal(t, "sensor.c", key)
+	assert.Empty(t, id)
+}
+
+func TestBuildAttention(t *testing.T) {
+	groups := map[string]*DeviceGroup{
+		"b": {Key: "b", Name: "Bravo", RoomID: "kitchen", RoomName: "Kitchen", Severity: monitor.AlertSeverityWarning,
+			Issues: []*Issue{{EntityID: "sensor.b2", Kind: IssueStale}, {EntityID: "sensor.b1", Kind: IssueLowBattery}}},
+		"a": {Key: "a", Name: "Alpha", RoomID: "kitchen", RoomName: "Kitchen", Severity: monitor.AlertSeverityWarning,
+			Issues: []*Issue{{EntityID: "sensor.a", Kind: IssueUnavailable}}},
+		"c": {Key: "c", Name: "Charlie", Severity: monitor.AlertSeverityCritical,
+			Issues: []*Issue{{EntityID: "sensor.c", Kind: IssueLowBattery}}},
+	}
+
+	attention := buildAttention(groups, time.Now())
+
+	assert.Equal(t, Summary{Stale: 1, Unavailable: 1, LowBattery: 2, Devices: 3}, attention.Summary)
+	require.Len(t, attention.Devices, 3)
+	assert.Equal(t, []string{"Charlie", "Alpha", "Bravo"}, []string{attention.Devices[0].Name, attention.Devices[1].Name, attention.Devices[2].Name})
+	assert.Equal(t, "sensor.b1", attention.Devices[2].Issues[0].EntityID, "issues are sorted by entity")
+
+	require.Len(t, attention.Rooms, 2)
+	assert.Equal(t, "", attention.Rooms[0].RoomName)
+	assert.Equal(t, &RoomGroup{RoomID: "kitchen", RoomName: "Kitchen", Devices: []string{"a", "b"}, IssueCount: 3}, attention.Rooms[1])
+}
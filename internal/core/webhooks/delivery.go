@@ -0,0 +1,215 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/notifications"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/queue"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/pkg/version"
+	"github.com/sirupsen/logrus"
+)
+
+// maxResponseLog is the number of response body bytes kept in the delivery log
+const maxResponseLog = 512
+
+// deliveryPayload is the action data of a queued delivery. The event is stored encoded so
+// every attempt posts, and signs, the same body.
+type deliveryPayload struct {
+	WebhookID int64           `json:"webhook_id"`
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// deliveryHandler executes queued deliveries
+type deliveryHandler struct {
+	service *Service
+}
+
+// GetHandlerName returns the handler name of the webhook_delivery action type
+func (h *deliveryHandler) GetHandlerName() string {
+	return HandlerName
+}
+
+// GetTimeout returns the maximum duration of one attempt
+func (h *deliveryHandler) GetTimeout() time.Duration {
+	return h.service.client.Timeout
+}
+
+// Execute delivers the event once. Failures are retried by the queue unless the endpoint
+// rejected the event or was disabled.
+func (h *deliveryHandler) Execute(ctx context.Context, action *models.QueuedAction) (*queue.ActionExecutionResult, error) {
+	var payload deliveryPayload
+	if err := json.Unmarshal([]byte(action.ActionData), &payload); err != nil {
+		return &queue.ActionExecutionResult{
+			Success:      false,
+			ErrorCode:    "INVALID_ACTION_DATA",
+			ErrorMessage: err.Error(),
+		}, fmt.Errorf("invalid webhook delivery: %w", err)
+	}
+
+	webhook, err := h.service.Get(payload.WebhookID)
+	if err != nil || !webhook.Enabled {
+		// Deleted or disabled since the event was queued
+		return &queue.ActionExecutionResult{
+			Success: true,
+			Data:    map[string]interface{}{"skipped": true, "webhook_id": payload.WebhookID},
+		}, nil
+	}
+
+	delivery := h.service.deliver(ctx, webhook, payload.EventID, payload.EventType, payload.Event, action.RetryCount+1)
+	enabled := h.service.recordResult(ctx, webhook.ID, delivery)
+
+	result := &queue.ActionExecutionResult{
+		Success: delivery.Success,
+		Data: map[string]interface{}{
+			"webhook_id":  webhook.ID,
+			"delivery_id": delivery.ID,
+			"status_code": delivery.StatusCode,
+		},
+	}
+	if delivery.Success {
+		return result, nil
+	}
+
+	result.ErrorCode = "DELIVERY_FAILED"
+	result.ErrorMessage = delivery.Error
+	result.ShouldRetry = enabled && retryable(delivery.StatusCode)
+	return result, errors.New(delivery.Error)
+}
+
+// retryable reports whether a failed attempt may succeed later. Attempts without a response
+// (status 0) are retried.
+func retryable(statusCode int) bool {
+	switch {
+	case statusCode == 0, statusCode >= 500:
+		return true
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// deliver posts an encoded event to a webhook and logs the attempt. When the webhook has a
+// secret the body is signed with HMAC-SHA256 in the X-PMA-Signature header ("sha256=<hex>").
+func (s *Service) deliver(ctx context.Context, webhook *models.Webhook, eventID, eventType string, body []byte, attempt int) *models.WebhookDelivery {
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   eventID,
+		EventType: eventType,
+		Attempt:   attempt,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	start := time.Now()
+	statusCode, response, err := s.post(ctx, webhook, eventID, eventType, body, attempt)
+	delivery.DurationMS = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.Response = response
+	switch {
+	case err != nil:
+		delivery.Error = err.Error()
+	case statusCode < 200 || statusCode >= 300:
+		delivery.Error = fmt.Sprintf("endpoint returned status %d", statusCode)
+	default:
+		delivery.Success = true
+	}
+
+	if err := s.store.CreateDelivery(ctx, delivery); err != nil {
+		s.logger.WithError(err).WithField("webhook_id", webhook.ID).Warn("Failed to log webhook delivery")
+	}
+	return delivery
+}
+
+func (s *Service) post(ctx context.Context, webhook *models.Webhook, eventID, eventType string, body []byte, attempt int) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PMA-Webhooks/"+version.Version)
+	req.Header.Set("X-PMA-Event", eventType)
+	req.Header.Set("X-PMA-Delivery", eventID)
+	req.Header.Set("X-PMA-Attempt", strconv.Itoa(attempt))
+	if webhook.Secret != "" {
+		req.Header.Set("X-PMA-Signature", notifications.Sign(webhook.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseLog))
+	return resp.StatusCode, strings.TrimSpace(string(snippet)), nil
+}
+
+// recordResult updates the delivery status of a webhook and disables it after too many
+// consecutive failures. It reports whether the webhook is still enabled.
+func (s *Service) recordResult(ctx context.Context, id int64, delivery *models.WebhookDelivery) bool {
+	s.mutex.Lock()
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		s.mutex.Unlock()
+		return false
+	}
+
+	now := delivery.CreatedAt
+	webhook.LastDeliveryAt = &now
+	disabled := false
+	if delivery.Success {
+		webhook.ConsecutiveFailures = 0
+		webhook.LastSuccessAt = &now
+	} else {
+		webhook.ConsecutiveFailures++
+		if webhook.Enabled && webhook.ConsecutiveFailures >= s.disableAfterFailures {
+			webhook.Enabled = false
+			webhook.DisabledReason = fmt.Sprintf("disabled after %d consecutive failed deliveries, last error: %s",
+				webhook.ConsecutiveFailures, delivery.Error)
+			disabled = true
+		}
+	}
+	status := cloneWebhook(webhook)
+	s.mutex.Unlock()
+
+	if err := s.store.SaveWebhookStatus(ctx, status); err != nil {
+		s.logger.WithError(err).WithField("webhook_id", id).Warn("Failed to save webhook status")
+	}
+
+	if disabled {
+		s.logger.WithFields(logrus.Fields{
+			"webhook_id": status.ID,
+			"name":       status.Name,
+			"failures":   status.ConsecutiveFailures,
+		}).Warn("Webhook disabled after repeated delivery failures")
+
+		if s.alertManager != nil {
+			s.alertManager.CreateAlert(monitor.Alert{
+				Severity: monitor.AlertSeverityWarning,
+				Source:   alertSource,
+				Message:  fmt.Sprintf("Webhook %s was disabled after %d failed deliveries", status.Name, status.ConsecutiveFailures),
+				Details: map[string]interface{}{
+					"webhook_id": status.ID,
+					"url":        status.URL,
+					"last_error": delivery.Error,
+				},
+			})
+		}
+	}
+	return status.Enabled
+}
@@ -0,0 +1,452 @@
+// Package webhooks delivers PMA events to user-defined HTTP endpoints. Deliveries run on the
+// action queue, which retries failed attempts with exponential backoff; endpoints that keep
+// failing are disabled.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/queue"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Event types
+const (
+	EventEntityStateChanged  = "entity.state_changed"
+	EventAutomationExecuted  = "automation.executed"
+	EventAlertCreated        = "alert.created"
+	EventAlertResolved       = "alert.resolved"
+	EventAdapterConnected    = "adapter.connected"
+	EventAdapterDisconnected = "adapter.disconnected"
+	EventTest                = "webhook.test"
+)
+
+// EventTypes lists the event types webhooks can subscribe to
+var EventTypes = []string{
+	EventEntityStateChanged,
+	EventAutomationExecuted,
+	EventAlertCreated,
+	EventAlertResolved,
+	EventAdapterConnected,
+	EventAdapterDisconnected,
+}
+
+// Queued deliveries use this action type, which the migrations register with HandlerName
+const (
+	ActionType  = "webhook_delivery"
+	HandlerName = "WebhookDeliveryHandler"
+)
+
+// alertSource is the source of alerts raised for disabled webhooks
+const alertSource = "webhooks"
+
+var (
+	// ErrWebhookNotFound is returned for unknown webhook IDs
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrInvalidWebhook is returned when a webhook fails validation
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// Event is the JSON body posted to webhooks
+type Event struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	EntityID  string                 `json:"entity_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// Store persists webhooks and their delivery log; it is implemented by the webhook repository
+type Store interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	SaveWebhookStatus(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*models.WebhookDelivery, int, error)
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Queue runs deliveries; it is implemented by the queue service
+type Queue interface {
+	EnqueueAction(ctx context.Context, req *models.CreateActionRequest, userID *int) (*models.QueuedAction, error)
+	RegisterHandler(handler queue.ActionHandler)
+}
+
+// Service manages webhook subscriptions and publishes events to them
+type Service struct {
+	store                Store
+	queue                Queue
+	alertManager         *monitor.AlertManager
+	logger               *logrus.Logger
+	client               *http.Client
+	disableAfterFailures int
+	adapterInterval      time.Duration
+	retention            time.Duration
+
+	mutex    sync.RWMutex
+	webhooks map[int64]*models.Webhook
+
+	adapters adapterMonitor
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewService creates the webhook service
+func NewService(cfg config.WebhooksConfig, store Store, queue Queue, logger *logrus.Logger) *Service {
+	s := &Service{
+		store:                store,
+		queue:                queue,
+		logger:               logger,
+		client:               &http.Client{Timeout: parseDuration(cfg.Timeout, 10*time.Second)},
+		disableAfterFailures: cfg.DisableAfterFailures,
+		adapterInterval:      parseDuration(cfg.AdapterCheckInterval, 30*time.Second),
+		retention:            parseDuration(cfg.DeliveryRetention, 7*24*time.Hour),
+		webhooks:             make(map[int64]*models.Webhook),
+		adapters:             adapterMonitor{connected: make(map[string]bool)},
+	}
+	if s.disableAfterFailures <= 0 {
+		s.disableAfterFailures = 10
+	}
+
+	queue.RegisterHandler(&deliveryHandler{service: s})
+	return s
+}
+
+// SetAlertManager sets the alert manager used to report disabled webhooks
+func (s *Service) SetAlertManager(alertManager *monitor.AlertManager) {
+	s.alertManager = alertManager
+}
+
+// Load reads the webhooks from the store
+func (s *Service) Load(ctx context.Context) error {
+	webhooks, err := s.store.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.webhooks = make(map[int64]*models.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		s.webhooks[webhook.ID] = webhook
+	}
+	return nil
+}
+
+// Start starts the adapter status checks and the delivery log cleanup
+func (s *Service) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		adapterTicker := time.NewTicker(s.adapterInterval)
+		defer adapterTicker.Stop()
+		cleanupTicker := time.NewTicker(time.Hour)
+		defer cleanupTicker.Stop()
+
+		s.checkAdapters(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-adapterTicker.C:
+				s.checkAdapters(ctx)
+			case <-cleanupTicker.C:
+				s.cleanup(ctx)
+			}
+		}
+	}()
+
+	s.logger.WithField("webhooks", len(s.List())).Info("Webhook service started")
+}
+
+// Stop stops the background checks
+func (s *Service) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// List returns all webhooks ordered by name
+func (s *Service) List() []*models.Webhook {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]*models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		list = append(list, cloneWebhook(webhook))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Get returns one webhook
+func (s *Service) Get(id int64) (*models.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+	}
+	return cloneWebhook(webhook), nil
+}
+
+// Create validates and stores a new webhook
+func (s *Service) Create(ctx context.Context, webhook *models.Webhook) error {
+	if err := validate(webhook); err != nil {
+		return err
+	}
+	webhook.ConsecutiveFailures = 0
+	webhook.DisabledReason = ""
+
+	if err := s.store.CreateWebhook(ctx, webhook); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.webhooks[webhook.ID] = cloneWebhook(webhook)
+	s.mutex.Unlock()
+	return nil
+}
+
+// Update replaces the settings of a webhook. The secret is kept when changes has none;
+// enabling a webhook clears its failures.
+func (s *Service) Update(ctx context.Context, id int64, changes *models.Webhook) (*models.Webhook, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, ok := s.webhooks[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+	}
+
+	updated := cloneWebhook(existing)
+	updated.Name = changes.Name
+	updated.URL = changes.URL
+	updated.Events = changes.Events
+	updated.Entities = changes.Entities
+	updated.Headers = changes.Headers
+	if changes.Secret != "" {
+		updated.Secret = changes.Secret
+	}
+	if changes.Enabled && !existing.Enabled {
+		updated.ConsecutiveFailures = 0
+		updated.DisabledReason = ""
+	}
+	updated.Enabled = changes.Enabled
+	if err := validate(updated); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateWebhook(ctx, updated); err != nil {
+		return nil, err
+	}
+	s.webhooks[id] = updated
+	return cloneWebhook(updated), nil
+}
+
+// Delete removes a webhook and its delivery log. Queued deliveries to it are skipped.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+	}
+	if err := s.store.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+// Deliveries returns the delivery attempts of a webhook, newest first, and their total count
+func (s *Service) Deliveries(ctx context.Context, id int64, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, 0, err
+	}
+	return s.store.ListDeliveries(ctx, id, limit, offset)
+}
+
+// Test delivers a test event to a webhook once, without retries, and returns the attempt.
+// The result does not count towards disabling the webhook.
+func (s *Service) Test(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	webhook, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := NewEvent(EventTest, "", map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "Test event from PMA",
+	})
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+	return s.deliver(ctx, webhook, event.ID, event.Type, body, 1), nil
+}
+
+// NewEvent creates an event with a new ID
+func NewEvent(eventType, entityID string, data map[string]interface{}) *Event {
+	return &Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		EntityID:  entityID,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// Publish queues a delivery of the event to every enabled webhook whose filters match it
+func (s *Service) Publish(ctx context.Context, event *Event) {
+	s.mutex.RLock()
+	var targets []*models.Webhook
+	for _, webhook := range s.webhooks {
+		if Matches(webhook, event) {
+			targets = append(targets, cloneWebhook(webhook))
+		}
+	}
+	s.mutex.RUnlock()
+
+	if len(targets) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.logger.WithError(err).WithField("event_type", event.Type).Error("Failed to encode webhook event")
+		return
+	}
+
+	for _, webhook := range targets {
+		data, err := json.Marshal(deliveryPayload{WebhookID: webhook.ID, EventID: event.ID, EventType: event.Type, Event: body})
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to encode webhook delivery")
+			continue
+		}
+
+		_, err = s.queue.EnqueueAction(ctx, &models.CreateActionRequest{
+			ActionType:     ActionType,
+			Name:           fmt.Sprintf("Webhook %s: %s", webhook.Name, event.Type),
+			ActionData:     data,
+			TargetEntityID: event.EntityID,
+			CorrelationID:  event.ID,
+		}, nil)
+		if err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"webhook_id": webhook.ID,
+				"event_type": event.Type,
+			}).Error("Failed to queue webhook delivery")
+		}
+	}
+}
+
+// Matches reports whether a webhook is enabled and subscribed to an event. Entity patterns only
+// filter events about an entity.
+func Matches(webhook *models.Webhook, event *Event) bool {
+	if !webhook.Enabled {
+		return false
+	}
+	if len(webhook.Events) > 0 && !matchAny(webhook.Events, event.Type) {
+		return false
+	}
+	if event.EntityID != "" && len(webhook.Entities) > 0 && !matchAny(webhook.Entities, event.EntityID) {
+		return false
+	}
+	return true
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the URL and filter patterns of a webhook
+func validate(webhook *models.Webhook) error {
+	webhook.Name = strings.TrimSpace(webhook.Name)
+	if webhook.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidWebhook)
+	}
+
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+
+	for _, pattern := range append(append([]string{}, webhook.Events...), webhook.Entities...) {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("%w: invalid pattern %q", ErrInvalidWebhook, pattern)
+		}
+	}
+	for name := range webhook.Headers {
+		if http.CanonicalHeaderKey(name) == "Content-Type" || strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Pma-") {
+			return fmt.Errorf("%w: header %s is set by PMA", ErrInvalidWebhook, name)
+		}
+	}
+	return nil
+}
+
+// cleanup removes delivery attempts older than the retention
+func (s *Service) cleanup(ctx context.Context) {
+	deleted, err := s.store.DeleteDeliveriesBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to clean up webhook deliveries")
+		return
+	}
+	if deleted > 0 {
+		s.logger.WithField("deleted", deleted).Debug("Cleaned up webhook deliveries")
+	}
+}
+
+func cloneWebhook(webhook *models.Webhook) *models.Webhook {
+	clone := *webhook
+	clone.Events = append([]string{}, webhook.Events...)
+	clone.Entities = append([]string{}, webhook.Entities...)
+	clone.Headers = make(map[string]string, len(webhook.Headers))
+	for key, value := range webhook.Headers {
+		clone.Headers[key] = value
+	}
+	return &clone
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
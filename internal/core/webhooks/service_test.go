@@ -0,0 +1,344 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/notifications"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/queue"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore keeps webhooks and deliveries in memory
+type memoryStore struct {
+	mu         sync.Mutex
+	nextID     int64
+	webhooks   map[int64]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{webhooks: make(map[int64]*models.Webhook)}
+}
+
+func (m *memoryStore) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	webhook.ID = m.nextID
+	m.webhooks[webhook.ID] = cloneWebhook(webhook)
+	return nil
+}
+
+func (m *memoryStore) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []*models.Webhook
+	for _, webhook := range m.webhooks {
+		list = append(list, cloneWebhook(webhook))
+	}
+	return list, nil
+}
+
+func (m *memoryStore) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	return m.SaveWebhookStatus(ctx, webhook)
+}
+
+func (m *memoryStore) SaveWebhookStatus(ctx context.Context, webhook *models.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks[webhook.ID] = cloneWebhook(webhook)
+	return nil
+}
+
+func (m *memoryStore) DeleteWebhook(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.webhooks, id)
+	return nil
+}
+
+func (m *memoryStore) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery.ID = int64(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+func (m *memoryStore) ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deliveries, len(m.deliveries), nil
+}
+
+func (m *memoryStore) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// recordingQueue records enqueued actions and the registered handler
+type recordingQueue struct {
+	handler queue.ActionHandler
+	actions []*models.CreateActionRequest
+}
+
+func (q *recordingQueue) EnqueueAction(ctx context.Context, req *models.CreateActionRequest, userID *int) (*models.QueuedAction, error) {
+	q.actions = append(q.actions, req)
+	return &models.QueuedAction{ID: len(q.actions), ActionData: string(req.ActionData)}, nil
+}
+
+func (q *recordingQueue) RegisterHandler(handler queue.ActionHandler) {
+	q.handler = handler
+}
+
+func newTestService(t *testing.T, disableAfter int) (*Service, *memoryStore, *recordingQueue) {
+	t.Helper()
+	store := newMemoryStore()
+	q := &recordingQueue{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	service := NewService(config.WebhooksConfig{Timeout: "2s", DisableAfterFailures: disableAfter}, store, q, logger)
+	require.NotNil(t, q.handler)
+	assert.Equal(t, HandlerName, q.handler.GetHandlerName())
+	return service, store, q
+}
+
+func createWebhook(t *testing.T, service *Service, url string) *models.Webhook {
+	t.Helper()
+	webhook := &models.Webhook{Name: "test", URL: url, Secret: "s3cret", Enabled: true}
+	require.NoError(t, service.Create(context.Background(), webhook))
+	return webhook
+}
+
+// publishOne publishes an event and returns the queued delivery as the queue would hand it over
+func publishOne(t *testing.T, service *Service, q *recordingQueue, retryCount int) *models.QueuedAction {
+	t.Helper()
+	service.Publish(context.Background(), NewEvent(EventAlertCreated, "", map[string]interface{}{"message": "hot"}))
+	require.NotEmpty(t, q.actions)
+	req := q.actions[len(q.actions)-1]
+	return &models.QueuedAction{ActionData: string(req.ActionData), RetryCount: retryCount}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       bool
+	}{
+		{0, true},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+		{http.StatusGone, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, retryable(tt.statusCode), "status %d", tt.statusCode)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook models.Webhook
+		event   Event
+		want    bool
+	}{
+		{"no filters", models.Webhook{Enabled: true}, Event{Type: EventAlertCreated}, true},
+		{"disabled", models.Webhook{}, Event{Type: EventAlertCreated}, false},
+		{"event pattern", models.Webhook{Enabled: true, Events: []string{"alert.*"}}, Event{Type: EventAlertResolved}, true},
+		{"event mismatch", models.Webhook{Enabled: true, Events: []string{"alert.*"}}, Event{Type: EventAdapterConnected}, false},
+		{"entity pattern", models.Webhook{Enabled: true, Entities: []string{"light.*"}}, Event{Type: EventEntityStateChanged, EntityID: "light.kitchen"}, true},
+		{"entity mismatch", models.Webhook{Enabled: true, Entities: []string{"light.*"}}, Event{Type: EventEntityStateChanged, EntityID: "switch.fan"}, false},
+		{"entity patterns ignore events without entity", models.Webhook{Enabled: true, Entities: []string{"light.*"}}, Event{Type: EventAlertCreated}, true},
+		{
+			"event and entity",
+			models.Webhook{Enabled: true, Events: []string{EventEntityStateChanged}, Entities: []string{"sensor.*", "light.*"}},
+			Event{Type: EventEntityStateChanged, EntityID: "sensor.temperature"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Matches(&tt.webhook, &tt.event))
+		})
+	}
+}
+
+func TestDeliveryHandlerExecute(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantSuccess bool
+		wantRetry   bool
+	}{
+		{"success", http.StatusNoContent, true, false},
+		{"server error is retried", http.StatusServiceUnavailable, false, true},
+		{"rate limit is retried", http.StatusTooManyRequests, false, true},
+		{"rejection is not retried", http.StatusBadRequest, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			service, store, q := newTestService(t, 10)
+			webhook := createWebhook(t, service, server.URL)
+			action := publishOne(t, service, q, 1)
+
+			result, err := q.handler.Execute(context.Background(), action)
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantSuccess, result.Success)
+			assert.Equal(t, tt.wantRetry, result.ShouldRetry)
+			if tt.wantSuccess {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, "DELIVERY_FAILED", result.ErrorCode)
+			}
+
+			// Headers identify and sign the event
+			var event Event
+			require.NoError(t, json.Unmarshal(body, &event))
+			assert.Equal(t, EventAlertCreated, header.Get("X-PMA-Event"))
+			assert.Equal(t, event.ID, header.Get("X-PMA-Delivery"))
+			assert.Equal(t, "2", header.Get("X-PMA-Attempt"))
+			assert.Equal(t, notifications.Sign("s3cret", body), header.Get("X-PMA-Signature"))
+
+			require.Len(t, store.deliveries, 1)
+			assert.Equal(t, tt.status, store.deliveries[0].StatusCode)
+			assert.Equal(t, tt.wantSuccess, store.deliveries[0].Success)
+
+			status, err := service.Get(webhook.ID)
+			require.NoError(t, err)
+			if tt.wantSuccess {
+				assert.Zero(t, status.ConsecutiveFailures)
+				assert.NotNil(t, status.LastSuccessAt)
+			} else {
+				assert.Equal(t, 1, status.ConsecutiveFailures)
+			}
+		})
+	}
+}
+
+func TestDeliveryHandlerExecuteUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	service, _, q := newTestService(t, 10)
+	createWebhook(t, service, url)
+
+	result, err := q.handler.Execute(context.Background(), publishOne(t, service, q, 0))
+	assert.Error(t, err)
+	assert.False(t, result.Success)
+	assert.True(t, result.ShouldRetry, "attempts without a response are retried")
+}
+
+func TestDeliveryHandlerExecuteSkips(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	t.Run("deleted webhook", func(t *testing.T) {
+		service, _, q := newTestService(t, 10)
+		webhook := createWebhook(t, service, server.URL)
+		action := publishOne(t, service, q, 0)
+		require.NoError(t, service.Delete(context.Background(), webhook.ID))
+
+		result, err := q.handler.Execute(context.Background(), action)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, true, result.Data["skipped"])
+	})
+
+	t.Run("disabled webhook", func(t *testing.T) {
+		service, _, q := newTestService(t, 10)
+		webhook := createWebhook(t, service, server.URL)
+		action := publishOne(t, service, q, 0)
+
+		webhook.Enabled = false
+		_, err := service.Update(context.Background(), webhook.ID, webhook)
+		require.NoError(t, err)
+
+		result, err := q.handler.Execute(context.Background(), action)
+		require.NoError(t, err)
+		assert.Equal(t, true, result.Data["skipped"])
+	})
+
+	t.Run("invalid action data", func(t *testing.T) {
+		_, _, q := newTestService(t, 10)
+
+		result, err := q.handler.Execute(context.Background(), &models.QueuedAction{ActionData: "{"})
+		assert.Error(t, err)
+		assert.Equal(t, "INVALID_ACTION_DATA", result.ErrorCode)
+		assert.False(t, result.ShouldRetry)
+	})
+
+	assert.Zero(t, requests)
+}
+
+func TestRecordResultDisablesAfterThreshold(t *testing.T) {
+	service, store, _ := newTestService(t, 3)
+	webhook := createWebhook(t, service, "http://example.invalid/hook")
+	ctx := context.Background()
+
+	failure := &models.WebhookDelivery{Error: "endpoint returned status 500", CreatedAt: time.Now()}
+	assert.True(t, service.recordResult(ctx, webhook.ID, failure))
+	assert.True(t, service.recordResult(ctx, webhook.ID, failure))
+
+	// A success resets the count
+	assert.True(t, service.recordResult(ctx, webhook.ID, &models.WebhookDelivery{Success: true, CreatedAt: time.Now()}))
+	status, _ := service.Get(webhook.ID)
+	assert.Zero(t, status.ConsecutiveFailures)
+
+	assert.True(t, service.recordResult(ctx, webhook.ID, failure))
+	assert.True(t, service.recordResult(ctx, webhook.ID, failure))
+	assert.False(t, service.recordResult(ctx, webhook.ID, failure), "the third consecutive failure disables the webhook")
+
+	status, _ = service.Get(webhook.ID)
+	assert.False(t, status.Enabled)
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+	assert.Contains(t, status.DisabledReason, "endpoint returned status 500")
+	assert.False(t, store.webhooks[webhook.ID].Enabled, "the disabled status is saved")
+
+	assert.False(t, service.recordResult(ctx, 999, failure), "unknown webhooks are not enabled")
+}
+
+func TestExecuteDoesNotRetryOnceDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service, _, q := newTestService(t, 1)
+	createWebhook(t, service, server.URL)
+
+	result, err := q.handler.Execute(context.Background(), publishOne(t, service, q, 0))
+	assert.Error(t, err)
+	assert.False(t, result.ShouldRetry, "a webhook disabled by this attempt is not retried")
+}
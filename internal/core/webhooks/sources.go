@@ -0,0 +1,127 @@
+package webhooks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/core/automation"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/monitor"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/types"
+	"github.com/frostdev-ops/pma-backend-go/internal/core/unified"
+)
+
+// adapterMonitor remembers the connection state of adapters between checks
+type adapterMonitor struct {
+	mutex     sync.Mutex
+	registry  types.AdapterRegistry
+	connected map[string]bool // adapter ID -> connected at the last check
+}
+
+// ObserveEntities publishes entity state changes
+func (s *Service) ObserveEntities(entities *unified.UnifiedEntityService) {
+	entities.AddStateChangeListener(func(entityID string, oldState, newState types.PMAEntityState, source types.PMASourceType) {
+		s.Publish(context.Background(), NewEvent(EventEntityStateChanged, entityID, map[string]interface{}{
+			"entity_id": entityID,
+			"old_state": string(oldState),
+			"new_state": string(newState),
+			"source":    string(source),
+		}))
+	})
+}
+
+// ObserveAutomations publishes automation rule runs
+func (s *Service) ObserveAutomations(engine *automation.AutomationEngine) {
+	engine.OnRuleExecuted(func(execution automation.RuleExecution) {
+		data := map[string]interface{}{
+			"rule_id":      execution.RuleID,
+			"execution_id": execution.ID,
+			"success":      execution.Success,
+			"duration_ms":  execution.Duration.Milliseconds(),
+		}
+		for key, value := range execution.Context {
+			data[key] = value
+		}
+		if execution.Error != "" {
+			data["error"] = execution.Error
+		}
+		s.Publish(context.Background(), NewEvent(EventAutomationExecuted, "", data))
+	})
+}
+
+// ObserveAlerts publishes created and resolved alerts
+func (s *Service) ObserveAlerts(alertManager *monitor.AlertManager) {
+	alertManager.OnAlertCreated(func(alert *monitor.Alert) {
+		s.Publish(context.Background(), NewEvent(EventAlertCreated, "", alertData(alert)))
+	})
+	alertManager.OnAlertResolved(func(alert *monitor.Alert) {
+		s.Publish(context.Background(), NewEvent(EventAlertResolved, "", alertData(alert)))
+	})
+}
+
+func alertData(alert *monitor.Alert) map[string]interface{} {
+	data := map[string]interface{}{
+		"alert_id":  alert.ID,
+		"severity":  string(alert.Severity),
+		"source":    alert.Source,
+		"message":   alert.Message,
+		"details":   alert.Details,
+		"timestamp": alert.Timestamp,
+	}
+	if alert.Resolved {
+		data["resolved_at"] = alert.ResolvedAt
+		data["resolved_by"] = alert.ResolvedBy
+	}
+	return data
+}
+
+// ObserveAdapters publishes adapters connecting and disconnecting. Adapters are checked
+// periodically once the service is started; adapters seen for the first time are not reported.
+func (s *Service) ObserveAdapters(registry types.AdapterRegistry) {
+	s.adapters.mutex.Lock()
+	defer s.adapters.mutex.Unlock()
+	s.adapters.registry = registry
+}
+
+// checkAdapters compares the connection state of every adapter with the last check
+func (s *Service) checkAdapters(ctx context.Context) {
+	s.adapters.mutex.Lock()
+	if s.adapters.registry == nil {
+		s.adapters.mutex.Unlock()
+		return
+	}
+
+	var events []*Event
+	seen := make(map[string]bool)
+	for _, adapter := range s.adapters.registry.GetAllAdapters() {
+		id := adapter.GetID()
+		connected := adapter.IsConnected()
+		seen[id] = true
+
+		previous, known := s.adapters.connected[id]
+		s.adapters.connected[id] = connected
+		if !known || previous == connected {
+			continue
+		}
+
+		eventType := EventAdapterDisconnected
+		if connected {
+			eventType = EventAdapterConnected
+		}
+		events = append(events, NewEvent(eventType, "", map[string]interface{}{
+			"adapter_id": id,
+			"name":       adapter.GetName(),
+			"source":     string(adapter.GetSourceType()),
+			"status":     adapter.GetStatus(),
+		}))
+	}
+	for id := range s.adapters.connected {
+		if !seen[id] {
+			delete(s.adapters.connected, id)
+		}
+	}
+	s.adapters.mutex.Unlock()
+
+	for _, event := range events {
+		s.Publish(ctx, event)
+	}
+}
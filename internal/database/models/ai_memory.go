@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AI memory sources
+const (
+	AIMemorySourceConversation = "conversation" // Learned from a conversation
+	AIMemorySourceNote         = "note"         // Written by a user
+)
+
+// AIMemory is a fact the assistant remembers across conversations
+type AIMemory struct {
+	ID             int64      `json:"id" db:"id"`
+	UserID         string     `json:"user_id,omitempty" db:"user_id"` // Empty for household memories every user shares
+	Content        string     `json:"content" db:"content"`
+	Source         string     `json:"source" db:"source"`
+	ConversationID string     `json:"conversation_id,omitempty" db:"conversation_id"`
+	Embedding      []float32  `json:"-" db:"embedding"`
+	EmbeddingModel string     `json:"embedding_model,omitempty" db:"embedding_model"` // "provider/model" of the embedding
+	RecallCount    int        `json:"recall_count" db:"recall_count"`
+	LastRecalledAt *time.Time `json:"last_recalled_at,omitempty" db:"last_recalled_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AIMemoryFilter selects the memories visible to a user: their own and the household's
+type AIMemoryFilter struct {
+	UserID string
+	Source string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry is one row of the audit log. Hash covers every other field and PrevHash, so editing
+// or deleting a row breaks the chain.
+type AuditEntry struct {
+	ID        int64           `json:"id" db:"id"`
+	Timestamp time.Time       `json:"timestamp" db:"timestamp"`
+	ActorType string          `json:"actor_type" db:"actor_type"`
+	ActorID   string          `json:"actor_id,omitempty" db:"actor_id"`
+	ActorName string          `json:"actor_name,omitempty" db:"actor_name"`
+	Category  string          `json:"category" db:"category"`
+	Action    string          `json:"action" db:"action"`
+	Target    string          `json:"target,omitempty" db:"target"`
+	Outcome   string          `json:"outcome" db:"outcome"`
+	Error     string          `json:"error,omitempty" db:"error"`
+	Details   json.RawMessage `json:"details,omitempty" db:"details"`
+	SourceIP  string          `json:"source_ip,omitempty" db:"source_ip"`
+	PrevHash  string          `json:"prev_hash" db:"prev_hash"`
+	Hash      string          `json:"hash" db:"hash"`
+}
+
+// AuditFilter selects audit entries; empty fields match everything
+type AuditFilter struct {
+	ActorType string    `json:"actor_type,omitempty"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Outcome   string    `json:"outcome,omitempty"`
+	Since     time.Time `json:"since,omitempty"`
+	Until     time.Time `json:"until,omitempty"`
+	Limit     int       `json:"limit,omitempty"`
+	Offset    int       `json:"offset,omitempty"`
+}
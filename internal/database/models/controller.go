@@ -7,22 +7,42 @@ import (
 
 // ControllerDashboard represents a controller dashboard
 type ControllerDashboard struct {
-	ID           int        `json:"id" db:"id"`
-	Name         string     `json:"name" db:"name"`
-	Description  string     `json:"description" db:"description"`
-	Category     string     `json:"category" db:"category"`
-	LayoutConfig string     `json:"layout_config" db:"layout_config"` // JSON
-	ElementsJSON string     `json:"elements_json" db:"elements_json"` // JSON
-	StyleConfig  string     `json:"style_config" db:"style_config"`   // JSON
-	AccessConfig string     `json:"access_config" db:"access_config"` // JSON
-	IsFavorite   bool       `json:"is_favorite" db:"is_favorite"`
-	Tags         string     `json:"tags" db:"tags"` // JSON array
-	ThumbnailURL *string    `json:"thumbnail_url" db:"thumbnail_url"`
-	Version      int        `json:"version" db:"version"`
-	UserID       *int       `json:"user_id" db:"user_id"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	LastAccessed *time.Time `json:"last_accessed" db:"last_accessed"`
+	ID            int        `json:"id" db:"id"`
+	Name          string     `json:"name" db:"name"`
+	Description   string     `json:"description" db:"description"`
+	Category      string     `json:"category" db:"category"`
+	LayoutConfig  string     `json:"layout_config" db:"layout_config"` // JSON
+	ElementsJSON  string     `json:"elements_json" db:"elements_json"` // JSON
+	StyleConfig   string     `json:"style_config" db:"style_config"`   // JSON
+	AccessConfig  string     `json:"access_config" db:"access_config"` // JSON
+	IsFavorite    bool       `json:"is_favorite" db:"is_favorite"`
+	Tags          string     `json:"tags" db:"tags"` // JSON array
+	ThumbnailURL  *string    `json:"thumbnail_url" db:"thumbnail_url"`
+	Version       int        `json:"version" db:"version"`               // Revision number, incremented on every update
+	SchemaVersion int        `json:"schema_version" db:"schema_version"` // Element schema version of the stored JSON
+	UserID        *int       `json:"user_id" db:"user_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	LastAccessed  *time.Time `json:"last_accessed" db:"last_accessed"`
+}
+
+// ControllerDashboardRevision represents a historical snapshot of a dashboard
+type ControllerDashboardRevision struct {
+	ID            int       `json:"id" db:"id"`
+	DashboardID   int       `json:"dashboard_id" db:"dashboard_id"`
+	Revision      int       `json:"revision" db:"revision"`
+	SchemaVersion int       `json:"schema_version" db:"schema_version"`
+	Name          string    `json:"name" db:"name"`
+	Description   string    `json:"description" db:"description"`
+	Category      string    `json:"category" db:"category"`
+	LayoutConfig  string    `json:"layout_config" db:"layout_config"` // JSON
+	ElementsJSON  string    `json:"elements_json" db:"elements_json"` // JSON
+	StyleConfig   string    `json:"style_config" db:"style_config"`   // JSON
+	AccessConfig  string    `json:"access_config" db:"access_config"` // JSON
+	Tags          string    `json:"tags" db:"tags"`                   // JSON array
+	ChangeSummary string    `json:"change_summary" db:"change_summary"`
+	UserID        *int      `json:"user_id" db:"user_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 }
 
 // DashboardLayout represents the layout configuration
@@ -99,10 +119,14 @@ type ControllerTemplate struct {
 // TemplateVariable represents a template variable
 type TemplateVariable struct {
 	Name        string      `json:"name"`
-	Type        string      `json:"type"`
+	Type        string      `json:"type"` // string, number, boolean, color, select, entity, room
+	Label       string      `json:"label,omitempty"`
 	Default     interface{} `json:"default"`
 	Description string      `json:"description"`
-	EntityType  string      `json:"entity_type,omitempty"`
+	EntityType  string      `json:"entity_type,omitempty"` // Restricts entity pickers to a domain
+	Options     []string    `json:"options,omitempty"`     // Allowed values for select variables
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
 	Required    bool        `json:"required,omitempty"`
 }
 
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// EntityCustomization holds user overrides for an entity. Empty fields keep the value of the source.
+type EntityCustomization struct {
+	EntityID     string    `json:"entity_id" db:"entity_id"`
+	FriendlyName string    `json:"friendly_name,omitempty" db:"friendly_name"`
+	Icon         string    `json:"icon,omitempty" db:"icon"`
+	EntityType   string    `json:"entity_type,omitempty" db:"entity_type"`
+	Unit         string    `json:"unit,omitempty" db:"unit"`
+	DeviceClass  string    `json:"device_class,omitempty" db:"device_class"`
+	Hidden       bool      `json:"hidden" db:"hidden"`
+	Disabled     bool      `json:"disabled" db:"disabled"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsEmpty reports whether the customization overrides nothing
+func (c *EntityCustomization) IsEmpty() bool {
+	return c.FriendlyName == "" && c.Icon == "" && c.EntityType == "" && c.Unit == "" &&
+		c.DeviceClass == "" && !c.Hidden && !c.Disabled
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Entity group modes
+const (
+	GroupModeAny = "any"
+	GroupModeAll = "all"
+)
+
+// Sensor group aggregates
+const (
+	GroupAggregateMin    = "min"
+	GroupAggregateMax    = "max"
+	GroupAggregateMean   = "mean"
+	GroupAggregateMedian = "median"
+	GroupAggregateSum    = "sum"
+)
+
+// EntityGroup is a user-defined group of entities exposed as one virtual entity
+type EntityGroup struct {
+	ID         string    `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	Mode       string    `json:"mode" db:"mode"`
+	Aggregate  string    `json:"aggregate,omitempty" db:"aggregate"`
+	Unit       string    `json:"unit,omitempty" db:"unit"`
+	Icon       string    `json:"icon,omitempty" db:"icon"`
+	RoomID     string    `json:"room_id,omitempty" db:"room_id"`
+	Members    []string  `json:"members" db:"-"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Helper is a persistent helper entity such as an input_boolean or a timer
+type Helper struct {
+	ID              string       `json:"id" db:"id"`
+	Type            string       `json:"type" db:"type"`
+	Name            string       `json:"name" db:"name"`
+	Icon            string       `json:"icon,omitempty" db:"icon"`
+	Config          HelperConfig `json:"config" db:"config"`
+	State           string       `json:"state" db:"state"`
+	TimerDuration   int          `json:"timer_duration,omitempty" db:"timer_duration"`
+	TimerRemaining  int          `json:"timer_remaining,omitempty" db:"timer_remaining"`
+	TimerFinishesAt *time.Time   `json:"timer_finishes_at,omitempty" db:"timer_finishes_at"`
+	CreatedAt       time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// HelperConfig holds the type specific settings of a helper
+type HelperConfig struct {
+	Min       *float64 `json:"min,omitempty"`        // input_number, counter
+	Max       *float64 `json:"max,omitempty"`        // input_number, counter
+	Step      float64  `json:"step,omitempty"`       // input_number, counter
+	Unit      string   `json:"unit,omitempty"`       // input_number
+	Mode      string   `json:"mode,omitempty"`       // input_number: slider or box, input_text: text or password
+	Options   []string `json:"options,omitempty"`    // input_select
+	MaxLength int      `json:"max_length,omitempty"` // input_text
+	Pattern   string   `json:"pattern,omitempty"`    // input_text
+	Initial   string   `json:"initial,omitempty"`    // value set on creation and reset
+	Duration  string   `json:"duration,omitempty"`   // timer: default duration, e.g. "5m"
+}
@@ -97,6 +97,7 @@ type KioskCommand struct {
 	ExpiresAt      time.Time       `json:"expires_at" db:"expires_at"`
 	ResultData     json.RawMessage `json:"result_data" db:"result_data"` // JSON object with command result
 	ErrorMessage   sql.NullString  `json:"error_message" db:"error_message"`
+	TimeoutSeconds int             `json:"timeout_seconds" db:"timeout_seconds"` // result timeout once delivered, 0 for the type default
 }
 
 // Request/Response DTOs for API endpoints
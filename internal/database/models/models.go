@@ -43,10 +43,21 @@ type Room struct {
 	HomeAssistantAreaID sql.NullString `json:"home_assistant_area_id" db:"home_assistant_area_id"`
 	Icon                sql.NullString `json:"icon" db:"icon"`
 	Description         sql.NullString `json:"description" db:"description"`
+	RoomKey             sql.NullString `json:"room_key" db:"room_key"`
+	ParentKey           sql.NullString `json:"parent_key" db:"parent_key"`
+	Metadata            sql.NullString `json:"metadata" db:"metadata"`
+	Attributes          sql.NullString `json:"attributes" db:"attributes"`
 	CreatedAt           time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at" db:"updated_at"`
 }
 
+// RoomEntityAssignment places an entity in a room by the room's key
+type RoomEntityAssignment struct {
+	EntityID   string    `json:"entity_id" db:"entity_id"`
+	RoomKey    string    `json:"room_key" db:"room_key"`
+	AssignedAt time.Time `json:"assigned_at" db:"assigned_at"`
+}
+
 // DisplaySetting represents a display configuration
 type DisplaySetting struct {
 	ID        int             `json:"id" db:"id"`
@@ -165,6 +176,79 @@ type Camera struct {
 	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
 }
 
+// CameraEvent represents an archived motion/doorbell event with its snapshot and clip
+type CameraEvent struct {
+	ID              int             `json:"id" db:"id"`
+	CameraEntityID  string          `json:"camera_entity_id" db:"camera_entity_id"`
+	EventType       string          `json:"event_type" db:"event_type"` // motion, ding, manual
+	Source          string          `json:"source" db:"source"`
+	TriggerEntityID string          `json:"trigger_entity_id,omitempty" db:"trigger_entity_id"`
+	ExternalID      string          `json:"external_id,omitempty" db:"external_id"`
+	SnapshotFileID  string          `json:"snapshot_file_id,omitempty" db:"snapshot_file_id"`
+	ThumbnailFileID string          `json:"thumbnail_file_id,omitempty" db:"thumbnail_file_id"`
+	ClipFileID      string          `json:"clip_file_id,omitempty" db:"clip_file_id"`
+	ClipStatus      string          `json:"clip_status" db:"clip_status"` // none, pending, downloaded, failed
+	Metadata        json.RawMessage `json:"metadata" db:"metadata"`
+	OccurredAt      time.Time       `json:"occurred_at" db:"occurred_at"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+}
+
+// CameraEventFilter narrows camera event timeline queries
+type CameraEventFilter struct {
+	CameraEntityID string
+	EventType      string
+	Since          time.Time
+	Until          time.Time
+	Limit          int
+	Offset         int
+}
+
+// Notification is a delivered notification together with its read, acknowledgement and response state
+type Notification struct {
+	ID             string          `json:"id" db:"id"`
+	UserID         string          `json:"user_id" db:"user_id"`
+	Type           string          `json:"type" db:"type"`
+	Title          string          `json:"title" db:"title"`
+	Message        string          `json:"message" db:"message"`
+	Priority       string          `json:"priority" db:"priority"`
+	Source         string          `json:"source,omitempty" db:"source"`
+	RoomID         string          `json:"room_id,omitempty" db:"room_id"`
+	DeviceID       string          `json:"device_id,omitempty" db:"device_id"`
+	Data           json.RawMessage `json:"data" db:"data"`
+	Actions        json.RawMessage `json:"actions" db:"actions"`
+	Categories     json.RawMessage `json:"categories" db:"categories"`
+	Deliveries     json.RawMessage `json:"deliveries" db:"deliveries"`
+	ReadAt         *time.Time      `json:"read_at,omitempty" db:"read_at"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	Response       json.RawMessage `json:"response,omitempty" db:"response"`
+	RespondedAt    *time.Time      `json:"responded_at,omitempty" db:"responded_at"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+// NotificationFilter narrows notification list queries
+type NotificationFilter struct {
+	UserID             string
+	Type               string
+	UnreadOnly         bool
+	UnacknowledgedOnly bool
+	Since              time.Time
+	Limit              int
+	Offset             int
+}
+
+// PushSubscription is a browser Web Push subscription of a user
+type PushSubscription struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Endpoint  string    `json:"endpoint" db:"endpoint"`
+	P256dh    string    `json:"p256dh" db:"p256dh"`
+	Auth      string    `json:"auth" db:"auth"`
+	UserAgent string    `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
 // DisplaySettings represents display/screensaver configuration
 type DisplaySettings struct {
 	ID                           int       `json:"id" db:"id"`
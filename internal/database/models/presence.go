@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Presence tracker types
+const (
+	TrackerTypeNetwork = "network"
+	TrackerTypeBLE     = "ble"
+	TrackerTypeEntity  = "entity"
+	TrackerTypeApp     = "app"
+)
+
+// PresencePerson is a person whose presence is derived from their trackers
+type PresencePerson struct {
+	ID                  string             `json:"id" db:"id"`
+	Name                string             `json:"name" db:"name"`
+	UserID              string             `json:"user_id,omitempty" db:"user_id"`
+	Picture             string             `json:"picture,omitempty" db:"picture"`
+	ConsiderHomeSeconds int                `json:"consider_home_seconds" db:"consider_home_seconds"`
+	Trackers            []*PresenceTracker `json:"trackers,omitempty" db:"-"`
+	CreatedAt           time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// PresenceTracker is one signal that can place a person at home or in a zone
+type PresenceTracker struct {
+	ID                  int        `json:"id" db:"id"`
+	PersonID            string     `json:"person_id" db:"person_id"`
+	Type                string     `json:"type" db:"type"`
+	Identifier          string     `json:"identifier" db:"identifier"`
+	Name                string     `json:"name,omitempty" db:"name"`
+	ProbGivenHome       float64    `json:"prob_given_home" db:"prob_given_home"`
+	ProbGivenAway       float64    `json:"prob_given_away" db:"prob_given_away"`
+	ConsiderHomeSeconds int        `json:"consider_home_seconds" db:"consider_home_seconds"`
+	MinRSSI             int        `json:"min_rssi,omitempty" db:"min_rssi"`
+	LastSeen            *time.Time `json:"last_seen,omitempty" db:"last_seen"`
+	LastState           string     `json:"last_state,omitempty" db:"last_state"`
+	LastReported        *time.Time `json:"last_reported,omitempty" db:"last_reported"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PresenceZone is a circular area a person can be reported in
+type PresenceZone struct {
+	ID           string    `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Latitude     float64   `json:"latitude" db:"latitude"`
+	Longitude    float64   `json:"longitude" db:"longitude"`
+	RadiusMeters float64   `json:"radius_meters" db:"radius_meters"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Webhook is an outbound endpoint notified of events matching its filters
+type Webhook struct {
+	ID                  int64             `json:"id" db:"id"`
+	Name                string            `json:"name" db:"name"`
+	URL                 string            `json:"url" db:"url"`
+	Secret              string            `json:"-" db:"secret"`
+	Events              []string          `json:"events" db:"events"`     // Event type patterns, e.g. "alert.*"; empty matches all
+	Entities            []string          `json:"entities" db:"entities"` // Entity ID patterns for entity events, e.g. "light.*"
+	Headers             map[string]string `json:"headers,omitempty" db:"headers"`
+	Enabled             bool              `json:"enabled" db:"enabled"`
+	ConsecutiveFailures int               `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledReason      string            `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	LastDeliveryAt      *time.Time        `json:"last_delivery_at,omitempty" db:"last_delivery_at"`
+	LastSuccessAt       *time.Time        `json:"last_success_at,omitempty" db:"last_success_at"`
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// HasSecret reports whether payloads are signed
+func (w *Webhook) HasSecret() bool {
+	return w.Secret != ""
+}
+
+// WebhookDelivery is one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID         int64     `json:"id" db:"id"`
+	WebhookID  int64     `json:"webhook_id" db:"webhook_id"`
+	EventID    string    `json:"event_id" db:"event_id"`
+	EventType  string    `json:"event_type" db:"event_type"`
+	Attempt    int       `json:"attempt" db:"attempt"`
+	Success    bool      `json:"success" db:"success"`
+	StatusCode int       `json:"status_code,omitempty" db:"status_code"`
+	Response   string    `json:"response,omitempty" db:"response"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	DurationMS int64     `json:"duration_ms" db:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -10,23 +10,31 @@ import (
 
 // Repositories holds all repository instances
 type Repositories struct {
-	User         repositories.UserRepository
-	Config       repositories.ConfigRepository
-	Entity       repositories.EntityRepository
-	Room         repositories.RoomRepository
-	Auth         repositories.AuthRepository
-	Kiosk        repositories.KioskRepository
-	Network      repositories.NetworkRepository
-	UPS          repositories.UPSRepository
-	Camera       repositories.CameraRepository
-	Display      repositories.DisplayRepository
-	Bluetooth    repositories.BluetoothRepository
-	Energy       repositories.EnergyRepository
-	Conversation repositories.ConversationRepository
-	MCP          repositories.MCPRepository
-	Area         repositories.AreaRepository
-	Controller   repositories.ControllerRepository
-	Screensaver  repositories.ScreensaverRepository
+	User          repositories.UserRepository
+	Config        repositories.ConfigRepository
+	Entity        repositories.EntityRepository
+	Room          repositories.RoomRepository
+	Auth          repositories.AuthRepository
+	Kiosk         repositories.KioskRepository
+	Network       repositories.NetworkRepository
+	UPS           repositories.UPSRepository
+	Camera        repositories.CameraRepository
+	Display       repositories.DisplayRepository
+	Bluetooth     repositories.BluetoothRepository
+	Energy        repositories.EnergyRepository
+	Conversation  repositories.ConversationRepository
+	MCP           repositories.MCPRepository
+	Area          repositories.AreaRepository
+	Controller    repositories.ControllerRepository
+	Screensaver   repositories.ScreensaverRepository
+	Notification  repositories.NotificationRepository
+	Presence      repositories.PresenceRepository
+	EntityGroup   repositories.EntityGroupRepository
+	Helper        repositories.HelperRepository
+	Customization repositories.EntityCustomizationRepository
+	Audit         repositories.AuditRepository
+	Webhook       repositories.WebhookRepository
+	AIMemory      repositories.AIMemoryRepository
 }
 
 // NewRepositories creates all repository instances
@@ -35,22 +43,30 @@ func NewRepositories(db *sql.DB) *Repositories {
 	sqlxDB := sqlx.NewDb(db, "sqlite")
 
 	return &Repositories{
-		User:         sqlite.NewUserRepository(db),
-		Config:       sqlite.NewConfigRepository(db),
-		Entity:       sqlite.NewEntityRepository(db),
-		Room:         sqlite.NewRoomRepository(db),
-		Auth:         sqlite.NewAuthRepository(db),
-		Kiosk:        sqlite.NewKioskRepository(db),
-		Network:      sqlite.NewNetworkRepository(db),
-		UPS:          sqlite.NewUPSRepository(db),
-		Camera:       sqlite.NewCameraRepository(db),
-		Display:      sqlite.NewDisplaySettingsRepository(db),
-		Bluetooth:    sqlite.NewBluetoothRepository(db),
-		Energy:       sqlite.NewEnergyRepository(db),
-		Conversation: sqlite.NewConversationRepository(db),
-		MCP:          sqlite.NewMCPRepository(db),
-		Area:         sqlite.NewAreaRepository(db),
-		Controller:   sqlite.NewControllerRepository(db),
-		Screensaver:  sqlite.NewScreensaverRepository(sqlxDB),
+		User:          sqlite.NewUserRepository(db),
+		Config:        sqlite.NewConfigRepository(db),
+		Entity:        sqlite.NewEntityRepository(db),
+		Room:          sqlite.NewRoomRepository(db),
+		Auth:          sqlite.NewAuthRepository(db),
+		Kiosk:         sqlite.NewKioskRepository(db),
+		Network:       sqlite.NewNetworkRepository(db),
+		UPS:           sqlite.NewUPSRepository(db),
+		Camera:        sqlite.NewCameraRepository(db),
+		Display:       sqlite.NewDisplaySettingsRepository(db),
+		Bluetooth:     sqlite.NewBluetoothRepository(db),
+		Energy:        sqlite.NewEnergyRepository(db),
+		Conversation:  sqlite.NewConversationRepository(db),
+		MCP:           sqlite.NewMCPRepository(db),
+		Area:          sqlite.NewAreaRepository(db),
+		Controller:    sqlite.NewControllerRepository(db),
+		Screensaver:   sqlite.NewScreensaverRepository(sqlxDB),
+		Notification:  sqlite.NewNotificationRepository(db),
+		Presence:      sqlite.NewPresenceRepository(db),
+		EntityGroup:   sqlite.NewEntityGroupRepository(db),
+		Helper:        sqlite.NewHelperRepository(db),
+		Customization: sqlite.NewEntityCustomizationRepository(db),
+		Audit:         sqlite.NewAuditRepository(db),
+		Webhook:       sqlite.NewWebhookRepository(db),
+		AIMemory:      sqlite.NewAIMemoryRepository(db),
 	}
 }
@@ -69,6 +69,12 @@ type MCPRepository interface {
 	GetMostUsedTools(ctx context.Context, limit int, days int) ([]*ai.MCPTool, error)
 	GetRecentToolExecutions(ctx context.Context, limit int) ([]*ai.MCPToolExecution, error)
 
+	// External server management
+	SaveServer(ctx context.Context, server *ai.MCPServer) error
+	GetServer(ctx context.Context, id string) (*ai.MCPServer, error)
+	GetServers(ctx context.Context) ([]*ai.MCPServer, error)
+	DeleteServer(ctx context.Context, id string) error
+
 	// Cleanup
 	CleanupOldExecutions(ctx context.Context, days int) error
 }
@@ -57,6 +57,18 @@ type RoomRepository interface {
 	GetRoomsWithEntities(ctx context.Context, areaID *int) ([]models.RoomWithEntities, error)
 	AssignToArea(ctx context.Context, roomID int, areaID *int) error
 	GetUnassignedRooms(ctx context.Context) ([]*models.Room, error)
+
+	// PMA room persistence, rooms are addressed by their room key
+	GetByKey(ctx context.Context, roomKey string) (*models.Room, error)
+	GetEntityAssignments(ctx context.Context) ([]*models.RoomEntityAssignment, error)
+	AssignEntity(ctx context.Context, entityID, roomKey string) error
+	UnassignEntity(ctx context.Context, entityID string) error
+	MoveEntities(ctx context.Context, fromRoomKey, toRoomKey string) error
+	DeleteEntityAssignments(ctx context.Context, roomKey string) error
+
+	// Reconciliation of the legacy entities.room_id column
+	GetOrphanedEntityRooms(ctx context.Context) (map[string]int, error)
+	ClearEntityRoom(ctx context.Context, entityID string) error
 }
 
 // AuthRepository defines authentication data access methods
@@ -117,13 +129,17 @@ type KioskRepository interface {
 	GetDeviceStatus(ctx context.Context, tokenID string) (*models.KioskDeviceStatus, error)
 	GetAllDeviceStatuses(ctx context.Context) ([]*models.KioskDeviceStatus, error)
 	UpdateHeartbeat(ctx context.Context, tokenID string) error
+	SetDeviceStatus(ctx context.Context, tokenID, status string) error
 
 	// Command management
 	CreateCommand(ctx context.Context, command *models.KioskCommand) error
 	GetCommand(ctx context.Context, commandID string) (*models.KioskCommand, error)
 	GetPendingCommands(ctx context.Context, tokenID string) ([]*models.KioskCommand, error)
+	GetCommandsByToken(ctx context.Context, tokenID string, limit int) ([]*models.KioskCommand, error)
 	UpdateCommandStatus(ctx context.Context, commandID, status string) error
 	CompleteCommand(ctx context.Context, commandID string, resultData []byte, errorMsg string) error
+	GetInFlightCommands(ctx context.Context) ([]*models.KioskCommand, error)
+	TimeOutCommand(ctx context.Context, commandID, errorMsg string) (bool, error)
 	CleanupExpiredCommands(ctx context.Context) error
 
 	// Statistics
@@ -172,6 +188,115 @@ type CameraRepository interface {
 	UpdateStatus(ctx context.Context, id int, enabled bool) error
 	UpdateStreamURL(ctx context.Context, id int, streamURL string) error
 	UpdateSnapshotURL(ctx context.Context, id int, snapshotURL string) error
+
+	// Event archive
+	CreateEvent(ctx context.Context, event *models.CameraEvent) error
+	GetEvent(ctx context.Context, id int) (*models.CameraEvent, error)
+	GetEventByExternalID(ctx context.Context, source, externalID string) (*models.CameraEvent, error)
+	ListEvents(ctx context.Context, filter models.CameraEventFilter) ([]*models.CameraEvent, int, error)
+	GetLatestEvent(ctx context.Context, cameraEntityID string) (*models.CameraEvent, error)
+	GetEventCameras(ctx context.Context) ([]string, error)
+	UpdateEventFiles(ctx context.Context, event *models.CameraEvent) error
+	GetExpiredEvents(ctx context.Context, cameraEntityID string, before time.Time, keep int) ([]*models.CameraEvent, error)
+	DeleteEvent(ctx context.Context, id int) error
+}
+
+// NotificationRepository defines notification data access methods
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	GetByID(ctx context.Context, id string) (*models.Notification, error)
+	List(ctx context.Context, filter models.NotificationFilter) ([]*models.Notification, int, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	UpdateDeliveries(ctx context.Context, id string, deliveries []byte) error
+	MarkRead(ctx context.Context, id string, at time.Time) error
+	MarkAllRead(ctx context.Context, userID string, at time.Time) (int, error)
+	Acknowledge(ctx context.Context, id, by string, at time.Time) error
+	SaveResponse(ctx context.Context, id string, response []byte, at time.Time) error
+	Delete(ctx context.Context, id string) error
+	DeleteOlderThan(ctx context.Context, before time.Time) (int, error)
+
+	// Web Push subscriptions
+	SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) error
+	GetPushSubscriptions(ctx context.Context, userID string) ([]*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, endpoint string) error
+}
+
+// PresenceRepository defines presence detection data access methods
+type PresenceRepository interface {
+	CreatePerson(ctx context.Context, person *models.PresencePerson) error
+	GetPerson(ctx context.Context, id string) (*models.PresencePerson, error)
+	ListPersons(ctx context.Context) ([]*models.PresencePerson, error)
+	UpdatePerson(ctx context.Context, person *models.PresencePerson) error
+	DeletePerson(ctx context.Context, id string) error
+
+	// Trackers
+	CreateTracker(ctx context.Context, tracker *models.PresenceTracker) error
+	DeleteTracker(ctx context.Context, personID string, id int) error
+	UpdateTrackerObservation(ctx context.Context, id int, lastSeen *time.Time, lastState string, reportedAt time.Time) error
+
+	// Zones
+	ListZones(ctx context.Context) ([]*models.PresenceZone, error)
+	SaveZone(ctx context.Context, zone *models.PresenceZone) error
+	DeleteZone(ctx context.Context, id string) error
+}
+
+// EntityGroupRepository defines group entity data access methods
+type EntityGroupRepository interface {
+	CreateGroup(ctx context.Context, group *models.EntityGroup) error
+	GetGroup(ctx context.Context, id string) (*models.EntityGroup, error)
+	ListGroups(ctx context.Context) ([]*models.EntityGroup, error)
+	UpdateGroup(ctx context.Context, group *models.EntityGroup) error
+	DeleteGroup(ctx context.Context, id string) error
+}
+
+// HelperRepository defines helper entity data access methods
+type HelperRepository interface {
+	CreateHelper(ctx context.Context, helper *models.Helper) error
+	GetHelper(ctx context.Context, id string) (*models.Helper, error)
+	ListHelpers(ctx context.Context) ([]*models.Helper, error)
+	UpdateHelper(ctx context.Context, helper *models.Helper) error
+	DeleteHelper(ctx context.Context, id string) error
+	SaveHelperState(ctx context.Context, helper *models.Helper) error
+}
+
+// EntityCustomizationRepository defines entity customization data access methods
+type EntityCustomizationRepository interface {
+	ListCustomizations(ctx context.Context) ([]*models.EntityCustomization, error)
+	GetCustomization(ctx context.Context, entityID string) (*models.EntityCustomization, error)
+	SaveCustomizations(ctx context.Context, customizations []*models.EntityCustomization) error
+	DeleteCustomization(ctx context.Context, entityID string) error
+}
+
+// AuditRepository defines audit log data access methods. The log is append-only.
+type AuditRepository interface {
+	CreateEntry(ctx context.Context, entry *models.AuditEntry) error
+	GetLastEntry(ctx context.Context) (*models.AuditEntry, error)
+	ListEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error)
+	WalkEntries(ctx context.Context, filter models.AuditFilter, fn func(*models.AuditEntry) error) error
+}
+
+// WebhookRepository defines outbound webhook and delivery log data access methods
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhook(ctx context.Context, id int64) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	SaveWebhookStatus(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*models.WebhookDelivery, int, error)
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AIMemoryRepository defines long-term AI memory data access methods
+type AIMemoryRepository interface {
+	CreateMemory(ctx context.Context, memory *models.AIMemory) error
+	GetMemory(ctx context.Context, id int64) (*models.AIMemory, error)
+	ListMemories(ctx context.Context, filter models.AIMemoryFilter) ([]*models.AIMemory, int, error)
+	GetUserMemories(ctx context.Context, userID string) ([]*models.AIMemory, error)
+	UpdateMemoryEmbedding(ctx context.Context, id int64, embedding []float32, model string) error
+	RecordRecalls(ctx context.Context, ids []int64, at time.Time) error
+	DeleteMemory(ctx context.Context, id int64) error
 }
 
 // DisplayRepository defines display settings data access methods
@@ -301,4 +426,10 @@ type ControllerRepository interface {
 	// Import/Export
 	ExportDashboard(ctx context.Context, id int) (map[string]interface{}, error)
 	ImportDashboard(ctx context.Context, data map[string]interface{}, userID *int) (*models.ControllerDashboard, error)
+
+	// Revision history
+	CreateRevision(ctx context.Context, revision *models.ControllerDashboardRevision) error
+	GetRevisions(ctx context.Context, dashboardID int, limit int) ([]*models.ControllerDashboardRevision, error)
+	GetRevision(ctx context.Context, dashboardID int, revision int) (*models.ControllerDashboardRevision, error)
+	PruneRevisions(ctx context.Context, dashboardID int, keep int) error
 }
@@ -0,0 +1,238 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const aiMemoryColumns = `id, user_id, content, source, conversation_id, embedding, embedding_model, recall_count,
+	last_recalled_at, created_at, updated_at`
+
+// AIMemoryRepository implements repositories.AIMemoryRepository
+type AIMemoryRepository struct {
+	db *sql.DB
+}
+
+// NewAIMemoryRepository creates a new AIMemoryRepository
+func NewAIMemoryRepository(db *sql.DB) repositories.AIMemoryRepository {
+	return &AIMemoryRepository{db: db}
+}
+
+// CreateMemory stores a new memory and sets its ID
+func (r *AIMemoryRepository) CreateMemory(ctx context.Context, memory *models.AIMemory) error {
+	query := `
+		INSERT INTO ai_memories (user_id, content, source, conversation_id, embedding, embedding_model, recall_count,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, query,
+		memory.UserID,
+		memory.Content,
+		memory.Source,
+		memory.ConversationID,
+		encodeEmbedding(memory.Embedding),
+		memory.EmbeddingModel,
+		memory.RecallCount,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create memory: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get memory ID: %w", err)
+	}
+	memory.ID = id
+	memory.CreatedAt = now
+	memory.UpdatedAt = now
+	return nil
+}
+
+// GetMemory retrieves a memory by ID
+func (r *AIMemoryRepository) GetMemory(ctx context.Context, id int64) (*models.AIMemory, error) {
+	query := `SELECT ` + aiMemoryColumns + ` FROM ai_memories WHERE id = ?`
+
+	memory, err := scanAIMemory(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("memory %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	return memory, nil
+}
+
+// ListMemories returns the memories visible to a user, newest first, and their total count
+func (r *AIMemoryRepository) ListMemories(ctx context.Context, filter models.AIMemoryFilter) ([]*models.AIMemory, int, error) {
+	where := ` WHERE (user_id = ? OR user_id = '')`
+	args := []interface{}{filter.UserID}
+	if filter.Source != "" {
+		where += ` AND source = ?`
+		args = append(args, filter.Source)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ai_memories`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count memories: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `SELECT ` + aiMemoryColumns + ` FROM ai_memories` + where + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	memories, err := r.queryMemories(ctx, query, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return memories, total, nil
+}
+
+// GetUserMemories returns every memory visible to a user, oldest first
+func (r *AIMemoryRepository) GetUserMemories(ctx context.Context, userID string) ([]*models.AIMemory, error) {
+	query := `SELECT ` + aiMemoryColumns + ` FROM ai_memories WHERE user_id = ? OR user_id = '' ORDER BY id`
+	return r.queryMemories(ctx, query, userID)
+}
+
+// UpdateMemoryEmbedding stores the embedding of a memory and the model it was made with
+func (r *AIMemoryRepository) UpdateMemoryEmbedding(ctx context.Context, id int64, embedding []float32, model string) error {
+	query := `UPDATE ai_memories SET embedding = ?, embedding_model = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, encodeEmbedding(embedding), model, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update memory embedding: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("memory %d not found", id)
+	}
+
+	return nil
+}
+
+// RecordRecalls counts a recall of each memory
+func (r *AIMemoryRepository) RecordRecalls(ctx context.Context, ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{at.UTC()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	query := `UPDATE ai_memories SET recall_count = recall_count + 1, last_recalled_at = ? WHERE id IN (` + placeholders + `)`
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record memory recalls: %w", err)
+	}
+	return nil
+}
+
+// DeleteMemory deletes a memory
+func (r *AIMemoryRepository) DeleteMemory(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM ai_memories WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete memory: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("memory %d not found", id)
+	}
+
+	return nil
+}
+
+func (r *AIMemoryRepository) queryMemories(ctx context.Context, query string, args ...interface{}) ([]*models.AIMemory, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	defer rows.Close()
+
+	memories := []*models.AIMemory{}
+	for rows.Next() {
+		memory, err := scanAIMemory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan memory: %w", err)
+		}
+		memories = append(memories, memory)
+	}
+
+	return memories, rows.Err()
+}
+
+func scanAIMemory(row notificationScanner) (*models.AIMemory, error) {
+	var memory models.AIMemory
+	var conversationID, embeddingModel sql.NullString
+	var embedding []byte
+	var lastRecalled sql.NullTime
+
+	err := row.Scan(
+		&memory.ID,
+		&memory.UserID,
+		&memory.Content,
+		&memory.Source,
+		&conversationID,
+		&embedding,
+		&embeddingModel,
+		&memory.RecallCount,
+		&lastRecalled,
+		&memory.CreatedAt,
+		&memory.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	memory.ConversationID = conversationID.String
+	memory.Embedding = decodeEmbedding(embedding)
+	memory.EmbeddingModel = embeddingModel.String
+	memory.LastRecalledAt = nullTimePtr(lastRecalled)
+	return &memory, nil
+}
+
+// encodeEmbedding stores a vector as little-endian float32 values
+func encodeEmbedding(embedding []float32) []byte {
+	if len(embedding) == 0 {
+		return nil
+	}
+	buf := make([]byte, 4*len(embedding))
+	for i, value := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(value))
+	}
+	return buf
+}
+
+func decodeEmbedding(buf []byte) []float32 {
+	if len(buf) < 4 {
+		return nil
+	}
+	embedding := make([]float32, len(buf)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return embedding
+}
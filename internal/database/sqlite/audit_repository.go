@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const auditColumns = `id, timestamp, actor_type, actor_id, actor_name, category, action, target, outcome, error,
+	details, source_ip, prev_hash, hash`
+
+// auditTimeFormat has a fixed width so that stored timestamps sort and compare as text
+const auditTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// auditWalkBatch is the number of rows read per query while walking the log
+const auditWalkBatch = 500
+
+// AuditRepository implements repositories.AuditRepository
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *sql.DB) repositories.AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// CreateEntry appends an entry to the log and sets its ID
+func (r *AuditRepository) CreateEntry(ctx context.Context, entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (timestamp, actor_type, actor_id, actor_name, category, action, target, outcome,
+			error, details, source_ip, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		entry.Timestamp.UTC().Format(auditTimeFormat),
+		entry.ActorType,
+		entry.ActorID,
+		entry.ActorName,
+		entry.Category,
+		entry.Action,
+		entry.Target,
+		entry.Outcome,
+		entry.Error,
+		string(entry.Details),
+		entry.SourceIP,
+		entry.PrevHash,
+		entry.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get audit entry ID: %w", err)
+	}
+	entry.ID = id
+	return nil
+}
+
+// GetLastEntry returns the newest entry, or nil when the log is empty
+func (r *AuditRepository) GetLastEntry(ctx context.Context) (*models.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log ORDER BY id DESC LIMIT 1`
+
+	entry, err := scanAuditEntry(r.db.QueryRowContext(ctx, query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last audit entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ListEntries returns entries matching the filter, newest first, together with the total match count
+func (r *AuditRepository) ListEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	where, args := auditFilterClause(filter)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log` + where + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// WalkEntries calls fn for every entry matching the filter, oldest first. Limit and offset are
+// ignored. Rows are read in batches so the log is not locked while fn runs.
+func (r *AuditRepository) WalkEntries(ctx context.Context, filter models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	where, args := auditFilterClause(filter)
+	if where == "" {
+		where = " WHERE id > ?"
+	} else {
+		where += " AND id > ?"
+	}
+	query := `SELECT ` + auditColumns + ` FROM audit_log` + where + ` ORDER BY id LIMIT ?`
+
+	var lastID int64
+	for {
+		rows, err := r.db.QueryContext(ctx, query, append(args, lastID, auditWalkBatch)...)
+		if err != nil {
+			return fmt.Errorf("failed to read audit entries: %w", err)
+		}
+
+		var batch []*models.AuditEntry
+		for rows.Next() {
+			entry, err := scanAuditEntry(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan audit entry: %w", err)
+			}
+			batch = append(batch, entry)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read audit entries: %w", err)
+		}
+
+		for _, entry := range batch {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(batch) < auditWalkBatch {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+func auditFilterClause(filter models.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, field := range []struct {
+		column string
+		value  string
+	}{
+		{"actor_type", filter.ActorType},
+		{"actor_id", filter.ActorID},
+		{"category", filter.Category},
+		{"action", filter.Action},
+		{"target", filter.Target},
+		{"outcome", filter.Outcome},
+	} {
+		if field.value != "" {
+			conditions = append(conditions, field.column+" = ?")
+			args = append(args, field.value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, filter.Since.UTC().Format(auditTimeFormat))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, filter.Until.UTC().Format(auditTimeFormat))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanAuditEntry(row notificationScanner) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var timestamp string
+	var actorID, actorName, target, errMsg, details, sourceIP sql.NullString
+
+	err := row.Scan(
+		&entry.ID,
+		&timestamp,
+		&entry.ActorType,
+		&actorID,
+		&actorName,
+		&entry.Category,
+		&entry.Action,
+		&target,
+		&entry.Outcome,
+		&errMsg,
+		&details,
+		&sourceIP,
+		&entry.PrevHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Timestamp, err = time.Parse(auditTimeFormat, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit timestamp %q: %w", timestamp, err)
+	}
+	entry.ActorID = actorID.String
+	entry.ActorName = actorName.String
+	entry.Target = target.String
+	entry.Error = errMsg.String
+	if details.String != "" {
+		entry.Details = json.RawMessage(details.String)
+	}
+	entry.SourceIP = sourceIP.String
+	return &entry, nil
+}
@@ -0,0 +1,304 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+)
+
+const cameraEventColumns = `id, camera_entity_id, event_type, source, trigger_entity_id, external_id,
+	snapshot_file_id, thumbnail_file_id, clip_file_id, clip_status, metadata, occurred_at, created_at`
+
+// CreateEvent stores a new camera event
+func (r *CameraRepository) CreateEvent(ctx context.Context, event *models.CameraEvent) error {
+	query := `
+		INSERT INTO camera_events (camera_entity_id, event_type, source, trigger_entity_id, external_id,
+			snapshot_file_id, thumbnail_file_id, clip_file_id, clip_status, metadata, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.ClipStatus == "" {
+		event.ClipStatus = "none"
+	}
+	metadata := string(event.Metadata)
+	if metadata == "" {
+		metadata = "{}"
+	}
+	event.CreatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx, query,
+		event.CameraEntityID,
+		event.EventType,
+		event.Source,
+		event.TriggerEntityID,
+		event.ExternalID,
+		event.SnapshotFileID,
+		event.ThumbnailFileID,
+		event.ClipFileID,
+		event.ClipStatus,
+		metadata,
+		event.OccurredAt.UTC(),
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create camera event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	event.ID = int(id)
+	return nil
+}
+
+// GetEvent retrieves a camera event by ID
+func (r *CameraRepository) GetEvent(ctx context.Context, id int) (*models.CameraEvent, error) {
+	query := `SELECT ` + cameraEventColumns + ` FROM camera_events WHERE id = ?`
+
+	event, err := scanCameraEvent(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("camera event with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get camera event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetEventByExternalID retrieves an event by the ID its source reported, or nil if it was not recorded yet
+func (r *CameraRepository) GetEventByExternalID(ctx context.Context, source, externalID string) (*models.CameraEvent, error) {
+	query := `SELECT ` + cameraEventColumns + ` FROM camera_events WHERE source = ? AND external_id = ? LIMIT 1`
+
+	event, err := scanCameraEvent(r.db.QueryRowContext(ctx, query, source, externalID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get camera event by external ID: %w", err)
+	}
+
+	return event, nil
+}
+
+// ListEvents returns events matching the filter, newest first, together with the total match count
+func (r *CameraRepository) ListEvents(ctx context.Context, filter models.CameraEventFilter) ([]*models.CameraEvent, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.CameraEntityID != "" {
+		conditions = append(conditions, "camera_entity_id = ?")
+		args = append(args, filter.CameraEntityID)
+	}
+	if filter.EventType != "" {
+		conditions = append(conditions, "event_type = ?")
+		args = append(args, filter.EventType)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "occurred_at <= ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM camera_events`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count camera events: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `SELECT ` + cameraEventColumns + ` FROM camera_events` + where + ` ORDER BY occurred_at DESC, id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list camera events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanCameraEvents(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// GetLatestEvent returns the most recent event of a camera that has a snapshot, or nil if there is none
+func (r *CameraRepository) GetLatestEvent(ctx context.Context, cameraEntityID string) (*models.CameraEvent, error) {
+	query := `
+		SELECT ` + cameraEventColumns + `
+		FROM camera_events
+		WHERE camera_entity_id = ? AND snapshot_file_id != ''
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT 1
+	`
+
+	event, err := scanCameraEvent(r.db.QueryRowContext(ctx, query, cameraEntityID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest camera event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetEventCameras returns the entity IDs of all cameras that have archived events
+func (r *CameraRepository) GetEventCameras(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT camera_entity_id FROM camera_events`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event cameras: %w", err)
+	}
+	defer rows.Close()
+
+	var cameras []string
+	for rows.Next() {
+		var entityID string
+		if err := rows.Scan(&entityID); err != nil {
+			return nil, fmt.Errorf("failed to scan camera entity ID: %w", err)
+		}
+		cameras = append(cameras, entityID)
+	}
+
+	return cameras, rows.Err()
+}
+
+// UpdateEventFiles updates the file references and clip status of an event
+func (r *CameraRepository) UpdateEventFiles(ctx context.Context, event *models.CameraEvent) error {
+	query := `
+		UPDATE camera_events
+		SET snapshot_file_id = ?, thumbnail_file_id = ?, clip_file_id = ?, clip_status = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		event.SnapshotFileID,
+		event.ThumbnailFileID,
+		event.ClipFileID,
+		event.ClipStatus,
+		event.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update camera event files: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("camera event with ID %d not found", event.ID)
+	}
+
+	return nil
+}
+
+// GetExpiredEvents returns events of a camera that occurred before the cutoff or fall outside
+// the newest keep events. A zero cutoff or non-positive keep disables that rule.
+func (r *CameraRepository) GetExpiredEvents(ctx context.Context, cameraEntityID string, before time.Time, keep int) ([]*models.CameraEvent, error) {
+	var rules []string
+	args := []interface{}{cameraEntityID}
+
+	if !before.IsZero() {
+		rules = append(rules, "occurred_at < ?")
+		args = append(args, before.UTC())
+	}
+	if keep > 0 {
+		rules = append(rules, `id NOT IN (
+			SELECT id FROM camera_events WHERE camera_entity_id = ? ORDER BY occurred_at DESC, id DESC LIMIT ?
+		)`)
+		args = append(args, cameraEntityID, keep)
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + cameraEventColumns + ` FROM camera_events WHERE camera_entity_id = ? AND (` +
+		strings.Join(rules, " OR ") + `) ORDER BY occurred_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired camera events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameraEvents(rows)
+}
+
+// DeleteEvent deletes a camera event record
+func (r *CameraRepository) DeleteEvent(ctx context.Context, id int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM camera_events WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete camera event: %w", err)
+	}
+	return nil
+}
+
+type cameraEventScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCameraEvent(row cameraEventScanner) (*models.CameraEvent, error) {
+	var event models.CameraEvent
+	var metadata string
+
+	err := row.Scan(
+		&event.ID,
+		&event.CameraEntityID,
+		&event.EventType,
+		&event.Source,
+		&event.TriggerEntityID,
+		&event.ExternalID,
+		&event.SnapshotFileID,
+		&event.ThumbnailFileID,
+		&event.ClipFileID,
+		&event.ClipStatus,
+		&metadata,
+		&event.OccurredAt,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Metadata = []byte(metadata)
+	return &event, nil
+}
+
+func scanCameraEvents(rows *sql.Rows) ([]*models.CameraEvent, error) {
+	var events []*models.CameraEvent
+	for rows.Next() {
+		event, err := scanCameraEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan camera event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate camera events: %w", err)
+	}
+
+	return events, nil
+}
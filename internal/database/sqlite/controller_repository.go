@@ -34,8 +34,8 @@ func (r *ControllerRepository) CreateDashboard(ctx context.Context, dashboard *m
 		INSERT INTO controller_dashboards (
 			name, description, category, layout_config, elements_json, 
 			style_config, access_config, is_favorite, tags, 
-			thumbnail_url, version, user_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			thumbnail_url, version, schema_version, user_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -50,6 +50,7 @@ func (r *ControllerRepository) CreateDashboard(ctx context.Context, dashboard *m
 		dashboard.Tags,
 		dashboard.ThumbnailURL,
 		dashboard.Version,
+		dashboard.SchemaVersion,
 		dashboard.UserID,
 	)
 
@@ -73,7 +74,7 @@ func (r *ControllerRepository) GetDashboardByID(ctx context.Context, id int) (*m
 	query := `
 		SELECT id, name, description, category, layout_config, elements_json,
 			   style_config, access_config, is_favorite, tags, thumbnail_url,
-			   version, user_id, created_at, updated_at, last_accessed
+			   version, schema_version, user_id, created_at, updated_at, last_accessed
 		FROM controller_dashboards
 		WHERE id = ?
 	`
@@ -92,6 +93,7 @@ func (r *ControllerRepository) GetDashboardByID(ctx context.Context, id int) (*m
 		&dashboard.Tags,
 		&dashboard.ThumbnailURL,
 		&dashboard.Version,
+		&dashboard.SchemaVersion,
 		&dashboard.UserID,
 		&dashboard.CreatedAt,
 		&dashboard.UpdatedAt,
@@ -116,7 +118,7 @@ func (r *ControllerRepository) GetDashboardsByUserID(ctx context.Context, userID
 		query = `
 			SELECT DISTINCT d.id, d.name, d.description, d.category, d.layout_config, 
 				   d.elements_json, d.style_config, d.access_config, d.is_favorite, 
-				   d.tags, d.thumbnail_url, d.version, d.user_id, d.created_at, 
+				   d.tags, d.thumbnail_url, d.version, d.schema_version, d.user_id, d.created_at, 
 				   d.updated_at, d.last_accessed
 			FROM controller_dashboards d
 			LEFT JOIN controller_shares s ON d.id = s.dashboard_id
@@ -128,7 +130,7 @@ func (r *ControllerRepository) GetDashboardsByUserID(ctx context.Context, userID
 		query = `
 			SELECT id, name, description, category, layout_config, elements_json,
 				   style_config, access_config, is_favorite, tags, thumbnail_url,
-				   version, user_id, created_at, updated_at, last_accessed
+				   version, schema_version, user_id, created_at, updated_at, last_accessed
 			FROM controller_dashboards
 			WHERE user_id = ?
 			ORDER BY updated_at DESC
@@ -150,7 +152,7 @@ func (r *ControllerRepository) GetAllDashboards(ctx context.Context, userID *int
 	query := `
 			SELECT id, name, description, category, layout_config, elements_json,
 				   style_config, access_config, is_favorite, tags, thumbnail_url,
-				   version, user_id, created_at, updated_at, last_accessed
+				   version, schema_version, user_id, created_at, updated_at, last_accessed
 			FROM controller_dashboards
 			ORDER BY updated_at DESC
 		`
@@ -169,7 +171,8 @@ func (r *ControllerRepository) UpdateDashboard(ctx context.Context, dashboard *m
 		UPDATE controller_dashboards 
 		SET name = ?, description = ?, category = ?, layout_config = ?, 
 			elements_json = ?, style_config = ?, access_config = ?, 
-			is_favorite = ?, tags = ?, thumbnail_url = ?, version = ?
+			is_favorite = ?, tags = ?, thumbnail_url = ?, version = ?,
+			schema_version = ?
 		WHERE id = ?
 	`
 
@@ -185,6 +188,7 @@ func (r *ControllerRepository) UpdateDashboard(ctx context.Context, dashboard *m
 		dashboard.Tags,
 		dashboard.ThumbnailURL,
 		dashboard.Version,
+		dashboard.SchemaVersion,
 		dashboard.ID,
 	)
 
@@ -216,18 +220,19 @@ func (r *ControllerRepository) DuplicateDashboard(ctx context.Context, id int, u
 
 	// Create new dashboard with modified properties
 	duplicate := &models.ControllerDashboard{
-		Name:         newName,
-		Description:  original.Description + " (Copy)",
-		Category:     original.Category,
-		LayoutConfig: original.LayoutConfig,
-		ElementsJSON: original.ElementsJSON,
-		StyleConfig:  original.StyleConfig,
-		AccessConfig: original.AccessConfig,
-		IsFavorite:   false,
-		Tags:         original.Tags,
-		ThumbnailURL: original.ThumbnailURL,
-		Version:      1,
-		UserID:       userID,
+		Name:          newName,
+		Description:   original.Description + " (Copy)",
+		Category:      original.Category,
+		LayoutConfig:  original.LayoutConfig,
+		ElementsJSON:  original.ElementsJSON,
+		StyleConfig:   original.StyleConfig,
+		AccessConfig:  original.AccessConfig,
+		IsFavorite:    false,
+		Tags:          original.Tags,
+		ThumbnailURL:  original.ThumbnailURL,
+		Version:       1,
+		SchemaVersion: original.SchemaVersion,
+		UserID:        userID,
 	}
 
 	err = r.CreateDashboard(ctx, duplicate)
@@ -247,7 +252,7 @@ func (r *ControllerRepository) SearchDashboards(ctx context.Context, userID *int
 	sqlQuery.WriteString(`
 		SELECT DISTINCT d.id, d.name, d.description, d.category, d.layout_config, 
 			   d.elements_json, d.style_config, d.access_config, d.is_favorite, 
-			   d.tags, d.thumbnail_url, d.version, d.user_id, d.created_at, 
+			   d.tags, d.thumbnail_url, d.version, d.schema_version, d.user_id, d.created_at, 
 			   d.updated_at, d.last_accessed
 		FROM controller_dashboards d
 		LEFT JOIN controller_shares s ON d.id = s.dashboard_id
@@ -304,7 +309,7 @@ func (r *ControllerRepository) GetFavoriteDashboards(ctx context.Context, userID
 	query := `
 		SELECT id, name, description, category, layout_config, elements_json,
 			   style_config, access_config, is_favorite, tags, thumbnail_url,
-			   version, user_id, created_at, updated_at, last_accessed
+			   version, schema_version, user_id, created_at, updated_at, last_accessed
 		FROM controller_dashboards
 		WHERE user_id = ? AND is_favorite = true
 		ORDER BY updated_at DESC
@@ -832,14 +837,15 @@ func (r *ControllerRepository) ExportDashboard(ctx context.Context, id int) (map
 		"version":     "1.0",
 		"exported_at": time.Now().UTC(),
 		"dashboard": map[string]interface{}{
-			"name":        dashboard.Name,
-			"description": dashboard.Description,
-			"category":    dashboard.Category,
-			"layout":      json.RawMessage(dashboard.LayoutConfig),
-			"elements":    json.RawMessage(dashboard.ElementsJSON),
-			"style":       json.RawMessage(dashboard.StyleConfig),
-			"access":      json.RawMessage(dashboard.AccessConfig),
-			"tags":        json.RawMessage(dashboard.Tags),
+			"name":           dashboard.Name,
+			"description":    dashboard.Description,
+			"category":       dashboard.Category,
+			"layout":         json.RawMessage(dashboard.LayoutConfig),
+			"elements":       json.RawMessage(dashboard.ElementsJSON),
+			"style":          json.RawMessage(dashboard.StyleConfig),
+			"access":         json.RawMessage(dashboard.AccessConfig),
+			"tags":           json.RawMessage(dashboard.Tags),
+			"schema_version": dashboard.SchemaVersion,
 		},
 	}
 
@@ -873,6 +879,12 @@ func (r *ControllerRepository) ImportDashboard(ctx context.Context, data map[str
 		UserID:       userID,
 	}
 
+	if schemaVersion, ok := dashboardData["schema_version"].(float64); ok {
+		dashboard.SchemaVersion = int(schemaVersion)
+	} else if schemaVersion, ok := dashboardData["schema_version"].(int); ok {
+		dashboard.SchemaVersion = schemaVersion
+	}
+
 	err := r.CreateDashboard(ctx, dashboard)
 	if err != nil {
 		return nil, fmt.Errorf("failed to import dashboard: %w", err)
@@ -880,3 +892,108 @@ func (r *ControllerRepository) ImportDashboard(ctx context.Context, data map[str
 
 	return dashboard, nil
 }
+
+// Revision history
+
+func (r *ControllerRepository) CreateRevision(ctx context.Context, revision *models.ControllerDashboardRevision) error {
+	query := `
+		INSERT INTO controller_dashboard_revisions (
+			dashboard_id, revision, schema_version, name, description, category,
+			layout_config, elements_json, style_config, access_config, tags,
+			change_summary, user_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		revision.DashboardID,
+		revision.Revision,
+		revision.SchemaVersion,
+		revision.Name,
+		revision.Description,
+		revision.Category,
+		revision.LayoutConfig,
+		revision.ElementsJSON,
+		revision.StyleConfig,
+		revision.AccessConfig,
+		revision.Tags,
+		revision.ChangeSummary,
+		revision.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create dashboard revision: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get revision ID: %w", err)
+	}
+
+	revision.ID = int(id)
+	revision.CreatedAt = time.Now()
+
+	return nil
+}
+
+func (r *ControllerRepository) GetRevisions(ctx context.Context, dashboardID int, limit int) ([]*models.ControllerDashboardRevision, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, dashboard_id, revision, schema_version, name, description, category,
+			   layout_config, elements_json, style_config, access_config, tags,
+			   change_summary, user_id, created_at
+		FROM controller_dashboard_revisions
+		WHERE dashboard_id = ?
+		ORDER BY revision DESC
+		LIMIT ?
+	`
+
+	revisions := []*models.ControllerDashboardRevision{}
+	err := r.dbx.SelectContext(ctx, &revisions, query, dashboardID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (r *ControllerRepository) GetRevision(ctx context.Context, dashboardID int, revision int) (*models.ControllerDashboardRevision, error) {
+	query := `
+		SELECT id, dashboard_id, revision, schema_version, name, description, category,
+			   layout_config, elements_json, style_config, access_config, tags,
+			   change_summary, user_id, created_at
+		FROM controller_dashboard_revisions
+		WHERE dashboard_id = ? AND revision = ?
+	`
+
+	rev := &models.ControllerDashboardRevision{}
+	err := r.dbx.GetContext(ctx, rev, query, dashboardID, revision)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("revision not found: %d", revision)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+func (r *ControllerRepository) PruneRevisions(ctx context.Context, dashboardID int, keep int) error {
+	query := `
+		DELETE FROM controller_dashboard_revisions
+		WHERE dashboard_id = ? AND revision NOT IN (
+			SELECT revision FROM controller_dashboard_revisions
+			WHERE dashboard_id = ?
+			ORDER BY revision DESC
+			LIMIT ?
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, dashboardID, dashboardID, keep)
+	if err != nil {
+		return fmt.Errorf("failed to prune dashboard revisions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const entityCustomizationColumns = `entity_id, friendly_name, icon, entity_type, unit, device_class, hidden, disabled,
+	created_at, updated_at`
+
+// EntityCustomizationRepository implements repositories.EntityCustomizationRepository
+type EntityCustomizationRepository struct {
+	db *sql.DB
+}
+
+// NewEntityCustomizationRepository creates a new EntityCustomizationRepository
+func NewEntityCustomizationRepository(db *sql.DB) repositories.EntityCustomizationRepository {
+	return &EntityCustomizationRepository{db: db}
+}
+
+// ListCustomizations returns all entity customizations
+func (r *EntityCustomizationRepository) ListCustomizations(ctx context.Context) ([]*models.EntityCustomization, error) {
+	query := `SELECT ` + entityCustomizationColumns + ` FROM entity_customizations ORDER BY entity_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customizations: %w", err)
+	}
+	defer rows.Close()
+
+	var customizations []*models.EntityCustomization
+	for rows.Next() {
+		customization, err := scanEntityCustomization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan customization: %w", err)
+		}
+		customizations = append(customizations, customization)
+	}
+
+	return customizations, rows.Err()
+}
+
+// GetCustomization retrieves the customization of an entity
+func (r *EntityCustomizationRepository) GetCustomization(ctx context.Context, entityID string) (*models.EntityCustomization, error) {
+	query := `SELECT ` + entityCustomizationColumns + ` FROM entity_customizations WHERE entity_id = ?`
+
+	customization, err := scanEntityCustomization(r.db.QueryRowContext(ctx, query, entityID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("customization for %s not found", entityID)
+		}
+		return nil, fmt.Errorf("failed to get customization: %w", err)
+	}
+
+	return customization, nil
+}
+
+// SaveCustomizations creates or replaces customizations in one transaction
+func (r *EntityCustomizationRepository) SaveCustomizations(ctx context.Context, customizations []*models.EntityCustomization) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO entity_customizations (` + entityCustomizationColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(entity_id) DO UPDATE SET
+			friendly_name = excluded.friendly_name, icon = excluded.icon, entity_type = excluded.entity_type,
+			unit = excluded.unit, device_class = excluded.device_class, hidden = excluded.hidden,
+			disabled = excluded.disabled, updated_at = excluded.updated_at
+	`
+
+	now := time.Now().UTC()
+	for _, c := range customizations {
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = now
+		}
+		c.UpdatedAt = now
+
+		_, err := tx.ExecContext(ctx, query,
+			c.EntityID,
+			c.FriendlyName,
+			c.Icon,
+			c.EntityType,
+			c.Unit,
+			c.DeviceClass,
+			c.Hidden,
+			c.Disabled,
+			c.CreatedAt,
+			c.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save customization for %s: %w", c.EntityID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit customizations: %w", err)
+	}
+	return nil
+}
+
+// DeleteCustomization removes the customization of an entity
+func (r *EntityCustomizationRepository) DeleteCustomization(ctx context.Context, entityID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM entity_customizations WHERE entity_id = ?`, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete customization: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("customization for %s not found", entityID)
+	}
+
+	return nil
+}
+
+func scanEntityCustomization(row notificationScanner) (*models.EntityCustomization, error) {
+	var c models.EntityCustomization
+	var friendlyName, icon, entityType, unit, deviceClass sql.NullString
+
+	err := row.Scan(
+		&c.EntityID,
+		&friendlyName,
+		&icon,
+		&entityType,
+		&unit,
+		&deviceClass,
+		&c.Hidden,
+		&c.Disabled,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.FriendlyName = friendlyName.String
+	c.Icon = icon.String
+	c.EntityType = entityType.String
+	c.Unit = unit.String
+	c.DeviceClass = deviceClass.String
+	return &c, nil
+}
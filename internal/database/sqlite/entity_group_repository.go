@@ -0,0 +1,251 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const entityGroupColumns = `id, name, entity_type, mode, aggregate, unit, icon, room_id, created_at, updated_at`
+
+// EntityGroupRepository implements repositories.EntityGroupRepository
+type EntityGroupRepository struct {
+	db *sql.DB
+}
+
+// NewEntityGroupRepository creates a new EntityGroupRepository
+func NewEntityGroupRepository(db *sql.DB) repositories.EntityGroupRepository {
+	return &EntityGroupRepository{db: db}
+}
+
+// CreateGroup stores a new group together with its members
+func (r *EntityGroupRepository) CreateGroup(ctx context.Context, group *models.EntityGroup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO entity_groups (` + entityGroupColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	_, err = tx.ExecContext(ctx, query,
+		group.ID,
+		group.Name,
+		group.EntityType,
+		group.Mode,
+		group.Aggregate,
+		group.Unit,
+		group.Icon,
+		group.RoomID,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	if err := insertGroupMembers(ctx, tx, group); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group: %w", err)
+	}
+
+	group.CreatedAt = now
+	group.UpdatedAt = now
+	return nil
+}
+
+// GetGroup retrieves a group together with its members
+func (r *EntityGroupRepository) GetGroup(ctx context.Context, id string) (*models.EntityGroup, error) {
+	query := `SELECT ` + entityGroupColumns + ` FROM entity_groups WHERE id = ?`
+
+	group, err := scanEntityGroup(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("group %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	members, err := r.getMembers(ctx, `WHERE group_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	group.Members = members[group.ID]
+	if group.Members == nil {
+		group.Members = []string{}
+	}
+
+	return group, nil
+}
+
+// ListGroups returns all groups together with their members
+func (r *EntityGroupRepository) ListGroups(ctx context.Context) ([]*models.EntityGroup, error) {
+	query := `SELECT ` + entityGroupColumns + ` FROM entity_groups ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.EntityGroup
+	for rows.Next() {
+		group, err := scanEntityGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate groups: %w", err)
+	}
+
+	members, err := r.getMembers(ctx, ``)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		group.Members = members[group.ID]
+		if group.Members == nil {
+			group.Members = []string{}
+		}
+	}
+
+	return groups, nil
+}
+
+// UpdateGroup updates the settings of a group and replaces its members
+func (r *EntityGroupRepository) UpdateGroup(ctx context.Context, group *models.EntityGroup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE entity_groups
+		SET name = ?, entity_type = ?, mode = ?, aggregate = ?, unit = ?, icon = ?, room_id = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, query,
+		group.Name, group.EntityType, group.Mode, group.Aggregate, group.Unit, group.Icon, group.RoomID, now, group.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("group %s not found", group.ID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entity_group_members WHERE group_id = ?`, group.ID); err != nil {
+		return fmt.Errorf("failed to clear group members: %w", err)
+	}
+	if err := insertGroupMembers(ctx, tx, group); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group: %w", err)
+	}
+
+	group.UpdatedAt = now
+	return nil
+}
+
+// DeleteGroup deletes a group and its members
+func (r *EntityGroupRepository) DeleteGroup(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM entity_group_members WHERE group_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete group members: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM entity_groups WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("group %s not found", id)
+	}
+
+	return nil
+}
+
+// getMembers returns the members of the matching groups keyed by group ID, in their stored order
+func (r *EntityGroupRepository) getMembers(ctx context.Context, where string, args ...interface{}) (map[string][]string, error) {
+	query := `SELECT group_id, entity_id FROM entity_group_members ` + where + ` ORDER BY group_id, position`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make(map[string][]string)
+	for rows.Next() {
+		var groupID, entityID string
+		if err := rows.Scan(&groupID, &entityID); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members[groupID] = append(members[groupID], entityID)
+	}
+
+	return members, rows.Err()
+}
+
+func insertGroupMembers(ctx context.Context, tx *sql.Tx, group *models.EntityGroup) error {
+	for i, entityID := range group.Members {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO entity_group_members (group_id, entity_id, position) VALUES (?, ?, ?)`,
+			group.ID, entityID, i,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add group member %s: %w", entityID, err)
+		}
+	}
+	return nil
+}
+
+func scanEntityGroup(row notificationScanner) (*models.EntityGroup, error) {
+	var group models.EntityGroup
+	var aggregate, unit, icon, roomID sql.NullString
+
+	err := row.Scan(
+		&group.ID,
+		&group.Name,
+		&group.EntityType,
+		&group.Mode,
+		&aggregate,
+		&unit,
+		&icon,
+		&roomID,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Aggregate = aggregate.String
+	group.Unit = unit.String
+	group.Icon = icon.String
+	group.RoomID = roomID.String
+	return &group, nil
+}
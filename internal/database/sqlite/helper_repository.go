@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const helperColumns = `id, type, name, icon, config, state, timer_duration, timer_remaining, timer_finishes_at,
+	created_at, updated_at`
+
+// HelperRepository implements repositories.HelperRepository
+type HelperRepository struct {
+	db *sql.DB
+}
+
+// NewHelperRepository creates a new HelperRepository
+func NewHelperRepository(db *sql.DB) repositories.HelperRepository {
+	return &HelperRepository{db: db}
+}
+
+// CreateHelper stores a new helper
+func (r *HelperRepository) CreateHelper(ctx context.Context, helper *models.Helper) error {
+	configJSON, err := json.Marshal(helper.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal helper config: %w", err)
+	}
+
+	query := `
+		INSERT INTO helpers (` + helperColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	_, err = r.db.ExecContext(ctx, query,
+		helper.ID,
+		helper.Type,
+		helper.Name,
+		helper.Icon,
+		string(configJSON),
+		helper.State,
+		helper.TimerDuration,
+		helper.TimerRemaining,
+		nullableTime(helper.TimerFinishesAt),
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create helper: %w", err)
+	}
+
+	helper.CreatedAt = now
+	helper.UpdatedAt = now
+	return nil
+}
+
+// GetHelper retrieves a helper by ID
+func (r *HelperRepository) GetHelper(ctx context.Context, id string) (*models.Helper, error) {
+	query := `SELECT ` + helperColumns + ` FROM helpers WHERE id = ?`
+
+	helper, err := scanHelper(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("helper %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get helper: %w", err)
+	}
+
+	return helper, nil
+}
+
+// ListHelpers returns all helpers
+func (r *HelperRepository) ListHelpers(ctx context.Context) ([]*models.Helper, error) {
+	query := `SELECT ` + helperColumns + ` FROM helpers ORDER BY type, name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list helpers: %w", err)
+	}
+	defer rows.Close()
+
+	var helpers []*models.Helper
+	for rows.Next() {
+		helper, err := scanHelper(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan helper: %w", err)
+		}
+		helpers = append(helpers, helper)
+	}
+
+	return helpers, rows.Err()
+}
+
+// UpdateHelper updates the settings of a helper
+func (r *HelperRepository) UpdateHelper(ctx context.Context, helper *models.Helper) error {
+	configJSON, err := json.Marshal(helper.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal helper config: %w", err)
+	}
+
+	query := `UPDATE helpers SET name = ?, icon = ?, config = ?, updated_at = ? WHERE id = ?`
+
+	now := time.Now().UTC()
+	if err := r.execOne(ctx, helper.ID, query, helper.Name, helper.Icon, string(configJSON), now, helper.ID); err != nil {
+		return err
+	}
+
+	helper.UpdatedAt = now
+	return nil
+}
+
+// DeleteHelper deletes a helper
+func (r *HelperRepository) DeleteHelper(ctx context.Context, id string) error {
+	return r.execOne(ctx, id, `DELETE FROM helpers WHERE id = ?`, id)
+}
+
+// SaveHelperState stores the current state of a helper, including timer progress
+func (r *HelperRepository) SaveHelperState(ctx context.Context, helper *models.Helper) error {
+	query := `
+		UPDATE helpers
+		SET state = ?, timer_duration = ?, timer_remaining = ?, timer_finishes_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now().UTC()
+	if err := r.execOne(ctx, helper.ID, query,
+		helper.State, helper.TimerDuration, helper.TimerRemaining, nullableTime(helper.TimerFinishesAt), now, helper.ID,
+	); err != nil {
+		return err
+	}
+
+	helper.UpdatedAt = now
+	return nil
+}
+
+// execOne executes a statement that must affect the helper
+func (r *HelperRepository) execOne(ctx context.Context, id, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update helper: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("helper %s not found", id)
+	}
+
+	return nil
+}
+
+func scanHelper(row notificationScanner) (*models.Helper, error) {
+	var helper models.Helper
+	var icon sql.NullString
+	var configJSON string
+	var finishesAt sql.NullTime
+
+	err := row.Scan(
+		&helper.ID,
+		&helper.Type,
+		&helper.Name,
+		&icon,
+		&configJSON,
+		&helper.State,
+		&helper.TimerDuration,
+		&helper.TimerRemaining,
+		&finishesAt,
+		&helper.CreatedAt,
+		&helper.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(configJSON), &helper.Config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal helper config: %w", err)
+	}
+	helper.Icon = icon.String
+	helper.TimerFinishesAt = nullTimePtr(finishesAt)
+	return &helper, nil
+}
+
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -1053,14 +1053,35 @@ func (r *KioskRepository) UpdateHeartbeat(ctx context.Context, tokenID string) e
 	return nil
 }
 
+// SetDeviceStatus updates only the status field of a device (online, offline, etc.)
+func (r *KioskRepository) SetDeviceStatus(ctx context.Context, tokenID, status string) error {
+	query := `UPDATE kiosk_device_status SET status = ?, updated_at = ? WHERE kiosk_token_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, status, time.Now(), tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to set device status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("device status not found")
+	}
+
+	return nil
+}
+
 // ======== COMMAND MANAGEMENT ========
 
 // CreateCommand creates a new kiosk command
 func (r *KioskRepository) CreateCommand(ctx context.Context, command *models.KioskCommand) error {
 	query := `
 		INSERT INTO kiosk_commands (id, kiosk_token_id, command_type, command_data, status, 
-			created_at, expires_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+			created_at, expires_at, timeout_seconds)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	command.CreatedAt = time.Now()
@@ -1075,6 +1096,7 @@ func (r *KioskRepository) CreateCommand(ctx context.Context, command *models.Kio
 		command.Status,
 		command.CreatedAt,
 		command.ExpiresAt,
+		command.TimeoutSeconds,
 	)
 
 	if err != nil {
@@ -1088,7 +1110,7 @@ func (r *KioskRepository) CreateCommand(ctx context.Context, command *models.Kio
 func (r *KioskRepository) GetCommand(ctx context.Context, commandID string) (*models.KioskCommand, error) {
 	query := `
 		SELECT id, kiosk_token_id, command_type, command_data, status, created_at,
-			sent_at, acknowledged_at, completed_at, expires_at, result_data, error_message
+			sent_at, acknowledged_at, completed_at, expires_at, result_data, error_message, timeout_seconds
 		FROM kiosk_commands
 		WHERE id = ?
 	`
@@ -1107,6 +1129,7 @@ func (r *KioskRepository) GetCommand(ctx context.Context, commandID string) (*mo
 		&command.ExpiresAt,
 		&command.ResultData,
 		&command.ErrorMessage,
+		&command.TimeoutSeconds,
 	)
 
 	if err == sql.ErrNoRows {
@@ -1123,7 +1146,7 @@ func (r *KioskRepository) GetCommand(ctx context.Context, commandID string) (*mo
 func (r *KioskRepository) GetPendingCommands(ctx context.Context, tokenID string) ([]*models.KioskCommand, error) {
 	query := `
 		SELECT id, kiosk_token_id, command_type, command_data, status, created_at,
-			sent_at, acknowledged_at, completed_at, expires_at, result_data, error_message
+			sent_at, acknowledged_at, completed_at, expires_at, result_data, error_message, timeout_seconds
 		FROM kiosk_commands
 		WHERE kiosk_token_id = ? AND status IN ('pending', 'sent') 
 		AND expires_at > datetime('now')
@@ -1152,6 +1175,55 @@ func (r *KioskRepository) GetPendingCommands(ctx context.Context, tokenID string
 			&command.ExpiresAt,
 			&command.ResultData,
 			&command.ErrorMessage,
+			&command.TimeoutSeconds,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command: %w", err)
+		}
+		commands = append(commands, command)
+	}
+
+	return commands, nil
+}
+
+// GetCommandsByToken retrieves the most recent commands for a kiosk token regardless of status
+func (r *KioskRepository) GetCommandsByToken(ctx context.Context, tokenID string, limit int) ([]*models.KioskCommand, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, kiosk_token_id, command_type, command_data, status, created_at,
+			sent_at, acknowledged_at, completed_at, expires_at, result_data, error_message, timeout_seconds
+		FROM kiosk_commands
+		WHERE kiosk_token_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tokenID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commands: %w", err)
+	}
+	defer rows.Close()
+
+	var commands []*models.KioskCommand
+	for rows.Next() {
+		command := &models.KioskCommand{}
+		err := rows.Scan(
+			&command.ID,
+			&command.KioskTokenID,
+			&command.CommandType,
+			&command.CommandData,
+			&command.Status,
+			&command.CreatedAt,
+			&command.SentAt,
+			&command.AcknowledgedAt,
+			&command.CompletedAt,
+			&command.ExpiresAt,
+			&command.ResultData,
+			&command.ErrorMessage,
+			&command.TimeoutSeconds,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan command: %w", err)
@@ -1240,6 +1312,71 @@ func (r *KioskRepository) CompleteCommand(ctx context.Context, commandID string,
 	return nil
 }
 
+// GetInFlightCommands retrieves commands delivered to any kiosk that have no result yet
+func (r *KioskRepository) GetInFlightCommands(ctx context.Context) ([]*models.KioskCommand, error) {
+	query := `
+		SELECT id, kiosk_token_id, command_type, command_data, status, created_at,
+			sent_at, acknowledged_at, completed_at, expires_at, result_data, error_message, timeout_seconds
+		FROM kiosk_commands
+		WHERE status IN ('sent', 'acknowledged')
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query in-flight commands: %w", err)
+	}
+	defer rows.Close()
+
+	var commands []*models.KioskCommand
+	for rows.Next() {
+		command := &models.KioskCommand{}
+		err := rows.Scan(
+			&command.ID,
+			&command.KioskTokenID,
+			&command.CommandType,
+			&command.CommandData,
+			&command.Status,
+			&command.CreatedAt,
+			&command.SentAt,
+			&command.AcknowledgedAt,
+			&command.CompletedAt,
+			&command.ExpiresAt,
+			&command.ResultData,
+			&command.ErrorMessage,
+			&command.TimeoutSeconds,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command: %w", err)
+		}
+		commands = append(commands, command)
+	}
+
+	return commands, nil
+}
+
+// TimeOutCommand marks an in-flight command as failed. It returns false when the
+// command already has a result, so a late result is never overwritten.
+func (r *KioskRepository) TimeOutCommand(ctx context.Context, commandID, errorMsg string) (bool, error) {
+	query := `
+		UPDATE kiosk_commands 
+		SET status = 'failed', completed_at = ?, error_message = ?
+		WHERE id = ? AND status IN ('sent', 'acknowledged')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), errorMsg, commandID)
+	if err != nil {
+		return false, fmt.Errorf("failed to time out command: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
 // CleanupExpiredCommands removes expired commands
 func (r *KioskRepository) CleanupExpiredCommands(ctx context.Context) error {
 	query := `DELETE FROM kiosk_commands WHERE expires_at <= datetime('now')`
@@ -848,3 +848,168 @@ func (r *MCPRepository) CleanupOldExecutions(ctx context.Context, days int) erro
 	fmt.Printf("Cleaned up %d old tool executions\n", rowsAffected)
 	return nil
 }
+
+const mcpServerColumns = `id, name, description, transport, command, args, env, cwd, url, headers,
+	timeout, enabled, auto_restart, max_restarts, created_at, updated_at`
+
+// SaveServer creates or replaces an external MCP server configuration
+func (r *MCPRepository) SaveServer(ctx context.Context, server *ai.MCPServer) error {
+	argsJSON, err := json.Marshal(server.Args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal args: %w", err)
+	}
+	envJSON, err := json.Marshal(server.Env)
+	if err != nil {
+		return fmt.Errorf("failed to marshal env: %w", err)
+	}
+	headersJSON, err := json.Marshal(server.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal headers: %w", err)
+	}
+
+	now := time.Now()
+	if server.CreatedAt.IsZero() {
+		server.CreatedAt = now
+	}
+	server.UpdatedAt = now
+
+	query := `
+		INSERT INTO mcp_servers (` + mcpServerColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			transport = excluded.transport,
+			command = excluded.command,
+			args = excluded.args,
+			env = excluded.env,
+			cwd = excluded.cwd,
+			url = excluded.url,
+			headers = excluded.headers,
+			timeout = excluded.timeout,
+			enabled = excluded.enabled,
+			auto_restart = excluded.auto_restart,
+			max_restarts = excluded.max_restarts,
+			updated_at = excluded.updated_at
+	`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		server.ID,
+		server.Name,
+		server.Description,
+		server.Transport,
+		server.Command,
+		string(argsJSON),
+		string(envJSON),
+		server.CWD,
+		server.URL,
+		string(headersJSON),
+		server.Timeout,
+		server.Enabled,
+		server.AutoRestart,
+		server.MaxRestarts,
+		server.CreatedAt,
+		server.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// GetServer retrieves an external MCP server configuration by ID
+func (r *MCPRepository) GetServer(ctx context.Context, id string) (*ai.MCPServer, error) {
+	query := `SELECT ` + mcpServerColumns + ` FROM mcp_servers WHERE id = ?`
+
+	server, err := scanMCPServer(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("MCP server not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCP server: %w", err)
+	}
+
+	return server, nil
+}
+
+// GetServers retrieves all external MCP server configurations
+func (r *MCPRepository) GetServers(ctx context.Context) ([]*ai.MCPServer, error) {
+	query := `SELECT ` + mcpServerColumns + ` FROM mcp_servers ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCP servers: %w", err)
+	}
+	defer rows.Close()
+
+	var servers []*ai.MCPServer
+	for rows.Next() {
+		server, err := scanMCPServer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan MCP server: %w", err)
+		}
+		servers = append(servers, server)
+	}
+
+	return servers, rows.Err()
+}
+
+// DeleteServer removes an external MCP server configuration
+func (r *MCPRepository) DeleteServer(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM mcp_servers WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete MCP server: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("MCP server not found")
+	}
+
+	return nil
+}
+
+func scanMCPServer(row interface{ Scan(...interface{}) error }) (*ai.MCPServer, error) {
+	server := &ai.MCPServer{}
+	var argsJSON, envJSON, headersJSON string
+
+	err := row.Scan(
+		&server.ID,
+		&server.Name,
+		&server.Description,
+		&server.Transport,
+		&server.Command,
+		&argsJSON,
+		&envJSON,
+		&server.CWD,
+		&server.URL,
+		&headersJSON,
+		&server.Timeout,
+		&server.Enabled,
+		&server.AutoRestart,
+		&server.MaxRestarts,
+		&server.CreatedAt,
+		&server.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(argsJSON), &server.Args); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+	if err := json.Unmarshal([]byte(envJSON), &server.Env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal env: %w", err)
+	}
+	if err := json.Unmarshal([]byte(headersJSON), &server.Headers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal headers: %w", err)
+	}
+
+	return server, nil
+}
@@ -0,0 +1,366 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const notificationColumns = `id, user_id, type, title, message, priority, source, room_id, device_id,
+	data, actions, categories, deliveries, read_at, acknowledged_at, acknowledged_by, response, responded_at,
+	expires_at, created_at`
+
+// NotificationRepository implements repositories.NotificationRepository
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *sql.DB) repositories.NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores a new notification
+func (r *NotificationRepository) Create(ctx context.Context, n *models.Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, type, title, message, priority, source, room_id, device_id,
+			data, actions, categories, deliveries, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	n.CreatedAt = n.CreatedAt.UTC()
+
+	var expiresAt interface{}
+	if n.ExpiresAt != nil {
+		expiresAt = n.ExpiresAt.UTC()
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		n.ID,
+		n.UserID,
+		n.Type,
+		n.Title,
+		n.Message,
+		n.Priority,
+		n.Source,
+		n.RoomID,
+		n.DeviceID,
+		jsonOrDefault(n.Data, "{}"),
+		jsonOrDefault(n.Actions, "[]"),
+		jsonOrDefault(n.Categories, "[]"),
+		jsonOrDefault(n.Deliveries, "[]"),
+		expiresAt,
+		n.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a notification by ID
+func (r *NotificationRepository) GetByID(ctx context.Context, id string) (*models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE id = ?`
+
+	n, err := scanNotification(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("notification %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	return n, nil
+}
+
+// List returns notifications matching the filter, newest first, together with the total match count
+func (r *NotificationRepository) List(ctx context.Context, filter models.NotificationFilter) ([]*models.Notification, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.UnreadOnly {
+		conditions = append(conditions, "read_at IS NULL")
+	}
+	if filter.UnacknowledgedOnly {
+		conditions = append(conditions, "acknowledged_at IS NULL")
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications` + where + ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// CountUnread returns the number of unread notifications of a user
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateDeliveries replaces the per-channel delivery results of a notification
+func (r *NotificationRepository) UpdateDeliveries(ctx context.Context, id string, deliveries []byte) error {
+	return r.updateOne(ctx, id, `UPDATE notifications SET deliveries = ? WHERE id = ?`, jsonOrDefault(deliveries, "[]"), id)
+}
+
+// MarkRead marks a notification as read, keeping the first read time
+func (r *NotificationRepository) MarkRead(ctx context.Context, id string, at time.Time) error {
+	return r.updateOne(ctx, id, `UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ?`, at.UTC(), id)
+}
+
+// MarkAllRead marks every unread notification of a user as read and returns how many changed
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`, at.UTC(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// Acknowledge marks a notification as acknowledged (and read)
+func (r *NotificationRepository) Acknowledge(ctx context.Context, id, by string, at time.Time) error {
+	query := `
+		UPDATE notifications
+		SET acknowledged_at = COALESCE(acknowledged_at, ?), acknowledged_by = ?, read_at = COALESCE(read_at, ?)
+		WHERE id = ?
+	`
+	return r.updateOne(ctx, id, query, at.UTC(), by, at.UTC(), id)
+}
+
+// SaveResponse stores the response to an actionable notification
+func (r *NotificationRepository) SaveResponse(ctx context.Context, id string, response []byte, at time.Time) error {
+	query := `UPDATE notifications SET response = ?, responded_at = ?, read_at = COALESCE(read_at, ?) WHERE id = ?`
+	return r.updateOne(ctx, id, query, string(response), at.UTC(), at.UTC(), id)
+}
+
+// Delete deletes a notification
+func (r *NotificationRepository) Delete(ctx context.Context, id string) error {
+	return r.updateOne(ctx, id, `DELETE FROM notifications WHERE id = ?`, id)
+}
+
+// DeleteOlderThan deletes notifications created before the cutoff and returns how many were removed
+func (r *NotificationRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notifications WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old notifications: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// SavePushSubscription stores a Web Push subscription, replacing an existing one for the same endpoint
+func (r *NotificationRepository) SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) error {
+	query := `
+		INSERT INTO notification_push_subscriptions (user_id, endpoint, p256dh, auth, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(endpoint) DO UPDATE SET
+			user_id = excluded.user_id, p256dh = excluded.p256dh, auth = excluded.auth, user_agent = excluded.user_agent
+	`
+
+	subscription.CreatedAt = time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, query,
+		subscription.UserID,
+		subscription.Endpoint,
+		subscription.P256dh,
+		subscription.Auth,
+		subscription.UserAgent,
+		subscription.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetPushSubscriptions returns the Web Push subscriptions of a user
+func (r *NotificationRepository) GetPushSubscriptions(ctx context.Context, userID string) ([]*models.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, user_agent, created_at
+		FROM notification_push_subscriptions
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*models.PushSubscription
+	for rows.Next() {
+		var s models.PushSubscription
+		var userAgent sql.NullString
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256dh, &s.Auth, &userAgent, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		s.UserAgent = userAgent.String
+		subscriptions = append(subscriptions, &s)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// DeletePushSubscription removes a Web Push subscription, e.g. after the push service reported it gone
+func (r *NotificationRepository) DeletePushSubscription(ctx context.Context, endpoint string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM notification_push_subscriptions WHERE endpoint = ?`, endpoint); err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+// updateOne executes a statement that must affect the notification with the given ID
+func (r *NotificationRepository) updateOne(ctx context.Context, id, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("notification %s not found", id)
+	}
+
+	return nil
+}
+
+type notificationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotification(row notificationScanner) (*models.Notification, error) {
+	var n models.Notification
+	var source, roomID, deviceID, acknowledgedBy, response sql.NullString
+	var data, actions, categories, deliveries string
+	var readAt, acknowledgedAt, respondedAt, expiresAt sql.NullTime
+
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.Title,
+		&n.Message,
+		&n.Priority,
+		&source,
+		&roomID,
+		&deviceID,
+		&data,
+		&actions,
+		&categories,
+		&deliveries,
+		&readAt,
+		&acknowledgedAt,
+		&acknowledgedBy,
+		&response,
+		&respondedAt,
+		&expiresAt,
+		&n.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	n.Source = source.String
+	n.RoomID = roomID.String
+	n.DeviceID = deviceID.String
+	n.AcknowledgedBy = acknowledgedBy.String
+	n.Data = []byte(data)
+	n.Actions = []byte(actions)
+	n.Categories = []byte(categories)
+	n.Deliveries = []byte(deliveries)
+	if response.String != "" {
+		n.Response = []byte(response.String)
+	}
+	n.ReadAt = nullTimePtr(readAt)
+	n.AcknowledgedAt = nullTimePtr(acknowledgedAt)
+	n.RespondedAt = nullTimePtr(respondedAt)
+	n.ExpiresAt = nullTimePtr(expiresAt)
+
+	return &n, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func jsonOrDefault(data []byte, fallback string) string {
+	if len(data) == 0 {
+		return fallback
+	}
+	return string(data)
+}
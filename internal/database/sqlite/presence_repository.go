@@ -0,0 +1,328 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const presenceTrackerColumns = `id, person_id, type, identifier, name, prob_given_home, prob_given_away,
+	consider_home_seconds, min_rssi, last_seen, last_state, last_reported, created_at`
+
+// PresenceRepository implements repositories.PresenceRepository
+type PresenceRepository struct {
+	db *sql.DB
+}
+
+// NewPresenceRepository creates a new PresenceRepository
+func NewPresenceRepository(db *sql.DB) repositories.PresenceRepository {
+	return &PresenceRepository{db: db}
+}
+
+// CreatePerson stores a new person
+func (r *PresenceRepository) CreatePerson(ctx context.Context, person *models.PresencePerson) error {
+	query := `
+		INSERT INTO presence_persons (id, name, user_id, picture, consider_home_seconds, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, query,
+		person.ID,
+		person.Name,
+		person.UserID,
+		person.Picture,
+		person.ConsiderHomeSeconds,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create person: %w", err)
+	}
+
+	person.CreatedAt = now
+	person.UpdatedAt = now
+	return nil
+}
+
+// GetPerson retrieves a person together with their trackers
+func (r *PresenceRepository) GetPerson(ctx context.Context, id string) (*models.PresencePerson, error) {
+	query := `
+		SELECT id, name, user_id, picture, consider_home_seconds, created_at, updated_at
+		FROM presence_persons
+		WHERE id = ?
+	`
+
+	person, err := scanPresencePerson(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("person %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	person.Trackers, err = r.getTrackers(ctx, `WHERE person_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+// ListPersons returns all persons together with their trackers
+func (r *PresenceRepository) ListPersons(ctx context.Context) ([]*models.PresencePerson, error) {
+	query := `
+		SELECT id, name, user_id, picture, consider_home_seconds, created_at, updated_at
+		FROM presence_persons
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persons: %w", err)
+	}
+	defer rows.Close()
+
+	var persons []*models.PresencePerson
+	byID := make(map[string]*models.PresencePerson)
+	for rows.Next() {
+		person, err := scanPresencePerson(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan person: %w", err)
+		}
+		persons = append(persons, person)
+		byID[person.ID] = person
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate persons: %w", err)
+	}
+
+	trackers, err := r.getTrackers(ctx, ``)
+	if err != nil {
+		return nil, err
+	}
+	for _, tracker := range trackers {
+		if person, ok := byID[tracker.PersonID]; ok {
+			person.Trackers = append(person.Trackers, tracker)
+		}
+	}
+
+	return persons, nil
+}
+
+// UpdatePerson updates the settings of a person
+func (r *PresenceRepository) UpdatePerson(ctx context.Context, person *models.PresencePerson) error {
+	query := `
+		UPDATE presence_persons
+		SET name = ?, user_id = ?, picture = ?, consider_home_seconds = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now().UTC()
+	if err := r.execOne(ctx, "person", person.ID, query,
+		person.Name, person.UserID, person.Picture, person.ConsiderHomeSeconds, now, person.ID,
+	); err != nil {
+		return err
+	}
+
+	person.UpdatedAt = now
+	return nil
+}
+
+// DeletePerson deletes a person and their trackers
+func (r *PresenceRepository) DeletePerson(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM presence_trackers WHERE person_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete trackers: %w", err)
+	}
+	return r.execOne(ctx, "person", id, `DELETE FROM presence_persons WHERE id = ?`, id)
+}
+
+// CreateTracker adds a tracker to a person
+func (r *PresenceRepository) CreateTracker(ctx context.Context, tracker *models.PresenceTracker) error {
+	query := `
+		INSERT INTO presence_trackers (person_id, type, identifier, name, prob_given_home, prob_given_away,
+			consider_home_seconds, min_rssi, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	tracker.CreatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, query,
+		tracker.PersonID,
+		tracker.Type,
+		tracker.Identifier,
+		tracker.Name,
+		tracker.ProbGivenHome,
+		tracker.ProbGivenAway,
+		tracker.ConsiderHomeSeconds,
+		tracker.MinRSSI,
+		tracker.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create tracker: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get inserted tracker ID: %w", err)
+	}
+	tracker.ID = int(id)
+
+	return nil
+}
+
+// DeleteTracker removes a tracker from a person
+func (r *PresenceRepository) DeleteTracker(ctx context.Context, personID string, id int) error {
+	return r.execOne(ctx, "tracker", fmt.Sprint(id), `DELETE FROM presence_trackers WHERE id = ? AND person_id = ?`, id, personID)
+}
+
+// UpdateTrackerObservation records what a tracker last reported
+func (r *PresenceRepository) UpdateTrackerObservation(ctx context.Context, id int, lastSeen *time.Time, lastState string, reportedAt time.Time) error {
+	var seen interface{}
+	if lastSeen != nil {
+		seen = lastSeen.UTC()
+	}
+
+	query := `UPDATE presence_trackers SET last_seen = ?, last_state = ?, last_reported = ? WHERE id = ?`
+	return r.execOne(ctx, "tracker", fmt.Sprint(id), query, seen, lastState, reportedAt.UTC(), id)
+}
+
+// ListZones returns all zones
+func (r *PresenceRepository) ListZones(ctx context.Context) ([]*models.PresenceZone, error) {
+	query := `
+		SELECT id, name, latitude, longitude, radius_meters, created_at
+		FROM presence_zones
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+	defer rows.Close()
+
+	var zones []*models.PresenceZone
+	for rows.Next() {
+		var zone models.PresenceZone
+		if err := rows.Scan(&zone.ID, &zone.Name, &zone.Latitude, &zone.Longitude, &zone.RadiusMeters, &zone.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan zone: %w", err)
+		}
+		zones = append(zones, &zone)
+	}
+
+	return zones, rows.Err()
+}
+
+// SaveZone creates or replaces a zone
+func (r *PresenceRepository) SaveZone(ctx context.Context, zone *models.PresenceZone) error {
+	query := `
+		INSERT INTO presence_zones (id, name, latitude, longitude, radius_meters, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name, latitude = excluded.latitude, longitude = excluded.longitude,
+			radius_meters = excluded.radius_meters
+	`
+
+	if zone.CreatedAt.IsZero() {
+		zone.CreatedAt = time.Now().UTC()
+	}
+	_, err := r.db.ExecContext(ctx, query, zone.ID, zone.Name, zone.Latitude, zone.Longitude, zone.RadiusMeters, zone.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save zone: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteZone deletes a zone
+func (r *PresenceRepository) DeleteZone(ctx context.Context, id string) error {
+	return r.execOne(ctx, "zone", id, `DELETE FROM presence_zones WHERE id = ?`, id)
+}
+
+func (r *PresenceRepository) getTrackers(ctx context.Context, where string, args ...interface{}) ([]*models.PresenceTracker, error) {
+	query := `SELECT ` + presenceTrackerColumns + ` FROM presence_trackers ` + where + ` ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trackers: %w", err)
+	}
+	defer rows.Close()
+
+	var trackers []*models.PresenceTracker
+	for rows.Next() {
+		var t models.PresenceTracker
+		var name, lastState sql.NullString
+		var lastSeen, lastReported sql.NullTime
+
+		err := rows.Scan(
+			&t.ID,
+			&t.PersonID,
+			&t.Type,
+			&t.Identifier,
+			&name,
+			&t.ProbGivenHome,
+			&t.ProbGivenAway,
+			&t.ConsiderHomeSeconds,
+			&t.MinRSSI,
+			&lastSeen,
+			&lastState,
+			&lastReported,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tracker: %w", err)
+		}
+
+		t.Name = name.String
+		t.LastState = lastState.String
+		t.LastSeen = nullTimePtr(lastSeen)
+		t.LastReported = nullTimePtr(lastReported)
+		trackers = append(trackers, &t)
+	}
+
+	return trackers, rows.Err()
+}
+
+// execOne executes a statement that must affect the named record
+func (r *PresenceRepository) execOne(ctx context.Context, kind, id, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", kind, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s %s not found", kind, id)
+	}
+
+	return nil
+}
+
+func scanPresencePerson(row notificationScanner) (*models.PresencePerson, error) {
+	var person models.PresencePerson
+	var userID, picture sql.NullString
+
+	err := row.Scan(
+		&person.ID,
+		&person.Name,
+		&userID,
+		&picture,
+		&person.ConsiderHomeSeconds,
+		&person.CreatedAt,
+		&person.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	person.UserID = userID.String
+	person.Picture = picture.String
+	return &person, nil
+}
@@ -464,7 +464,13 @@ func (r *QueueRepository) DeleteQueuedAction(ctx context.Context, id int) error
 }
 
 // Queue Processing Methods
-func (r *QueueRepository) GetNextActionsToProcess(ctx context.Context, limit int) ([]*models.QueuedAction, error) {
+// GetNextActionsToProcess returns the actions due for processing, highest priority first,
+// limited to action types handled by one of handlerNames
+func (r *QueueRepository) GetNextActionsToProcess(ctx context.Context, limit int, handlerNames []string) ([]*models.QueuedAction, error) {
+	if len(handlerNames) == 0 {
+		return nil, nil
+	}
+
 	query := `
 		SELECT 
 			qa.id, qa.action_type_id, qa.priority_id, qa.status_id, qa.name, qa.description,
@@ -485,11 +491,18 @@ func (r *QueueRepository) GetNextActionsToProcess(ctx context.Context, limit int
 		  AND (qa.execute_after IS NULL OR qa.execute_after <= CURRENT_TIMESTAMP)
 		  AND (qa.next_retry_at IS NULL OR qa.next_retry_at <= CURRENT_TIMESTAMP)
 		  AND (qa.deadline IS NULL OR qa.deadline > CURRENT_TIMESTAMP)
+		  AND at.handler_name IN (?` + strings.Repeat(", ?", len(handlerNames)-1) + `)
 		ORDER BY ap.weight DESC, qa.created_at ASC
 		LIMIT ?
 	`
 
-	rows, err := r.db.QueryxContext(ctx, query, limit)
+	args := make([]interface{}, 0, len(handlerNames)+1)
+	for _, name := range handlerNames {
+		args = append(args, name)
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		r.log.WithError(err).Error("Failed to get next actions to process")
 		return nil, fmt.Errorf("failed to get next actions to process: %w", err)
@@ -668,7 +681,8 @@ func (r *QueueRepository) ScheduleRetry(ctx context.Context, actionID int, nextR
 		WHERE id = ?
 	`
 
-	_, err = r.db.ExecContext(ctx, updateQuery, statusID, nextRetryAt, actionID)
+	// Stored in UTC so it compares with CURRENT_TIMESTAMP when picking the next actions
+	_, err = r.db.ExecContext(ctx, updateQuery, statusID, nextRetryAt.UTC(), actionID)
 	if err != nil {
 		return fmt.Errorf("failed to schedule retry: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
@@ -23,8 +24,8 @@ func NewRoomRepository(db *sql.DB) repositories.RoomRepository {
 // Create creates a new room
 func (r *RoomRepository) Create(ctx context.Context, room *models.Room) error {
 	query := `
-		INSERT INTO rooms (name, area_id, home_assistant_area_id, icon, description, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO rooms (name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
@@ -36,6 +37,10 @@ func (r *RoomRepository) Create(ctx context.Context, room *models.Room) error {
 		room.HomeAssistantAreaID,
 		room.Icon,
 		room.Description,
+		room.RoomKey,
+		room.ParentKey,
+		room.Metadata,
+		room.Attributes,
 		now,
 		now,
 	)
@@ -49,6 +54,14 @@ func (r *RoomRepository) Create(ctx context.Context, room *models.Room) error {
 	}
 
 	room.ID = int(id)
+
+	// Rooms created without a key are addressed by their numeric ID
+	if !room.RoomKey.Valid {
+		room.RoomKey = sql.NullString{String: strconv.Itoa(room.ID), Valid: true}
+		if _, err := r.db.ExecContext(ctx, `UPDATE rooms SET room_key = ? WHERE id = ?`, room.RoomKey, room.ID); err != nil {
+			return fmt.Errorf("failed to set room key: %w", err)
+		}
+	}
 	room.CreatedAt = now
 	room.UpdatedAt = now
 
@@ -58,7 +71,7 @@ func (r *RoomRepository) Create(ctx context.Context, room *models.Room) error {
 // GetByID retrieves a room by ID
 func (r *RoomRepository) GetByID(ctx context.Context, id int) (*models.Room, error) {
 	query := `
-		SELECT id, name, area_id, home_assistant_area_id, icon, description, created_at, updated_at
+		SELECT id, name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at
 		FROM rooms
 		WHERE id = ?
 	`
@@ -71,6 +84,10 @@ func (r *RoomRepository) GetByID(ctx context.Context, id int) (*models.Room, err
 		&room.HomeAssistantAreaID,
 		&room.Icon,
 		&room.Description,
+		&room.RoomKey,
+		&room.ParentKey,
+		&room.Metadata,
+		&room.Attributes,
 		&room.CreatedAt,
 		&room.UpdatedAt,
 	)
@@ -88,7 +105,7 @@ func (r *RoomRepository) GetByID(ctx context.Context, id int) (*models.Room, err
 // GetByName retrieves a room by name
 func (r *RoomRepository) GetByName(ctx context.Context, name string) (*models.Room, error) {
 	query := `
-		SELECT id, name, area_id, home_assistant_area_id, icon, description, created_at, updated_at
+		SELECT id, name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at
 		FROM rooms
 		WHERE name = ?
 	`
@@ -101,6 +118,10 @@ func (r *RoomRepository) GetByName(ctx context.Context, name string) (*models.Ro
 		&room.HomeAssistantAreaID,
 		&room.Icon,
 		&room.Description,
+		&room.RoomKey,
+		&room.ParentKey,
+		&room.Metadata,
+		&room.Attributes,
 		&room.CreatedAt,
 		&room.UpdatedAt,
 	)
@@ -118,7 +139,7 @@ func (r *RoomRepository) GetByName(ctx context.Context, name string) (*models.Ro
 // GetAll retrieves all rooms
 func (r *RoomRepository) GetAll(ctx context.Context) ([]*models.Room, error) {
 	query := `
-		SELECT id, name, area_id, home_assistant_area_id, icon, description, created_at, updated_at
+		SELECT id, name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at
 		FROM rooms
 		ORDER BY name
 	`
@@ -139,6 +160,10 @@ func (r *RoomRepository) GetAll(ctx context.Context) ([]*models.Room, error) {
 			&room.HomeAssistantAreaID,
 			&room.Icon,
 			&room.Description,
+			&room.RoomKey,
+			&room.ParentKey,
+			&room.Metadata,
+			&room.Attributes,
 			&room.CreatedAt,
 			&room.UpdatedAt,
 		)
@@ -155,7 +180,8 @@ func (r *RoomRepository) GetAll(ctx context.Context) ([]*models.Room, error) {
 func (r *RoomRepository) Update(ctx context.Context, room *models.Room) error {
 	query := `
 		UPDATE rooms 
-		SET name = ?, area_id = ?, home_assistant_area_id = ?, icon = ?, description = ?, updated_at = ?
+		SET name = ?, area_id = ?, home_assistant_area_id = ?, icon = ?, description = ?,
+			parent_key = ?, metadata = ?, attributes = ?, updated_at = ?
 		WHERE id = ?
 	`
 
@@ -168,6 +194,9 @@ func (r *RoomRepository) Update(ctx context.Context, room *models.Room) error {
 		room.HomeAssistantAreaID,
 		room.Icon,
 		room.Description,
+		room.ParentKey,
+		room.Metadata,
+		room.Attributes,
 		now,
 		room.ID,
 	)
@@ -213,7 +242,7 @@ func (r *RoomRepository) Delete(ctx context.Context, id int) error {
 // GetByAreaID retrieves all rooms in a specific area
 func (r *RoomRepository) GetByAreaID(ctx context.Context, areaID int) ([]*models.Room, error) {
 	query := `
-		SELECT id, name, area_id, home_assistant_area_id, icon, description, created_at, updated_at
+		SELECT id, name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at
 		FROM rooms
 		WHERE area_id = ?
 		ORDER BY name
@@ -235,6 +264,10 @@ func (r *RoomRepository) GetByAreaID(ctx context.Context, areaID int) ([]*models
 			&room.HomeAssistantAreaID,
 			&room.Icon,
 			&room.Description,
+			&room.RoomKey,
+			&room.ParentKey,
+			&room.Metadata,
+			&room.Attributes,
 			&room.CreatedAt,
 			&room.UpdatedAt,
 		)
@@ -346,7 +379,7 @@ func (r *RoomRepository) AssignToArea(ctx context.Context, roomID int, areaID *i
 // GetUnassignedRooms retrieves all rooms that are not assigned to any area
 func (r *RoomRepository) GetUnassignedRooms(ctx context.Context) ([]*models.Room, error) {
 	query := `
-		SELECT id, name, area_id, home_assistant_area_id, icon, description, created_at, updated_at
+		SELECT id, name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at
 		FROM rooms
 		WHERE area_id IS NULL
 		ORDER BY name
@@ -368,6 +401,10 @@ func (r *RoomRepository) GetUnassignedRooms(ctx context.Context) ([]*models.Room
 			&room.HomeAssistantAreaID,
 			&room.Icon,
 			&room.Description,
+			&room.RoomKey,
+			&room.ParentKey,
+			&room.Metadata,
+			&room.Attributes,
 			&room.CreatedAt,
 			&room.UpdatedAt,
 		)
@@ -379,3 +416,152 @@ func (r *RoomRepository) GetUnassignedRooms(ctx context.Context) ([]*models.Room
 
 	return rooms, nil
 }
+
+// GetByKey retrieves a room by its room key
+func (r *RoomRepository) GetByKey(ctx context.Context, roomKey string) (*models.Room, error) {
+	query := `
+		SELECT id, name, area_id, home_assistant_area_id, icon, description, room_key, parent_key, metadata, attributes, created_at, updated_at
+		FROM rooms
+		WHERE room_key = ?
+	`
+
+	room := &models.Room{}
+	err := r.db.QueryRowContext(ctx, query, roomKey).Scan(
+		&room.ID,
+		&room.Name,
+		&room.AreaID,
+		&room.HomeAssistantAreaID,
+		&room.Icon,
+		&room.Description,
+		&room.RoomKey,
+		&room.ParentKey,
+		&room.Metadata,
+		&room.Attributes,
+		&room.CreatedAt,
+		&room.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("room not found with key: %s", roomKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	return room, nil
+}
+
+// GetEntityAssignments retrieves all entity to room assignments
+func (r *RoomRepository) GetEntityAssignments(ctx context.Context) ([]*models.RoomEntityAssignment, error) {
+	query := `
+		SELECT entity_id, room_key, assigned_at
+		FROM room_entity_assignments
+		ORDER BY room_key, entity_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entity assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*models.RoomEntityAssignment
+	for rows.Next() {
+		assignment := &models.RoomEntityAssignment{}
+		if err := rows.Scan(&assignment.EntityID, &assignment.RoomKey, &assignment.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entity assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+// AssignEntity places an entity in a room, replacing its previous room
+func (r *RoomRepository) AssignEntity(ctx context.Context, entityID, roomKey string) error {
+	query := `
+		INSERT INTO room_entity_assignments (entity_id, room_key, assigned_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(entity_id) DO UPDATE SET
+			room_key = excluded.room_key,
+			assigned_at = excluded.assigned_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, entityID, roomKey, time.Now()); err != nil {
+		return fmt.Errorf("failed to assign entity to room: %w", err)
+	}
+
+	return nil
+}
+
+// UnassignEntity removes an entity from its room
+func (r *RoomRepository) UnassignEntity(ctx context.Context, entityID string) error {
+	query := `DELETE FROM room_entity_assignments WHERE entity_id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, entityID); err != nil {
+		return fmt.Errorf("failed to unassign entity: %w", err)
+	}
+
+	return nil
+}
+
+// MoveEntities moves all entities of one room to another
+func (r *RoomRepository) MoveEntities(ctx context.Context, fromRoomKey, toRoomKey string) error {
+	query := `UPDATE room_entity_assignments SET room_key = ?, assigned_at = ? WHERE room_key = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, toRoomKey, time.Now(), fromRoomKey); err != nil {
+		return fmt.Errorf("failed to move entities: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEntityAssignments removes all entity assignments of a room
+func (r *RoomRepository) DeleteEntityAssignments(ctx context.Context, roomKey string) error {
+	query := `DELETE FROM room_entity_assignments WHERE room_key = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, roomKey); err != nil {
+		return fmt.Errorf("failed to delete entity assignments: %w", err)
+	}
+
+	return nil
+}
+
+// GetOrphanedEntityRooms returns entities whose room_id references a room that no longer exists
+func (r *RoomRepository) GetOrphanedEntityRooms(ctx context.Context) (map[string]int, error) {
+	query := `
+		SELECT e.entity_id, e.room_id
+		FROM entities e
+		LEFT JOIN rooms r ON r.id = e.room_id
+		WHERE e.room_id IS NOT NULL AND r.id IS NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned entity rooms: %w", err)
+	}
+	defer rows.Close()
+
+	orphaned := make(map[string]int)
+	for rows.Next() {
+		var entityID string
+		var roomID int
+		if err := rows.Scan(&entityID, &roomID); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned entity room: %w", err)
+		}
+		orphaned[entityID] = roomID
+	}
+
+	return orphaned, nil
+}
+
+// ClearEntityRoom clears the room_id of an entity
+func (r *RoomRepository) ClearEntityRoom(ctx context.Context, entityID string) error {
+	query := `UPDATE entities SET room_id = NULL WHERE entity_id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, entityID); err != nil {
+		return fmt.Errorf("failed to clear entity room: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,327 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/database/models"
+	"github.com/frostdev-ops/pma-backend-go/internal/database/repositories"
+)
+
+const webhookColumns = `id, name, url, secret, events, entities, headers, enabled, consecutive_failures, disabled_reason,
+	last_delivery_at, last_success_at, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, attempt, success, status_code, response, error,
+	duration_ms, created_at`
+
+// WebhookRepository implements repositories.WebhookRepository
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *sql.DB) repositories.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateWebhook stores a new webhook and sets its ID
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	events, entities, headers, err := marshalWebhookFilters(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhooks (name, url, secret, events, entities, headers, enabled, consecutive_failures,
+			disabled_reason, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, query,
+		webhook.Name,
+		webhook.URL,
+		webhook.Secret,
+		events,
+		entities,
+		headers,
+		webhook.Enabled,
+		webhook.ConsecutiveFailures,
+		webhook.DisabledReason,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook ID: %w", err)
+	}
+	webhook.ID = id
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+	return nil
+}
+
+// GetWebhook retrieves a webhook by ID
+func (r *WebhookRepository) GetWebhook(ctx context.Context, id int64) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns all webhooks
+func (r *WebhookRepository) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY name, id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook updates the settings of a webhook, including whether it is enabled
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	events, entities, headers, err := marshalWebhookFilters(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET name = ?, url = ?, secret = ?, events = ?, entities = ?, headers = ?, enabled = ?,
+			consecutive_failures = ?, disabled_reason = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now().UTC()
+	if err := r.execOne(ctx, webhook.ID, query,
+		webhook.Name, webhook.URL, webhook.Secret, events, entities, headers, webhook.Enabled,
+		webhook.ConsecutiveFailures, webhook.DisabledReason, now, webhook.ID,
+	); err != nil {
+		return err
+	}
+
+	webhook.UpdatedAt = now
+	return nil
+}
+
+// SaveWebhookStatus stores the delivery status of a webhook
+func (r *WebhookRepository) SaveWebhookStatus(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET enabled = ?, consecutive_failures = ?, disabled_reason = ?, last_delivery_at = ?, last_success_at = ?
+		WHERE id = ?
+	`
+
+	return r.execOne(ctx, webhook.ID, query,
+		webhook.Enabled, webhook.ConsecutiveFailures, webhook.DisabledReason,
+		nullableTime(webhook.LastDeliveryAt), nullableTime(webhook.LastSuccessAt), webhook.ID,
+	)
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return r.execOne(ctx, id, `DELETE FROM webhooks WHERE id = ?`, id)
+}
+
+// CreateDelivery appends a delivery attempt to the log and sets its ID
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, attempt, success, status_code, response,
+			error, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now().UTC()
+	}
+	result, err := r.db.ExecContext(ctx, query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Attempt,
+		delivery.Success,
+		delivery.StatusCode,
+		delivery.Response,
+		delivery.Error,
+		delivery.DurationMS,
+		delivery.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery ID: %w", err)
+	}
+	delivery.ID = id
+	return nil
+}
+
+// ListDeliveries returns the delivery attempts of a webhook, newest first, and their total count
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?`, webhookID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var response, errMsg sql.NullString
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempt,
+			&delivery.Success,
+			&delivery.StatusCode,
+			&response,
+			&errMsg,
+			&delivery.DurationMS,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		delivery.Response = response.String
+		delivery.Error = errMsg.String
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+// DeleteDeliveriesBefore removes delivery attempts older than the given time
+func (r *WebhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// execOne executes a statement that must affect the webhook
+func (r *WebhookRepository) execOne(ctx context.Context, id int64, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook %d not found", id)
+	}
+
+	return nil
+}
+
+func marshalWebhookFilters(webhook *models.Webhook) (events, entities, headers string, err error) {
+	eventsJSON, err := json.Marshal(nonNilStrings(webhook.Events))
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal webhook events: %w", err)
+	}
+	entitiesJSON, err := json.Marshal(nonNilStrings(webhook.Entities))
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal webhook entities: %w", err)
+	}
+	headerMap := webhook.Headers
+	if headerMap == nil {
+		headerMap = map[string]string{}
+	}
+	headersJSON, err := json.Marshal(headerMap)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal webhook headers: %w", err)
+	}
+	return string(eventsJSON), string(entitiesJSON), string(headersJSON), nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func scanWebhook(row notificationScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var secret, disabledReason sql.NullString
+	var events, entities, headers string
+	var lastDelivery, lastSuccess sql.NullTime
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.Name,
+		&webhook.URL,
+		&secret,
+		&events,
+		&entities,
+		&headers,
+		&webhook.Enabled,
+		&webhook.ConsecutiveFailures,
+		&disabledReason,
+		&lastDelivery,
+		&lastSuccess,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook events: %w", err)
+	}
+	if err := json.Unmarshal([]byte(entities), &webhook.Entities); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook entities: %w", err)
+	}
+	if err := json.Unmarshal([]byte(headers), &webhook.Headers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook headers: %w", err)
+	}
+	webhook.Secret = secret.String
+	webhook.DisabledReason = disabledReason.String
+	webhook.LastDeliveryAt = nullTimePtr(lastDelivery)
+	webhook.LastSuccessAt = nullTimePtr(lastSuccess)
+	return &webhook, nil
+}
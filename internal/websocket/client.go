@@ -23,8 +23,8 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer
-	maxMessageSize = 512
+	// Maximum message size allowed from peer, large enough for base64 encoded voice audio chunks
+	maxMessageSize = 64 * 1024
 )
 
 var upgrader = websocket.Upgrader{
@@ -350,10 +350,60 @@ func (c *Client) handleMessage(message []byte) {
 		}
 		c.send <- pong.ToJSON()
 	default:
+		if handler, ok := c.hub.getMessageHandler(msg.Type); ok {
+			handler(c, msg)
+			return
+		}
 		c.logger.WithField("message_type", msg.Type).Warn("Unknown WebSocket message type")
 	}
 }
 
+// Send queues a message for delivery to this client.
+// It returns false if the client's send buffer is full or the client has disconnected.
+func (c *Client) Send(messageType string, data map[string]interface{}) (sent bool) {
+	defer func() {
+		// The send channel is closed when the client unregisters
+		if r := recover(); r != nil {
+			sent = false
+		}
+	}()
+
+	msg := Message{
+		Type:      messageType,
+		Data:      data,
+		Timestamp: time.Now().UTC(),
+	}
+
+	select {
+	case c.send <- msg.ToJSON():
+		return true
+	default:
+		c.logger.WithField("client_id", c.ID).Warn("Client send channel full, dropping message")
+		return false
+	}
+}
+
+// SetMetadata stores a metadata value on the client info
+func (c *Client) SetMetadata(key string, value interface{}) {
+	c.subscriptionMu.Lock()
+	defer c.subscriptionMu.Unlock()
+	if c.info.Metadata == nil {
+		c.info.Metadata = make(map[string]interface{})
+	}
+	c.info.Metadata[key] = value
+}
+
+// GetMetadata returns a metadata value from the client info
+func (c *Client) GetMetadata(key string) (interface{}, bool) {
+	c.subscriptionMu.RLock()
+	defer c.subscriptionMu.RUnlock()
+	if c.info.Metadata == nil {
+		return nil, false
+	}
+	value, ok := c.info.Metadata[key]
+	return value, ok
+}
+
 // SubscribeToRoom subscribes the client to room updates
 func (c *Client) SubscribeToRoom(roomID int) {
 	c.rooms[roomID] = true
@@ -56,8 +56,17 @@ type Hub struct {
 	clientTimeout   time.Duration
 	cleanupTicker   *time.Ticker
 	cleanupStopChan chan bool
+
+	// Handlers for application-specific message types (e.g. kiosk protocol)
+	messageHandlers map[string]MessageHandler
+
+	// Callbacks invoked after a client disconnects
+	disconnectHandlers []func(*Client)
 }
 
+// MessageHandler processes an inbound client message that is not part of the core protocol
+type MessageHandler func(client *Client, msg Message)
+
 // ExtendedClientInfo holds additional information about a connected client
 type ExtendedClientInfo struct {
 	ID            string                 `json:"id"`
@@ -139,6 +148,7 @@ func NewHub(logger *logrus.Logger) *Hub {
 		clientTimeout:   DefaultClientTimeout,
 		cleanupTicker:   time.NewTicker(CleanupInterval),
 		cleanupStopChan: make(chan bool),
+		messageHandlers: make(map[string]MessageHandler),
 	}
 }
 
@@ -361,6 +371,72 @@ func (h *Hub) SendToClient(clientID, messageType string, data interface{}) {
 	}
 }
 
+// RegisterMessageHandler registers a handler for a client message type.
+// Registering a handler for an existing type replaces the previous handler.
+func (h *Hub) RegisterMessageHandler(messageType string, handler MessageHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messageHandlers[messageType] = handler
+}
+
+// OnClientDisconnect registers a callback that runs after a client disconnects
+func (h *Hub) OnClientDisconnect(callback func(*Client)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.disconnectHandlers = append(h.disconnectHandlers, callback)
+}
+
+// getMessageHandler returns the registered handler for a message type, if any
+func (h *Hub) getMessageHandler(messageType string) (MessageHandler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	handler, ok := h.messageHandlers[messageType]
+	return handler, ok
+}
+
+// SubscribeClientToTopic subscribes a client to a topic used by BroadcastToTopic
+func (h *Hub) SubscribeClientToTopic(client *Client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+
+	if h.subscriptions[client] == nil {
+		h.subscriptions[client] = make(map[string]bool)
+	}
+	h.subscriptions[client][topic] = true
+
+	if h.topicClients[topic] == nil {
+		h.topicClients[topic] = make(map[*Client]bool)
+	}
+	h.topicClients[topic][client] = true
+}
+
+// UnsubscribeClientFromTopic removes a client from a topic
+func (h *Hub) UnsubscribeClientFromTopic(client *Client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if subs, ok := h.subscriptions[client]; ok {
+		delete(subs, topic)
+	}
+	if clients, ok := h.topicClients[topic]; ok {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.topicClients, topic)
+		}
+	}
+}
+
+// TopicSubscriberCount returns the number of clients subscribed to a topic
+func (h *Hub) TopicSubscriberCount(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topicClients[topic])
+}
+
 // Convenience methods for common events
 
 // BroadcastEntityUpdate broadcasts an entity update to all clients
@@ -651,6 +727,11 @@ func (h *Hub) unregisterClient(client *Client) {
 			"client_id":         client.ID,
 			"remaining_clients": len(h.clients),
 		}).Info("WebSocket client unregistered")
+
+		// Run callbacks outside the hub lock
+		for _, callback := range h.disconnectHandlers {
+			go callback(client)
+		}
 	}
 }
 
@@ -0,0 +1,8 @@
+-- Rollback Controller Dashboard Schema Versioning and Revision History
+
+DROP INDEX IF EXISTS idx_controller_dashboard_revisions_created;
+DROP INDEX IF EXISTS idx_controller_dashboard_revisions_dashboard;
+
+DROP TABLE IF EXISTS controller_dashboard_revisions;
+
+ALTER TABLE controller_dashboards DROP COLUMN schema_version;
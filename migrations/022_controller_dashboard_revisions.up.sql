@@ -0,0 +1,32 @@
+-- Controller Dashboard Schema Versioning and Revision History
+
+-- Track which element schema version a dashboard's JSON conforms to
+ALTER TABLE controller_dashboards ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;
+
+-- Dashboard revision history (a snapshot is stored on every create/update)
+CREATE TABLE IF NOT EXISTS controller_dashboard_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dashboard_id INTEGER NOT NULL REFERENCES controller_dashboards(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    schema_version INTEGER NOT NULL DEFAULT 1,
+
+    -- Snapshot of the dashboard content
+    name TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    category TEXT DEFAULT 'custom',
+    layout_config TEXT NOT NULL DEFAULT '{}',
+    elements_json TEXT NOT NULL DEFAULT '[]',
+    style_config TEXT NOT NULL DEFAULT '{}',
+    access_config TEXT NOT NULL DEFAULT '{}',
+    tags TEXT DEFAULT '[]',
+
+    -- Change metadata
+    change_summary TEXT DEFAULT '',
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(dashboard_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_controller_dashboard_revisions_dashboard ON controller_dashboard_revisions(dashboard_id, revision DESC);
+CREATE INDEX IF NOT EXISTS idx_controller_dashboard_revisions_created ON controller_dashboard_revisions(created_at);
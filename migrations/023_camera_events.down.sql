@@ -0,0 +1,7 @@
+-- Rollback Camera Event Archive
+
+DROP INDEX IF EXISTS idx_camera_events_external;
+DROP INDEX IF EXISTS idx_camera_events_occurred;
+DROP INDEX IF EXISTS idx_camera_events_camera;
+
+DROP TABLE IF EXISTS camera_events;
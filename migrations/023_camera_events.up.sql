@@ -0,0 +1,25 @@
+-- Camera Event Archive
+-- Snapshots and clips captured when cameras report motion or doorbell events
+
+CREATE TABLE IF NOT EXISTS camera_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    camera_entity_id TEXT NOT NULL,
+    event_type TEXT NOT NULL, -- motion, ding, manual
+    source TEXT NOT NULL,     -- ring, homeassistant, ...
+    trigger_entity_id TEXT DEFAULT '',
+    external_id TEXT DEFAULT '', -- Event ID reported by the source, used to deduplicate
+
+    -- Files stored through the file manager
+    snapshot_file_id TEXT DEFAULT '',
+    thumbnail_file_id TEXT DEFAULT '',
+    clip_file_id TEXT DEFAULT '',
+    clip_status TEXT NOT NULL DEFAULT 'none', -- none, pending, downloaded, failed
+
+    metadata TEXT NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_camera_events_camera ON camera_events(camera_entity_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_camera_events_occurred ON camera_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_camera_events_external ON camera_events(source, external_id);
@@ -0,0 +1,10 @@
+-- Rollback Notification Delivery
+
+DROP INDEX IF EXISTS idx_notification_push_subscriptions_user;
+DROP TABLE IF EXISTS notification_push_subscriptions;
+
+DROP INDEX IF EXISTS idx_notifications_created;
+DROP INDEX IF EXISTS idx_notifications_unread;
+DROP INDEX IF EXISTS idx_notifications_user;
+
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,44 @@
+-- Notification Delivery
+-- Persisted notifications with per-channel delivery results, read/acknowledged state and action responses
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    priority TEXT NOT NULL DEFAULT 'medium', -- low, medium, high, critical
+    source TEXT DEFAULT '',
+    room_id TEXT DEFAULT '',
+    device_id TEXT DEFAULT '',
+    data TEXT NOT NULL DEFAULT '{}',
+    actions TEXT NOT NULL DEFAULT '[]',
+    categories TEXT NOT NULL DEFAULT '[]',
+    deliveries TEXT NOT NULL DEFAULT '[]', -- channel, status (sent, failed, suppressed), error
+
+    read_at TIMESTAMP,
+    acknowledged_at TIMESTAMP,
+    acknowledged_by TEXT DEFAULT '',
+    response TEXT DEFAULT '',      -- JSON response to an actionable notification
+    responded_at TIMESTAMP,
+
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, read_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_created ON notifications(created_at);
+
+-- Web Push subscriptions registered by browsers
+CREATE TABLE IF NOT EXISTS notification_push_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_push_subscriptions_user ON notification_push_subscriptions(user_id);
@@ -0,0 +1,11 @@
+-- 025_room_persistence.down.sql
+-- Rollback PMA room persistence
+
+DROP INDEX IF EXISTS idx_room_entity_assignments_room;
+DROP TABLE IF EXISTS room_entity_assignments;
+
+DROP INDEX IF EXISTS idx_rooms_room_key;
+ALTER TABLE rooms DROP COLUMN attributes;
+ALTER TABLE rooms DROP COLUMN metadata;
+ALTER TABLE rooms DROP COLUMN parent_key;
+ALTER TABLE rooms DROP COLUMN room_key;
@@ -0,0 +1,29 @@
+-- 025_room_persistence.up.sql
+-- Persist PMA rooms and their entity assignments
+
+-- Stable string key used by the room service (e.g. pma_room_<id> or adapter room IDs)
+ALTER TABLE rooms ADD COLUMN room_key TEXT;
+ALTER TABLE rooms ADD COLUMN parent_key TEXT;
+ALTER TABLE rooms ADD COLUMN metadata TEXT; -- JSON PMAMetadata
+ALTER TABLE rooms ADD COLUMN attributes TEXT; -- JSON attributes
+
+-- Existing rooms are addressed by their numeric ID
+UPDATE rooms SET room_key = CAST(id AS TEXT) WHERE room_key IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rooms_room_key ON rooms(room_key);
+
+-- Entity to room assignments, keyed by room_key so adapter rooms can be referenced
+-- before they are synced. Dangling keys are reported by the room reconciliation job.
+CREATE TABLE IF NOT EXISTS room_entity_assignments (
+    entity_id TEXT PRIMARY KEY,
+    room_key TEXT NOT NULL,
+    assigned_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_entity_assignments_room ON room_entity_assignments(room_key);
+
+-- Carry over assignments stored on the entities table
+INSERT OR IGNORE INTO room_entity_assignments (entity_id, room_key)
+SELECT entity_id, CAST(room_id AS TEXT)
+FROM entities
+WHERE room_id IS NOT NULL;
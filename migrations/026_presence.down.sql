@@ -0,0 +1,7 @@
+-- Rollback Presence Detection
+
+DROP INDEX IF EXISTS idx_presence_trackers_person;
+DROP TABLE IF EXISTS presence_trackers;
+DROP INDEX IF EXISTS idx_presence_persons_user;
+DROP TABLE IF EXISTS presence_persons;
+DROP TABLE IF EXISTS presence_zones;
@@ -0,0 +1,42 @@
+-- Presence Detection
+-- People, the trackers that locate them and the zones they can be in
+
+CREATE TABLE IF NOT EXISTS presence_persons (
+    id TEXT PRIMARY KEY,              -- slug, the entity is person.<id>
+    name TEXT NOT NULL,
+    user_id TEXT DEFAULT '',          -- optional link to a PMA user for app check-ins
+    picture TEXT DEFAULT '',
+    consider_home_seconds INTEGER NOT NULL DEFAULT 0, -- 0 uses the configured default
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_presence_persons_user ON presence_persons(user_id);
+
+CREATE TABLE IF NOT EXISTS presence_trackers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id TEXT NOT NULL REFERENCES presence_persons(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,               -- network, ble, entity, app
+    identifier TEXT NOT NULL,         -- MAC/IP, BLE address or beacon key, entity ID, app device name
+    name TEXT DEFAULT '',
+    prob_given_home REAL NOT NULL,    -- chance the tracker sees the person when they are home
+    prob_given_away REAL NOT NULL,    -- chance the tracker sees the person when they are away
+    consider_home_seconds INTEGER NOT NULL DEFAULT 0, -- 0 uses the person's timeout
+    min_rssi INTEGER NOT NULL DEFAULT 0,              -- BLE only, 0 disables
+    last_seen TIMESTAMP,              -- last time the tracker placed the person at home
+    last_state TEXT DEFAULT '',       -- last reported state: home, not_home or a zone ID
+    last_reported TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE(person_id, type, identifier)
+);
+
+CREATE INDEX IF NOT EXISTS idx_presence_trackers_person ON presence_trackers(person_id);
+
+CREATE TABLE IF NOT EXISTS presence_zones (
+    id TEXT PRIMARY KEY,              -- "home" is the home zone
+    name TEXT NOT NULL,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    radius_meters REAL NOT NULL DEFAULT 100,
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Rollback Group Entities
+
+DROP INDEX IF EXISTS idx_entity_group_members_entity;
+DROP TABLE IF EXISTS entity_group_members;
+DROP TABLE IF EXISTS entity_groups;
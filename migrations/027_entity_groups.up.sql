@@ -0,0 +1,24 @@
+-- Group Entities
+-- User-defined groups and sensor aggregates exposed as virtual PMA entities
+
+CREATE TABLE IF NOT EXISTS entity_groups (
+    id TEXT PRIMARY KEY,              -- slug, the entity is <entity_type>.group_<id>
+    name TEXT NOT NULL,
+    entity_type TEXT NOT NULL,        -- light, switch, cover, lock or sensor
+    mode TEXT NOT NULL DEFAULT 'any', -- any or all, how member states combine
+    aggregate TEXT DEFAULT '',        -- sensor groups: min, max, mean, median or sum
+    unit TEXT DEFAULT '',             -- sensor groups: overrides the unit of the members
+    icon TEXT DEFAULT '',
+    room_id TEXT DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS entity_group_members (
+    group_id TEXT NOT NULL REFERENCES entity_groups(id) ON DELETE CASCADE,
+    entity_id TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (group_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_group_members_entity ON entity_group_members(entity_id);
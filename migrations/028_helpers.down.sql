@@ -0,0 +1,4 @@
+-- Rollback Helper Entities
+
+DROP INDEX IF EXISTS idx_helpers_type;
+DROP TABLE IF EXISTS helpers;
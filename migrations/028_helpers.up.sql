@@ -0,0 +1,18 @@
+-- Helper Entities
+-- Persistent toggles, numbers, selects, texts, counters and timers owned by PMA
+
+CREATE TABLE IF NOT EXISTS helpers (
+    id TEXT PRIMARY KEY,              -- slug, the entity is <type>.<id>
+    type TEXT NOT NULL,               -- input_boolean, input_number, input_select, input_text, counter or timer
+    name TEXT NOT NULL,
+    icon TEXT DEFAULT '',
+    config TEXT NOT NULL DEFAULT '{}', -- JSON: min, max, step, unit, mode, options, max_length, pattern, initial, duration
+    state TEXT NOT NULL DEFAULT '',
+    timer_duration INTEGER NOT NULL DEFAULT 0,  -- timers: seconds the running timer was started for
+    timer_remaining INTEGER NOT NULL DEFAULT 0, -- timers: seconds left when paused
+    timer_finishes_at TIMESTAMP,                -- timers: when the running timer finishes
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_helpers_type ON helpers(type);
@@ -0,0 +1,3 @@
+-- Rollback Entity Customizations
+
+DROP TABLE IF EXISTS entity_customizations;
@@ -0,0 +1,15 @@
+-- Entity Customizations
+-- User overrides applied to adapter entities on every sync; empty columns keep the source value
+
+CREATE TABLE IF NOT EXISTS entity_customizations (
+    entity_id TEXT PRIMARY KEY,
+    friendly_name TEXT DEFAULT '',
+    icon TEXT DEFAULT '',
+    entity_type TEXT DEFAULT '',      -- e.g. light for a switch driving a light
+    unit TEXT DEFAULT '',             -- numeric states are converted when the source unit is known
+    device_class TEXT DEFAULT '',
+    hidden BOOLEAN NOT NULL DEFAULT 0,   -- left out of entity listings
+    disabled BOOLEAN NOT NULL DEFAULT 0, -- unavailable and not controllable
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Rollback MCP Servers
+
+DROP TABLE IF EXISTS mcp_servers;
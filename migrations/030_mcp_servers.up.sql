@@ -0,0 +1,21 @@
+-- MCP Servers
+-- External Model Context Protocol servers whose tools are merged into AI chats
+
+CREATE TABLE IF NOT EXISTS mcp_servers (
+    id TEXT PRIMARY KEY,              -- also the namespace of the server's tools
+    name TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    transport TEXT NOT NULL DEFAULT 'stdio', -- stdio, http (streamable HTTP) or sse
+    command TEXT DEFAULT '',          -- stdio only
+    args TEXT DEFAULT '[]',           -- JSON array, stdio only
+    env TEXT DEFAULT '{}',            -- JSON object, stdio only
+    cwd TEXT DEFAULT '',
+    url TEXT DEFAULT '',              -- http and sse only
+    headers TEXT DEFAULT '{}',        -- JSON object, http and sse only
+    timeout INTEGER NOT NULL DEFAULT 30, -- request timeout in seconds
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    auto_restart BOOLEAN NOT NULL DEFAULT 1,
+    max_restarts INTEGER NOT NULL DEFAULT 3, -- negative retries forever
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Rollback Audit Log
+
+DROP TABLE IF EXISTS audit_log;
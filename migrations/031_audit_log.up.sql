@@ -0,0 +1,24 @@
+-- Audit Log
+-- Append-only record of control actions and configuration changes; each row hashes the previous one
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    timestamp TEXT NOT NULL,          -- UTC with nanoseconds, sortable as text
+    actor_type TEXT NOT NULL,         -- user, kiosk, automation, ai, api_token, system or anonymous
+    actor_id TEXT DEFAULT '',
+    actor_name TEXT DEFAULT '',
+    category TEXT NOT NULL,           -- entity, automation, scene, area, user, config, auth or kiosk
+    action TEXT NOT NULL,
+    target TEXT DEFAULT '',
+    outcome TEXT NOT NULL,            -- success or failure
+    error TEXT DEFAULT '',
+    details TEXT DEFAULT '',          -- JSON object, hashed verbatim
+    source_ip TEXT DEFAULT '',
+    prev_hash TEXT NOT NULL DEFAULT '',
+    hash TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log(timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_type, actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_category ON audit_log(category);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target);
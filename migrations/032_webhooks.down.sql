@@ -0,0 +1,5 @@
+-- Rollback Outbound Webhooks
+
+DELETE FROM action_types WHERE name = 'webhook_delivery';
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,41 @@
+-- Outbound Webhooks
+-- User-defined endpoints notified of entity, automation, alert and adapter events
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT DEFAULT '',              -- signs payloads with HMAC-SHA256 (X-PMA-Signature header)
+    events TEXT NOT NULL DEFAULT '[]',   -- JSON array of event type patterns, e.g. "alert.*"; empty matches all
+    entities TEXT NOT NULL DEFAULT '[]', -- JSON array of entity ID patterns for entity events, e.g. "light.*"
+    headers TEXT NOT NULL DEFAULT '{}',  -- JSON object of extra request headers
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_reason TEXT DEFAULT '',     -- set when the endpoint was disabled for failing
+    last_delivery_at TIMESTAMP,
+    last_success_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- One row per delivery attempt
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 1,
+    success BOOLEAN NOT NULL DEFAULT 0,
+    status_code INTEGER DEFAULT 0,
+    response TEXT DEFAULT '',            -- start of the response body
+    error TEXT DEFAULT '',
+    duration_ms INTEGER DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created ON webhook_deliveries(created_at);
+
+-- Deliveries run on the action queue, which retries them with exponential backoff
+INSERT OR IGNORE INTO action_types (name, description, handler_name, default_timeout, max_retries, retry_backoff_factor) VALUES
+('webhook_delivery', 'Deliver an event to an outbound webhook', 'WebhookDeliveryHandler', 30, 5, 3.0);
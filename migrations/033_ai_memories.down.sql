@@ -0,0 +1,3 @@
+-- Rollback AI Long-Term Memory
+
+DROP TABLE IF EXISTS ai_memories;
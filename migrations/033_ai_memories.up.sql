@@ -0,0 +1,20 @@
+-- AI Long-Term Memory
+-- Facts learned from conversations and user notes, recalled into the system prompt of later
+-- conversations by embedding similarity
+
+CREATE TABLE IF NOT EXISTS ai_memories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL DEFAULT '',        -- empty for household memories every user shares
+    content TEXT NOT NULL,
+    source TEXT NOT NULL,                    -- conversation, note
+    conversation_id TEXT DEFAULT '',
+    embedding BLOB,                          -- little-endian float32 vector
+    embedding_model TEXT DEFAULT '',         -- provider/model the embedding was made with
+    recall_count INTEGER NOT NULL DEFAULT 0,
+    last_recalled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_memories_user ON ai_memories(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_memories_conversation ON ai_memories(conversation_id);
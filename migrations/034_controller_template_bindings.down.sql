@@ -0,0 +1,6 @@
+-- Rollback Controller Template Bindings
+
+UPDATE controller_templates
+SET template_json = '{"layout":{"columns":12,"rows":8,"grid_size":64,"gap":8,"responsive":true},"elements":[{"type":"button","position":{"x":0,"y":0,"width":3,"height":2},"config":{"label":"Light Toggle","button":{"text":"Toggle Light","action_type":"entity_action"}},"style":{"background":"#374151","text_color":"#ffffff"},"behavior":{"enabled":true,"visible":true,"interactive":true}},{"type":"slider","position":{"x":3,"y":0,"width":4,"height":2},"config":{"label":"Brightness","min":0,"max":100,"step":1,"unit":"%"},"style":{"background":"#374151","text_color":"#ffffff"},"behavior":{"enabled":true,"visible":true,"interactive":true}},{"type":"display_value","position":{"x":7,"y":0,"width":3,"height":1},"config":{"label":"Temperature","format":"number","unit":"°C"},"style":{"background":"#1f2937","text_color":"#ffffff"},"behavior":{"enabled":true,"visible":true,"interactive":false}}]}',
+    variables_json = '[{"name":"room_name","type":"text","default":"Living Room","description":"Name of the room for this dashboard"},{"name":"light_entity","type":"entity","entity_type":"light","description":"Primary light entity to control"}]'
+WHERE id = 1 AND category = 'system' AND template_json LIKE '%"light_toggle_binding_1"%';
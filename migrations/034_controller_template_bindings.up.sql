@@ -0,0 +1,9 @@
+-- Controller Template Bindings
+-- Rewrites the seeded "Basic Controls" template in the current element schema: the controls are
+-- bound to the light and temperature sensor picked when applying it, since sliders and sensor
+-- displays require an entity binding. Templates edited since the seed are left alone.
+
+UPDATE controller_templates
+SET template_json = '{"schema_version":2,"layout":{"columns":12,"rows":8,"grid_size":64,"gap":8,"responsive":true},"elements":[{"id":"light_toggle","type":"button","position":{"x":0,"y":0,"width":3,"height":2},"config":{"label":"{{room_name}} Light","action":"toggle"},"entity_bindings":[{"id":"light_toggle_binding_1","entity_id":"{{light_entity}}","property":"state","update_mode":"realtime"}],"style":{"background":"#374151","text_color":"#ffffff"},"behavior":{"enabled":true,"visible":true,"interactive":true}},{"id":"light_brightness","type":"slider","position":{"x":3,"y":0,"width":4,"height":2},"config":{"label":"Brightness","slider":{"min":0,"max":100,"step":1,"unit":"%"}},"entity_bindings":[{"id":"light_brightness_binding_1","entity_id":"{{light_entity}}","property":"brightness","update_mode":"realtime"}],"style":{"background":"#374151","text_color":"#ffffff"},"behavior":{"enabled":true,"visible":true,"interactive":true}},{"id":"temperature","type":"sensor","position":{"x":7,"y":0,"width":3,"height":1},"config":{"label":"Temperature","unit":"°C","precision":1},"entity_bindings":[{"id":"temperature_binding_1","entity_id":"{{temperature_entity}}","property":"state","update_mode":"realtime"}],"style":{"background":"#1f2937","text_color":"#ffffff"},"behavior":{"enabled":true,"visible":true,"interactive":false}}]}',
+    variables_json = '[{"name":"room_name","type":"string","default":"Living Room","description":"Name of the room for this dashboard"},{"name":"light_entity","type":"entity","entity_type":"light","description":"Primary light entity to control","required":true},{"name":"temperature_entity","type":"entity","entity_type":"sensor","description":"Temperature sensor to display","required":true}]'
+WHERE id = 1 AND category = 'system' AND template_json LIKE '%"display_value"%';
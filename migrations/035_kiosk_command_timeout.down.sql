@@ -0,0 +1,3 @@
+-- Rollback Kiosk Command Timeout
+
+ALTER TABLE kiosk_commands DROP COLUMN timeout_seconds;
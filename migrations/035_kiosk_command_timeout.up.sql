@@ -0,0 +1,4 @@
+-- Kiosk Command Timeout
+-- Stores each command's result timeout with the command so it survives until delivery
+
+ALTER TABLE kiosk_commands ADD COLUMN timeout_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/frostdev-ops/pma-backend-go/internal/api"
+	"github.com/frostdev-ops/pma-backend-go/internal/config"
+	"github.com/frostdev-ops/pma-backend-go/internal/database"
+	"github.com/frostdev-ops/pma-backend-go/internal/websocket"
+	"github.com/frostdev-ops/pma-backend-go/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupContractRouter(t *testing.T) *api.RouterWithHandlers {
+	t.Helper()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Mode: "test",
+		},
+		AI: config.AIConfig{
+			Intents:     config.IntentsConfig{Enabled: true, LocalesPath: "../../locales"},
+			Memory:      config.AIMemoryConfig{Enabled: true},
+			HomeContext: config.HomeContextConfig{Enabled: true},
+		},
+		Presence: config.PresenceConfig{Enabled: true},
+		Watchdog: config.WatchdogConfig{Enabled: true},
+		Webhooks: config.WebhooksConfig{Enabled: true},
+		Voice:    config.VoiceConfig{Enabled: true},
+	}
+	db, err := database.Initialize(config.DatabaseConfig{
+		Path:           filepath.Join(t.TempDir(), "pma.db"),
+		MaxConnections: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.Migrate(db, "../../migrations"))
+
+	log := logger.New()
+	wsHub := websocket.NewHub(log.Logger)
+	go wsHub.Run()
+	t.Cleanup(wsHub.Shutdown)
+
+	return api.NewRouter(cfg, database.NewRepositories(db), log, wsHub, db, nil)
+}
+
+// TestOpenAPIContract fails when a typed route drifts from the generated spec
+func TestOpenAPIContract(t *testing.T) {
+	r := setupContractRouter(t)
+
+	t.Run("DescribedRoutesAreRegistered", func(t *testing.T) {
+		assert.Empty(t, r.Spec.Unregistered(r.Router.Routes()))
+	})
+
+	t.Run("SpecIsServed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var doc struct {
+			OpenAPI string                                `json:"openapi"`
+			Paths   map[string]map[string]json.RawMessage `json:"paths"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		assert.Equal(t, "3.0.3", doc.OpenAPI)
+		assert.Contains(t, doc.Paths, "/api/v1/helpers/{id}")
+		assert.Contains(t, doc.Paths["/api/v1/entities/groups"], "post")
+	})
+
+	// Targets name ids captured from earlier responses in braces, e.g. {room_id}
+	ids := make(map[string]string)
+	expand := func(target string) string {
+		for name, id := range ids {
+			target = strings.ReplaceAll(target, "{"+name+"}", id)
+		}
+		return target
+	}
+
+	t.Run("CreatedResourcesMatchSpec", func(t *testing.T) {
+		requests := []struct {
+			method, path, target, body, capture string
+		}{
+			{http.MethodPost, "/api/v1/helpers/", "/api/v1/helpers/", `{"id": "guest_mode", "type": "input_boolean", "name": "Guest mode"}`, ""},
+			{http.MethodGet, "/api/v1/helpers/:id", "/api/v1/helpers/input_boolean.guest_mode", "", ""},
+			{http.MethodPut, "/api/v1/entities/:id/customization", "/api/v1/entities/light.kitchen/customization", `{"friendly_name": "Kitchen", "hidden": true}`, ""},
+
+			{http.MethodPost, "/api/v1/entities/", "/api/v1/entities/", `{"id": "light.contract", "type": "light", "friendly_name": "Contract light"}`, ""},
+			{http.MethodGet, "/api/v1/entities/:id", "/api/v1/entities/light.contract", "", ""},
+			{http.MethodGet, "/api/v1/entities/type/:type", "/api/v1/entities/type/light", "", ""},
+			{http.MethodGet, "/api/v1/entities/source/:source", "/api/v1/entities/source/pma", "", ""},
+
+			{http.MethodPost, "/api/v1/rooms/", "/api/v1/rooms/", `{"name": "Contract room"}`, "room_id"},
+			{http.MethodGet, "/api/v1/rooms/:id", "/api/v1/rooms/{room_id}", "", ""},
+			{http.MethodPut, "/api/v1/rooms/:id", "/api/v1/rooms/{room_id}", `{"name": "Contract den"}`, ""},
+			{http.MethodGet, "/api/v1/entities/room/:roomId", "/api/v1/entities/room/{room_id}", "", ""},
+
+			{http.MethodPost, "/api/v1/areas/", "/api/v1/areas/", `{"name": "Contract area"}`, "id"},
+			{http.MethodGet, "/api/v1/areas/:id", "/api/v1/areas/{id}", "", ""},
+			{http.MethodPut, "/api/v1/areas/:id", "/api/v1/areas/{id}", `{"name": "Contract floor"}`, ""},
+
+			{http.MethodPost, "/api/v1/automation/rules", "/api/v1/automation/rules", `{"id": "contract_rule", "name": "Contract rule", "triggers": [{"platform": "state", "entity_id": "light.contract", "to": "on"}], "actions": [{"service": "light.turn_off", "entity_id": "light.contract"}]}`, ""},
+			{http.MethodGet, "/api/v1/automation/rules/:id", "/api/v1/automation/rules/contract_rule", "", ""},
+			{http.MethodPost, "/api/v1/automation/rules/:id/disable", "/api/v1/automation/rules/contract_rule/disable", "", ""},
+			{http.MethodPost, "/api/v1/automation/rules/:id/enable", "/api/v1/automation/rules/contract_rule/enable", "", ""},
+		}
+
+		for _, request := range requests {
+			target := expand(request.target)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(request.method, target, strings.NewReader(request.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.Router.ServeHTTP(w, req)
+
+			require.Less(t, w.Code, 300, "%s %s: %s", request.method, target, w.Body.String())
+			assert.NoError(t, r.Spec.ValidateResponse(request.method, request.path, w.Code, w.Body.Bytes()))
+
+			if request.capture != "" {
+				var response struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				ids[request.capture] = fmt.Sprint(response.Data[request.capture])
+			}
+		}
+	})
+
+	// Runs after the creates so lists have items to check against the spec
+	t.Run("ResponsesMatchSpec", func(t *testing.T) {
+		for _, route := range r.Spec.Routes() {
+			if route.Method != http.MethodGet || strings.Contains(route.Path, ":") {
+				continue
+			}
+
+			query := url.Values{}
+			for _, parameter := range route.Query {
+				if parameter.Required {
+					query.Set(parameter.Name, "contract")
+				}
+			}
+			target := route.Path
+			if len(query) > 0 {
+				target += "?" + query.Encode()
+			}
+
+			w := httptest.NewRecorder()
+			r.Router.ServeHTTP(w, httptest.NewRequest(route.Method, target, nil))
+			assert.Less(t, w.Code, 300, "%s: %s", target, w.Body.String())
+			assert.NoError(t, r.Spec.ValidateResponse(route.Method, route.Path, w.Code, w.Body.Bytes()), w.Body.String())
+		}
+	})
+
+	t.Run("DeletedResourcesMatchSpec", func(t *testing.T) {
+		requests := []struct {
+			path, target string
+		}{
+			{"/api/v1/helpers/:id", "/api/v1/helpers/input_boolean.guest_mode"},
+			{"/api/v1/entities/:id", "/api/v1/entities/light.contract"},
+			{"/api/v1/automation/rules/:id", "/api/v1/automation/rules/contract_rule"},
+			{"/api/v1/rooms/:id", "/api/v1/rooms/{room_id}"},
+			{"/api/v1/areas/:id", "/api/v1/areas/{id}"},
+		}
+
+		for _, request := range requests {
+			target := expand(request.target)
+			w := httptest.NewRecorder()
+			r.Router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, target, nil))
+			assert.Equal(t, http.StatusOK, w.Code, "%s: %s", target, w.Body.String())
+			assert.NoError(t, r.Spec.ValidateResponse(http.MethodDelete, request.path, w.Code, w.Body.Bytes()))
+		}
+	})
+
+	t.Run("InvalidRequestBodyIsRejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := strings.NewReader(`{"name": "Lights", "members": "light.kitchen"}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/entities/groups", body)
+		req.Header.Set("Content-Type", "application/json")
+		r.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "$.members")
+		assert.NoError(t, r.Spec.ValidateResponse(http.MethodPost, "/api/v1/entities/groups", w.Code, w.Body.Bytes()))
+	})
+}